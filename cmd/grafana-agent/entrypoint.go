@@ -3,6 +3,8 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -18,6 +20,7 @@ import (
 	"github.com/go-kit/log/level"
 	"github.com/gorilla/mux"
 	"github.com/grafana/agent/pkg/config"
+	"github.com/grafana/agent/pkg/config/instrumentation"
 	"github.com/grafana/agent/pkg/logs"
 	"github.com/grafana/agent/pkg/metrics"
 	"github.com/grafana/agent/pkg/metrics/instance"
@@ -37,6 +40,9 @@ type Entrypoint struct {
 	mut sync.Mutex
 
 	reloader Reloader
+	// poller holds the state of polling the remote config. It's shared with
+	// reloader.
+	poller *config.RemoteConfigPoller
 
 	log *server.Logger
 	cfg config.Config
@@ -50,13 +56,28 @@ type Entrypoint struct {
 
 	reloadListener net.Listener
 	reloadServer   *http.Server
+
+	// refreshRemoteConfig signals pollConfig to fetch the remote config
+	// without waiting for the polling interval.
+	refreshRemoteConfig chan struct{}
+
+	// remoteConfigDiff describes the changes made by the last reload which
+	// applied a different config while Agent Management was enabled.
+	remoteConfigDiff remoteConfigDiff
+}
+
+// remoteConfigDiff is served by the /-/remote-config/diff endpoint.
+type remoteConfigDiff struct {
+	AppliedAt time.Time             `json:"applied_at"`
+	Changes   []config.ConfigChange `json:"changes"`
 }
 
 // Reloader is any function that returns a new config.
 type Reloader = func(log *server.Logger) (*config.Config, error)
 
-// NewEntrypoint creates a new Entrypoint.
-func NewEntrypoint(logger *server.Logger, cfg *config.Config, reloader Reloader) (*Entrypoint, error) {
+// NewEntrypoint creates a new Entrypoint. The remote config is loaded by
+// reloader with poller.
+func NewEntrypoint(logger *server.Logger, cfg *config.Config, poller *config.RemoteConfigPoller, reloader Reloader) (*Entrypoint, error) {
 	var (
 		reg      = prometheus.DefaultRegisterer
 		gatherer = prometheus.DefaultGatherer
@@ -64,6 +85,9 @@ func NewEntrypoint(logger *server.Logger, cfg *config.Config, reloader Reloader)
 		ep = &Entrypoint{
 			log:      logger,
 			reloader: reloader,
+			poller:   poller,
+
+			refreshRemoteConfig: make(chan struct{}, 1),
 		}
 		err error
 	)
@@ -77,6 +101,9 @@ func NewEntrypoint(logger *server.Logger, cfg *config.Config, reloader Reloader)
 	if err != nil {
 		return nil, err
 	}
+	// Requests for remote configs carry the shard of the agent once it joins
+	// the scraping service cluster.
+	poller.SetClusterShardFunc(ep.promMetrics.ClusterShard)
 
 	ep.lokiLogs, err = logs.New(reg, cfg.Logs, logger, false)
 	if err != nil {
@@ -107,7 +134,9 @@ func NewEntrypoint(logger *server.Logger, cfg *config.Config, reloader Reloader)
 
 	// Mostly everything should be up to date except for the server, which hasn't
 	// been created yet.
-	if err := ep.ApplyConfig(*cfg); err != nil {
+	err = ep.ApplyConfig(*cfg)
+	ep.remoteConfigApplied(err)
+	if err != nil {
 		return nil, err
 	}
 	return ep, nil
@@ -234,6 +263,11 @@ func (ep *Entrypoint) wire(mux *mux.Router, grpc *grpc.Server) {
 	})
 
 	mux.HandleFunc("/-/reload", ep.reloadHandler).Methods("GET", "POST")
+	mux.HandleFunc("/-/remote-config/versions", ep.remoteConfigVersionsHandler).Methods("GET")
+	mux.HandleFunc("/-/remote-config/rollback", ep.remoteConfigRollbackHandler).Methods("POST")
+	mux.HandleFunc("/-/remote-config/refresh", ep.remoteConfigRefreshHandler).Methods("POST")
+	mux.HandleFunc("/-/remote-config/diff", ep.remoteConfigDiffHandler).Methods("GET")
+	mux.HandleFunc("/agent/api/v1/remote-config", ep.remoteConfigStateHandler).Methods("GET")
 
 	mux.HandleFunc("/-/support", ep.supportHandler).Methods("GET")
 }
@@ -247,6 +281,121 @@ func (ep *Entrypoint) reloadHandler(rw http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// remoteConfigVersionsHandler lists the versions of the remote config kept in
+// the cache history.
+func (ep *Entrypoint) remoteConfigVersionsHandler(rw http.ResponseWriter, r *http.Request) {
+	ep.mut.Lock()
+	cfg := ep.cfg
+	ep.mut.Unlock()
+
+	if !cfg.AgentManagement.Enabled {
+		http.Error(rw, "agent management is disabled", http.StatusNotFound)
+		return
+	}
+
+	versions, err := config.RemoteConfigVersions(&cfg)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(versions)
+}
+
+// remoteConfigStateHandler returns the state of the remote config, such as
+// the hash of the applied remote config and the outcome of the last fetch.
+func (ep *Entrypoint) remoteConfigStateHandler(rw http.ResponseWriter, r *http.Request) {
+	ep.mut.Lock()
+	cfg := ep.cfg
+	ep.mut.Unlock()
+
+	if !cfg.AgentManagement.Enabled {
+		http.Error(rw, "agent management is disabled", http.StatusNotFound)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(config.GetRemoteConfigState(ep.poller))
+}
+
+// remoteConfigDiffHandler returns the redacted changes made by the last
+// reload which applied a different config.
+func (ep *Entrypoint) remoteConfigDiffHandler(rw http.ResponseWriter, r *http.Request) {
+	ep.mut.Lock()
+	cfg := ep.cfg
+	diff := ep.remoteConfigDiff
+	ep.mut.Unlock()
+
+	if !cfg.AgentManagement.Enabled {
+		http.Error(rw, "agent management is disabled", http.StatusNotFound)
+		return
+	}
+
+	if diff.Changes == nil {
+		diff.Changes = []config.ConfigChange{}
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(diff)
+}
+
+// remoteConfigRollbackHandler rolls the remote config back to the cached
+// version given by the version query parameter and reloads the config.
+func (ep *Entrypoint) remoteConfigRollbackHandler(rw http.ResponseWriter, r *http.Request) {
+	ep.mut.Lock()
+	cfg := ep.cfg
+	ep.mut.Unlock()
+
+	if !cfg.AgentManagement.Enabled {
+		http.Error(rw, "agent management is disabled", http.StatusNotFound)
+		return
+	}
+
+	version := r.URL.Query().Get("version")
+	if version == "" {
+		http.Error(rw, "version must be specified", http.StatusBadRequest)
+		return
+	}
+
+	err := config.RollbackRemoteConfig(&cfg, ep.poller, version)
+	if errors.Is(err, config.ErrUnknownRemoteConfigVersion) {
+		http.Error(rw, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	level.Info(ep.log).Log("msg", "rolled back remote config", "version", version)
+
+	if !ep.TriggerReload() {
+		http.Error(rw, "rolled back remote config, but the config failed to reload", http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+// remoteConfigRefreshHandler requests the remote config to be fetched from
+// the Agent Management API right away instead of at the next polling
+// interval. The fetch happens in the background, so the handler returns
+// without waiting for the config to be reloaded.
+func (ep *Entrypoint) remoteConfigRefreshHandler(rw http.ResponseWriter, r *http.Request) {
+	ep.mut.Lock()
+	enabled := ep.cfg.AgentManagement.Enabled
+	ep.mut.Unlock()
+
+	if !enabled {
+		http.Error(rw, "agent management is disabled", http.StatusNotFound)
+		return
+	}
+
+	level.Info(ep.log).Log("msg", "remote config refresh requested")
+	select {
+	case ep.refreshRemoteConfig <- struct{}{}:
+	default:
+		// A refresh is already pending.
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
 // getReporterMetrics creates the metrics map to send to usage reporter
 func (ep *Entrypoint) getReporterMetrics() map[string]interface{} {
 	ep.mut.Lock()
@@ -332,43 +481,235 @@ func (ep *Entrypoint) supportHandler(rw http.ResponseWriter, r *http.Request) {
 
 // TriggerReload will cause the Entrypoint to re-request the config file and
 // apply the latest config. TriggerReload returns true if the reload was
-// successful.
+// successful. The running config is kept if the remote config from the Agent
+// Management API hasn't changed.
+//
+// When Agent Management is enabled and the new config fails to apply, the
+// previous config is applied again so that subsystems aren't left running
+// with a partially applied config. The remote config which failed to apply
+// is loaded again by the next reload.
 func (ep *Entrypoint) TriggerReload() bool {
 	level.Info(ep.log).Log("msg", "reload of config file requested")
 
 	cfg, err := ep.reloader(ep.log)
-	if err != nil {
+	if errors.Is(err, config.ErrRemoteConfigNotModified) {
+		level.Info(ep.log).Log("msg", "remote config unchanged, skipping reload")
+		return true
+	} else if err != nil {
 		level.Error(ep.log).Log("msg", "failed to reload config file", "err", err)
 		return false
 	}
 	cfg.LogDeprecations(ep.log)
 
+	ep.mut.Lock()
+	prevCfg := ep.cfg
+	ep.mut.Unlock()
+
+	var changes []config.ConfigChange
+	if cfg.AgentManagement.Enabled {
+		changes, err = config.DiffConfigs(&prevCfg, cfg)
+		if err != nil {
+			level.Warn(ep.log).Log("msg", "could not diff the new config against the previous config", "err", err)
+		}
+	}
+
 	err = ep.ApplyConfig(*cfg)
+	ep.remoteConfigApplied(err)
 	if err != nil {
 		level.Error(ep.log).Log("msg", "failed to reload config file", "err", err)
+
+		if prevCfg.AgentManagement.Enabled {
+			ep.rollbackConfig(prevCfg)
+		}
 		return false
 	}
 
+	if len(changes) > 0 {
+		ep.recordRemoteConfigDiff(changes)
+	}
 	return true
 }
 
-// pollConfig triggers a reload of the config on each tick of the ticker until the context
-// completes.
-func (ep *Entrypoint) pollConfig(ctx context.Context, sleepTime time.Duration) error {
+// remoteConfigApplied records the outcome of applying the remote config which
+// was last loaded, so that a remote config which failed to apply isn't kept
+// as loaded or cached.
+func (ep *Entrypoint) remoteConfigApplied(err error) {
+	if restoreErr := ep.poller.RemoteConfigApplied(err); restoreErr != nil {
+		level.Warn(ep.log).Log("msg", "could not restore the cached remote config", "err", restoreErr)
+	}
+}
+
+// recordRemoteConfigDiff logs the changes made by applying a new config and
+// keeps them for the /-/remote-config/diff endpoint. Secrets are redacted
+// from the changes.
+func (ep *Entrypoint) recordRemoteConfigDiff(changes []config.ConfigChange) {
+	level.Info(ep.log).Log("msg", "applied config differs from the previous config", "changes", len(changes))
+	for _, c := range changes {
+		level.Info(ep.log).Log("msg", "config changed", "type", c.Type, "path", c.Path, "old", c.Old, "new", c.New)
+	}
+
+	ep.mut.Lock()
+	defer ep.mut.Unlock()
+	ep.remoteConfigDiff = remoteConfigDiff{AppliedAt: time.Now(), Changes: changes}
+}
+
+// rollbackConfig applies prevCfg again after a new config failed to apply.
+func (ep *Entrypoint) rollbackConfig(prevCfg config.Config) {
+	level.Warn(ep.log).Log("msg", "rolling back to the previous config")
+
+	if err := ep.ApplyConfig(prevCfg); err != nil {
+		level.Error(ep.log).Log("msg", "failed to roll back to the previous config", "err", err)
+		return
+	}
+	instrumentation.InstrumentRollback()
+}
+
+// pollConfig triggers a reload of the config after waiting for the duration
+// returned by SleepTime until the context completes. A reload is also
+// triggered right away when a refresh is requested through the API. The
+// running config is only replaced when the remote config changed.
+func (ep *Entrypoint) pollConfig(ctx context.Context) error {
 	// Add an initial jitter to requests
 	time.Sleep(ep.cfg.AgentManagement.JitterTime())
 
-	t := time.NewTicker(sleepTime)
 	for {
+		// The sleep time is recomputed on every iteration so that changes to
+		// the polling settings and backoff after failed fetches are applied.
+		ep.mut.Lock()
+		sleepTime := remoteConfigSleepTime(ep.log, ep.poller, &ep.cfg.AgentManagement)
+		ep.mut.Unlock()
+
 		select {
 		case <-ctx.Done():
 			return nil
-		case <-t.C:
-			ok := ep.TriggerReload()
-			if !ok {
-				level.Error(ep.log).Log("msg", "config reload did not succeed")
+		case <-time.After(sleepTime):
+		case <-ep.refreshRemoteConfig:
+		}
+
+		ok := ep.TriggerReload()
+		if !ok {
+			level.Error(ep.log).Log("msg", "config reload did not succeed")
+		}
+	}
+}
+
+// canaryCheckInterval is how often the health of the agent is checked while
+// a canary remote config is applied.
+const canaryCheckInterval = 15 * time.Second
+
+// watchCanary checks the health of the agent every canaryCheckInterval while
+// a canary remote config is applied, until the context completes. The
+// previous remote config is restored and reloaded if the watched failure
+// counters grow too much, or if the agent restarted while the canary was
+// applied. Otherwise, the canary is kept once its TTL elapses.
+func (ep *Entrypoint) watchCanary(ctx context.Context) error {
+	var (
+		baseline     config.CanaryHealthBaseline
+		baselineHash string
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(canaryCheckInterval):
+		}
+
+		ep.mut.Lock()
+		cfg := ep.cfg
+		ep.mut.Unlock()
+
+		canary, err := config.ActiveRemoteConfigCanary(&cfg, ep.poller)
+		if err != nil {
+			level.Warn(ep.log).Log("msg", "could not check for a canary remote config", "err", err)
+			continue
+		} else if canary == nil {
+			baseline = nil
+			continue
+		}
+
+		var reason string
+		switch {
+		case canary.Restarted:
+			reason = "agent restarted while the canary was applied"
+		case baseline == nil || baselineHash != canary.ConfigHash:
+			// The health of the agent is compared against the failure counters
+			// from when the canary was first seen.
+			baseline, err = config.NewCanaryHealthBaseline(prometheus.DefaultGatherer)
+			if err != nil {
+				level.Warn(ep.log).Log("msg", "could not gather failure counters for canary remote config", "err", err)
+			}
+			baselineHash = canary.ConfigHash
+			continue
+		default:
+			reason, err = config.CheckCanaryHealth(prometheus.DefaultGatherer, baseline, canary.FailureThreshold)
+			if err != nil {
+				level.Warn(ep.log).Log("msg", "could not check health of canary remote config", "err", err)
+				continue
 			}
 		}
+
+		if reason != "" {
+			level.Warn(ep.log).Log("msg", "canary remote config is unhealthy, restoring the previous remote config", "hash", canary.ConfigHash, "reason", reason)
+			if err := config.FailRemoteConfigCanary(&cfg, ep.poller, ep.log, reason); err != nil {
+				level.Error(ep.log).Log("msg", "failed to restore the previous remote config", "err", err)
+				continue
+			}
+			baseline = nil
+			if !ep.TriggerReload() {
+				level.Error(ep.log).Log("msg", "restored the previous remote config, but the config failed to reload")
+			}
+			continue
+		}
+
+		if time.Now().After(canary.Deadline) {
+			if err := config.PromoteRemoteConfigCanary(&cfg, ep.poller, ep.log); err != nil {
+				level.Error(ep.log).Log("msg", "failed to keep canary remote config", "err", err)
+				continue
+			}
+			level.Info(ep.log).Log("msg", "canary remote config stayed healthy, keeping it", "hash", canary.ConfigHash)
+			baseline = nil
+		}
+	}
+}
+
+// remoteConfigSleepTime returns the duration to wait before the next fetch
+// of the remote config, logging when the circuit breaker opens or closes.
+func remoteConfigSleepTime(l log.Logger, poller *config.RemoteConfigPoller, am *config.AgentManagementConfig) time.Duration {
+	wasOpen := poller.CircuitBreakerOpen()
+	sleepTime := poller.SleepTime(am)
+
+	switch open := poller.CircuitBreakerOpen(); {
+	case open && !wasOpen:
+		level.Warn(l).Log("msg", "fetching the remote config failed repeatedly, retrying at the circuit breaker interval", "interval", am.CircuitBreakerInterval)
+	case !open && wasOpen:
+		level.Info(l).Log("msg", "fetching the remote config succeeded, resuming the polling interval", "interval", am.PollingInterval)
+	}
+	return sleepTime
+}
+
+// heartbeat registers the agent with the Agent Management API and then sends
+// heartbeats with the identity of the agent every HeartbeatTime until the
+// context completes. Failures are logged, but otherwise ignored.
+func (ep *Entrypoint) heartbeat(ctx context.Context) error {
+	event := config.RegistrationEventRegister
+
+	for {
+		ep.mut.Lock()
+		cfg := ep.cfg
+		ep.mut.Unlock()
+
+		if err := config.RegisterAgent(&cfg, ep.poller, event); err != nil {
+			level.Warn(ep.log).Log("msg", "could not register agent", "event", event, "err", err)
+		} else {
+			event = config.RegistrationEventHeartbeat
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(cfg.AgentManagement.HeartbeatTime()):
+		}
 	}
 }
 
@@ -424,12 +765,24 @@ func (ep *Entrypoint) Start() error {
 		managementContext, managementCancel := context.WithCancel(context.Background())
 		defer managementCancel()
 
-		sleepTime := ep.cfg.AgentManagement.SleepTime()
 		g.Add(func() error {
-			return ep.pollConfig(managementContext, sleepTime)
+			return ep.pollConfig(managementContext)
 		}, func(e error) {
 			managementCancel()
 		})
+		g.Add(func() error {
+			return ep.watchCanary(managementContext)
+		}, func(e error) {
+			managementCancel()
+		})
+
+		if ep.cfg.AgentManagement.RegistrationUrl != "" {
+			g.Add(func() error {
+				return ep.heartbeat(managementContext)
+			}, func(e error) {
+				managementCancel()
+			})
+		}
 	}
 
 	srvContext, srvCancel := context.WithCancel(context.Background())
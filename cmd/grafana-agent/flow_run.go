@@ -2,15 +2,19 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path"
+	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/grafana/agent/web/api"
 	"github.com/grafana/agent/web/ui"
@@ -18,13 +22,19 @@ import (
 	"golang.org/x/exp/maps"
 
 	"github.com/fatih/color"
+	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/gorilla/mux"
+	"github.com/grafana/agent/component"
+	flowprometheus "github.com/grafana/agent/component/prometheus"
+	"github.com/grafana/agent/pkg/config"
 	"github.com/grafana/agent/pkg/config/instrumentation"
 	"github.com/grafana/agent/pkg/flow"
 	"github.com/grafana/agent/pkg/flow/logging"
 	"github.com/grafana/agent/pkg/flow/tracing"
+	"github.com/grafana/agent/pkg/handoff"
 	"github.com/grafana/agent/pkg/river/diag"
+	"github.com/grafana/agent/pkg/runtimelimits"
 	"github.com/grafana/agent/pkg/usagestats"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -41,10 +51,12 @@ func runCommand() *cobra.Command {
 		storagePath:      "data-agent/",
 		uiPrefix:         "/",
 		disableReporting: false,
+		runtimeLimits:    runtimelimits.DefaultOptions,
+		seriesRefs:       flowprometheus.DefaultRefMapOptions,
 	}
 
 	cmd := &cobra.Command{
-		Use:   "run [flags] file",
+		Use:   "run [flags] [file]",
 		Short: "Run Grafana Agent Flow",
 		Long: `The run subcommand runs Grafana Agent Flow in the foreground until an interrupt
 is received.
@@ -65,15 +77,38 @@ Additionally, the HTTP server exposes the following debug endpoints:
 
   /debug/pprof   Go performance profiling tools
 
+Individual components can be restarted or re-evaluated without reloading the
+config file by sending a POST request to
+/api/v0/web/components/<id>/restart or /api/v0/web/components/<id>/reevaluate,
+relative to --server.http.ui-path-prefix. These endpoints are only enabled when
+--server.http.admin-token-file is set, and requests must send the token from
+that file as a bearer token.
+
+Instead of a River file, run may be given a YAML file with an agent_management
+block through --agent-management.config-file. The River config is then fetched
+from the Agent Management API, cached, and polled for changes according to the
+block's polling settings.
+
 If reloading the config file fails, Grafana Agent Flow will continue running in
 its last valid state. Components which failed may be be listed as unhealthy,
-depending on the nature of the reload error.
+depending on the nature of the reload error. When --config.rollback-on-error
+is set, all components are instead returned to the previously loaded config
+file if any of them fail to evaluate.
+
+When --upgrade-handoff is set, run takes over the HTTP listener and the
+storage directory of an agent running with the same --storage.path and
+--upgrade-handoff, which then exits. Connections made during the handoff wait
+in the backlog of the listener until the new agent is ready to serve them.
 `,
-		Args:         cobra.ExactArgs(1),
+		Args:         cobra.MaximumNArgs(1),
 		SilenceUsage: true,
 
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return r.Run(args[0])
+			var configFile string
+			if len(args) > 0 {
+				configFile = args[0]
+			}
+			return r.Run(configFile)
 		},
 	}
 
@@ -81,8 +116,24 @@ depending on the nature of the reload error.
 		StringVar(&r.httpListenAddr, "server.http.listen-addr", r.httpListenAddr, "address to listen for HTTP traffic on")
 	cmd.Flags().StringVar(&r.storagePath, "storage.path", r.storagePath, "Base directory where components can store data")
 	cmd.Flags().StringVar(&r.uiPrefix, "server.http.ui-path-prefix", r.uiPrefix, "Prefix to serve the HTTP UI at")
+	cmd.Flags().
+		StringVar(&r.adminTokenFile, "server.http.admin-token-file", r.adminTokenFile, "Path to a file containing the bearer token required by administrative HTTP endpoints. Administrative endpoints are disabled if unset")
 	cmd.Flags().
 		BoolVar(&r.disableReporting, "disable-reporting", r.disableReporting, "Disable reporting of enabled components to Grafana.")
+	cmd.Flags().
+		BoolVar(&r.rollbackOnError, "config.rollback-on-error", r.rollbackOnError, "Return to the previously loaded config if reloading the config file fails")
+	cmd.Flags().
+		StringVar(&r.agentManagementFile, "agent-management.config-file", r.agentManagementFile, "Path to a YAML file with an agent_management block to fetch the River config from the Agent Management API with, instead of reading it from a file")
+	cmd.Flags().
+		DurationVar(&r.seriesRefs.IdleTimeout, "prometheus.series-refs.idle-timeout", r.seriesRefs.IdleTimeout, "How long a series may go unused before its cached references are evicted. 0 disables eviction of idle series.")
+	cmd.Flags().
+		BoolVar(&r.upgradeHandoff, "upgrade-handoff", r.upgradeHandoff, "Take over the HTTP listener and storage directory from an agent running with the same --storage.path, and offer them to the next agent started with this flag")
+	cmd.Flags().
+		IntVar(&r.seriesRefs.MaxSeries, "prometheus.series-refs.max-series", r.seriesRefs.MaxSeries, "Maximum number of series to cache references for, evicting the least recently used series first. 0 means no limit.")
+
+	runtimeFlags := flag.NewFlagSet("runtime", flag.ContinueOnError)
+	r.runtimeLimits.RegisterFlags(runtimeFlags)
+	cmd.Flags().AddGoFlagSet(runtimeFlags)
 	return cmd
 }
 
@@ -90,7 +141,14 @@ type flowRun struct {
 	httpListenAddr   string
 	storagePath      string
 	uiPrefix         string
+	adminTokenFile   string
 	disableReporting bool
+	runtimeLimits    runtimelimits.Options
+	seriesRefs       flowprometheus.RefMapOptions
+	rollbackOnError  bool
+
+	agentManagementFile string
+	upgradeHandoff      bool
 }
 
 func (fr *flowRun) Run(configFile string) error {
@@ -100,8 +158,25 @@ func (fr *flowRun) Run(configFile string) error {
 	ctx, cancel := interruptContext()
 	defer cancel()
 
-	if configFile == "" {
+	if configFile == "" && fr.agentManagementFile == "" {
 		return fmt.Errorf("file argument not provided")
+	} else if configFile != "" && fr.agentManagementFile != "" {
+		return fmt.Errorf("file argument can not be used with --agent-management.config-file")
+	}
+	if fr.seriesRefs.IdleTimeout < 0 || fr.seriesRefs.MaxSeries < 0 {
+		return fmt.Errorf("--prometheus.series-refs.idle-timeout and --prometheus.series-refs.max-series must not be negative")
+	}
+
+	var adminToken string
+	if fr.adminTokenFile != "" {
+		bb, err := os.ReadFile(fr.adminTokenFile)
+		if err != nil {
+			return fmt.Errorf("reading admin token file: %w", err)
+		}
+		adminToken = strings.TrimSpace(string(bb))
+		if adminToken == "" {
+			return fmt.Errorf("admin token file %q is empty", fr.adminTokenFile)
+		}
 	}
 
 	logSink, err := logging.WriterSink(os.Stderr, logging.DefaultSinkOptions)
@@ -110,6 +185,48 @@ func (fr *flowRun) Run(configFile string) error {
 	}
 	l := logging.New(logSink)
 
+	var (
+		agentManagement *config.AgentManagementConfig
+		// remoteConfigPoller holds the state of polling the remote config when
+		// it's fetched from the Agent Management API.
+		remoteConfigPoller = config.NewRemoteConfigPoller()
+		// remoteConfigBytes is the remote config which was last read, used to
+		// print diagnostics for it.
+		remoteConfigBytes []byte
+	)
+	if fr.agentManagementFile != "" {
+		agentManagement, err = config.LoadAgentManagementFile(fr.agentManagementFile)
+		if err != nil {
+			return fmt.Errorf("loading agent management config: %w", err)
+		}
+	}
+
+	// With --upgrade-handoff, the listener and storage directory are taken
+	// over from a running agent. The config file is checked first, so that
+	// the running agent isn't stopped for a config which can't be loaded.
+	var (
+		httpListener net.Listener
+		dataLock     *handoff.Lock
+		takenOver    bool
+	)
+	if fr.upgradeHandoff {
+		if configFile != "" {
+			if _, err := loadFlowFile(configFile); err != nil {
+				return fmt.Errorf("reading config file %q: %w", configFile, err)
+			}
+		}
+
+		httpListener, dataLock, takenOver, err = fr.takeOver(ctx, l)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = dataLock.Release() }()
+	}
+
+	// refreshCh requests the remote config to be fetched without waiting for
+	// the polling interval.
+	refreshCh := make(chan struct{}, 1)
+
 	t, err := tracing.New(tracing.DefaultOptions)
 	if err != nil {
 		return fmt.Errorf("building tracer: %w", err)
@@ -137,6 +254,11 @@ func (fr *flowRun) Run(configFile string) error {
 	// metrics are still exposed.
 	reg := prometheus.DefaultRegisterer
 	reg.MustRegister(newResourcesCollector(l))
+	reg.MustRegister(flowprometheus.GlobalRefMapping)
+
+	if _, err := runtimelimits.Apply(l, reg, fr.runtimeLimits); err != nil {
+		return fmt.Errorf("applying runtime limits: %w", err)
+	}
 
 	f := flow.New(flow.Options{
 		LogSink:        logSink,
@@ -145,37 +267,82 @@ func (fr *flowRun) Run(configFile string) error {
 		Reg:            reg,
 		HTTPPathPrefix: "/api/v0/component/",
 		HTTPListenAddr: fr.httpListenAddr,
-	})
 
-	reload := func() error {
-		flowCfg, err := loadFlowFile(configFile)
-		defer instrumentation.InstrumentLoad(err == nil)
+		RollbackOnError: fr.rollbackOnError,
+	})
 
-		if err != nil {
-			return fmt.Errorf("reading config file %q: %w", configFile, err)
+	reload := func() (err error) {
+		defer func() { instrumentation.InstrumentLoad(err == nil) }()
+
+		var flowCfg *flow.File
+		if agentManagement != nil {
+			flowCfg, remoteConfigBytes, err = loadRemoteFlowFile(agentManagement, remoteConfigPoller, l)
+			if errors.Is(err, config.ErrRemoteConfigNotModified) {
+				level.Debug(l).Log("msg", "remote config unchanged, skipping reload")
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("loading remote config: %w", err)
+			}
+		} else {
+			flowCfg, err = loadFlowFile(configFile)
+			if err != nil {
+				return fmt.Errorf("reading config file %q: %w", configFile, err)
+			}
+		}
+		err = f.LoadFile(flowCfg, nil)
+		if agentManagement != nil {
+			if restoreErr := remoteConfigPoller.RemoteConfigApplied(err); restoreErr != nil {
+				level.Warn(l).Log("msg", "could not restore the cached remote config", "err", restoreErr)
+			}
 		}
-		if err := f.LoadFile(flowCfg, nil); err != nil {
+		if err != nil {
+			var rolledBack *flow.RolledBackError
+			if errors.As(err, &rolledBack) {
+				instrumentation.InstrumentRollback()
+			}
 			return fmt.Errorf("error during the initial gragent load: %w", err)
 		}
 
 		return nil
 	}
 
-	// Flow controller
+	// Evict series references which are no longer used by components.
 	{
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			f.Run(ctx)
+			flowprometheus.GlobalRefMapping.Run(ctx, fr.seriesRefs)
 		}()
 	}
 
-	// HTTP server
+	// Flow controller. It's stopped separately from the rest of the agent when
+	// its state is handed over to a new agent.
+	flowCtx, stopFlow := context.WithCancel(ctx)
+	defer stopFlow()
+	flowDone := make(chan struct{})
 	{
-		lis, err := net.Listen("tcp", fr.httpListenAddr)
-		if err != nil {
-			return fmt.Errorf("failed to listen on %s: %w", fr.httpListenAddr, err)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(flowDone)
+			f.Run(flowCtx)
+		}()
+	}
+
+	// HTTP server. After a handoff, it only starts serving once the initial
+	// load is done, so that connections waiting in the backlog aren't served
+	// before components are running.
+	serveHTTP := make(chan struct{})
+	var srv *http.Server
+	{
+		lis := httpListener
+		if lis == nil {
+			lis, err = net.Listen("tcp", fr.httpListenAddr)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", fr.httpListenAddr, err)
+			}
 		}
+		httpListener = lis
 
 		r := mux.NewRouter()
 		r.Use(otelmux.Middleware(
@@ -197,6 +364,41 @@ func (fr *flowRun) Run(configFile string) error {
 			}
 		})
 
+		r.HandleFunc("/-/healthy", func(w http.ResponseWriter, _ *http.Request) {
+			if firing := firingHealthRules(f); len(firing) > 0 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprintf(w, "Health rules are firing: %s\n", strings.Join(firing, ", "))
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, "Agent is Healthy.\n")
+		})
+
+		r.HandleFunc("/agent/api/v1/remote-config", func(w http.ResponseWriter, _ *http.Request) {
+			if agentManagement == nil {
+				http.Error(w, "agent management is disabled", http.StatusNotFound)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(config.GetRemoteConfigState(remoteConfigPoller))
+		}).Methods(http.MethodGet)
+
+		r.HandleFunc("/-/remote-config/refresh", func(w http.ResponseWriter, _ *http.Request) {
+			if agentManagement == nil {
+				http.Error(w, "agent management is disabled", http.StatusNotFound)
+				return
+			}
+
+			level.Info(l).Log("msg", "remote config refresh requested")
+			select {
+			case refreshCh <- struct{}{}:
+			default:
+				// A refresh is already pending.
+			}
+			w.WriteHeader(http.StatusOK)
+		}).Methods(http.MethodPost)
+
 		r.HandleFunc("/-/reload", func(w http.ResponseWriter, _ *http.Request) {
 			level.Info(l).Log("msg", "reload requested via /-/reload endpoint")
 			defer level.Info(l).Log("msg", "config reloaded")
@@ -210,20 +412,30 @@ func (fr *flowRun) Run(configFile string) error {
 		}).Methods(http.MethodGet, http.MethodPost)
 
 		// Register Routes must be the last
-		fa := api.NewFlowAPI(f, r)
+		fa := api.NewFlowAPI(f, logSink, r, adminToken)
+		if agentManagement != nil {
+			fa.AddSecretFile(agentManagement.CacheLocation, "cached remote configs from the Agent Management API")
+		}
 		fa.RegisterRoutes(path.Join(fr.uiPrefix, "/api/v0/web"), r)
 
 		// NOTE(rfratto): keep this at the bottom of all other routes, otherwise it
 		// will take precedence over anything else mapped in uiPrefix.
 		ui.RegisterRoutes(fr.uiPrefix, r)
 
-		srv := &http.Server{Handler: r}
+		srv = &http.Server{Handler: r}
 
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			defer cancel()
 
+			select {
+			case <-serveHTTP:
+			case <-ctx.Done():
+				_ = lis.Close()
+				return
+			}
+
 			level.Info(l).Log("msg", "now listening for http traffic", "addr", fr.httpListenAddr)
 			if err := srv.Serve(lis); err != nil {
 				level.Info(l).Log("msg", "http server closed", "err", err)
@@ -232,6 +444,9 @@ func (fr *flowRun) Run(configFile string) error {
 
 		defer func() { _ = srv.Shutdown(ctx) }()
 	}
+	if !takenOver {
+		close(serveHTTP)
+	}
 
 	// Report usage of enabled components
 	if !fr.disableReporting {
@@ -253,14 +468,18 @@ func (fr *flowRun) Run(configFile string) error {
 	if err := reload(); err != nil {
 		var diags diag.Diagnostics
 		if errors.As(err, &diags) {
-			bb, _ := os.ReadFile(configFile)
+			name, bb := remoteConfigFilename, remoteConfigBytes
+			if agentManagement == nil {
+				name = configFile
+				bb, _ = os.ReadFile(configFile)
+			}
 
 			p := diag.NewPrinter(diag.PrinterConfig{
 				Color:              !color.NoColor,
 				ContextLinesBefore: 1,
 				ContextLinesAfter:  1,
 			})
-			_ = p.Fprint(os.Stderr, map[string][]byte{configFile: bb}, diags)
+			_ = p.Fprint(os.Stderr, map[string][]byte{name: bb}, diags)
 
 			// Print newline after the diagnostics.
 			fmt.Println()
@@ -271,11 +490,41 @@ func (fr *flowRun) Run(configFile string) error {
 		// Exit if the initial load files
 		return err
 	}
+	if takenOver {
+		close(serveHTTP)
+	}
+
+	if fr.upgradeHandoff {
+		err := fr.serveHandoffs(ctx, l, httpListener, func() error {
+			// Stop accepting connections, which now wait in the backlog of the
+			// listener of the new agent, and stop components so that they're
+			// done writing to the storage directory.
+			shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), handoffShutdownTimeout)
+			defer cancelShutdown()
+			if err := srv.Shutdown(shutdownCtx); err != nil {
+				level.Warn(l).Log("msg", "failed to shut down http server gracefully", "err", err)
+			}
+			stopFlow()
+			<-flowDone
+			return dataLock.Release()
+		}, cancel)
+		if err != nil {
+			return err
+		}
+	}
 
 	reloadSignal := make(chan os.Signal, 1)
 	signal.Notify(reloadSignal, syscall.SIGHUP)
 	defer signal.Stop(reloadSignal)
 
+	// The remote config is polled for changes when it's fetched from the
+	// Agent Management API, or right away when a refresh is requested through
+	// refreshCh. pollCh is nil otherwise, so it never fires.
+	var pollCh <-chan time.Time
+	if agentManagement != nil {
+		pollCh = time.After(remoteConfigSleepTime(l, remoteConfigPoller, agentManagement))
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -286,10 +535,134 @@ func (fr *flowRun) Run(configFile string) error {
 			} else {
 				level.Info(l).Log("msg", "config reloaded")
 			}
+		case <-refreshCh:
+			if err := reload(); err != nil {
+				level.Error(l).Log("msg", "failed to reload remote config", "err", err)
+			}
+			pollCh = time.After(remoteConfigSleepTime(l, remoteConfigPoller, agentManagement))
+		case <-pollCh:
+			if err := reload(); err != nil {
+				level.Error(l).Log("msg", "failed to reload remote config", "err", err)
+			}
+			pollCh = time.After(remoteConfigSleepTime(l, remoteConfigPoller, agentManagement))
 		}
 	}
 }
 
+// Timeouts of upgrade handoffs.
+const (
+	// handoffReleaseTimeout is how long a new agent waits for the running
+	// agent to release its state.
+	handoffReleaseTimeout = 5 * time.Minute
+	// handoffShutdownTimeout is how long the running agent waits for
+	// in-flight HTTP requests before stopping its components.
+	handoffShutdownTimeout = 30 * time.Second
+)
+
+// httpListenerName is the name the HTTP listener is handed over under.
+const httpListenerName = "http"
+
+// takeOver takes the HTTP listener and the storage directory over from the
+// agent serving handoffs for --storage.path, if there is one. lis is nil if
+// the listener wasn't taken over. The storage directory is locked either way.
+func (fr *flowRun) takeOver(ctx context.Context, l log.Logger) (lis net.Listener, lock *handoff.Lock, takenOver bool, err error) {
+	if err := os.MkdirAll(fr.storagePath, 0770); err != nil {
+		return nil, nil, false, fmt.Errorf("creating storage directory: %w", err)
+	}
+
+	h, err := handoff.Take(ctx, handoff.SocketPath(fr.storagePath))
+	switch {
+	case errors.Is(err, handoff.ErrNoServer):
+		level.Info(l).Log("msg", "no running agent to take over from")
+	case err != nil:
+		return nil, nil, false, fmt.Errorf("taking over from running agent: %w", err)
+	default:
+		defer h.Close()
+		level.Info(l).Log("msg", "taking over from running agent")
+
+		for name, hl := range h.Listeners {
+			if name == httpListenerName && sameListenAddr(hl.Addr(), fr.httpListenAddr) {
+				lis = hl
+			} else {
+				// The listener isn't used by this agent, such as when
+				// --server.http.listen-addr changed.
+				_ = hl.Close()
+			}
+		}
+
+		releaseCtx, cancel := context.WithTimeout(ctx, handoffReleaseTimeout)
+		defer cancel()
+		if err := h.Release(releaseCtx); err != nil {
+			if lis != nil {
+				_ = lis.Close()
+			}
+			return nil, nil, false, fmt.Errorf("taking over from running agent: %w", err)
+		}
+		takenOver = true
+	}
+
+	lock, err = handoff.AcquireLock(fr.storagePath)
+	if err != nil {
+		if lis != nil {
+			_ = lis.Close()
+		}
+		return nil, nil, false, err
+	}
+	return lis, lock, takenOver, nil
+}
+
+// serveHandoffs offers the HTTP listener and the storage directory to the
+// next agent started with --upgrade-handoff. release is called once the new
+// agent asks for the state, after which the agent is stopped through stop.
+func (fr *flowRun) serveHandoffs(ctx context.Context, l log.Logger, lis net.Listener, release func() error, stop func()) error {
+	hs, err := handoff.NewServer(handoff.SocketPath(fr.storagePath), map[string]net.Listener{httpListenerName: lis}, l)
+	if err != nil {
+		return fmt.Errorf("serving upgrade handoffs: %w", err)
+	}
+
+	go func() {
+		err := hs.Serve(ctx, release)
+		if errors.Is(err, context.Canceled) {
+			return
+		} else if err != nil {
+			level.Error(l).Log("msg", "failed to hand over to new agent", "err", err)
+		} else {
+			level.Info(l).Log("msg", "handed over to new agent, exiting")
+		}
+		stop()
+	}()
+	return nil
+}
+
+// sameListenAddr returns true if addr is the address listened on for the
+// listen address listenAddr.
+func sameListenAddr(addr net.Addr, listenAddr string) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	want, err := net.ResolveTCPAddr("tcp", listenAddr)
+	if err != nil || want.Port != tcpAddr.Port {
+		return false
+	}
+	if len(want.IP) == 0 || want.IP.IsUnspecified() {
+		return len(tcpAddr.IP) == 0 || tcpAddr.IP.IsUnspecified()
+	}
+	return want.IP.Equal(tcpAddr.IP)
+}
+
+// firingHealthRules returns the IDs of the health.rule components which are
+// firing.
+func firingHealthRules(f *flow.Flow) []string {
+	var firing []string
+	for _, info := range f.ComponentInfos() {
+		if info.Name == "health.rule" && info.Health != nil && info.Health.State == component.HealthTypeUnhealthy.String() {
+			firing = append(firing, info.ID)
+		}
+	}
+	return firing
+}
+
 // getEnabledComponentsFunc returns a function that gets the current enabled components
 func getEnabledComponentsFunc(f *flow.Flow) func() map[string]interface{} {
 	return func() map[string]interface{} {
@@ -313,6 +686,33 @@ func loadFlowFile(filename string) (*flow.File, error) {
 	return flow.ReadFile(filename, bb)
 }
 
+// remoteConfigFilename is the name River configs fetched from the Agent
+// Management API are reported under in diagnostics.
+const remoteConfigFilename = "remote-config.river"
+
+// loadRemoteFlowFile fetches the River config from the Agent Management API
+// configured by am, falling back to the cached config if needed. It also
+// returns the config which was read, for printing diagnostics.
+func loadRemoteFlowFile(am *config.AgentManagementConfig, poller *config.RemoteConfigPoller, l log.Logger) (*flow.File, []byte, error) {
+	var (
+		file *flow.File
+		// read is the config last passed to validation, so diagnostics can be
+		// printed for it even when it fails to parse.
+		read []byte
+	)
+	bb, err := config.GetFlowRemoteConfig(am, poller, func(bb []byte) (err error) {
+		read = bb
+		file, err = flow.ReadFile(remoteConfigFilename, bb)
+		return err
+	}, l)
+	if err != nil {
+		return nil, read, err
+	}
+
+	instrumentation.InstrumentConfig(bb)
+	return file, bb, nil
+}
+
 func interruptContext() (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithCancel(context.Background())
 
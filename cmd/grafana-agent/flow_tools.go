@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grafana/agent/component/common/deadletter"
+	flow_relabel "github.com/grafana/agent/component/common/relabel"
+	"github.com/grafana/agent/pkg/agentctl"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/spf13/cobra"
+)
+
+func toolsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tools",
+		Short: "Utilities for debugging Grafana Agent Flow",
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Usage()
+		},
+	}
+
+	cmd.AddCommand(
+		toolsScrapeCommand(),
+		toolsDeadLetterCommand(),
+	)
+	return cmd
+}
+
+func toolsScrapeCommand() *cobra.Command {
+	ts := &toolsScrape{
+		agentAddr:     "http://127.0.0.1:12345",
+		uiPrefix:      "/",
+		opts:          agentctl.DefaultScrapeDebugOptions,
+		maxSamples:    50,
+		extraLabels:   map[string]string{},
+		metricsPath:   agentctl.DefaultScrapeDebugOptions.MetricsPath,
+		scrapeTimeout: agentctl.DefaultScrapeDebugOptions.ScrapeTimeout,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "scrape [flags]",
+		Short: "Perform a one-off scrape of a target for debugging",
+		Long: `The scrape subcommand performs a single scrape of a target and prints the
+result of each step of the scrape pipeline: the discovered labels of the target,
+the labels after target relabeling, the URL which was scraped, and the samples
+which were parsed from the response along with the result of metric relabeling.
+
+The target to scrape is provided either by --target, which accepts an address
+(host:port) or a full URL, or by --component, which scrapes every active target
+of a running prometheus.scrape component retrieved from the agent at --addr.
+
+Relabeling rules are read from River files containing rule blocks, using the
+same syntax as discovery.relabel and prometheus.relabel. Rules passed via
+--relabel-file are applied to the target, and rules passed via
+--metric-relabel-file are applied to each scraped sample.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return ts.Run(cmd.Context())
+		},
+	}
+
+	cmd.Flags().StringVar(&ts.target, "target", ts.target, "Address or URL of the target to scrape")
+	cmd.Flags().StringVar(&ts.component, "component", ts.component, "ID of a running prometheus.scrape component whose targets should be scraped")
+	cmd.Flags().StringVar(&ts.agentAddr, "addr", ts.agentAddr, "Address of the running agent to query when --component is set")
+	cmd.Flags().StringVar(&ts.uiPrefix, "server.http.ui-path-prefix", ts.uiPrefix, "Path prefix of the agent's HTTP API when --component is set")
+	cmd.Flags().StringToStringVar(&ts.extraLabels, "label", ts.extraLabels, "Additional discovered labels for the target, in the form name=value")
+	cmd.Flags().StringVar(&ts.relabelFile, "relabel-file", ts.relabelFile, "River file with rule blocks to apply to the target before scraping")
+	cmd.Flags().StringVar(&ts.metricRelabelFile, "metric-relabel-file", ts.metricRelabelFile, "River file with rule blocks to apply to each scraped sample")
+	cmd.Flags().StringVar(&ts.metricsPath, "metrics-path", ts.metricsPath, "Default metrics path when --target is an address")
+	cmd.Flags().BoolVar(&ts.opts.HonorLabels, "honor-labels", ts.opts.HonorLabels, "Keep labels from the scraped data when they conflict with target labels")
+	cmd.Flags().DurationVar(&ts.scrapeTimeout, "scrape-timeout", ts.scrapeTimeout, "Timeout for the scrape")
+	cmd.Flags().IntVar(&ts.maxSamples, "max-samples", ts.maxSamples, "Maximum number of samples to print per target. 0 prints all samples")
+	return cmd
+}
+
+type toolsScrape struct {
+	target            string
+	component         string
+	agentAddr         string
+	uiPrefix          string
+	extraLabels       map[string]string
+	relabelFile       string
+	metricRelabelFile string
+	metricsPath       string
+	scrapeTimeout     time.Duration
+	maxSamples        int
+
+	opts agentctl.ScrapeDebugOptions
+}
+
+func (ts *toolsScrape) Run(ctx context.Context) error {
+	if (ts.target == "") == (ts.component == "") {
+		return fmt.Errorf("exactly one of --target or --component must be provided")
+	}
+
+	var err error
+	if ts.opts.RelabelConfigs, err = loadRelabelRules(ts.relabelFile); err != nil {
+		return err
+	}
+	if ts.opts.MetricRelabels, err = loadRelabelRules(ts.metricRelabelFile); err != nil {
+		return err
+	}
+	ts.opts.MetricsPath = ts.metricsPath
+	ts.opts.ScrapeTimeout = ts.scrapeTimeout
+
+	var targets []map[string]string
+	if ts.target != "" {
+		target, err := targetFromFlag(ts.target)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, target)
+	} else {
+		targets, err = ts.componentTargets(ctx)
+		if err != nil {
+			return err
+		}
+		if len(targets) == 0 {
+			return fmt.Errorf("component %q has no active targets", ts.component)
+		}
+		ts.opts.JobName = ts.component
+	}
+
+	var failed bool
+	for i, target := range targets {
+		for k, v := range ts.extraLabels {
+			target[k] = v
+		}
+
+		opts := ts.opts
+		opts.Target = target
+
+		res, err := agentctl.ScrapeDebug(ctx, opts)
+		if err != nil {
+			return err
+		}
+		if res.Err != nil {
+			failed = true
+		}
+
+		if i > 0 {
+			fmt.Println()
+		}
+		if err := res.Fprint(os.Stdout, ts.maxSamples); err != nil {
+			return err
+		}
+	}
+
+	if failed {
+		return fmt.Errorf("scrape failed")
+	}
+	return nil
+}
+
+// componentTargets retrieves the active targets of a prometheus.scrape
+// component from a running agent.
+func (ts *toolsScrape) componentTargets(ctx context.Context) ([]map[string]string, error) {
+	u, err := url.Parse(ts.agentAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid agent address: %w", err)
+	}
+	u = u.JoinPath(ts.uiPrefix, "/api/v0/web/components", ts.component)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("querying agent: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return agentctl.ComponentTargets(resp.Body)
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("component %q not found", ts.component)
+	default:
+		return nil, fmt.Errorf("querying agent: unexpected status %s", resp.Status)
+	}
+}
+
+// targetFromFlag converts the --target flag into a set of discovered labels.
+func targetFromFlag(target string) (map[string]string, error) {
+	if !strings.Contains(target, "://") {
+		return map[string]string{model.AddressLabel: target}, nil
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target URL: %w", err)
+	}
+
+	res := map[string]string{
+		model.AddressLabel: u.Host,
+		model.SchemeLabel:  u.Scheme,
+	}
+	if u.Path != "" {
+		res[model.MetricsPathLabel] = u.Path
+	}
+	for k, vv := range u.Query() {
+		if len(vv) > 0 {
+			res[model.ParamLabelPrefix+k] = vv[0]
+		}
+	}
+	return res, nil
+}
+
+// loadRelabelRules reads rule blocks from a River file.
+func loadRelabelRules(filename string) ([]*relabel.Config, error) {
+	if filename == "" {
+		return nil, nil
+	}
+
+	bb, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Rules []*flow_relabel.Config `river:"rule,block,optional"`
+	}
+	if err := river.Unmarshal(bb, &file); err != nil {
+		return nil, fmt.Errorf("reading relabel rules from %q: %w", filename, err)
+	}
+	return flow_relabel.ComponentToPromRelabelConfigs(file.Rules), nil
+}
+
+func toolsDeadLetterCommand() *cobra.Command {
+	var (
+		path    string
+		target  string
+		timeout = 30 * time.Second
+		headers = map[string]string{}
+	)
+
+	cmd := &cobra.Command{
+		Use:   "dead-letter",
+		Short: "Inspect and replay dead-lettered telemetry",
+		Long: `The dead-letter subcommand inspects and replays payloads written to a
+dead-letter spool by components such as loki.write after all retries to
+deliver them were exhausted.`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Usage()
+		},
+	}
+	cmd.PersistentFlags().StringVar(&path, "path", path, "Path of the dead-letter spool directory")
+
+	listCmd := &cobra.Command{
+		Use:          "list",
+		Short:        "List records in a dead-letter spool",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+
+		RunE: func(_ *cobra.Command, _ []string) error {
+			spool, err := openDeadLetterSpool(path)
+			if err != nil {
+				return err
+			}
+			recs, err := spool.List()
+			if err != nil {
+				return err
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "NAME\tTIMESTAMP\tURL\tREASON")
+			for _, rec := range recs {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", rec.Name, rec.Timestamp.Format(time.RFC3339), rec.URL, rec.Reason)
+			}
+			return tw.Flush()
+		},
+	}
+
+	replayCmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Send records in a dead-letter spool to their endpoint",
+		Long: `The replay subcommand sends each record in the spool, from oldest to newest,
+to the endpoint it was originally sent to, or to --url if provided. Records
+are removed from the spool after being successfully sent. Replaying stops at
+the first record which fails to be sent.
+
+Credentials are never written to the spool; use --header to provide any
+authentication headers required by the endpoint.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			spool, err := openDeadLetterSpool(path)
+			if err != nil {
+				return err
+			}
+
+			client := &http.Client{Timeout: timeout}
+			n, err := spool.Replay(func(rec deadletter.Record) error {
+				for k, v := range headers {
+					if rec.Headers == nil {
+						rec.Headers = make(map[string]string)
+					}
+					rec.Headers[k] = v
+				}
+				return deadletter.Post(cmd.Context(), client, target, rec)
+			})
+			fmt.Fprintf(os.Stdout, "replayed %d records\n", n)
+			return err
+		},
+	}
+	replayCmd.Flags().StringVar(&target, "url", target, "URL to send records to instead of their original endpoint")
+	replayCmd.Flags().StringToStringVar(&headers, "header", headers, "Additional request headers in the form name=value")
+	replayCmd.Flags().DurationVar(&timeout, "timeout", timeout, "Timeout for each request")
+
+	cmd.AddCommand(listCmd, replayCmd)
+	return cmd
+}
+
+func openDeadLetterSpool(path string) (*deadletter.Spool, error) {
+	if path == "" {
+		return nil, fmt.Errorf("--path must be provided")
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return deadletter.New(path, 0, "", nil)
+}
@@ -8,6 +8,7 @@ import (
 	util_log "github.com/cortexproject/cortex/pkg/util/log"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/agent/pkg/config"
+	"github.com/grafana/agent/pkg/runtimelimits"
 	"github.com/grafana/agent/pkg/server"
 
 	// Adds version information
@@ -55,9 +56,10 @@ func main() {
 	defaultCfg := server.DefaultConfig()
 	logger := server.NewLogger(&defaultCfg)
 
+	poller := config.NewRemoteConfigPoller()
 	reloader := func(log *server.Logger) (*config.Config, error) {
 		fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
-		return config.Load(fs, os.Args[1:], log)
+		return config.Load(fs, os.Args[1:], log, poller)
 	}
 	cfg, err := reloader(logger)
 	if err != nil {
@@ -68,7 +70,11 @@ func main() {
 	logger = server.NewLogger(cfg.Server)
 	util_log.Logger = logger
 
-	ep, err := NewEntrypoint(logger, cfg, reloader)
+	if _, err := runtimelimits.Apply(logger, prometheus.DefaultRegisterer, cfg.RuntimeLimits); err != nil {
+		level.Error(logger).Log("msg", "failed to apply runtime limits", "err", err)
+	}
+
+	ep, err := NewEntrypoint(logger, cfg, poller, reloader)
 	if err != nil {
 		level.Error(logger).Log("msg", "error creating the agent server entrypoint", "err", err)
 		os.Exit(1)
@@ -33,9 +33,10 @@ func (m *AgentService) Execute(args []string, serviceRequests <-chan svc.ChangeR
 	defaultServerCfg := server.DefaultConfig()
 	logger := server.NewWindowsEventLogger(&defaultServerCfg)
 
+	poller := config.NewRemoteConfigPoller()
 	reloader := func(log *server.Logger) (*config.Config, error) {
 		fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
-		return config.Load(fs, os.Args[1:], log)
+		return config.Load(fs, os.Args[1:], log, poller)
 	}
 	cfg, err := reloader(logger)
 	if err != nil {
@@ -56,7 +57,7 @@ func (m *AgentService) Execute(args []string, serviceRequests <-chan svc.ChangeR
 	// Kick off the server in the background so that we can respond to status queries
 	var ep *Entrypoint
 	go func() {
-		ep, err = NewEntrypoint(logger, cfg, reloader)
+		ep, err = NewEntrypoint(logger, cfg, poller, reloader)
 		if err != nil {
 			level.Error(logger).Log("msg", "error creating the agent server entrypoint", "err", err)
 			os.Exit(1)
@@ -72,6 +72,9 @@ func main() {
 		cloudConfigCmd(),
 		templateDryRunCmd(),
 		testLogs(),
+		remoteConfigVersionsCmd(),
+		remoteConfigRollbackCmd(),
+		remoteConfigRefreshCmd(),
 	)
 
 	_ = cmd.Execute()
@@ -121,6 +124,96 @@ source-of-truth directory.`,
 	return cmd
 }
 
+func remoteConfigVersionsCmd() *cobra.Command {
+	var agentAddr string
+
+	cmd := &cobra.Command{
+		Use:   "remote-config-versions",
+		Short: "List the cached versions of an Agent's remote config",
+		Long: `remote-config-versions lists the versions of the remote config which an Agent
+using Agent Management keeps in its cache history, newest version first. The
+current version is marked with an asterisk.`,
+		Args: cobra.NoArgs,
+
+		Run: func(_ *cobra.Command, _ []string) {
+			cli := client.New(agentAddr)
+			versions, err := cli.RemoteConfigVersions(context.Background())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to list remote config versions: %s\n", err)
+				os.Exit(1)
+			}
+
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"", "Version", "Cached at", "Config hash"})
+			table.SetBorder(false)
+			for _, v := range versions {
+				current := ""
+				if v.Current {
+					current = "*"
+				}
+				table.Append([]string{current, v.Version, v.CachedAt.Format(time.RFC3339), v.ConfigHash})
+			}
+			table.Render()
+		},
+	}
+
+	cmd.Flags().StringVarP(&agentAddr, "addr", "a", "http://localhost:12345", "address of the agent to connect to")
+	return cmd
+}
+
+func remoteConfigRollbackCmd() *cobra.Command {
+	var agentAddr string
+
+	cmd := &cobra.Command{
+		Use:   "remote-config-rollback [version]",
+		Short: "Roll an Agent's remote config back to a cached version",
+		Long: `remote-config-rollback rolls the remote config of an Agent using Agent
+Management back to a version from its cache history and reloads the Agent's
+config. Versions are listed by remote-config-versions.
+
+The Agent keeps running the rolled back version until the Agent Management API
+serves a remote config other than the one which was rolled back from.`,
+		Args: cobra.ExactArgs(1),
+
+		Run: func(_ *cobra.Command, args []string) {
+			cli := client.New(agentAddr)
+			if err := cli.RollbackRemoteConfig(context.Background(), args[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to roll back remote config: %s\n", err)
+				os.Exit(1)
+			}
+			fmt.Fprintf(os.Stdout, "rolled back remote config to version %s\n", args[0])
+		},
+	}
+
+	cmd.Flags().StringVarP(&agentAddr, "addr", "a", "http://localhost:12345", "address of the agent to connect to")
+	return cmd
+}
+
+func remoteConfigRefreshCmd() *cobra.Command {
+	var agentAddr string
+
+	cmd := &cobra.Command{
+		Use:   "remote-config-refresh",
+		Short: "Make an Agent fetch its remote config right away",
+		Long: `remote-config-refresh requests an Agent using Agent Management to fetch its
+remote config without waiting for the polling interval. The config is fetched
+and reloaded in the background; the Agent's logs report whether it changed.`,
+		Args: cobra.NoArgs,
+
+		Run: func(_ *cobra.Command, _ []string) {
+			cli := client.New(agentAddr)
+			if err := cli.RefreshRemoteConfig(context.Background()); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to refresh remote config: %s\n", err)
+				os.Exit(1)
+			}
+			fmt.Fprintln(os.Stdout, "remote config refresh requested")
+		},
+	}
+
+	cmd.Flags().StringVarP(&agentAddr, "addr", "a", "http://localhost:12345", "address of the agent to connect to")
+	return cmd
+}
+
 func configCheckCmd() *cobra.Command {
 	var expandEnv bool
 
@@ -0,0 +1,78 @@
+package deadletter
+
+import "github.com/prometheus/client_golang/prometheus"
+
+type metrics struct {
+	written      prometheus.Counter
+	writtenBytes prometheus.Counter
+	evicted      prometheus.Counter
+	discarded    prometheus.Counter
+	replayed     prometheus.Counter
+	size         prometheus.Gauge
+	records      prometheus.Gauge
+}
+
+func newMetrics(namespace string) *metrics {
+	return &metrics{
+		written: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dead_letter_records_written_total",
+			Help:      "Total number of undeliverable payloads written to the dead-letter spool.",
+		}),
+		writtenBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dead_letter_written_bytes_total",
+			Help:      "Total number of payload bytes written to the dead-letter spool.",
+		}),
+		evicted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dead_letter_records_evicted_total",
+			Help:      "Total number of records evicted from the dead-letter spool to stay within its maximum size.",
+		}),
+		discarded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dead_letter_records_discarded_total",
+			Help:      "Total number of payloads which were too large to be written to the dead-letter spool.",
+		}),
+		replayed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dead_letter_records_replayed_total",
+			Help:      "Total number of records successfully replayed from the dead-letter spool.",
+		}),
+		size: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "dead_letter_size_bytes",
+			Help:      "Current size of the dead-letter spool in bytes.",
+		}),
+		records: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "dead_letter_records",
+			Help:      "Current number of records in the dead-letter spool.",
+		}),
+	}
+}
+
+func (m *metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.written, m.writtenBytes, m.evicted, m.discarded, m.replayed, m.size, m.records,
+	}
+}
+
+func (m *metrics) register(reg prometheus.Registerer) error {
+	for i, c := range m.collectors() {
+		if err := reg.Register(c); err != nil {
+			// Don't leave the metrics partially registered.
+			for _, registered := range m.collectors()[:i] {
+				reg.Unregister(registered)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *metrics) unregister(reg prometheus.Registerer) {
+	for _, c := range m.collectors() {
+		reg.Unregister(c)
+	}
+}
@@ -0,0 +1,43 @@
+package deadletter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Post sends the payload of rec to url using client. If url is empty, the
+// original URL of the record is used. An error is returned if the request
+// fails or the server doesn't respond with a 2xx status code.
+func Post(ctx context.Context, client *http.Client, url string, rec Record) error {
+	if url == "" {
+		url = rec.URL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(rec.Payload))
+	if err != nil {
+		return err
+	}
+	for k, v := range rec.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		scanner := bufio.NewScanner(io.LimitReader(resp.Body, 1024))
+		line := ""
+		if scanner.Scan() {
+			line = scanner.Text()
+		}
+		return fmt.Errorf("server returned HTTP status %s: %s", resp.Status, line)
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+// Package deadletter implements a bounded on-disk spool for telemetry which
+// could not be delivered after all retries were exhausted.
+//
+// Each record in the spool holds the raw request payload which failed to be
+// delivered along with metadata needed to replay it, such as the endpoint URL
+// and request headers. When the spool exceeds its maximum size, the oldest
+// records are evicted first.
+package deadletter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// recordExt is the file extension used for spooled records.
+const recordExt = ".dlq"
+
+// Record is an undeliverable payload stored in the spool.
+type Record struct {
+	// Name of the record file within the spool directory.
+	Name string `json:"-"`
+
+	// URL is the endpoint the payload was originally sent to.
+	URL string `json:"url"`
+	// Headers are the request headers required to replay the payload.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Reason describes why the payload was undeliverable.
+	Reason string `json:"reason,omitempty"`
+	// Timestamp is the time the record was spooled.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Payload is the raw request body.
+	Payload []byte `json:"-"`
+}
+
+// Spool is a bounded directory of dead-lettered records. Spool is safe for
+// concurrent use.
+type Spool struct {
+	dir     string
+	maxSize int64
+	metrics *metrics
+
+	mut  sync.Mutex
+	size int64
+	last int64 // Last used record timestamp, to keep names unique and ordered.
+}
+
+// New opens or creates a spool in dir which holds at most maxSize bytes of
+// records. If maxSize is 0, the spool is unbounded. Metrics for the spool are
+// registered to reg, prefixed by namespace. reg may be nil.
+func New(dir string, maxSize int64, namespace string, reg prometheus.Registerer) (*Spool, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("creating dead-letter directory: %w", err)
+	}
+
+	s := &Spool{
+		dir:     dir,
+		maxSize: maxSize,
+		metrics: newMetrics(namespace),
+	}
+
+	names, err := s.names()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		fi, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		s.size += fi.Size()
+	}
+	s.metrics.size.Set(float64(s.size))
+	s.metrics.records.Set(float64(len(names)))
+
+	if reg != nil {
+		if err := s.metrics.register(reg); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// SetMaxSize changes the maximum size of the spool to maxSize, evicting the
+// oldest records if the spool exceeds it. If maxSize is 0, the spool is
+// unbounded.
+func (s *Spool) SetMaxSize(maxSize int64) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.maxSize = maxSize
+	return s.evictLocked(0)
+}
+
+// Dir returns the directory of the spool.
+func (s *Spool) Dir() string { return s.dir }
+
+// Write stores rec in the spool, evicting the oldest records if the spool
+// would exceed its maximum size. Records larger than the maximum size of the
+// spool are discarded.
+func (s *Spool) Write(rec Record) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	ts := time.Now()
+	if rec.Timestamp.IsZero() {
+		rec.Timestamp = ts
+	}
+
+	var buf bytes.Buffer
+	if err := encodeRecord(&buf, rec); err != nil {
+		return err
+	}
+
+	recordSize := int64(buf.Len())
+	if s.maxSize > 0 && recordSize > s.maxSize {
+		s.metrics.discarded.Inc()
+		return fmt.Errorf("record of %d bytes exceeds maximum dead-letter size of %d bytes", recordSize, s.maxSize)
+	}
+	if err := s.evictLocked(recordSize); err != nil {
+		return err
+	}
+
+	// Names are derived from the spool time so that sorting names returns
+	// records from oldest to newest.
+	id := ts.UnixNano()
+	if id <= s.last {
+		id = s.last + 1
+	}
+	s.last = id
+	name := fmt.Sprintf("%020d%s", id, recordExt)
+
+	tmp := filepath.Join(s.dir, name+".tmp")
+	if err := os.WriteFile(tmp, buf.Bytes(), 0640); err != nil {
+		return fmt.Errorf("writing dead-letter record: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, name)); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("writing dead-letter record: %w", err)
+	}
+
+	s.size += recordSize
+	s.metrics.written.Inc()
+	s.metrics.writtenBytes.Add(float64(len(rec.Payload)))
+	s.metrics.size.Set(float64(s.size))
+	s.metrics.records.Inc()
+	return nil
+}
+
+// evictLocked removes the oldest records until incoming bytes can be stored
+// without exceeding the maximum size. s.mut must be held when calling.
+func (s *Spool) evictLocked(incoming int64) error {
+	if s.maxSize <= 0 || s.size+incoming <= s.maxSize {
+		return nil
+	}
+
+	names, err := s.names()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if s.size+incoming <= s.maxSize {
+			break
+		}
+		if err := s.removeLocked(name); err != nil {
+			return err
+		}
+		s.metrics.evicted.Inc()
+	}
+	return nil
+}
+
+func (s *Spool) removeLocked(name string) error {
+	path := filepath.Join(s.dir, name)
+	fi, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+
+	s.size -= fi.Size()
+	s.metrics.size.Set(float64(s.size))
+	s.metrics.records.Dec()
+	return nil
+}
+
+// List returns the records in the spool from oldest to newest without their
+// payloads.
+func (s *Spool) List() ([]Record, error) {
+	s.mut.Lock()
+	names, err := s.names()
+	s.mut.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Record, 0, len(names))
+	for _, name := range names {
+		rec, err := readRecord(filepath.Join(s.dir, name), false)
+		if errors.Is(err, os.ErrNotExist) {
+			// Evicted or replayed concurrently.
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		rec.Name = name
+		res = append(res, rec)
+	}
+	return res, nil
+}
+
+// Replay calls fn for each record in the spool from oldest to newest.
+// Records for which fn returns nil are removed from the spool. Replay stops at
+// the first error returned by fn, returning the number of replayed records
+// and the error.
+func (s *Spool) Replay(fn func(Record) error) (int, error) {
+	recs, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+
+	var replayed int
+	for _, meta := range recs {
+		rec, err := readRecord(filepath.Join(s.dir, meta.Name), true)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return replayed, err
+		}
+		rec.Name = meta.Name
+
+		if err := fn(rec); err != nil {
+			return replayed, fmt.Errorf("replaying %s: %w", rec.Name, err)
+		}
+
+		s.mut.Lock()
+		err = s.removeLocked(rec.Name)
+		s.mut.Unlock()
+		if err != nil {
+			return replayed, err
+		}
+
+		s.metrics.replayed.Inc()
+		replayed++
+	}
+	return replayed, nil
+}
+
+// Size returns the current size of the spool in bytes.
+func (s *Spool) Size() int64 {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.size
+}
+
+// Register registers the spool's metrics to reg.
+func (s *Spool) Register(reg prometheus.Registerer) error {
+	return s.metrics.register(reg)
+}
+
+// Unregister unregisters the spool's metrics from reg.
+func (s *Spool) Unregister(reg prometheus.Registerer) {
+	s.metrics.unregister(reg)
+}
+
+// names returns the sorted names of records in the spool.
+func (s *Spool) names() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading dead-letter directory: %w", err)
+	}
+
+	var res []string
+	for _, ent := range entries {
+		if ent.IsDir() || !strings.HasSuffix(ent.Name(), recordExt) {
+			continue
+		}
+		res = append(res, ent.Name())
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// Records are stored as a big-endian uint32 length of a JSON-encoded header,
+// the header, and then the raw payload.
+func encodeRecord(w io.Writer, rec Record) error {
+	hdr, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(hdr)))
+	for _, b := range [][]byte{lenBuf[:], hdr, rec.Payload} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readRecord(path string, withPayload bool) (Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Record{}, err
+	}
+	defer f.Close()
+
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(f, lenBuf[:]); err != nil {
+		return Record{}, fmt.Errorf("reading record %s: %w", path, err)
+	}
+	hdr := make([]byte, binary.BigEndian.Uint32(lenBuf[:]))
+	if _, err := io.ReadFull(f, hdr); err != nil {
+		return Record{}, fmt.Errorf("reading record %s: %w", path, err)
+	}
+
+	var rec Record
+	if err := json.Unmarshal(hdr, &rec); err != nil {
+		return Record{}, fmt.Errorf("decoding record %s: %w", path, err)
+	}
+	if withPayload {
+		if rec.Payload, err = io.ReadAll(f); err != nil {
+			return Record{}, fmt.Errorf("reading record %s: %w", path, err)
+		}
+	}
+	return rec, nil
+}
@@ -0,0 +1,112 @@
+package deadletter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpool_WriteReplay(t *testing.T) {
+	dir := t.TempDir()
+	reg := prometheus.NewRegistry()
+
+	s, err := New(dir, 0, "test", reg)
+	require.NoError(t, err)
+
+	for _, payload := range []string{"first", "second"} {
+		require.NoError(t, s.Write(Record{
+			URL:     "http://example.com/push",
+			Headers: map[string]string{"X-Scope-OrgID": "tenant"},
+			Payload: []byte(payload),
+		}))
+	}
+
+	recs, err := s.List()
+	require.NoError(t, err)
+	require.Len(t, recs, 2)
+	require.Equal(t, "http://example.com/push", recs[0].URL)
+	require.Nil(t, recs[0].Payload)
+
+	// Reopening the spool should pick up existing records.
+	s, err = New(dir, 0, "test", nil)
+	require.NoError(t, err)
+
+	var payloads []string
+	n, err := s.Replay(func(r Record) error {
+		payloads = append(payloads, string(r.Payload))
+		require.Equal(t, "tenant", r.Headers["X-Scope-OrgID"])
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []string{"first", "second"}, payloads)
+	require.Zero(t, s.Size())
+
+	recs, err = s.List()
+	require.NoError(t, err)
+	require.Empty(t, recs)
+}
+
+func TestSpool_Eviction(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	// Each record holds a 100 byte payload plus its header, so only two
+	// records fit.
+	s, err := New(t.TempDir(), 400, "test", reg)
+	require.NoError(t, err)
+
+	for _, c := range []string{"a", "b", "c"} {
+		require.NoError(t, s.Write(Record{Payload: []byte(strings.Repeat(c, 100))}))
+	}
+
+	var payloads []string
+	_, err = s.Replay(func(r Record) error {
+		payloads = append(payloads, string(r.Payload[:1]))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c"}, payloads)
+
+	expect := `
+		# HELP test_dead_letter_records_evicted_total Total number of records evicted from the dead-letter spool to stay within its maximum size.
+		# TYPE test_dead_letter_records_evicted_total counter
+		test_dead_letter_records_evicted_total 1
+	`
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expect), "test_dead_letter_records_evicted_total"))
+
+	require.Error(t, s.Write(Record{Payload: make([]byte, 500)}))
+}
+
+func TestSpool_ReplayStopsOnError(t *testing.T) {
+	s, err := New(t.TempDir(), 0, "test", nil)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bb, _ := io.ReadAll(r.Body)
+		if string(bb) == "bad" {
+			http.Error(w, "rejected", http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	for _, payload := range []string{"good", "bad", "good"} {
+		require.NoError(t, s.Write(Record{URL: srv.URL, Payload: []byte(payload)}))
+	}
+
+	n, err := s.Replay(func(r Record) error {
+		return Post(context.Background(), srv.Client(), "", r)
+	})
+	require.ErrorContains(t, err, "rejected")
+	require.Equal(t, 1, n)
+
+	recs, err := s.List()
+	require.NoError(t, err)
+	require.Len(t, recs, 2)
+}
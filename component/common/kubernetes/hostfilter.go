@@ -0,0 +1,44 @@
+// Package kubernetes holds types shared by components which discover
+// resources from Kubernetes.
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/grafana/agent/pkg/river"
+)
+
+// NodeNameEnv is the environment variable used to determine the name of the
+// node Grafana Agent is running on when no node name is configured. It's
+// typically set from the spec.nodeName field using the Kubernetes downward
+// API.
+const NodeNameEnv = "NODE_NAME"
+
+// HostFilterArguments restricts discovered resources to the ones scheduled
+// on a single node. It allows running Grafana Agent as a DaemonSet where every
+// instance only collects telemetry from its own node.
+type HostFilterArguments struct {
+	NodeName string `river:"node_name,attr,optional"`
+}
+
+var _ river.Unmarshaler = (*HostFilterArguments)(nil)
+
+// UnmarshalRiver implements river.Unmarshaler. If node_name isn't set, it
+// defaults to the value of the NODE_NAME environment variable.
+func (args *HostFilterArguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = HostFilterArguments{}
+
+	type arguments HostFilterArguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if args.NodeName == "" {
+		args.NodeName = os.Getenv(NodeNameEnv)
+	}
+	if args.NodeName == "" {
+		return fmt.Errorf("node_name must be set when the %s environment variable is empty", NodeNameEnv)
+	}
+	return nil
+}
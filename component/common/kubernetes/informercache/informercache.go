@@ -0,0 +1,285 @@
+// Package informercache shares Kubernetes informers between components.
+//
+// Components which watch the same cluster with the same client settings
+// share a single cache of informers, so that memory usage and the load on the
+// API server scale with the number of unique watches instead of the number of
+// components.
+package informercache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// scheme holds the types which can be watched through shared caches.
+var scheme = runtime.NewScheme()
+
+// AddToScheme registers types which can be watched through shared caches.
+// It must only be called from init functions.
+func AddToScheme(add func(*runtime.Scheme) error) {
+	if err := add(scheme); err != nil {
+		panic(fmt.Sprintf("informercache: unable to register scheme: %s", err))
+	}
+}
+
+// Scheme returns the scheme of shared caches, which holds all types
+// registered through AddToScheme.
+func Scheme() *runtime.Scheme { return scheme }
+
+// Default is the set of caches shared by all components.
+var Default = New()
+
+// Caches is a set of reference-counted informer caches.
+type Caches struct {
+	newCache func(*rest.Config, cache.Options) (cache.Cache, error)
+
+	mut     sync.Mutex
+	entries []*entry
+}
+
+// New returns an empty set of caches.
+func New() *Caches {
+	return &Caches{newCache: cache.New}
+}
+
+type entry struct {
+	client    interface{}
+	namespace string
+	refs      int
+
+	cache  cache.Cache
+	cancel context.CancelFunc
+
+	mut         sync.Mutex
+	dispatchers map[reflect.Type]*dispatcher
+}
+
+// Acquire returns a handle to the cache of the cluster identified by client
+// watching namespace, or all namespaces if namespace is empty. client is
+// typically the arguments a component uses to connect to Kubernetes, and is
+// compared using reflect.DeepEqual. If no such cache exists, one is created
+// from the config returned by buildConfig and started.
+//
+// The handle must be released once it's no longer used. The cache is
+// stopped when all of its handles are released.
+func (c *Caches) Acquire(l log.Logger, client interface{}, namespace string, buildConfig func() (*rest.Config, error)) (*Handle, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	for _, e := range c.entries {
+		if e.namespace == namespace && reflect.DeepEqual(e.client, client) {
+			e.refs++
+			return &Handle{caches: c, entry: e}, nil
+		}
+	}
+
+	cfg, err := buildConfig()
+	if err != nil {
+		return nil, err
+	}
+	informers, err := c.newCache(cfg, cache.Options{Scheme: scheme, Namespace: namespace})
+	if err != nil {
+		return nil, fmt.Errorf("creating informers cache: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		// Start only returns once the cache is stopped.
+		if err := informers.Start(ctx); err != nil && ctx.Err() == nil {
+			level.Error(l).Log("msg", "failed to start informers", "err", err)
+		}
+	}()
+
+	e := &entry{
+		client:      client,
+		namespace:   namespace,
+		refs:        1,
+		cache:       informers,
+		cancel:      cancel,
+		dispatchers: make(map[reflect.Type]*dispatcher),
+	}
+	c.entries = append(c.entries, e)
+	return &Handle{caches: c, entry: e}, nil
+}
+
+// size returns the number of caches currently running.
+func (c *Caches) size() int {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return len(c.entries)
+}
+
+func (c *Caches) release(e *entry) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	e.refs--
+	if e.refs > 0 {
+		return
+	}
+	e.cancel()
+	for i, other := range c.entries {
+		if other == e {
+			c.entries = append(c.entries[:i], c.entries[i+1:]...)
+			break
+		}
+	}
+}
+
+var errReleased = errors.New("informer cache handle is released")
+
+// Handle is a reference to a shared cache.
+type Handle struct {
+	caches *Caches
+	entry  *entry
+
+	mut           sync.Mutex
+	released      bool
+	subscriptions []*subscription
+}
+
+// subscription is an event handler added through a handle.
+type subscription struct {
+	dispatcher *dispatcher
+	handler    toolscache.ResourceEventHandler
+}
+
+// Reader returns a client.Reader which reads objects from the cache.
+func (h *Handle) Reader() client.Reader {
+	return h.entry.cache
+}
+
+// WaitForCacheSync waits until all informers of the cache have synced. It
+// returns false if ctx is canceled first.
+func (h *Handle) WaitForCacheSync(ctx context.Context) bool {
+	return h.entry.cache.WaitForCacheSync(ctx)
+}
+
+// AddEventHandler calls handler when objects of the type of obj change,
+// until the handle is released. The informer for the type is created if it
+// doesn't exist yet.
+//
+// Like handlers added to informers directly, handler is first called with
+// all existing objects. Objects which change while handler is added may be
+// passed to it twice.
+func (h *Handle) AddEventHandler(ctx context.Context, obj client.Object, handler toolscache.ResourceEventHandler) error {
+	if h.isReleased() {
+		return errReleased
+	}
+	informer, err := h.entry.cache.GetInformer(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	h.entry.mut.Lock()
+	ty := reflect.TypeOf(obj)
+	d, ok := h.entry.dispatchers[ty]
+	if !ok {
+		d = &dispatcher{subscriptions: make(map[*subscription]struct{})}
+		if si, ok := informer.(interface{ GetStore() toolscache.Store }); ok {
+			d.store = si.GetStore()
+		}
+		informer.AddEventHandler(d)
+		h.entry.dispatchers[ty] = d
+	}
+	h.entry.mut.Unlock()
+
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	if h.released {
+		return errReleased
+	}
+	s := &subscription{dispatcher: d, handler: handler}
+	d.subscribe(s)
+	h.subscriptions = append(h.subscriptions, s)
+	return nil
+}
+
+func (h *Handle) isReleased() bool {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	return h.released
+}
+
+// Release removes the event handlers added through h and releases the
+// reference to the cache. Calling Release more than once has no effect.
+func (h *Handle) Release() {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	if h.released {
+		return
+	}
+	h.released = true
+
+	for _, s := range h.subscriptions {
+		s.dispatcher.unsubscribe(s)
+	}
+	h.subscriptions = nil
+	h.caches.release(h.entry)
+}
+
+// dispatcher is the single event handler added to a shared informer. Since
+// handlers can't be removed from informers, dispatcher forwards events to the
+// handlers of the handles which are still in use.
+type dispatcher struct {
+	store toolscache.Store // May be nil.
+
+	mut           sync.RWMutex
+	subscriptions map[*subscription]struct{}
+}
+
+var _ toolscache.ResourceEventHandler = (*dispatcher)(nil)
+
+func (d *dispatcher) subscribe(s *subscription) {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	if d.store != nil {
+		for _, obj := range d.store.List() {
+			s.handler.OnAdd(obj)
+		}
+	}
+	d.subscriptions[s] = struct{}{}
+}
+
+func (d *dispatcher) unsubscribe(s *subscription) {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	delete(d.subscriptions, s)
+}
+
+func (d *dispatcher) OnAdd(obj interface{}) {
+	d.mut.RLock()
+	defer d.mut.RUnlock()
+	for s := range d.subscriptions {
+		s.handler.OnAdd(obj)
+	}
+}
+
+func (d *dispatcher) OnUpdate(oldObj, newObj interface{}) {
+	d.mut.RLock()
+	defer d.mut.RUnlock()
+	for s := range d.subscriptions {
+		s.handler.OnUpdate(oldObj, newObj)
+	}
+}
+
+func (d *dispatcher) OnDelete(obj interface{}) {
+	d.mut.RLock()
+	defer d.mut.RUnlock()
+	for s := range d.subscriptions {
+		s.handler.OnDelete(obj)
+	}
+}
@@ -0,0 +1,124 @@
+package informercache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+)
+
+func init() {
+	AddToScheme(corev1.AddToScheme)
+}
+
+// newTestCaches returns Caches which create fake caches, along with the list
+// of created caches.
+func newTestCaches() (*Caches, *[]*informertest.FakeInformers) {
+	var created []*informertest.FakeInformers
+	c := New()
+	c.newCache = func(_ *rest.Config, opts cache.Options) (cache.Cache, error) {
+		fake := &informertest.FakeInformers{Scheme: opts.Scheme}
+		created = append(created, fake)
+		return fake, nil
+	}
+	return c, &created
+}
+
+func buildTestConfig() (*rest.Config, error) {
+	return &rest.Config{Host: "localhost"}, nil
+}
+
+func TestCaches_Acquire(t *testing.T) {
+	c, created := newTestCaches()
+
+	type client struct{ Server string }
+
+	h1, err := c.Acquire(log.NewNopLogger(), client{"a"}, "", buildTestConfig)
+	require.NoError(t, err)
+	h2, err := c.Acquire(log.NewNopLogger(), client{"a"}, "", buildTestConfig)
+	require.NoError(t, err)
+	require.Len(t, *created, 1, "components with the same settings should share a cache")
+	require.Equal(t, h1.Reader(), h2.Reader())
+
+	// Caches aren't shared between clusters or namespaces.
+	h3, err := c.Acquire(log.NewNopLogger(), client{"b"}, "", buildTestConfig)
+	require.NoError(t, err)
+	h4, err := c.Acquire(log.NewNopLogger(), client{"a"}, "default", buildTestConfig)
+	require.NoError(t, err)
+	require.Len(t, *created, 3)
+	require.Equal(t, 3, c.size())
+
+	h1.Release()
+	h1.Release()
+	require.Equal(t, 3, c.size(), "cache should run while it has handles")
+	h2.Release()
+	h3.Release()
+	h4.Release()
+	require.Equal(t, 0, c.size())
+
+	// Caches which were stopped are recreated.
+	h, err := c.Acquire(log.NewNopLogger(), client{"a"}, "", buildTestConfig)
+	require.NoError(t, err)
+	defer h.Release()
+	require.Len(t, *created, 4)
+}
+
+func TestHandle_AddEventHandler(t *testing.T) {
+	c, created := newTestCaches()
+	ctx := context.Background()
+
+	var added1, added2 []string
+	handler := func(added *[]string) toolscache.ResourceEventHandler {
+		return toolscache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				*added = append(*added, obj.(*corev1.Pod).Name)
+			},
+		}
+	}
+
+	h1, err := c.Acquire(log.NewNopLogger(), "cluster", "", buildTestConfig)
+	require.NoError(t, err)
+	require.NoError(t, h1.AddEventHandler(ctx, &corev1.Pod{}, handler(&added1)))
+	h2, err := c.Acquire(log.NewNopLogger(), "cluster", "", buildTestConfig)
+	require.NoError(t, err)
+	require.NoError(t, h2.AddEventHandler(ctx, &corev1.Pod{}, handler(&added2)))
+
+	informer, err := (*created)[0].FakeInformerFor(&corev1.Pod{})
+	require.NoError(t, err)
+	informer.Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "first"}})
+	require.Equal(t, []string{"first"}, added1)
+	require.Equal(t, []string{"first"}, added2)
+
+	// Handlers of released handles aren't called anymore, even though the
+	// cache keeps running for the other handle.
+	h1.Release()
+	informer.Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "second"}})
+	require.Equal(t, []string{"first"}, added1)
+	require.Equal(t, []string{"first", "second"}, added2)
+	require.Equal(t, 1, c.size())
+
+	h2.Release()
+	require.Error(t, h2.AddEventHandler(ctx, &corev1.Pod{}, handler(&added2)))
+}
+
+func TestDispatcher_ReplaysExistingObjects(t *testing.T) {
+	store := toolscache.NewStore(toolscache.MetaNamespaceKeyFunc)
+	require.NoError(t, store.Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "existing"}}))
+
+	d := &dispatcher{store: store, subscriptions: make(map[*subscription]struct{})}
+
+	var added []string
+	d.subscribe(&subscription{dispatcher: d, handler: toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			added = append(added, obj.(*corev1.Pod).Name)
+		},
+	}})
+	require.Equal(t, []string{"existing"}, added)
+}
@@ -0,0 +1,146 @@
+package loki
+
+import (
+	"sync"
+)
+
+// Acknowledger is notified once a log entry has been delivered by loki.write,
+// or once it has been permanently dropped along the way.
+//
+// Ack must be safe to call from any goroutine. Calls after the first are
+// ignored.
+type Acknowledger interface {
+	Ack()
+}
+
+// FanoutAck returns an Acknowledger which acknowledges a after being
+// acknowledged n times. It is used when the same entry is sent to n
+// receivers. If n is zero, a is acknowledged immediately and nil is returned.
+func FanoutAck(a Acknowledger, n int) Acknowledger {
+	if a == nil {
+		return nil
+	}
+	if n <= 0 {
+		a.Ack()
+		return nil
+	}
+	if n == 1 {
+		return a
+	}
+	return &fanoutAck{next: a, remaining: n}
+}
+
+type fanoutAck struct {
+	mut       sync.Mutex
+	next      Acknowledger
+	remaining int
+}
+
+func (f *fanoutAck) Ack() {
+	f.mut.Lock()
+	f.remaining--
+	done := f.remaining == 0
+	f.mut.Unlock()
+
+	if done {
+		f.next.Ack()
+	}
+}
+
+// JoinAcks returns an Acknowledger which acknowledges all of acks at once.
+// It is used when several entries are merged into a single one. nil
+// Acknowledgers are skipped; nil is returned if there is nothing to
+// acknowledge.
+func JoinAcks(acks ...Acknowledger) Acknowledger {
+	joined := make(joinedAck, 0, len(acks))
+	for _, a := range acks {
+		if a != nil {
+			joined = append(joined, a)
+		}
+	}
+
+	switch len(joined) {
+	case 0:
+		return nil
+	case 1:
+		return joined[0]
+	default:
+		return &joined
+	}
+}
+
+type joinedAck []Acknowledger
+
+func (j *joinedAck) Ack() {
+	for _, a := range *j {
+		a.Ack()
+	}
+}
+
+// AckTracker hands out Acknowledgers for positions read by a source, in read
+// order, and commits the latest position up to which every entry has been
+// acknowledged. Entries can be acknowledged out of order; a position is only
+// committed once all entries read before it have been acknowledged too.
+type AckTracker[P any] struct {
+	mut     sync.Mutex
+	commit  func(P)
+	pending []*trackedAck[P]
+}
+
+// NewAckTracker creates a new AckTracker which calls commit with the latest
+// fully acknowledged position. commit is called with the tracker's lock
+// held, so positions are always committed in order.
+func NewAckTracker[P any](commit func(P)) *AckTracker[P] {
+	return &AckTracker[P]{commit: commit}
+}
+
+// Track registers an entry read up to pos and returns the Acknowledger to
+// attach to it.
+func (t *AckTracker[P]) Track(pos P) Acknowledger {
+	a := &trackedAck[P]{tracker: t, pos: pos}
+
+	t.mut.Lock()
+	t.pending = append(t.pending, a)
+	t.mut.Unlock()
+
+	return a
+}
+
+// Pending returns the number of tracked entries which are waiting to be
+// committed.
+func (t *AckTracker[P]) Pending() int {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	return len(t.pending)
+}
+
+func (t *AckTracker[P]) ack(a *trackedAck[P]) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	if a.acked {
+		return
+	}
+	a.acked = true
+
+	var (
+		last      P
+		committed bool
+	)
+	for len(t.pending) > 0 && t.pending[0].acked {
+		last, committed = t.pending[0].pos, true
+		t.pending[0] = nil
+		t.pending = t.pending[1:]
+	}
+	if committed {
+		t.commit(last)
+	}
+}
+
+type trackedAck[P any] struct {
+	tracker *AckTracker[P]
+	pos     P
+	acked   bool
+}
+
+func (a *trackedAck[P]) Ack() { a.tracker.ack(a) }
@@ -0,0 +1,64 @@
+package loki
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type countingAck struct{ acks int }
+
+func (c *countingAck) Ack() { c.acks++ }
+
+func TestFanoutAck(t *testing.T) {
+	var c countingAck
+	a := FanoutAck(&c, 3)
+	a.Ack()
+	a.Ack()
+	require.Equal(t, 0, c.acks)
+	a.Ack()
+	require.Equal(t, 1, c.acks)
+
+	// No receivers acknowledges immediately.
+	require.Nil(t, FanoutAck(&c, 0))
+	require.Equal(t, 2, c.acks)
+
+	require.Nil(t, FanoutAck(nil, 2))
+}
+
+func TestJoinAcks(t *testing.T) {
+	var c1, c2 countingAck
+	require.Nil(t, JoinAcks(nil, nil))
+
+	JoinAcks(&c1, nil, &c2).Ack()
+	require.Equal(t, 1, c1.acks)
+	require.Equal(t, 1, c2.acks)
+}
+
+func TestAckTracker(t *testing.T) {
+	var committed []int64
+	tracker := NewAckTracker(func(pos int64) {
+		committed = append(committed, pos)
+	})
+
+	a := tracker.Track(10)
+	b := tracker.Track(20)
+	c := tracker.Track(30)
+	require.Equal(t, 3, tracker.Pending())
+
+	// Acknowledging out of order must not commit past unacknowledged entries.
+	b.Ack()
+	require.Empty(t, committed)
+
+	a.Ack()
+	require.Equal(t, []int64{20}, committed)
+
+	// Acknowledging twice is a no-op.
+	a.Ack()
+	b.Ack()
+	require.Equal(t, []int64{20}, committed)
+
+	c.Ack()
+	require.Equal(t, []int64{20, 30}, committed)
+	require.Equal(t, 0, tracker.Pending())
+}
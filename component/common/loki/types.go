@@ -31,6 +31,20 @@ type LogsReceiver chan Entry
 type Entry struct {
 	Labels model.LabelSet
 	logproto.Entry
+
+	// Ack, when set, is notified once the entry has been handled by its final
+	// destination. Sources which only advance their read positions after
+	// delivery set Ack; every other component must carry it along unchanged
+	// or call Acknowledge when dropping the entry.
+	Ack Acknowledger
+}
+
+// Acknowledge notifies the entry's Acknowledger, if any, that the entry has
+// been handled.
+func (e Entry) Acknowledge() {
+	if e.Ack != nil {
+		e.Ack.Ack()
+	}
 }
 
 // InstrumentedEntryHandler ...
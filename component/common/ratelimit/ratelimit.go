@@ -0,0 +1,127 @@
+// Package ratelimit limits the requests accepted by components which receive
+// data over HTTP, so that a single misbehaving client can't overwhelm an
+// agent shared by many clients.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/alecthomas/units"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+// Arguments configures the limits of an HTTP receiver. Every endpoint of the
+// receiver is limited separately. Zero values disable the respective limit.
+type Arguments struct {
+	RequestsPerSecond  float64          `river:"requests_per_second,attr,optional"`
+	Burst              int              `river:"burst,attr,optional"`
+	MaxRequestBodySize units.Base2Bytes `river:"max_request_body_size,attr,optional"`
+}
+
+var _ river.Unmarshaler = (*Arguments)(nil)
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = Arguments{}
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+	return args.Validate()
+}
+
+// Validate returns an error if args is invalid.
+func (args *Arguments) Validate() error {
+	if args.RequestsPerSecond < 0 {
+		return fmt.Errorf("requests_per_second must not be negative")
+	}
+	if args.Burst < 0 {
+		return fmt.Errorf("burst must not be negative")
+	}
+	if args.Burst > 0 && args.RequestsPerSecond == 0 {
+		return fmt.Errorf("burst requires requests_per_second to be set")
+	}
+	if args.MaxRequestBodySize < 0 {
+		return fmt.Errorf("max_request_body_size must not be negative")
+	}
+	return nil
+}
+
+// burst returns the number of requests which can be accepted at once. It
+// defaults to one second worth of requests.
+func (args *Arguments) burst() int {
+	if args.Burst > 0 {
+		return args.Burst
+	}
+	return int(math.Max(1, math.Ceil(args.RequestsPerSecond)))
+}
+
+// Metrics holds the metrics of rejected requests. They're shared by all the
+// endpoints of a component, which are distinguished by the endpoint label.
+type Metrics struct {
+	rateLimited     *prometheus.CounterVec
+	bodySizeLimited *prometheus.CounterVec
+}
+
+// NewMetrics creates and registers the metrics of rejected requests, whose
+// names are prefixed with namespace.
+func NewMetrics(reg prometheus.Registerer, namespace string) *Metrics {
+	m := &Metrics{
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rate_limited_requests_total",
+			Help:      "Number of requests rejected with 429 Too Many Requests because the endpoint's rate limit was exceeded.",
+		}, []string{"endpoint"}),
+		bodySizeLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "request_body_too_large_total",
+			Help:      "Number of requests rejected because their body exceeded the maximum request body size.",
+		}, []string{"endpoint"}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.rateLimited, m.bodySizeLimited)
+	}
+	return m
+}
+
+// Handler wraps next with the limits of args for the endpoint. Requests over
+// the rate limit are rejected with 429 Too Many Requests. Requests whose
+// declared body size is over the maximum size are rejected with 413 Request
+// Entity Too Large, and reading more than the maximum size from any other
+// body fails.
+func Handler(args Arguments, m *Metrics, endpoint string, next http.Handler) http.Handler {
+	if args.RequestsPerSecond == 0 && args.MaxRequestBodySize == 0 {
+		return next
+	}
+
+	var limiter *rate.Limiter
+	if args.RequestsPerSecond > 0 {
+		limiter = rate.NewLimiter(rate.Limit(args.RequestsPerSecond), args.burst())
+	}
+	maxBodySize := int64(args.MaxRequestBodySize)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limiter != nil && !limiter.Allow() {
+			m.rateLimited.WithLabelValues(endpoint).Inc()
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+
+		if maxBodySize > 0 {
+			if r.ContentLength > maxBodySize {
+				m.bodySizeLimited.WithLabelValues(endpoint).Inc()
+				http.Error(w, fmt.Sprintf("request body is larger than %d bytes", maxBodySize), http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
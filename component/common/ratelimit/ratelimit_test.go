@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grafana/agent/pkg/river"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalRiver(t *testing.T) {
+	var args Arguments
+	err := river.Unmarshal([]byte(`
+		requests_per_second   = 10
+		burst                 = 20
+		max_request_body_size = "1MiB"
+	`), &args)
+	require.NoError(t, err)
+	require.Equal(t, Arguments{RequestsPerSecond: 10, Burst: 20, MaxRequestBodySize: 1 << 20}, args)
+
+	err = river.Unmarshal([]byte(`burst = 5`), &args)
+	require.EqualError(t, err, "burst requires requests_per_second to be set")
+}
+
+func TestHandler_RateLimit(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg, "test")
+	h := Handler(Arguments{RequestsPerSecond: 0.001, Burst: 2}, m, "/push", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	var codes []int
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/push", nil))
+		codes = append(codes, rec.Code)
+	}
+	require.Equal(t, []int{http.StatusNoContent, http.StatusNoContent, http.StatusTooManyRequests}, codes)
+	require.Equal(t, 1.0, testutil.ToFloat64(m.rateLimited.WithLabelValues("/push")))
+}
+
+func TestHandler_MaxRequestBodySize(t *testing.T) {
+	m := NewMetrics(nil, "test")
+	h := Handler(Arguments{MaxRequestBodySize: 4}, m, "/push", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/push", strings.NewReader("1234")))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/push", strings.NewReader("12345")))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	require.Equal(t, 1.0, testutil.ToFloat64(m.bodySizeLimited.WithLabelValues("/push")))
+
+	// Bodies of unknown size are cut off once the limit is reached.
+	req := httptest.NewRequest(http.MethodPost, "/push", io.NopCloser(strings.NewReader("12345")))
+	req.ContentLength = -1
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -61,6 +61,7 @@ package component
 import (
 	"context"
 	"net/http"
+	"time"
 )
 
 // The Arguments contains the input fields for a specific component, which is
@@ -115,6 +116,51 @@ type DebugComponent interface {
 	DebugInfo() interface{}
 }
 
+// ScrapeComponent is an extension interface for components which scrape
+// targets and can report the state of each target.
+type ScrapeComponent interface {
+	Component
+
+	// ScrapeTargets returns the state of the targets which are currently being
+	// scraped.
+	//
+	// ScrapeTargets must be safe for calling concurrently.
+	ScrapeTargets() []ScrapeTarget
+}
+
+// ScrapeTarget reports on the latest scrape of a target.
+type ScrapeTarget struct {
+	JobName            string
+	URL                string
+	Health             string
+	Labels             map[string]string
+	LastError          string
+	LastScrape         time.Time
+	LastScrapeDuration time.Duration
+}
+
+// StorageComponent is an extension interface for components which can report
+// where they store data on disk.
+type StorageComponent interface {
+	Component
+
+	// StoragePaths returns the files and directories where the component
+	// stores data. Paths may be inside or outside of the data directory of the
+	// component.
+	//
+	// StoragePaths must be safe for calling concurrently.
+	StoragePaths() []StoragePath
+}
+
+// StoragePath is a file or directory where a component stores data.
+type StoragePath struct {
+	Path string
+	// Buffer is true for data which is kept until it's sent, such as WALs,
+	// disk queues, and dead-letter spools. Buffers may be large and aren't
+	// portable between hosts, so they're left out of snapshots.
+	Buffer bool
+}
+
 // HTTPComponent is an extension interface for components which contain their own HTTP handlers.
 type HTTPComponent interface {
 	Component
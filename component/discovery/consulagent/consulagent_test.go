@@ -0,0 +1,51 @@
+package consulagent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/pkg/river"
+	promconfig "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiverConfig(t *testing.T) {
+	var exampleRiverConfig = `
+	server           = "localhost:8500"
+	token            = "token"
+	datacenter       = "dc1"
+	services         = ["api", "web"]
+	tags             = ["prod"]
+	node_meta        = { rack = "a" }
+	refresh_interval = "10s"
+	tls_config {
+		insecure_skip_verify = true
+	}
+`
+
+	var args Arguments
+	err := river.Unmarshal([]byte(exampleRiverConfig), &args)
+	require.NoError(t, err)
+
+	sd := args.Convert()
+	require.Equal(t, "localhost:8500", sd.Server)
+	require.Equal(t, promconfig.Secret("token"), sd.Token)
+	require.Equal(t, "dc1", sd.Datacenter)
+	require.Equal(t, ",", sd.TagSeparator)
+	require.Equal(t, "http", sd.Scheme)
+	require.Equal(t, model.Duration(10*time.Second), sd.RefreshInterval)
+	require.Equal(t, []string{"api", "web"}, sd.Services)
+	require.Equal(t, []string{"prod"}, sd.ServiceTags)
+	require.Equal(t, map[string]string{"rack": "a"}, sd.NodeMeta)
+	require.True(t, sd.TLSConfig.InsecureSkipVerify)
+}
+
+func TestBadRiverConfig(t *testing.T) {
+	var args Arguments
+	err := river.Unmarshal([]byte(`server = ""`), &args)
+	require.EqualError(t, err, "server must not be empty")
+
+	err = river.Unmarshal([]byte(`refresh_interval = "0s"`), &args)
+	require.EqualError(t, err, "refresh_interval must be greater than 0")
+}
@@ -0,0 +1,168 @@
+package debug
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/grafana/agent/component"
+	flow_relabel "github.com/grafana/agent/component/common/relabel"
+	"github.com/grafana/agent/component/discovery"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "discovery.debug",
+		Args:    Arguments{},
+		Exports: Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Arguments holds values which are used to configure the discovery.debug
+// component.
+type Arguments struct {
+	// Targets contains the input 'targets' passed by a service discovery component.
+	Targets []discovery.Target `river:"targets,attr"`
+
+	// RelabelRules are the rules exported by a chain of relabeling
+	// components, applied in order.
+	RelabelRules []flow_relabel.Rules `river:"relabel_rules,attr,optional"`
+
+	// RelabelConfigs are applied after RelabelRules, so that new rules can be
+	// tried out before adding them to a relabeling component.
+	RelabelConfigs []*flow_relabel.Config `river:"rule,block,optional"`
+}
+
+// Exports holds values which are exported by the discovery.debug component.
+type Exports struct {
+	Output []discovery.Target `river:"output,attr"`
+}
+
+// Component implements the discovery.debug component.
+type Component struct {
+	opts component.Options
+
+	mut     sync.RWMutex
+	targets []targetDebugInfo
+}
+
+var (
+	_ component.Component      = (*Component)(nil)
+	_ component.DebugComponent = (*Component)(nil)
+)
+
+// New creates a new discovery.debug component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{opts: o}
+
+	// Call to Update() to set the output once at the start
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// stage is a list of rules along with the name used to refer to it in debug
+// information.
+type stage struct {
+	name  string
+	rules []*relabel.Config
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+
+	stages := make([]stage, 0, len(newArgs.RelabelRules)+1)
+	for i, rules := range newArgs.RelabelRules {
+		stages = append(stages, stage{
+			name:  fmt.Sprintf("relabel_rules[%d].", i),
+			rules: flow_relabel.ComponentToPromRelabelConfigs(rules),
+		})
+	}
+	stages = append(stages, stage{
+		rules: flow_relabel.ComponentToPromRelabelConfigs(newArgs.RelabelConfigs),
+	})
+
+	output := make([]discovery.Target, 0, len(newArgs.Targets))
+	infos := make([]targetDebugInfo, 0, len(newArgs.Targets))
+	for _, t := range newArgs.Targets {
+		info := processTarget(t.Labels(), stages)
+		if !info.Dropped {
+			output = append(output, promLabelsToComponent(info.output))
+		}
+		infos = append(infos, info)
+	}
+
+	c.mut.Lock()
+	c.targets = infos
+	c.mut.Unlock()
+
+	c.opts.OnStateChange(Exports{Output: output})
+	return nil
+}
+
+// processTarget applies the rules of every stage to lset one rule at a time,
+// recording the rule which dropped the target, if any.
+func processTarget(lset labels.Labels, stages []stage) targetDebugInfo {
+	info := targetDebugInfo{Labels: lset.String()}
+
+	for _, s := range stages {
+		for i, rc := range s.rules {
+			lset = relabel.Process(lset, rc)
+			if lset == nil {
+				info.Dropped = true
+				info.DroppedBy = fmt.Sprintf("%srule[%d] (action %q)", s.name, i, rc.Action)
+				return info
+			}
+		}
+	}
+
+	info.Output = lset.String()
+	info.output = lset
+	return info
+}
+
+// DebugInfo returns the labels of every target before and after relabeling,
+// and the rule which dropped it, if any.
+func (c *Component) DebugInfo() interface{} {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	return debugInfo{Targets: c.targets}
+}
+
+type debugInfo struct {
+	Targets []targetDebugInfo `river:"target,block,optional"`
+}
+
+type targetDebugInfo struct {
+	Labels    string `river:"labels,attr"`
+	Output    string `river:"output,attr,optional"`
+	Dropped   bool   `river:"dropped,attr"`
+	DroppedBy string `river:"dropped_by,attr,optional"`
+
+	output labels.Labels
+}
+
+func promLabelsToComponent(ls labels.Labels) discovery.Target {
+	res := make(map[string]string, len(ls))
+	for _, l := range ls {
+		res[l.Name] = l.Value
+	}
+
+	return res
+}
@@ -0,0 +1,79 @@
+package debug
+
+import (
+	"testing"
+
+	"github.com/grafana/agent/component"
+	flow_relabel "github.com/grafana/agent/component/common/relabel"
+	"github.com/grafana/agent/component/discovery"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/river/parser"
+	"github.com/grafana/agent/pkg/river/vm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebug(t *testing.T) {
+	// The rules of an upstream relabeling component, as exported in its rules
+	// field.
+	var upstream struct {
+		Rules []*flow_relabel.Config `river:"rule,block"`
+	}
+	require.NoError(t, river.Unmarshal([]byte(`
+		rule {
+			source_labels = ["app"]
+			action        = "drop"
+			regex         = "frontend"
+		}
+	`), &upstream))
+
+	file, err := parser.ParseFile(t.Name(), []byte(`
+		targets = [
+			{ "__address__" = "localhost:1", "app" = "backend",  "instance" = "one"   },
+			{ "__address__" = "localhost:2", "app" = "frontend", "instance" = "two"   },
+			{ "__address__" = "localhost:3", "app" = "db",       "instance" = "three" },
+		]
+
+		relabel_rules = [upstream_rules]
+
+		rule {
+			source_labels = ["instance"]
+			target_label  = "name"
+		}
+
+		rule {
+			source_labels = ["app"]
+			action        = "keep"
+			regex         = "backend"
+		}
+	`))
+	require.NoError(t, err)
+
+	var args Arguments
+	scope := &vm.Scope{Variables: map[string]interface{}{
+		"upstream_rules": flow_relabel.Rules(upstream.Rules),
+	}}
+	require.NoError(t, vm.New(file).Evaluate(scope, &args))
+	require.Len(t, args.RelabelRules, 1)
+
+	var exports Exports
+	c, err := New(component.Options{
+		OnStateChange: func(e component.Exports) { exports = e.(Exports) },
+	}, args)
+	require.NoError(t, err)
+	require.Equal(t, []discovery.Target{
+		{"__address__": "localhost:1", "app": "backend", "instance": "one", "name": "one"},
+	}, exports.Output)
+
+	info := c.DebugInfo().(debugInfo)
+	require.Len(t, info.Targets, 3)
+
+	require.False(t, info.Targets[0].Dropped)
+	require.Equal(t, `{__address__="localhost:1", app="backend", instance="one"}`, info.Targets[0].Labels)
+	require.Equal(t, `{__address__="localhost:1", app="backend", instance="one", name="one"}`, info.Targets[0].Output)
+
+	require.True(t, info.Targets[1].Dropped)
+	require.Equal(t, `relabel_rules[0].rule[0] (action "drop")`, info.Targets[1].DroppedBy)
+
+	require.True(t, info.Targets[2].Dropped)
+	require.Equal(t, `rule[1] (action "keep")`, info.Targets[2].DroppedBy)
+}
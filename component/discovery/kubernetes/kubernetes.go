@@ -2,10 +2,16 @@
 package kubernetes
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/grafana/agent/component"
 	"github.com/grafana/agent/component/common/config"
+	commonk8s "github.com/grafana/agent/component/common/kubernetes"
 	"github.com/grafana/agent/component/discovery"
+	"github.com/prometheus/common/model"
 	promk8s "github.com/prometheus/prometheus/discovery/kubernetes"
+	"github.com/prometheus/prometheus/discovery/targetgroup"
 )
 
 func init() {
@@ -28,6 +34,8 @@ type Arguments struct {
 	HTTPClientConfig   config.HTTPClientConfig `river:",squash"`
 	NamespaceDiscovery NamespaceDiscovery      `river:"namespaces,block,optional"`
 	Selectors          []SelectorConfig        `river:"selectors,block,optional"`
+
+	HostFilter *commonk8s.HostFilterArguments `river:"host_filter,block,optional"`
 }
 
 // DefaultConfig holds defaults for SDConfig.
@@ -44,6 +52,14 @@ func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
 		return err
 	}
 
+	if args.HostFilter != nil {
+		switch promk8s.Role(args.Role) {
+		case promk8s.RolePod, promk8s.RoleNode, promk8s.RoleEndpoint, promk8s.RoleEndpointSlice:
+		default:
+			return fmt.Errorf("host_filter is not supported with role %q", args.Role)
+		}
+	}
+
 	// We must explicitly Validate because HTTPClientConfig is squashed and it won't run otherwise
 	return args.HTTPClientConfig.Validate()
 }
@@ -54,6 +70,9 @@ func (args *Arguments) Convert() *promk8s.SDConfig {
 	for i, s := range args.Selectors {
 		selectors[i] = *s.convert()
 	}
+	if args.HostFilter != nil {
+		selectors = hostFilterSelectors(promk8s.Role(args.Role), args.HostFilter.NodeName, selectors)
+	}
 	return &promk8s.SDConfig{
 		APIServer:          args.APIServer.Convert(),
 		Role:               promk8s.Role(args.Role),
@@ -96,6 +115,107 @@ func (sc *SelectorConfig) convert() *promk8s.SelectorConfig {
 func New(opts component.Options, args Arguments) (component.Component, error) {
 	return discovery.New(opts, args, func(args component.Arguments) (discovery.Discoverer, error) {
 		newArgs := args.(Arguments)
-		return promk8s.New(opts.Logger, newArgs.Convert())
+		disc, err := promk8s.New(opts.Logger, newArgs.Convert())
+		if err != nil || newArgs.HostFilter == nil {
+			return disc, err
+		}
+		return &hostFilterDiscoverer{inner: disc, nodeName: newArgs.HostFilter.NodeName}, nil
 	})
 }
+
+// hostFilterSelectors adds field selectors to selectors so that only
+// resources on the given node are watched, where the Kubernetes API supports
+// it. Existing field selectors for the same role are preserved.
+func hostFilterSelectors(role promk8s.Role, nodeName string, selectors []promk8s.SelectorConfig) []promk8s.SelectorConfig {
+	var field string
+	switch role {
+	case promk8s.RolePod, promk8s.RoleEndpoint, promk8s.RoleEndpointSlice:
+		// Pods referenced by endpoints are filtered too; the endpoints
+		// themselves are filtered by hostFilterDiscoverer.
+		role, field = promk8s.RolePod, "spec.nodeName="+nodeName
+	case promk8s.RoleNode:
+		field = "metadata.name=" + nodeName
+	default:
+		return selectors
+	}
+
+	for i, s := range selectors {
+		if s.Role != role {
+			continue
+		}
+		if s.Field != "" {
+			field = s.Field + "," + field
+		}
+		selectors[i].Field = field
+		return selectors
+	}
+	return append(selectors, promk8s.SelectorConfig{Role: role, Field: field})
+}
+
+// hostFilterNodeLabels are the labels used to determine which node a
+// discovered target runs on.
+var hostFilterNodeLabels = []model.LabelName{
+	"__meta_kubernetes_pod_node_name",
+	"__meta_kubernetes_node_name",
+	"__meta_kubernetes_endpoint_node_name",
+}
+
+// hostFilterDiscoverer wraps a Discoverer and drops targets which don't run
+// on the given node.
+type hostFilterDiscoverer struct {
+	inner    discovery.Discoverer
+	nodeName string
+}
+
+// Run implements discovery.Discoverer.
+func (d *hostFilterDiscoverer) Run(ctx context.Context, up chan<- []*targetgroup.Group) {
+	ch := make(chan []*targetgroup.Group)
+	go d.inner.Run(ctx, ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case groups := <-ch:
+			filtered := make([]*targetgroup.Group, 0, len(groups))
+			for _, group := range groups {
+				filtered = append(filtered, d.filterGroup(group))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case up <- filtered:
+			}
+		}
+	}
+}
+
+// filterGroup returns a copy of group which only contains targets running on
+// the node of d. Groups are never dropped entirely, since an empty group
+// signals that previously discovered targets have been removed.
+func (d *hostFilterDiscoverer) filterGroup(group *targetgroup.Group) *targetgroup.Group {
+	res := &targetgroup.Group{
+		Source: group.Source,
+		Labels: group.Labels,
+	}
+	for _, target := range group.Targets {
+		if d.matches(group.Labels, target) {
+			res.Targets = append(res.Targets, target)
+		}
+	}
+	return res
+}
+
+func (d *hostFilterDiscoverer) matches(groupLabels, target model.LabelSet) bool {
+	for _, name := range hostFilterNodeLabels {
+		value, ok := target[name]
+		if !ok {
+			value, ok = groupLabels[name]
+		}
+		if ok && string(value) == d.nodeName {
+			return true
+		}
+	}
+	return false
+}
@@ -3,7 +3,11 @@ package kubernetes
 import (
 	"testing"
 
+	commonk8s "github.com/grafana/agent/component/common/kubernetes"
 	"github.com/grafana/agent/pkg/river"
+	"github.com/prometheus/common/model"
+	promk8s "github.com/prometheus/prometheus/discovery/kubernetes"
+	"github.com/prometheus/prometheus/discovery/targetgroup"
 	"github.com/stretchr/testify/require"
 )
 
@@ -33,3 +37,76 @@ func TestBadRiverConfig(t *testing.T) {
 	err := river.Unmarshal([]byte(exampleRiverConfig), &args)
 	require.ErrorContains(t, err, "at most one of bearer_token & bearer_token_file must be configured")
 }
+
+func TestHostFilter(t *testing.T) {
+	t.Setenv(commonk8s.NodeNameEnv, "node-a")
+
+	var exampleRiverConfig = `
+	role = "pod"
+	selectors {
+		role  = "pod"
+		field = "status.phase=Running"
+	}
+	host_filter {}
+`
+
+	var args Arguments
+	err := river.Unmarshal([]byte(exampleRiverConfig), &args)
+	require.NoError(t, err)
+	require.Equal(t, "node-a", args.HostFilter.NodeName)
+
+	sdConfig := args.Convert()
+	require.Equal(t, []promk8s.SelectorConfig{{
+		Role:  promk8s.RolePod,
+		Field: "status.phase=Running,spec.nodeName=node-a",
+	}}, sdConfig.Selectors)
+}
+
+func TestHostFilterBadRiverConfig(t *testing.T) {
+	t.Setenv(commonk8s.NodeNameEnv, "")
+
+	var args Arguments
+	err := river.Unmarshal([]byte(`
+	role = "pod"
+	host_filter {}
+`), &args)
+	require.ErrorContains(t, err, "node_name must be set")
+
+	err = river.Unmarshal([]byte(`
+	role = "service"
+	host_filter {
+		node_name = "node-a"
+	}
+`), &args)
+	require.ErrorContains(t, err, `host_filter is not supported with role "service"`)
+}
+
+func TestHostFilterDiscoverer(t *testing.T) {
+	d := &hostFilterDiscoverer{nodeName: "node-a"}
+
+	group := &targetgroup.Group{
+		Source: "endpoints/default/app",
+		Labels: model.LabelSet{"__meta_kubernetes_namespace": "default"},
+		Targets: []model.LabelSet{
+			{"__address__": "10.0.0.1:80", "__meta_kubernetes_endpoint_node_name": "node-a"},
+			{"__address__": "10.0.0.2:80", "__meta_kubernetes_endpoint_node_name": "node-b"},
+			{"__address__": "10.0.0.3:80", "__meta_kubernetes_pod_node_name": "node-a"},
+			{"__address__": "10.0.0.4:80"},
+		},
+	}
+
+	filtered := d.filterGroup(group)
+	require.Equal(t, group.Source, filtered.Source)
+	require.Equal(t, group.Labels, filtered.Labels)
+	require.Equal(t, []model.LabelSet{group.Targets[0], group.Targets[2]}, filtered.Targets)
+
+	// Groups where no target matches are kept, so that previously
+	// discovered targets get removed.
+	filtered = d.filterGroup(&targetgroup.Group{
+		Source:  "pod/default/app",
+		Labels:  model.LabelSet{"__meta_kubernetes_pod_node_name": "node-b"},
+		Targets: []model.LabelSet{{"__address__": "10.0.0.5:80"}},
+	})
+	require.Equal(t, "pod/default/app", filtered.Source)
+	require.Empty(t, filtered.Targets)
+}
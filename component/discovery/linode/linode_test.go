@@ -0,0 +1,40 @@
+package linode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/pkg/river"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiverConfig(t *testing.T) {
+	var exampleRiverConfig = `
+	refresh_interval = "5m"
+	port             = 9100
+	bearer_token     = "token"
+`
+
+	var args Arguments
+	err := river.Unmarshal([]byte(exampleRiverConfig), &args)
+	require.NoError(t, err)
+
+	sdConfig := args.Convert()
+	require.Equal(t, model.Duration(5*time.Minute), sdConfig.RefreshInterval)
+	require.Equal(t, 9100, sdConfig.Port)
+	require.Equal(t, ",", sdConfig.TagSeparator)
+	require.Equal(t, "token", string(sdConfig.HTTPClientConfig.Authorization.Credentials))
+}
+
+func TestBadRiverConfig(t *testing.T) {
+	var exampleRiverConfig = `
+	bearer_token      = "token"
+	bearer_token_file = "/path/to/file.token"
+`
+
+	// Make sure the squashed HTTPClientConfig Validate function is being utilized correctly
+	var args Arguments
+	err := river.Unmarshal([]byte(exampleRiverConfig), &args)
+	require.ErrorContains(t, err, "at most one of bearer_token & bearer_token_file must be configured")
+}
@@ -0,0 +1,105 @@
+// Package ovhcloud implements the discovery.ovhcloud component.
+package ovhcloud
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/discovery"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	prom_discovery "github.com/prometheus/prometheus/discovery/ovhcloud"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "discovery.ovhcloud",
+		Args:    Arguments{},
+		Exports: discovery.Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Services which can be discovered.
+const (
+	ServiceVPS             = "vps"
+	ServiceDedicatedServer = "dedicated_server"
+)
+
+// Arguments configures the discovery.ovhcloud component.
+type Arguments struct {
+	Endpoint          string            `river:"endpoint,attr,optional"`
+	ApplicationKey    string            `river:"application_key,attr"`
+	ApplicationSecret rivertypes.Secret `river:"application_secret,attr"`
+	ConsumerKey       rivertypes.Secret `river:"consumer_key,attr"`
+	RefreshInterval   time.Duration     `river:"refresh_interval,attr,optional"`
+	Service           string            `river:"service,attr"`
+}
+
+// DefaultArguments holds default values for Arguments.
+var DefaultArguments = Arguments{
+	Endpoint:        "ovh-eu",
+	RefreshInterval: 60 * time.Second,
+}
+
+var _ river.Unmarshaler = (*Arguments)(nil)
+
+// UnmarshalRiver implements river.Unmarshaler, applying defaults and
+// validating the provided config.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if args.Endpoint == "" {
+		return fmt.Errorf("endpoint must not be empty")
+	}
+	if args.ApplicationKey == "" {
+		return fmt.Errorf("application_key must not be empty")
+	}
+	if args.ApplicationSecret == "" {
+		return fmt.Errorf("application_secret must not be empty")
+	}
+	if args.ConsumerKey == "" {
+		return fmt.Errorf("consumer_key must not be empty")
+	}
+	if args.RefreshInterval <= 0 {
+		return fmt.Errorf("refresh_interval must be greater than 0")
+	}
+
+	switch args.Service {
+	case ServiceVPS, ServiceDedicatedServer:
+	default:
+		return fmt.Errorf("unknown service %q, expected one of %q or %q", args.Service, ServiceVPS, ServiceDedicatedServer)
+	}
+
+	return nil
+}
+
+// Convert converts Arguments to the upstream Prometheus SD type.
+func (args Arguments) Convert() *prom_discovery.SDConfig {
+	return &prom_discovery.SDConfig{
+		Endpoint:          args.Endpoint,
+		ApplicationKey:    args.ApplicationKey,
+		ApplicationSecret: config.Secret(args.ApplicationSecret),
+		ConsumerKey:       config.Secret(args.ConsumerKey),
+		RefreshInterval:   model.Duration(args.RefreshInterval),
+		Service:           args.Service,
+	}
+}
+
+// New returns a new instance of a discovery.ovhcloud component.
+func New(opts component.Options, args Arguments) (component.Component, error) {
+	return discovery.New(opts, args, func(args component.Arguments) (discovery.Discoverer, error) {
+		return prom_discovery.NewDiscovery(args.(Arguments).Convert(), opts.Logger)
+	})
+}
@@ -0,0 +1,40 @@
+package ovhcloud
+
+import (
+	"testing"
+
+	"github.com/grafana/agent/pkg/river"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiverConfig(t *testing.T) {
+	var exampleRiverConfig = `
+	application_key    = "key"
+	application_secret = "secret"
+	consumer_key       = "consumer"
+	service            = "vps"
+`
+
+	var args Arguments
+	err := river.Unmarshal([]byte(exampleRiverConfig), &args)
+	require.NoError(t, err)
+
+	sdConfig := args.Convert()
+	require.Equal(t, "ovh-eu", sdConfig.Endpoint)
+	require.Equal(t, "secret", string(sdConfig.ApplicationSecret))
+	require.Equal(t, "consumer", string(sdConfig.ConsumerKey))
+	require.Equal(t, ServiceVPS, sdConfig.Service)
+}
+
+func TestBadRiverConfig(t *testing.T) {
+	var exampleRiverConfig = `
+	application_key    = "key"
+	application_secret = "secret"
+	consumer_key       = "consumer"
+	service            = "cloud"
+`
+
+	var args Arguments
+	err := river.Unmarshal([]byte(exampleRiverConfig), &args)
+	require.ErrorContains(t, err, `unknown service "cloud"`)
+}
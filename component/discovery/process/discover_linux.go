@@ -0,0 +1,113 @@
+//go:build linux
+
+package process
+
+import (
+	"os/user"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/component/discovery"
+	"github.com/prometheus/procfs"
+)
+
+// discover returns a target for every process running on the host.
+func discover(l log.Logger, cfg DiscoverConfig) ([]discovery.Target, error) {
+	fs, err := procfs.NewDefaultFS()
+	if err != nil {
+		return nil, err
+	}
+	procs, err := fs.AllProcs()
+	if err != nil {
+		return nil, err
+	}
+
+	usernames := make(map[string]string)
+
+	res := make([]discovery.Target, 0, len(procs))
+	for _, p := range procs {
+		// Processes may exit at any time during discovery, so failing to read
+		// the name of a process skips it.
+		name, err := p.Comm()
+		if err != nil {
+			level.Debug(l).Log("msg", "skipping process", "pid", p.PID, "err", err)
+			continue
+		}
+
+		t := discovery.Target{
+			labelProcessID:   strconv.Itoa(p.PID),
+			labelProcessName: name,
+		}
+
+		// The remaining details may not be readable without elevated
+		// privileges and are omitted if they can't be read.
+		if cfg.Exe {
+			if exe, err := p.Executable(); err == nil && exe != "" {
+				t[labelProcessExe] = exe
+			}
+		}
+		if cfg.Cwd {
+			if cwd, err := p.Cwd(); err == nil && cwd != "" {
+				t[labelProcessCwd] = cwd
+			}
+		}
+		if cfg.Commandline {
+			if cmdline, err := p.CmdLine(); err == nil && len(cmdline) > 0 {
+				t[labelProcessCommandline] = strings.Join(cmdline, " ")
+			}
+		}
+		if cfg.UID || cfg.Username {
+			if status, err := p.NewStatus(); err == nil && status.UIDs[0] != "" {
+				uid := status.UIDs[0]
+				if cfg.UID {
+					t[labelProcessUID] = uid
+				}
+				if cfg.Username {
+					if username := lookupUsername(usernames, uid); username != "" {
+						t[labelProcessUsername] = username
+					}
+				}
+			}
+		}
+		if cfg.ContainerID {
+			if id := processContainerID(p); id != "" {
+				t[labelProcessContainerID] = id
+			}
+		}
+
+		res = append(res, t)
+	}
+	return res, nil
+}
+
+// lookupUsername returns the name of the user with the given uid, caching
+// results in cache.
+func lookupUsername(cache map[string]string, uid string) string {
+	if username, ok := cache[uid]; ok {
+		return username
+	}
+
+	var username string
+	if u, err := user.LookupId(uid); err == nil {
+		username = u.Username
+	}
+	cache[uid] = username
+	return username
+}
+
+// processContainerID returns the ID of the container p runs in, or an empty
+// string if p doesn't run in a container.
+func processContainerID(p procfs.Proc) string {
+	cgroups, err := p.Cgroups()
+	if err != nil {
+		return ""
+	}
+	for _, cg := range cgroups {
+		if id := containerIDFromCgroupPath(cg.Path); id != "" {
+			return id
+		}
+	}
+	return ""
+}
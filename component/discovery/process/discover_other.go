@@ -0,0 +1,13 @@
+//go:build !linux
+
+package process
+
+import (
+	"github.com/go-kit/log"
+	"github.com/grafana/agent/component/discovery"
+)
+
+// discover is a no-op on non-Linux platforms.
+func discover(_ log.Logger, _ DiscoverConfig) ([]discovery.Target, error) {
+	return nil, nil
+}
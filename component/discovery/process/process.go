@@ -0,0 +1,206 @@
+// Package process implements the discovery.process component.
+package process
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/discovery"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/discovery/refresh"
+	"github.com/prometheus/prometheus/discovery/targetgroup"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "discovery.process",
+		Args:    Arguments{},
+		Exports: discovery.Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Labels set on discovered process targets.
+const (
+	labelProcessID          = "__process_pid__"
+	labelProcessName        = "__meta_process_name"
+	labelProcessExe         = "__meta_process_exe"
+	labelProcessCwd         = "__meta_process_cwd"
+	labelProcessCommandline = "__meta_process_commandline"
+	labelProcessUID         = "__meta_process_uid"
+	labelProcessUsername    = "__meta_process_username"
+	labelProcessContainerID = "__container_id__"
+)
+
+// Arguments configures the discovery.process component.
+type Arguments struct {
+	Join            []discovery.Target `river:"join,attr,optional"`
+	RefreshInterval time.Duration      `river:"refresh_interval,attr,optional"`
+	DiscoverConfig  DiscoverConfig     `river:"discover_config,block,optional"`
+}
+
+// DiscoverConfig controls which optional labels are set on discovered
+// processes.
+type DiscoverConfig struct {
+	Cwd         bool `river:"cwd,attr,optional"`
+	Exe         bool `river:"exe,attr,optional"`
+	Commandline bool `river:"commandline,attr,optional"`
+	UID         bool `river:"uid,attr,optional"`
+	Username    bool `river:"username,attr,optional"`
+	ContainerID bool `river:"container_id,attr,optional"`
+}
+
+// DefaultDiscoverConfig holds default values for DiscoverConfig.
+var DefaultDiscoverConfig = DiscoverConfig{
+	Cwd:         true,
+	Exe:         true,
+	Commandline: true,
+	UID:         true,
+	Username:    true,
+	ContainerID: true,
+}
+
+// DefaultArguments holds default values for Arguments.
+var DefaultArguments = Arguments{
+	RefreshInterval: 60 * time.Second,
+	DiscoverConfig:  DefaultDiscoverConfig,
+}
+
+var (
+	_ river.Unmarshaler = (*Arguments)(nil)
+	_ river.Unmarshaler = (*DiscoverConfig)(nil)
+)
+
+// UnmarshalRiver implements river.Unmarshaler, applying defaults and
+// validating the provided config.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if args.RefreshInterval <= 0 {
+		return fmt.Errorf("refresh_interval must be greater than 0")
+	}
+	return nil
+}
+
+// UnmarshalRiver implements river.Unmarshaler and applies defaults.
+func (cfg *DiscoverConfig) UnmarshalRiver(f func(interface{}) error) error {
+	*cfg = DefaultDiscoverConfig
+
+	type discoverConfig DiscoverConfig
+	return f((*discoverConfig)(cfg))
+}
+
+// New returns a new instance of a discovery.process component.
+func New(opts component.Options, args Arguments) (component.Component, error) {
+	if runtime.GOOS != "linux" {
+		level.Warn(opts.Logger).Log("msg", "discovery.process only works on linux platforms and will not discover any processes")
+	}
+
+	return discovery.New(opts, args, func(args component.Arguments) (discovery.Discoverer, error) {
+		return newDiscoverer(opts.Logger, args.(Arguments)), nil
+	})
+}
+
+func newDiscoverer(l log.Logger, args Arguments) discovery.Discoverer {
+	return refresh.NewDiscovery(l, "process", args.RefreshInterval, func(ctx context.Context) ([]*targetgroup.Group, error) {
+		processes, err := discover(l, args.DiscoverConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		tg := &targetgroup.Group{Source: "process"}
+		for _, t := range join(processes, args.Join) {
+			lset := make(model.LabelSet, len(t))
+			for k, v := range t {
+				lset[model.LabelName(k)] = model.LabelValue(v)
+			}
+			tg.Targets = append(tg.Targets, lset)
+		}
+		return []*targetgroup.Group{tg}, nil
+	})
+}
+
+// join merges the labels of targets into the processes running in the same
+// container. Labels of processes take precedence over labels of targets.
+func join(processes, targets []discovery.Target) []discovery.Target {
+	containers := make(map[string]discovery.Target, len(targets))
+	for _, t := range targets {
+		if id := targetContainerID(t); id != "" {
+			containers[id] = t
+		}
+	}
+
+	res := make([]discovery.Target, 0, len(processes))
+	for _, p := range processes {
+		container, ok := containers[p[labelProcessContainerID]]
+		if p[labelProcessContainerID] == "" || !ok {
+			res = append(res, p)
+			continue
+		}
+
+		merged := make(discovery.Target, len(container)+len(p))
+		for k, v := range container {
+			merged[k] = v
+		}
+		for k, v := range p {
+			merged[k] = v
+		}
+		res = append(res, merged)
+	}
+	return res
+}
+
+// targetContainerID returns the ID of the container of t as discovered by
+// the discovery.kubernetes or discovery.docker components.
+func targetContainerID(t discovery.Target) string {
+	if id := t[labelProcessContainerID]; id != "" {
+		return id
+	}
+	if id := t["__meta_docker_container_id"]; id != "" {
+		return id
+	}
+	if id := t["__meta_kubernetes_pod_container_id"]; id != "" {
+		// Kubernetes container IDs are prefixed with the container runtime,
+		// for example containerd://<id>.
+		if idx := strings.Index(id, "://"); idx >= 0 {
+			id = id[idx+len("://"):]
+		}
+		return id
+	}
+	return ""
+}
+
+// cgroupContainerIDRegexp matches the container ID in cgroup paths used by
+// common container runtimes, for example:
+//
+//	/docker/<id>
+//	/kubepods/burstable/pod<uid>/<id>
+//	/system.slice/docker-<id>.scope
+//	/kubepods.slice/kubepods-pod<uid>.slice/cri-containerd-<id>.scope
+var cgroupContainerIDRegexp = regexp.MustCompile(`(?:^|[/-])([0-9a-f]{64})(?:\.scope)?$`)
+
+// containerIDFromCgroupPath returns the container ID from a cgroup path, or
+// an empty string if the path doesn't belong to a container.
+func containerIDFromCgroupPath(path string) string {
+	m := cgroupContainerIDRegexp.FindStringSubmatch(path)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
@@ -0,0 +1,99 @@
+package process
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/grafana/agent/component/discovery"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	cfg := `
+		refresh_interval = "10s"
+
+		discover_config {
+			cwd = false
+		}
+	`
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	expect := DefaultDiscoverConfig
+	expect.Cwd = false
+	require.Equal(t, expect, args.DiscoverConfig)
+
+	err := river.Unmarshal([]byte(`refresh_interval = "0s"`), &args)
+	require.ErrorContains(t, err, "refresh_interval must be greater than 0")
+}
+
+func TestContainerIDFromCgroupPath(t *testing.T) {
+	const id = "9c7b2f53c1b9e5d7b8ad2a0d9d6c6d0d1d1a3f0e1c5b4a3f2e1d0c9b8a7f6e5d"
+
+	tt := []struct {
+		path   string
+		expect string
+	}{
+		{"/docker/" + id, id},
+		{"/kubepods/burstable/pod8f2b4c1a-0d6e-4a8b-9c3d-2e1f0a9b8c7d/" + id, id},
+		{"/system.slice/docker-" + id + ".scope", id},
+		{"/kubepods.slice/kubepods-pod8f2b.slice/cri-containerd-" + id + ".scope", id},
+		{"/user.slice/user-1000.slice/session-2.scope", ""},
+		{"/", ""},
+	}
+
+	for _, tc := range tt {
+		require.Equal(t, tc.expect, containerIDFromCgroupPath(tc.path), tc.path)
+	}
+}
+
+func TestJoin(t *testing.T) {
+	processes := []discovery.Target{
+		{labelProcessID: "1", labelProcessContainerID: "aaa"},
+		{labelProcessID: "2", labelProcessContainerID: "bbb"},
+		{labelProcessID: "3"},
+	}
+	targets := []discovery.Target{
+		{"__meta_kubernetes_pod_container_id": "containerd://aaa", "__meta_kubernetes_pod_name": "foo", labelProcessID: "ignored"},
+		{"__meta_docker_container_id": "ccc", "__meta_docker_container_name": "bar"},
+	}
+
+	expect := []discovery.Target{
+		{
+			labelProcessID:                       "1",
+			labelProcessContainerID:              "aaa",
+			"__meta_kubernetes_pod_container_id": "containerd://aaa",
+			"__meta_kubernetes_pod_name":         "foo",
+		},
+		{labelProcessID: "2", labelProcessContainerID: "bbb"},
+		{labelProcessID: "3"},
+	}
+	require.Equal(t, expect, join(processes, targets))
+}
+
+func TestDiscover(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("discovery.process only works on linux")
+	}
+
+	targets, err := discover(util.TestLogger(t), DefaultDiscoverConfig)
+	require.NoError(t, err)
+
+	pid := strconv.Itoa(os.Getpid())
+	for _, target := range targets {
+		if target[labelProcessID] != pid {
+			continue
+		}
+
+		exe, err := os.Executable()
+		require.NoError(t, err)
+		require.Equal(t, exe, target[labelProcessExe])
+		require.Equal(t, strconv.Itoa(os.Getuid()), target[labelProcessUID])
+		return
+	}
+	require.FailNow(t, "test process was not discovered")
+}
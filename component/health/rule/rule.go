@@ -0,0 +1,232 @@
+// Package rule implements the health.rule component.
+package rule
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/component"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "health.rule",
+		Args:    Arguments{},
+		Exports: Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Arguments holds values which are used to configure the health.rule
+// component.
+type Arguments struct {
+	// Condition is an expression over the exports of other components. The
+	// rule is active while it's true.
+	Condition bool `river:"condition,attr"`
+
+	// For is how long the condition must be true before the rule fires.
+	For time.Duration `river:"for,attr,optional"`
+
+	// Message describes the problem while the rule is firing.
+	Message string `river:"message,attr,optional"`
+}
+
+// Exports holds values which are exported by the health.rule component.
+type Exports struct {
+	Firing bool `river:"firing,attr"`
+}
+
+// Component implements the health.rule component.
+type Component struct {
+	opts component.Options
+
+	mut         sync.Mutex
+	args        Arguments
+	activeSince time.Time // Zero while the condition is false.
+	firing      bool
+
+	healthMut sync.RWMutex
+	health    component.Health
+
+	// updateCh is a buffered channel which is written to when the arguments
+	// change, so that Run reschedules the firing of the rule.
+	updateCh chan struct{}
+
+	firingGauge prometheus.Gauge
+	firedTotal  prometheus.Counter
+}
+
+var (
+	_ component.Component       = (*Component)(nil)
+	_ component.HealthComponent = (*Component)(nil)
+)
+
+// New creates a new health.rule component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{
+		opts:     o,
+		updateCh: make(chan struct{}, 1),
+
+		firingGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "agent_health_rule_firing",
+			Help: "1 if the health rule is firing, 0 otherwise.",
+		}),
+		firedTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "agent_health_rule_fired_total",
+			Help: "Number of times the health rule started firing.",
+		}),
+	}
+	if err := o.Registerer.Register(c.firingGauge); err != nil {
+		return nil, err
+	}
+	if err := o.Registerer.Register(c.firedTotal); err != nil {
+		return nil, err
+	}
+
+	o.OnStateChange(Exports{Firing: false})
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-c.updateCh:
+		case <-timer.C:
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if wait := c.evaluate(time.Now()); wait > 0 {
+			timer.Reset(wait)
+		}
+	}
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+	if newArgs.For < 0 {
+		return fmt.Errorf("for must not be negative")
+	}
+
+	c.mut.Lock()
+	c.args = newArgs
+	c.mut.Unlock()
+
+	c.evaluate(time.Now())
+	select {
+	case c.updateCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// evaluate updates the state of the rule at now. It returns how long to wait
+// until the rule fires if the rule is pending, and 0 otherwise.
+func (c *Component) evaluate(now time.Time) time.Duration {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if !c.args.Condition {
+		c.activeSince = time.Time{}
+		if c.firing {
+			c.setFiring(false, now)
+		} else {
+			c.setHealth(component.Health{
+				Health:     component.HealthTypeHealthy,
+				Message:    "condition is false",
+				UpdateTime: now,
+			})
+		}
+		return 0
+	}
+
+	if c.activeSince.IsZero() {
+		c.activeSince = now
+	}
+	if c.firing {
+		// Refresh the message, which may have changed.
+		c.setFiringHealth()
+		return 0
+	}
+
+	if wait := c.activeSince.Add(c.args.For).Sub(now); wait > 0 {
+		c.setHealth(component.Health{
+			Health:     component.HealthTypeHealthy,
+			Message:    fmt.Sprintf("condition is true since %s, pending", c.activeSince.Format(time.RFC3339)),
+			UpdateTime: now,
+		})
+		return wait
+	}
+	c.setFiring(true, now)
+	return 0
+}
+
+// setFiring changes whether the rule fires. c.mut must be held.
+func (c *Component) setFiring(firing bool, now time.Time) {
+	c.firing = firing
+	c.opts.OnStateChange(Exports{Firing: firing})
+
+	if firing {
+		c.firingGauge.Set(1)
+		c.firedTotal.Inc()
+		level.Warn(c.opts.Logger).Log("msg", "health rule is firing", "message", c.args.Message, "active_since", c.activeSince)
+		c.setFiringHealth()
+		return
+	}
+
+	c.firingGauge.Set(0)
+	level.Info(c.opts.Logger).Log("msg", "health rule resolved")
+	c.setHealth(component.Health{
+		Health:     component.HealthTypeHealthy,
+		Message:    "condition is false",
+		UpdateTime: now,
+	})
+}
+
+// setFiringHealth reports the component as unhealthy because the rule is
+// firing. c.mut must be held.
+func (c *Component) setFiringHealth() {
+	msg := c.args.Message
+	if msg == "" {
+		msg = "rule is firing"
+	}
+	c.setHealth(component.Health{
+		Health:     component.HealthTypeUnhealthy,
+		Message:    fmt.Sprintf("%s (condition is true since %s)", msg, c.activeSince.Format(time.RFC3339)),
+		UpdateTime: c.activeSince.Add(c.args.For),
+	})
+}
+
+// CurrentHealth implements component.HealthComponent.
+func (c *Component) CurrentHealth() component.Health {
+	c.healthMut.RLock()
+	defer c.healthMut.RUnlock()
+	return c.health
+}
+
+func (c *Component) setHealth(h component.Health) {
+	c.healthMut.Lock()
+	defer c.healthMut.Unlock()
+	c.health = h
+}
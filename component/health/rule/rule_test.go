@@ -0,0 +1,107 @@
+package rule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestComponent(t *testing.T, args Arguments) (*Component, *Exports) {
+	t.Helper()
+
+	var exports Exports
+	c, err := New(component.Options{
+		ID:     "health.rule.test",
+		Logger: util.TestFlowLogger(t),
+		OnStateChange: func(e component.Exports) {
+			exports = e.(Exports)
+		},
+		Registerer: prometheus.NewRegistry(),
+	}, args)
+	require.NoError(t, err)
+	return c, &exports
+}
+
+func TestEvaluate(t *testing.T) {
+	start := time.Now()
+	c, exports := newTestComponent(t, Arguments{Condition: false, For: time.Minute})
+	require.False(t, exports.Firing)
+	require.Equal(t, component.HealthTypeHealthy, c.CurrentHealth().Health)
+
+	// The rule is pending until the condition was true for a minute.
+	c.args.Condition = true
+	require.Equal(t, time.Minute, c.evaluate(start))
+	require.Equal(t, 30*time.Second, c.evaluate(start.Add(30*time.Second)))
+	require.False(t, exports.Firing)
+	require.Equal(t, component.HealthTypeHealthy, c.CurrentHealth().Health)
+
+	require.Zero(t, c.evaluate(start.Add(time.Minute)))
+	require.True(t, exports.Firing)
+	require.Equal(t, component.HealthTypeUnhealthy, c.CurrentHealth().Health)
+	require.Equal(t, 1.0, testutil.ToFloat64(c.firingGauge))
+	require.Equal(t, 1.0, testutil.ToFloat64(c.firedTotal))
+
+	// The rule resolves as soon as the condition is false.
+	c.args.Condition = false
+	require.Zero(t, c.evaluate(start.Add(2*time.Minute)))
+	require.False(t, exports.Firing)
+	require.Equal(t, component.HealthTypeHealthy, c.CurrentHealth().Health)
+	require.Equal(t, 0.0, testutil.ToFloat64(c.firingGauge))
+
+	// The time the condition was true before doesn't count.
+	c.args.Condition = true
+	require.Equal(t, time.Minute, c.evaluate(start.Add(3*time.Minute)))
+	require.False(t, exports.Firing)
+}
+
+func TestEvaluate_Message(t *testing.T) {
+	c, exports := newTestComponent(t, Arguments{Condition: true, Message: "too many dropped entries"})
+	require.True(t, exports.Firing)
+
+	health := c.CurrentHealth()
+	require.Equal(t, component.HealthTypeUnhealthy, health.Health)
+	require.Contains(t, health.Message, "too many dropped entries")
+}
+
+func TestRule(t *testing.T) {
+	ctrl, err := componenttest.NewControllerFromID(util.TestLogger(t), "health.rule")
+	require.NoError(t, err)
+
+	go func() {
+		err := ctrl.Run(componenttest.TestContext(t), Arguments{Condition: false})
+		require.NoError(t, err)
+	}()
+	require.NoError(t, ctrl.WaitRunning(time.Second))
+	require.NoError(t, ctrl.WaitExports(time.Second))
+	require.Equal(t, Exports{Firing: false}, ctrl.Exports())
+
+	require.NoError(t, ctrl.Update(Arguments{Condition: true, For: 50 * time.Millisecond}))
+	require.Eventually(t, func() bool {
+		return ctrl.Exports().(Exports).Firing
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, ctrl.Update(Arguments{Condition: false}))
+	require.Equal(t, Exports{Firing: false}, ctrl.Exports())
+}
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	cfg := `
+		condition = 1 > 0
+		for       = "5m"
+		message   = "something is wrong"
+	`
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+	require.Equal(t, Arguments{
+		Condition: true,
+		For:       5 * time.Minute,
+		Message:   "something is wrong",
+	}, args)
+}
@@ -0,0 +1,200 @@
+// Package enrich provides the loki.enrich component, which adds labels
+// looked up from a table to log entries.
+package enrich
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/common/loki"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "loki.enrich",
+		Args:    Arguments{},
+		Exports: Exports{},
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Arguments holds values which are used to configure the loki.enrich
+// component.
+type Arguments struct {
+	ForwardTo []loki.LogsReceiver `river:"forward_to,attr"`
+
+	// Table is the content of the lookup table, typically exported by
+	// local.file or remote.http.
+	Table  string `river:"table,attr"`
+	Format string `river:"format,attr,optional"`
+
+	// SourceLabel is the label of log entries whose value is looked up in
+	// KeyColumn of the table.
+	SourceLabel string `river:"source_label,attr"`
+	KeyColumn   string `river:"key_column,attr"`
+
+	// Labels are the columns of the table to add as labels. If empty, all
+	// columns except KeyColumn are added.
+	Labels []string `river:"labels,attr,optional"`
+
+	// OverwriteExisting controls whether labels from the table replace labels
+	// already set on log entries.
+	OverwriteExisting bool `river:"overwrite_existing,attr,optional"`
+}
+
+// DefaultArguments holds default settings for Arguments.
+var DefaultArguments = Arguments{
+	Format: FormatCSV,
+}
+
+var _ river.Unmarshaler = (*Arguments)(nil)
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(a)); err != nil {
+		return err
+	}
+
+	if !model.LabelName(a.SourceLabel).IsValid() {
+		return fmt.Errorf("source_label %q is not a valid label name", a.SourceLabel)
+	}
+	if a.KeyColumn == "" {
+		return fmt.Errorf("key_column must not be empty")
+	}
+	return nil
+}
+
+// Exports holds the values exported by the loki.enrich component.
+type Exports struct {
+	Receiver loki.LogsReceiver `river:"receiver,attr"`
+}
+
+// Component implements the loki.enrich component.
+type Component struct {
+	opts     component.Options
+	receiver loki.LogsReceiver
+
+	entriesEnriched  prometheus.Counter
+	entriesUnmatched prometheus.Counter
+	tableSize        prometheus.Gauge
+
+	mut    sync.RWMutex
+	args   Arguments
+	table  table
+	fanout []loki.LogsReceiver
+}
+
+var _ component.Component = (*Component)(nil)
+
+// New creates a new loki.enrich component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{
+		opts:     o,
+		receiver: make(loki.LogsReceiver),
+
+		entriesEnriched: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "loki_enrich_entries_enriched_total",
+			Help: "Total number of log entries whose source label was found in the lookup table",
+		}),
+		entriesUnmatched: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "loki_enrich_entries_unmatched_total",
+			Help: "Total number of log entries whose source label was missing or not found in the lookup table",
+		}),
+		tableSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "loki_enrich_table_size",
+			Help: "Number of rows in the lookup table",
+		}),
+	}
+	for _, m := range []prometheus.Collector{c.entriesEnriched, c.entriesUnmatched, c.tableSize} {
+		if err := o.Registerer.Register(m); err != nil {
+			return nil, err
+		}
+	}
+
+	// Create and immediately export the receiver which remains the same for
+	// the component's lifetime.
+	o.OnStateChange(Exports{Receiver: c.receiver})
+
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case entry := <-c.receiver:
+			c.mut.RLock()
+			entry.Labels = c.enrich(entry.Labels)
+			fanout := c.fanout
+			c.mut.RUnlock()
+
+			entry.Ack = loki.FanoutAck(entry.Ack, len(fanout))
+			for _, f := range fanout {
+				select {
+				case <-ctx.Done():
+					return nil
+				case f <- entry:
+				}
+			}
+		}
+	}
+}
+
+// enrich returns lbls with the labels of the matching row of the table
+// added. lbls is never modified, since it may be shared with other
+// receivers. enrich must be called with c.mut held.
+func (c *Component) enrich(lbls model.LabelSet) model.LabelSet {
+	key, ok := lbls[model.LabelName(c.args.SourceLabel)]
+	if !ok {
+		c.entriesUnmatched.Inc()
+		return lbls
+	}
+	row, ok := c.table[string(key)]
+	if !ok {
+		c.entriesUnmatched.Inc()
+		return lbls
+	}
+	c.entriesEnriched.Inc()
+
+	res := lbls.Clone()
+	for name, value := range row {
+		if _, exists := res[name]; exists && !c.args.OverwriteExisting {
+			continue
+		}
+		res[name] = value
+	}
+	return res
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+
+	t, err := parseTable(newArgs.Table, newArgs.Format, newArgs.KeyColumn, newArgs.Labels)
+	if err != nil {
+		return fmt.Errorf("invalid lookup table: %w", err)
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.args = newArgs
+	c.table = t
+	c.fanout = newArgs.ForwardTo
+	c.tableSize.Set(float64(len(t)))
+	return nil
+}
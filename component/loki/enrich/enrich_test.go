@@ -0,0 +1,262 @@
+package enrich
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/common/loki"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+const testCSV = `ip,owner,team
+10.0.0.1,alice,payments
+10.0.0.2,bob,
+`
+
+func TestParseTable(t *testing.T) {
+	tt := []struct {
+		name    string
+		content string
+		format  string
+		columns []string
+		expect  table
+	}{
+		{
+			name:    "csv",
+			content: testCSV,
+			format:  FormatCSV,
+			expect: table{
+				"10.0.0.1": {"owner": "alice", "team": "payments"},
+				"10.0.0.2": {"owner": "bob"},
+			},
+		},
+		{
+			name:    "csv with selected columns",
+			content: testCSV,
+			format:  FormatCSV,
+			columns: []string{"team"},
+			expect: table{
+				"10.0.0.1": {"team": "payments"},
+				"10.0.0.2": {},
+			},
+		},
+		{
+			name: "json",
+			content: `[
+				{"ip": "10.0.0.1", "owner": "alice", "team": "payments"},
+				{"ip": "10.0.0.2", "owner": "bob"}
+			]`,
+			format: FormatJSON,
+			expect: table{
+				"10.0.0.1": {"owner": "alice", "team": "payments"},
+				"10.0.0.2": {"owner": "bob"},
+			},
+		},
+		{
+			name:    "empty",
+			content: "",
+			format:  FormatCSV,
+			expect:  table{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := parseTable(tc.content, tc.format, "ip", tc.columns)
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, actual)
+		})
+	}
+}
+
+func TestParseTable_Errors(t *testing.T) {
+	tt := []struct {
+		name    string
+		content string
+		format  string
+		columns []string
+		expect  string
+	}{
+		{
+			name:    "unknown format",
+			content: testCSV,
+			format:  "yaml",
+			expect:  `unsupported format "yaml"`,
+		},
+		{
+			name:    "missing key column",
+			content: "address,owner\n10.0.0.1,alice\n",
+			format:  FormatCSV,
+			expect:  `row 1: missing key column "ip"`,
+		},
+		{
+			name:    "duplicate key",
+			content: "ip,owner\n10.0.0.1,alice\n10.0.0.1,bob\n",
+			format:  FormatCSV,
+			expect:  `row 2: duplicate key "10.0.0.1"`,
+		},
+		{
+			name:    "invalid label name",
+			content: "ip,service-owner\n10.0.0.1,alice\n",
+			format:  FormatCSV,
+			expect:  `column "service-owner" is not a valid label name`,
+		},
+		{
+			name:    "key column used as label",
+			content: testCSV,
+			format:  FormatCSV,
+			columns: []string{"ip"},
+			expect:  `key column "ip" can't be used as a label`,
+		},
+		{
+			name:    "inconsistent number of fields",
+			content: "ip,owner\n10.0.0.1\n",
+			format:  FormatCSV,
+			expect:  "wrong number of fields",
+		},
+		{
+			name:    "non-string JSON values",
+			content: `[{"ip": "10.0.0.1", "port": 8080}]`,
+			format:  FormatJSON,
+			expect:  "parsing JSON",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseTable(tc.content, tc.format, "ip", tc.columns)
+			require.ErrorContains(t, err, tc.expect)
+		})
+	}
+}
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	var args Arguments
+	err := river.Unmarshal([]byte(`
+		forward_to   = []
+		table        = ""
+		source_label = "instance-ip"
+		key_column   = "ip"
+	`), &args)
+	require.EqualError(t, err, `source_label "instance-ip" is not a valid label name`)
+}
+
+func TestComponent(t *testing.T) {
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(`
+		forward_to   = []
+		table        = "ip,owner,team\n10.0.0.1,alice,payments\n"
+		source_label = "instance"
+		key_column   = "ip"
+	`), &args))
+
+	ch := make(loki.LogsReceiver)
+	args.ForwardTo = []loki.LogsReceiver{ch}
+
+	var exports Exports
+	c, err := New(component.Options{
+		Logger:        util.TestFlowLogger(t),
+		Registerer:    prometheus.NewRegistry(),
+		OnStateChange: func(e component.Exports) { exports = e.(Exports) },
+	}, args)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+
+	send := func(lbls model.LabelSet) model.LabelSet {
+		t.Helper()
+		exports.Receiver <- loki.Entry{
+			Labels: lbls,
+			Entry:  logproto.Entry{Timestamp: time.Now(), Line: "hello"},
+		}
+		select {
+		case e := <-ch:
+			return e.Labels
+		case <-time.After(time.Second):
+			require.FailNow(t, "failed waiting for log entry")
+			return nil
+		}
+	}
+
+	// Existing labels are kept, and the received label set isn't modified.
+	in := model.LabelSet{"instance": "10.0.0.1", "team": "platform"}
+	require.Equal(t, model.LabelSet{"instance": "10.0.0.1", "owner": "alice", "team": "platform"}, send(in))
+	require.Equal(t, model.LabelSet{"instance": "10.0.0.1", "team": "platform"}, in)
+
+	// Entries without a matching row are forwarded unchanged.
+	require.Equal(t, model.LabelSet{"instance": "10.0.0.9"}, send(model.LabelSet{"instance": "10.0.0.9"}))
+	require.Equal(t, model.LabelSet{"app": "foo"}, send(model.LabelSet{"app": "foo"}))
+
+	// Reloading the table replaces its content.
+	args.Table = "ip,owner,team\n10.0.0.9,carol,search\n"
+	args.OverwriteExisting = true
+	require.NoError(t, c.Update(args))
+	require.Equal(t, model.LabelSet{"instance": "10.0.0.9", "owner": "carol", "team": "search"}, send(model.LabelSet{"instance": "10.0.0.9", "team": "platform"}))
+
+	// An invalid table is rejected.
+	args.Table = "ip,owner\n10.0.0.1\n"
+	require.Error(t, c.Update(args))
+
+	require.Equal(t, 2.0, testutil.ToFloat64(c.entriesEnriched))
+	require.Equal(t, 2.0, testutil.ToFloat64(c.entriesUnmatched))
+	require.Equal(t, 1.0, testutil.ToFloat64(c.tableSize))
+}
+
+type countingAck struct{ acks atomic.Int32 }
+
+func (c *countingAck) Ack() { c.acks.Add(1) }
+
+func TestComponent_Ack(t *testing.T) {
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(`
+		forward_to   = []
+		table        = "ip,owner,team\n10.0.0.1,alice,payments\n"
+		source_label = "instance"
+		key_column   = "ip"
+	`), &args))
+
+	ch1, ch2 := make(loki.LogsReceiver), make(loki.LogsReceiver)
+	args.ForwardTo = []loki.LogsReceiver{ch1, ch2}
+
+	var exports Exports
+	c, err := New(component.Options{
+		Logger:        util.TestFlowLogger(t),
+		Registerer:    prometheus.NewRegistry(),
+		OnStateChange: func(e component.Exports) { exports = e.(Exports) },
+	}, args)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+
+	var ack countingAck
+	exports.Receiver <- loki.Entry{
+		Labels: model.LabelSet{"instance": "10.0.0.1"},
+		Entry:  logproto.Entry{Timestamp: time.Now(), Line: "hello"},
+		Ack:    &ack,
+	}
+
+	// The entry is only acknowledged once both receivers delivered it.
+	for i, ch := range []loki.LogsReceiver{ch1, ch2} {
+		select {
+		case e := <-ch:
+			require.Equal(t, int32(0), ack.acks.Load())
+			e.Ack.Ack()
+		case <-time.After(time.Second):
+			require.FailNowf(t, "failed waiting for log entry", "receiver %d", i)
+		}
+	}
+	require.Equal(t, int32(1), ack.acks.Load())
+}
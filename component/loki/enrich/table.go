@@ -0,0 +1,125 @@
+package enrich
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+// Supported formats of lookup tables.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// table maps values of the key column to the labels of their row.
+type table map[string]model.LabelSet
+
+// parseTable parses the content of a lookup table. keyColumn is the column
+// which holds lookup keys. If columns is non-empty, only those columns are
+// turned into labels; otherwise, all columns except keyColumn are used.
+func parseTable(content, format, keyColumn string, columns []string) (table, error) {
+	var (
+		rows []map[string]string
+		err  error
+	)
+	switch format {
+	case FormatCSV:
+		rows, err = parseCSV(content)
+	case FormatJSON:
+		rows, err = parseJSON(content)
+	default:
+		return nil, fmt.Errorf("unsupported format %q, expected one of %q or %q", format, FormatCSV, FormatJSON)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, col := range columns {
+		if col == keyColumn {
+			return nil, fmt.Errorf("key column %q can't be used as a label", keyColumn)
+		}
+		if !model.LabelName(col).IsValid() {
+			return nil, fmt.Errorf("column %q is not a valid label name", col)
+		}
+	}
+
+	t := make(table, len(rows))
+	for i, row := range rows {
+		key, ok := row[keyColumn]
+		if !ok {
+			return nil, fmt.Errorf("row %d: missing key column %q", i+1, keyColumn)
+		}
+		if _, exists := t[key]; exists {
+			return nil, fmt.Errorf("row %d: duplicate key %q", i+1, key)
+		}
+
+		lbls := make(model.LabelSet)
+		if len(columns) > 0 {
+			for _, col := range columns {
+				if v := row[col]; v != "" {
+					lbls[model.LabelName(col)] = model.LabelValue(v)
+				}
+			}
+		} else {
+			for col, v := range row {
+				if col == keyColumn || v == "" {
+					continue
+				}
+				if !model.LabelName(col).IsValid() {
+					return nil, fmt.Errorf("column %q is not a valid label name", col)
+				}
+				lbls[model.LabelName(col)] = model.LabelValue(v)
+			}
+		}
+		t[key] = lbls
+	}
+	return t, nil
+}
+
+// parseCSV parses CSV content whose first record is a header naming the
+// columns.
+func parseCSV(content string) ([]map[string]string, error) {
+	r := csv.NewReader(strings.NewReader(content))
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	var rows []map[string]string
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("reading CSV: %w", err)
+		}
+
+		row := make(map[string]string, len(header))
+		for i, col := range header {
+			row[col] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseJSON parses JSON content holding an array of objects with string
+// values.
+func parseJSON(content string) ([]map[string]string, error) {
+	var rows []map[string]string
+	if err := json.Unmarshal([]byte(content), &rows); err != nil {
+		return nil, fmt.Errorf("parsing JSON: %w", err)
+	}
+	return rows, nil
+}
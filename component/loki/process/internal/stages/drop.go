@@ -91,6 +91,7 @@ func (m *dropStage) Run(in chan Entry) chan Entry {
 				continue
 			}
 			m.dropCount.WithLabelValues(m.cfg.DropReason).Inc()
+			e.Acknowledge()
 		}
 	}()
 	return out
@@ -0,0 +1,126 @@
+package stages
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/prometheus/common/model"
+)
+
+// EventLogMessageConfig represents an eventlogmessage Stage configuration.
+type EventLogMessageConfig struct {
+	Source            string `river:"source,attr,optional"`
+	DropInvalidLabels bool   `river:"drop_invalid_labels,attr,optional"`
+	OverwriteExisting bool   `river:"overwrite_existing,attr,optional"`
+}
+
+// DefaultEventLogMessageConfig sets the defaults.
+var DefaultEventLogMessageConfig = EventLogMessageConfig{
+	Source: "message",
+}
+
+var _ river.Unmarshaler = (*EventLogMessageConfig)(nil)
+
+// UnmarshalRiver implements river.Unmarshaler, applying defaults and
+// validating the provided config.
+func (args *EventLogMessageConfig) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultEventLogMessageConfig
+
+	type arguments EventLogMessageConfig
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if !model.LabelName(args.Source).IsValid() {
+		return fmt.Errorf("invalid source %q: must be a valid label name", args.Source)
+	}
+	return nil
+}
+
+// eventLogMessageStage extracts the "Key: Value" lines of the message of a
+// Windows event into the extracted map.
+type eventLogMessageStage struct {
+	cfg    *EventLogMessageConfig
+	logger log.Logger
+}
+
+// newEventLogMessageStage creates a new eventlogmessage pipeline stage from a
+// config.
+func newEventLogMessageStage(logger log.Logger, cfg EventLogMessageConfig) Stage {
+	return toStage(&eventLogMessageStage{
+		cfg:    &cfg,
+		logger: log.With(logger, "component", "stage", "type", "eventlogmessage"),
+	})
+}
+
+// Process implements Stage
+func (m *eventLogMessageStage) Process(labels model.LabelSet, extracted map[string]interface{}, t *time.Time, entry *string) {
+	if _, ok := extracted[m.cfg.Source]; !ok {
+		level.Debug(m.logger).Log("msg", "source does not exist in the set of extracted values", "source", m.cfg.Source)
+		return
+	}
+
+	value, err := getString(extracted[m.cfg.Source])
+	if err != nil {
+		level.Debug(m.logger).Log("msg", "failed to convert source value to string", "source", m.cfg.Source, "err", err, "type", reflect.TypeOf(extracted[m.cfg.Source]))
+		return
+	}
+
+	for _, line := range strings.Split(value, "\n") {
+		key, val, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		val = strings.TrimSpace(val)
+		if key == "" {
+			continue
+		}
+
+		if !model.LabelName(key).IsValid() {
+			if m.cfg.DropInvalidLabels {
+				level.Debug(m.logger).Log("msg", "dropping invalid label name", "key", key)
+				continue
+			}
+			key = sanitizeLabelName(key)
+		}
+
+		if !m.cfg.OverwriteExisting {
+			for {
+				if _, exists := extracted[key]; !exists {
+					break
+				}
+				key += "_extracted"
+			}
+		}
+		extracted[key] = val
+	}
+	level.Debug(m.logger).Log("msg", "extracted data debug in eventlogmessage stage", "extracted data", fmt.Sprintf("%v", extracted))
+}
+
+// Name implements Stage
+func (m *eventLogMessageStage) Name() string {
+	return StageTypeEventLogMessage
+}
+
+// sanitizeLabelName replaces every character of s which isn't allowed in a
+// label name with an underscore.
+func sanitizeLabelName(s string) string {
+	var sb strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			sb.WriteRune(r)
+		case r >= '0' && r <= '9' && i > 0:
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+	return sb.String()
+}
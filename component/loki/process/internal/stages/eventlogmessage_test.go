@@ -0,0 +1,97 @@
+package stages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/agent/pkg/river"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+var testEventLogMessageLine = "An account was successfully logged on.\r\n\r\nSubject:\r\n\tSecurity ID:\t\tS-1-5-18\r\n\tAccount Name:\t\tHOST$\r\nLogon Type:\t\t\t5\r\nProcess Name:\t\tC:\\Windows\\System32\\services.exe\r\n"
+
+func TestEventLogMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		config          string
+		extracted       map[string]interface{}
+		expectedExtract map[string]interface{}
+	}{
+		"sanitizes invalid label names": {
+			`stage.eventlogmessage { }`,
+			map[string]interface{}{"message": testEventLogMessageLine},
+			map[string]interface{}{
+				"message":      testEventLogMessageLine,
+				"Subject":      "",
+				"Security_ID":  "S-1-5-18",
+				"Account_Name": "HOST$",
+				"Logon_Type":   "5",
+				"Process_Name": `C:\Windows\System32\services.exe`,
+			},
+		},
+		"drops invalid label names": {
+			`stage.eventlogmessage {
+				drop_invalid_labels = true
+			}`,
+			map[string]interface{}{"message": testEventLogMessageLine},
+			map[string]interface{}{
+				"message": testEventLogMessageLine,
+				"Subject": "",
+			},
+		},
+		"keeps existing values": {
+			`stage.eventlogmessage {
+				source = "msg"
+			}`,
+			map[string]interface{}{"msg": "Level: Error\nLevel: Warning", "Level": "Info"},
+			map[string]interface{}{
+				"msg":                       "Level: Error\nLevel: Warning",
+				"Level":                     "Info",
+				"Level_extracted":           "Error",
+				"Level_extracted_extracted": "Warning",
+			},
+		},
+		"overwrites existing values": {
+			`stage.eventlogmessage {
+				source             = "msg"
+				overwrite_existing = true
+			}`,
+			map[string]interface{}{"msg": "Level: Error", "Level": "Info"},
+			map[string]interface{}{
+				"msg":   "Level: Error",
+				"Level": "Error",
+			},
+		},
+		"missing source": {
+			`stage.eventlogmessage { }`,
+			map[string]interface{}{},
+			map[string]interface{}{},
+		},
+	}
+
+	for testName, testData := range tests {
+		testData := testData
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			pl, err := NewPipeline(util_log.Logger, loadConfig(testData.config), nil, prometheus.DefaultRegisterer)
+			require.NoError(t, err)
+			out := processEntries(pl, newEntry(testData.extracted, nil, "", time.Now()))[0]
+			assert.Equal(t, testData.expectedExtract, out.Extracted)
+		})
+	}
+}
+
+func TestEventLogMessageConfigValidation(t *testing.T) {
+	var config Configs
+	err := river.Unmarshal([]byte(`stage.eventlogmessage {
+		source = "not a label"
+	}`), &config)
+	require.EqualError(t, err, `invalid source "not a label": must be a valid label name`)
+}
@@ -89,6 +89,7 @@ func (j *jsonStage) Run(in chan Entry) chan Entry {
 		for e := range in {
 			err := j.processEntry(e.Extracted, &e.Line)
 			if err != nil && j.cfg.DropMalformed {
+				e.Acknowledge()
 				continue
 			}
 			out <- e
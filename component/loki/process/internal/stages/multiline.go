@@ -85,6 +85,8 @@ type multilineState struct {
 	buffer         *bytes.Buffer // The lines of the current multiline block.
 	startLineEntry Entry         // The entry of the start line of a multiline block.
 	currentLines   uint64        // The number of lines of the current multiline block.
+
+	acks []loki.Acknowledger // The Acknowledgers of the lines of the current multiline block.
 }
 
 // newMulitlineStage creates a MulitlineStage from config
@@ -177,6 +179,9 @@ func (m *multilineStage) runMultiline(in chan Entry, out chan Entry, wg *sync.Wa
 			}
 			state.buffer.WriteString(e.Line)
 			state.currentLines++
+			if e.Ack != nil {
+				state.acks = append(state.acks, e.Ack)
+			}
 
 			if state.currentLines == m.cfg.MaxLines {
 				m.flush(out, state)
@@ -203,10 +208,12 @@ func (m *multilineStage) flush(out chan Entry, s *multilineState) {
 				Timestamp: s.startLineEntry.Entry.Entry.Timestamp,
 				Line:      s.buffer.String(),
 			},
+			Ack: loki.JoinAcks(s.acks...),
 		},
 	}
 	s.buffer.Reset()
 	s.currentLines = 0
+	s.acks = nil
 
 	out <- collapsed
 }
@@ -24,26 +24,28 @@ type StageConfig struct {
 	// external caller will not be able to construct the component Arguments by
 	// hand. This will be fixed once we've gained confidence in the ported
 	// processing stages and the package is made non-internal.
-	JSONConfig         *JSONConfig         `river:"json,block,optional"`
-	LogfmtConfig       *LogfmtConfig       `river:"logfmt,block,optional"`
-	LabelsConfig       *LabelsConfig       `river:"labels,block,optional"`
-	LabelAllowConfig   *LabelAllowConfig   `river:"label_keep,block,optional"`
-	LabelDropConfig    *LabelDropConfig    `river:"label_drop,block,optional"`
-	StaticLabelsConfig *StaticLabelsConfig `river:"static_labels,block,optional"`
-	DockerConfig       *DockerConfig       `river:"docker,block,optional"`
-	CRIConfig          *CRIConfig          `river:"cri,block,optional"`
-	RegexConfig        *RegexConfig        `river:"regex,block,optional"`
-	TimestampConfig    *TimestampConfig    `river:"timestamp,block,optional"`
-	OutputConfig       *OutputConfig       `river:"output,block,optional"`
-	ReplaceConfig      *ReplaceConfig      `river:"replace,block,optional"`
-	MultilineConfig    *MultilineConfig    `river:"multiline,block,optional"`
-	MatchConfig        *MatchConfig        `river:"match,block,optional"`
-	DropConfig         *DropConfig         `river:"drop,block,optional"`
-	PackConfig         *PackConfig         `river:"pack,block,optional"`
-	TemplateConfig     *TemplateConfig     `river:"template,block,optional"`
-	TenantConfig       *TenantConfig       `river:"tenant,block,optional"`
-	LimitConfig        *LimitConfig        `river:"limit,block,optional"`
-	MetricsConfig      *MetricsConfig      `river:"metrics,block,optional"`
+	JSONConfig            *JSONConfig            `river:"json,block,optional"`
+	LogfmtConfig          *LogfmtConfig          `river:"logfmt,block,optional"`
+	LabelsConfig          *LabelsConfig          `river:"labels,block,optional"`
+	LabelAllowConfig      *LabelAllowConfig      `river:"label_keep,block,optional"`
+	LabelDropConfig       *LabelDropConfig       `river:"label_drop,block,optional"`
+	StaticLabelsConfig    *StaticLabelsConfig    `river:"static_labels,block,optional"`
+	DockerConfig          *DockerConfig          `river:"docker,block,optional"`
+	CRIConfig             *CRIConfig             `river:"cri,block,optional"`
+	RegexConfig           *RegexConfig           `river:"regex,block,optional"`
+	TimestampConfig       *TimestampConfig       `river:"timestamp,block,optional"`
+	OutputConfig          *OutputConfig          `river:"output,block,optional"`
+	ReplaceConfig         *ReplaceConfig         `river:"replace,block,optional"`
+	MultilineConfig       *MultilineConfig       `river:"multiline,block,optional"`
+	MatchConfig           *MatchConfig           `river:"match,block,optional"`
+	DropConfig            *DropConfig            `river:"drop,block,optional"`
+	PackConfig            *PackConfig            `river:"pack,block,optional"`
+	TemplateConfig        *TemplateConfig        `river:"template,block,optional"`
+	TenantConfig          *TenantConfig          `river:"tenant,block,optional"`
+	LimitConfig           *LimitConfig           `river:"limit,block,optional"`
+	MetricsConfig         *MetricsConfig         `river:"metrics,block,optional"`
+	XMLConfig             *XMLConfig             `river:"xml,block,optional"`
+	EventLogMessageConfig *EventLogMessageConfig `river:"eventlogmessage,block,optional"`
 }
 
 var rateLimiter *rate.Limiter
@@ -96,6 +98,7 @@ func RunWithSkip(input chan Entry, process func(e Entry) (Entry, bool)) chan Ent
 		for e := range input {
 			ee, skip := process(e)
 			if skip {
+				e.Acknowledge()
 				continue
 			}
 			out <- ee
@@ -142,6 +145,7 @@ func (p *Pipeline) Wrap(next loki.EntryHandler) loki.EntryHandler {
 				if rateLimiterDrop {
 					if !rateLimiter.Allow() {
 						p.dropCount.WithLabelValues(rateLimiterDropReason).Inc()
+						e.Acknowledge()
 						continue
 					}
 				} else {
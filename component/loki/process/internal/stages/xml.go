@@ -0,0 +1,322 @@
+package stages
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/common/model"
+)
+
+// Config Errors
+var (
+	ErrEmptyXMLStageConfig = errors.New("empty xml stage configuration")
+	ErrXMLExpressionsEmpty = errors.New("xml expressions are required")
+	ErrEmptyXMLStageSource = errors.New("empty source")
+	ErrInvalidXMLPath      = errors.New("invalid xml path")
+)
+
+// XMLConfig represents an XML Stage configuration.
+type XMLConfig struct {
+	Expressions map[string]string `river:"expressions,attr"`
+	Source      *string           `river:"source,attr,optional"`
+}
+
+// validateXMLConfig validates an xml stage config and returns the parsed
+// path of every expression.
+func validateXMLConfig(c *XMLConfig) (map[string]xmlPath, error) {
+	if c == nil {
+		return nil, ErrEmptyXMLStageConfig
+	}
+
+	if len(c.Expressions) == 0 {
+		return nil, ErrXMLExpressionsEmpty
+	}
+
+	if c.Source != nil && *c.Source == "" {
+		return nil, ErrEmptyXMLStageSource
+	}
+
+	paths := make(map[string]xmlPath, len(c.Expressions))
+	for n, e := range c.Expressions {
+		// If there is no expression, use the name as the expression.
+		if e == "" {
+			e = n
+		}
+		p, err := parseXMLPath(e)
+		if err != nil {
+			return nil, fmt.Errorf("%v %q: %w", ErrInvalidXMLPath, e, err)
+		}
+		paths[n] = p
+	}
+	return paths, nil
+}
+
+// xmlStage sets extracted data using paths into an XML document.
+type xmlStage struct {
+	cfg    *XMLConfig
+	paths  map[string]xmlPath
+	logger log.Logger
+}
+
+// newXMLStage creates a new xml pipeline stage from a config.
+func newXMLStage(logger log.Logger, cfg XMLConfig) (Stage, error) {
+	paths, err := validateXMLConfig(&cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return toStage(&xmlStage{
+		cfg:    &cfg,
+		paths:  paths,
+		logger: log.With(logger, "component", "stage", "type", "xml"),
+	}), nil
+}
+
+// Process implements Stage
+func (x *xmlStage) Process(labels model.LabelSet, extracted map[string]interface{}, t *time.Time, entry *string) {
+	// If a source key is provided, the xml stage should process it
+	// from the extracted map, otherwise should fallback to the entry
+	input := entry
+
+	if x.cfg.Source != nil {
+		if _, ok := extracted[*x.cfg.Source]; !ok {
+			level.Debug(x.logger).Log("msg", "source does not exist in the set of extracted values", "source", *x.cfg.Source)
+			return
+		}
+
+		value, err := getString(extracted[*x.cfg.Source])
+		if err != nil {
+			level.Debug(x.logger).Log("msg", "failed to convert source value to string", "source", *x.cfg.Source, "err", err, "type", reflect.TypeOf(extracted[*x.cfg.Source]))
+			return
+		}
+
+		input = &value
+	}
+
+	if input == nil {
+		level.Debug(x.logger).Log("msg", "cannot parse a nil entry")
+		return
+	}
+
+	root, err := parseXMLDocument(*input)
+	if err != nil {
+		level.Debug(x.logger).Log("msg", "failed to parse xml", "err", err)
+		return
+	}
+
+	for n, p := range x.paths {
+		if v, ok := p.eval(root); ok {
+			extracted[n] = v
+		}
+	}
+	level.Debug(x.logger).Log("msg", "extracted data debug in xml stage", "extracted data", fmt.Sprintf("%v", extracted))
+}
+
+// Name implements Stage
+func (x *xmlStage) Name() string {
+	return StageTypeXML
+}
+
+// xmlNode is an element of a parsed XML document. Namespaces are ignored and
+// elements and attributes are referred to by their local names.
+type xmlNode struct {
+	name     string
+	attrs    map[string]string
+	children []*xmlNode
+	text     strings.Builder
+}
+
+// parseXMLDocument parses s and returns a node whose only child is the root
+// element of the document.
+func parseXMLDocument(s string) (*xmlNode, error) {
+	var (
+		doc   = &xmlNode{}
+		stack = []*xmlNode{doc}
+	)
+
+	dec := xml.NewDecoder(strings.NewReader(s))
+	dec.Strict = false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		top := stack[len(stack)-1]
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{name: tok.Name.Local, attrs: make(map[string]string, len(tok.Attr))}
+			for _, a := range tok.Attr {
+				n.attrs[a.Name.Local] = a.Value
+			}
+			top.children = append(top.children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("unexpected end element %q", tok.Name.Local)
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			top.text.Write(tok)
+		}
+	}
+
+	if len(doc.children) == 0 {
+		return nil, errors.New("document has no root element")
+	}
+	return doc, nil
+}
+
+// xmlPath is a parsed path into an XML document. It supports a small subset
+// of XPath: element names or '*' separated by '/', each optionally followed
+// by a predicate ([@attr='value'] or a 1-based [index]), with an optional
+// final @attr step to select an attribute instead of the element text.
+type xmlPath struct {
+	steps []xmlStep
+	attr  string
+}
+
+type xmlStep struct {
+	name string
+
+	// Predicates; at most one of them is set.
+	attrName, attrValue string
+	index               int
+}
+
+func parseXMLPath(s string) (xmlPath, error) {
+	var p xmlPath
+
+	s = strings.TrimPrefix(s, "/")
+	if s == "" {
+		return p, errors.New("path is empty")
+	}
+
+	parts := strings.Split(s, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, "@") {
+			if i != len(parts)-1 {
+				return p, errors.New("attribute must be the last step")
+			}
+			p.attr = strings.TrimPrefix(part, "@")
+			if p.attr == "" {
+				return p, errors.New("attribute name is empty")
+			}
+			break
+		}
+
+		step, err := parseXMLStep(part)
+		if err != nil {
+			return p, err
+		}
+		p.steps = append(p.steps, step)
+	}
+
+	if len(p.steps) == 0 {
+		return p, errors.New("path must select at least one element")
+	}
+	return p, nil
+}
+
+func parseXMLStep(s string) (xmlStep, error) {
+	var step xmlStep
+
+	name, pred, hasPred := strings.Cut(s, "[")
+	if name == "" {
+		return step, fmt.Errorf("step %q has no element name", s)
+	}
+	step.name = name
+	if !hasPred {
+		return step, nil
+	}
+
+	if !strings.HasSuffix(pred, "]") {
+		return step, fmt.Errorf("step %q has an unterminated predicate", s)
+	}
+	pred = strings.TrimSuffix(pred, "]")
+
+	if strings.HasPrefix(pred, "@") {
+		attr, value, ok := strings.Cut(strings.TrimPrefix(pred, "@"), "=")
+		if !ok || attr == "" {
+			return step, fmt.Errorf("step %q has an invalid attribute predicate", s)
+		}
+		unquoted, ok := unquoteXMLPathValue(strings.TrimSpace(value))
+		if !ok {
+			return step, fmt.Errorf("step %q has an unquoted attribute value", s)
+		}
+		step.attrName, step.attrValue = strings.TrimSpace(attr), unquoted
+		return step, nil
+	}
+
+	index, err := strconv.Atoi(pred)
+	if err != nil || index < 1 {
+		return step, fmt.Errorf("step %q has an invalid predicate", s)
+	}
+	step.index = index
+	return step, nil
+}
+
+func unquoteXMLPathValue(s string) (string, bool) {
+	if len(s) < 2 {
+		return "", false
+	}
+	if q := s[0]; (q == '\'' || q == '"') && s[len(s)-1] == q {
+		return s[1 : len(s)-1], true
+	}
+	return "", false
+}
+
+// eval returns the value selected by p in the document doc. If p matches
+// several elements, the first one is used.
+func (p xmlPath) eval(doc *xmlNode) (string, bool) {
+	nodes := []*xmlNode{doc}
+	for _, step := range p.steps {
+		var next []*xmlNode
+		for _, n := range nodes {
+			next = append(next, step.match(n.children)...)
+		}
+		if len(next) == 0 {
+			return "", false
+		}
+		nodes = next
+	}
+
+	n := nodes[0]
+	if p.attr != "" {
+		v, ok := n.attrs[p.attr]
+		return v, ok
+	}
+	return strings.TrimSpace(n.text.String()), true
+}
+
+// match returns the nodes which match s.
+func (s xmlStep) match(nodes []*xmlNode) []*xmlNode {
+	var res []*xmlNode
+	for _, n := range nodes {
+		if s.name != "*" && s.name != n.name {
+			continue
+		}
+		if s.attrName != "" && n.attrs[s.attrName] != s.attrValue {
+			continue
+		}
+		res = append(res, n)
+	}
+
+	if s.index > 0 {
+		if s.index > len(res) {
+			return nil
+		}
+		return res[s.index-1 : s.index]
+	}
+	return res
+}
@@ -0,0 +1,164 @@
+package stages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/agent/pkg/util"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+var testXMLEventLine = `<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'>
+	<System>
+		<Provider Name='Microsoft-Windows-Security-Auditing' Guid='{54849625-5478-4994-a5ba-3e3b0328c30d}'/>
+		<EventID>4624</EventID>
+		<Level>0</Level>
+		<TimeCreated SystemTime='2023-01-24T12:00:00.0000000Z'/>
+		<Computer>host.example.com</Computer>
+	</System>
+	<EventData>
+		<Data Name='SubjectUserSid'>S-1-5-18</Data>
+		<Data Name='TargetUserName'>alice</Data>
+		<Data Name='LogonType'>5</Data>
+	</EventData>
+</Event>`
+
+var testXMLRiverSingleStage = `
+stage.xml {
+		expressions = {
+			"event_id"  = "Event/System/EventID",
+			"provider"  = "Event/System/Provider/@Name",
+			"user"      = "Event/EventData/Data[@Name='TargetUserName']",
+			"first"     = "Event/EventData/Data[1]",
+			"missing"   = "Event/System/Missing",
+		}
+}`
+
+var testXMLRiverMultiStageWithSource = `
+stage.json {
+		expressions = { "xml" = "" }
+}
+stage.xml {
+		expressions = { "computer" = "/Event/System/Computer" }
+		source      = "xml"
+}`
+
+func TestXML(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		config          string
+		entry           string
+		expectedExtract map[string]interface{}
+	}{
+		"successfully run a pipeline with 1 xml stage without source": {
+			testXMLRiverSingleStage,
+			testXMLEventLine,
+			map[string]interface{}{
+				"event_id": "4624",
+				"provider": "Microsoft-Windows-Security-Auditing",
+				"user":     "alice",
+				"first":    "S-1-5-18",
+			},
+		},
+		"successfully run a pipeline with an xml stage with source": {
+			testXMLRiverMultiStageWithSource,
+			`{"xml":"<Event><System><Computer>host.example.com</Computer></System></Event>"}`,
+			map[string]interface{}{
+				"xml":      "<Event><System><Computer>host.example.com</Computer></System></Event>",
+				"computer": "host.example.com",
+			},
+		},
+		"malformed xml is ignored": {
+			testXMLRiverSingleStage,
+			`this is not xml`,
+			map[string]interface{}{},
+		},
+	}
+
+	for testName, testData := range tests {
+		testData := testData
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			pl, err := NewPipeline(util_log.Logger, loadConfig(testData.config), nil, prometheus.DefaultRegisterer)
+			require.NoError(t, err)
+			out := processEntries(pl, newEntry(nil, nil, testData.entry, time.Now()))[0]
+			assert.Equal(t, testData.expectedExtract, out.Extracted)
+		})
+	}
+}
+
+func TestXMLConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	emptySource := ""
+	tests := map[string]struct {
+		config XMLConfig
+		err    string
+	}{
+		"no expressions": {
+			XMLConfig{},
+			ErrXMLExpressionsEmpty.Error(),
+		},
+		"empty source": {
+			XMLConfig{Expressions: map[string]string{"foo": ""}, Source: &emptySource},
+			ErrEmptyXMLStageSource.Error(),
+		},
+		"attribute is not the last step": {
+			XMLConfig{Expressions: map[string]string{"foo": "Event/@Name/System"}},
+			`invalid xml path "Event/@Name/System": attribute must be the last step`,
+		},
+		"unquoted attribute value": {
+			XMLConfig{Expressions: map[string]string{"foo": "Event/Data[@Name=User]"}},
+			`invalid xml path "Event/Data[@Name=User]": step "Data[@Name=User]" has an unquoted attribute value`,
+		},
+		"invalid index": {
+			XMLConfig{Expressions: map[string]string{"foo": "Event/Data[0]"}},
+			`invalid xml path "Event/Data[0]": step "Data[0]" has an invalid predicate`,
+		},
+		"valid": {
+			XMLConfig{Expressions: map[string]string{"Event": "", "id": "Event/System/EventID"}},
+			"",
+		},
+	}
+	for tName, tt := range tests {
+		tt := tt
+		t.Run(tName, func(t *testing.T) {
+			_, err := validateXMLConfig(&tt.config)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestXMLParser_Parse(t *testing.T) {
+	t.Parallel()
+	logger := util.TestFlowLogger(t)
+
+	source := "log"
+	p, err := New(logger, nil, StageConfig{XMLConfig: &XMLConfig{
+		Expressions: map[string]string{
+			"logon_type": "*/EventData/Data[@Name=\"LogonType\"]",
+			"time":       "Event/System/TimeCreated/@SystemTime",
+		},
+		Source: &source,
+	}}, nil)
+	require.NoError(t, err)
+
+	extracted := map[string]interface{}{"log": testXMLEventLine}
+	out := processEntries(p, newEntry(extracted, nil, "{}", time.Now()))[0]
+	assert.Equal(t, map[string]interface{}{
+		"log":        testXMLEventLine,
+		"logon_type": "5",
+		"time":       "2023-01-24T12:00:00.0000000Z",
+	}, out.Extracted)
+}
@@ -147,6 +147,7 @@ func (c *Component) handleOut(ctx context.Context, wg *sync.WaitGroup) {
 			return
 		case entry := <-c.processOut:
 			c.mut.RLock()
+			entry.Ack = loki.FanoutAck(entry.Ack, len(c.fanout))
 			for _, f := range c.fanout {
 				select {
 				case <-ctx.Done():
@@ -3,14 +3,20 @@ package relabel
 import (
 	"github.com/prometheus/client_golang/prometheus"
 	prometheus_client "github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/atomic"
 )
 
 type metrics struct {
 	entriesProcessed prometheus_client.Counter
 	entriesOutgoing  prometheus_client.Counter
+	entriesDropped   prometheus_client.Counter
 	cacheHits        prometheus_client.Counter
 	cacheMisses      prometheus_client.Counter
 	cacheSize        prometheus_client.Gauge
+	cacheHitRatio    prometheus_client.GaugeFunc
+
+	// Totals used for computing cacheHitRatio.
+	hits, lookups atomic.Uint64
 }
 
 // newMetrics creates a new set of metrics. If reg is non-nil, the metrics
@@ -26,6 +32,10 @@ func newMetrics(reg prometheus.Registerer) *metrics {
 		Name: "loki_relabel_entries_written",
 		Help: "Total number of log entries forwarded",
 	})
+	m.entriesDropped = prometheus_client.NewCounter(prometheus_client.CounterOpts{
+		Name: "loki_relabel_entries_dropped",
+		Help: "Total number of log entries dropped because relabeling removed all of their labels",
+	})
 	m.cacheMisses = prometheus_client.NewCounter(prometheus_client.CounterOpts{
 		Name: "loki_relabel_cache_misses",
 		Help: "Total number of cache misses",
@@ -38,16 +48,41 @@ func newMetrics(reg prometheus.Registerer) *metrics {
 		Name: "loki_relabel_cache_size",
 		Help: "Total size of relabel cache",
 	})
+	m.cacheHitRatio = prometheus_client.NewGaugeFunc(prometheus_client.GaugeOpts{
+		Name: "loki_relabel_cache_hit_ratio",
+		Help: "Ratio of cache lookups which were hits since the component started",
+	}, func() float64 {
+		lookups := m.lookups.Load()
+		if lookups == 0 {
+			return 0
+		}
+		return float64(m.hits.Load()) / float64(lookups)
+	})
 
 	if reg != nil {
 		reg.MustRegister(
 			m.entriesProcessed,
 			m.entriesOutgoing,
+			m.entriesDropped,
 			m.cacheMisses,
 			m.cacheHits,
 			m.cacheSize,
+			m.cacheHitRatio,
 		)
 	}
 
 	return &m
 }
+
+// cacheHit records a cache lookup which found a relabeled label set.
+func (m *metrics) cacheHit() {
+	m.cacheHits.Inc()
+	m.hits.Inc()
+	m.lookups.Inc()
+}
+
+// cacheMiss records a cache lookup which required evaluating the rules.
+func (m *metrics) cacheMiss() {
+	m.cacheMisses.Inc()
+	m.lookups.Inc()
+}
@@ -2,6 +2,7 @@ package relabel
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"sync"
 
@@ -33,6 +34,10 @@ type Arguments struct {
 	// Where the relabeled metrics should be forwarded to.
 	ForwardTo []loki.LogsReceiver `river:"forward_to,attr"`
 
+	// Relabelling rules exported by another component, applied before the
+	// rules defined in RelabelConfigs.
+	Rules flow_relabel.Rules `river:"rules,attr,optional"`
+
 	// The relabelling rules to apply to each log entry before it's forwarded.
 	RelabelConfigs []*flow_relabel.Config `river:"rule,block,optional"`
 
@@ -53,7 +58,22 @@ func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
 	*a = DefaultArguments
 
 	type arguments Arguments
-	return f((*arguments)(a))
+	if err := f((*arguments)(a)); err != nil {
+		return err
+	}
+
+	if a.MaxCacheSize <= 0 {
+		return fmt.Errorf("max_cache_size must be greater than 0")
+	}
+	return nil
+}
+
+// allRules returns the imported rules followed by the rules defined in rule
+// blocks.
+func (a Arguments) allRules() flow_relabel.Rules {
+	res := make(flow_relabel.Rules, 0, len(a.Rules)+len(a.RelabelConfigs))
+	res = append(res, a.Rules...)
+	return append(res, a.RelabelConfigs...)
 }
 
 // Exports holds values which are exported by the loki.relabel component.
@@ -97,7 +117,7 @@ func New(o component.Options, args Arguments) (*Component, error) {
 	// Create and immediately export the receiver which remains the same for
 	// the component's lifetime.
 	c.receiver = make(loki.LogsReceiver)
-	o.OnStateChange(Exports{Receiver: c.receiver, Rules: args.RelabelConfigs})
+	o.OnStateChange(Exports{Receiver: c.receiver, Rules: args.allRules()})
 
 	// Call to Update() to set the relabelling rules once at the start.
 	if err := c.Update(args); err != nil {
@@ -115,15 +135,23 @@ func (c *Component) Run(ctx context.Context) error {
 			return nil
 		case entry := <-c.receiver:
 			c.metrics.entriesProcessed.Inc()
+
+			c.mut.RLock()
 			lbls := c.relabel(entry)
+			fanout := c.fanout
+			c.mut.RUnlock()
+
 			if len(lbls) == 0 {
+				c.metrics.entriesDropped.Inc()
 				level.Debug(c.opts.Logger).Log("msg", "dropping entry after relabeling", "labels", entry.Labels.String())
+				entry.Acknowledge()
 				continue
 			}
 
 			c.metrics.entriesOutgoing.Inc()
 			entry.Labels = lbls
-			for _, f := range c.fanout {
+			entry.Ack = loki.FanoutAck(entry.Ack, len(fanout))
+			for _, f := range fanout {
 				select {
 				case <-ctx.Done():
 					return nil
@@ -140,7 +168,8 @@ func (c *Component) Update(args component.Arguments) error {
 	defer c.mut.Unlock()
 
 	newArgs := args.(Arguments)
-	newRCS := flow_relabel.ComponentToPromRelabelConfigs(newArgs.RelabelConfigs)
+	rules := newArgs.allRules()
+	newRCS := flow_relabel.ComponentToPromRelabelConfigs(rules)
 	if relabelingChanged(c.rcs, newRCS) {
 		level.Debug(c.opts.Logger).Log("msg", "received new relabel configs, purging cache")
 		c.cache.Purge()
@@ -151,11 +180,13 @@ func (c *Component) Update(args component.Arguments) error {
 		if evicted > 0 {
 			level.Debug(c.opts.Logger).Log("msg", "resizing the cache lead to evicting of items", "len_items_evicted", evicted)
 		}
+		c.maxCacheSize = newArgs.MaxCacheSize
+		c.metrics.cacheSize.Set(float64(c.cache.Len()))
 	}
 	c.rcs = newRCS
 	c.fanout = newArgs.ForwardTo
 
-	c.opts.OnStateChange(Exports{Receiver: c.receiver, Rules: newArgs.RelabelConfigs})
+	c.opts.OnStateChange(Exports{Receiver: c.receiver, Rules: rules})
 
 	return nil
 }
@@ -192,14 +223,14 @@ func (c *Component) relabel(e loki.Entry) model.LabelSet {
 	if found {
 		for _, ci := range val.([]cacheItem) {
 			if e.Labels.Equal(ci.original) {
-				c.metrics.cacheHits.Inc()
+				c.metrics.cacheHit()
 				return ci.relabeled
 			}
 		}
 	}
 
 	// Seems like it's either a new entry or a hash collision.
-	c.metrics.cacheMisses.Inc()
+	c.metrics.cacheMiss()
 	relabeled := c.process(e)
 
 	// In case it's a new hash, initialize it as a new cacheItem.
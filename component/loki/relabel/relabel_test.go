@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/agent/pkg/util"
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/relabel"
 
@@ -332,6 +333,102 @@ func TestRuleGetter(t *testing.T) {
 	require.Equal(t, gotUpdated[0].Regex, gotOriginal[0].Regex)
 }
 
+func TestImportedRules(t *testing.T) {
+	// The imported rules come from the rc ruleset, as exported by another
+	// component such as discovery.relabel.
+	type cfg struct {
+		Rcs []*flow_relabel.Config `river:"rule,block,optional"`
+	}
+	var imported cfg
+	require.NoError(t, river.Unmarshal([]byte(rc), &imported))
+
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(`
+		forward_to = []
+		rule {
+			source_labels = ["environment"]
+			target_label  = "env"
+			action        = "replace"
+		}`), &args))
+	args.Rules = imported.Rcs
+
+	var exports Exports
+	c, err := New(component.Options{
+		Logger:        util.TestFlowLogger(t),
+		Registerer:    prometheus.NewRegistry(),
+		OnStateChange: func(e component.Exports) { exports = e.(Exports) },
+	}, args)
+	require.NoError(t, err)
+
+	// Imported rules run before the component's own rules, so the env label
+	// can be copied from the environment label set by the imported rules.
+	entry := loki.Entry{Labels: model.LabelSet{"kubernetes_namespace": "dev"}}
+	require.Equal(t, model.LabelSet{
+		"namespace":   "dev",
+		"environment": "dev",
+		"env":         "dev",
+	}, c.relabel(entry))
+
+	require.Len(t, exports.Rules, len(imported.Rcs)+1)
+	require.Equal(t, imported.Rcs[0], exports.Rules[0])
+}
+
+func TestMetrics(t *testing.T) {
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(`
+		forward_to = []
+		rule {
+			source_labels = ["drop"]
+			regex         = "true"
+			action        = "drop"
+		}`), &args))
+
+	ch := make(loki.LogsReceiver)
+	args.ForwardTo = []loki.LogsReceiver{ch}
+
+	c, err := New(component.Options{
+		Logger:        util.TestFlowLogger(t),
+		Registerer:    prometheus.NewRegistry(),
+		OnStateChange: func(e component.Exports) {},
+	}, args)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+
+	for _, lset := range []model.LabelSet{
+		{"app": "foo"},
+		{"app": "foo"},
+		{"app": "foo"},
+		{"app": "bar", "drop": "true"},
+	} {
+		e := getEntry()
+		e.Labels = lset
+		c.receiver <- e
+		if _, ok := lset["drop"]; !ok {
+			<-ch
+		}
+	}
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(c.metrics.entriesDropped) == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, 4.0, testutil.ToFloat64(c.metrics.entriesProcessed))
+	require.Equal(t, 3.0, testutil.ToFloat64(c.metrics.entriesOutgoing))
+	require.Equal(t, 2.0, testutil.ToFloat64(c.metrics.cacheHits))
+	require.Equal(t, 2.0, testutil.ToFloat64(c.metrics.cacheMisses))
+	require.Equal(t, 0.5, testutil.ToFloat64(c.metrics.cacheHitRatio))
+}
+
+func TestInvalidCacheSize(t *testing.T) {
+	var args Arguments
+	err := river.Unmarshal([]byte(`
+		forward_to     = []
+		max_cache_size = 0`), &args)
+	require.EqualError(t, err, "max_cache_size must be greater than 0")
+}
+
 func getEntry() loki.Entry {
 	return loki.Entry{
 		Labels: model.LabelSet{},
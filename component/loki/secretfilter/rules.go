@@ -0,0 +1,63 @@
+package secretfilter
+
+// defaultRules are the built-in rules used when include_default_rules is
+// true. The rules are adapted from the gitleaks project and cover well-known
+// token formats which can be matched with a low rate of false positives.
+var defaultRules = []Rule{
+	{
+		Name:        "aws-access-key-id",
+		Regex:       `\b((?:A3T[A-Z0-9]|AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16})\b`,
+		SecretGroup: 1,
+		Keywords:    []string{"a3t", "akia", "asia", "abia", "acca"},
+	},
+	{
+		Name:     "github-token",
+		Regex:    `\bgh[pousr]_[0-9a-zA-Z]{36}\b`,
+		Keywords: []string{"ghp_", "gho_", "ghu_", "ghs_", "ghr_"},
+	},
+	{
+		Name:     "github-fine-grained-token",
+		Regex:    `\bgithub_pat_[0-9a-zA-Z_]{82}\b`,
+		Keywords: []string{"github_pat_"},
+	},
+	{
+		Name:     "gitlab-token",
+		Regex:    `\bglpat-[0-9a-zA-Z_-]{20}\b`,
+		Keywords: []string{"glpat-"},
+	},
+	{
+		Name:     "gcp-api-key",
+		Regex:    `\bAIza[0-9A-Za-z_-]{35}\b`,
+		Keywords: []string{"aiza"},
+	},
+	{
+		Name:     "grafana-cloud-token",
+		Regex:    `\bglc_[A-Za-z0-9+/]{32,400}={0,2}`,
+		Keywords: []string{"glc_"},
+	},
+	{
+		Name:     "grafana-service-account-token",
+		Regex:    `\bglsa_[A-Za-z0-9]{32}_[A-Fa-f0-9]{8}\b`,
+		Keywords: []string{"glsa_"},
+	},
+	{
+		Name:     "jwt",
+		Regex:    `\beyJ[0-9A-Za-z_-]{10,}\.eyJ[0-9A-Za-z_-]{10,}\.[0-9A-Za-z_-]{10,}`,
+		Keywords: []string{"eyj"},
+	},
+	{
+		Name:     "private-key",
+		Regex:    `-----BEGIN[ A-Z0-9_-]{0,100}PRIVATE KEY(?: BLOCK)?-----[\s\S]*?-----END[ A-Z0-9_-]{0,100}PRIVATE KEY(?: BLOCK)?-----`,
+		Keywords: []string{"private key"},
+	},
+	{
+		Name:     "slack-token",
+		Regex:    `\bxox[baprs]-[0-9a-zA-Z-]{10,48}\b`,
+		Keywords: []string{"xoxb-", "xoxa-", "xoxp-", "xoxr-", "xoxs-"},
+	},
+	{
+		Name:     "stripe-key",
+		Regex:    `\b(?:sk|rk)_(?:test|live)_[0-9a-zA-Z]{10,99}\b`,
+		Keywords: []string{"sk_test_", "sk_live_", "rk_test_", "rk_live_"},
+	},
+}
@@ -0,0 +1,307 @@
+// Package secretfilter provides the loki.secretfilter component, which
+// redacts secrets from log lines before they are forwarded.
+package secretfilter
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/common/loki"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "loki.secretfilter",
+		Args:    Arguments{},
+		Exports: Exports{},
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// secretNamePlaceholder is replaced by the name of the matching rule in the
+// redaction text.
+const secretNamePlaceholder = "$SECRET_NAME"
+
+// Arguments holds values which are used to configure the loki.secretfilter
+// component.
+type Arguments struct {
+	ForwardTo []loki.LogsReceiver `river:"forward_to,attr"`
+
+	// IncludeDefaultRules enables the built-in set of rules for well-known
+	// secret formats.
+	IncludeDefaultRules bool `river:"include_default_rules,attr,optional"`
+
+	// RedactWith is the text which replaces redacted secrets.
+	RedactWith string `river:"redact_with,attr,optional"`
+
+	// Allowlist holds regular expressions for values which must never be
+	// redacted, even if they match a rule.
+	Allowlist []string `river:"allowlist,attr,optional"`
+
+	Rules []Rule `river:"rule,block,optional"`
+}
+
+// Rule describes a secret to look for in log lines.
+type Rule struct {
+	Name  string `river:"name,attr"`
+	Regex string `river:"regex,attr"`
+
+	// SecretGroup is the index of the capture group in Regex holding the
+	// secret. If 0, the entire match is redacted.
+	SecretGroup int `river:"secret_group,attr,optional"`
+
+	// Keywords are used to cheaply skip lines which can't contain the secret.
+	// If set, Regex is only evaluated against lines containing at least one of
+	// the keywords, compared case-insensitively.
+	Keywords []string `river:"keywords,attr,optional"`
+}
+
+// DefaultArguments holds default settings for Arguments.
+var DefaultArguments = Arguments{
+	IncludeDefaultRules: true,
+	RedactWith:          "<REDACTED:" + secretNamePlaceholder + ">",
+}
+
+var _ river.Unmarshaler = (*Arguments)(nil)
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(a)); err != nil {
+		return err
+	}
+
+	_, err := newFilter(*a)
+	return err
+}
+
+// Exports holds the values exported by the loki.secretfilter component.
+type Exports struct {
+	Receiver loki.LogsReceiver `river:"receiver,attr"`
+}
+
+// Component implements the loki.secretfilter component.
+type Component struct {
+	opts     component.Options
+	receiver loki.LogsReceiver
+
+	secretsRedacted *prometheus.CounterVec
+
+	mut    sync.RWMutex
+	filter *filter
+	fanout []loki.LogsReceiver
+}
+
+var _ component.Component = (*Component)(nil)
+
+// New creates a new loki.secretfilter component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{
+		opts:     o,
+		receiver: make(loki.LogsReceiver),
+
+		secretsRedacted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "loki_secretfilter_secrets_redacted_total",
+			Help: "Total number of secrets redacted from log lines",
+		}, []string{"rule"}),
+	}
+	if err := o.Registerer.Register(c.secretsRedacted); err != nil {
+		return nil, err
+	}
+
+	// Create and immediately export the receiver which remains the same for
+	// the component's lifetime.
+	o.OnStateChange(Exports{Receiver: c.receiver})
+
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case entry := <-c.receiver:
+			c.mut.RLock()
+			entry.Line = c.filter.redact(entry.Line, c.secretsRedacted)
+			fanout := c.fanout
+			c.mut.RUnlock()
+
+			entry.Ack = loki.FanoutAck(entry.Ack, len(fanout))
+			for _, f := range fanout {
+				select {
+				case <-ctx.Done():
+					return nil
+				case f <- entry:
+				}
+			}
+		}
+	}
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+
+	f, err := newFilter(newArgs)
+	if err != nil {
+		return err
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.filter = f
+	c.fanout = newArgs.ForwardTo
+	return nil
+}
+
+// filter redacts secrets from log lines.
+type filter struct {
+	rules      []compiledRule
+	allowlist  []*regexp.Regexp
+	redactWith string
+
+	// hasKeywords is true if any rule has keywords, in which case lines are
+	// lowercased once before rules are evaluated.
+	hasKeywords bool
+}
+
+type compiledRule struct {
+	name        string
+	re          *regexp.Regexp
+	secretGroup int
+	keywords    []string
+}
+
+func newFilter(args Arguments) (*filter, error) {
+	f := &filter{redactWith: args.RedactWith}
+
+	rules := args.Rules
+	if args.IncludeDefaultRules {
+		rules = append(append([]Rule{}, defaultRules...), rules...)
+	}
+
+	names := make(map[string]struct{}, len(rules))
+	for _, r := range rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("rule name must not be empty")
+		}
+		if _, ok := names[r.Name]; ok {
+			return nil, fmt.Errorf("rule %q is defined more than once", r.Name)
+		}
+		names[r.Name] = struct{}{}
+
+		re, err := regexp.Compile(r.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex for rule %q: %w", r.Name, err)
+		}
+		if r.SecretGroup < 0 || r.SecretGroup > re.NumSubexp() {
+			return nil, fmt.Errorf("secret_group %d of rule %q does not exist in its regex", r.SecretGroup, r.Name)
+		}
+
+		keywords := make([]string, 0, len(r.Keywords))
+		for _, kw := range r.Keywords {
+			keywords = append(keywords, strings.ToLower(kw))
+		}
+		if len(keywords) > 0 {
+			f.hasKeywords = true
+		}
+
+		f.rules = append(f.rules, compiledRule{
+			name:        r.Name,
+			re:          re,
+			secretGroup: r.SecretGroup,
+			keywords:    keywords,
+		})
+	}
+
+	for _, expr := range args.Allowlist {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist regex %q: %w", expr, err)
+		}
+		f.allowlist = append(f.allowlist, re)
+	}
+
+	return f, nil
+}
+
+// redact returns line with all secrets replaced. The number of redacted
+// secrets per rule is added to counter.
+func (f *filter) redact(line string, counter *prometheus.CounterVec) string {
+	var lower string
+	if f.hasKeywords {
+		lower = strings.ToLower(line)
+	}
+
+	for _, r := range f.rules {
+		if len(r.keywords) > 0 && !containsAny(lower, r.keywords) {
+			continue
+		}
+
+		matches := r.re.FindAllStringSubmatchIndex(line, -1)
+		if len(matches) == 0 {
+			continue
+		}
+
+		var (
+			sb       strings.Builder
+			last     int
+			redacted int
+		)
+		for _, m := range matches {
+			start, end := m[2*r.secretGroup], m[2*r.secretGroup+1]
+			if start < 0 || f.allowed(line[start:end]) {
+				continue
+			}
+			sb.WriteString(line[last:start])
+			sb.WriteString(strings.ReplaceAll(f.redactWith, secretNamePlaceholder, r.name))
+			last = end
+			redacted++
+		}
+		if redacted == 0 {
+			continue
+		}
+		sb.WriteString(line[last:])
+
+		line = sb.String()
+		if f.hasKeywords {
+			lower = strings.ToLower(line)
+		}
+		counter.WithLabelValues(r.name).Add(float64(redacted))
+	}
+
+	return line
+}
+
+func (f *filter) allowed(secret string) bool {
+	for _, re := range f.allowlist {
+		if re.MatchString(secret) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
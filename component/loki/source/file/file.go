@@ -37,8 +37,9 @@ const (
 // component.
 // TODO(@tpaschalis) Allow users to configure the encoding of the tailed files.
 type Arguments struct {
-	Targets   []discovery.Target  `river:"targets,attr"`
-	ForwardTo []loki.LogsReceiver `river:"forward_to,attr"`
+	Targets         []discovery.Target  `river:"targets,attr"`
+	ForwardTo       []loki.LogsReceiver `river:"forward_to,attr"`
+	WaitForDelivery bool                `river:"wait_for_delivery,attr,optional"`
 }
 
 var (
@@ -120,6 +121,7 @@ func (c *Component) Run(ctx context.Context) error {
 			return nil
 		case entry := <-c.handler:
 			c.mut.RLock()
+			entry.Ack = loki.FanoutAck(entry.Ack, len(c.receivers))
 			for _, receiver := range c.receivers {
 				receiver <- entry
 			}
@@ -308,6 +310,7 @@ func (c *Component) startTailing(path string, labels model.LabelSet, handler lok
 			path,
 			labels.String(),
 			"",
+			c.args.WaitForDelivery,
 		)
 		if err != nil {
 			level.Error(c.opts.Logger).Log("msg", "failed to start tailer", "error", err, "filename", path)
@@ -130,3 +130,59 @@ func TestTwoTargets(t *testing.T) {
 	require.True(t, foundF1)
 	require.True(t, foundF2)
 }
+
+func TestWaitForDelivery(t *testing.T) {
+	opts := component.Options{
+		Logger:        util.TestFlowLogger(t),
+		Registerer:    prometheus.NewRegistry(),
+		OnStateChange: func(e component.Exports) {},
+		DataPath:      t.TempDir(),
+	}
+
+	f, err := os.CreateTemp(opts.DataPath, "example")
+	require.NoError(t, err)
+	defer f.Close()
+
+	ch := make(chan loki.Entry)
+	args := Arguments{
+		Targets:         []discovery.Target{{"__path__": f.Name()}},
+		ForwardTo:       []loki.LogsReceiver{ch},
+		WaitForDelivery: true,
+	}
+
+	c, err := New(opts, args)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	// Lines may end with "\r\n", which must be accounted for in positions.
+	_, err = f.Write([]byte("first\r\nsecond\n"))
+	require.NoError(t, err)
+
+	var entries []loki.Entry
+	for len(entries) < 2 {
+		select {
+		case e := <-ch:
+			require.NotNil(t, e.Ack)
+			entries = append(entries, e)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "failed waiting for log line")
+		}
+	}
+
+	position := func() int64 {
+		pos, err := c.posFile.Get(f.Name(), model.LabelSet{}.String())
+		require.NoError(t, err)
+		return pos
+	}
+
+	// Positions must not advance past entries which haven't been delivered.
+	entries[1].Acknowledge()
+	require.Equal(t, int64(0), position())
+
+	entries[0].Acknowledge()
+	require.Equal(t, int64(len("first\r\nsecond\n")), position())
+}
@@ -42,9 +42,16 @@ type tailer struct {
 	done    chan struct{}
 
 	decoder *encoding.Decoder
+
+	// acks is set when positions are only advanced after entries have been
+	// delivered. offset is the byte offset right after the last read line,
+	// and file is read to find the line terminator which follows each line.
+	acks   *loki.AckTracker[int64]
+	offset int64
+	file   *os.File
 }
 
-func newTailer(metrics *metrics, logger log.Logger, handler loki.EntryHandler, positions positions.Positions, path string, labels string, encoding string) (*tailer, error) {
+func newTailer(metrics *metrics, logger log.Logger, handler loki.EntryHandler, positions positions.Positions, path string, labels string, encoding string, waitForDelivery bool) (*tailer, error) {
 	// Simple check to make sure the file we are tailing doesn't
 	// have a position already saved which is past the end of the file.
 	fi, err := os.Stat(path)
@@ -58,6 +65,7 @@ func newTailer(metrics *metrics, logger log.Logger, handler loki.EntryHandler, p
 
 	if fi.Size() < pos {
 		positions.Remove(path, labels)
+		pos = 0
 	}
 
 	tail, err := tail.TailFile(path, tail.Config{
@@ -100,6 +108,17 @@ func newTailer(metrics *metrics, logger log.Logger, handler loki.EntryHandler, p
 		tailer.decoder = decoder
 	}
 
+	if waitForDelivery {
+		if err := tailer.openFile(); err != nil {
+			_ = tail.Stop()
+			return nil, err
+		}
+		tailer.offset = pos
+		tailer.acks = loki.NewAckTracker(func(pos int64) {
+			tailer.positions.Put(path, labels, pos)
+		})
+	}
+
 	go tailer.readLines()
 	go tailer.updatePosition()
 	metrics.filesActive.Add(1.)
@@ -154,6 +173,9 @@ func (t *tailer) readLines() {
 	defer func() {
 		t.cleanupMetrics()
 		t.running.Store(false)
+		if t.file != nil {
+			t.file.Close()
+		}
 		level.Info(t.logger).Log("msg", "tail routine: exited", "path", t.path)
 		close(t.done)
 	}()
@@ -191,8 +213,62 @@ func (t *tailer) readLines() {
 				Timestamp: line.Time,
 				Line:      text,
 			},
+			Ack: t.trackLine(line.Text),
+		}
+	}
+}
+
+// trackLine returns the Acknowledger for the line which was just read, or nil
+// if positions aren't waiting for delivery.
+func (t *tailer) trackLine(text string) loki.Acknowledger {
+	if t.acks == nil {
+		return nil
+	}
+
+	// The underlying tailer doesn't expose the offset of each line, so it is
+	// computed from the lines read so far. The underlying tailer is always at
+	// or ahead of that offset; if it's behind, the file was truncated or
+	// rotated and reopened from the start.
+	if pos, err := t.tail.Tell(); err == nil && pos < t.offset {
+		t.offset = 0
+		if err := t.openFile(); err != nil {
+			level.Warn(t.logger).Log("msg", "failed to reopen file to track positions", "path", t.path, "error", err)
 		}
 	}
+	t.offset += t.lineLength(text)
+	return t.acks.Track(t.offset)
+}
+
+// openFile opens the tailed file to read line terminators from, replacing the
+// file opened before. It's opened the same way as by the underlying tailer, so
+// that it doesn't prevent rotating the file on Windows.
+func (t *tailer) openFile() error {
+	f, err := tail.OpenFile(t.path)
+	if err != nil {
+		return err
+	}
+	if t.file != nil {
+		t.file.Close()
+	}
+	t.file = f
+	return nil
+}
+
+// lineLength returns the number of bytes the line text starting at t.offset
+// takes up in the file. The underlying tailer strips the line terminator, so
+// it's read back from the file to tell "\n" from "\r\n".
+func (t *tailer) lineLength(text string) int64 {
+	n := int64(len(text))
+
+	var term [2]byte
+	read, _ := t.file.ReadAt(term[:], t.offset+n)
+	switch {
+	case read > 0 && term[0] == '\n':
+		return n + 1
+	case read > 1 && term[0] == '\r' && term[1] == '\n':
+		return n + 2
+	}
+	return n
 }
 
 func (t *tailer) MarkPositionAndSize() error {
@@ -216,7 +292,11 @@ func (t *tailer) MarkPositionAndSize() error {
 		return err
 	}
 	t.metrics.readBytes.WithLabelValues(t.path).Set(float64(pos))
-	t.positions.Put(t.path, t.labels, pos)
+	if t.acks == nil {
+		// When waiting for delivery, positions are updated as entries get
+		// acknowledged instead.
+		t.positions.Put(t.path, t.labels, pos)
+	}
 
 	return nil
 }
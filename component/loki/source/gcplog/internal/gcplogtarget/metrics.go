@@ -5,7 +5,10 @@ package gcplogtarget
 // logs like bucket logs, load balancer logs, and Kubernetes cluster logs
 // from GCP.
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"github.com/grafana/agent/component/common/ratelimit"
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 // Metrics stores gcplog entry metrics.
 type Metrics struct {
@@ -18,6 +21,7 @@ type Metrics struct {
 
 	gcpPushEntries *prometheus.CounterVec
 	gcpPushErrors  *prometheus.CounterVec
+	gcpPushLimits  *ratelimit.Metrics
 }
 
 // NewMetrics creates a new set of metrics. Metrics will be registered to reg.
@@ -59,5 +63,6 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 		m.gcpPushEntries,
 		m.gcpPushErrors,
 	)
+	m.gcpPushLimits = ratelimit.NewMetrics(reg, "loki_source_gcplog_push")
 	return &m
 }
@@ -15,6 +15,8 @@ import (
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/agent/component/common/loki"
+	"github.com/grafana/agent/component/common/ratelimit"
+	"github.com/grafana/agent/pkg/util"
 	"github.com/grafana/loki/clients/pkg/promtail/targets/serverutils"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
@@ -51,7 +53,7 @@ func NewPushTarget(metrics *Metrics, logger log.Logger, handler loki.EntryHandle
 
 	srvCfg := server.Config{
 		HTTPListenPort:    config.HTTPListenPort,
-		HTTPListenAddress: config.HTTPListenAddress,
+		HTTPListenAddress: util.BracketIPv6(config.HTTPListenAddress),
 
 		// Avoid logging entire received request on failures
 		ExcludeRequestInLog: true,
@@ -95,7 +97,8 @@ func (p *PushTarget) run() error {
 	}
 	p.server = srv
 
-	p.server.HTTP.Path("/gcp/api/v1/push").Methods("POST").Handler(http.HandlerFunc(p.push))
+	const pushEndpoint = "/gcp/api/v1/push"
+	p.server.HTTP.Path(pushEndpoint).Methods("POST").Handler(ratelimit.Handler(p.config.Limits, p.metrics.gcpPushLimits, pushEndpoint, http.HandlerFunc(p.push)))
 
 	go func() {
 		err := srv.Run()
@@ -3,6 +3,8 @@ package gcplogtarget
 import (
 	"fmt"
 	"time"
+
+	"github.com/grafana/agent/component/common/ratelimit"
 )
 
 // Target is a common interface implemented by both GCPLog targets.
@@ -26,6 +28,8 @@ type PushConfig struct {
 	PushTimeout          time.Duration     `river:"push_timeout,attr,optional"`
 	Labels               map[string]string `river:"labels,attr,optional"`
 	UseIncomingTimestamp bool              `river:"use_incoming_timestamp,attr,optional"`
+
+	Limits ratelimit.Arguments `river:"limits,block,optional"`
 }
 
 // DefaultPushConfig sets the default listen address and port.
@@ -2,13 +2,15 @@ package heroku
 
 import (
 	"context"
-	"fmt"
+	"net"
 	"reflect"
+	"strconv"
 	"sync"
 
 	"github.com/go-kit/log/level"
 	"github.com/grafana/agent/component"
 	"github.com/grafana/agent/component/common/loki"
+	"github.com/grafana/agent/component/common/ratelimit"
 	flow_relabel "github.com/grafana/agent/component/common/relabel"
 	ht "github.com/grafana/agent/component/loki/source/heroku/internal/herokutarget"
 	"github.com/prometheus/common/model"
@@ -42,6 +44,8 @@ type ListenerConfig struct {
 	ListenAddress string `river:"address,attr,optional"`
 	ListenPort    int    `river:"port,attr"`
 	// TODO - add the rest of the server config from Promtail
+
+	Limits ratelimit.Arguments `river:"limits,block,optional"`
 }
 
 // DefaultListenerConfig provides the default arguments for a heroku listener.
@@ -173,6 +177,7 @@ func (args *Arguments) Convert() *ht.HerokuDrainTargetConfig {
 		},
 		Labels:               lbls,
 		UseIncomingTimestamp: args.UseIncomingTimestamp,
+		Limits:               args.HerokuListener.Limits,
 	}
 }
 
@@ -183,7 +188,7 @@ func (c *Component) DebugInfo() interface{} {
 
 	var res readerDebugInfo = readerDebugInfo{
 		Ready:   c.target.Ready(),
-		Address: fmt.Sprintf("%s:%d", c.target.ListenAddress(), c.target.ListenPort()),
+		Address: net.JoinHostPort(c.target.ListenAddress(), strconv.Itoa(c.target.ListenPort())),
 	}
 
 	return res
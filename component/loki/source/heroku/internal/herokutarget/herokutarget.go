@@ -6,7 +6,9 @@ package herokutarget
 
 import (
 	"fmt"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,6 +23,8 @@ import (
 	"github.com/weaveworks/common/server"
 
 	"github.com/grafana/agent/component/common/loki"
+	"github.com/grafana/agent/component/common/ratelimit"
+	"github.com/grafana/agent/pkg/util"
 
 	"github.com/grafana/loki/pkg/logproto"
 	util_log "github.com/grafana/loki/pkg/util/log"
@@ -39,6 +43,9 @@ type HerokuDrainTargetConfig struct {
 	// UseIncomingTimestamp sets the timestamp to the incoming heroku log entry timestamp. If false,
 	// promtail will assign the current timestamp to the log entry when it was processed.
 	UseIncomingTimestamp bool
+
+	// Limits restricts the rate and size of requests to the drain endpoint.
+	Limits ratelimit.Arguments
 }
 
 type HerokuTarget struct {
@@ -85,13 +92,18 @@ func (h *HerokuTarget) run() error {
 	// Wrapping util logger with component-specific key vals, and the expected GoKit logging interface
 	h.config.Server.Log = logging.GoKit(log.With(util_log.Logger, "component", "heroku_drain"))
 
-	srv, err := server.New(h.config.Server)
+	// The server formats its listen address as "host:port", so IPv6 hosts must
+	// be bracketed.
+	srvCfg := h.config.Server
+	srvCfg.HTTPListenAddress = util.BracketIPv6(srvCfg.HTTPListenAddress)
+
+	srv, err := server.New(srvCfg)
 	if err != nil {
 		return err
 	}
 
 	h.server = srv
-	h.server.HTTP.Path(h.DrainEndpoint()).Methods("POST").Handler(http.HandlerFunc(h.drain))
+	h.server.HTTP.Path(h.DrainEndpoint()).Methods("POST").Handler(ratelimit.Handler(h.config.Limits, h.metrics.limits, h.DrainEndpoint(), http.HandlerFunc(h.drain)))
 	h.server.HTTP.Path(h.HealthyEndpoint()).Methods("GET").Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }))
 
 	go func() {
@@ -189,7 +201,7 @@ func (h *HerokuTarget) HealthyEndpoint() string {
 }
 
 func (h *HerokuTarget) Ready() bool {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s:%d%s", h.ListenAddress(), h.ListenPort(), h.HealthyEndpoint()), nil)
+	req, err := http.NewRequest(http.MethodGet, net.JoinHostPort(h.ListenAddress(), strconv.Itoa(h.ListenPort()))+h.HealthyEndpoint(), nil)
 	if err != nil {
 		return false
 	}
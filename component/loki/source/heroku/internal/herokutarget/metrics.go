@@ -4,11 +4,15 @@ package herokutarget
 // configure and run the targets that can read heroku entries and forward them
 // to other loki components.
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"github.com/grafana/agent/component/common/ratelimit"
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 type Metrics struct {
 	herokuEntries *prometheus.CounterVec
 	herokuErrors  *prometheus.CounterVec
+	limits        *ratelimit.Metrics
 }
 
 func NewMetrics(reg prometheus.Registerer) *Metrics {
@@ -25,5 +29,6 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 	}, []string{})
 
 	reg.MustRegister(m.herokuEntries, m.herokuErrors)
+	m.limits = ratelimit.NewMetrics(reg, "loki_source_heroku_drain")
 	return &m
 }
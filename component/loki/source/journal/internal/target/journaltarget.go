@@ -110,6 +110,10 @@ type JournalTarget struct {
 
 	r     journalReader
 	until chan time.Time
+
+	// acks is set when the cursor is only advanced after entries have been
+	// delivered.
+	acks *loki.AckTracker[string]
 }
 
 // NewJournalTarget configures a new JournalTarget.
@@ -121,6 +125,7 @@ func NewJournalTarget(
 	jobName string,
 	relabelConfig []*relabel.Config,
 	targetConfig *scrapeconfig.JournalTargetConfig,
+	waitForDelivery bool,
 ) (*JournalTarget, error) {
 
 	return journalTargetWithReader(
@@ -131,6 +136,7 @@ func NewJournalTarget(
 		jobName,
 		relabelConfig,
 		targetConfig,
+		waitForDelivery,
 		defaultJournalReaderFunc,
 		defaultJournalEntryFunc,
 	)
@@ -144,6 +150,7 @@ func journalTargetWithReader(
 	jobName string,
 	relabelConfig []*relabel.Config,
 	targetConfig *scrapeconfig.JournalTargetConfig,
+	waitForDelivery bool,
 	readerFunc journalReaderFunc,
 	entryFunc journalEntryFunc,
 ) (*JournalTarget, error) {
@@ -171,6 +178,11 @@ func journalTargetWithReader(
 
 		until: until,
 	}
+	if waitForDelivery {
+		t.acks = loki.NewAckTracker(func(cursor string) {
+			pos.PutString(positionPath, "", cursor)
+		})
+	}
 
 	var maxAge time.Duration
 	var err error
@@ -333,13 +345,19 @@ func (t *JournalTarget) formatter(entry *sdjournal.JournalEntry) (string, error)
 	}
 
 	t.metrics.journalLines.Inc()
-	t.positions.PutString(t.positionPath, "", entry.Cursor)
+	var ack loki.Acknowledger
+	if t.acks != nil {
+		ack = t.acks.Track(entry.Cursor)
+	} else {
+		t.positions.PutString(t.positionPath, "", entry.Cursor)
+	}
 	t.handler.Chan() <- loki.Entry{
 		Labels: lbls,
 		Entry: logproto.Entry{
 			Line:      msg,
 			Timestamp: ts,
 		},
+		Ack: ack,
 	}
 	return journalEmptyStr, nil
 }
@@ -104,7 +104,7 @@ func TestJournalTarget(t *testing.T) {
 
 	registry := prometheus.NewRegistry()
 	jt, err := journalTargetWithReader(NewMetrics(registry), logger, client, ps, "test", relabels,
-		&scrapeconfig.JournalTargetConfig{}, newMockJournalReader, newMockJournalEntry(nil))
+		&scrapeconfig.JournalTargetConfig{}, false, newMockJournalReader, newMockJournalEntry(nil))
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -158,7 +158,7 @@ func TestJournalTargetParsingErrors(t *testing.T) {
 
 	registry := prometheus.NewRegistry()
 	jt, err := journalTargetWithReader(NewMetrics(registry), logger, client, ps, "test", relabels,
-		&scrapeconfig.JournalTargetConfig{}, newMockJournalReader, newMockJournalEntry(nil))
+		&scrapeconfig.JournalTargetConfig{}, false, newMockJournalReader, newMockJournalEntry(nil))
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -235,7 +235,7 @@ func TestJournalTarget_JSON(t *testing.T) {
 	cfg := &scrapeconfig.JournalTargetConfig{JSON: true}
 
 	jt, err := journalTargetWithReader(NewMetrics(prometheus.NewRegistry()), logger, client, ps, "test", relabels,
-		cfg, newMockJournalReader, newMockJournalEntry(nil))
+		cfg, false, newMockJournalReader, newMockJournalEntry(nil))
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -286,7 +286,7 @@ func TestJournalTarget_Since(t *testing.T) {
 	}
 
 	jt, err := journalTargetWithReader(NewMetrics(prometheus.NewRegistry()), logger, client, ps, "test", nil,
-		&cfg, newMockJournalReader, newMockJournalEntry(nil))
+		&cfg, false, newMockJournalReader, newMockJournalEntry(nil))
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -326,7 +326,7 @@ func TestJournalTarget_Cursor_TooOld(t *testing.T) {
 	})
 
 	jt, err := journalTargetWithReader(NewMetrics(prometheus.NewRegistry()), logger, client, ps, "test", nil,
-		&cfg, newMockJournalReader, journalEntry)
+		&cfg, false, newMockJournalReader, journalEntry)
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -366,7 +366,7 @@ func TestJournalTarget_Cursor_NotTooOld(t *testing.T) {
 	})
 
 	jt, err := journalTargetWithReader(NewMetrics(prometheus.NewRegistry()), logger, client, ps, "test", nil,
-		&cfg, newMockJournalReader, journalEntry)
+		&cfg, false, newMockJournalReader, journalEntry)
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -417,7 +417,7 @@ func TestJournalTarget_Matches(t *testing.T) {
 	}
 
 	jt, err := journalTargetWithReader(NewMetrics(prometheus.NewRegistry()), logger, client, ps, "test", nil,
-		&cfg, newMockJournalReader, newMockJournalEntry(nil))
+		&cfg, false, newMockJournalReader, newMockJournalEntry(nil))
 	require.NoError(t, err)
 
 	r := jt.r.(*mockJournalReader)
@@ -89,6 +89,7 @@ func (c *Component) Run(ctx context.Context) error {
 			lokiEntry := loki.Entry{
 				Labels: entry.Labels,
 				Entry:  entry.Entry,
+				Ack:    loki.FanoutAck(entry.Ack, len(c.receivers)),
 			}
 			for _, r := range c.receivers {
 				r <- lokiEntry
@@ -112,7 +113,7 @@ func (c *Component) Update(args component.Arguments) error {
 	rcs := flow_relabel.ComponentToPromRelabelConfigs(newArgs.RelabelRules)
 	entryHandler := loki.NewEntryHandler(c.handler, func() {})
 
-	newTarget, err := target.NewJournalTarget(c.metrics, c.o.Logger, entryHandler, c.positions, c.o.ID, rcs, convertArgs(c.o.ID, newArgs))
+	newTarget, err := target.NewJournalTarget(c.metrics, c.o.Logger, entryHandler, c.positions, c.o.ID, rcs, convertArgs(c.o.ID, newArgs), newArgs.WaitForDelivery)
 	if err != nil {
 		return err
 	}
@@ -10,25 +10,26 @@ import (
 	"github.com/cespare/xxhash/v2"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/component/common/kubernetes/informercache"
 	"github.com/grafana/agent/component/common/loki"
 	"github.com/grafana/agent/component/common/loki/positions"
+	"github.com/grafana/agent/component/loki/source/kubernetes"
 	"github.com/grafana/agent/pkg/runner"
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/prometheus/common/model"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
 	cachetools "k8s.io/client-go/tools/cache"
-	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type eventControllerTask struct {
 	Log          log.Logger
-	Config       *rest.Config // Config to connect to Kubernetes.
-	Namespace    string       // Namespace to watch for events in.
-	JobName      string       // Label value to use for job.
-	InstanceName string       // Label value to use for instance.
+	Client       *kubernetes.ClientArguments // Settings used to build Config.
+	Config       *rest.Config                // Config to connect to Kubernetes.
+	Namespace    string                      // Namespace to watch for events in.
+	JobName      string                      // Label value to use for job.
+	InstanceName string                      // Label value to use for instance.
 	Receiver     loki.LogsReceiver
 	Positions    positions.Positions
 }
@@ -84,26 +85,15 @@ func (ctrl *eventController) Run(ctx context.Context) {
 }
 
 func (ctrl *eventController) runError(ctx context.Context) error {
-	scheme := runtime.NewScheme()
-	if err := corev1.AddToScheme(scheme); err != nil {
-		return fmt.Errorf("adding core to scheme: %w", err)
-	}
-
-	opts := cache.Options{
-		Scheme:    scheme,
-		Namespace: ctrl.task.Namespace,
-	}
-	informers, err := cache.New(ctrl.task.Config, opts)
+	// Informers are shared with other components watching the same cluster
+	// and namespace.
+	informers, err := informercache.Default.Acquire(ctrl.log, *ctrl.task.Client, ctrl.task.Namespace, func() (*rest.Config, error) {
+		return ctrl.task.Config, nil
+	})
 	if err != nil {
 		return fmt.Errorf("creating informers cache: %w", err)
 	}
-
-	go func() {
-		err := informers.Start(ctx)
-		if err != nil && ctx.Err() != nil {
-			level.Error(ctrl.log).Log("msg", "failed to start informers", "err", err)
-		}
-	}()
+	defer informers.Release()
 
 	if !informers.WaitForCacheSync(ctx) {
 		return fmt.Errorf("informer caches failed to sync")
@@ -116,7 +106,7 @@ func (ctrl *eventController) runError(ctx context.Context) error {
 	return nil
 }
 
-func (ctrl *eventController) configureInformers(ctx context.Context, informers cache.Informers) error {
+func (ctrl *eventController) configureInformers(ctx context.Context, informers *informercache.Handle) error {
 	types := []client.Object{
 		&corev1.Event{},
 	}
@@ -125,7 +115,11 @@ func (ctrl *eventController) configureInformers(ctx context.Context, informers c
 	defer cancel()
 
 	for _, ty := range types {
-		informer, err := informers.GetInformer(informerCtx, ty)
+		err := informers.AddEventHandler(informerCtx, ty, cachetools.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { ctrl.onAdd(ctx, obj) },
+			UpdateFunc: func(oldObj, newObj interface{}) { ctrl.onUpdate(ctx, oldObj, newObj) },
+			DeleteFunc: func(obj interface{}) { ctrl.onDelete(ctx, obj) },
+		})
 		if err != nil {
 			if errors.Is(informerCtx.Err(), context.DeadlineExceeded) { // Check the context to prevent GetInformer returning a fake timeout
 				return fmt.Errorf("timeout exceeded while configuring informers. Check the connection"+
@@ -133,12 +127,6 @@ func (ctrl *eventController) configureInformers(ctx context.Context, informers c
 			}
 			return err
 		}
-
-		informer.AddEventHandler(cachetools.ResourceEventHandlerFuncs{
-			AddFunc:    func(obj interface{}) { ctrl.onAdd(ctx, obj) },
-			UpdateFunc: func(oldObj, newObj interface{}) { ctrl.onUpdate(ctx, oldObj, newObj) },
-			DeleteFunc: func(obj interface{}) { ctrl.onDelete(ctx, obj) },
-		})
 	}
 	return nil
 }
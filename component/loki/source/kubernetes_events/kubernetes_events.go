@@ -15,12 +15,14 @@ import (
 	"github.com/go-kit/log/level"
 	"github.com/grafana/agent/component"
 	"github.com/grafana/agent/component/common/config"
+	"github.com/grafana/agent/component/common/kubernetes/informercache"
 	"github.com/grafana/agent/component/common/loki"
 	"github.com/grafana/agent/component/common/loki/positions"
 	"github.com/grafana/agent/component/loki/source/kubernetes"
 	"github.com/grafana/agent/pkg/river"
 	"github.com/grafana/agent/pkg/runner"
 	"github.com/oklog/run"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/rest"
 )
 
@@ -28,6 +30,8 @@ import (
 const informerSyncTimeout = 10 * time.Second
 
 func init() {
+	informercache.AddToScheme(corev1.AddToScheme)
+
 	component.Register(component.Registration{
 		Name: "loki.source.kubernetes_events",
 		Args: Arguments{},
@@ -89,6 +93,7 @@ type Component struct {
 
 	mut        sync.Mutex
 	args       Arguments
+	client     *kubernetes.ClientArguments
 	restConfig *rest.Config
 
 	tasksMut sync.RWMutex
@@ -197,15 +202,14 @@ func (c *Component) Update(args component.Arguments) error {
 	c.receivers = newArgs.ForwardTo
 	c.receiversMut.Unlock()
 
-	restConfig := c.restConfig
-
 	// Create a new restConfig if we don't have one or if our arguments changed.
-	if restConfig == nil || !reflect.DeepEqual(c.args.Client, newArgs.Client) {
-		var err error
-		restConfig, err = newArgs.Client.BuildRESTConfig(c.log)
+	if c.restConfig == nil || !reflect.DeepEqual(c.args.Client, newArgs.Client) {
+		restConfig, err := newArgs.Client.BuildRESTConfig(c.log)
 		if err != nil {
 			return fmt.Errorf("building Kubernetes client config: %w", err)
 		}
+		client := newArgs.Client
+		c.client, c.restConfig = &client, restConfig
 	}
 
 	// Create a task for each defined namespace.
@@ -213,7 +217,8 @@ func (c *Component) Update(args component.Arguments) error {
 	for _, namespace := range getNamespaces(newArgs) {
 		newTasks = append(newTasks, eventControllerTask{
 			Log:          c.log,
-			Config:       restConfig,
+			Client:       c.client,
+			Config:       c.restConfig,
 			JobName:      newArgs.JobName,
 			InstanceName: c.opts.ID,
 			Namespace:    namespace,
@@ -9,12 +9,14 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/component/common/kubernetes/informercache"
+	"github.com/grafana/agent/component/loki/source/kubernetes"
 	monitoringv1alpha2 "github.com/grafana/agent/component/loki/source/podlogs/internal/apis/monitoring/v1alpha2"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/runtime"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	toolscache "k8s.io/client-go/tools/cache"
-	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -23,8 +25,10 @@ type controller struct {
 	reconciler *reconciler
 
 	mut       sync.RWMutex
-	informers cache.Informers
+	informers *informercache.Handle
 	client    client.Client
+	events    typedcorev1.EventsGetter
+	pending   bool          // True if informers wasn't picked up by Run yet.
 	reloadCh  chan struct{} // Written to when informers or client changes
 
 	reconcileCh chan struct{}
@@ -47,39 +51,45 @@ func newController(l log.Logger, reconciler *reconciler) *controller {
 	}
 }
 
-func (ctrl *controller) UpdateConfig(cfg *rest.Config) error {
-	scheme := runtime.NewScheme()
-	for _, add := range []func(*runtime.Scheme) error{
-		corev1.AddToScheme,
-		monitoringv1alpha2.AddToScheme,
-	} {
-		if err := add(scheme); err != nil {
-			return fmt.Errorf("unable to register scheme: %w", err)
-		}
+// UpdateConfig updates the config used to connect to Kubernetes. clientArgs
+// are the arguments cfg was built from, which identify the informers shared
+// with other components.
+func (ctrl *controller) UpdateConfig(clientArgs kubernetes.ClientArguments, cfg *rest.Config) error {
+	cli, err := client.New(cfg, client.Options{Scheme: informercache.Scheme()})
+	if err != nil {
+		return err
 	}
-
-	cli, err := client.New(cfg, client.Options{Scheme: scheme})
+	events, err := typedcorev1.NewForConfig(cfg)
 	if err != nil {
 		return err
 	}
 
-	cache, err := cache.New(cfg, cache.Options{Scheme: scheme})
+	informers, err := informercache.Default.Acquire(ctrl.log, clientArgs, "", func() (*rest.Config, error) {
+		return cfg, nil
+	})
 	if err != nil {
 		return err
 	}
 
 	delegateCli, err := client.NewDelegatingClient(client.NewDelegatingClientInput{
-		CacheReader: cache,
+		CacheReader: informers.Reader(),
 		Client:      cli,
 	})
 	if err != nil {
+		informers.Release()
 		return err
 	}
 
 	// Update the stored informers and client and schedule a reload.
 	ctrl.mut.Lock()
-	ctrl.informers = cache
+	if ctrl.pending {
+		// Run never used the previous informers.
+		ctrl.informers.Release()
+	}
+	ctrl.informers = informers
 	ctrl.client = delegateCli
+	ctrl.events = events
+	ctrl.pending = true
 	ctrl.mut.Unlock()
 
 	select {
@@ -94,30 +104,46 @@ func (ctrl *controller) UpdateConfig(cfg *rest.Config) error {
 func (ctrl *controller) Run(ctx context.Context) error {
 	var (
 		cancel    context.CancelFunc
-		informers cache.Informers
+		informers *informercache.Handle
 	)
+	defer func() {
+		if informers != nil {
+			cancel()
+			informers.Release()
+		}
+
+		ctrl.mut.Lock()
+		if ctrl.pending {
+			ctrl.informers.Release()
+			ctrl.pending = false
+		}
+		ctrl.mut.Unlock()
+	}()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
 		case <-ctrl.reloadCh:
-			ctrl.mut.RLock()
+			ctrl.mut.Lock()
 			var (
 				newInformers = ctrl.informers
 				newClient    = ctrl.client
+				newEvents    = ctrl.events
 			)
-			ctrl.mut.RUnlock()
+			ctrl.pending = false
+			ctrl.mut.Unlock()
 
-			// Stop old informers.
+			// Stop using old informers.
 			if informers != nil {
 				cancel()
+				informers.Release()
 			}
 
 			informerContext, informerCancel := context.WithCancel(ctx)
 
 			go func() {
-				if err := ctrl.run(informerContext, newInformers, newClient); err != nil {
+				if err := ctrl.run(informerContext, newInformers, newClient, newEvents); err != nil {
 					level.Error(ctrl.log).Log("msg", "failed to run controller", "err", err)
 				}
 			}()
@@ -128,16 +154,15 @@ func (ctrl *controller) Run(ctx context.Context) error {
 	}
 }
 
-func (ctrl *controller) run(ctx context.Context, informers cache.Informers, client client.Client) error {
+func (ctrl *controller) run(ctx context.Context, informers *informercache.Handle, client client.Client, events typedcorev1.EventsGetter) error {
 	level.Info(ctrl.log).Log("msg", "starting controller")
 	defer level.Info(ctrl.log).Log("msg", "controller exiting")
 
-	go func() {
-		err := informers.Start(ctx)
-		if err != nil && ctx.Err() != nil {
-			level.Error(ctrl.log).Log("msg", "failed to start informers", "err", err)
-		}
-	}()
+	// Reconcile errors are recorded as Events on the offending PodLogs.
+	broadcaster := record.NewBroadcaster()
+	defer broadcaster.Shutdown()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: events.Events("")})
+	recorder := broadcaster.NewRecorder(informercache.Scheme(), corev1.EventSource{Component: eventSourceComponent})
 
 	if !informers.WaitForCacheSync(ctx) {
 		return fmt.Errorf("informer caches failed to sync")
@@ -152,7 +177,7 @@ func (ctrl *controller) run(ctx context.Context, informers cache.Informers, clie
 		case <-ctx.Done():
 			return nil
 		case <-ctrl.reconcileCh:
-			if err := ctrl.reconciler.Reconcile(ctx, client); err != nil {
+			if err := ctrl.reconciler.Reconcile(ctx, client, recorder); err != nil {
 				level.Error(ctrl.log).Log("msg", "reconcile failed", "err", err)
 			}
 		}
@@ -160,7 +185,7 @@ func (ctrl *controller) run(ctx context.Context, informers cache.Informers, clie
 }
 
 // configureInformers starts the informers used by this controller to perform reconciles.
-func (ctrl *controller) configureInformers(ctx context.Context, informers cache.Informers) error {
+func (ctrl *controller) configureInformers(ctx context.Context, informers *informercache.Handle) error {
 	// We want to re-reconcile the set of PodLogs whenever namespaces, pods, or
 	// PodLogs changes. Reconciling on namespaces and pods is important so that
 	// we can reevaluate selectors defined in PodLogs.
@@ -174,7 +199,7 @@ func (ctrl *controller) configureInformers(ctx context.Context, informers cache.
 	defer cancel()
 
 	for _, ty := range types {
-		informer, err := informers.GetInformer(informerCtx, ty)
+		err := informers.AddEventHandler(informerCtx, ty, onChangeEventHandler{ChangeFunc: ctrl.RequestReconcile})
 		if err != nil {
 			if errors.Is(informerCtx.Err(), context.DeadlineExceeded) { // Check the context to prevent GetInformer returning a fake timeout
 				return fmt.Errorf("Timeout exceeded while configuring informers. Check the connection"+
@@ -183,7 +208,6 @@ func (ctrl *controller) configureInformers(ctx context.Context, informers cache.
 
 			return err
 		}
-		informer.AddEventHandler(onChangeEventHandler{ChangeFunc: ctrl.RequestReconcile})
 	}
 	return nil
 }
@@ -0,0 +1,94 @@
+package podlogs
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/grafana/agent/pkg/river"
+	promlabels "github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/util/strutil"
+)
+
+// MetaLabelsArguments limits the meta labels which are created from the
+// labels and annotations of Kubernetes objects. Clusters with many or large
+// annotations otherwise cause every target to hold a large set of labels in
+// memory.
+type MetaLabelsArguments struct {
+	// Limit is the maximum number of labels and the maximum number of
+	// annotations taken from each Kubernetes object. 0 means no limit.
+	Limit int `river:"limit,attr,optional"`
+	// ValueLengthLimit is the maximum length of a label or annotation value.
+	// Labels and annotations with longer values are skipped. 0 means no limit.
+	ValueLengthLimit int `river:"value_length_limit,attr,optional"`
+	// DropAnnotations skips all annotations.
+	DropAnnotations bool `river:"drop_annotations,attr,optional"`
+}
+
+var _ river.Unmarshaler = (*MetaLabelsArguments)(nil)
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *MetaLabelsArguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = MetaLabelsArguments{}
+
+	type arguments MetaLabelsArguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if args.Limit < 0 {
+		return fmt.Errorf("limit must not be negative")
+	}
+	if args.ValueLengthLimit < 0 {
+		return fmt.Errorf("value_length_limit must not be negative")
+	}
+	return nil
+}
+
+// setLabels adds the <prefix>_label_<name> and <prefix>_labelpresent_<name>
+// meta labels for the labels of a Kubernetes object to lb.
+func (args *MetaLabelsArguments) setLabels(lb *promlabels.Builder, prefix string, labels map[string]string) {
+	args.set(lb, prefix+"_label_", prefix+"_labelpresent_", labels)
+}
+
+// setAnnotations adds the <prefix>_annotation_<name> and
+// <prefix>_annotationpresent_<name> meta labels for the annotations of a
+// Kubernetes object to lb.
+func (args *MetaLabelsArguments) setAnnotations(lb *promlabels.Builder, prefix string, annotations map[string]string) {
+	if args != nil && args.DropAnnotations {
+		return
+	}
+	args.set(lb, prefix+"_annotation_", prefix+"_annotationpresent_", annotations)
+}
+
+// set adds meta labels for the entries of kv to lb, applying the limits of
+// args. Entries are processed in sorted order so the same entries are kept
+// whenever the limit is reached.
+func (args *MetaLabelsArguments) set(lb *promlabels.Builder, valuePrefix, presentPrefix string, kv map[string]string) {
+	var limits MetaLabelsArguments
+	if args != nil {
+		limits = *args
+	}
+
+	keys := make([]string, 0, len(kv))
+	for key := range kv {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var added int
+	for _, key := range keys {
+		if limits.Limit > 0 && added >= limits.Limit {
+			break
+		}
+
+		value := kv[key]
+		if limits.ValueLengthLimit > 0 && len(value) > limits.ValueLengthLimit {
+			continue
+		}
+
+		name := strutil.SanitizeLabelName(key)
+		lb.Set(valuePrefix+name, value)
+		lb.Set(presentPrefix+name, "true")
+		added++
+	}
+}
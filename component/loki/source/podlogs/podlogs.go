@@ -13,17 +13,24 @@ import (
 	"github.com/go-kit/log/level"
 	"github.com/grafana/agent/component"
 	"github.com/grafana/agent/component/common/config"
+	commonk8s "github.com/grafana/agent/component/common/kubernetes"
+	"github.com/grafana/agent/component/common/kubernetes/informercache"
 	"github.com/grafana/agent/component/common/loki"
 	"github.com/grafana/agent/component/common/loki/positions"
 	"github.com/grafana/agent/component/loki/source/kubernetes"
 	"github.com/grafana/agent/component/loki/source/kubernetes/kubetail"
+	monitoringv1alpha2 "github.com/grafana/agent/component/loki/source/podlogs/internal/apis/monitoring/v1alpha2"
 	"github.com/grafana/agent/pkg/river"
 	"github.com/oklog/run"
+	corev1 "k8s.io/api/core/v1"
 	kubeclient "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
 func init() {
+	informercache.AddToScheme(corev1.AddToScheme)
+	informercache.AddToScheme(monitoringv1alpha2.AddToScheme)
+
 	component.Register(component.Registration{
 		Name: "loki.source.podlogs",
 		Args: Arguments{},
@@ -44,6 +51,9 @@ type Arguments struct {
 
 	Selector          LabelSelector `river:"selector,block,optional"`
 	NamespaceSelector LabelSelector `river:"namespace_selector,block,optional"`
+
+	HostFilter *commonk8s.HostFilterArguments `river:"host_filter,block,optional"`
+	MetaLabels *MetaLabelsArguments           `river:"meta_labels,block,optional"`
 }
 
 var _ river.Unmarshaler = (*Arguments)(nil)
@@ -246,8 +256,10 @@ func (c *Component) updateReconciler(args Arguments) error {
 	var (
 		selectorChanged          = !reflect.DeepEqual(c.args.Selector, args.Selector)
 		namespaceSelectorChanged = !reflect.DeepEqual(c.args.NamespaceSelector, args.NamespaceSelector)
+		hostFilterChanged        = !reflect.DeepEqual(c.args.HostFilter, args.HostFilter)
+		metaLabelsChanged        = !reflect.DeepEqual(c.args.MetaLabels, args.MetaLabels)
 	)
-	if !selectorChanged && !namespaceSelectorChanged {
+	if !selectorChanged && !namespaceSelectorChanged && !hostFilterChanged && !metaLabelsChanged {
 		return nil
 	}
 
@@ -262,6 +274,13 @@ func (c *Component) updateReconciler(args Arguments) error {
 
 	c.reconciler.UpdateSelectors(sel, nsSel)
 
+	var nodeName string
+	if args.HostFilter != nil {
+		nodeName = args.HostFilter.NodeName
+	}
+	c.reconciler.UpdateNodeName(nodeName)
+	c.reconciler.UpdateMetaLabels(args.MetaLabels)
+
 	// Request a reconcile so the new selectors get applied.
 	c.controller.RequestReconcile()
 	return nil
@@ -282,7 +301,7 @@ func (c *Component) updateController(args Arguments) error {
 	}
 	c.restConfig = cfg
 
-	return c.controller.UpdateConfig(cfg)
+	return c.controller.UpdateConfig(args.Client, cfg)
 }
 
 // DebugInfo returns debug information for loki.source.podlogs.
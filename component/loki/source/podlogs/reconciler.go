@@ -15,10 +15,10 @@ import (
 	"github.com/prometheus/common/model"
 	promlabels "github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/relabel"
-	"github.com/prometheus/prometheus/util/strutil"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -31,11 +31,26 @@ type reconciler struct {
 	reconcileMut             sync.RWMutex
 	podLogsSelector          labels.Selector
 	podLogsNamespaceSelector labels.Selector
+	nodeName                 string
+	metaLabels               *MetaLabelsArguments
 
 	debugMut  sync.RWMutex
 	debugInfo []DiscoveredPodLogs
+	// reconcileErrors holds the reconcile errors of PodLogs from the previous
+	// reconcile, so that Events are only recorded when an error changes.
+	reconcileErrors map[client.ObjectKey]string
 }
 
+const (
+	// eventSourceComponent is the component reported as the source of
+	// Events.
+	eventSourceComponent = "grafana-agent"
+
+	// reasonReconcileFailed is the reason of Events recorded when a PodLogs
+	// can't be reconciled.
+	reasonReconcileFailed = "ReconcileFailed"
+)
+
 // newReconciler creates a new reconciler which synchronizes targets with the
 // provided tailer whenever Reconcile is called.
 func newReconciler(l log.Logger, tailer *kubetail.Manager) *reconciler {
@@ -57,14 +72,47 @@ func (r *reconciler) UpdateSelectors(podLogs, namespace labels.Selector) {
 	r.podLogsNamespaceSelector = namespace
 }
 
+// UpdateNodeName restricts the reconciler to Pods running on the given node.
+// If nodeName is empty, Pods on all nodes are used.
+func (r *reconciler) UpdateNodeName(nodeName string) {
+	r.reconcileMut.Lock()
+	defer r.reconcileMut.Unlock()
+
+	r.nodeName = nodeName
+}
+
+// UpdateMetaLabels updates the limits applied to meta labels created from
+// Kubernetes labels and annotations. If args is nil, no limits are applied.
+func (r *reconciler) UpdateMetaLabels(args *MetaLabelsArguments) {
+	r.reconcileMut.Lock()
+	defer r.reconcileMut.Unlock()
+
+	r.metaLabels = args
+}
+
 // Reconcile synchronizes the set of running kubetail targets with the set of
-// discovered PodLogs.
-func (r *reconciler) Reconcile(ctx context.Context, cli client.Client) error {
+// discovered PodLogs. Errors reconciling individual PodLogs are recorded as
+// Events on them through recorder.
+func (r *reconciler) Reconcile(ctx context.Context, cli client.Client, recorder record.EventRecorder) error {
 	var newDebugInfo []DiscoveredPodLogs
 	var newTasks []*kubetail.Target
+	newReconcileErrors := make(map[client.ObjectKey]string)
+
+	r.debugMut.RLock()
+	prevReconcileErrors := r.reconcileErrors
+	r.debugMut.RUnlock()
+
+	r.reconcileMut.RLock()
+	var (
+		podLogsSelector          = r.podLogsSelector
+		podLogsNamespaceSelector = r.podLogsNamespaceSelector
+		nodeName                 = r.nodeName
+		metaLabels               = r.metaLabels
+	)
+	r.reconcileMut.RUnlock()
 
 	listOpts := []client.ListOption{
-		client.MatchingLabelsSelector{Selector: r.podLogsSelector},
+		client.MatchingLabelsSelector{Selector: podLogsSelector},
 	}
 	var podLogsList monitoringv1alpha2.PodLogsList
 	if err := cli.List(ctx, &podLogsList, listOpts...); err != nil {
@@ -80,11 +128,17 @@ func (r *reconciler) Reconcile(ctx context.Context, cli client.Client) error {
 			level.Error(r.log).Log("msg", "failed to reconcile PodLogs", "operation", "get namespace", "key", key, "err", err)
 			continue
 		}
-		if !r.podLogsNamespaceSelector.Matches(labels.Set(podLogsNamespace.Labels)) {
+		if !podLogsNamespaceSelector.Matches(labels.Set(podLogsNamespace.Labels)) {
 			continue
 		}
 
-		targets, discoveredPodLogs := r.reconcilePodLogs(ctx, cli, podLogs)
+		targets, discoveredPodLogs := r.reconcilePodLogs(ctx, cli, podLogs, nodeName, metaLabels)
+		if msg := discoveredPodLogs.ReconcileError; msg != "" {
+			newReconcileErrors[key] = msg
+			if prevReconcileErrors[key] != msg {
+				recorder.Event(podLogs, corev1.EventTypeWarning, reasonReconcileFailed, msg)
+			}
+		}
 
 		newTasks = append(newTasks, targets...)
 		newDebugInfo = append(newDebugInfo, discoveredPodLogs)
@@ -96,12 +150,16 @@ func (r *reconciler) Reconcile(ctx context.Context, cli client.Client) error {
 
 	r.debugMut.Lock()
 	r.debugInfo = newDebugInfo
+	r.reconcileErrors = newReconcileErrors
 	r.debugMut.Unlock()
 
 	return nil
 }
 
-func (r *reconciler) reconcilePodLogs(ctx context.Context, cli client.Client, podLogs *monitoringv1alpha2.PodLogs) ([]*kubetail.Target, DiscoveredPodLogs) {
+// reconcilePodLogs returns the targets for an individual PodLogs. If nodeName
+// is non-empty, only Pods running on that node are used. metaLabels limits the
+// meta labels of the targets and may be nil.
+func (r *reconciler) reconcilePodLogs(ctx context.Context, cli client.Client, podLogs *monitoringv1alpha2.PodLogs, nodeName string, metaLabels *MetaLabelsArguments) ([]*kubetail.Target, DiscoveredPodLogs) {
 	var targets []*kubetail.Target
 
 	discoveredPodLogs := DiscoveredPodLogs{
@@ -146,6 +204,11 @@ func (r *reconciler) reconcilePodLogs(ctx context.Context, cli client.Client, po
 	}
 
 	for _, pod := range podList.Items {
+		// Skip over this pod if it's filtered out by host_filter.
+		if nodeName != "" && pod.Spec.NodeName != nodeName {
+			continue
+		}
+
 		discoveredPod := DiscoveredPod{
 			Namespace: pod.Namespace,
 			Name:      pod.Name,
@@ -170,6 +233,7 @@ func (r *reconciler) reconcilePodLogs(ctx context.Context, cli client.Client, po
 				Pod:           &pod,
 				Container:     container,
 				InitContainer: initContainer,
+				MetaLabels:    metaLabels,
 			})
 			processedLabels := relabel.Process(targetLabels.Copy(), relabelRules...)
 
@@ -223,6 +287,10 @@ type discoveredContainer struct {
 	Pod           *corev1.Pod
 	Container     *corev1.Container
 	InitContainer bool
+
+	// MetaLabels limits the meta labels created from Kubernetes labels and
+	// annotations. May be nil.
+	MetaLabels *MetaLabelsArguments
 }
 
 func buildTargetLabels(opts discoveredContainer) promlabels.Labels {
@@ -230,41 +298,17 @@ func buildTargetLabels(opts discoveredContainer) promlabels.Labels {
 
 	targetLabels.Set("__meta_kubernetes_podlogs_namespace", opts.PodLogs.Namespace)
 	targetLabels.Set("__meta_kubernetes_podlogs_name", opts.PodLogs.Name)
-	for key, value := range opts.PodLogs.Labels {
-		key = strutil.SanitizeLabelName(key)
-		targetLabels.Set("__meta_kubernetes_podlogs_label_"+key, value)
-		targetLabels.Set("__meta_kubernetes_podlogs_labelpresent_"+key, "true")
-	}
-	for key, value := range opts.PodLogs.Annotations {
-		key = strutil.SanitizeLabelName(key)
-		targetLabels.Set("__meta_kubernetes_podlogs_annotation_"+key, value)
-		targetLabels.Set("__meta_kubernetes_podlogs_annotationpresent_"+key, "true")
-	}
+	opts.MetaLabels.setLabels(targetLabels, "__meta_kubernetes_podlogs", opts.PodLogs.Labels)
+	opts.MetaLabels.setAnnotations(targetLabels, "__meta_kubernetes_podlogs", opts.PodLogs.Annotations)
 
 	targetLabels.Set("__meta_kubernetes_namespace", opts.Pod.Namespace)
-	for key, value := range opts.PodNamespace.Labels {
-		key = strutil.SanitizeLabelName(key)
-		targetLabels.Set("__meta_kubernetes_namespace_label_"+key, value)
-		targetLabels.Set("__meta_kubernetes_namespace_labelpresent_"+key, "true")
-	}
-	for key, value := range opts.PodNamespace.Annotations {
-		key = strutil.SanitizeLabelName(key)
-		targetLabels.Set("__meta_kubernetes_namespace_annotation_"+key, value)
-		targetLabels.Set("__meta_kubernetes_namespace_annotationpresent_"+key, "true")
-	}
+	opts.MetaLabels.setLabels(targetLabels, "__meta_kubernetes_namespace", opts.PodNamespace.Labels)
+	opts.MetaLabels.setAnnotations(targetLabels, "__meta_kubernetes_namespace", opts.PodNamespace.Annotations)
 
 	targetLabels.Set("__meta_kubernetes_pod_name", opts.Pod.Name)
 	targetLabels.Set("__meta_kubernetes_pod_ip", opts.Pod.Status.PodIP)
-	for key, value := range opts.Pod.Labels {
-		key = strutil.SanitizeLabelName(key)
-		targetLabels.Set("__meta_kubernetes_pod_label_"+key, value)
-		targetLabels.Set("__meta_kubernetes_pod_labelpresent_"+key, "true")
-	}
-	for key, value := range opts.Pod.Annotations {
-		key = strutil.SanitizeLabelName(key)
-		targetLabels.Set("__meta_kubernetes_pod_annotation_"+key, value)
-		targetLabels.Set("__meta_kubernetes_pod_annotationpresent_"+key, "true")
-	}
+	opts.MetaLabels.setLabels(targetLabels, "__meta_kubernetes_pod", opts.Pod.Labels)
+	opts.MetaLabels.setAnnotations(targetLabels, "__meta_kubernetes_pod", opts.Pod.Annotations)
 	targetLabels.Set("__meta_kubernetes_pod_container_init", fmt.Sprint(opts.InitContainer))
 	targetLabels.Set("__meta_kubernetes_pod_container_name", opts.Container.Name)
 	targetLabels.Set("__meta_kubernetes_pod_container_image", opts.Container.Image)
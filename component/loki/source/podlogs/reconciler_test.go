@@ -0,0 +1,180 @@
+package podlogs
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/grafana/agent/component/loki/source/kubernetes/kubetail"
+	monitoringv1alpha2 "github.com/grafana/agent/component/loki/source/podlogs/internal/apis/monitoring/v1alpha2"
+	"github.com/grafana/agent/pkg/util"
+	promv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_NodeName(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, monitoringv1alpha2.AddToScheme(scheme))
+
+	newPod := func(name, node string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: corev1.PodSpec{
+				NodeName:   node,
+				Containers: []corev1.Container{{Name: "app"}},
+			},
+		}
+	}
+
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&monitoringv1alpha2.PodLogs{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "all"}},
+		newPod("pod-a", "node-a"),
+		newPod("pod-b", "node-b"),
+	).Build()
+
+	l := util.TestLogger(t)
+	r := newReconciler(l, kubetail.NewManager(l, nil))
+
+	discoveredPods := func() []string {
+		require.NoError(t, r.Reconcile(context.Background(), cli, record.NewFakeRecorder(10)))
+
+		var names []string
+		for _, podLogs := range r.DebugInfo() {
+			for _, pod := range podLogs.Pods {
+				names = append(names, pod.Name)
+			}
+		}
+		return names
+	}
+
+	require.ElementsMatch(t, []string{"pod-a", "pod-b"}, discoveredPods())
+
+	r.UpdateNodeName("node-a")
+	require.Equal(t, []string{"pod-a"}, discoveredPods())
+}
+
+func TestReconciler_Events(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, monitoringv1alpha2.AddToScheme(scheme))
+
+	invalid := &monitoringv1alpha2.PodLogs{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "invalid"},
+		Spec: monitoringv1alpha2.PodLogsSpec{
+			RelabelConfigs: []*promv1.RelabelConfig{{Regex: "(unclosed"}},
+		},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&monitoringv1alpha2.PodLogs{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "valid"}},
+		invalid,
+	).Build()
+
+	l := util.TestLogger(t)
+	r := newReconciler(l, kubetail.NewManager(l, nil))
+	recorder := record.NewFakeRecorder(10)
+
+	require.NoError(t, r.Reconcile(context.Background(), cli, recorder))
+	require.Len(t, recorder.Events, 1)
+	require.Contains(t, <-recorder.Events, "Warning ReconcileFailed invalid relabelings")
+
+	// The same error isn't recorded again.
+	require.NoError(t, r.Reconcile(context.Background(), cli, recorder))
+	require.Len(t, recorder.Events, 0)
+
+	// Errors are recorded again after they were fixed and reintroduced.
+	invalid.Spec.RelabelConfigs = nil
+	require.NoError(t, cli.Update(context.Background(), invalid))
+	require.NoError(t, r.Reconcile(context.Background(), cli, recorder))
+	require.Len(t, recorder.Events, 0)
+
+	invalid.Spec.RelabelConfigs = []*promv1.RelabelConfig{{Regex: "(unclosed"}}
+	require.NoError(t, cli.Update(context.Background(), invalid))
+	require.NoError(t, r.Reconcile(context.Background(), cli, recorder))
+	require.Len(t, recorder.Events, 1)
+}
+
+func TestBuildTargetLabels_MetaLabels(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "pod",
+			Labels:      map[string]string{"a": "1", "b": "2", "c": "3"},
+			Annotations: map[string]string{"small": "x", "large": "xxxxxxxxxx"},
+		},
+	}
+	opts := discoveredContainer{
+		PodLogs:      &monitoringv1alpha2.PodLogs{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "podlogs"}},
+		PodNamespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		Pod:          pod,
+		Container:    &corev1.Container{Name: "app"},
+	}
+
+	tt := []struct {
+		name       string
+		metaLabels *MetaLabelsArguments
+		expect     map[string]string
+	}{
+		{
+			name:       "no limits",
+			metaLabels: nil,
+			expect: map[string]string{
+				"__meta_kubernetes_pod_label_a":          "1",
+				"__meta_kubernetes_pod_label_b":          "2",
+				"__meta_kubernetes_pod_label_c":          "3",
+				"__meta_kubernetes_pod_annotation_small": "x",
+				"__meta_kubernetes_pod_annotation_large": "xxxxxxxxxx",
+			},
+		},
+		{
+			name:       "limit",
+			metaLabels: &MetaLabelsArguments{Limit: 2},
+			expect: map[string]string{
+				"__meta_kubernetes_pod_label_a":          "1",
+				"__meta_kubernetes_pod_label_b":          "2",
+				"__meta_kubernetes_pod_annotation_large": "xxxxxxxxxx",
+				"__meta_kubernetes_pod_annotation_small": "x",
+			},
+		},
+		{
+			name:       "value length limit",
+			metaLabels: &MetaLabelsArguments{ValueLengthLimit: 5},
+			expect: map[string]string{
+				"__meta_kubernetes_pod_label_a":          "1",
+				"__meta_kubernetes_pod_label_b":          "2",
+				"__meta_kubernetes_pod_label_c":          "3",
+				"__meta_kubernetes_pod_annotation_small": "x",
+			},
+		},
+		{
+			name:       "drop annotations",
+			metaLabels: &MetaLabelsArguments{DropAnnotations: true},
+			expect: map[string]string{
+				"__meta_kubernetes_pod_label_a": "1",
+				"__meta_kubernetes_pod_label_b": "2",
+				"__meta_kubernetes_pod_label_c": "3",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			opts.MetaLabels = tc.metaLabels
+
+			actual := make(map[string]string)
+			for _, l := range buildTargetLabels(opts) {
+				if strings.HasPrefix(l.Name, "__meta_kubernetes_pod_label_") || strings.HasPrefix(l.Name, "__meta_kubernetes_pod_annotation_") {
+					actual[l.Name] = l.Value
+				}
+			}
+			require.Equal(t, tc.expect, actual)
+		})
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/prometheus/prometheus/util/strutil"
 
 	"github.com/grafana/agent/component/common/loki"
 	"github.com/grafana/loki/clients/pkg/promtail/scrapeconfig"
@@ -32,6 +33,21 @@ var (
 	DefaultProtocol         = protocolTCP
 )
 
+// LabelConfig configures which fields of syslog messages are added to log
+// entries as labels. Unlike the internal __syslog_message_* labels, these
+// labels are kept without requiring relabeling rules.
+type LabelConfig struct {
+	// FacilityLabel and SeverityLabel are the names of the labels to store the
+	// facility and severity of messages in. Empty names disable the labels.
+	FacilityLabel string
+	SeverityLabel string
+
+	// StructuredData is the allowlist of structured data element IDs whose
+	// parameters are added as labels named <id>_<param>. Characters which are
+	// invalid in label names are replaced with underscores.
+	StructuredData []string
+}
+
 // SyslogTarget listens to syslog messages.
 // nolint:revive
 type SyslogTarget struct {
@@ -40,6 +56,7 @@ type SyslogTarget struct {
 	handler       loki.EntryHandler
 	config        *scrapeconfig.SyslogTargetConfig
 	relabelConfig []*relabel.Config
+	labelConfig   LabelConfig
 
 	transport Transport
 
@@ -59,6 +76,7 @@ func NewSyslogTarget(
 	logger log.Logger,
 	handler loki.EntryHandler,
 	relabel []*relabel.Config,
+	labelConfig LabelConfig,
 	config *scrapeconfig.SyslogTargetConfig,
 ) (*SyslogTarget, error) {
 
@@ -68,6 +86,7 @@ func NewSyslogTarget(
 		handler:       handler,
 		config:        config,
 		relabelConfig: relabel,
+		labelConfig:   labelConfig,
 		messagesDone:  make(chan struct{}),
 	}
 
@@ -148,6 +167,8 @@ func (t *SyslogTarget) handleMessage(connLabels labels.Labels, msg syslog.Messag
 		}
 	}
 
+	t.addConfiguredLabels(lb, rfc5424Msg)
+
 	processed := relabel.Process(lb.Labels(nil), t.relabelConfig...)
 
 	filtered := make(model.LabelSet)
@@ -177,6 +198,35 @@ func (t *SyslogTarget) handleMessage(connLabels labels.Labels, msg syslog.Messag
 	t.messages <- message{filtered, m, timestamp}
 }
 
+// addConfiguredLabels adds the labels enabled in the target's LabelConfig to
+// lb. They're added before relabeling so that relabeling rules can still
+// modify or drop them.
+func (t *SyslogTarget) addConfiguredLabels(lb *labels.Builder, msg *rfc5424.SyslogMessage) {
+	if name := t.labelConfig.FacilityLabel; name != "" {
+		if v := msg.FacilityLevel(); v != nil {
+			lb.Set(name, *v)
+		}
+	}
+	if name := t.labelConfig.SeverityLabel; name != "" {
+		if v := msg.SeverityLevel(); v != nil {
+			lb.Set(name, *v)
+		}
+	}
+
+	if len(t.labelConfig.StructuredData) == 0 || msg.StructuredData == nil {
+		return
+	}
+	for _, id := range t.labelConfig.StructuredData {
+		params, ok := (*msg.StructuredData)[id]
+		if !ok {
+			continue
+		}
+		for name, value := range params {
+			lb.Set(strutil.SanitizeLabelName(id+"_"+name), value)
+		}
+	}
+}
+
 func (t *SyslogTarget) messageSender(entries chan<- loki.Entry) {
 	for msg := range t.messages {
 		entries <- loki.Entry{
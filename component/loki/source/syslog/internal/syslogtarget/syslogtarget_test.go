@@ -313,7 +313,7 @@ func Benchmark_SyslogTarget(b *testing.B) {
 			client := fake.New(func() {})
 
 			metrics := NewMetrics(nil)
-			tgt, _ := NewSyslogTarget(metrics, log.NewNopLogger(), client, []*relabel.Config{}, &scrapeconfig.SyslogTargetConfig{
+			tgt, _ := NewSyslogTarget(metrics, log.NewNopLogger(), client, []*relabel.Config{}, LabelConfig{}, &scrapeconfig.SyslogTargetConfig{
 				ListenAddress:       "127.0.0.1:0",
 				ListenProtocol:      tt.protocol,
 				LabelStructuredData: true,
@@ -375,7 +375,7 @@ func TestSyslogTarget(t *testing.T) {
 			client := fake.New(func() {})
 
 			metrics := NewMetrics(nil)
-			tgt, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), &scrapeconfig.SyslogTargetConfig{
+			tgt, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), LabelConfig{}, &scrapeconfig.SyslogTargetConfig{
 				MaxMessageLength:    1 << 12, // explicitly not use default value
 				ListenAddress:       "127.0.0.1:0",
 				ListenProtocol:      tt.protocol,
@@ -439,6 +439,53 @@ func TestSyslogTarget(t *testing.T) {
 	}
 }
 
+func TestSyslogTarget_LabelConfig(t *testing.T) {
+	client := fake.New(func() {})
+
+	metrics := NewMetrics(nil)
+	labelConfig := LabelConfig{
+		FacilityLabel:  "facility",
+		SeverityLabel:  "level",
+		StructuredData: []string{"origin", "custom@32473"},
+	}
+	tgt, err := NewSyslogTarget(metrics, log.NewNopLogger(), client, []*relabel.Config{}, labelConfig, &scrapeconfig.SyslogTargetConfig{
+		ListenAddress:  "127.0.0.1:0",
+		ListenProtocol: protocolTCP,
+		Labels: model.LabelSet{
+			"test": "syslog_target",
+		},
+	})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, tgt.Stop())
+	}()
+
+	require.Eventually(t, tgt.Ready, time.Second, 10*time.Millisecond)
+
+	c, err := net.Dial(protocolTCP, tgt.ListenAddress().String())
+	require.NoError(t, err)
+
+	messages := []string{
+		`<165>1 2018-10-11T22:14:15.003Z host5 e - id1 [custom@32473 exkey="1"][origin software="agent"][meta sequenceId="7"] An application event log entry...`,
+	}
+	require.NoError(t, writeMessagesToStream(c, messages, fmtNewline))
+	require.NoError(t, c.Close())
+
+	require.Eventually(t, func() bool {
+		return len(client.Received()) == len(messages)
+	}, time.Second, 10*time.Millisecond)
+
+	require.Equal(t, model.LabelSet{
+		"test": "syslog_target",
+
+		"facility": "local4",
+		"level":    "notice",
+
+		"origin_software":    "agent",
+		"custom_32473_exkey": "1",
+	}, client.Received()[0].Labels)
+}
+
 func relabelConfig(t *testing.T) []*relabel.Config {
 	relabelCfg := `
 - source_labels: ['__syslog_message_severity']
@@ -490,7 +537,7 @@ func TestSyslogTarget_RFC5424Messages(t *testing.T) {
 			client := fake.New(func() {})
 
 			metrics := NewMetrics(nil)
-			tgt, err := NewSyslogTarget(metrics, logger, client, []*relabel.Config{}, &scrapeconfig.SyslogTargetConfig{
+			tgt, err := NewSyslogTarget(metrics, logger, client, []*relabel.Config{}, LabelConfig{}, &scrapeconfig.SyslogTargetConfig{
 				ListenAddress:       "127.0.0.1:0",
 				ListenProtocol:      tt.protocol,
 				LabelStructuredData: true,
@@ -540,7 +587,7 @@ func TestSyslogTarget_TLSConfigWithoutServerCertificate(t *testing.T) {
 	client := fake.New(func() {})
 
 	metrics := NewMetrics(nil)
-	_, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), &scrapeconfig.SyslogTargetConfig{
+	_, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), LabelConfig{}, &scrapeconfig.SyslogTargetConfig{
 		ListenAddress: "127.0.0.1:0",
 		TLSConfig: promconfig.TLSConfig{
 			KeyFile: "foo",
@@ -555,7 +602,7 @@ func TestSyslogTarget_TLSConfigWithoutServerKey(t *testing.T) {
 	client := fake.New(func() {})
 
 	metrics := NewMetrics(nil)
-	_, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), &scrapeconfig.SyslogTargetConfig{
+	_, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), LabelConfig{}, &scrapeconfig.SyslogTargetConfig{
 		ListenAddress: "127.0.0.1:0",
 		TLSConfig: promconfig.TLSConfig{
 			CertFile: "foo",
@@ -594,7 +641,7 @@ func testSyslogTargetWithTLS(t *testing.T, fmtFunc formatFunc) {
 	client := fake.New(func() {})
 
 	metrics := NewMetrics(nil)
-	tgt, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), &scrapeconfig.SyslogTargetConfig{
+	tgt, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), LabelConfig{}, &scrapeconfig.SyslogTargetConfig{
 		ListenAddress:       "127.0.0.1:0",
 		LabelStructuredData: true,
 		Labels: model.LabelSet{
@@ -726,7 +773,7 @@ func testSyslogTargetWithTLSVerifyClientCertificate(t *testing.T, fmtFunc format
 	client := fake.New(func() {})
 
 	metrics := NewMetrics(nil)
-	tgt, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), &scrapeconfig.SyslogTargetConfig{
+	tgt, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), LabelConfig{}, &scrapeconfig.SyslogTargetConfig{
 		ListenAddress:       "127.0.0.1:0",
 		LabelStructuredData: true,
 		Labels: model.LabelSet{
@@ -804,7 +851,7 @@ func TestSyslogTarget_InvalidData(t *testing.T) {
 	client := fake.New(func() {})
 	metrics := NewMetrics(nil)
 
-	tgt, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), &scrapeconfig.SyslogTargetConfig{
+	tgt, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), LabelConfig{}, &scrapeconfig.SyslogTargetConfig{
 		ListenAddress: "127.0.0.1:0",
 	})
 	require.NoError(t, err)
@@ -835,7 +882,7 @@ func TestSyslogTarget_NonUTF8Message(t *testing.T) {
 	client := fake.New(func() {})
 	metrics := NewMetrics(nil)
 
-	tgt, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), &scrapeconfig.SyslogTargetConfig{
+	tgt, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), LabelConfig{}, &scrapeconfig.SyslogTargetConfig{
 		ListenAddress: "127.0.0.1:0",
 	})
 	require.NoError(t, err)
@@ -873,7 +920,7 @@ func TestSyslogTarget_IdleTimeout(t *testing.T) {
 	client := fake.New(func() {})
 	metrics := NewMetrics(nil)
 
-	tgt, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), &scrapeconfig.SyslogTargetConfig{
+	tgt, err := NewSyslogTarget(metrics, logger, client, relabelConfig(t), LabelConfig{}, &scrapeconfig.SyslogTargetConfig{
 		ListenAddress: "127.0.0.1:0",
 		IdleTimeout:   time.Millisecond,
 	})
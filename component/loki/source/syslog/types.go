@@ -22,6 +22,10 @@ type ListenerConfig struct {
 	UseRFC5424Message    bool              `river:"use_rfc5424_message,attr,optional"`
 	MaxMessageLength     int               `river:"max_message_length,attr,optional"`
 	TLSConfig            config.TLSConfig  `river:"tls_config,block,optional"`
+
+	FacilityLabel           string   `river:"facility_label,attr,optional"`
+	SeverityLabel           string   `river:"severity_label,attr,optional"`
+	StructuredDataAllowlist []string `river:"structured_data_allowlist,attr,optional"`
 }
 
 // DefaultListenerConfig provides the default arguments for a syslog listener.
@@ -47,6 +51,12 @@ func (sc *ListenerConfig) UnmarshalRiver(f func(interface{}) error) error {
 		return fmt.Errorf("syslog listener protocol should be either 'tcp' or 'udp', got %s", sc.ListenProtocol)
 	}
 
+	for _, name := range []string{sc.FacilityLabel, sc.SeverityLabel} {
+		if name != "" && !model.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid label name %q", name)
+		}
+	}
+
 	return nil
 }
 
@@ -69,3 +79,13 @@ func (sc ListenerConfig) Convert() *scrapeconfig.SyslogTargetConfig {
 		TLSConfig:            *sc.TLSConfig.Convert(),
 	}
 }
+
+// LabelConfig returns the labels to add to entries from the listener's
+// messages.
+func (sc ListenerConfig) LabelConfig() st.LabelConfig {
+	return st.LabelConfig{
+		FacilityLabel:  sc.FacilityLabel,
+		SeverityLabel:  sc.SeverityLabel,
+		StructuredData: sc.StructuredDataAllowlist,
+	}
+}
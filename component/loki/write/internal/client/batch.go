@@ -29,6 +29,10 @@ type batch struct {
 	bytes     int
 	createdAt time.Time
 
+	// acks holds the Acknowledgers of the entries in the batch, notified once
+	// the batch has been sent or dropped.
+	acks []loki.Acknowledger
+
 	maxStreams int
 }
 
@@ -56,6 +60,7 @@ func (b *batch) add(entry loki.Entry) error {
 	// Append the entry to an already existing stream (if any)
 	labels := labelsMapToString(entry.Labels, ReservedLabelTenantID)
 	if stream, ok := b.streams[labels]; ok {
+		b.addAck(entry.Ack)
 		stream.Entries = append(stream.Entries, entry.Entry)
 		return nil
 	}
@@ -64,6 +69,8 @@ func (b *batch) add(entry loki.Entry) error {
 	if b.maxStreams > 0 && streams >= b.maxStreams {
 		return fmt.Errorf(errMaxStreamsLimitExceeded, streams, b.maxStreams, labels)
 	}
+	b.addAck(entry.Ack)
+
 	// Add the entry as a new stream
 	b.streams[labels] = &logproto.Stream{
 		Labels:  labels,
@@ -88,6 +95,20 @@ Outer:
 	return fmt.Sprintf("{%s}", strings.Join(lstrs, ", "))
 }
 
+func (b *batch) addAck(a loki.Acknowledger) {
+	if a != nil {
+		b.acks = append(b.acks, a)
+	}
+}
+
+// ack notifies the Acknowledgers of all entries in the batch.
+func (b *batch) ack() {
+	for _, a := range b.acks {
+		a.Ack()
+	}
+	b.acks = nil
+}
+
 // sizeBytes returns the current batch size in bytes
 func (b *batch) sizeBytes() int {
 	return b.bytes
@@ -18,6 +18,7 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/component/common/deadletter"
 	"github.com/grafana/agent/component/common/loki"
 	"github.com/grafana/dskit/backoff"
 	lokiutil "github.com/grafana/loki/pkg/util"
@@ -159,6 +160,9 @@ func New(metrics *Metrics, cfg Config, streamLagLabels []string, maxStreams int,
 	if cfg.StreamLagLabels.String() != "" {
 		return nil, fmt.Errorf("client config stream_lag_labels is deprecated in favour of the config file options block field, and will be ignored: %+v", cfg.StreamLagLabels.String())
 	}
+	if cfg.Shards > 1 {
+		return newShardedClient(metrics, cfg, streamLagLabels, maxStreams, logger)
+	}
 	return newClient(metrics, cfg, streamLagLabels, maxStreams, logger)
 }
 
@@ -279,6 +283,7 @@ func (c *client) run() {
 			if err != nil {
 				level.Error(c.logger).Log("msg", "batch add err", "error", err)
 				c.metrics.droppedEntries.WithLabelValues(c.cfg.URL.Host).Inc()
+				e.Acknowledge()
 				return
 			}
 		case <-maxWaitCheck.C:
@@ -308,6 +313,17 @@ func asSha256(o interface{}) string {
 }
 
 func (c *client) sendBatch(tenantID string, batch *batch) {
+	// Acknowledge the batch once it's been sent or permanently dropped, so
+	// sources waiting for delivery can advance their positions. Batches
+	// abandoned because the client is being stopped without retries are left
+	// unacknowledged so they're read again after a restart.
+	var err error
+	defer func() {
+		if err == nil || c.ctx.Err() == nil {
+			batch.ack()
+		}
+	}()
+
 	buf, entriesCount, err := batch.encode()
 	if err != nil {
 		level.Error(c.logger).Log("msg", "error encoding batch", "error", err)
@@ -384,6 +400,30 @@ func (c *client) sendBatch(tenantID string, batch *batch) {
 		level.Error(c.logger).Log("msg", "final error sending batch", "status", status, "error", err)
 		c.metrics.droppedBytes.WithLabelValues(c.cfg.URL.Host).Add(bufBytes)
 		c.metrics.droppedEntries.WithLabelValues(c.cfg.URL.Host).Add(float64(entriesCount))
+		c.deadLetter(tenantID, buf, err)
+	}
+}
+
+// deadLetter writes a batch which couldn't be sent to the dead-letter spool,
+// if one is configured.
+func (c *client) deadLetter(tenantID string, buf []byte, sendErr error) {
+	if c.cfg.DeadLetter == nil {
+		return
+	}
+
+	headers := map[string]string{"Content-Type": contentType}
+	if tenantID != "" {
+		headers["X-Scope-OrgID"] = tenantID
+	}
+
+	err := c.cfg.DeadLetter.Write(deadletter.Record{
+		URL:     c.cfg.URL.String(),
+		Headers: headers,
+		Reason:  sendErr.Error(),
+		Payload: buf,
+	})
+	if err != nil {
+		level.Error(c.logger).Log("msg", "failed to write batch to dead-letter spool", "error", err)
 	}
 }
 
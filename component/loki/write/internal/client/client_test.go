@@ -26,6 +26,7 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
 )
 
 var logEntries = []loki.Entry{
@@ -446,6 +447,57 @@ func TestClient_StopNow(t *testing.T) {
 	}
 }
 
+type countingAck struct{ acks atomic.Int32 }
+
+func (c *countingAck) Ack() { c.acks.Inc() }
+
+func TestClient_AcknowledgesEntries(t *testing.T) {
+	tests := map[string]struct {
+		serverResponseStatus int
+	}{
+		"entries are acknowledged once sent": {
+			serverResponseStatus: 200,
+		},
+		"entries are acknowledged once dropped": {
+			serverResponseStatus: 400,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			receivedReqsChan := make(chan receivedReq, 10)
+			server := httptest.NewServer(createServerHandler(receivedReqsChan, testData.serverResponseStatus))
+			defer server.Close()
+
+			serverURL := flagext.URLValue{}
+			require.NoError(t, serverURL.Set(server.URL))
+
+			cfg := Config{
+				URL:           serverURL,
+				BatchWait:     10 * time.Millisecond,
+				BatchSize:     100,
+				BackoffConfig: backoff.Config{MinBackoff: 1 * time.Millisecond, MaxBackoff: 2 * time.Millisecond, MaxRetries: 1},
+				Timeout:       1 * time.Second,
+			}
+
+			c, err := New(NewMetrics(prometheus.NewRegistry(), nil), cfg, nil, 0, log.NewNopLogger())
+			require.NoError(t, err)
+
+			var ack countingAck
+			for _, e := range logEntries {
+				e.Ack = &ack
+				c.Chan() <- e
+			}
+
+			require.Eventually(t, func() bool {
+				return int(ack.acks.Load()) == len(logEntries)
+			}, 5*time.Second, 10*time.Millisecond)
+
+			c.Stop()
+		})
+	}
+}
+
 func createServerHandler(receivedReqsChan chan receivedReq, status int) http.HandlerFunc {
 	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		// Parse the request
@@ -7,6 +7,7 @@ import (
 	"flag"
 	"time"
 
+	"github.com/grafana/agent/component/common/deadletter"
 	"github.com/grafana/dskit/backoff"
 	"github.com/grafana/dskit/flagext"
 	"github.com/prometheus/common/config"
@@ -44,6 +45,14 @@ type Config struct {
 
 	// deprecated use StreamLagLabels from config.Config instead
 	StreamLagLabels flagext.StringSliceCSV `yaml:"stream_lag_labels"`
+
+	// DeadLetter, if set, receives batches which couldn't be sent after all
+	// retries were exhausted.
+	DeadLetter *deadletter.Spool `yaml:"-"`
+
+	// Shards is the number of senders pushing to URL in parallel. Values
+	// below 2 use a single sender.
+	Shards int `yaml:"-"`
 }
 
 // RegisterFlags with prefix registers flags where every name is prefixed by
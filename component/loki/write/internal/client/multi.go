@@ -59,6 +59,7 @@ func (m *MultiClient) start() {
 	go func() {
 		defer m.wg.Done()
 		for e := range m.entries {
+			e.Ack = loki.FanoutAck(e.Ack, len(m.clients))
 			for _, c := range m.clients {
 				c.Chan() <- e
 			}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/agent/component/common/loki"
+)
+
+// shardedClient sends entries to the same Loki instance through several
+// clients running in parallel. Entries are assigned to a shard by the hash of
+// their labels, so all entries of a stream are sent by the same shard and
+// keep their order.
+type shardedClient struct {
+	name    string
+	shards  []*client
+	entries chan loki.Entry
+	wg      sync.WaitGroup
+
+	once sync.Once
+}
+
+func newShardedClient(metrics *Metrics, cfg Config, streamLagLabels []string, maxStreams int, logger log.Logger) (*shardedClient, error) {
+	perShardMaxStreams := shardMaxStreams(maxStreams, cfg.Shards)
+
+	shards := make([]*client, 0, cfg.Shards)
+	for i := 0; i < cfg.Shards; i++ {
+		shard, err := newClient(metrics, cfg, streamLagLabels, perShardMaxStreams, log.With(logger, "shard", i))
+		if err != nil {
+			for _, s := range shards {
+				s.StopNow()
+			}
+			return nil, err
+		}
+		shards = append(shards, shard)
+	}
+
+	s := &shardedClient{
+		name:    shards[0].Name(),
+		shards:  shards,
+		entries: make(chan loki.Entry),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// shardMaxStreams divides the maxStreams limit of an endpoint across its
+// shards, rounding up so every shard can send at least one stream. A limit of
+// 0 means no limit, and is the same for every shard.
+func shardMaxStreams(maxStreams, shards int) int {
+	if maxStreams <= 0 {
+		return maxStreams
+	}
+	return (maxStreams + shards - 1) / shards
+}
+
+func (s *shardedClient) run() {
+	defer s.wg.Done()
+	for e := range s.entries {
+		shard := s.shards[uint64(e.Labels.FastFingerprint())%uint64(len(s.shards))]
+		shard.Chan() <- e
+	}
+}
+
+// Chan implements Client.
+func (s *shardedClient) Chan() chan<- loki.Entry {
+	return s.entries
+}
+
+// Stop implements Client. Pending batches of all shards are sent before
+// returning.
+func (s *shardedClient) Stop() {
+	s.once.Do(func() { close(s.entries) })
+	s.wg.Wait()
+
+	var wg sync.WaitGroup
+	for _, shard := range s.shards {
+		wg.Add(1)
+		go func(shard *client) {
+			defer wg.Done()
+			shard.Stop()
+		}(shard)
+	}
+	wg.Wait()
+}
+
+// StopNow implements Client.
+func (s *shardedClient) StopNow() {
+	for _, shard := range s.shards {
+		// Cancel all shards first so none of them keeps retrying while the
+		// others are stopped.
+		shard.cancel()
+	}
+	s.Stop()
+}
+
+// Name implements Client.
+func (s *shardedClient) Name() string {
+	return s.name
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/agent/component/common/loki"
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardedClient_PreservesStreamOrder(t *testing.T) {
+	const (
+		streams          = 10
+		entriesPerStream = 50
+	)
+
+	receivedReqsChan := make(chan receivedReq, streams*entriesPerStream)
+	server := httptest.NewServer(createServerHandler(receivedReqsChan, http.StatusOK))
+	defer server.Close()
+
+	serverURL := flagext.URLValue{}
+	require.NoError(t, serverURL.Set(server.URL))
+
+	cfg := Config{
+		URL:           serverURL,
+		BatchWait:     10 * time.Millisecond,
+		BatchSize:     100,
+		BackoffConfig: backoff.Config{MinBackoff: 1 * time.Millisecond, MaxBackoff: 2 * time.Millisecond, MaxRetries: 1},
+		Timeout:       1 * time.Second,
+		Shards:        4,
+	}
+
+	c, err := New(NewMetrics(prometheus.NewRegistry(), nil), cfg, nil, 0, log.NewNopLogger())
+	require.NoError(t, err)
+	require.IsType(t, &shardedClient{}, c)
+	require.Len(t, c.(*shardedClient).shards, 4)
+
+	for i := 0; i < entriesPerStream; i++ {
+		for s := 0; s < streams; s++ {
+			c.Chan() <- loki.Entry{
+				Labels: model.LabelSet{"stream": model.LabelValue(fmt.Sprint(s))},
+				Entry:  logproto.Entry{Timestamp: time.Unix(int64(i), 0), Line: fmt.Sprint(i)},
+			}
+		}
+	}
+
+	// Stop sends all pending batches.
+	c.Stop()
+	close(receivedReqsChan)
+
+	received := make(map[string][]string)
+	for req := range receivedReqsChan {
+		for _, s := range req.pushReq.Streams {
+			for _, e := range s.Entries {
+				received[s.Labels] = append(received[s.Labels], e.Line)
+			}
+		}
+	}
+
+	require.Len(t, received, streams)
+	for labels, lines := range received {
+		require.Len(t, lines, entriesPerStream, "stream %s", labels)
+		for i, line := range lines {
+			require.Equal(t, fmt.Sprint(i), line, "entries of stream %s out of order", labels)
+		}
+	}
+}
+
+func TestShardedClient_DividesMaxStreams(t *testing.T) {
+	serverURL := flagext.URLValue{}
+	require.NoError(t, serverURL.Set("http://localhost:3100/loki/api/v1/push"))
+
+	tt := []struct {
+		maxStreams, shards, expect int
+	}{
+		{maxStreams: 0, shards: 4, expect: 0},
+		{maxStreams: 100, shards: 4, expect: 25},
+		{maxStreams: 10, shards: 4, expect: 3},
+		{maxStreams: 2, shards: 4, expect: 1},
+	}
+	for _, tc := range tt {
+		cfg := Config{URL: serverURL, BatchWait: time.Second, BatchSize: 100, Timeout: time.Second, Shards: tc.shards}
+		c, err := New(NewMetrics(prometheus.NewRegistry(), nil), cfg, nil, tc.maxStreams, log.NewNopLogger())
+		require.NoError(t, err)
+		for _, shard := range c.(*shardedClient).shards {
+			require.Equal(t, tc.expect, shard.maxStreams, "max_streams %d across %d shards", tc.maxStreams, tc.shards)
+		}
+		c.Stop()
+	}
+}
@@ -25,6 +25,7 @@ type EndpointOptions struct {
 	MaxBackoff        time.Duration           `river:"max_backoff_period,attr,optional"`  // increase exponentially to this level
 	MaxBackoffRetries int                     `river:"max_backoff_retries,attr,optional"` // give up after this many; zero means infinite retries
 	TenantID          string                  `river:"tenant_id,attr,optional"`
+	Shards            int                     `river:"shards,attr,optional"` // number of parallel senders
 	HTTPClientConfig  *types.HTTPClientConfig `river:",squash"`
 }
 
@@ -41,6 +42,7 @@ func GetDefaultEndpointOptions() EndpointOptions {
 		MinBackoff:        500 * time.Millisecond,
 		MaxBackoff:        5 * time.Minute,
 		MaxBackoffRetries: 10,
+		Shards:            1,
 		HTTPClientConfig:  types.CloneDefaultHTTPClientConfig(),
 	}
 
@@ -60,6 +62,10 @@ func (r *EndpointOptions) UnmarshalRiver(f func(v interface{}) error) error {
 		return fmt.Errorf("failed to parse remote url %q: %w", r.URL, err)
 	}
 
+	if r.Shards < 1 {
+		return fmt.Errorf("shards must be at least 1")
+	}
+
 	// We must explicitly Validate because HTTPClientConfig is squashed and it won't run otherwise
 	if r.HTTPClientConfig != nil {
 		return r.HTTPClientConfig.Validate()
@@ -68,6 +74,34 @@ func (r *EndpointOptions) UnmarshalRiver(f func(v interface{}) error) error {
 	return nil
 }
 
+// DeadLetterOptions configures where batches are written after all retries
+// to send them have been exhausted.
+type DeadLetterOptions struct {
+	Path    string           `river:"path,attr,optional"`
+	MaxSize units.Base2Bytes `river:"max_size,attr,optional"`
+}
+
+// DefaultDeadLetterOptions defines the default settings for the dead-letter
+// spool.
+var DefaultDeadLetterOptions = DeadLetterOptions{
+	MaxSize: 256 * units.MiB,
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (o *DeadLetterOptions) UnmarshalRiver(f func(v interface{}) error) error {
+	*o = DefaultDeadLetterOptions
+
+	type options DeadLetterOptions
+	if err := f((*options)(o)); err != nil {
+		return err
+	}
+
+	if o.MaxSize < 0 {
+		return fmt.Errorf("max_size must not be negative")
+	}
+	return nil
+}
+
 func (args Arguments) convertClientConfigs() []client.Config {
 	var res []client.Config
 	for _, cfg := range args.Endpoints {
@@ -86,6 +120,7 @@ func (args Arguments) convertClientConfigs() []client.Config {
 			ExternalLabels: lokiflagext.LabelSet{LabelSet: toLabelSet(args.ExternalLabels)},
 			Timeout:        cfg.RemoteTimeout,
 			TenantID:       cfg.TenantID,
+			Shards:         cfg.Shards,
 		}
 		res = append(res, cc)
 	}
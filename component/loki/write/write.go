@@ -3,9 +3,12 @@ package write
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"sync"
 
+	"github.com/go-kit/log/level"
 	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/common/deadletter"
 	"github.com/grafana/agent/component/common/loki"
 	"github.com/grafana/agent/component/loki/write/internal/client"
 	"github.com/grafana/agent/pkg/build"
@@ -29,9 +32,10 @@ func init() {
 
 // Arguments holds values which are used to configure the loki.write component.
 type Arguments struct {
-	Endpoints      []EndpointOptions `river:"endpoint,block,optional"`
-	ExternalLabels map[string]string `river:"external_labels,attr,optional"`
-	MaxStreams     int               `river:"max_streams,attr,optional"`
+	Endpoints      []EndpointOptions  `river:"endpoint,block,optional"`
+	ExternalLabels map[string]string  `river:"external_labels,attr,optional"`
+	MaxStreams     int                `river:"max_streams,attr,optional"`
+	DeadLetter     *DeadLetterOptions `river:"dead_letter,block,optional"`
 }
 
 // Exports holds the receiver that is used to send log entries to the
@@ -41,7 +45,8 @@ type Exports struct {
 }
 
 var (
-	_ component.Component = (*Component)(nil)
+	_ component.Component        = (*Component)(nil)
+	_ component.StorageComponent = (*Component)(nil)
 )
 
 // Component implements the loki.write component.
@@ -49,10 +54,11 @@ type Component struct {
 	opts    component.Options
 	metrics *client.Metrics
 
-	mut      sync.RWMutex
-	args     Arguments
-	receiver loki.LogsReceiver
-	clients  []client.Client
+	mut        sync.RWMutex
+	args       Arguments
+	receiver   loki.LogsReceiver
+	clients    []client.Client
+	deadLetter *deadletter.Spool
 }
 
 // New creates a new loki.write component.
@@ -82,6 +88,15 @@ func (c *Component) Run(ctx context.Context) error {
 		case <-ctx.Done():
 			return nil
 		case entry := <-c.receiver:
+			// Every endpoint must handle the entry before it's acknowledged.
+			var endpoints int
+			for _, client := range c.clients {
+				if client != nil {
+					endpoints++
+				}
+			}
+			entry.Ack = loki.FanoutAck(entry.Ack, endpoints)
+
 			for _, client := range c.clients {
 				if client != nil {
 					select {
@@ -102,6 +117,11 @@ func (c *Component) Update(args component.Arguments) error {
 
 	c.mut.Lock()
 	defer c.mut.Unlock()
+
+	spool, err := c.updateDeadLetter(newArgs.DeadLetter)
+	if err != nil {
+		return err
+	}
 	c.args = newArgs
 
 	for _, client := range c.clients {
@@ -109,6 +129,9 @@ func (c *Component) Update(args component.Arguments) error {
 			client.Stop()
 		}
 	}
+	// The old clients are stopped, so nothing writes to the previous spool
+	// anymore.
+	c.replaceDeadLetter(spool)
 	c.clients = make([]client.Client, len(newArgs.Endpoints))
 
 	cfgs := newArgs.convertClientConfigs()
@@ -116,6 +139,7 @@ func (c *Component) Update(args component.Arguments) error {
 	// fanout logic back to the client layer, but I opted to keep it explicit
 	// here a) for easier debugging and b) possible improvements in the future.
 	for _, cfg := range cfgs {
+		cfg.DeadLetter = c.deadLetter
 		client, err := client.New(c.metrics, cfg, streamLagLabels, newArgs.MaxStreams, c.opts.Logger)
 		if err != nil {
 			return err
@@ -125,3 +149,55 @@ func (c *Component) Update(args component.Arguments) error {
 
 	return nil
 }
+
+// StoragePaths implements component.StorageComponent.
+func (c *Component) StoragePaths() []component.StoragePath {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	if c.deadLetter == nil {
+		return nil
+	}
+	return []component.StoragePath{{Path: c.deadLetter.Dir(), Buffer: true}}
+}
+
+// updateDeadLetter returns the dead-letter spool to use for opts, which is
+// nil if opts is nil. The current spool is kept if it's in the same
+// directory, so that there's a single spool accounting for the size of each
+// directory. c.mut must be held when calling.
+func (c *Component) updateDeadLetter(opts *DeadLetterOptions) (*deadletter.Spool, error) {
+	if opts == nil {
+		return nil, nil
+	}
+
+	dir := opts.Path
+	if dir == "" {
+		dir = filepath.Join(c.opts.DataPath, "dead-letter")
+	}
+	if c.deadLetter != nil && c.deadLetter.Dir() == dir {
+		return c.deadLetter, c.deadLetter.SetMaxSize(int64(opts.MaxSize))
+	}
+
+	// A new spool is opened before the current one is replaced, so the current
+	// one keeps being used if the new one can't be opened.
+	return deadletter.New(dir, int64(opts.MaxSize), "loki_write", nil)
+}
+
+// replaceDeadLetter replaces the dead-letter spool with spool, moving the
+// registration of the spool metrics over to it. It must only be called once
+// no clients use the current spool anymore. c.mut must be held when calling.
+func (c *Component) replaceDeadLetter(spool *deadletter.Spool) {
+	if spool == c.deadLetter {
+		return
+	}
+
+	if c.deadLetter != nil {
+		c.deadLetter.Unregister(c.opts.Registerer)
+	}
+	if spool != nil {
+		if err := spool.Register(c.opts.Registerer); err != nil {
+			level.Warn(c.opts.Logger).Log("msg", "failed to register dead-letter spool metrics", "err", err)
+		}
+	}
+	c.deadLetter = spool
+}
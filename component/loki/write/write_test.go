@@ -6,15 +6,22 @@ import (
 	"math"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/alecthomas/units"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/common/deadletter"
 	"github.com/grafana/agent/component/common/loki"
 	"github.com/grafana/agent/pkg/flow/componenttest"
 	"github.com/grafana/agent/pkg/river"
 	"github.com/grafana/agent/pkg/util"
 	"github.com/grafana/loki/pkg/logproto"
 	loki_util "github.com/grafana/loki/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 )
@@ -113,3 +120,111 @@ func Test(t *testing.T) {
 		require.Equal(t, req.Streams[0].Entries[1].Line, logEntry.Line)
 	}
 }
+
+func TestDeadLetter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "entry too far behind", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	cfg := fmt.Sprintf(`
+		endpoint {
+			url        = "%s"
+			batch_wait = "10ms"
+			tenant_id  = "tenant-1"
+		}
+		dead_letter {
+			path = "%s"
+		}
+	`, srv.URL, dir)
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+	require.Equal(t, DefaultDeadLetterOptions.MaxSize, args.DeadLetter.MaxSize)
+
+	tc, err := componenttest.NewControllerFromID(util.TestLogger(t), "loki.write")
+	require.NoError(t, err)
+	go func() {
+		err = tc.Run(componenttest.TestContext(t), args)
+		require.NoError(t, err)
+	}()
+	require.NoError(t, tc.WaitExports(time.Second))
+
+	tc.Exports().(Exports).Receiver <- loki.Entry{
+		Labels: model.LabelSet{"foo": "bar"},
+		Entry: logproto.Entry{
+			Timestamp: time.Now(),
+			Line:      "very important log",
+		},
+	}
+
+	spool, err := deadletter.New(dir, 0, "", nil)
+	require.NoError(t, err)
+
+	var recs []deadletter.Record
+	require.Eventually(t, func() bool {
+		recs, err = spool.List()
+		require.NoError(t, err)
+		return len(recs) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.Equal(t, srv.URL, recs[0].URL)
+	require.Equal(t, "tenant-1", recs[0].Headers["X-Scope-OrgID"])
+	require.Contains(t, recs[0].Reason, "entry too far behind")
+}
+
+func TestDeadLetter_UpdateFails(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	dir := t.TempDir()
+	c, err := New(component.Options{
+		Logger:        util.TestFlowLogger(t),
+		Registerer:    reg,
+		OnStateChange: func(e component.Exports) {},
+		DataPath:      dir,
+	}, Arguments{DeadLetter: &DeadLetterOptions{Path: filepath.Join(dir, "spool")}})
+	require.NoError(t, err)
+	spool := c.deadLetter
+
+	// A file in place of the spool directory can't be opened.
+	invalidPath := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(invalidPath, nil, 0600))
+	err = c.Update(Arguments{DeadLetter: &DeadLetterOptions{Path: invalidPath}})
+	require.Error(t, err)
+
+	// The previous spool is still used, along with its metrics.
+	require.Same(t, spool, c.deadLetter)
+	count, err := testutil.GatherAndCount(reg, "loki_write_dead_letter_records")
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	// Replacing the spool unregisters the metrics of the previous one.
+	require.NoError(t, c.Update(Arguments{DeadLetter: &DeadLetterOptions{Path: filepath.Join(dir, "other")}}))
+	require.NotSame(t, spool, c.deadLetter)
+	count, err = testutil.GatherAndCount(reg, "loki_write_dead_letter_records")
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
+
+func TestDeadLetter_UpdateSameDir(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(component.Options{
+		Logger:        util.TestFlowLogger(t),
+		Registerer:    prometheus.NewRegistry(),
+		OnStateChange: func(e component.Exports) {},
+		DataPath:      dir,
+	}, Arguments{DeadLetter: &DeadLetterOptions{MaxSize: units.MiB}})
+	require.NoError(t, err)
+	spool := c.deadLetter
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, spool.Write(deadletter.Record{Payload: make([]byte, 100)}))
+	}
+
+	// The spool of the same directory is kept, so its size is only accounted
+	// for once, and the new maximum size applies to it right away.
+	require.NoError(t, c.Update(Arguments{DeadLetter: &DeadLetterOptions{MaxSize: 200}}))
+	require.Same(t, spool, c.deadLetter)
+	recs, err := spool.List()
+	require.NoError(t, err)
+	require.Len(t, recs, 1)
+}
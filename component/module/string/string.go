@@ -112,7 +112,8 @@ func (c *Component) Update(args component.Arguments) error {
 func (c *Component) Handler() http.Handler {
 	r := mux.NewRouter()
 
-	fa := api.NewFlowAPI(c.ctrl, r)
+	// Administrative endpoints aren't supported for components of modules.
+	fa := api.NewFlowAPI(c.ctrl, nil, r, "")
 	fa.RegisterRoutes("/", r)
 
 	r.PathPrefix("/{id}/").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -27,13 +27,9 @@ type GRPCServerArguments struct {
 
 	Keepalive *KeepaliveServerArguments `river:"keepalive,block,optional"`
 
-	// TODO(rfratto): auth
-	//
-	// Figuring out how to do authentication isn't very straightforward here. The
-	// auth section links to an authenticator extension.
-	//
-	// We will need to generally figure out how we want to provide common
-	// authentication extensions to all of our components.
+	// Auth is a binding to an otelcol.auth.* or otelcol.extension.basicauth
+	// component extension which authenticates incoming requests.
+	Auth *auth.Handler `river:"auth,attr,optional"`
 
 	IncludeMetadata bool `river:"include_metadata,attr,optional"`
 }
@@ -44,6 +40,12 @@ func (args *GRPCServerArguments) Convert() *otelconfiggrpc.GRPCServerSettings {
 		return nil
 	}
 
+	// Configure the authentication if args.Auth is set.
+	var auth *otelconfigauth.Authentication
+	if args.Auth != nil {
+		auth = &otelconfigauth.Authentication{AuthenticatorID: args.Auth.ID}
+	}
+
 	return &otelconfiggrpc.GRPCServerSettings{
 		NetAddr: confignet.NetAddr{
 			Endpoint:  args.Endpoint,
@@ -59,10 +61,21 @@ func (args *GRPCServerArguments) Convert() *otelconfiggrpc.GRPCServerSettings {
 
 		Keepalive: args.Keepalive.Convert(),
 
+		Auth: auth,
+
 		IncludeMetadata: args.IncludeMetadata,
 	}
 }
 
+// Extensions exposes extensions used by args.
+func (args *GRPCServerArguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	m := make(map[otelconfig.ComponentID]otelcomponent.Extension)
+	if args != nil && args.Auth != nil {
+		m[args.Auth.ID] = args.Auth.Extension
+	}
+	return m
+}
+
 // KeepaliveServerArguments holds shared keepalive settings for components
 // which launch servers.
 type KeepaliveServerArguments struct {
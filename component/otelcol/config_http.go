@@ -20,13 +20,9 @@ type HTTPServerArguments struct {
 
 	CORS *CORSArguments `river:"cors,block,optional"`
 
-	// TODO(rfratto): auth
-	//
-	// Figuring out how to do authentication isn't very straightforward here. The
-	// auth section links to an authenticator extension.
-	//
-	// We will need to generally figure out how we want to provide common
-	// authentication extensions to all of our components.
+	// Auth is a binding to an otelcol.auth.* or otelcol.extension.basicauth
+	// component extension which authenticates incoming requests.
+	Auth *auth.Handler `river:"auth,attr,optional"`
 
 	MaxRequestBodySize units.Base2Bytes `river:"max_request_body_size,attr,optional"`
 	IncludeMetadata    bool             `river:"include_metadata,attr,optional"`
@@ -38,15 +34,31 @@ func (args *HTTPServerArguments) Convert() *otelconfighttp.HTTPServerSettings {
 		return nil
 	}
 
+	// Configure the authentication if args.Auth is set.
+	var auth *otelconfigauth.Authentication
+	if args.Auth != nil {
+		auth = &otelconfigauth.Authentication{AuthenticatorID: args.Auth.ID}
+	}
+
 	return &otelconfighttp.HTTPServerSettings{
 		Endpoint:           args.Endpoint,
 		TLSSetting:         args.TLS.Convert(),
 		CORS:               args.CORS.Convert(),
+		Auth:               auth,
 		MaxRequestBodySize: int64(args.MaxRequestBodySize),
 		IncludeMetadata:    args.IncludeMetadata,
 	}
 }
 
+// Extensions exposes extensions used by args.
+func (args *HTTPServerArguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	m := make(map[otelconfig.ComponentID]otelcomponent.Extension)
+	if args != nil && args.Auth != nil {
+		m[args.Auth.ID] = args.Auth.Extension
+	}
+	return m
+}
+
 // CORSArguments holds shared CORS settings for components which launch HTTP
 // servers.
 type CORSArguments struct {
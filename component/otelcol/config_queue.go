@@ -3,7 +3,10 @@ package otelcol
 import (
 	"fmt"
 
+	"github.com/grafana/agent/component/otelcol/storage"
 	"github.com/grafana/agent/pkg/river"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
 	otelexporterhelper "go.opentelemetry.io/collector/exporter/exporterhelper"
 )
 
@@ -14,7 +17,9 @@ type QueueArguments struct {
 	NumConsumers int  `river:"num_consumers,attr,optional"`
 	QueueSize    int  `river:"queue_size,attr,optional"`
 
-	// TODO(rfratto): queues can send to persistent storage through an extension.
+	// Storage persists the queue through a storage extension, so that queued
+	// batches survive restarts.
+	Storage *storage.Handler `river:"storage,attr,optional"`
 }
 
 var _ river.Unmarshaler = (*QueueArguments)(nil)
@@ -49,11 +54,24 @@ func (args *QueueArguments) Convert() *otelexporterhelper.QueueSettings {
 		return nil
 	}
 
-	return &otelexporterhelper.QueueSettings{
+	res := &otelexporterhelper.QueueSettings{
 		Enabled:      args.Enabled,
 		NumConsumers: args.NumConsumers,
 		QueueSize:    args.QueueSize,
 	}
+	if args.Storage != nil {
+		res.StorageID = &args.Storage.ID
+	}
+	return res
+}
+
+// Extensions exposes extensions used by args.
+func (args *QueueArguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	m := make(map[otelconfig.ComponentID]otelcomponent.Extension)
+	if args != nil && args.Storage != nil {
+		m[args.Storage.ID] = args.Storage.Extension
+	}
+	return m
 }
 
 // Validate returns an error if args is invalid.
@@ -0,0 +1,263 @@
+// Package datadog provides an otelcol.exporter.datadog component.
+package datadog
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/exporter"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/datadogexporter"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confignet"
+	otelexporterhelper "go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "otelcol.exporter.datadog",
+		Args:    Arguments{},
+		Exports: otelcol.ConsumerExports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := datadogexporter.NewFactory()
+			return exporter.New(opts, fact, args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.exporter.datadog component.
+type Arguments struct {
+	API          APIArguments          `river:"api,block"`
+	Hostname     string                `river:"hostname,attr,optional"`
+	HostMetadata HostMetadataArguments `river:"host_metadata,block,optional"`
+	Metrics      MetricsArguments      `river:"metrics,block,optional"`
+	Traces       TracesArguments       `river:"traces,block,optional"`
+
+	Timeout time.Duration          `river:"timeout,attr,optional"`
+	Queue   otelcol.QueueArguments `river:"sending_queue,block,optional"`
+	Retry   otelcol.RetryArguments `river:"retry_on_failure,block,optional"`
+}
+
+var (
+	_ river.Unmarshaler  = (*Arguments)(nil)
+	_ exporter.Arguments = Arguments{}
+)
+
+// DefaultArguments holds default values for Arguments.
+var DefaultArguments = Arguments{
+	API:          DefaultAPIArguments,
+	HostMetadata: DefaultHostMetadataArguments,
+	Metrics:      DefaultMetricsArguments,
+
+	Timeout: 15 * time.Second,
+	Queue:   otelcol.DefaultQueueArguments,
+	Retry:   otelcol.DefaultRetryArguments,
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+	return args.Validate()
+}
+
+// Validate returns an error if the configuration is invalid.
+func (args *Arguments) Validate() error {
+	cfg, err := args.convert()
+	if err != nil {
+		return err
+	}
+	return cfg.Validate()
+}
+
+// Convert implements exporter.Arguments.
+func (args Arguments) Convert() (otelconfig.Exporter, error) {
+	return args.convert()
+}
+
+func (args Arguments) convert() (*datadogexporter.Config, error) {
+	var (
+		hostnameSource datadogexporter.HostnameSource
+		histogramMode  datadogexporter.HistogramMode
+		sumMode        datadogexporter.CumulativeMonotonicSumMode
+		summaryMode    datadogexporter.SummaryMode
+	)
+	if err := hostnameSource.UnmarshalText([]byte(args.HostMetadata.HostnameSource)); err != nil {
+		return nil, err
+	}
+	if err := histogramMode.UnmarshalText([]byte(args.Metrics.HistogramMode)); err != nil {
+		return nil, err
+	}
+	if err := sumMode.UnmarshalText([]byte(args.Metrics.CumulativeMonotonicMode)); err != nil {
+		return nil, err
+	}
+	if err := summaryMode.UnmarshalText([]byte(args.Metrics.SummaryMode)); err != nil {
+		return nil, err
+	}
+
+	site := args.API.Site
+	if site == "" {
+		site = datadogexporter.DefaultSite
+	}
+
+	ignoreResources := args.Traces.IgnoreResources
+	if ignoreResources == nil {
+		ignoreResources = []string{}
+	}
+
+	return &datadogexporter.Config{
+		ExporterSettings: otelconfig.NewExporterSettings(otelconfig.NewComponentID("datadog")),
+		TimeoutSettings:  otelexporterhelper.TimeoutSettings{Timeout: args.Timeout},
+		QueueSettings:    *args.Queue.Convert(),
+		RetrySettings:    *args.Retry.Convert(),
+
+		TagsConfig: datadogexporter.TagsConfig{Hostname: args.Hostname},
+
+		API: datadogexporter.APIConfig{
+			Key:              string(args.API.Key),
+			Site:             site,
+			FailOnInvalidKey: args.API.FailOnInvalidKey,
+		},
+
+		Metrics: datadogexporter.MetricsConfig{
+			TCPAddr:  confignet.TCPAddr{Endpoint: endpointOrDefault(args.Metrics.Endpoint, "https://api.%s", site)},
+			DeltaTTL: args.Metrics.DeltaTTL,
+			ExporterConfig: datadogexporter.MetricsExporterConfig{
+				ResourceAttributesAsTags:           args.Metrics.ResourceAttributesAsTags,
+				InstrumentationScopeMetadataAsTags: args.Metrics.InstrumentationScopeMetadataAsTags,
+			},
+			HistConfig: datadogexporter.HistogramConfig{
+				Mode:         histogramMode,
+				SendCountSum: args.Metrics.SendCountSum,
+			},
+			SumConfig:     datadogexporter.SumConfig{CumulativeMonotonicMode: sumMode},
+			SummaryConfig: datadogexporter.SummaryConfig{Mode: summaryMode},
+		},
+
+		Traces: datadogexporter.TracesConfig{
+			TCPAddr:                confignet.TCPAddr{Endpoint: endpointOrDefault(args.Traces.Endpoint, "https://trace.agent.%s", site)},
+			IgnoreResources:        ignoreResources,
+			SpanNameRemappings:     args.Traces.SpanNameRemappings,
+			SpanNameAsResourceName: args.Traces.SpanNameAsResourceName,
+		},
+
+		Logs: datadogexporter.LogsConfig{
+			TCPAddr: confignet.TCPAddr{Endpoint: endpointOrDefault("", "https://http-intake.logs.%s", site)},
+		},
+
+		HostMetadata: datadogexporter.HostMetadataConfig{
+			Enabled:        args.HostMetadata.Enabled,
+			HostnameSource: hostnameSource,
+			Tags:           args.HostMetadata.Tags,
+		},
+	}, nil
+}
+
+// endpointOrDefault returns endpoint if it's set. Otherwise, the default
+// endpoint for site is built from format.
+func endpointOrDefault(endpoint, format, site string) string {
+	if endpoint != "" {
+		return endpoint
+	}
+	return fmt.Sprintf(format, site)
+}
+
+// Extensions implements exporter.Arguments.
+func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return args.Queue.Extensions()
+}
+
+// Exporters implements exporter.Arguments.
+func (args Arguments) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
+
+// APIArguments configures access to the Datadog API.
+type APIArguments struct {
+	Key              rivertypes.Secret `river:"api_key,attr"`
+	Site             string            `river:"site,attr,optional"`
+	FailOnInvalidKey bool              `river:"fail_on_invalid_key,attr,optional"`
+}
+
+var _ river.Unmarshaler = (*APIArguments)(nil)
+
+// DefaultAPIArguments holds default values for APIArguments.
+var DefaultAPIArguments = APIArguments{
+	Site: datadogexporter.DefaultSite,
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *APIArguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultAPIArguments
+	type arguments APIArguments
+	return f((*arguments)(args))
+}
+
+// HostMetadataArguments configures the host metadata sent to Datadog, which
+// populates the infrastructure list and host map.
+type HostMetadataArguments struct {
+	Enabled        bool     `river:"enabled,attr,optional"`
+	HostnameSource string   `river:"hostname_source,attr,optional"`
+	Tags           []string `river:"tags,attr,optional"`
+}
+
+var _ river.Unmarshaler = (*HostMetadataArguments)(nil)
+
+// DefaultHostMetadataArguments holds default values for
+// HostMetadataArguments.
+var DefaultHostMetadataArguments = HostMetadataArguments{
+	Enabled:        true,
+	HostnameSource: string(datadogexporter.HostnameSourceConfigOrSystem),
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *HostMetadataArguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultHostMetadataArguments
+	type arguments HostMetadataArguments
+	return f((*arguments)(args))
+}
+
+// MetricsArguments configures how metrics are sent to Datadog.
+type MetricsArguments struct {
+	Endpoint                           string `river:"endpoint,attr,optional"`
+	DeltaTTL                           int64  `river:"delta_ttl,attr,optional"`
+	ResourceAttributesAsTags           bool   `river:"resource_attributes_as_tags,attr,optional"`
+	InstrumentationScopeMetadataAsTags bool   `river:"instrumentation_scope_metadata_as_tags,attr,optional"`
+	HistogramMode                      string `river:"histogram_mode,attr,optional"`
+	SendCountSum                       bool   `river:"send_count_sum_metrics,attr,optional"`
+	CumulativeMonotonicMode            string `river:"cumulative_monotonic_mode,attr,optional"`
+	SummaryMode                        string `river:"summary_mode,attr,optional"`
+}
+
+var _ river.Unmarshaler = (*MetricsArguments)(nil)
+
+// DefaultMetricsArguments holds default values for MetricsArguments.
+var DefaultMetricsArguments = MetricsArguments{
+	DeltaTTL:                3600,
+	HistogramMode:           string(datadogexporter.HistogramModeDistributions),
+	CumulativeMonotonicMode: string(datadogexporter.CumulativeMonotonicSumModeToDelta),
+	SummaryMode:             string(datadogexporter.SummaryModeGauges),
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *MetricsArguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultMetricsArguments
+	type arguments MetricsArguments
+	return f((*arguments)(args))
+}
+
+// TracesArguments configures how traces are sent to Datadog.
+type TracesArguments struct {
+	Endpoint               string            `river:"endpoint,attr,optional"`
+	IgnoreResources        []string          `river:"ignore_resources,attr,optional"`
+	SpanNameRemappings     map[string]string `river:"span_name_remappings,attr,optional"`
+	SpanNameAsResourceName bool              `river:"span_name_as_resource_name,attr,optional"`
+}
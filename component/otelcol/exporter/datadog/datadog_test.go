@@ -0,0 +1,106 @@
+package datadog_test
+
+import (
+	"testing"
+
+	"github.com/grafana/agent/component/otelcol/exporter/datadog"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/datadogexporter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		in := `
+			api {
+				api_key = "abc"
+				site    = "datadoghq.eu"
+			}
+		`
+		var args datadog.Arguments
+		require.NoError(t, river.Unmarshal([]byte(in), &args))
+
+		c, err := args.Convert()
+		require.NoError(t, err)
+		cfg := c.(*datadogexporter.Config)
+
+		require.Equal(t, "abc", cfg.API.Key)
+		require.Equal(t, "https://api.datadoghq.eu", cfg.Metrics.Endpoint)
+		require.Equal(t, "https://trace.agent.datadoghq.eu", cfg.Traces.Endpoint)
+		require.Equal(t, "https://http-intake.logs.datadoghq.eu", cfg.Logs.Endpoint)
+		require.True(t, cfg.HostMetadata.Enabled)
+		require.Equal(t, datadogexporter.HostnameSourceConfigOrSystem, cfg.HostMetadata.HostnameSource)
+		require.Equal(t, datadogexporter.HistogramModeDistributions, cfg.Metrics.HistConfig.Mode)
+		require.Equal(t, int64(3600), cfg.Metrics.DeltaTTL)
+	})
+
+	t.Run("custom settings", func(t *testing.T) {
+		in := `
+			api {
+				api_key = "abc"
+			}
+			hostname = "agent-1"
+
+			host_metadata {
+				hostname_source = "first_resource"
+				tags            = ["env:prod"]
+			}
+
+			metrics {
+				endpoint       = "https://metrics.example.com"
+				histogram_mode = "counters"
+			}
+
+			traces {
+				ignore_resources = ["GET /health"]
+			}
+		`
+		var args datadog.Arguments
+		require.NoError(t, river.Unmarshal([]byte(in), &args))
+
+		c, err := args.Convert()
+		require.NoError(t, err)
+		cfg := c.(*datadogexporter.Config)
+
+		require.Equal(t, "agent-1", cfg.Hostname)
+		require.True(t, cfg.HostMetadata.Enabled)
+		require.Equal(t, datadogexporter.HostnameSourceFirstResource, cfg.HostMetadata.HostnameSource)
+		require.Equal(t, []string{"env:prod"}, cfg.HostMetadata.Tags)
+		require.Equal(t, "https://metrics.example.com", cfg.Metrics.Endpoint)
+		require.Equal(t, datadogexporter.HistogramModeCounters, cfg.Metrics.HistConfig.Mode)
+		require.Equal(t, "https://trace.agent.datadoghq.com", cfg.Traces.Endpoint)
+		require.Equal(t, []string{"GET /health"}, cfg.Traces.IgnoreResources)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tt := map[string]string{
+			"missing api key": `
+				api {
+					api_key = ""
+				}
+			`,
+			"invalid hostname source": `
+				api {
+					api_key = "abc"
+				}
+				host_metadata {
+					hostname_source = "somewhere"
+				}
+			`,
+			"invalid histogram mode": `
+				api {
+					api_key = "abc"
+				}
+				metrics {
+					histogram_mode = "buckets"
+				}
+			`,
+		}
+		for name, in := range tt {
+			t.Run(name, func(t *testing.T) {
+				var args datadog.Arguments
+				require.Error(t, river.Unmarshal([]byte(in), &args))
+			})
+		}
+	})
+}
@@ -78,7 +78,11 @@ func (args Arguments) Convert() (otelconfig.Exporter, error) {
 
 // Extensions implements exporter.Arguments.
 func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
-	return (*otelcol.HTTPClientArguments)(&args.Client).Extensions()
+	m := (*otelcol.HTTPClientArguments)(&args.Client).Extensions()
+	for id, ext := range args.Queue.Extensions() {
+		m[id] = ext
+	}
+	return m
 }
 
 // Exporters implements exporter.Arguments.
@@ -58,6 +58,17 @@ type Options struct {
 	// IncludeScopeInfo includes the otel_scope_info metric and adds
 	// otel_scope_name and otel_scope_version labels to data points.
 	IncludeScopeInfo bool
+	// PromoteResourceAttributes lists resource attributes which are added as
+	// labels to every data point of the resource.
+	PromoteResourceAttributes []string
+	// TargetInfoAttributes limits the resource attributes added as labels to
+	// the target_info metric. All resource attributes are added when empty.
+	TargetInfoAttributes []string
+	// Namespace is prefixed to the name of every converted metric.
+	Namespace string
+	// AddMetricSuffixes appends unit and type suffixes to the names of
+	// converted metrics, such as _seconds or _total.
+	AddMetricSuffixes bool
 }
 
 var _ consumer.Metrics = (*Converter)(nil)
@@ -159,6 +170,7 @@ func (conv *Converter) getOrCreateResource(res pcommon.Resource) *memorySeries {
 	targetInfoLabels := labels.FromStrings(model.MetricNameLabel, "target_info")
 
 	var (
+		opts  = conv.getOpts()
 		attrs = res.Attributes().Sort()
 
 		jobLabel      string
@@ -190,18 +202,43 @@ func (conv *Converter) getOrCreateResource(res pcommon.Resource) *memorySeries {
 
 			return true
 		}
+		if len(opts.TargetInfoAttributes) > 0 && !contains(opts.TargetInfoAttributes, k) {
+			return true
+		}
 
 		lb.Set(prometheus.NormalizeLabel(k), v.AsString())
 		return true
 	})
 
-	labels := lb.Labels(nil)
-
-	entry := newMemorySeries(map[string]string{
+	// The metadata of the resource holds the labels which are added to every
+	// data point of the resource.
+	seriesLabels := map[string]string{
 		model.JobLabel:      jobLabel,
 		model.InstanceLabel: instanceLabel,
-	}, labels)
-	if actual, loaded := conv.seriesCache.LoadOrStore(labels.String(), entry); loaded {
+	}
+	promoted := labels.NewBuilder(nil)
+	for _, k := range opts.PromoteResourceAttributes {
+		v, ok := attrs.Get(k)
+		if !ok {
+			continue
+		}
+		name := prometheus.NormalizeLabel(k)
+		if _, exists := seriesLabels[name]; exists {
+			// Never override job or instance.
+			continue
+		}
+		seriesLabels[name] = v.AsString()
+		promoted.Set(name, v.AsString())
+	}
+
+	labels := lb.Labels(nil)
+
+	// Promoted attributes may be excluded from target_info, so they must be
+	// part of the cache key to keep resources unique.
+	key := labels.String() + promoted.Labels(nil).String()
+
+	entry := newMemorySeries(seriesLabels, labels)
+	if actual, loaded := conv.seriesCache.LoadOrStore(key, entry); loaded {
 		entry = actual.(*memorySeries)
 	}
 
@@ -277,7 +314,7 @@ func (conv *Converter) consumeMetric(app storage.Appender, memResource *memorySe
 }
 
 func (conv *Converter) consumeGauge(app storage.Appender, memResource *memorySeries, memScope *memorySeries, m pmetric.Metric) {
-	metricName := prometheus.BuildPromCompliantName(m, "")
+	metricName := conv.metricName(m)
 
 	metricMD := conv.createOrUpdateMetadata(metricName, metadata.Metadata{
 		Type: textparse.MetricTypeGauge,
@@ -323,13 +360,10 @@ func writeSeries(app storage.Appender, series *memorySeries, dp otelcolDataPoint
 // resource, scope, metric, and attributes. The LastSeen field of the
 // *memorySeries is updated before returning.
 func (conv *Converter) getOrCreateSeries(res *memorySeries, scope *memorySeries, name string, attrs pcommon.Map, extraLabels ...labels.Label) *memorySeries {
-	seriesBaseLabels := labels.FromStrings(
-		model.MetricNameLabel, name,
-		model.JobLabel, res.metadata[model.JobLabel],
-		model.InstanceLabel, res.metadata[model.InstanceLabel],
-	)
-
-	lb := labels.NewBuilder(seriesBaseLabels)
+	lb := labels.NewBuilder(labels.FromStrings(model.MetricNameLabel, name))
+	for k, v := range res.metadata {
+		lb.Set(k, v)
+	}
 	for _, extraLabel := range extraLabels {
 		lb.Set(extraLabel.Name, extraLabel.Value)
 	}
@@ -367,7 +401,7 @@ func getNumberDataPointValue(dp pmetric.NumberDataPoint) float64 {
 }
 
 func (conv *Converter) consumeSum(app storage.Appender, memResource *memorySeries, memScope *memorySeries, m pmetric.Metric) {
-	metricName := prometheus.BuildPromCompliantName(m, "")
+	metricName := conv.metricName(m)
 
 	// Excerpt from the spec:
 	//
@@ -417,7 +451,7 @@ func (conv *Converter) consumeSum(app storage.Appender, memResource *memorySerie
 }
 
 func (conv *Converter) consumeHistogram(app storage.Appender, memResource *memorySeries, memScope *memorySeries, m pmetric.Metric) {
-	metricName := prometheus.BuildPromCompliantName(m, "")
+	metricName := conv.metricName(m)
 
 	if m.Histogram().AggregationTemporality() != pmetric.AggregationTemporalityCumulative {
 		// Drop non-cumulative histograms for now, which is permitted by the spec.
@@ -496,7 +530,7 @@ func (conv *Converter) consumeHistogram(app storage.Appender, memResource *memor
 }
 
 func (conv *Converter) consumeSummary(app storage.Appender, memResource *memorySeries, memScope *memorySeries, m pmetric.Metric) {
-	metricName := prometheus.BuildPromCompliantName(m, "")
+	metricName := conv.metricName(m)
 
 	metricMD := conv.createOrUpdateMetadata(metricName, metadata.Metadata{
 		Type: textparse.MetricTypeSummary,
@@ -18,9 +18,13 @@ func TestConverter(t *testing.T) {
 		input  string
 		expect string
 
-		showTimestamps    bool
-		includeTargetInfo bool
-		includeScopeInfo  bool
+		showTimestamps            bool
+		includeTargetInfo         bool
+		includeScopeInfo          bool
+		promoteResourceAttributes []string
+		targetInfoAttributes      []string
+		namespace                 string
+		addMetricSuffixes         bool
 	}{
 		{
 			name: "Gauge",
@@ -316,6 +320,146 @@ func TestConverter(t *testing.T) {
 				test_metric_seconds{instance="instance",job="myservice"} 1234.56
 			`,
 		},
+		{
+			name: "Promoted resource attributes",
+			input: `{
+				"resource_metrics": [{
+					"resource": {
+						"attributes": [{
+							"key": "service.name",
+							"value": { "stringValue": "myservice" }
+						}, {
+							"key": "k8s.pod.name",
+							"value": { "stringValue": "mypod" }
+						}, {
+							"key": "do_not_display",
+							"value": { "stringValue": "test" }
+						}]
+					},
+					"scope_metrics": [{
+						"metrics": [{
+							"name": "test_metric_seconds",
+							"gauge": {
+								"data_points": [{
+									"as_double": 1234.56
+								}]
+							}
+						}]
+					}]
+				}]
+			}`,
+			promoteResourceAttributes: []string{"k8s.pod.name", "missing"},
+			expect: `
+				# TYPE test_metric_seconds gauge
+				test_metric_seconds{k8s_pod_name="mypod",job="myservice"} 1234.56
+			`,
+		},
+		{
+			name: "Target info metric with selected attributes",
+			input: `{
+				"resource_metrics": [{
+					"resource": {
+						"attributes": [{
+							"key": "service.name",
+							"value": { "stringValue": "myservice" }
+						}, {
+							"key": "custom_attr",
+							"value": { "stringValue": "test" }
+						}, {
+							"key": "other_attr",
+							"value": { "stringValue": "test" }
+						}]
+					},
+					"scope_metrics": [{
+						"metrics": [{
+							"name": "test_metric_seconds",
+							"gauge": {
+								"data_points": [{
+									"as_double": 1234.56
+								}]
+							}
+						}]
+					}]
+				}]
+			}`,
+			includeTargetInfo:    true,
+			targetInfoAttributes: []string{"custom_attr"},
+			expect: `
+				# HELP target_info Target metadata
+				# TYPE target_info gauge
+				target_info{job="myservice",custom_attr="test"} 1.0
+				# TYPE test_metric_seconds gauge
+				test_metric_seconds{job="myservice"} 1234.56
+			`,
+		},
+		{
+			name: "Namespace",
+			input: `{
+				"resource_metrics": [{
+					"scope_metrics": [{
+						"metrics": [{
+							"name": "test_metric_seconds",
+							"gauge": {
+								"data_points": [{
+									"as_double": 1234.56
+								}]
+							}
+						}]
+					}]
+				}]
+			}`,
+			namespace: "otel",
+			expect: `
+				# TYPE otel_test_metric_seconds gauge
+				otel_test_metric_seconds 1234.56
+			`,
+		},
+		{
+			name: "Metric suffixes",
+			input: `{
+				"resource_metrics": [{
+					"scope_metrics": [{
+						"metrics": [{
+							"name": "http.server.duration",
+							"unit": "ms",
+							"gauge": {
+								"data_points": [{
+									"as_double": 1234.56
+								}]
+							}
+						}, {
+							"name": "http.server.requests",
+							"unit": "{requests}",
+							"sum": {
+								"aggregation_temporality": 2,
+								"is_monotonic": true,
+								"data_points": [{
+									"as_double": 15
+								}]
+							}
+						}, {
+							"name": "network.io",
+							"unit": "By/s",
+							"gauge": {
+								"data_points": [{
+									"as_double": 10
+								}]
+							}
+						}]
+					}]
+				}]
+			}`,
+			namespace:         "otel",
+			addMetricSuffixes: true,
+			expect: `
+				# TYPE otel_http_server_duration_milliseconds gauge
+				otel_http_server_duration_milliseconds 1234.56
+				# TYPE otel_http_server_requests counter
+				otel_http_server_requests_total 15.0
+				# TYPE otel_network_io_bytes_per_second gauge
+				otel_network_io_bytes_per_second 10.0
+			`,
+		},
 	}
 
 	decoder := &pmetric.JSONUnmarshaler{}
@@ -329,8 +473,12 @@ func TestConverter(t *testing.T) {
 
 			l := util.TestLogger(t)
 			conv := convert.New(l, appenderAppendable{Inner: &app}, convert.Options{
-				IncludeTargetInfo: tc.includeTargetInfo,
-				IncludeScopeInfo:  tc.includeScopeInfo,
+				IncludeTargetInfo:         tc.includeTargetInfo,
+				IncludeScopeInfo:          tc.includeScopeInfo,
+				PromoteResourceAttributes: tc.promoteResourceAttributes,
+				TargetInfoAttributes:      tc.targetInfoAttributes,
+				Namespace:                 tc.namespace,
+				AddMetricSuffixes:         tc.addMetricSuffixes,
 			})
 			require.NoError(t, conv.ConsumeMetrics(context.Background(), payload))
 
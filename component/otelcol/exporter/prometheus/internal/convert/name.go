@@ -0,0 +1,143 @@
+package convert
+
+import (
+	"strings"
+	"unicode"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/prometheus"
+)
+
+// unitSuffixes maps OpenTelemetry units to Prometheus metric name suffixes.
+// It matches the unit mapping used by the OpenTelemetry Collector's Prometheus
+// translator.
+var unitSuffixes = map[string]string{
+	// Time
+	"d":   "days",
+	"h":   "hours",
+	"min": "minutes",
+	"s":   "seconds",
+	"ms":  "milliseconds",
+	"us":  "microseconds",
+	"ns":  "nanoseconds",
+
+	// Bytes
+	"By":   "bytes",
+	"KiBy": "kibibytes",
+	"MiBy": "mebibytes",
+	"GiBy": "gibibytes",
+	"TiBy": "tibibytes",
+	"KBy":  "kilobytes",
+	"MBy":  "megabytes",
+	"GBy":  "gigabytes",
+	"TBy":  "terabytes",
+	"B":    "bytes",
+	"KB":   "kilobytes",
+	"MB":   "megabytes",
+	"GB":   "gigabytes",
+	"TB":   "terabytes",
+
+	// SI
+	"m": "meters",
+	"V": "volts",
+	"A": "amperes",
+	"J": "joules",
+	"W": "watts",
+	"g": "grams",
+
+	// Misc
+	"Cel": "celsius",
+	"Hz":  "hertz",
+	"1":   "",
+	"%":   "percent",
+	"$":   "dollars",
+}
+
+// perUnitSuffixes maps OpenTelemetry units used as a denominator (such as the
+// "s" in "By/s") to Prometheus metric name suffixes.
+var perUnitSuffixes = map[string]string{
+	"s":  "second",
+	"m":  "minute",
+	"h":  "hour",
+	"d":  "day",
+	"w":  "week",
+	"mo": "month",
+	"y":  "year",
+}
+
+// metricName returns the Prometheus metric name to use for m.
+func (conv *Converter) metricName(m pmetric.Metric) string {
+	opts := conv.getOpts()
+	if !opts.AddMetricSuffixes {
+		return prometheus.BuildPromCompliantName(m, opts.Namespace)
+	}
+	return suffixedMetricName(m, opts.Namespace)
+}
+
+// suffixedMetricName builds a Prometheus metric name for m following the
+// Prometheus naming conventions, appending the unit of m and a _total suffix
+// for counters.
+func suffixedMetricName(m pmetric.Metric, namespace string) string {
+	tokens := strings.FieldsFunc(m.Name(), isNotAlphanumeric)
+
+	unit, perUnit, _ := strings.Cut(m.Unit(), "/")
+	if suffix := unitSuffix(unit, unitSuffixes); suffix != "" && !contains(tokens, suffix) {
+		tokens = append(tokens, suffix)
+	}
+	if suffix := unitSuffix(perUnit, perUnitSuffixes); suffix != "" && !contains(tokens, suffix) {
+		tokens = append(tokens, "per", suffix)
+	}
+
+	switch {
+	case m.Type() == pmetric.MetricTypeSum && m.Sum().IsMonotonic():
+		tokens = append(removeToken(tokens, "total"), "total")
+	case m.Type() == pmetric.MetricTypeGauge && m.Unit() == "1":
+		tokens = append(removeToken(tokens, "ratio"), "ratio")
+	}
+
+	if namespace != "" {
+		tokens = append([]string{namespace}, tokens...)
+	}
+
+	name := strings.Join(tokens, "_")
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	return name
+}
+
+// unitSuffix returns the metric name suffix for unit. Annotations such as
+// "{requests}" don't produce a suffix. Units which aren't found in known are
+// used as-is after removing invalid characters.
+func unitSuffix(unit string, known map[string]string) string {
+	unit = strings.TrimSpace(unit)
+	if unit == "" || strings.ContainsAny(unit, "{}") {
+		return ""
+	}
+	if suffix, ok := known[unit]; ok {
+		return suffix
+	}
+	return strings.Join(strings.FieldsFunc(unit, isNotAlphanumeric), "_")
+}
+
+func isNotAlphanumeric(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeToken(tokens []string, token string) []string {
+	res := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		if t != token {
+			res = append(res, t)
+		}
+	}
+	return res
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/agent/component/otelcol/internal/lazyconsumer"
 	"github.com/grafana/agent/component/prometheus"
 	"github.com/grafana/agent/pkg/river"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/storage"
 )
 
@@ -31,10 +32,14 @@ func init() {
 
 // Arguments configures the otelcol.exporter.prometheus component.
 type Arguments struct {
-	IncludeTargetInfo bool                 `river:"include_target_info,attr,optional"`
-	IncludeScopeInfo  bool                 `river:"include_scope_info,attr,optional"`
-	GCFrequency       time.Duration        `river:"gc_frequency,attr,optional"`
-	ForwardTo         []storage.Appendable `river:"forward_to,attr"`
+	IncludeTargetInfo         bool                 `river:"include_target_info,attr,optional"`
+	IncludeScopeInfo          bool                 `river:"include_scope_info,attr,optional"`
+	TargetInfoAttributes      []string             `river:"target_info_attributes,attr,optional"`
+	PromoteResourceAttributes []string             `river:"promote_resource_attributes,attr,optional"`
+	Namespace                 string               `river:"namespace,attr,optional"`
+	AddMetricSuffixes         bool                 `river:"add_metric_suffixes,attr,optional"`
+	GCFrequency               time.Duration        `river:"gc_frequency,attr,optional"`
+	ForwardTo                 []storage.Appendable `river:"forward_to,attr"`
 }
 
 var _ river.Unmarshaler = (*Arguments)(nil)
@@ -58,10 +63,25 @@ func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
 	if args.GCFrequency == 0 {
 		return fmt.Errorf("gc_frequency must be greater than 0")
 	}
+	if args.Namespace != "" && !model.IsValidMetricName(model.LabelValue(args.Namespace)) {
+		return fmt.Errorf("namespace %q is not a valid metric name prefix", args.Namespace)
+	}
 
 	return nil
 }
 
+// convertOptions returns the convert.Options to use for args.
+func (args Arguments) convertOptions() convert.Options {
+	return convert.Options{
+		IncludeTargetInfo:         args.IncludeTargetInfo,
+		IncludeScopeInfo:          args.IncludeScopeInfo,
+		PromoteResourceAttributes: args.PromoteResourceAttributes,
+		TargetInfoAttributes:      args.TargetInfoAttributes,
+		Namespace:                 args.Namespace,
+		AddMetricSuffixes:         args.AddMetricSuffixes,
+	}
+}
+
 // Component is the otelcol.exporter.prometheus component.
 type Component struct {
 	log  log.Logger
@@ -136,10 +156,7 @@ func (c *Component) Update(newConfig component.Arguments) error {
 	c.cfg = cfg
 
 	c.fanout.UpdateChildren(cfg.ForwardTo)
-	c.converter.UpdateOptions(convert.Options{
-		IncludeTargetInfo: cfg.IncludeTargetInfo,
-		IncludeScopeInfo:  cfg.IncludeScopeInfo,
-	})
+	c.converter.UpdateOptions(cfg.convertOptions())
 
 	// If our forward_to argument changed, we need to flush the metadata cache to
 	// ensure the new children have all the metadata they need.
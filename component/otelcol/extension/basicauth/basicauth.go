@@ -0,0 +1,108 @@
+// Package basicauth provides an otelcol.extension.basicauth component.
+package basicauth
+
+import (
+	"fmt"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol/auth"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/basicauthextension"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "otelcol.extension.basicauth",
+		Args:    Arguments{},
+		Exports: auth.Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := basicauthextension.NewFactory()
+			return auth.New(opts, fact, args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.extension.basicauth component. Exactly one
+// of Htpasswd or ClientAuth must be set.
+type Arguments struct {
+	// Htpasswd authenticates incoming requests of receivers.
+	Htpasswd *HtpasswdArguments `river:"htpasswd,block,optional"`
+
+	// ClientAuth authenticates outgoing requests of exporters.
+	ClientAuth *ClientAuthArguments `river:"client_auth,block,optional"`
+}
+
+// HtpasswdArguments holds the credentials accepted from clients.
+type HtpasswdArguments struct {
+	File   string            `river:"file,attr,optional"`
+	Inline rivertypes.Secret `river:"inline,attr,optional"`
+}
+
+// ClientAuthArguments holds the credentials sent to servers.
+type ClientAuthArguments struct {
+	Username string            `river:"username,attr"`
+	Password rivertypes.Secret `river:"password,attr"`
+}
+
+var (
+	_ auth.Arguments    = Arguments{}
+	_ river.Unmarshaler = (*Arguments)(nil)
+)
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = Arguments{}
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+	return args.Validate()
+}
+
+// Validate returns an error if args is invalid.
+func (args *Arguments) Validate() error {
+	switch {
+	case args.Htpasswd != nil && args.ClientAuth != nil:
+		return fmt.Errorf("only one of htpasswd or client_auth may be specified")
+	case args.Htpasswd == nil && args.ClientAuth == nil:
+		return fmt.Errorf("one of htpasswd or client_auth must be specified")
+	case args.Htpasswd != nil && args.Htpasswd.File == "" && args.Htpasswd.Inline == "":
+		return fmt.Errorf("htpasswd requires file or inline to be set")
+	}
+	return nil
+}
+
+// Convert implements auth.Arguments.
+func (args Arguments) Convert() (otelconfig.Extension, error) {
+	cfg := &basicauthextension.Config{
+		ExtensionSettings: otelconfig.NewExtensionSettings(otelconfig.NewComponentID("basicauth")),
+	}
+	if args.Htpasswd != nil {
+		cfg.Htpasswd = &basicauthextension.HtpasswdSettings{
+			File:   args.Htpasswd.File,
+			Inline: string(args.Htpasswd.Inline),
+		}
+	}
+	if args.ClientAuth != nil {
+		cfg.ClientAuth = &basicauthextension.ClientAuthSettings{
+			Username: args.ClientAuth.Username,
+			Password: string(args.ClientAuth.Password),
+		}
+	}
+	return cfg, nil
+}
+
+// Extensions implements auth.Arguments.
+func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return nil
+}
+
+// Exporters implements auth.Arguments.
+func (args Arguments) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
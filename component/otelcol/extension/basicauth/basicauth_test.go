@@ -0,0 +1,117 @@
+package basicauth_test
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/otelcol/auth"
+	"github.com/grafana/agent/component/otelcol/extension/basicauth"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configauth"
+)
+
+// Test runs the otelcol.extension.basicauth component with htpasswd
+// credentials and ensures that it authenticates incoming requests.
+func Test(t *testing.T) {
+	ctx := componenttest.TestContext(t)
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	l := util.TestLogger(t)
+
+	ctrl, err := componenttest.NewControllerFromID(l, "otelcol.extension.basicauth")
+	require.NoError(t, err)
+
+	cfg := `
+		htpasswd {
+			inline = "foo:bar"
+		}
+	`
+	var args basicauth.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	go func() {
+		err := ctrl.Run(ctx, args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, ctrl.WaitRunning(time.Second), "component never started")
+	require.NoError(t, ctrl.WaitExports(time.Second), "component never exported anything")
+
+	exports := ctrl.Exports().(auth.Exports)
+	require.NotNil(t, exports.Handler.Extension, "handler extension is nil")
+
+	serverAuth, ok := exports.Handler.Extension.(configauth.ServerAuthenticator)
+	require.True(t, ok, "handler does not implement configauth.ServerAuthenticator")
+
+	authHeader := func(username, password string) map[string][]string {
+		creds := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		return map[string][]string{"Authorization": {"Basic " + creds}}
+	}
+
+	_, err = serverAuth.Authenticate(ctx, authHeader("foo", "bar"))
+	require.NoError(t, err)
+
+	_, err = serverAuth.Authenticate(ctx, authHeader("foo", "wrong"))
+	require.Error(t, err)
+}
+
+func TestArguments_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         string
+		expectError string
+	}{
+		{
+			name: "client_auth",
+			cfg: `
+				client_auth {
+					username = "foo"
+					password = "bar"
+				}
+			`,
+		},
+		{
+			name:        "none",
+			cfg:         ``,
+			expectError: "one of htpasswd or client_auth must be specified",
+		},
+		{
+			name: "both",
+			cfg: `
+				htpasswd {
+					file = "/etc/htpasswd"
+				}
+				client_auth {
+					username = "foo"
+					password = "bar"
+				}
+			`,
+			expectError: "only one of htpasswd or client_auth may be specified",
+		},
+		{
+			name: "empty htpasswd",
+			cfg: `
+				htpasswd {}
+			`,
+			expectError: "htpasswd requires file or inline to be set",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var args basicauth.Arguments
+			err := river.Unmarshal([]byte(tc.cfg), &args)
+			if tc.expectError != "" {
+				require.EqualError(t, err, tc.expectError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
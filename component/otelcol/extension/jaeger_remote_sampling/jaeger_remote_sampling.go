@@ -89,7 +89,11 @@ func (args Arguments) Convert() (otelconfig.Extension, error) {
 
 // Extensions implements extension.Arguments.
 func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
-	return nil
+	m := (*otelcol.GRPCServerArguments)(args.GRPC).Extensions()
+	for id, ext := range (*otelcol.HTTPServerArguments)(args.HTTP).Extensions() {
+		m[id] = ext
+	}
+	return m
 }
 
 // Exporters implements extension.Arguments.
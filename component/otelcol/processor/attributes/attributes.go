@@ -0,0 +1,240 @@
+// Package attributes provides an otelcol.processor.attributes component.
+package attributes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/processor"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/attributesprocessor"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "otelcol.processor.attributes",
+		Args:    Arguments{},
+		Exports: otelcol.ConsumerExports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := attributesprocessor.NewFactory()
+			return processor.New(opts, fact, args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.processor.attributes component.
+type Arguments struct {
+	// Signals restricts the telemetry signals the processor applies to.
+	// Telemetry of other signals is rejected by the component.
+	Signals []string `river:"signals,attr,optional"`
+
+	Actions []Action     `river:"action,block"`
+	Include *MatchConfig `river:"include,block,optional"`
+	Exclude *MatchConfig `river:"exclude,block,optional"`
+
+	// Output configures where to send processed data. Required.
+	Output *otelcol.ConsumerArguments `river:"output,block"`
+}
+
+var (
+	_ processor.SignalArguments = Arguments{}
+	_ river.Unmarshaler         = (*Arguments)(nil)
+)
+
+// DefaultArguments holds default settings for Arguments.
+var DefaultArguments = Arguments{
+	Signals: []string{
+		string(otelconfig.TracesDataType),
+		string(otelconfig.MetricsDataType),
+		string(otelconfig.LogsDataType),
+	},
+}
+
+// UnmarshalRiver implements river.Unmarshaler. It applies defaults to args and
+// validates settings provided by the user.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if len(args.Signals) == 0 {
+		return fmt.Errorf("signals must not be empty")
+	}
+	seen := make(map[string]struct{}, len(args.Signals))
+	for _, signal := range args.Signals {
+		switch otelconfig.DataType(signal) {
+		case otelconfig.TracesDataType, otelconfig.MetricsDataType, otelconfig.LogsDataType:
+		default:
+			return fmt.Errorf("unrecognized signal %q, expected one of traces, metrics, or logs", signal)
+		}
+		if _, ok := seen[signal]; ok {
+			return fmt.Errorf("signal %q specified more than once", signal)
+		}
+		seen[signal] = struct{}{}
+	}
+
+	if len(args.Actions) == 0 {
+		return fmt.Errorf("at least one action block must be provided")
+	}
+	return nil
+}
+
+// Action is an individual action to perform on attributes.
+type Action struct {
+	Key           string      `river:"key,attr"`
+	Action        string      `river:"action,attr"`
+	Value         interface{} `river:"value,attr,optional"`
+	Pattern       string      `river:"pattern,attr,optional"`
+	FromAttribute string      `river:"from_attribute,attr,optional"`
+	FromContext   string      `river:"from_context,attr,optional"`
+	ConvertedType string      `river:"converted_type,attr,optional"`
+}
+
+var validActions = []string{"insert", "update", "upsert", "delete", "hash", "extract", "convert"}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (a *Action) UnmarshalRiver(f func(interface{}) error) error {
+	type action Action
+	if err := f((*action)(a)); err != nil {
+		return err
+	}
+
+	for _, valid := range validActions {
+		if strings.EqualFold(a.Action, valid) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unrecognized action %q, expected one of %s", a.Action, strings.Join(validActions, ", "))
+}
+
+func (a Action) convert() map[string]interface{} {
+	return map[string]interface{}{
+		"key":            a.Key,
+		"action":         strings.ToLower(a.Action),
+		"value":          a.Value,
+		"pattern":        a.Pattern,
+		"from_attribute": a.FromAttribute,
+		"from_context":   a.FromContext,
+		"converted_type": a.ConvertedType,
+	}
+}
+
+// MatchConfig selects the telemetry an attributes processor applies to.
+type MatchConfig struct {
+	MatchType        string      `river:"match_type,attr"`
+	Services         []string    `river:"services,attr,optional"`
+	SpanNames        []string    `river:"span_names,attr,optional"`
+	SpanKinds        []string    `river:"span_kinds,attr,optional"`
+	LogBodies        []string    `river:"log_bodies,attr,optional"`
+	LogSeverityTexts []string    `river:"log_severity_texts,attr,optional"`
+	MetricNames      []string    `river:"metric_names,attr,optional"`
+	Attributes       []Attribute `river:"attribute,block,optional"`
+	Resources        []Attribute `river:"resource,block,optional"`
+	Libraries        []Library   `river:"library,block,optional"`
+}
+
+// Attribute is a key/value pair to match against.
+type Attribute struct {
+	Key   string      `river:"key,attr"`
+	Value interface{} `river:"value,attr,optional"`
+}
+
+// Library is an instrumentation library to match against.
+type Library struct {
+	Name    string  `river:"name,attr"`
+	Version *string `river:"version,attr,optional"`
+}
+
+func (mc *MatchConfig) convert() map[string]interface{} {
+	if mc == nil {
+		return nil
+	}
+
+	attrs := func(in []Attribute) []interface{} {
+		res := make([]interface{}, 0, len(in))
+		for _, a := range in {
+			res = append(res, map[string]interface{}{"key": a.Key, "value": a.Value})
+		}
+		return res
+	}
+
+	libraries := make([]interface{}, 0, len(mc.Libraries))
+	for _, l := range mc.Libraries {
+		lib := map[string]interface{}{"name": l.Name}
+		if l.Version != nil {
+			lib["version"] = *l.Version
+		}
+		libraries = append(libraries, lib)
+	}
+
+	return map[string]interface{}{
+		"match_type":         mc.MatchType,
+		"services":           mc.Services,
+		"span_names":         mc.SpanNames,
+		"span_kinds":         mc.SpanKinds,
+		"log_bodies":         mc.LogBodies,
+		"log_severity_texts": mc.LogSeverityTexts,
+		"metric_names":       mc.MetricNames,
+		"attributes":         attrs(mc.Attributes),
+		"resources":          attrs(mc.Resources),
+		"libraries":          libraries,
+	}
+}
+
+// Convert implements processor.Arguments.
+func (args Arguments) Convert() (otelconfig.Processor, error) {
+	actions := make([]interface{}, 0, len(args.Actions))
+	for _, a := range args.Actions {
+		actions = append(actions, a.convert())
+	}
+
+	input := map[string]interface{}{"actions": actions}
+	if args.Include != nil {
+		input["include"] = args.Include.convert()
+	}
+	if args.Exclude != nil {
+		input["exclude"] = args.Exclude.convert()
+	}
+
+	// The attributes processor configuration is built from internal packages
+	// of the collector, so it is decoded from a map rather than constructed
+	// directly.
+	cfg := attributesprocessor.NewFactory().CreateDefaultConfig()
+	if err := confmap.NewFromStringMap(input).Unmarshal(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Extensions implements processor.Arguments.
+func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return nil
+}
+
+// Exporters implements processor.Arguments.
+func (args Arguments) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
+
+// NextConsumers implements processor.Arguments.
+func (args Arguments) NextConsumers() *otelcol.ConsumerArguments {
+	return args.Output
+}
+
+// EnabledSignals implements processor.SignalArguments.
+func (args Arguments) EnabledSignals() []otelconfig.DataType {
+	res := make([]otelconfig.DataType, 0, len(args.Signals))
+	for _, signal := range args.Signals {
+		res = append(res, otelconfig.DataType(signal))
+	}
+	return res
+}
@@ -0,0 +1,216 @@
+package attributes_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/internal/fakeconsumer"
+	"github.com/grafana/agent/component/otelcol/processor/attributes"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/grafana/dskit/backoff"
+	"github.com/stretchr/testify/require"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// Test performs a basic integration test which runs the
+// otelcol.processor.attributes component and ensures that it can accept,
+// process, and forward data.
+func Test(t *testing.T) {
+	ctx := componenttest.TestContext(t)
+	l := util.TestLogger(t)
+
+	ctrl, err := componenttest.NewControllerFromID(l, "otelcol.processor.attributes")
+	require.NoError(t, err)
+
+	cfg := `
+		signals = ["traces"]
+
+		action {
+			key    = "env"
+			value  = "prod"
+			action = "insert"
+		}
+
+		output {
+			// no-op: will be overridden by test code.
+		}
+	`
+	var args attributes.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	// Override our arguments so traces get forwarded to traceCh.
+	traceCh := make(chan ptrace.Traces)
+	args.Output = makeTracesOutput(traceCh)
+
+	go func() {
+		err := ctrl.Run(ctx, args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, ctrl.WaitRunning(time.Second), "component never started")
+	require.NoError(t, ctrl.WaitExports(time.Second), "component never exported anything")
+
+	exports := ctrl.Exports().(otelcol.ConsumerExports)
+
+	// Metrics aren't enabled and must be rejected.
+	err = exports.Input.ConsumeMetrics(ctx, pmetric.NewMetrics())
+	require.ErrorIs(t, err, otelcomponent.ErrDataTypeIsNotSupported)
+
+	// Send traces in the background to our processor.
+	go func() {
+		bo := backoff.New(ctx, backoff.Config{
+			MinBackoff: 10 * time.Millisecond,
+			MaxBackoff: 100 * time.Millisecond,
+		})
+		for bo.Ongoing() {
+			err := exports.Input.ConsumeTraces(ctx, createTestTraces())
+			if err != nil {
+				level.Error(l).Log("msg", "failed to send traces", "err", err)
+				bo.Wait()
+				continue
+			}
+
+			return
+		}
+	}()
+
+	// Wait for our processor to finish and forward data to traceCh.
+	select {
+	case <-time.After(time.Second):
+		require.FailNow(t, "failed waiting for traces")
+	case tr := <-traceCh:
+		require.Equal(t, 1, tr.SpanCount())
+
+		span := tr.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+		val, ok := span.Attributes().Get("env")
+		require.True(t, ok, "attribute was not inserted")
+		require.Equal(t, "prod", val.Str())
+	}
+}
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	tt := []struct {
+		name   string
+		cfg    string
+		expect string
+	}{
+		{
+			name: "unknown signal",
+			cfg: `
+				signals = ["spans"]
+				action {
+					key    = "env"
+					action = "delete"
+				}
+				output {}
+			`,
+			expect: `unrecognized signal "spans"`,
+		},
+		{
+			name: "duplicate signal",
+			cfg: `
+				signals = ["logs", "logs"]
+				action {
+					key    = "env"
+					action = "delete"
+				}
+				output {}
+			`,
+			expect: `signal "logs" specified more than once`,
+		},
+		{
+			name: "unknown action",
+			cfg: `
+				action {
+					key    = "env"
+					action = "rename"
+				}
+				output {}
+			`,
+			expect: `unrecognized action "rename"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var args attributes.Arguments
+			err := river.Unmarshal([]byte(tc.cfg), &args)
+			require.ErrorContains(t, err, tc.expect)
+		})
+	}
+}
+
+func TestArguments_Convert(t *testing.T) {
+	cfg := `
+		action {
+			key    = "http.status_code"
+			action = "convert"
+			converted_type = "int"
+		}
+
+		include {
+			match_type = "strict"
+			services   = ["api"]
+
+			attribute {
+				key   = "region"
+				value = "eu"
+			}
+		}
+
+		output {}
+	`
+	var args attributes.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	_, err := args.Convert()
+	require.NoError(t, err)
+	require.Len(t, args.EnabledSignals(), 3, "all signals should be enabled by default")
+}
+
+// makeTracesOutput returns ConsumerArguments which will forward traces to the
+// provided channel.
+func makeTracesOutput(ch chan ptrace.Traces) *otelcol.ConsumerArguments {
+	traceConsumer := fakeconsumer.Consumer{
+		ConsumeTracesFunc: func(ctx context.Context, t ptrace.Traces) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ch <- t:
+				return nil
+			}
+		},
+	}
+
+	return &otelcol.ConsumerArguments{
+		Traces: []otelcol.Consumer{&traceConsumer},
+	}
+}
+
+func createTestTraces() ptrace.Traces {
+	// Matches format from the protobuf definition:
+	// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/trace/v1/trace.proto
+	var bb = `{
+		"resource_spans": [{
+			"scope_spans": [{
+				"spans": [{
+					"name": "TestSpan"
+				}]
+			}]
+		}]
+	}`
+
+	decoder := &ptrace.JSONUnmarshaler{}
+	data, err := decoder.UnmarshalTraces([]byte(bb))
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
@@ -21,7 +21,7 @@ func init() {
 		Exports: otelcol.ConsumerExports{},
 
 		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
-			fact := batchprocessor.NewFactory()
+			fact := newFactory()
 			return processor.New(opts, fact, args.(Arguments))
 		},
 	})
@@ -33,6 +33,9 @@ type Arguments struct {
 	SendBatchSize    uint32        `river:"send_batch_size,attr,optional"`
 	SendBatchMaxSize uint32        `river:"send_batch_max_size,attr,optional"`
 
+	MetadataKeys             []string `river:"metadata_keys,attr,optional"`
+	MetadataCardinalityLimit uint32   `river:"metadata_cardinality_limit,attr,optional"`
+
 	// Output configures where to send processed data. Required.
 	Output *otelcol.ConsumerArguments `river:"output,block"`
 }
@@ -46,6 +49,8 @@ var (
 var DefaultArguments = Arguments{
 	Timeout:       200 * time.Millisecond,
 	SendBatchSize: 8192,
+
+	MetadataCardinalityLimit: 1000,
 }
 
 // UnmarshalRiver implements river.Unmarshaler. It applies defaults to args and
@@ -66,11 +71,15 @@ func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
 
 // Convert implements processor.Arguments.
 func (args Arguments) Convert() (otelconfig.Processor, error) {
-	return &batchprocessor.Config{
-		ProcessorSettings: otelconfig.NewProcessorSettings(otelconfig.NewComponentID("batch")),
-		Timeout:           args.Timeout,
-		SendBatchSize:     args.SendBatchSize,
-		SendBatchMaxSize:  args.SendBatchMaxSize,
+	return &Config{
+		Config: batchprocessor.Config{
+			ProcessorSettings: otelconfig.NewProcessorSettings(otelconfig.NewComponentID("batch")),
+			Timeout:           args.Timeout,
+			SendBatchSize:     args.SendBatchSize,
+			SendBatchMaxSize:  args.SendBatchMaxSize,
+		},
+		MetadataKeys:             args.MetadataKeys,
+		MetadataCardinalityLimit: args.MetadataCardinalityLimit,
 	}, nil
 }
 
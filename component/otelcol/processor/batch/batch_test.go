@@ -2,6 +2,7 @@ package batch_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/grafana/agent/pkg/util"
 	"github.com/grafana/dskit/backoff"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/client"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 )
 
@@ -77,6 +79,82 @@ func Test(t *testing.T) {
 	}
 }
 
+// TestMetadataKeys ensures that data is batched separately for each tenant and
+// that the client metadata is available to the next consumer.
+func TestMetadataKeys(t *testing.T) {
+	ctx := componenttest.TestContext(t)
+	l := util.TestLogger(t)
+
+	ctrl, err := componenttest.NewControllerFromID(l, "otelcol.processor.batch")
+	require.NoError(t, err)
+
+	cfg := `
+		timeout                    = "10ms"
+		metadata_keys              = ["X-Scope-OrgID"]
+		metadata_cardinality_limit = 2
+
+		output {
+			// no-op: will be overridden by test code.
+		}
+	`
+	var args batch.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	// Override our arguments so the tenant of each batch gets forwarded to
+	// tenantCh.
+	tenantCh := make(chan string)
+	args.Output = &otelcol.ConsumerArguments{
+		Traces: []otelcol.Consumer{&fakeconsumer.Consumer{
+			ConsumeTracesFunc: func(ctx context.Context, t ptrace.Traces) error {
+				tenant := strings.Join(client.FromContext(ctx).Metadata.Get("X-Scope-OrgID"), ",")
+				for i := 0; i < t.SpanCount(); i++ {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case tenantCh <- tenant:
+					}
+				}
+				return nil
+			},
+		}},
+	}
+
+	go func() {
+		err := ctrl.Run(ctx, args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, ctrl.WaitRunning(time.Second), "component never started")
+	require.NoError(t, ctrl.WaitExports(time.Second), "component never exported anything")
+
+	exports := ctrl.Exports().(otelcol.ConsumerExports)
+	tenantContext := func(tenant string) context.Context {
+		return client.NewContext(ctx, client.Info{
+			Metadata: client.NewMetadata(map[string][]string{"X-Scope-OrgID": {tenant}}),
+		})
+	}
+
+	go func() {
+		for _, tenant := range []string{"tenant-a", "tenant-b", "tenant-a"} {
+			require.NoError(t, exports.Input.ConsumeTraces(tenantContext(tenant), createTestTraces()))
+		}
+
+		// A third tenant exceeds the cardinality limit.
+		require.Error(t, exports.Input.ConsumeTraces(tenantContext("tenant-c"), createTestTraces()))
+	}()
+
+	received := make(map[string]int)
+	for i := 0; i < 3; i++ {
+		select {
+		case <-time.After(time.Second):
+			require.FailNow(t, "failed waiting for traces")
+		case tenant := <-tenantCh:
+			received[tenant]++
+		}
+	}
+	require.Equal(t, map[string]int{"tenant-a": 2, "tenant-b": 1}, received)
+}
+
 // makeTracesOutput returns ConsumerArguments which will forward traces to the
 // provided channel.
 func makeTracesOutput(ch chan ptrace.Traces) *otelcol.ConsumerArguments {
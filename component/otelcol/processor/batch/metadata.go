@@ -0,0 +1,269 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/client"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor/batchprocessor"
+	"go.uber.org/multierr"
+)
+
+// Config is the configuration of processors created by the factory returned
+// by newFactory. It extends the upstream batch processor configuration with
+// settings for batching by client metadata.
+type Config struct {
+	batchprocessor.Config
+
+	// MetadataKeys is the list of client metadata keys to batch data by. When
+	// empty, all data is put into the same batch.
+	MetadataKeys []string
+	// MetadataCardinalityLimit is the maximum number of distinct combinations
+	// of metadata values to batch separately. 0 means no limit.
+	MetadataCardinalityLimit uint32
+}
+
+// newFactory returns a factory for batch processors which wraps the upstream
+// batch processor factory.
+//
+// When MetadataKeys is set, the created processors batch data separately for
+// each distinct combination of values of the given client metadata keys. The
+// metadata is restored on the context passed to the next consumer, so that
+// components further down the pipeline (such as otelcol.auth.headers with
+// from_context) can still access it after batching.
+func newFactory() otelcomponent.ProcessorFactory {
+	upstream := batchprocessor.NewFactory()
+
+	return otelcomponent.NewProcessorFactory(
+		upstream.Type(),
+		func() otelconfig.Processor {
+			return &Config{Config: *upstream.CreateDefaultConfig().(*batchprocessor.Config)}
+		},
+		otelcomponent.WithTracesProcessor(func(ctx context.Context, set otelcomponent.ProcessorCreateSettings, cfg otelconfig.Processor, next consumer.Traces) (otelcomponent.TracesProcessor, error) {
+			c := cfg.(*Config)
+			if len(c.MetadataKeys) == 0 {
+				return upstream.CreateTracesProcessor(ctx, set, &c.Config, next)
+			}
+			return &tracesProcessor{
+				shards: newShards(c, func(md client.Metadata) (otelcomponent.TracesProcessor, error) {
+					return upstream.CreateTracesProcessor(ctx, set, &c.Config, tracesWithMetadata(next, md))
+				}),
+			}, nil
+		}, upstream.TracesProcessorStability()),
+		otelcomponent.WithMetricsProcessor(func(ctx context.Context, set otelcomponent.ProcessorCreateSettings, cfg otelconfig.Processor, next consumer.Metrics) (otelcomponent.MetricsProcessor, error) {
+			c := cfg.(*Config)
+			if len(c.MetadataKeys) == 0 {
+				return upstream.CreateMetricsProcessor(ctx, set, &c.Config, next)
+			}
+			return &metricsProcessor{
+				shards: newShards(c, func(md client.Metadata) (otelcomponent.MetricsProcessor, error) {
+					return upstream.CreateMetricsProcessor(ctx, set, &c.Config, metricsWithMetadata(next, md))
+				}),
+			}, nil
+		}, upstream.MetricsProcessorStability()),
+		otelcomponent.WithLogsProcessor(func(ctx context.Context, set otelcomponent.ProcessorCreateSettings, cfg otelconfig.Processor, next consumer.Logs) (otelcomponent.LogsProcessor, error) {
+			c := cfg.(*Config)
+			if len(c.MetadataKeys) == 0 {
+				return upstream.CreateLogsProcessor(ctx, set, &c.Config, next)
+			}
+			return &logsProcessor{
+				shards: newShards(c, func(md client.Metadata) (otelcomponent.LogsProcessor, error) {
+					return upstream.CreateLogsProcessor(ctx, set, &c.Config, logsWithMetadata(next, md))
+				}),
+			}, nil
+		}, upstream.LogsProcessorStability()),
+	)
+}
+
+// shards manages one processor for each distinct combination of metadata
+// values. Processors are created on demand.
+type shards[P otelcomponent.Component] struct {
+	keys   []string
+	limit  int
+	create func(md client.Metadata) (P, error)
+
+	mut        sync.Mutex
+	host       otelcomponent.Host // Set once started.
+	processors map[string]P
+}
+
+func newShards[P otelcomponent.Component](cfg *Config, create func(client.Metadata) (P, error)) *shards[P] {
+	return &shards[P]{
+		keys:       cfg.MetadataKeys,
+		limit:      int(cfg.MetadataCardinalityLimit),
+		create:     create,
+		processors: make(map[string]P),
+	}
+}
+
+// Start implements otelcomponent.Component.
+func (s *shards[P]) Start(ctx context.Context, host otelcomponent.Host) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.host = host
+	for _, p := range s.processors {
+		if err := p.Start(ctx, host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Shutdown implements otelcomponent.Component. All processors are shut down,
+// flushing their pending batches.
+func (s *shards[P]) Shutdown(ctx context.Context) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	var err error
+	for _, p := range s.processors {
+		err = multierr.Append(err, p.Shutdown(ctx))
+	}
+	s.processors = make(map[string]P)
+	s.host = nil
+	return err
+}
+
+// get returns the processor responsible for the metadata found in ctx,
+// creating it if it doesn't exist yet.
+func (s *shards[P]) get(ctx context.Context) (P, error) {
+	info := client.FromContext(ctx)
+
+	var (
+		sb strings.Builder
+		md = make(map[string][]string, len(s.keys))
+	)
+	for i, key := range s.keys {
+		vals := info.Metadata.Get(key)
+		if i > 0 {
+			sb.WriteByte(0)
+		}
+		sb.WriteString(fmt.Sprintf("%q", vals))
+		if len(vals) > 0 {
+			md[key] = vals
+		}
+	}
+	id := sb.String()
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if p, ok := s.processors[id]; ok {
+		return p, nil
+	}
+
+	var zero P
+	if s.limit > 0 && len(s.processors) >= s.limit {
+		return zero, fmt.Errorf("too many distinct metadata value combinations: limit of %d reached", s.limit)
+	}
+
+	p, err := s.create(client.NewMetadata(md))
+	if err != nil {
+		return zero, err
+	}
+	if s.host != nil {
+		// Use a background context here; the context of the incoming request
+		// may be canceled while the processor is still running.
+		if err := p.Start(context.Background(), s.host); err != nil {
+			return zero, err
+		}
+	}
+	s.processors[id] = p
+	return p, nil
+}
+
+type tracesProcessor struct {
+	*shards[otelcomponent.TracesProcessor]
+}
+
+var _ otelcomponent.TracesProcessor = (*tracesProcessor)(nil)
+
+func (p *tracesProcessor) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: true}
+}
+
+func (p *tracesProcessor) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	proc, err := p.get(ctx)
+	if err != nil {
+		return err
+	}
+	return proc.ConsumeTraces(ctx, td)
+}
+
+type metricsProcessor struct {
+	*shards[otelcomponent.MetricsProcessor]
+}
+
+var _ otelcomponent.MetricsProcessor = (*metricsProcessor)(nil)
+
+func (p *metricsProcessor) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: true}
+}
+
+func (p *metricsProcessor) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	proc, err := p.get(ctx)
+	if err != nil {
+		return err
+	}
+	return proc.ConsumeMetrics(ctx, md)
+}
+
+type logsProcessor struct {
+	*shards[otelcomponent.LogsProcessor]
+}
+
+var _ otelcomponent.LogsProcessor = (*logsProcessor)(nil)
+
+func (p *logsProcessor) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: true}
+}
+
+func (p *logsProcessor) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	proc, err := p.get(ctx)
+	if err != nil {
+		return err
+	}
+	return proc.ConsumeLogs(ctx, ld)
+}
+
+// tracesWithMetadata returns a consumer which forwards traces to next with md
+// set as the client metadata of the context.
+func tracesWithMetadata(next consumer.Traces, md client.Metadata) consumer.Traces {
+	c, _ := consumer.NewTraces(func(ctx context.Context, td ptrace.Traces) error {
+		return next.ConsumeTraces(withMetadata(ctx, md), td)
+	}, consumer.WithCapabilities(next.Capabilities()))
+	return c
+}
+
+// metricsWithMetadata returns a consumer which forwards metrics to next with
+// md set as the client metadata of the context.
+func metricsWithMetadata(next consumer.Metrics, md client.Metadata) consumer.Metrics {
+	c, _ := consumer.NewMetrics(func(ctx context.Context, m pmetric.Metrics) error {
+		return next.ConsumeMetrics(withMetadata(ctx, md), m)
+	}, consumer.WithCapabilities(next.Capabilities()))
+	return c
+}
+
+// logsWithMetadata returns a consumer which forwards logs to next with md set
+// as the client metadata of the context.
+func logsWithMetadata(next consumer.Logs, md client.Metadata) consumer.Logs {
+	c, _ := consumer.NewLogs(func(ctx context.Context, ld plog.Logs) error {
+		return next.ConsumeLogs(withMetadata(ctx, md), ld)
+	}, consumer.WithCapabilities(next.Capabilities()))
+	return c
+}
+
+func withMetadata(ctx context.Context, md client.Metadata) context.Context {
+	info := client.FromContext(ctx)
+	info.Metadata = md
+	return client.NewContext(ctx, info)
+}
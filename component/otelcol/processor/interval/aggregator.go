@@ -0,0 +1,408 @@
+package interval
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// Supported aggregations for gauges.
+const (
+	AggregationLast = "last"
+	AggregationSum  = "sum"
+	AggregationMin  = "min"
+	AggregationMax  = "max"
+)
+
+// aggregator accumulates metrics between flushes, keeping a single data
+// point per series.
+//
+// Gauges are aggregated using the configured aggregation. Other metric types
+// are aggregated so that no information is lost: delta sums are added
+// together, and cumulative sums, cumulative histograms, and summaries keep
+// the most recent data point. Delta histograms can't be merged without
+// knowing their bucket layout, so they are passed through unmodified.
+//
+// aggregator is not safe for concurrent use.
+type aggregator struct {
+	aggregation string
+	resources   map[string]*resourceState
+}
+
+type resourceState struct {
+	resource  pcommon.Resource
+	schemaURL string
+	scopes    map[string]*scopeState
+}
+
+type scopeState struct {
+	scope     pcommon.InstrumentationScope
+	schemaURL string
+	metrics   map[string]*metricState
+}
+
+type metricState struct {
+	// metric holds the metadata of the metric along with the aggregated data
+	// points.
+	metric pmetric.Metric
+	// points maps the identity of a series to the index of its data point in
+	// metric.
+	points map[string]int
+}
+
+func newAggregator(aggregation string) *aggregator {
+	return &aggregator{
+		aggregation: aggregation,
+		resources:   make(map[string]*resourceState),
+	}
+}
+
+// add aggregates md into the state of a. Metrics which can't be aggregated
+// are returned to be forwarded immediately.
+func (a *aggregator) add(md pmetric.Metrics) pmetric.Metrics {
+	passthrough := newBuilder()
+
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		rKey := rm.SchemaUrl() + "\x00" + mapKey(rm.Resource().Attributes())
+
+		rs, ok := a.resources[rKey]
+		if !ok {
+			rs = &resourceState{
+				resource:  pcommon.NewResource(),
+				schemaURL: rm.SchemaUrl(),
+				scopes:    make(map[string]*scopeState),
+			}
+			rm.Resource().CopyTo(rs.resource)
+			a.resources[rKey] = rs
+		}
+
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			sm := sms.At(j)
+			sKey := scopeKey(sm)
+
+			ss, ok := rs.scopes[sKey]
+			if !ok {
+				ss = &scopeState{
+					scope:     pcommon.NewInstrumentationScope(),
+					schemaURL: sm.SchemaUrl(),
+					metrics:   make(map[string]*metricState),
+				}
+				sm.Scope().CopyTo(ss.scope)
+				rs.scopes[sKey] = ss
+			}
+
+			ms := sm.Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				m := ms.At(k)
+				if !aggregatable(m) {
+					out := passthrough.scopeMetrics(rKey, rm.Resource(), rm.SchemaUrl(), sKey, sm.Scope(), sm.SchemaUrl())
+					m.CopyTo(out.Metrics().AppendEmpty())
+					continue
+				}
+
+				mKey := metricKey(m)
+				st, ok := ss.metrics[mKey]
+				if !ok {
+					st = newMetricState(m)
+					ss.metrics[mKey] = st
+				}
+				a.addMetric(st, m)
+			}
+		}
+	}
+
+	return passthrough.metrics
+}
+
+func (a *aggregator) addMetric(st *metricState, m pmetric.Metric) {
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		dst, src := st.metric.Gauge().DataPoints(), m.Gauge().DataPoints()
+		for i := 0; i < src.Len(); i++ {
+			dp := src.At(i)
+			if idx, ok := st.point(dp.Attributes(), dst.Len()); ok {
+				mergeNumber(dst.At(idx), dp, a.aggregation)
+			} else {
+				dp.CopyTo(dst.AppendEmpty())
+			}
+		}
+
+	case pmetric.MetricTypeSum:
+		aggregation := AggregationLast
+		if m.Sum().AggregationTemporality() == pmetric.AggregationTemporalityDelta {
+			aggregation = AggregationSum
+		}
+
+		dst, src := st.metric.Sum().DataPoints(), m.Sum().DataPoints()
+		for i := 0; i < src.Len(); i++ {
+			dp := src.At(i)
+			if idx, ok := st.point(dp.Attributes(), dst.Len()); ok {
+				mergeNumber(dst.At(idx), dp, aggregation)
+			} else {
+				dp.CopyTo(dst.AppendEmpty())
+			}
+		}
+
+	case pmetric.MetricTypeHistogram:
+		dst, src := st.metric.Histogram().DataPoints(), m.Histogram().DataPoints()
+		for i := 0; i < src.Len(); i++ {
+			dp := src.At(i)
+			if idx, ok := st.point(dp.Attributes(), dst.Len()); !ok {
+				dp.CopyTo(dst.AppendEmpty())
+			} else if dp.Timestamp() >= dst.At(idx).Timestamp() {
+				dp.CopyTo(dst.At(idx))
+			}
+		}
+
+	case pmetric.MetricTypeExponentialHistogram:
+		dst, src := st.metric.ExponentialHistogram().DataPoints(), m.ExponentialHistogram().DataPoints()
+		for i := 0; i < src.Len(); i++ {
+			dp := src.At(i)
+			if idx, ok := st.point(dp.Attributes(), dst.Len()); !ok {
+				dp.CopyTo(dst.AppendEmpty())
+			} else if dp.Timestamp() >= dst.At(idx).Timestamp() {
+				dp.CopyTo(dst.At(idx))
+			}
+		}
+
+	case pmetric.MetricTypeSummary:
+		dst, src := st.metric.Summary().DataPoints(), m.Summary().DataPoints()
+		for i := 0; i < src.Len(); i++ {
+			dp := src.At(i)
+			if idx, ok := st.point(dp.Attributes(), dst.Len()); !ok {
+				dp.CopyTo(dst.AppendEmpty())
+			} else if dp.Timestamp() >= dst.At(idx).Timestamp() {
+				dp.CopyTo(dst.At(idx))
+			}
+		}
+	}
+}
+
+// flush returns the aggregated metrics and resets the state of a.
+func (a *aggregator) flush() pmetric.Metrics {
+	b := newBuilder()
+
+	for rKey, rs := range a.resources {
+		for sKey, ss := range rs.scopes {
+			sm := b.scopeMetrics(rKey, rs.resource, rs.schemaURL, sKey, ss.scope, ss.schemaURL)
+			for _, st := range ss.metrics {
+				st.metric.MoveTo(sm.Metrics().AppendEmpty())
+			}
+		}
+	}
+
+	a.resources = make(map[string]*resourceState)
+	return b.metrics
+}
+
+// seriesCount returns the number of series currently being aggregated.
+func (a *aggregator) seriesCount() int {
+	var n int
+	for _, rs := range a.resources {
+		for _, ss := range rs.scopes {
+			for _, st := range ss.metrics {
+				n += len(st.points)
+			}
+		}
+	}
+	return n
+}
+
+func newMetricState(m pmetric.Metric) *metricState {
+	st := &metricState{
+		metric: pmetric.NewMetric(),
+		points: make(map[string]int),
+	}
+	st.metric.SetName(m.Name())
+	st.metric.SetDescription(m.Description())
+	st.metric.SetUnit(m.Unit())
+
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		st.metric.SetEmptyGauge()
+	case pmetric.MetricTypeSum:
+		sum := st.metric.SetEmptySum()
+		sum.SetAggregationTemporality(m.Sum().AggregationTemporality())
+		sum.SetIsMonotonic(m.Sum().IsMonotonic())
+	case pmetric.MetricTypeHistogram:
+		st.metric.SetEmptyHistogram().SetAggregationTemporality(m.Histogram().AggregationTemporality())
+	case pmetric.MetricTypeExponentialHistogram:
+		st.metric.SetEmptyExponentialHistogram().SetAggregationTemporality(m.ExponentialHistogram().AggregationTemporality())
+	case pmetric.MetricTypeSummary:
+		st.metric.SetEmptySummary()
+	}
+	return st
+}
+
+// point returns the index of the data point for the series identified by
+// attrs. If the series is new, it is assigned next and false is returned.
+func (st *metricState) point(attrs pcommon.Map, next int) (int, bool) {
+	key := mapKey(attrs)
+	if idx, ok := st.points[key]; ok {
+		return idx, true
+	}
+	st.points[key] = next
+	return next, false
+}
+
+// aggregatable returns true if data points of m can be merged.
+func aggregatable(m pmetric.Metric) bool {
+	switch m.Type() {
+	case pmetric.MetricTypeHistogram:
+		return m.Histogram().AggregationTemporality() != pmetric.AggregationTemporalityDelta
+	case pmetric.MetricTypeExponentialHistogram:
+		return m.ExponentialHistogram().AggregationTemporality() != pmetric.AggregationTemporalityDelta
+	case pmetric.MetricTypeGauge, pmetric.MetricTypeSum, pmetric.MetricTypeSummary:
+		return true
+	default:
+		return false
+	}
+}
+
+// mergeNumber merges src into dst using the given aggregation.
+func mergeNumber(dst, src pmetric.NumberDataPoint, aggregation string) {
+	if aggregation == AggregationLast {
+		if src.Timestamp() >= dst.Timestamp() {
+			src.CopyTo(dst)
+		}
+		return
+	}
+
+	if dst.ValueType() == pmetric.NumberDataPointValueTypeInt && src.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		a, b := dst.IntValue(), src.IntValue()
+		switch aggregation {
+		case AggregationSum:
+			dst.SetIntValue(a + b)
+		case AggregationMin:
+			if b < a {
+				dst.SetIntValue(b)
+			}
+		case AggregationMax:
+			if b > a {
+				dst.SetIntValue(b)
+			}
+		}
+	} else {
+		a, b := numberValue(dst), numberValue(src)
+		switch aggregation {
+		case AggregationSum:
+			dst.SetDoubleValue(a + b)
+		case AggregationMin:
+			dst.SetDoubleValue(math.Min(a, b))
+		case AggregationMax:
+			dst.SetDoubleValue(math.Max(a, b))
+		}
+	}
+
+	if src.Timestamp() > dst.Timestamp() {
+		dst.SetTimestamp(src.Timestamp())
+	}
+	if start := src.StartTimestamp(); start != 0 && (dst.StartTimestamp() == 0 || start < dst.StartTimestamp()) {
+		dst.SetStartTimestamp(start)
+	}
+}
+
+func numberValue(dp pmetric.NumberDataPoint) float64 {
+	if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		return float64(dp.IntValue())
+	}
+	return dp.DoubleValue()
+}
+
+func scopeKey(sm pmetric.ScopeMetrics) string {
+	return strings.Join([]string{
+		sm.SchemaUrl(),
+		sm.Scope().Name(),
+		sm.Scope().Version(),
+		mapKey(sm.Scope().Attributes()),
+	}, "\x00")
+}
+
+func metricKey(m pmetric.Metric) string {
+	var temporality pmetric.AggregationTemporality
+	var monotonic bool
+
+	switch m.Type() {
+	case pmetric.MetricTypeSum:
+		temporality = m.Sum().AggregationTemporality()
+		monotonic = m.Sum().IsMonotonic()
+	case pmetric.MetricTypeHistogram:
+		temporality = m.Histogram().AggregationTemporality()
+	case pmetric.MetricTypeExponentialHistogram:
+		temporality = m.ExponentialHistogram().AggregationTemporality()
+	}
+
+	return strings.Join([]string{
+		m.Name(),
+		m.Unit(),
+		m.Type().String(),
+		temporality.String(),
+		strconv.FormatBool(monotonic),
+	}, "\x00")
+}
+
+// mapKey returns a string which uniquely identifies the contents of m.
+func mapKey(m pcommon.Map) string {
+	keys := make([]string, 0, m.Len())
+	m.Range(func(k string, _ pcommon.Value) bool {
+		keys = append(keys, k)
+		return true
+	})
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		v, _ := m.Get(k)
+		sb.WriteString(k)
+		sb.WriteByte(0)
+		sb.WriteString(v.Type().String())
+		sb.WriteByte(0)
+		sb.WriteString(v.AsString())
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
+
+// builder builds a pmetric.Metrics, grouping metrics by resource and scope.
+type builder struct {
+	metrics   pmetric.Metrics
+	resources map[string]pmetric.ResourceMetrics
+	scopes    map[string]pmetric.ScopeMetrics
+}
+
+func newBuilder() *builder {
+	return &builder{
+		metrics:   pmetric.NewMetrics(),
+		resources: make(map[string]pmetric.ResourceMetrics),
+		scopes:    make(map[string]pmetric.ScopeMetrics),
+	}
+}
+
+// scopeMetrics returns the ScopeMetrics to append metrics for the given
+// resource and scope to, creating it if it doesn't exist.
+func (b *builder) scopeMetrics(rKey string, res pcommon.Resource, resSchemaURL, sKey string, scope pcommon.InstrumentationScope, scopeSchemaURL string) pmetric.ScopeMetrics {
+	rm, ok := b.resources[rKey]
+	if !ok {
+		rm = b.metrics.ResourceMetrics().AppendEmpty()
+		res.CopyTo(rm.Resource())
+		rm.SetSchemaUrl(resSchemaURL)
+		b.resources[rKey] = rm
+	}
+
+	fullKey := rKey + "\x01" + sKey
+	sm, ok := b.scopes[fullKey]
+	if !ok {
+		sm = rm.ScopeMetrics().AppendEmpty()
+		scope.CopyTo(sm.Scope())
+		sm.SetSchemaUrl(scopeSchemaURL)
+		b.scopes[fullKey] = sm
+	}
+	return sm
+}
@@ -0,0 +1,134 @@
+package interval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestAggregator_Gauge(t *testing.T) {
+	tt := []struct {
+		aggregation string
+		expect      float64
+	}{
+		{AggregationLast, 2},
+		{AggregationSum, 10},
+		{AggregationMin, 1},
+		{AggregationMax, 7},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.aggregation, func(t *testing.T) {
+			agg := newAggregator(tc.aggregation)
+			for i, v := range []float64{1, 7, 2} {
+				md, m := newMetric("gauge")
+				dp := m.SetEmptyGauge().DataPoints().AppendEmpty()
+				dp.SetTimestamp(pcommon.Timestamp(i + 1))
+				dp.SetDoubleValue(v)
+				require.Zero(t, agg.add(md).DataPointCount())
+			}
+			require.Equal(t, 1, agg.seriesCount())
+
+			out := agg.flush()
+			require.Equal(t, 1, out.DataPointCount())
+			dp := firstMetric(out).Gauge().DataPoints().At(0)
+			require.Equal(t, tc.expect, dp.DoubleValue())
+			require.Equal(t, pcommon.Timestamp(3), dp.Timestamp())
+
+			require.Zero(t, agg.seriesCount())
+			require.Zero(t, agg.flush().DataPointCount())
+		})
+	}
+}
+
+func TestAggregator_Series(t *testing.T) {
+	agg := newAggregator(AggregationLast)
+
+	for _, host := range []string{"a", "b", "a"} {
+		md, m := newMetric("gauge")
+		md.ResourceMetrics().At(0).Resource().Attributes().PutStr("service.name", "svc")
+		dp := m.SetEmptyGauge().DataPoints().AppendEmpty()
+		dp.Attributes().PutStr("host", host)
+		dp.SetIntValue(1)
+		agg.add(md)
+	}
+
+	require.Equal(t, 2, agg.seriesCount())
+
+	out := agg.flush()
+	require.Equal(t, 1, out.ResourceMetrics().Len())
+	require.Equal(t, 1, out.MetricCount())
+	require.Equal(t, 2, out.DataPointCount())
+}
+
+func TestAggregator_Sum(t *testing.T) {
+	agg := newAggregator(AggregationLast)
+
+	for i, v := range []int64{1, 2, 3} {
+		md, m := newMetric("delta")
+		sum := m.SetEmptySum()
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		sum.SetIsMonotonic(true)
+		dp := sum.DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(pcommon.Timestamp(i*10 + 5))
+		dp.SetTimestamp(pcommon.Timestamp(i*10 + 10))
+		dp.SetIntValue(v)
+		agg.add(md)
+
+		md, m = newMetric("cumulative")
+		sum = m.SetEmptySum()
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		dp = sum.DataPoints().AppendEmpty()
+		dp.SetTimestamp(pcommon.Timestamp(i*10 + 10))
+		dp.SetIntValue(v * 100)
+		agg.add(md)
+	}
+
+	out := agg.flush()
+	require.Equal(t, 2, out.DataPointCount())
+
+	ms := out.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < ms.Len(); i++ {
+		m := ms.At(i)
+		dp := m.Sum().DataPoints().At(0)
+
+		switch m.Name() {
+		case "delta":
+			require.True(t, m.Sum().IsMonotonic())
+			require.Equal(t, int64(6), dp.IntValue())
+			require.Equal(t, pcommon.Timestamp(5), dp.StartTimestamp())
+			require.Equal(t, pcommon.Timestamp(30), dp.Timestamp())
+		case "cumulative":
+			require.Equal(t, int64(300), dp.IntValue())
+		default:
+			require.FailNow(t, "unexpected metric", m.Name())
+		}
+	}
+}
+
+func TestAggregator_DeltaHistogramPassthrough(t *testing.T) {
+	agg := newAggregator(AggregationLast)
+
+	md, m := newMetric("histogram")
+	hist := m.SetEmptyHistogram()
+	hist.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	hist.DataPoints().AppendEmpty().SetCount(5)
+
+	passthrough := agg.add(md)
+	require.Equal(t, 1, passthrough.DataPointCount())
+	require.Equal(t, uint64(5), firstMetric(passthrough).Histogram().DataPoints().At(0).Count())
+	require.Zero(t, agg.flush().DataPointCount())
+}
+
+func newMetric(name string) (pmetric.Metrics, pmetric.Metric) {
+	md := pmetric.NewMetrics()
+	m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName(name)
+	return md, m
+}
+
+func firstMetric(md pmetric.Metrics) pmetric.Metric {
+	return md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+}
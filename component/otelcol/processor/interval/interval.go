@@ -0,0 +1,192 @@
+// Package interval provides an otelcol.processor.interval component.
+package interval
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/internal/fanoutconsumer"
+	"github.com/grafana/agent/component/otelcol/internal/lazyconsumer"
+	"github.com/grafana/agent/pkg/river"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "otelcol.processor.interval",
+		Args:    Arguments{},
+		Exports: otelcol.ConsumerExports{},
+
+		Build: func(o component.Options, a component.Arguments) (component.Component, error) {
+			return New(o, a.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.processor.interval component.
+type Arguments struct {
+	Interval    time.Duration `river:"interval,attr,optional"`
+	Aggregation string        `river:"aggregation,attr,optional"`
+
+	// Output configures where to send processed data. Required.
+	Output *otelcol.ConsumerArguments `river:"output,block"`
+}
+
+var _ river.Unmarshaler = (*Arguments)(nil)
+
+// DefaultArguments holds default settings for otelcol.processor.interval.
+var DefaultArguments = Arguments{
+	Interval:    60 * time.Second,
+	Aggregation: AggregationLast,
+}
+
+// UnmarshalRiver implements river.Unmarshaler and applies defaults.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if args.Interval <= 0 {
+		return fmt.Errorf("interval must be greater than 0")
+	}
+
+	switch args.Aggregation {
+	case AggregationLast, AggregationSum, AggregationMin, AggregationMax:
+	default:
+		return fmt.Errorf("unsupported aggregation %q, expected one of %q, %q, %q, or %q",
+			args.Aggregation, AggregationLast, AggregationSum, AggregationMin, AggregationMax)
+	}
+
+	return nil
+}
+
+// Component is the otelcol.processor.interval component.
+type Component struct {
+	log log.Logger
+
+	updateCh chan struct{}
+
+	mut  sync.Mutex
+	args Arguments
+	agg  *aggregator
+	next otelconsumer.Metrics
+}
+
+var (
+	_ component.Component  = (*Component)(nil)
+	_ otelconsumer.Metrics = (*Component)(nil)
+)
+
+// New creates a new otelcol.processor.interval component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{
+		log:      o.Logger,
+		updateCh: make(chan struct{}, 1),
+		agg:      newAggregator(args.Aggregation),
+	}
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+
+	// The exported consumer remains the same throughout the component's
+	// lifetime, so we export it during component construction. Only metrics
+	// are supported.
+	export := lazyconsumer.New(context.Background())
+	export.SetConsumers(nil, c, nil)
+	o.OnStateChange(otelcol.ConsumerExports{Input: export})
+
+	return c, nil
+}
+
+// Run implements Component.
+func (c *Component) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Flush whatever was aggregated so far so that it isn't lost on
+			// shutdown.
+			c.flush(context.Background())
+			return nil
+		case <-c.updateCh:
+			ticker.Reset(c.interval())
+		case <-ticker.C:
+			c.flush(ctx)
+		}
+	}
+}
+
+func (c *Component) interval() time.Duration {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.args.Interval
+}
+
+// flush sends aggregated metrics to the next consumers.
+func (c *Component) flush(ctx context.Context) {
+	c.mut.Lock()
+	md := c.agg.flush()
+	next := c.next
+	c.mut.Unlock()
+
+	if md.DataPointCount() == 0 {
+		return
+	}
+	if err := next.ConsumeMetrics(ctx, md); err != nil {
+		level.Error(c.log).Log("msg", "failed to send aggregated metrics", "err", err)
+	}
+}
+
+// Update implements Component.
+func (c *Component) Update(newArgs component.Arguments) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	args := newArgs.(Arguments)
+	c.args = args
+	c.agg.aggregation = args.Aggregation
+
+	var next []otelcol.Consumer
+	if args.Output != nil {
+		next = args.Output.Metrics
+	}
+	c.next = fanoutconsumer.Metrics(next)
+
+	select {
+	case c.updateCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Capabilities implements otelconsumer.Metrics.
+func (c *Component) Capabilities() otelconsumer.Capabilities {
+	return otelconsumer.Capabilities{MutatesData: false}
+}
+
+// ConsumeMetrics implements otelconsumer.Metrics. Metrics which can be
+// aggregated are held until the next flush, while all other metrics are
+// forwarded immediately.
+func (c *Component) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	c.mut.Lock()
+	passthrough := c.agg.add(md)
+	next := c.next
+	c.mut.Unlock()
+
+	if passthrough.DataPointCount() == 0 {
+		return nil
+	}
+	return next.ConsumeMetrics(ctx, passthrough)
+}
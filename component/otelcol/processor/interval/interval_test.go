@@ -0,0 +1,126 @@
+package interval_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/internal/fakeconsumer"
+	"github.com/grafana/agent/component/otelcol/processor/interval"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// Test performs a basic integration test which runs the
+// otelcol.processor.interval component and ensures that it aggregates and
+// forwards data.
+func Test(t *testing.T) {
+	ctx := componenttest.TestContext(t)
+	l := util.TestLogger(t)
+
+	ctrl, err := componenttest.NewControllerFromID(l, "otelcol.processor.interval")
+	require.NoError(t, err)
+
+	cfg := `
+		interval    = "100ms"
+		aggregation = "max"
+
+		output {
+			// no-op: will be overridden by test code.
+		}
+	`
+	var args interval.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	// Override our arguments so metrics get forwarded to metricCh.
+	metricCh := make(chan pmetric.Metrics)
+	args.Output = makeMetricsOutput(metricCh)
+
+	go func() {
+		err := ctrl.Run(ctx, args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, ctrl.WaitRunning(time.Second), "component never started")
+	require.NoError(t, ctrl.WaitExports(time.Second), "component never exported anything")
+
+	exports := ctrl.Exports().(otelcol.ConsumerExports)
+	for _, v := range []float64{3, 7, 5} {
+		require.NoError(t, exports.Input.ConsumeMetrics(ctx, createTestGauge(v)))
+	}
+
+	select {
+	case <-time.After(time.Second):
+		require.FailNow(t, "failed waiting for metrics")
+	case md := <-metricCh:
+		require.Equal(t, 1, md.DataPointCount())
+
+		m := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+		require.Equal(t, "test_gauge", m.Name())
+		require.Equal(t, 7.0, m.Gauge().DataPoints().At(0).DoubleValue())
+	}
+}
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	tt := []struct {
+		name   string
+		cfg    string
+		expect string
+	}{
+		{
+			name: "zero interval",
+			cfg: `
+				interval = "0s"
+				output {}
+			`,
+			expect: "interval must be greater than 0",
+		},
+		{
+			name: "unknown aggregation",
+			cfg: `
+				aggregation = "avg"
+				output {}
+			`,
+			expect: `unsupported aggregation "avg"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var args interval.Arguments
+			err := river.Unmarshal([]byte(tc.cfg), &args)
+			require.ErrorContains(t, err, tc.expect)
+		})
+	}
+}
+
+// makeMetricsOutput returns ConsumerArguments which will forward metrics to
+// the provided channel.
+func makeMetricsOutput(ch chan pmetric.Metrics) *otelcol.ConsumerArguments {
+	metricsConsumer := fakeconsumer.Consumer{
+		ConsumeMetricsFunc: func(ctx context.Context, m pmetric.Metrics) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ch <- m:
+				return nil
+			}
+		},
+	}
+
+	return &otelcol.ConsumerArguments{
+		Metrics: []otelcol.Consumer{&metricsConsumer},
+	}
+}
+
+func createTestGauge(v float64) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("test_gauge")
+	m.SetEmptyGauge().DataPoints().AppendEmpty().SetDoubleValue(v)
+	return md
+}
@@ -0,0 +1,292 @@
+// Package metricstransform provides an otelcol.processor.metricstransform
+// component.
+package metricstransform
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/internal/fanoutconsumer"
+	"github.com/grafana/agent/component/otelcol/internal/lazyconsumer"
+	"github.com/grafana/agent/pkg/river"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "otelcol.processor.metricstransform",
+		Args:    Arguments{},
+		Exports: otelcol.ConsumerExports{},
+
+		Build: func(o component.Options, a component.Arguments) (component.Component, error) {
+			return New(o, a.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.processor.metricstransform component.
+type Arguments struct {
+	Transforms []Transform `river:"transform,block"`
+
+	// Output configures where to send processed data. Required.
+	Output *otelcol.ConsumerArguments `river:"output,block"`
+}
+
+// Supported match types of transforms.
+const (
+	MatchTypeStrict = "strict"
+	MatchTypeRegexp = "regexp"
+)
+
+// Supported actions of transforms.
+const (
+	ActionUpdate  = "update"
+	ActionInsert  = "insert"
+	ActionCombine = "combine"
+)
+
+// Supported cases of label values taken from submatches.
+const (
+	SubmatchCaseLower = "lower"
+	SubmatchCaseUpper = "upper"
+)
+
+// Transform is a transformation applied to the metrics matching Include.
+type Transform struct {
+	Include   string `river:"include,attr"`
+	MatchType string `river:"match_type,attr,optional"`
+	Action    string `river:"action,attr"`
+	NewName   string `river:"new_name,attr,optional"`
+
+	// AggregationType and SubmatchCase only apply to the combine action.
+	AggregationType string `river:"aggregation_type,attr,optional"`
+	SubmatchCase    string `river:"submatch_case,attr,optional"`
+
+	Operations []Operation `river:"operation,block,optional"`
+}
+
+var _ river.Unmarshaler = (*Transform)(nil)
+
+// DefaultTransform holds default settings for Transform.
+var DefaultTransform = Transform{
+	MatchType:       MatchTypeStrict,
+	AggregationType: AggregationSum,
+}
+
+// UnmarshalRiver implements river.Unmarshaler and applies defaults.
+func (t *Transform) UnmarshalRiver(f func(interface{}) error) error {
+	*t = DefaultTransform
+
+	type transform Transform
+	if err := f((*transform)(t)); err != nil {
+		return err
+	}
+
+	switch t.MatchType {
+	case MatchTypeStrict:
+	case MatchTypeRegexp:
+		if _, err := regexp.Compile(t.Include); err != nil {
+			return fmt.Errorf("invalid include %q: %w", t.Include, err)
+		}
+	default:
+		return fmt.Errorf("unsupported match_type %q, expected one of %q or %q",
+			t.MatchType, MatchTypeStrict, MatchTypeRegexp)
+	}
+
+	switch t.Action {
+	case ActionUpdate:
+	case ActionInsert:
+		if t.NewName == "" {
+			return fmt.Errorf("new_name must be set for the %q action", ActionInsert)
+		}
+	case ActionCombine:
+		if t.NewName == "" {
+			return fmt.Errorf("new_name must be set for the %q action", ActionCombine)
+		}
+		if t.MatchType != MatchTypeRegexp {
+			return fmt.Errorf("match_type must be %q for the %q action", MatchTypeRegexp, ActionCombine)
+		}
+	default:
+		return fmt.Errorf("unsupported action %q, expected one of %q, %q, or %q",
+			t.Action, ActionUpdate, ActionInsert, ActionCombine)
+	}
+
+	if err := validateAggregationType(t.AggregationType); err != nil {
+		return err
+	}
+	switch t.SubmatchCase {
+	case "", SubmatchCaseLower, SubmatchCaseUpper:
+	default:
+		return fmt.Errorf("unsupported submatch_case %q, expected one of %q or %q",
+			t.SubmatchCase, SubmatchCaseLower, SubmatchCaseUpper)
+	}
+	return nil
+}
+
+// Supported actions of operations.
+const (
+	OperationAddLabel             = "add_label"
+	OperationUpdateLabel          = "update_label"
+	OperationDeleteLabelValue     = "delete_label_value"
+	OperationToggleScalarDataType = "toggle_scalar_data_type"
+	OperationScaleValue           = "experimental_scale_value"
+	OperationAggregateLabels      = "aggregate_labels"
+	OperationAggregateLabelValues = "aggregate_label_values"
+)
+
+// Supported aggregation types.
+const (
+	AggregationSum  = "sum"
+	AggregationMean = "mean"
+	AggregationMin  = "min"
+	AggregationMax  = "max"
+)
+
+// Operation is an operation applied to the metrics of a transform, in the
+// order operations are defined.
+type Operation struct {
+	Action           string        `river:"action,attr"`
+	Label            string        `river:"label,attr,optional"`
+	NewLabel         string        `river:"new_label,attr,optional"`
+	LabelValue       string        `river:"label_value,attr,optional"`
+	NewValue         string        `river:"new_value,attr,optional"`
+	LabelSet         []string      `river:"label_set,attr,optional"`
+	AggregatedValues []string      `river:"aggregated_values,attr,optional"`
+	AggregationType  string        `river:"aggregation_type,attr,optional"`
+	Scale            float64       `river:"experimental_scale,attr,optional"`
+	ValueActions     []ValueAction `river:"value_action,block,optional"`
+}
+
+var _ river.Unmarshaler = (*Operation)(nil)
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (o *Operation) UnmarshalRiver(f func(interface{}) error) error {
+	type operation Operation
+	if err := f((*operation)(o)); err != nil {
+		return err
+	}
+
+	switch o.Action {
+	case OperationAddLabel:
+		if o.NewLabel == "" || o.NewValue == "" {
+			return fmt.Errorf("new_label and new_value must be set for the %q operation", o.Action)
+		}
+	case OperationUpdateLabel:
+		if o.Label == "" {
+			return fmt.Errorf("label must be set for the %q operation", o.Action)
+		}
+	case OperationDeleteLabelValue:
+		if o.Label == "" || o.LabelValue == "" {
+			return fmt.Errorf("label and label_value must be set for the %q operation", o.Action)
+		}
+	case OperationToggleScalarDataType:
+	case OperationScaleValue:
+		if o.Scale == 0 {
+			return fmt.Errorf("experimental_scale must be set to a non-zero value for the %q operation", o.Action)
+		}
+	case OperationAggregateLabels:
+		if err := validateAggregationType(o.AggregationType); err != nil {
+			return err
+		}
+	case OperationAggregateLabelValues:
+		if o.Label == "" || o.NewValue == "" || len(o.AggregatedValues) == 0 {
+			return fmt.Errorf("label, new_value, and aggregated_values must be set for the %q operation", o.Action)
+		}
+		if err := validateAggregationType(o.AggregationType); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported operation action %q", o.Action)
+	}
+	return nil
+}
+
+// ValueAction renames a label value in an update_label operation.
+type ValueAction struct {
+	Value    string `river:"value,attr"`
+	NewValue string `river:"new_value,attr"`
+}
+
+func validateAggregationType(aggregationType string) error {
+	switch aggregationType {
+	case AggregationSum, AggregationMean, AggregationMin, AggregationMax:
+		return nil
+	default:
+		return fmt.Errorf("unsupported aggregation_type %q, expected one of %q, %q, %q, or %q",
+			aggregationType, AggregationSum, AggregationMean, AggregationMin, AggregationMax)
+	}
+}
+
+// Component is the otelcol.processor.metricstransform component.
+type Component struct {
+	mut         sync.RWMutex
+	transformer *transformer
+	next        otelconsumer.Metrics
+}
+
+var (
+	_ component.Component  = (*Component)(nil)
+	_ otelconsumer.Metrics = (*Component)(nil)
+)
+
+// New creates a new otelcol.processor.metricstransform component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{}
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+
+	// The exported consumer remains the same throughout the component's
+	// lifetime, so we export it during component construction. Only metrics
+	// are supported.
+	export := lazyconsumer.New(context.Background())
+	export.SetConsumers(nil, c, nil)
+	o.OnStateChange(otelcol.ConsumerExports{Input: export})
+
+	return c, nil
+}
+
+// Run implements Component.
+func (c *Component) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// Update implements Component.
+func (c *Component) Update(newArgs component.Arguments) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	args := newArgs.(Arguments)
+	c.transformer = newTransformer(args.Transforms)
+
+	var next []otelcol.Consumer
+	if args.Output != nil {
+		next = args.Output.Metrics
+	}
+	c.next = fanoutconsumer.Metrics(next)
+	return nil
+}
+
+// Capabilities implements otelconsumer.Metrics.
+func (c *Component) Capabilities() otelconsumer.Capabilities {
+	return otelconsumer.Capabilities{MutatesData: true}
+}
+
+// ConsumeMetrics implements otelconsumer.Metrics.
+func (c *Component) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	c.mut.RLock()
+	transformer, next := c.transformer, c.next
+	c.mut.RUnlock()
+
+	transformer.processMetrics(md)
+	if md.DataPointCount() == 0 {
+		return nil
+	}
+	return next.ConsumeMetrics(ctx, md)
+}
@@ -0,0 +1,196 @@
+package metricstransform_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/internal/fakeconsumer"
+	"github.com/grafana/agent/component/otelcol/processor/metricstransform"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// Test performs a basic integration test which runs the
+// otelcol.processor.metricstransform component and ensures that it
+// transforms and forwards data.
+func Test(t *testing.T) {
+	ctx := componenttest.TestContext(t)
+	l := util.TestLogger(t)
+
+	ctrl, err := componenttest.NewControllerFromID(l, "otelcol.processor.metricstransform")
+	require.NoError(t, err)
+
+	cfg := `
+		transform {
+			include  = "system.cpu.usage"
+			action   = "update"
+			new_name = "cpu_usage_seconds"
+
+			operation {
+				action             = "experimental_scale_value"
+				experimental_scale = 0.001
+			}
+		}
+
+		output {
+			// no-op: will be overridden by test code.
+		}
+	`
+	var args metricstransform.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	// Override our arguments so metrics get forwarded to metricsCh.
+	metricsCh := make(chan pmetric.Metrics)
+	args.Output = makeMetricsOutput(metricsCh)
+
+	go func() {
+		err := ctrl.Run(ctx, args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, ctrl.WaitRunning(time.Second), "component never started")
+	require.NoError(t, ctrl.WaitExports(time.Second), "component never exported anything")
+
+	md := pmetric.NewMetrics()
+	m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("system.cpu.usage")
+	m.SetEmptyGauge().DataPoints().AppendEmpty().SetDoubleValue(1500)
+
+	exports := ctrl.Exports().(otelcol.ConsumerExports)
+	go func() {
+		require.NoError(t, exports.Input.ConsumeMetrics(ctx, md))
+	}()
+
+	select {
+	case <-time.After(time.Second):
+		require.FailNow(t, "failed waiting for metrics")
+	case md := <-metricsCh:
+		m := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+		require.Equal(t, "cpu_usage_seconds", m.Name())
+		require.Equal(t, 1.5, m.Gauge().DataPoints().At(0).DoubleValue())
+	}
+}
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	tt := []struct {
+		name   string
+		cfg    string
+		expect string
+	}{
+		{
+			name:   "no transforms",
+			cfg:    `output {}`,
+			expect: `missing required block "transform"`,
+		},
+		{
+			name: "invalid regexp",
+			cfg: `
+				transform {
+					include    = "(unclosed"
+					match_type = "regexp"
+					action     = "update"
+				}
+				output {}
+			`,
+			expect: `invalid include "(unclosed"`,
+		},
+		{
+			name: "unknown action",
+			cfg: `
+				transform {
+					include = "foo"
+					action  = "upsert"
+				}
+				output {}
+			`,
+			expect: `unsupported action "upsert"`,
+		},
+		{
+			name: "insert without new name",
+			cfg: `
+				transform {
+					include = "foo"
+					action  = "insert"
+				}
+				output {}
+			`,
+			expect: `new_name must be set for the "insert" action`,
+		},
+		{
+			name: "combine with strict match type",
+			cfg: `
+				transform {
+					include  = "foo"
+					action   = "combine"
+					new_name = "bar"
+				}
+				output {}
+			`,
+			expect: `match_type must be "regexp" for the "combine" action`,
+		},
+		{
+			name: "unknown operation",
+			cfg: `
+				transform {
+					include = "foo"
+					action  = "update"
+
+					operation {
+						action = "rename_label"
+					}
+				}
+				output {}
+			`,
+			expect: `unsupported operation action "rename_label"`,
+		},
+		{
+			name: "unknown aggregation type",
+			cfg: `
+				transform {
+					include = "foo"
+					action  = "update"
+
+					operation {
+						action           = "aggregate_labels"
+						label_set        = ["state"]
+						aggregation_type = "median"
+					}
+				}
+				output {}
+			`,
+			expect: `unsupported aggregation_type "median"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var args metricstransform.Arguments
+			err := river.Unmarshal([]byte(tc.cfg), &args)
+			require.ErrorContains(t, err, tc.expect)
+		})
+	}
+}
+
+// makeMetricsOutput returns ConsumerArguments which will forward metrics to
+// the provided channel.
+func makeMetricsOutput(ch chan pmetric.Metrics) *otelcol.ConsumerArguments {
+	metricsConsumer := fakeconsumer.Consumer{
+		ConsumeMetricsFunc: func(ctx context.Context, m pmetric.Metrics) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ch <- m:
+				return nil
+			}
+		},
+	}
+
+	return &otelcol.ConsumerArguments{
+		Metrics: []otelcol.Consumer{&metricsConsumer},
+	}
+}
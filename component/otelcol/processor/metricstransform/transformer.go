@@ -0,0 +1,526 @@
+package metricstransform
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// transformer applies transforms to the metrics of each scope, in the order
+// the transforms are defined.
+type transformer struct {
+	transforms []*compiledTransform
+}
+
+type compiledTransform struct {
+	Transform
+	re *regexp.Regexp // Only set for the regexp match type.
+}
+
+// newTransformer creates a transformer from transforms, which must have been
+// validated.
+func newTransformer(transforms []Transform) *transformer {
+	t := &transformer{}
+	for _, tr := range transforms {
+		ct := &compiledTransform{Transform: tr}
+		if tr.MatchType == MatchTypeRegexp {
+			ct.re = regexp.MustCompile(tr.Include)
+		}
+		t.transforms = append(t.transforms, ct)
+	}
+	return t
+}
+
+// processMetrics transforms md in place.
+func (t *transformer) processMetrics(md pmetric.Metrics) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			ms := sms.At(j).Metrics()
+			for _, tr := range t.transforms {
+				tr.apply(ms)
+			}
+		}
+	}
+}
+
+func (tr *compiledTransform) matches(name string) bool {
+	if tr.re != nil {
+		return tr.re.MatchString(name)
+	}
+	return name == tr.Include
+}
+
+// newName returns the name of a matching metric after the transform. New
+// names of regexp transforms may refer to submatches of include, such as $1.
+func (tr *compiledTransform) newName(name string) string {
+	switch {
+	case tr.NewName == "":
+		return name
+	case tr.re != nil:
+		return tr.re.ReplaceAllString(name, tr.NewName)
+	default:
+		return tr.NewName
+	}
+}
+
+func (tr *compiledTransform) apply(ms pmetric.MetricSlice) {
+	if tr.Action == ActionCombine {
+		tr.combine(ms)
+		return
+	}
+
+	// Metrics inserted by the transform are appended to ms, and must not be
+	// transformed again.
+	n := ms.Len()
+	for i := 0; i < n; i++ {
+		m := ms.At(i)
+		if !tr.matches(m.Name()) {
+			continue
+		}
+		if tr.Action == ActionInsert {
+			m.CopyTo(ms.AppendEmpty())
+			m = ms.At(ms.Len() - 1)
+		}
+		m.SetName(tr.newName(m.Name()))
+		for _, op := range tr.Operations {
+			applyOperation(m, op)
+		}
+	}
+}
+
+// combine replaces the metrics matching tr with a single metric. Named
+// submatches of include become labels of the combined data points. Metrics
+// are left unchanged if the matching metrics don't share their type, unit,
+// and temporality.
+func (tr *compiledTransform) combine(ms pmetric.MetricSlice) {
+	var matched []pmetric.Metric
+	for i := 0; i < ms.Len(); i++ {
+		if m := ms.At(i); tr.re.MatchString(m.Name()) {
+			if len(matched) > 0 && !compatible(matched[0], m) {
+				return
+			}
+			matched = append(matched, m)
+		}
+	}
+	if len(matched) == 0 {
+		return
+	}
+
+	combined := newMetricLike(matched[0])
+	combined.SetName(tr.NewName)
+	combined.SetDescription(matched[0].Description())
+	for _, m := range matched {
+		submatches := tr.re.FindStringSubmatch(m.Name())
+		forEachAttributes(m, func(attrs pcommon.Map) {
+			for i, name := range tr.re.SubexpNames() {
+				if name == "" {
+					continue
+				}
+				attrs.PutStr(name, applyCase(submatches[i], tr.SubmatchCase))
+			}
+		})
+		moveDataPoints(m, combined)
+	}
+	ms.RemoveIf(func(m pmetric.Metric) bool {
+		return tr.re.MatchString(m.Name())
+	})
+
+	aggregateDataPoints(combined, tr.AggregationType)
+	for _, op := range tr.Operations {
+		applyOperation(combined, op)
+	}
+	combined.MoveTo(ms.AppendEmpty())
+}
+
+func applyCase(s, submatchCase string) string {
+	switch submatchCase {
+	case SubmatchCaseLower:
+		return strings.ToLower(s)
+	case SubmatchCaseUpper:
+		return strings.ToUpper(s)
+	default:
+		return s
+	}
+}
+
+func applyOperation(m pmetric.Metric, op Operation) {
+	switch op.Action {
+	case OperationAddLabel:
+		forEachAttributes(m, func(attrs pcommon.Map) {
+			attrs.PutStr(op.NewLabel, op.NewValue)
+		})
+
+	case OperationUpdateLabel:
+		forEachAttributes(m, func(attrs pcommon.Map) {
+			v, ok := attrs.Get(op.Label)
+			if !ok {
+				return
+			}
+			for _, va := range op.ValueActions {
+				if v.AsString() == va.Value {
+					v.SetStr(va.NewValue)
+					break
+				}
+			}
+			if op.NewLabel != "" && op.NewLabel != op.Label {
+				v.CopyTo(attrs.PutEmpty(op.NewLabel))
+				attrs.Remove(op.Label)
+			}
+		})
+
+	case OperationDeleteLabelValue:
+		removeDataPoints(m, func(attrs pcommon.Map) bool {
+			v, ok := attrs.Get(op.Label)
+			return ok && v.AsString() == op.LabelValue
+		})
+
+	case OperationToggleScalarDataType:
+		forEachNumberDataPoint(m, func(dp pmetric.NumberDataPoint) {
+			switch dp.ValueType() {
+			case pmetric.NumberDataPointValueTypeInt:
+				dp.SetDoubleValue(float64(dp.IntValue()))
+			case pmetric.NumberDataPointValueTypeDouble:
+				dp.SetIntValue(int64(dp.DoubleValue()))
+			}
+		})
+
+	case OperationScaleValue:
+		forEachNumberDataPoint(m, func(dp pmetric.NumberDataPoint) {
+			switch dp.ValueType() {
+			case pmetric.NumberDataPointValueTypeInt:
+				dp.SetIntValue(int64(float64(dp.IntValue()) * op.Scale))
+			case pmetric.NumberDataPointValueTypeDouble:
+				dp.SetDoubleValue(dp.DoubleValue() * op.Scale)
+			}
+		})
+
+	case OperationAggregateLabels:
+		keep := make(map[string]struct{}, len(op.LabelSet))
+		for _, l := range op.LabelSet {
+			keep[l] = struct{}{}
+		}
+		forEachAttributes(m, func(attrs pcommon.Map) {
+			attrs.RemoveIf(func(k string, _ pcommon.Value) bool {
+				_, ok := keep[k]
+				return !ok
+			})
+		})
+		aggregateDataPoints(m, op.AggregationType)
+
+	case OperationAggregateLabelValues:
+		values := make(map[string]struct{}, len(op.AggregatedValues))
+		for _, v := range op.AggregatedValues {
+			values[v] = struct{}{}
+		}
+		forEachAttributes(m, func(attrs pcommon.Map) {
+			v, ok := attrs.Get(op.Label)
+			if !ok {
+				return
+			}
+			if _, ok := values[v.AsString()]; ok {
+				attrs.PutStr(op.Label, op.NewValue)
+			}
+		})
+		aggregateDataPoints(m, op.AggregationType)
+	}
+}
+
+// compatible returns true if the data points of a and b can be combined into
+// a single metric.
+func compatible(a, b pmetric.Metric) bool {
+	if a.Type() != b.Type() || a.Unit() != b.Unit() {
+		return false
+	}
+	switch a.Type() {
+	case pmetric.MetricTypeSum:
+		return a.Sum().AggregationTemporality() == b.Sum().AggregationTemporality() &&
+			a.Sum().IsMonotonic() == b.Sum().IsMonotonic()
+	case pmetric.MetricTypeHistogram:
+		return a.Histogram().AggregationTemporality() == b.Histogram().AggregationTemporality()
+	case pmetric.MetricTypeExponentialHistogram:
+		return a.ExponentialHistogram().AggregationTemporality() == b.ExponentialHistogram().AggregationTemporality()
+	}
+	return true
+}
+
+// newMetricLike returns an empty metric with the same type, unit, and
+// temporality as m.
+func newMetricLike(m pmetric.Metric) pmetric.Metric {
+	out := pmetric.NewMetric()
+	out.SetUnit(m.Unit())
+
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		out.SetEmptyGauge()
+	case pmetric.MetricTypeSum:
+		sum := out.SetEmptySum()
+		sum.SetAggregationTemporality(m.Sum().AggregationTemporality())
+		sum.SetIsMonotonic(m.Sum().IsMonotonic())
+	case pmetric.MetricTypeHistogram:
+		out.SetEmptyHistogram().SetAggregationTemporality(m.Histogram().AggregationTemporality())
+	case pmetric.MetricTypeExponentialHistogram:
+		out.SetEmptyExponentialHistogram().SetAggregationTemporality(m.ExponentialHistogram().AggregationTemporality())
+	case pmetric.MetricTypeSummary:
+		out.SetEmptySummary()
+	}
+	return out
+}
+
+// moveDataPoints moves the data points of src to dst, which must have the
+// same type.
+func moveDataPoints(src, dst pmetric.Metric) {
+	switch src.Type() {
+	case pmetric.MetricTypeGauge:
+		src.Gauge().DataPoints().MoveAndAppendTo(dst.Gauge().DataPoints())
+	case pmetric.MetricTypeSum:
+		src.Sum().DataPoints().MoveAndAppendTo(dst.Sum().DataPoints())
+	case pmetric.MetricTypeHistogram:
+		src.Histogram().DataPoints().MoveAndAppendTo(dst.Histogram().DataPoints())
+	case pmetric.MetricTypeExponentialHistogram:
+		src.ExponentialHistogram().DataPoints().MoveAndAppendTo(dst.ExponentialHistogram().DataPoints())
+	case pmetric.MetricTypeSummary:
+		src.Summary().DataPoints().MoveAndAppendTo(dst.Summary().DataPoints())
+	}
+}
+
+// forEachAttributes calls f with the attributes of each data point of m.
+func forEachAttributes(m pmetric.Metric, f func(pcommon.Map)) {
+	switch m.Type() {
+	case pmetric.MetricTypeGauge, pmetric.MetricTypeSum:
+		forEachNumberDataPoint(m, func(dp pmetric.NumberDataPoint) { f(dp.Attributes()) })
+	case pmetric.MetricTypeHistogram:
+		dps := m.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			f(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := m.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			f(dps.At(i).Attributes())
+		}
+	case pmetric.MetricTypeSummary:
+		dps := m.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			f(dps.At(i).Attributes())
+		}
+	}
+}
+
+// forEachNumberDataPoint calls f with each data point of m if m is a gauge
+// or a sum.
+func forEachNumberDataPoint(m pmetric.Metric, f func(pmetric.NumberDataPoint)) {
+	dps, ok := numberDataPoints(m)
+	if !ok {
+		return
+	}
+	for i := 0; i < dps.Len(); i++ {
+		f(dps.At(i))
+	}
+}
+
+func numberDataPoints(m pmetric.Metric) (pmetric.NumberDataPointSlice, bool) {
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		return m.Gauge().DataPoints(), true
+	case pmetric.MetricTypeSum:
+		return m.Sum().DataPoints(), true
+	default:
+		return pmetric.NumberDataPointSlice{}, false
+	}
+}
+
+// removeDataPoints removes the data points of m whose attributes match f.
+func removeDataPoints(m pmetric.Metric, f func(pcommon.Map) bool) {
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		m.Gauge().DataPoints().RemoveIf(func(dp pmetric.NumberDataPoint) bool { return f(dp.Attributes()) })
+	case pmetric.MetricTypeSum:
+		m.Sum().DataPoints().RemoveIf(func(dp pmetric.NumberDataPoint) bool { return f(dp.Attributes()) })
+	case pmetric.MetricTypeHistogram:
+		m.Histogram().DataPoints().RemoveIf(func(dp pmetric.HistogramDataPoint) bool { return f(dp.Attributes()) })
+	case pmetric.MetricTypeExponentialHistogram:
+		m.ExponentialHistogram().DataPoints().RemoveIf(func(dp pmetric.ExponentialHistogramDataPoint) bool { return f(dp.Attributes()) })
+	case pmetric.MetricTypeSummary:
+		m.Summary().DataPoints().RemoveIf(func(dp pmetric.SummaryDataPoint) bool { return f(dp.Attributes()) })
+	}
+}
+
+// aggregateDataPoints merges the data points of m which have the same
+// attributes. Gauges and sums support all aggregation types. Histograms are
+// only merged using sum and when their bucket boundaries match. Data points
+// of exponential histograms and summaries aren't merged.
+func aggregateDataPoints(m pmetric.Metric, aggregationType string) {
+	switch m.Type() {
+	case pmetric.MetricTypeGauge, pmetric.MetricTypeSum:
+		dps, _ := numberDataPoints(m)
+		aggregateNumberDataPoints(dps, aggregationType)
+	case pmetric.MetricTypeHistogram:
+		if aggregationType == AggregationSum {
+			aggregateHistogramDataPoints(m.Histogram().DataPoints())
+		}
+	}
+}
+
+func aggregateNumberDataPoints(dps pmetric.NumberDataPointSlice, aggregationType string) {
+	type group struct {
+		dp     pmetric.NumberDataPoint
+		values []float64
+		isInt  bool
+	}
+
+	out := pmetric.NewNumberDataPointSlice()
+	groups := make(map[string]*group)
+	var order []*group
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		key := mapKey(dp.Attributes())
+		g, ok := groups[key]
+		if !ok {
+			g = &group{dp: out.AppendEmpty(), isInt: true}
+			dp.CopyTo(g.dp)
+			groups[key] = g
+			order = append(order, g)
+		} else {
+			mergeTimestamps(g.dp, dp.StartTimestamp(), dp.Timestamp())
+		}
+		g.values = append(g.values, numberValue(dp))
+		g.isInt = g.isInt && dp.ValueType() == pmetric.NumberDataPointValueTypeInt
+	}
+
+	for _, g := range order {
+		v := aggregate(g.values, aggregationType)
+		if g.isInt && aggregationType != AggregationMean {
+			g.dp.SetIntValue(int64(v))
+		} else {
+			g.dp.SetDoubleValue(v)
+		}
+	}
+	out.CopyTo(dps)
+}
+
+func aggregateHistogramDataPoints(dps pmetric.HistogramDataPointSlice) {
+	out := pmetric.NewHistogramDataPointSlice()
+	groups := make(map[string][]pmetric.HistogramDataPoint)
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		key := mapKey(dp.Attributes())
+
+		merged := false
+		for _, dst := range groups[key] {
+			if equalBounds(dst.ExplicitBounds(), dp.ExplicitBounds()) {
+				mergeHistogram(dst, dp)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			dst := out.AppendEmpty()
+			dp.CopyTo(dst)
+			groups[key] = append(groups[key], dst)
+		}
+	}
+	out.CopyTo(dps)
+}
+
+// mergeHistogram adds src to dst, which must have the same bucket
+// boundaries.
+func mergeHistogram(dst, src pmetric.HistogramDataPoint) {
+	dst.SetCount(dst.Count() + src.Count())
+	if src.HasSum() {
+		dst.SetSum(dst.Sum() + src.Sum())
+	}
+	if src.HasMin() && (!dst.HasMin() || src.Min() < dst.Min()) {
+		dst.SetMin(src.Min())
+	}
+	if src.HasMax() && (!dst.HasMax() || src.Max() > dst.Max()) {
+		dst.SetMax(src.Max())
+	}
+
+	dstCounts, srcCounts := dst.BucketCounts(), src.BucketCounts()
+	for i := 0; i < dstCounts.Len() && i < srcCounts.Len(); i++ {
+		dstCounts.SetAt(i, dstCounts.At(i)+srcCounts.At(i))
+	}
+	mergeTimestamps(dst, src.StartTimestamp(), src.Timestamp())
+}
+
+type timestamped interface {
+	StartTimestamp() pcommon.Timestamp
+	SetStartTimestamp(pcommon.Timestamp)
+	Timestamp() pcommon.Timestamp
+	SetTimestamp(pcommon.Timestamp)
+}
+
+// mergeTimestamps extends the time range of dst to include start and ts.
+func mergeTimestamps(dst timestamped, start, ts pcommon.Timestamp) {
+	if ts > dst.Timestamp() {
+		dst.SetTimestamp(ts)
+	}
+	if start != 0 && (dst.StartTimestamp() == 0 || start < dst.StartTimestamp()) {
+		dst.SetStartTimestamp(start)
+	}
+}
+
+func equalBounds(a, b pcommon.Float64Slice) bool {
+	if a.Len() != b.Len() {
+		return false
+	}
+	for i := 0; i < a.Len(); i++ {
+		if a.At(i) != b.At(i) {
+			return false
+		}
+	}
+	return true
+}
+
+func aggregate(values []float64, aggregationType string) float64 {
+	result := values[0]
+	for _, v := range values[1:] {
+		switch aggregationType {
+		case AggregationSum, AggregationMean:
+			result += v
+		case AggregationMin:
+			result = math.Min(result, v)
+		case AggregationMax:
+			result = math.Max(result, v)
+		}
+	}
+	if aggregationType == AggregationMean {
+		result /= float64(len(values))
+	}
+	return result
+}
+
+func numberValue(dp pmetric.NumberDataPoint) float64 {
+	if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		return float64(dp.IntValue())
+	}
+	return dp.DoubleValue()
+}
+
+// mapKey returns a string which uniquely identifies the contents of m.
+func mapKey(m pcommon.Map) string {
+	keys := make([]string, 0, m.Len())
+	m.Range(func(k string, _ pcommon.Value) bool {
+		keys = append(keys, k)
+		return true
+	})
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		v, _ := m.Get(k)
+		sb.WriteString(k)
+		sb.WriteByte(0)
+		sb.WriteString(v.Type().String())
+		sb.WriteByte(0)
+		sb.WriteString(v.AsString())
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
@@ -0,0 +1,262 @@
+package metricstransform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestTransformer_UpdateRegexp(t *testing.T) {
+	md, ms := newMetrics()
+	addGauge(ms, "app.requests", map[string]string{"state": "ok"}, 1)
+	addGauge(ms, "app.errors", map[string]string{"state": "failed"}, 2)
+	addGauge(ms, "other", nil, 3)
+
+	newTransformer([]Transform{{
+		Include:   `^app\.(.*)$`,
+		MatchType: MatchTypeRegexp,
+		Action:    ActionUpdate,
+		NewName:   "app_${1}_total",
+		Operations: []Operation{{
+			Action:       OperationUpdateLabel,
+			Label:        "state",
+			NewLabel:     "status",
+			ValueActions: []ValueAction{{Value: "failed", NewValue: "error"}},
+		}},
+	}}).processMetrics(md)
+
+	require.Equal(t, []string{"app_requests_total", "app_errors_total", "other"}, names(ms))
+	require.Equal(t, map[string]interface{}{"status": "ok"}, gaugeAttrs(ms.At(0), 0))
+	require.Equal(t, map[string]interface{}{"status": "error"}, gaugeAttrs(ms.At(1), 0))
+}
+
+func TestTransformer_Insert(t *testing.T) {
+	md, ms := newMetrics()
+	addGauge(ms, "queue.size", nil, 10)
+
+	newTransformer([]Transform{{
+		Include:   "queue.size",
+		MatchType: MatchTypeStrict,
+		Action:    ActionInsert,
+		NewName:   "queue.size",
+		Operations: []Operation{
+			{Action: OperationAddLabel, NewLabel: "copy", NewValue: "true"},
+			{Action: OperationToggleScalarDataType},
+		},
+	}}).processMetrics(md)
+
+	// The inserted metric isn't transformed again.
+	require.Equal(t, []string{"queue.size", "queue.size"}, names(ms))
+	require.Empty(t, gaugeAttrs(ms.At(0), 0))
+	require.Equal(t, pmetric.NumberDataPointValueTypeInt, ms.At(0).Gauge().DataPoints().At(0).ValueType())
+	require.Equal(t, map[string]interface{}{"copy": "true"}, gaugeAttrs(ms.At(1), 0))
+	require.Equal(t, 10.0, ms.At(1).Gauge().DataPoints().At(0).DoubleValue())
+}
+
+func TestTransformer_Combine(t *testing.T) {
+	md, ms := newMetrics()
+	addGauge(ms, "disk.READ.bytes", map[string]string{"device": "sda"}, 1)
+	addGauge(ms, "disk.WRITE.bytes", map[string]string{"device": "sda"}, 2)
+	addGauge(ms, "disk.WRITE.bytes", map[string]string{"device": "sda"}, 3)
+	addGauge(ms, "memory.bytes", nil, 4)
+
+	newTransformer([]Transform{{
+		Include:         `^disk\.(?P<direction>[A-Z]+)\.bytes$`,
+		MatchType:       MatchTypeRegexp,
+		Action:          ActionCombine,
+		NewName:         "disk.io",
+		AggregationType: AggregationSum,
+		SubmatchCase:    SubmatchCaseLower,
+	}}).processMetrics(md)
+
+	require.Equal(t, []string{"memory.bytes", "disk.io"}, names(ms))
+	dps := ms.At(1).Gauge().DataPoints()
+	require.Equal(t, 2, dps.Len())
+	require.Equal(t, map[string]interface{}{"device": "sda", "direction": "read"}, dps.At(0).Attributes().AsRaw())
+	require.Equal(t, int64(1), dps.At(0).IntValue())
+	require.Equal(t, map[string]interface{}{"device": "sda", "direction": "write"}, dps.At(1).Attributes().AsRaw())
+	require.Equal(t, int64(5), dps.At(1).IntValue())
+}
+
+func TestTransformer_CombineIncompatible(t *testing.T) {
+	md, ms := newMetrics()
+	addGauge(ms, "disk.read", nil, 1)
+	ms.AppendEmpty().SetName("disk.write")
+	ms.At(1).SetEmptySum().DataPoints().AppendEmpty().SetIntValue(2)
+
+	newTransformer([]Transform{{
+		Include:         `^disk\.`,
+		MatchType:       MatchTypeRegexp,
+		Action:          ActionCombine,
+		NewName:         "disk.io",
+		AggregationType: AggregationSum,
+	}}).processMetrics(md)
+
+	require.Equal(t, []string{"disk.read", "disk.write"}, names(ms))
+}
+
+func TestTransformer_Aggregation(t *testing.T) {
+	tt := []struct {
+		aggregationType string
+		expect          float64
+	}{
+		{AggregationSum, 12},
+		{AggregationMean, 4},
+		{AggregationMin, 1},
+		{AggregationMax, 8},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.aggregationType, func(t *testing.T) {
+			md, ms := newMetrics()
+			m := ms.AppendEmpty()
+			m.SetName("cpu.time")
+			dps := m.SetEmptySum().DataPoints()
+			for i, state := range []string{"user", "system", "idle"} {
+				dp := dps.AppendEmpty()
+				dp.Attributes().PutStr("cpu", "0")
+				dp.Attributes().PutStr("state", state)
+				dp.SetTimestamp(pcommon.Timestamp(i + 1))
+				dp.SetDoubleValue([]float64{1, 3, 8}[i])
+			}
+
+			newTransformer([]Transform{{
+				Include:   "cpu.time",
+				MatchType: MatchTypeStrict,
+				Action:    ActionUpdate,
+				Operations: []Operation{{
+					Action:          OperationAggregateLabels,
+					LabelSet:        []string{"cpu"},
+					AggregationType: tc.aggregationType,
+				}},
+			}}).processMetrics(md)
+
+			require.Equal(t, 1, dps.Len())
+			require.Equal(t, map[string]interface{}{"cpu": "0"}, dps.At(0).Attributes().AsRaw())
+			require.Equal(t, tc.expect, dps.At(0).DoubleValue())
+			require.Equal(t, pcommon.Timestamp(3), dps.At(0).Timestamp())
+		})
+	}
+}
+
+func TestTransformer_AggregateLabelValues(t *testing.T) {
+	md, ms := newMetrics()
+	addGauge(ms, "memory.usage", map[string]string{"state": "used"}, 5)
+	m := ms.At(0)
+	for _, state := range []string{"slab_reclaimable", "slab_unreclaimable"} {
+		dp := m.Gauge().DataPoints().AppendEmpty()
+		dp.Attributes().PutStr("state", state)
+		dp.SetIntValue(2)
+	}
+
+	newTransformer([]Transform{{
+		Include:   "memory.usage",
+		MatchType: MatchTypeStrict,
+		Action:    ActionUpdate,
+		Operations: []Operation{{
+			Action:           OperationAggregateLabelValues,
+			Label:            "state",
+			AggregatedValues: []string{"slab_reclaimable", "slab_unreclaimable"},
+			NewValue:         "slab",
+			AggregationType:  AggregationSum,
+		}},
+	}}).processMetrics(md)
+
+	dps := m.Gauge().DataPoints()
+	require.Equal(t, 2, dps.Len())
+	require.Equal(t, map[string]interface{}{"state": "used"}, dps.At(0).Attributes().AsRaw())
+	require.Equal(t, map[string]interface{}{"state": "slab"}, dps.At(1).Attributes().AsRaw())
+	require.Equal(t, int64(4), dps.At(1).IntValue())
+}
+
+func TestTransformer_AggregateHistograms(t *testing.T) {
+	md, ms := newMetrics()
+	m := ms.AppendEmpty()
+	m.SetName("request.duration")
+	dps := m.SetEmptyHistogram().DataPoints()
+	for _, tc := range []struct {
+		route  string
+		bounds []float64
+		counts []uint64
+	}{
+		{"/a", []float64{1}, []uint64{1, 2}},
+		{"/b", []float64{1}, []uint64{3, 4}},
+		{"/c", []float64{5}, []uint64{1, 1}},
+	} {
+		dp := dps.AppendEmpty()
+		dp.Attributes().PutStr("route", tc.route)
+		dp.ExplicitBounds().FromRaw(tc.bounds)
+		dp.BucketCounts().FromRaw(tc.counts)
+		dp.SetCount(tc.counts[0] + tc.counts[1])
+		dp.SetSum(1)
+	}
+
+	newTransformer([]Transform{{
+		Include:   "request.duration",
+		MatchType: MatchTypeStrict,
+		Action:    ActionUpdate,
+		Operations: []Operation{{
+			Action:          OperationAggregateLabels,
+			AggregationType: AggregationSum,
+		}},
+	}}).processMetrics(md)
+
+	// Data points with different bucket boundaries can't be merged.
+	require.Equal(t, 2, dps.Len())
+	require.Equal(t, []uint64{4, 6}, dps.At(0).BucketCounts().AsRaw())
+	require.Equal(t, uint64(10), dps.At(0).Count())
+	require.Equal(t, 2.0, dps.At(0).Sum())
+	require.Equal(t, []float64{5}, dps.At(1).ExplicitBounds().AsRaw())
+}
+
+func TestTransformer_DeleteLabelValue(t *testing.T) {
+	md, ms := newMetrics()
+	addGauge(ms, "cpu.usage", map[string]string{"state": "idle"}, 1)
+	dp := ms.At(0).Gauge().DataPoints().AppendEmpty()
+	dp.Attributes().PutStr("state", "user")
+	dp.SetIntValue(2)
+
+	newTransformer([]Transform{{
+		Include:   "cpu.usage",
+		MatchType: MatchTypeStrict,
+		Action:    ActionUpdate,
+		Operations: []Operation{{
+			Action:     OperationDeleteLabelValue,
+			Label:      "state",
+			LabelValue: "idle",
+		}},
+	}}).processMetrics(md)
+
+	require.Equal(t, 1, ms.At(0).Gauge().DataPoints().Len())
+	require.Equal(t, map[string]interface{}{"state": "user"}, gaugeAttrs(ms.At(0), 0))
+}
+
+func newMetrics() (pmetric.Metrics, pmetric.MetricSlice) {
+	md := pmetric.NewMetrics()
+	ms := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	return md, ms
+}
+
+func addGauge(ms pmetric.MetricSlice, name string, attrs map[string]string, v int64) {
+	m := ms.AppendEmpty()
+	m.SetName(name)
+	dp := m.SetEmptyGauge().DataPoints().AppendEmpty()
+	for k, v := range attrs {
+		dp.Attributes().PutStr(k, v)
+	}
+	dp.SetIntValue(v)
+}
+
+func names(ms pmetric.MetricSlice) []string {
+	var names []string
+	for i := 0; i < ms.Len(); i++ {
+		names = append(names, ms.At(i).Name())
+	}
+	return names
+}
+
+func gaugeAttrs(m pmetric.Metric, i int) map[string]interface{} {
+	return m.Gauge().DataPoints().At(i).Attributes().AsRaw()
+}
@@ -45,6 +45,21 @@ type Arguments interface {
 	NextConsumers() *otelcol.ConsumerArguments
 }
 
+// SignalArguments is an optional extension of Arguments for processors which
+// may be restricted to a subset of telemetry signals. Processors are only
+// created for the returned signals; telemetry of any other signal sent to the
+// component is rejected.
+//
+// Arguments which do not implement SignalArguments are created for every
+// signal supported by the processor factory.
+type SignalArguments interface {
+	Arguments
+
+	// EnabledSignals returns the set of telemetry signals the processor
+	// should be created for.
+	EnabledSignals() []otelconfig.DataType
+}
+
 // Processor is a Flow component shim which manages an OpenTelemetry Collector
 // processor component.
 type Processor struct {
@@ -159,27 +174,41 @@ func (p *Processor) Update(args component.Arguments) error {
 
 	// Create instances of the processor from our factory for each of our
 	// supported telemetry signals.
-	var components []otelcomponent.Component
+	var (
+		components []otelcomponent.Component
 
-	tracesProcessor, err := p.factory.CreateTracesProcessor(p.ctx, settings, processorConfig, nextTraces)
-	if err != nil && !errors.Is(err, otelcomponent.ErrDataTypeIsNotSupported) {
-		return err
-	} else if tracesProcessor != nil {
-		components = append(components, tracesProcessor)
+		tracesProcessor  otelcomponent.TracesProcessor
+		metricsProcessor otelcomponent.MetricsProcessor
+		logsProcessor    otelcomponent.LogsProcessor
+
+		signals = signalSet(pargs)
+	)
+
+	if signals[otelconfig.TracesDataType] {
+		tracesProcessor, err = p.factory.CreateTracesProcessor(p.ctx, settings, processorConfig, nextTraces)
+		if err != nil && !errors.Is(err, otelcomponent.ErrDataTypeIsNotSupported) {
+			return err
+		} else if tracesProcessor != nil {
+			components = append(components, tracesProcessor)
+		}
 	}
 
-	metricsProcessor, err := p.factory.CreateMetricsProcessor(p.ctx, settings, processorConfig, nextMetrics)
-	if err != nil && !errors.Is(err, otelcomponent.ErrDataTypeIsNotSupported) {
-		return err
-	} else if metricsProcessor != nil {
-		components = append(components, metricsProcessor)
+	if signals[otelconfig.MetricsDataType] {
+		metricsProcessor, err = p.factory.CreateMetricsProcessor(p.ctx, settings, processorConfig, nextMetrics)
+		if err != nil && !errors.Is(err, otelcomponent.ErrDataTypeIsNotSupported) {
+			return err
+		} else if metricsProcessor != nil {
+			components = append(components, metricsProcessor)
+		}
 	}
 
-	logsProcessor, err := p.factory.CreateLogsProcessor(p.ctx, settings, processorConfig, nextLogs)
-	if err != nil && !errors.Is(err, otelcomponent.ErrDataTypeIsNotSupported) {
-		return err
-	} else if logsProcessor != nil {
-		components = append(components, logsProcessor)
+	if signals[otelconfig.LogsDataType] {
+		logsProcessor, err = p.factory.CreateLogsProcessor(p.ctx, settings, processorConfig, nextLogs)
+		if err != nil && !errors.Is(err, otelcomponent.ErrDataTypeIsNotSupported) {
+			return err
+		} else if logsProcessor != nil {
+			components = append(components, logsProcessor)
+		}
 	}
 
 	// Schedule the components to run once our component is running.
@@ -188,6 +217,24 @@ func (p *Processor) Update(args component.Arguments) error {
 	return nil
 }
 
+// signalSet returns the set of signals processors should be created for.
+func signalSet(args Arguments) map[otelconfig.DataType]bool {
+	sargs, ok := args.(SignalArguments)
+	if !ok {
+		return map[otelconfig.DataType]bool{
+			otelconfig.TracesDataType:  true,
+			otelconfig.MetricsDataType: true,
+			otelconfig.LogsDataType:    true,
+		}
+	}
+
+	res := make(map[otelconfig.DataType]bool)
+	for _, signal := range sargs.EnabledSignals() {
+		res[signal] = true
+	}
+	return res
+}
+
 // CurrentHealth implements component.HealthComponent.
 func (p *Processor) CurrentHealth() component.Health {
 	return p.sched.CurrentHealth()
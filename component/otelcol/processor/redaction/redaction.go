@@ -0,0 +1,154 @@
+// Package redaction provides an otelcol.processor.redaction component.
+package redaction
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/internal/fanoutconsumer"
+	"github.com/grafana/agent/component/otelcol/internal/lazyconsumer"
+	"github.com/grafana/agent/pkg/river"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "otelcol.processor.redaction",
+		Args:    Arguments{},
+		Exports: otelcol.ConsumerExports{},
+
+		Build: func(o component.Options, a component.Arguments) (component.Component, error) {
+			return New(o, a.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.processor.redaction component.
+type Arguments struct {
+	AllowAllKeys  bool     `river:"allow_all_keys,attr,optional"`
+	AllowedKeys   []string `river:"allowed_keys,attr,optional"`
+	IgnoredKeys   []string `river:"ignored_keys,attr,optional"`
+	BlockedValues []string `river:"blocked_values,attr,optional"`
+	Summary       string   `river:"summary,attr,optional"`
+
+	// Output configures where to send processed data. Required.
+	Output *otelcol.ConsumerArguments `river:"output,block"`
+}
+
+var _ river.Unmarshaler = (*Arguments)(nil)
+
+// DefaultArguments holds default settings for otelcol.processor.redaction.
+var DefaultArguments = Arguments{
+	Summary: SummaryInfo,
+}
+
+// UnmarshalRiver implements river.Unmarshaler and applies defaults.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	for _, v := range args.BlockedValues {
+		if _, err := regexp.Compile(v); err != nil {
+			return fmt.Errorf("invalid blocked value %q: %w", v, err)
+		}
+	}
+
+	switch args.Summary {
+	case SummaryDebug, SummaryInfo, SummarySilent:
+	default:
+		return fmt.Errorf("unsupported summary %q, expected one of %q, %q, or %q",
+			args.Summary, SummaryDebug, SummaryInfo, SummarySilent)
+	}
+
+	return nil
+}
+
+// Component is the otelcol.processor.redaction component.
+type Component struct {
+	mut        sync.RWMutex
+	redactor   *redactor
+	nextTraces otelconsumer.Traces
+	nextLogs   otelconsumer.Logs
+}
+
+var (
+	_ component.Component = (*Component)(nil)
+	_ otelconsumer.Traces = (*Component)(nil)
+	_ otelconsumer.Logs   = (*Component)(nil)
+)
+
+// New creates a new otelcol.processor.redaction component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{}
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+
+	// The exported consumer remains the same throughout the component's
+	// lifetime, so we export it during component construction. Only traces
+	// and logs are supported.
+	export := lazyconsumer.New(context.Background())
+	export.SetConsumers(c, nil, c)
+	o.OnStateChange(otelcol.ConsumerExports{Input: export})
+
+	return c, nil
+}
+
+// Run implements Component.
+func (c *Component) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// Update implements Component.
+func (c *Component) Update(newArgs component.Arguments) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	args := newArgs.(Arguments)
+	c.redactor = newRedactor(args)
+
+	var nextTraces, nextLogs []otelcol.Consumer
+	if args.Output != nil {
+		nextTraces = args.Output.Traces
+		nextLogs = args.Output.Logs
+	}
+	c.nextTraces = fanoutconsumer.Traces(nextTraces)
+	c.nextLogs = fanoutconsumer.Logs(nextLogs)
+	return nil
+}
+
+// Capabilities implements otelconsumer.Traces and otelconsumer.Logs.
+func (c *Component) Capabilities() otelconsumer.Capabilities {
+	return otelconsumer.Capabilities{MutatesData: true}
+}
+
+// ConsumeTraces implements otelconsumer.Traces.
+func (c *Component) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	c.mut.RLock()
+	redactor, next := c.redactor, c.nextTraces
+	c.mut.RUnlock()
+
+	redactor.processTraces(td)
+	return next.ConsumeTraces(ctx, td)
+}
+
+// ConsumeLogs implements otelconsumer.Logs.
+func (c *Component) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	c.mut.RLock()
+	redactor, next := c.redactor, c.nextLogs
+	c.mut.RUnlock()
+
+	redactor.processLogs(ld)
+	return next.ConsumeLogs(ctx, ld)
+}
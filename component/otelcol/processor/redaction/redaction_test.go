@@ -0,0 +1,130 @@
+package redaction_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/internal/fakeconsumer"
+	"github.com/grafana/agent/component/otelcol/processor/redaction"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// Test performs a basic integration test which runs the
+// otelcol.processor.redaction component and ensures that it redacts and
+// forwards data.
+func Test(t *testing.T) {
+	ctx := componenttest.TestContext(t)
+	l := util.TestLogger(t)
+
+	ctrl, err := componenttest.NewControllerFromID(l, "otelcol.processor.redaction")
+	require.NoError(t, err)
+
+	cfg := `
+		allowed_keys   = ["http.method", "user.email"]
+		blocked_values = ["[a-z]+@example\\.com"]
+		summary        = "debug"
+
+		output {
+			// no-op: will be overridden by test code.
+		}
+	`
+	var args redaction.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	// Override our arguments so traces get forwarded to traceCh.
+	traceCh := make(chan ptrace.Traces)
+	args.Output = makeTracesOutput(traceCh)
+
+	go func() {
+		err := ctrl.Run(ctx, args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, ctrl.WaitRunning(time.Second), "component never started")
+	require.NoError(t, ctrl.WaitExports(time.Second), "component never exported anything")
+
+	td := ptrace.NewTraces()
+	span := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetName("test_span")
+	span.Attributes().PutStr("http.method", "GET")
+	span.Attributes().PutStr("user.email", "contact: jane@example.com")
+	span.Attributes().PutStr("user.password", "hunter2")
+
+	exports := ctrl.Exports().(otelcol.ConsumerExports)
+	go func() {
+		require.NoError(t, exports.Input.ConsumeTraces(ctx, td))
+	}()
+
+	select {
+	case <-time.After(time.Second):
+		require.FailNow(t, "failed waiting for traces")
+	case td := <-traceCh:
+		attrs := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Attributes()
+		require.Equal(t, map[string]interface{}{
+			"http.method":              "GET",
+			"user.email":               "contact: ****",
+			"redaction.redacted.keys":  "user.password",
+			"redaction.redacted.count": int64(1),
+			"redaction.masked.keys":    "user.email",
+			"redaction.masked.count":   int64(1),
+		}, attrs.AsRaw())
+	}
+}
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	tt := []struct {
+		name   string
+		cfg    string
+		expect string
+	}{
+		{
+			name: "invalid blocked value",
+			cfg: `
+				blocked_values = ["(unclosed"]
+				output {}
+			`,
+			expect: `invalid blocked value "(unclosed"`,
+		},
+		{
+			name: "unknown summary",
+			cfg: `
+				summary = "verbose"
+				output {}
+			`,
+			expect: `unsupported summary "verbose"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var args redaction.Arguments
+			err := river.Unmarshal([]byte(tc.cfg), &args)
+			require.ErrorContains(t, err, tc.expect)
+		})
+	}
+}
+
+// makeTracesOutput returns ConsumerArguments which will forward traces to the
+// provided channel.
+func makeTracesOutput(ch chan ptrace.Traces) *otelcol.ConsumerArguments {
+	traceConsumer := fakeconsumer.Consumer{
+		ConsumeTracesFunc: func(ctx context.Context, t ptrace.Traces) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ch <- t:
+				return nil
+			}
+		},
+	}
+
+	return &otelcol.ConsumerArguments{
+		Traces: []otelcol.Consumer{&traceConsumer},
+	}
+}
@@ -0,0 +1,165 @@
+package redaction
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// Supported levels of summary attributes.
+const (
+	SummaryDebug  = "debug"
+	SummaryInfo   = "info"
+	SummarySilent = "silent"
+)
+
+// Names of the summary attributes added to redacted attributes.
+const (
+	redactedKeys  = "redaction.redacted.keys"
+	redactedCount = "redaction.redacted.count"
+	maskedKeys    = "redaction.masked.keys"
+	maskedCount   = "redaction.masked.count"
+)
+
+// mask replaces the parts of attribute values matching a blocked value.
+const mask = "****"
+
+// redactor removes attributes which aren't allowed and masks blocked values
+// in the attributes of resources, spans, and log records.
+type redactor struct {
+	allowAllKeys  bool
+	allowedKeys   map[string]struct{}
+	ignoredKeys   map[string]struct{}
+	blockedValues []*regexp.Regexp
+	summary       string
+}
+
+// newRedactor creates a redactor from args, which must have been validated.
+func newRedactor(args Arguments) *redactor {
+	r := &redactor{
+		allowAllKeys: args.AllowAllKeys,
+		allowedKeys:  toSet(args.AllowedKeys),
+		ignoredKeys:  toSet(args.IgnoredKeys),
+		summary:      args.Summary,
+	}
+	for _, v := range args.BlockedValues {
+		r.blockedValues = append(r.blockedValues, regexp.MustCompile(v))
+	}
+	return r
+}
+
+func toSet(keys []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		set[k] = struct{}{}
+	}
+	return set
+}
+
+// processTraces redacts the attributes of the resources and spans of td in
+// place.
+func (r *redactor) processTraces(td ptrace.Traces) {
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		r.processAttributes(rs.Resource().Attributes())
+
+		sss := rs.ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				r.processAttributes(spans.At(k).Attributes())
+			}
+		}
+	}
+}
+
+// processLogs redacts the attributes of the resources and log records of ld
+// in place.
+func (r *redactor) processLogs(ld plog.Logs) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		r.processAttributes(rl.Resource().Attributes())
+
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			records := sls.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				r.processAttributes(records.At(k).Attributes())
+			}
+		}
+	}
+}
+
+// processAttributes removes the attributes which aren't allowed, masks the
+// blocked values of the remaining attributes, and then adds the summary of
+// what was changed. Ignored keys are left untouched.
+func (r *redactor) processAttributes(attrs pcommon.Map) {
+	var redacted, masked []string
+
+	attrs.RemoveIf(func(k string, _ pcommon.Value) bool {
+		if r.ignored(k) || r.allowAllKeys {
+			return false
+		}
+		if _, ok := r.allowedKeys[k]; ok {
+			return false
+		}
+		redacted = append(redacted, k)
+		return true
+	})
+
+	if len(r.blockedValues) > 0 {
+		attrs.Range(func(k string, v pcommon.Value) bool {
+			if r.ignored(k) {
+				return true
+			}
+			if s, ok := r.maskValue(v.AsString()); ok {
+				v.SetStr(s)
+				masked = append(masked, k)
+			}
+			return true
+		})
+	}
+
+	r.addSummary(attrs, redactedKeys, redactedCount, redacted)
+	r.addSummary(attrs, maskedKeys, maskedCount, masked)
+}
+
+func (r *redactor) ignored(k string) bool {
+	_, ok := r.ignoredKeys[k]
+	return ok
+}
+
+// maskValue masks the parts of s matching any of the blocked values. It
+// returns false if nothing was masked.
+func (r *redactor) maskValue(s string) (string, bool) {
+	var changed bool
+	for _, re := range r.blockedValues {
+		if re.MatchString(s) {
+			s = re.ReplaceAllString(s, mask)
+			changed = true
+		}
+	}
+	return s, changed
+}
+
+// addSummary adds the number of keys to attrs, along with the sorted list of
+// keys for the debug summary.
+func (r *redactor) addSummary(attrs pcommon.Map, keysAttr, countAttr string, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	switch r.summary {
+	case SummaryDebug:
+		sort.Strings(keys)
+		attrs.PutStr(keysAttr, strings.Join(keys, ","))
+		attrs.PutInt(countAttr, int64(len(keys)))
+	case SummaryInfo:
+		attrs.PutInt(countAttr, int64(len(keys)))
+	}
+}
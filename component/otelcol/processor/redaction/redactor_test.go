@@ -0,0 +1,72 @@
+package redaction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestRedactor_Logs(t *testing.T) {
+	tt := []struct {
+		name   string
+		args   Arguments
+		expect map[string]interface{}
+	}{
+		{
+			name: "info summary",
+			args: Arguments{
+				AllowedKeys:   []string{"card"},
+				IgnoredKeys:   []string{"trace_id"},
+				BlockedValues: []string{`\d{4}-\d{4}`},
+				Summary:       SummaryInfo,
+			},
+			expect: map[string]interface{}{
+				"card":                     "****-5678",
+				"trace_id":                 "1234-5678-abcd",
+				"redaction.redacted.count": int64(1),
+				"redaction.masked.count":   int64(1),
+			},
+		},
+		{
+			name: "allow all keys",
+			args: Arguments{
+				AllowAllKeys: true,
+				Summary:      SummaryDebug,
+			},
+			expect: map[string]interface{}{
+				"card":     "1234-5678-5678",
+				"trace_id": "1234-5678-abcd",
+				"secret":   "s3cr3t",
+			},
+		},
+		{
+			name: "silent summary",
+			args: Arguments{
+				Summary: SummarySilent,
+			},
+			expect: map[string]interface{}{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ld := plog.NewLogs()
+			rl := ld.ResourceLogs().AppendEmpty()
+			rl.Resource().Attributes().PutStr("secret", "s3cr3t")
+			record := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+			record.Attributes().PutStr("card", "1234-5678-5678")
+			record.Attributes().PutStr("trace_id", "1234-5678-abcd")
+			record.Attributes().PutStr("secret", "s3cr3t")
+
+			newRedactor(tc.args).processLogs(ld)
+
+			resourceAttrs := ld.ResourceLogs().At(0).Resource().Attributes()
+			_, kept := resourceAttrs.Get("secret")
+			require.Equal(t, tc.args.AllowAllKeys, kept)
+
+			attrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+			require.Equal(t, tc.expect, attrs.AsRaw())
+		})
+	}
+}
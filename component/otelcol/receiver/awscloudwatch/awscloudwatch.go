@@ -0,0 +1,114 @@
+// Package awscloudwatch provides an otelcol.receiver.awscloudwatch component.
+package awscloudwatch
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/receiver"
+	"github.com/grafana/agent/pkg/river"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name: "otelcol.receiver.awscloudwatch",
+		Args: Arguments{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return receiver.New(opts, newFactory(opts.DataPath), args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.receiver.awscloudwatch component.
+type Arguments struct {
+	Region              string        `river:"region,attr"`
+	Profile             string        `river:"profile,attr,optional"`
+	Endpoint            string        `river:"endpoint,attr,optional"`
+	PollInterval        time.Duration `river:"poll_interval,attr,optional"`
+	MaxEventsPerRequest int           `river:"max_events_per_request,attr,optional"`
+	StartFrom           string        `river:"start_from,attr,optional"`
+
+	LogGroups []LogGroupArguments `river:"log_group,block"`
+
+	// Output configures where to send received data. Required.
+	Output *otelcol.ConsumerArguments `river:"output,block"`
+}
+
+// LogGroupArguments selects log groups and the streams to read from them.
+type LogGroupArguments struct {
+	Name         string   `river:"name,attr,optional"`
+	NamePrefix   string   `river:"name_prefix,attr,optional"`
+	StreamNames  []string `river:"stream_names,attr,optional"`
+	StreamPrefix string   `river:"stream_prefix,attr,optional"`
+}
+
+var (
+	_ receiver.Arguments = Arguments{}
+	_ river.Unmarshaler  = (*Arguments)(nil)
+)
+
+// DefaultArguments holds default settings for otelcol.receiver.awscloudwatch.
+var DefaultArguments = Arguments{
+	PollInterval:        time.Minute,
+	MaxEventsPerRequest: 1000,
+	StartFrom:           StartFromEnd,
+}
+
+// UnmarshalRiver applies defaults to args before unmarshaling.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	_, err := args.Convert()
+	return err
+}
+
+// Convert implements receiver.Arguments.
+func (args Arguments) Convert() (otelconfig.Receiver, error) {
+	cfg := createDefaultConfig().(*Config)
+
+	cfg.Region = args.Region
+	cfg.Profile = args.Profile
+	cfg.Endpoint = args.Endpoint
+	cfg.PollInterval = args.PollInterval
+	cfg.MaxEventsPerRequest = args.MaxEventsPerRequest
+	cfg.StartFrom = args.StartFrom
+
+	for _, g := range args.LogGroups {
+		cfg.Groups = append(cfg.Groups, GroupConfig{
+			Name:         g.Name,
+			NamePrefix:   g.NamePrefix,
+			StreamNames:  g.StreamNames,
+			StreamPrefix: g.StreamPrefix,
+		})
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// Extensions implements receiver.Arguments.
+func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return nil
+}
+
+// Exporters implements receiver.Arguments.
+func (args Arguments) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
+
+// NextConsumers implements receiver.Arguments.
+func (args Arguments) NextConsumers() *otelcol.ConsumerArguments {
+	return args.Output
+}
@@ -0,0 +1,90 @@
+package awscloudwatch_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/otelcol/receiver/awscloudwatch"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	tt := []struct {
+		name   string
+		cfg    string
+		expect string
+	}{
+		{
+			name: "no name or prefix",
+			cfg: `
+				region = "us-east-1"
+				log_group {}
+				output {}
+			`,
+			expect: "exactly one of name or name_prefix must be specified",
+		},
+		{
+			name: "stream names and prefix",
+			cfg: `
+				region = "us-east-1"
+				log_group {
+					name          = "group"
+					stream_names  = ["a"]
+					stream_prefix = "b"
+				}
+				output {}
+			`,
+			expect: "stream_names and stream_prefix are mutually exclusive",
+		},
+		{
+			name: "unknown start position",
+			cfg: `
+				region     = "us-east-1"
+				start_from = "middle"
+				log_group {
+					name = "group"
+				}
+				output {}
+			`,
+			expect: `unsupported start_from "middle"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var args awscloudwatch.Arguments
+			err := river.Unmarshal([]byte(tc.cfg), &args)
+			require.ErrorContains(t, err, tc.expect)
+		})
+	}
+}
+
+func TestArguments_Convert(t *testing.T) {
+	cfg := `
+		region        = "eu-west-1"
+		poll_interval = "30s"
+
+		log_group {
+			name_prefix  = "/aws/lambda/"
+			stream_names = ["a", "b"]
+		}
+
+		output {}
+	`
+	var args awscloudwatch.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	out, err := args.Convert()
+	require.NoError(t, err)
+
+	conf := out.(*awscloudwatch.Config)
+	require.Equal(t, "eu-west-1", conf.Region)
+	require.Equal(t, 30*time.Second, conf.PollInterval)
+	require.Equal(t, 1000, conf.MaxEventsPerRequest)
+	require.Equal(t, awscloudwatch.StartFromEnd, conf.StartFrom)
+	require.Equal(t, []awscloudwatch.GroupConfig{{
+		NamePrefix:  "/aws/lambda/",
+		StreamNames: []string{"a", "b"},
+	}}, conf.Groups)
+}
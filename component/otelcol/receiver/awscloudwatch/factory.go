@@ -0,0 +1,108 @@
+package awscloudwatch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+)
+
+// typeStr is the type of the receiver.
+const typeStr = "awscloudwatch"
+
+// Positions to start reading log groups from when no checkpoint exists.
+const (
+	StartFromBeginning = "beginning"
+	StartFromEnd       = "end"
+)
+
+// Config configures the CloudWatch Logs receiver.
+type Config struct {
+	otelconfig.ReceiverSettings `mapstructure:",squash"`
+
+	Region   string `mapstructure:"region"`
+	Profile  string `mapstructure:"profile"`
+	Endpoint string `mapstructure:"endpoint"`
+
+	PollInterval        time.Duration `mapstructure:"poll_interval"`
+	MaxEventsPerRequest int           `mapstructure:"max_events_per_request"`
+	StartFrom           string        `mapstructure:"start_from"`
+
+	Groups []GroupConfig `mapstructure:"groups"`
+}
+
+// GroupConfig selects log groups and the streams within them to read.
+type GroupConfig struct {
+	// Exactly one of Name or NamePrefix must be set.
+	Name       string `mapstructure:"name"`
+	NamePrefix string `mapstructure:"name_prefix"`
+
+	// At most one of StreamNames or StreamPrefix may be set. All streams are
+	// read if neither is set.
+	StreamNames  []string `mapstructure:"stream_names"`
+	StreamPrefix string   `mapstructure:"stream_prefix"`
+}
+
+var _ otelconfig.Receiver = (*Config)(nil)
+
+// Validate checks that cfg is valid.
+func (cfg *Config) Validate() error {
+	if cfg.Region == "" {
+		return fmt.Errorf("region must be specified")
+	}
+	if cfg.PollInterval <= 0 {
+		return fmt.Errorf("poll_interval must be greater than 0")
+	}
+	if cfg.MaxEventsPerRequest <= 0 || cfg.MaxEventsPerRequest > 10000 {
+		return fmt.Errorf("max_events_per_request must be between 1 and 10000")
+	}
+	switch cfg.StartFrom {
+	case StartFromBeginning, StartFromEnd:
+	default:
+		return fmt.Errorf("unsupported start_from %q, expected %q or %q", cfg.StartFrom, StartFromBeginning, StartFromEnd)
+	}
+	if len(cfg.Groups) == 0 {
+		return fmt.Errorf("at least one log group must be specified")
+	}
+	for _, g := range cfg.Groups {
+		if (g.Name == "") == (g.NamePrefix == "") {
+			return fmt.Errorf("exactly one of name or name_prefix must be specified for a log group")
+		}
+		if len(g.StreamNames) > 0 && g.StreamPrefix != "" {
+			return fmt.Errorf("stream_names and stream_prefix are mutually exclusive")
+		}
+	}
+	return nil
+}
+
+// newFactory creates a receiver factory for CloudWatch Logs receivers. Read
+// positions are persisted in storageDir; they aren't persisted if storageDir
+// is empty.
+func newFactory(storageDir string) otelcomponent.ReceiverFactory {
+	createLogsReceiver := func(
+		_ context.Context,
+		set otelcomponent.ReceiverCreateSettings,
+		cfg otelconfig.Receiver,
+		next otelconsumer.Logs,
+	) (otelcomponent.LogsReceiver, error) {
+		return newLogsReceiver(set.Logger, cfg.(*Config), storageDir, next), nil
+	}
+
+	return otelcomponent.NewReceiverFactory(
+		typeStr,
+		createDefaultConfig,
+		otelcomponent.WithLogsReceiver(createLogsReceiver, otelcomponent.StabilityLevelAlpha),
+	)
+}
+
+func createDefaultConfig() otelconfig.Receiver {
+	return &Config{
+		ReceiverSettings:    otelconfig.NewReceiverSettings(otelconfig.NewComponentID(typeStr)),
+		PollInterval:        time.Minute,
+		MaxEventsPerRequest: 1000,
+		StartFrom:           StartFromEnd,
+	}
+}
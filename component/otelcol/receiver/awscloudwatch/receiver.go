@@ -0,0 +1,284 @@
+package awscloudwatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// Resource attributes set on emitted logs.
+const (
+	attrLogGroup  = "cloudwatch.log.group.name"
+	attrLogStream = "cloudwatch.log.stream"
+	attrRegion    = "cloud.region"
+	attrEventID   = "id"
+)
+
+const checkpointsFilename = "checkpoints.json"
+
+// logsReceiver periodically pulls log events from CloudWatch Logs.
+type logsReceiver struct {
+	log        *zap.Logger
+	cfg        *Config
+	storageDir string
+	next       otelconsumer.Logs
+
+	// newClient creates the CloudWatch Logs client. Overridden in tests.
+	newClient func(cfg *Config) (cloudwatchlogsiface.CloudWatchLogsAPI, error)
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// checkpoints holds the timestamp in milliseconds of the newest event read
+	// from each log group.
+	checkpoints map[string]int64
+}
+
+var _ otelcomponent.LogsReceiver = (*logsReceiver)(nil)
+
+func newLogsReceiver(log *zap.Logger, cfg *Config, storageDir string, next otelconsumer.Logs) *logsReceiver {
+	return &logsReceiver{
+		log:         log,
+		cfg:         cfg,
+		storageDir:  storageDir,
+		next:        next,
+		newClient:   newClient,
+		checkpoints: make(map[string]int64),
+	}
+}
+
+func newClient(cfg *Config) (cloudwatchlogsiface.CloudWatchLogsAPI, error) {
+	awsConfig := aws.NewConfig().WithRegion(cfg.Region)
+	if cfg.Endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(cfg.Endpoint)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *awsConfig,
+		Profile:           cfg.Profile,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	return cloudwatchlogs.New(sess), nil
+}
+
+// Start implements otelcomponent.Component.
+func (r *logsReceiver) Start(_ context.Context, _ otelcomponent.Host) error {
+	client, err := r.newClient(r.cfg)
+	if err != nil {
+		return err
+	}
+	if err := r.loadCheckpoints(); err != nil {
+		r.log.Warn("failed to load read positions, starting from the configured position", zap.Error(err))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.run(ctx, client)
+	}()
+	return nil
+}
+
+// Shutdown implements otelcomponent.Component.
+func (r *logsReceiver) Shutdown(_ context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	return nil
+}
+
+func (r *logsReceiver) run(ctx context.Context, client cloudwatchlogsiface.CloudWatchLogsAPI) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		r.poll(ctx, client, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll reads all events up to now from the configured log groups.
+func (r *logsReceiver) poll(ctx context.Context, client cloudwatchlogsiface.CloudWatchLogsAPI, now time.Time) {
+	for _, group := range r.cfg.Groups {
+		names, err := r.groupNames(ctx, client, group)
+		if err != nil {
+			r.log.Error("failed to list log groups", zap.String("prefix", group.NamePrefix), zap.Error(err))
+			continue
+		}
+
+		for _, name := range names {
+			if err := r.pollGroup(ctx, client, name, group, now); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				r.log.Error("failed to read log events", zap.String("log_group", name), zap.Error(err))
+			}
+		}
+	}
+
+	if err := r.saveCheckpoints(); err != nil {
+		r.log.Error("failed to persist read positions", zap.Error(err))
+	}
+}
+
+// groupNames returns the names of the log groups selected by group.
+func (r *logsReceiver) groupNames(ctx context.Context, client cloudwatchlogsiface.CloudWatchLogsAPI, group GroupConfig) ([]string, error) {
+	if group.Name != "" {
+		return []string{group.Name}, nil
+	}
+
+	var names []string
+	err := client.DescribeLogGroupsPagesWithContext(ctx, &cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: aws.String(group.NamePrefix),
+	}, func(out *cloudwatchlogs.DescribeLogGroupsOutput, _ bool) bool {
+		for _, g := range out.LogGroups {
+			names = append(names, aws.StringValue(g.LogGroupName))
+		}
+		return true
+	})
+	return names, err
+}
+
+// pollGroup reads events from a single log group which are newer than its
+// checkpoint and sends them to the next consumer one page at a time.
+func (r *logsReceiver) pollGroup(ctx context.Context, client cloudwatchlogsiface.CloudWatchLogsAPI, name string, group GroupConfig, now time.Time) error {
+	start, ok := r.checkpoints[name]
+	if !ok {
+		if r.cfg.StartFrom == StartFromEnd {
+			// There's nothing to read yet; start reading from now on the next
+			// poll.
+			r.checkpoints[name] = now.UnixMilli()
+			return nil
+		}
+		start = -1
+	}
+
+	input := &cloudwatchlogs.FilterLogEventsInput{
+		LogGroupName: aws.String(name),
+		StartTime:    aws.Int64(start + 1),
+		EndTime:      aws.Int64(now.UnixMilli()),
+		Limit:        aws.Int64(int64(r.cfg.MaxEventsPerRequest)),
+	}
+	if len(group.StreamNames) > 0 {
+		input.LogStreamNames = aws.StringSlice(group.StreamNames)
+	}
+	if group.StreamPrefix != "" {
+		input.LogStreamNamePrefix = aws.String(group.StreamPrefix)
+	}
+
+	var consumeErr error
+	err := client.FilterLogEventsPagesWithContext(ctx, input, func(out *cloudwatchlogs.FilterLogEventsOutput, _ bool) bool {
+		if len(out.Events) == 0 {
+			return true
+		}
+
+		logs, newest := r.convertEvents(name, out.Events)
+		if consumeErr = r.next.ConsumeLogs(ctx, logs); consumeErr != nil {
+			return false
+		}
+		if newest > r.checkpoints[name] {
+			r.checkpoints[name] = newest
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return consumeErr
+}
+
+// convertEvents converts events from a log group to OpenTelemetry logs. It
+// also returns the timestamp of the newest event.
+func (r *logsReceiver) convertEvents(group string, events []*cloudwatchlogs.FilteredLogEvent) (plog.Logs, int64) {
+	logs := plog.NewLogs()
+	byStream := make(map[string]plog.LogRecordSlice)
+
+	var newest int64
+	for _, ev := range events {
+		stream := aws.StringValue(ev.LogStreamName)
+		records, ok := byStream[stream]
+		if !ok {
+			rl := logs.ResourceLogs().AppendEmpty()
+			attrs := rl.Resource().Attributes()
+			attrs.PutStr(attrRegion, r.cfg.Region)
+			attrs.PutStr(attrLogGroup, group)
+			attrs.PutStr(attrLogStream, stream)
+			records = rl.ScopeLogs().AppendEmpty().LogRecords()
+			byStream[stream] = records
+		}
+
+		ts := aws.Int64Value(ev.Timestamp)
+		if ts > newest {
+			newest = ts
+		}
+
+		rec := records.AppendEmpty()
+		rec.SetTimestamp(pcommon.NewTimestampFromTime(time.UnixMilli(ts)))
+		rec.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.UnixMilli(aws.Int64Value(ev.IngestionTime))))
+		rec.Body().SetStr(aws.StringValue(ev.Message))
+		rec.Attributes().PutStr(attrEventID, aws.StringValue(ev.EventId))
+	}
+	return logs, newest
+}
+
+func (r *logsReceiver) loadCheckpoints() error {
+	if r.storageDir == "" {
+		return nil
+	}
+
+	bb, err := os.ReadFile(filepath.Join(r.storageDir, checkpointsFilename))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(bb, &r.checkpoints)
+}
+
+func (r *logsReceiver) saveCheckpoints() error {
+	if r.storageDir == "" {
+		return nil
+	}
+
+	bb, err := json.Marshal(r.checkpoints)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(r.storageDir, 0750); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so positions are never half-written.
+	path := filepath.Join(r.storageDir, checkpointsFilename)
+	if err := os.WriteFile(path+".tmp", bb, 0640); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
@@ -0,0 +1,125 @@
+package awscloudwatch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+// fakeClient serves a fixed set of log groups and events.
+type fakeClient struct {
+	cloudwatchlogsiface.CloudWatchLogsAPI
+
+	groups []string
+	events map[string][]*cloudwatchlogs.FilteredLogEvent
+
+	filterInputs []*cloudwatchlogs.FilterLogEventsInput
+}
+
+func (c *fakeClient) DescribeLogGroupsPagesWithContext(_ aws.Context, in *cloudwatchlogs.DescribeLogGroupsInput, fn func(*cloudwatchlogs.DescribeLogGroupsOutput, bool) bool, _ ...request.Option) error {
+	var out cloudwatchlogs.DescribeLogGroupsOutput
+	for _, g := range c.groups {
+		if len(g) >= len(*in.LogGroupNamePrefix) && g[:len(*in.LogGroupNamePrefix)] == *in.LogGroupNamePrefix {
+			out.LogGroups = append(out.LogGroups, &cloudwatchlogs.LogGroup{LogGroupName: aws.String(g)})
+		}
+	}
+	fn(&out, true)
+	return nil
+}
+
+func (c *fakeClient) FilterLogEventsPagesWithContext(_ aws.Context, in *cloudwatchlogs.FilterLogEventsInput, fn func(*cloudwatchlogs.FilterLogEventsOutput, bool) bool, _ ...request.Option) error {
+	c.filterInputs = append(c.filterInputs, in)
+
+	var out cloudwatchlogs.FilterLogEventsOutput
+	for _, ev := range c.events[*in.LogGroupName] {
+		if *ev.Timestamp >= *in.StartTime && *ev.Timestamp <= *in.EndTime {
+			out.Events = append(out.Events, ev)
+		}
+	}
+	fn(&out, true)
+	return nil
+}
+
+func event(stream, msg string, ts int64) *cloudwatchlogs.FilteredLogEvent {
+	return &cloudwatchlogs.FilteredLogEvent{
+		EventId:       aws.String(msg),
+		LogStreamName: aws.String(stream),
+		Message:       aws.String(msg),
+		Timestamp:     aws.Int64(ts),
+		IngestionTime: aws.Int64(ts + 1),
+	}
+}
+
+func TestPoll(t *testing.T) {
+	client := &fakeClient{
+		groups: []string{"/aws/lambda/a", "/aws/lambda/b", "/other"},
+		events: map[string][]*cloudwatchlogs.FilteredLogEvent{
+			"/aws/lambda/a": {event("s1", "a-1", 1000), event("s2", "a-2", 2000)},
+			"/aws/lambda/b": {event("s1", "b-1", 1500)},
+			"/other":        {event("s1", "other-1", 1500)},
+		},
+	}
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-east-1"
+	cfg.StartFrom = StartFromBeginning
+	cfg.Groups = []GroupConfig{{NamePrefix: "/aws/lambda/", StreamPrefix: "s"}}
+
+	sink := &consumertest.LogsSink{}
+	storageDir := t.TempDir()
+	r := newLogsReceiver(zap.NewNop(), cfg, storageDir, sink)
+
+	r.poll(context.Background(), client, time.UnixMilli(3000))
+	require.Equal(t, 3, sink.LogRecordCount())
+	require.Equal(t, "s", *client.filterInputs[0].LogStreamNamePrefix)
+
+	rl := sink.AllLogs()[0].ResourceLogs().At(0)
+	group, _ := rl.Resource().Attributes().Get(attrLogGroup)
+	require.Equal(t, "/aws/lambda/a", group.Str())
+	stream, _ := rl.Resource().Attributes().Get(attrLogStream)
+	require.Equal(t, "s1", stream.Str())
+	rec := rl.ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, "a-1", rec.Body().Str())
+	require.Equal(t, time.UnixMilli(1000).UTC(), rec.Timestamp().AsTime())
+
+	// Events which were already read aren't read again, even after a
+	// restart.
+	client.events["/aws/lambda/a"] = append(client.events["/aws/lambda/a"], event("s1", "a-3", 3500))
+
+	sink.Reset()
+	r = newLogsReceiver(zap.NewNop(), cfg, storageDir, sink)
+	require.NoError(t, r.loadCheckpoints())
+	r.poll(context.Background(), client, time.UnixMilli(4000))
+	require.Equal(t, 1, sink.LogRecordCount())
+	require.Equal(t, "a-3", sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+}
+
+func TestPoll_StartFromEnd(t *testing.T) {
+	client := &fakeClient{
+		events: map[string][]*cloudwatchlogs.FilteredLogEvent{
+			"group": {event("s1", "old", 1000)},
+		},
+	}
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-east-1"
+	cfg.Groups = []GroupConfig{{Name: "group"}}
+
+	sink := &consumertest.LogsSink{}
+	r := newLogsReceiver(zap.NewNop(), cfg, "", sink)
+
+	r.poll(context.Background(), client, time.UnixMilli(2000))
+	require.Zero(t, sink.LogRecordCount())
+
+	client.events["group"] = append(client.events["group"], event("s1", "new", 2500))
+	r.poll(context.Background(), client, time.UnixMilli(3000))
+	require.Equal(t, 1, sink.LogRecordCount())
+}
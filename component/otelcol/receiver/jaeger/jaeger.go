@@ -131,10 +131,16 @@ func (args Arguments) Convert() (otelconfig.Receiver, error) {
 
 // Extensions implements receiver.Arguments.
 func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
-	if args.RemoteSampling == nil {
-		return nil
+	m := args.Protocols.GRPC.Extensions()
+	for id, ext := range args.Protocols.ThriftHTTP.Extensions() {
+		m[id] = ext
+	}
+	if args.RemoteSampling != nil {
+		for id, ext := range args.RemoteSampling.Client.Extensions() {
+			m[id] = ext
+		}
 	}
-	return args.RemoteSampling.Client.Extensions()
+	return m
 }
 
 // Exporters implements receiver.Arguments.
@@ -91,6 +91,9 @@ func (c *Component) Run(ctx context.Context) error {
 			if err != nil {
 				level.Error(c.opts.Logger).Log("msg", "failed to consume log entries", "err", err)
 			}
+			// The entry is out of Loki's pipeline once it's handed to the
+			// consumer, so it's acknowledged whether or not that succeeded.
+			entry.Acknowledge()
 		}
 	}
 }
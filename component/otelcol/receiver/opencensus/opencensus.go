@@ -70,7 +70,7 @@ func (args Arguments) Convert() (otelconfig.Receiver, error) {
 
 // Extensions implements receiver.Arguments.
 func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
-	return nil
+	return args.GRPC.Extensions()
 }
 
 // Exporters implements receiver.Arguments.
@@ -0,0 +1,150 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+	otelconfigtls "go.opentelemetry.io/collector/config/configtls"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+)
+
+// typeStr is the type of the receiver.
+const typeStr = "rabbitmq"
+
+// Supported encodings of message bodies.
+const (
+	EncodingOTLPProto = "otlp_proto"
+	EncodingOTLPJSON  = "otlp_json"
+)
+
+// Config configures the RabbitMQ receiver.
+type Config struct {
+	otelconfig.ReceiverSettings `mapstructure:",squash"`
+
+	// Endpoint is the URL of the broker, in the form
+	// amqp[s]://host[:port][/vhost].
+	Endpoint string                          `mapstructure:"endpoint"`
+	Username string                          `mapstructure:"username"`
+	Password string                          `mapstructure:"password"`
+	TLS      *otelconfigtls.TLSClientSetting `mapstructure:"tls"`
+
+	// Queues to consume from for each signal. A receiver for a signal is only
+	// created if its queue is set.
+	TracesQueue  string `mapstructure:"traces_queue"`
+	MetricsQueue string `mapstructure:"metrics_queue"`
+	LogsQueue    string `mapstructure:"logs_queue"`
+
+	Encoding    string `mapstructure:"encoding"`
+	ConsumerTag string `mapstructure:"consumer_tag"`
+
+	// PrefetchCount is the number of unacknowledged messages the broker sends
+	// to the receiver.
+	PrefetchCount int `mapstructure:"prefetch_count"`
+	// AutoAck makes the broker consider messages acknowledged as soon as they
+	// are delivered, so messages are lost if they can't be processed.
+	AutoAck bool `mapstructure:"auto_ack"`
+	// RequeueOnError returns messages to the queue when the next consumer
+	// fails to process them. Messages which can't be decoded are always
+	// discarded.
+	RequeueOnError bool `mapstructure:"requeue_on_error"`
+
+	ReconnectInterval time.Duration `mapstructure:"reconnect_interval"`
+}
+
+var _ otelconfig.Receiver = (*Config)(nil)
+
+// Validate checks that cfg is valid.
+func (cfg *Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return fmt.Errorf("endpoint must be specified")
+	}
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint: %w", err)
+	}
+	if u.Scheme != "amqp" && u.Scheme != "amqps" {
+		return fmt.Errorf("unsupported endpoint scheme %q, expected amqp or amqps", u.Scheme)
+	}
+
+	if cfg.TracesQueue == "" && cfg.MetricsQueue == "" && cfg.LogsQueue == "" {
+		return fmt.Errorf("at least one of traces_queue, metrics_queue, or logs_queue must be specified")
+	}
+
+	switch cfg.Encoding {
+	case EncodingOTLPProto, EncodingOTLPJSON:
+	default:
+		return fmt.Errorf("unsupported encoding %q, expected %q or %q", cfg.Encoding, EncodingOTLPProto, EncodingOTLPJSON)
+	}
+
+	if cfg.PrefetchCount < 0 {
+		return fmt.Errorf("prefetch_count must not be negative")
+	}
+	if cfg.ReconnectInterval <= 0 {
+		return fmt.Errorf("reconnect_interval must be greater than 0")
+	}
+	return nil
+}
+
+// newFactory creates a receiver factory for RabbitMQ receivers.
+func newFactory() otelcomponent.ReceiverFactory {
+	createTracesReceiver := func(
+		_ context.Context,
+		set otelcomponent.ReceiverCreateSettings,
+		cfg otelconfig.Receiver,
+		next otelconsumer.Traces,
+	) (otelcomponent.TracesReceiver, error) {
+		c := cfg.(*Config)
+		if c.TracesQueue == "" {
+			return nil, nil
+		}
+		return newReceiver(set.Logger, c, c.TracesQueue, tracesHandler(c.Encoding, next)), nil
+	}
+
+	createMetricsReceiver := func(
+		_ context.Context,
+		set otelcomponent.ReceiverCreateSettings,
+		cfg otelconfig.Receiver,
+		next otelconsumer.Metrics,
+	) (otelcomponent.MetricsReceiver, error) {
+		c := cfg.(*Config)
+		if c.MetricsQueue == "" {
+			return nil, nil
+		}
+		return newReceiver(set.Logger, c, c.MetricsQueue, metricsHandler(c.Encoding, next)), nil
+	}
+
+	createLogsReceiver := func(
+		_ context.Context,
+		set otelcomponent.ReceiverCreateSettings,
+		cfg otelconfig.Receiver,
+		next otelconsumer.Logs,
+	) (otelcomponent.LogsReceiver, error) {
+		c := cfg.(*Config)
+		if c.LogsQueue == "" {
+			return nil, nil
+		}
+		return newReceiver(set.Logger, c, c.LogsQueue, logsHandler(c.Encoding, next)), nil
+	}
+
+	return otelcomponent.NewReceiverFactory(
+		typeStr,
+		createDefaultConfig,
+		otelcomponent.WithTracesReceiver(createTracesReceiver, otelcomponent.StabilityLevelAlpha),
+		otelcomponent.WithMetricsReceiver(createMetricsReceiver, otelcomponent.StabilityLevelAlpha),
+		otelcomponent.WithLogsReceiver(createLogsReceiver, otelcomponent.StabilityLevelAlpha),
+	)
+}
+
+func createDefaultConfig() otelconfig.Receiver {
+	return &Config{
+		ReceiverSettings:  otelconfig.NewReceiverSettings(otelconfig.NewComponentID(typeStr)),
+		Encoding:          EncodingOTLPProto,
+		PrefetchCount:     100,
+		RequeueOnError:    true,
+		ReconnectInterval: 5 * time.Second,
+	}
+}
@@ -0,0 +1,126 @@
+// Package rabbitmq provides an otelcol.receiver.rabbitmq component.
+package rabbitmq
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/receiver"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/river"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name: "otelcol.receiver.rabbitmq",
+		Args: Arguments{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return receiver.New(opts, newFactory(), args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.receiver.rabbitmq component.
+type Arguments struct {
+	Endpoint string                      `river:"endpoint,attr"`
+	Username string                      `river:"username,attr,optional"`
+	Password rivertypes.Secret           `river:"password,attr,optional"`
+	TLS      *otelcol.TLSClientArguments `river:"tls,block,optional"`
+
+	TracesQueue  string `river:"traces_queue,attr,optional"`
+	MetricsQueue string `river:"metrics_queue,attr,optional"`
+	LogsQueue    string `river:"logs_queue,attr,optional"`
+
+	Encoding          string        `river:"encoding,attr,optional"`
+	ConsumerTag       string        `river:"consumer_tag,attr,optional"`
+	PrefetchCount     int           `river:"prefetch_count,attr,optional"`
+	AutoAck           bool          `river:"auto_ack,attr,optional"`
+	RequeueOnError    bool          `river:"requeue_on_error,attr,optional"`
+	ReconnectInterval time.Duration `river:"reconnect_interval,attr,optional"`
+
+	// Output configures where to send received data. Required.
+	Output *otelcol.ConsumerArguments `river:"output,block"`
+}
+
+var (
+	_ receiver.Arguments = Arguments{}
+	_ river.Unmarshaler  = (*Arguments)(nil)
+)
+
+// DefaultArguments holds default settings for otelcol.receiver.rabbitmq.
+var DefaultArguments = Arguments{
+	Encoding:          EncodingOTLPProto,
+	PrefetchCount:     100,
+	RequeueOnError:    true,
+	ReconnectInterval: 5 * time.Second,
+}
+
+// UnmarshalRiver applies defaults to args before unmarshaling.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	_, err := args.Convert()
+	return err
+}
+
+// Convert implements receiver.Arguments.
+func (args Arguments) Convert() (otelconfig.Receiver, error) {
+	cfg := createDefaultConfig().(*Config)
+
+	cfg.Endpoint = args.Endpoint
+	cfg.Username = args.Username
+	cfg.Password = string(args.Password)
+	cfg.TLS = args.TLS.Convert()
+	cfg.TracesQueue = args.TracesQueue
+	cfg.MetricsQueue = args.MetricsQueue
+	cfg.LogsQueue = args.LogsQueue
+	cfg.Encoding = args.Encoding
+	cfg.ConsumerTag = args.ConsumerTag
+	cfg.PrefetchCount = args.PrefetchCount
+	cfg.AutoAck = args.AutoAck
+	cfg.RequeueOnError = args.RequeueOnError
+	cfg.ReconnectInterval = args.ReconnectInterval
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Messages taken from a queue without a matching output would be
+	// acknowledged and dropped.
+	if out := args.Output; out != nil {
+		switch {
+		case args.TracesQueue != "" && len(out.Traces) == 0:
+			return nil, fmt.Errorf("traces_queue is set but output.traces is empty")
+		case args.MetricsQueue != "" && len(out.Metrics) == 0:
+			return nil, fmt.Errorf("metrics_queue is set but output.metrics is empty")
+		case args.LogsQueue != "" && len(out.Logs) == 0:
+			return nil, fmt.Errorf("logs_queue is set but output.logs is empty")
+		}
+	}
+	return cfg, nil
+}
+
+// Extensions implements receiver.Arguments.
+func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return nil
+}
+
+// Exporters implements receiver.Arguments.
+func (args Arguments) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
+
+// NextConsumers implements receiver.Arguments.
+func (args Arguments) NextConsumers() *otelcol.ConsumerArguments {
+	return args.Output
+}
@@ -0,0 +1,104 @@
+package rabbitmq_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/internal/fakeconsumer"
+	"github.com/grafana/agent/component/otelcol/receiver/rabbitmq"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	tt := []struct {
+		name   string
+		cfg    string
+		expect string
+	}{
+		{
+			name: "no queues",
+			cfg: `
+				endpoint = "amqp://localhost:5672/"
+				output {}
+			`,
+			expect: "at least one of traces_queue, metrics_queue, or logs_queue must be specified",
+		},
+		{
+			name: "unknown endpoint scheme",
+			cfg: `
+				endpoint   = "http://localhost:5672/"
+				logs_queue = "otlp-logs"
+				output {}
+			`,
+			expect: `unsupported endpoint scheme "http"`,
+		},
+		{
+			name: "unknown encoding",
+			cfg: `
+				endpoint   = "amqp://localhost:5672/"
+				logs_queue = "otlp-logs"
+				encoding   = "jaeger_proto"
+				output {}
+			`,
+			expect: `unsupported encoding "jaeger_proto"`,
+		},
+		{
+			name: "negative prefetch count",
+			cfg: `
+				endpoint       = "amqp://localhost:5672/"
+				logs_queue     = "otlp-logs"
+				prefetch_count = -1
+				output {}
+			`,
+			expect: "prefetch_count must not be negative",
+		},
+		{
+			name: "queue without output",
+			cfg: `
+				endpoint     = "amqp://localhost:5672/"
+				traces_queue = "otlp-traces"
+				output {}
+			`,
+			expect: "traces_queue is set but output.traces is empty",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var args rabbitmq.Arguments
+			err := river.Unmarshal([]byte(tc.cfg), &args)
+			require.ErrorContains(t, err, tc.expect)
+		})
+	}
+}
+
+func TestArguments_Convert(t *testing.T) {
+	args := rabbitmq.DefaultArguments
+	args.Endpoint = "amqps://rabbitmq:5671/telemetry"
+	args.Username = "agent"
+	args.Password = "secret"
+	args.LogsQueue = "otlp-logs"
+	args.PrefetchCount = 10
+	args.RequeueOnError = false
+	args.Output = &otelcol.ConsumerArguments{
+		Logs: []otelcol.Consumer{&fakeconsumer.Consumer{}},
+	}
+
+	out, err := args.Convert()
+	require.NoError(t, err)
+
+	conf := out.(*rabbitmq.Config)
+	require.Equal(t, "amqps://rabbitmq:5671/telemetry", conf.Endpoint)
+	require.Equal(t, "agent", conf.Username)
+	require.Equal(t, "secret", conf.Password)
+	require.Nil(t, conf.TLS)
+	require.Equal(t, "otlp-logs", conf.LogsQueue)
+	require.Empty(t, conf.TracesQueue)
+	require.Equal(t, rabbitmq.EncodingOTLPProto, conf.Encoding)
+	require.Equal(t, 10, conf.PrefetchCount)
+	require.False(t, conf.AutoAck)
+	require.False(t, conf.RequeueOnError)
+	require.Equal(t, 5*time.Second, conf.ReconnectInterval)
+}
@@ -0,0 +1,204 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+// handler decodes a message body and sends it to the next consumer. Errors
+// which are permanent, such as messages which can't be decoded, are marked
+// with consumererror.NewPermanent.
+type handler func(ctx context.Context, body []byte) error
+
+func tracesHandler(encoding string, next otelconsumer.Traces) handler {
+	unmarshaler := ptrace.NewProtoUnmarshaler()
+	if encoding == EncodingOTLPJSON {
+		unmarshaler = ptrace.NewJSONUnmarshaler()
+	}
+	return func(ctx context.Context, body []byte) error {
+		traces, err := unmarshaler.UnmarshalTraces(body)
+		if err != nil {
+			return consumererror.NewPermanent(fmt.Errorf("failed to decode traces: %w", err))
+		}
+		return next.ConsumeTraces(ctx, traces)
+	}
+}
+
+func metricsHandler(encoding string, next otelconsumer.Metrics) handler {
+	unmarshaler := pmetric.NewProtoUnmarshaler()
+	if encoding == EncodingOTLPJSON {
+		unmarshaler = pmetric.NewJSONUnmarshaler()
+	}
+	return func(ctx context.Context, body []byte) error {
+		metrics, err := unmarshaler.UnmarshalMetrics(body)
+		if err != nil {
+			return consumererror.NewPermanent(fmt.Errorf("failed to decode metrics: %w", err))
+		}
+		return next.ConsumeMetrics(ctx, metrics)
+	}
+}
+
+func logsHandler(encoding string, next otelconsumer.Logs) handler {
+	unmarshaler := plog.NewProtoUnmarshaler()
+	if encoding == EncodingOTLPJSON {
+		unmarshaler = plog.NewJSONUnmarshaler()
+	}
+	return func(ctx context.Context, body []byte) error {
+		logs, err := unmarshaler.UnmarshalLogs(body)
+		if err != nil {
+			return consumererror.NewPermanent(fmt.Errorf("failed to decode logs: %w", err))
+		}
+		return next.ConsumeLogs(ctx, logs)
+	}
+}
+
+// queueReceiver consumes messages from a single queue, reconnecting to the
+// broker whenever the connection is lost.
+type queueReceiver struct {
+	log    *zap.Logger
+	cfg    *Config
+	queue  string
+	handle handler
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var (
+	_ otelcomponent.TracesReceiver  = (*queueReceiver)(nil)
+	_ otelcomponent.MetricsReceiver = (*queueReceiver)(nil)
+	_ otelcomponent.LogsReceiver    = (*queueReceiver)(nil)
+)
+
+func newReceiver(log *zap.Logger, cfg *Config, queue string, handle handler) *queueReceiver {
+	return &queueReceiver{
+		log:    log.With(zap.String("queue", queue)),
+		cfg:    cfg,
+		queue:  queue,
+		handle: handle,
+	}
+}
+
+// Start implements otelcomponent.Component.
+func (r *queueReceiver) Start(_ context.Context, _ otelcomponent.Host) error {
+	// Load the TLS settings up front so that invalid files are reported
+	// immediately.
+	amqpConfig := amqp.Config{}
+	if r.cfg.TLS != nil {
+		tlsConfig, err := r.cfg.TLS.LoadTLSConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load TLS config: %w", err)
+		}
+		amqpConfig.TLSClientConfig = tlsConfig
+	}
+	if r.cfg.Username != "" {
+		amqpConfig.SASL = []amqp.Authentication{&amqp.PlainAuth{
+			Username: r.cfg.Username,
+			Password: r.cfg.Password,
+		}}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.run(ctx, amqpConfig)
+	}()
+	return nil
+}
+
+// Shutdown implements otelcomponent.Component.
+func (r *queueReceiver) Shutdown(_ context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	return nil
+}
+
+func (r *queueReceiver) run(ctx context.Context, amqpConfig amqp.Config) {
+	for {
+		if err := r.consume(ctx, amqpConfig); err != nil && ctx.Err() == nil {
+			r.log.Error("failed to consume messages, reconnecting", zap.Duration("backoff", r.cfg.ReconnectInterval), zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.cfg.ReconnectInterval):
+		}
+	}
+}
+
+// consume connects to the broker and handles deliveries until ctx is
+// canceled or the connection is closed.
+func (r *queueReceiver) consume(ctx context.Context, amqpConfig amqp.Config) error {
+	conn, err := amqp.DialConfig(r.cfg.Endpoint, amqpConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to broker: %w", err)
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	if err := ch.Qos(r.cfg.PrefetchCount, 0, false); err != nil {
+		return fmt.Errorf("failed to set prefetch count: %w", err)
+	}
+	deliveries, err := ch.Consume(r.queue, r.cfg.ConsumerTag, r.cfg.AutoAck, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to consume from queue: %w", err)
+	}
+	r.log.Info("consuming messages")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case d, ok := <-deliveries:
+			if !ok {
+				return fmt.Errorf("delivery channel closed")
+			}
+			r.handleDelivery(ctx, d)
+		}
+	}
+}
+
+// handleDelivery sends the body of d to the next consumer and acknowledges d
+// according to the result.
+func (r *queueReceiver) handleDelivery(ctx context.Context, d amqp.Delivery) {
+	err := r.handle(ctx, d.Body)
+	if err != nil {
+		r.log.Error("failed to process message", zap.Error(err))
+	}
+	if r.cfg.AutoAck {
+		return
+	}
+
+	var ackErr error
+	switch {
+	case err == nil:
+		ackErr = d.Ack(false)
+	case consumererror.IsPermanent(err) || !r.cfg.RequeueOnError:
+		ackErr = d.Reject(false)
+	default:
+		ackErr = d.Nack(false, true)
+	}
+	if ackErr != nil {
+		r.log.Warn("failed to acknowledge message", zap.Error(ackErr))
+	}
+}
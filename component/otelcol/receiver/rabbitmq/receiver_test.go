@@ -0,0 +1,115 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// fakeAcknowledger records how a delivery was acknowledged.
+type fakeAcknowledger struct {
+	result string
+}
+
+func (a *fakeAcknowledger) Ack(uint64, bool) error { a.result = "ack"; return nil }
+
+func (a *fakeAcknowledger) Nack(_ uint64, _ bool, requeue bool) error {
+	a.result = "nack"
+	if requeue {
+		a.result = "nack requeue"
+	}
+	return nil
+}
+
+func (a *fakeAcknowledger) Reject(_ uint64, requeue bool) error {
+	a.result = "reject"
+	if requeue {
+		a.result = "reject requeue"
+	}
+	return nil
+}
+
+func testLogs(t *testing.T, encoding string) []byte {
+	t.Helper()
+
+	logs := plog.NewLogs()
+	logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("hello")
+
+	var (
+		bb  []byte
+		err error
+	)
+	if encoding == EncodingOTLPJSON {
+		bb, err = plog.NewJSONMarshaler().MarshalLogs(logs)
+	} else {
+		bb, err = plog.NewProtoMarshaler().MarshalLogs(logs)
+	}
+	require.NoError(t, err)
+	return bb
+}
+
+func TestReceiver_HandleDelivery(t *testing.T) {
+	for _, encoding := range []string{EncodingOTLPProto, EncodingOTLPJSON} {
+		t.Run(encoding, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Encoding = encoding
+
+			sink := new(consumertest.LogsSink)
+			r := newReceiver(zap.NewNop(), cfg, "otlp-logs", logsHandler(encoding, sink))
+
+			ack := &fakeAcknowledger{}
+			r.handleDelivery(context.Background(), amqp.Delivery{Acknowledger: ack, Body: testLogs(t, encoding)})
+			require.Equal(t, "ack", ack.result)
+			require.Equal(t, 1, sink.LogRecordCount())
+		})
+	}
+}
+
+func TestReceiver_HandleDeliveryErrors(t *testing.T) {
+	tt := []struct {
+		name           string
+		body           []byte
+		consumerErr    error
+		autoAck        bool
+		requeueOnError bool
+		expect         string
+	}{
+		{name: "undecodable", body: []byte("not otlp"), requeueOnError: true, expect: "reject"},
+		{name: "consumer error", consumerErr: errors.New("full"), requeueOnError: true, expect: "nack requeue"},
+		{name: "consumer error without requeue", consumerErr: errors.New("full"), expect: "reject"},
+		{name: "permanent consumer error", consumerErr: consumererror.NewPermanent(errors.New("bad data")), requeueOnError: true, expect: "reject"},
+		{name: "auto ack", consumerErr: errors.New("full"), autoAck: true, requeueOnError: true, expect: ""},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.AutoAck = tc.autoAck
+			cfg.RequeueOnError = tc.requeueOnError
+
+			sink := new(consumertest.LogsSink)
+			var next consumer.Logs = sink
+			if tc.consumerErr != nil {
+				next = consumertest.NewErr(tc.consumerErr)
+			}
+			r := newReceiver(zap.NewNop(), cfg, "otlp-logs", logsHandler(EncodingOTLPProto, next))
+
+			body := tc.body
+			if body == nil {
+				body = testLogs(t, EncodingOTLPProto)
+			}
+			ack := &fakeAcknowledger{}
+			r.handleDelivery(context.Background(), amqp.Delivery{Acknowledger: ack, Body: body})
+			require.Equal(t, tc.expect, ack.result)
+			require.Zero(t, sink.LogRecordCount())
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package snmp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+)
+
+// typeStr is the type of the receiver.
+const typeStr = "snmp"
+
+// Supported SNMP versions.
+const (
+	Version1  = "v1"
+	Version2c = "v2c"
+	Version3  = "v3"
+)
+
+// Supported SNMPv3 security levels.
+const (
+	SecurityLevelNoAuthNoPriv = "no_auth_no_priv"
+	SecurityLevelAuthNoPriv   = "auth_no_priv"
+	SecurityLevelAuthPriv     = "auth_priv"
+)
+
+// Supported types of metrics.
+const (
+	MetricTypeGauge = "gauge"
+	MetricTypeSum   = "sum"
+)
+
+// defaultPort is the port of SNMP agents when the endpoint doesn't specify one.
+const defaultPort = 161
+
+// Config configures the SNMP receiver.
+type Config struct {
+	otelconfig.ReceiverSettings `mapstructure:",squash"`
+
+	// Endpoint is the address of the SNMP agent, in the form
+	// [udp|tcp://]host[:port].
+	Endpoint string `mapstructure:"endpoint"`
+	Version  string `mapstructure:"version"`
+
+	// Community is used by SNMPv1 and SNMPv2c.
+	Community string `mapstructure:"community"`
+
+	// The remaining credentials are used by SNMPv3.
+	User            string `mapstructure:"user"`
+	SecurityLevel   string `mapstructure:"security_level"`
+	AuthType        string `mapstructure:"auth_type"`
+	AuthPassword    string `mapstructure:"auth_password"`
+	PrivacyType     string `mapstructure:"privacy_type"`
+	PrivacyPassword string `mapstructure:"privacy_password"`
+
+	CollectionInterval time.Duration `mapstructure:"collection_interval"`
+	Timeout            time.Duration `mapstructure:"timeout"`
+
+	Metrics []MetricConfig `mapstructure:"metrics"`
+}
+
+// MetricConfig maps an SNMP object to a metric.
+type MetricConfig struct {
+	Name        string `mapstructure:"name"`
+	Description string `mapstructure:"description"`
+	Unit        string `mapstructure:"unit"`
+	Type        string `mapstructure:"type"`
+
+	// Exactly one of OID or ColumnOID must be set. OID is a scalar object,
+	// which produces a single data point. ColumnOID is a column of a table,
+	// which is walked to produce a data point for every row, with the index of
+	// the row as the snmp.index attribute.
+	OID       string `mapstructure:"oid"`
+	ColumnOID string `mapstructure:"column_oid"`
+}
+
+var _ otelconfig.Receiver = (*Config)(nil)
+
+// Validate checks that cfg is valid.
+func (cfg *Config) Validate() error {
+	if _, _, _, err := cfg.parseEndpoint(); err != nil {
+		return err
+	}
+	if cfg.CollectionInterval <= 0 {
+		return fmt.Errorf("collection_interval must be greater than 0")
+	}
+	if cfg.Timeout <= 0 {
+		return fmt.Errorf("timeout must be greater than 0")
+	}
+
+	switch cfg.Version {
+	case Version1, Version2c:
+		if cfg.Community == "" {
+			return fmt.Errorf("community must be specified for SNMP %s", cfg.Version)
+		}
+	case Version3:
+		if err := cfg.validateV3(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported version %q, expected %q, %q, or %q", cfg.Version, Version1, Version2c, Version3)
+	}
+
+	if len(cfg.Metrics) == 0 {
+		return fmt.Errorf("at least one metric must be specified")
+	}
+	names := make(map[string]struct{}, len(cfg.Metrics))
+	for _, m := range cfg.Metrics {
+		if m.Name == "" {
+			return fmt.Errorf("metric name must be specified")
+		}
+		if _, dup := names[m.Name]; dup {
+			return fmt.Errorf("metric %q is specified more than once", m.Name)
+		}
+		names[m.Name] = struct{}{}
+
+		if (m.OID == "") == (m.ColumnOID == "") {
+			return fmt.Errorf("exactly one of oid or column_oid must be specified for metric %q", m.Name)
+		}
+		switch m.Type {
+		case MetricTypeGauge, MetricTypeSum:
+		default:
+			return fmt.Errorf("unsupported type %q for metric %q, expected %q or %q", m.Type, m.Name, MetricTypeGauge, MetricTypeSum)
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) validateV3() error {
+	if cfg.User == "" {
+		return fmt.Errorf("user must be specified for SNMP v3")
+	}
+
+	switch cfg.SecurityLevel {
+	case SecurityLevelNoAuthNoPriv:
+		return nil
+	case SecurityLevelAuthNoPriv, SecurityLevelAuthPriv:
+	default:
+		return fmt.Errorf("unsupported security_level %q, expected %q, %q, or %q", cfg.SecurityLevel, SecurityLevelNoAuthNoPriv, SecurityLevelAuthNoPriv, SecurityLevelAuthPriv)
+	}
+
+	if _, ok := authProtocols[strings.ToUpper(cfg.AuthType)]; !ok {
+		return fmt.Errorf("unsupported auth_type %q", cfg.AuthType)
+	}
+	if cfg.AuthPassword == "" {
+		return fmt.Errorf("auth_password must be specified for security_level %q", cfg.SecurityLevel)
+	}
+	if cfg.SecurityLevel == SecurityLevelAuthNoPriv {
+		return nil
+	}
+
+	if _, ok := privacyProtocols[strings.ToUpper(cfg.PrivacyType)]; !ok {
+		return fmt.Errorf("unsupported privacy_type %q", cfg.PrivacyType)
+	}
+	if cfg.PrivacyPassword == "" {
+		return fmt.Errorf("privacy_password must be specified for security_level %q", cfg.SecurityLevel)
+	}
+	return nil
+}
+
+// parseEndpoint returns the transport, host, and port of the SNMP agent.
+func (cfg *Config) parseEndpoint() (transport, host string, port uint16, err error) {
+	if cfg.Endpoint == "" {
+		return "", "", 0, fmt.Errorf("endpoint must be specified")
+	}
+
+	transport, hostport := "udp", cfg.Endpoint
+	if strings.Contains(cfg.Endpoint, "://") {
+		u, err := url.Parse(cfg.Endpoint)
+		if err != nil {
+			return "", "", 0, fmt.Errorf("invalid endpoint: %w", err)
+		}
+		if u.Scheme != "udp" && u.Scheme != "tcp" {
+			return "", "", 0, fmt.Errorf("unsupported endpoint scheme %q, expected udp or tcp", u.Scheme)
+		}
+		transport, hostport = u.Scheme, u.Host
+	}
+
+	host, rawPort, err := net.SplitHostPort(hostport)
+	if err != nil {
+		// The endpoint has no port.
+		return transport, hostport, defaultPort, nil
+	}
+	p, err := strconv.ParseUint(rawPort, 10, 16)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid endpoint port %q", rawPort)
+	}
+	return transport, host, uint16(p), nil
+}
+
+// newFactory creates a receiver factory for SNMP receivers.
+func newFactory() otelcomponent.ReceiverFactory {
+	createMetricsReceiver := func(
+		_ context.Context,
+		set otelcomponent.ReceiverCreateSettings,
+		cfg otelconfig.Receiver,
+		next otelconsumer.Metrics,
+	) (otelcomponent.MetricsReceiver, error) {
+		return newMetricsReceiver(set.Logger, cfg.(*Config), next), nil
+	}
+
+	return otelcomponent.NewReceiverFactory(
+		typeStr,
+		createDefaultConfig,
+		otelcomponent.WithMetricsReceiver(createMetricsReceiver, otelcomponent.StabilityLevelAlpha),
+	)
+}
+
+func createDefaultConfig() otelconfig.Receiver {
+	return &Config{
+		ReceiverSettings:   otelconfig.NewReceiverSettings(otelconfig.NewComponentID(typeStr)),
+		Version:            Version2c,
+		Community:          "public",
+		SecurityLevel:      SecurityLevelNoAuthNoPriv,
+		AuthType:           "MD5",
+		PrivacyType:        "DES",
+		CollectionInterval: 10 * time.Second,
+		Timeout:            5 * time.Second,
+	}
+}
@@ -0,0 +1,341 @@
+package snmp
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// Attributes set on emitted metrics.
+const (
+	attrEndpoint = "snmp.endpoint"
+	attrIndex    = "snmp.index"
+)
+
+var authProtocols = map[string]gosnmp.SnmpV3AuthProtocol{
+	"MD5":    gosnmp.MD5,
+	"SHA":    gosnmp.SHA,
+	"SHA224": gosnmp.SHA224,
+	"SHA256": gosnmp.SHA256,
+	"SHA384": gosnmp.SHA384,
+	"SHA512": gosnmp.SHA512,
+}
+
+var privacyProtocols = map[string]gosnmp.SnmpV3PrivProtocol{
+	"DES":     gosnmp.DES,
+	"AES":     gosnmp.AES,
+	"AES192":  gosnmp.AES192,
+	"AES192C": gosnmp.AES192C,
+	"AES256":  gosnmp.AES256,
+	"AES256C": gosnmp.AES256C,
+}
+
+// client is the subset of gosnmp.GoSNMP used by the receiver.
+type client interface {
+	Get(oids []string) (*gosnmp.SnmpPacket, error)
+	// Walk walks the subtree at rootOid, using GETBULK requests where the
+	// SNMP version supports them.
+	Walk(rootOid string, walkFn gosnmp.WalkFunc) error
+	Close() error
+}
+
+// metricsReceiver periodically collects metrics from an SNMP agent.
+type metricsReceiver struct {
+	log  *zap.Logger
+	cfg  *Config
+	next otelconsumer.Metrics
+
+	// newClient connects to the SNMP agent. Overridden in tests.
+	newClient func(ctx context.Context, cfg *Config) (client, error)
+
+	startTime pcommon.Timestamp
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+var _ otelcomponent.MetricsReceiver = (*metricsReceiver)(nil)
+
+func newMetricsReceiver(log *zap.Logger, cfg *Config, next otelconsumer.Metrics) *metricsReceiver {
+	return &metricsReceiver{
+		log:       log,
+		cfg:       cfg,
+		next:      next,
+		newClient: newClient,
+	}
+}
+
+// goSNMPClient adapts gosnmp.GoSNMP to client.
+type goSNMPClient struct {
+	*gosnmp.GoSNMP
+}
+
+func (c goSNMPClient) Walk(rootOid string, walkFn gosnmp.WalkFunc) error {
+	if c.Version == gosnmp.Version1 {
+		return c.GoSNMP.Walk(rootOid, walkFn)
+	}
+	return c.BulkWalk(rootOid, walkFn)
+}
+
+func (c goSNMPClient) Close() error {
+	return c.Conn.Close()
+}
+
+func newClient(ctx context.Context, cfg *Config) (client, error) {
+	transport, host, port, err := cfg.parseEndpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &gosnmp.GoSNMP{
+		Context:            ctx,
+		Target:             host,
+		Port:               port,
+		Transport:          transport,
+		Community:          cfg.Community,
+		Timeout:            cfg.Timeout,
+		Retries:            gosnmp.Default.Retries,
+		ExponentialTimeout: true,
+		MaxOids:            gosnmp.MaxOids,
+	}
+
+	switch cfg.Version {
+	case Version1:
+		c.Version = gosnmp.Version1
+	case Version2c:
+		c.Version = gosnmp.Version2c
+	case Version3:
+		c.Version = gosnmp.Version3
+		c.SecurityModel = gosnmp.UserSecurityModel
+		params := &gosnmp.UsmSecurityParameters{UserName: cfg.User}
+		switch cfg.SecurityLevel {
+		case SecurityLevelNoAuthNoPriv:
+			c.MsgFlags = gosnmp.NoAuthNoPriv
+		case SecurityLevelAuthNoPriv:
+			c.MsgFlags = gosnmp.AuthNoPriv
+		case SecurityLevelAuthPriv:
+			c.MsgFlags = gosnmp.AuthPriv
+		}
+		if c.MsgFlags&gosnmp.AuthNoPriv != 0 {
+			params.AuthenticationProtocol = authProtocols[strings.ToUpper(cfg.AuthType)]
+			params.AuthenticationPassphrase = cfg.AuthPassword
+		}
+		if c.MsgFlags&gosnmp.AuthPriv == gosnmp.AuthPriv {
+			params.PrivacyProtocol = privacyProtocols[strings.ToUpper(cfg.PrivacyType)]
+			params.PrivacyPassphrase = cfg.PrivacyPassword
+		}
+		c.SecurityParameters = params
+	}
+
+	if err := c.Connect(); err != nil {
+		return nil, fmt.Errorf("failed to connect to SNMP agent: %w", err)
+	}
+	return goSNMPClient{c}, nil
+}
+
+// Start implements otelcomponent.Component.
+func (r *metricsReceiver) Start(_ context.Context, _ otelcomponent.Host) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.startTime = pcommon.NewTimestampFromTime(time.Now())
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.run(ctx)
+	}()
+	return nil
+}
+
+// Shutdown implements otelcomponent.Component.
+func (r *metricsReceiver) Shutdown(_ context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	return nil
+}
+
+func (r *metricsReceiver) run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.CollectionInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.collect(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			r.log.Error("failed to collect SNMP metrics", zap.String("endpoint", r.cfg.Endpoint), zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// collect reads the configured objects from the SNMP agent and sends them to
+// the next consumer as metrics.
+func (r *metricsReceiver) collect(ctx context.Context, now time.Time) error {
+	c, err := r.newClient(ctx, r.cfg)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	metrics := pmetric.NewMetrics()
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr(attrEndpoint, r.cfg.Endpoint)
+	ms := rm.ScopeMetrics().AppendEmpty().Metrics()
+	ts := pcommon.NewTimestampFromTime(now)
+
+	scalars, err := r.getScalars(c)
+	if err != nil {
+		return err
+	}
+
+	for _, mc := range r.cfg.Metrics {
+		var points []dataPoint
+		if mc.OID != "" {
+			pdu, ok := scalars[normalizeOID(mc.OID)]
+			if !ok {
+				continue
+			}
+			if v, ok := pduValue(pdu); ok {
+				points = append(points, dataPoint{value: v})
+			}
+		} else {
+			points, err = r.walkColumn(c, mc.ColumnOID)
+			if err != nil {
+				r.log.Warn("failed to walk column", zap.String("metric", mc.Name), zap.String("column_oid", mc.ColumnOID), zap.Error(err))
+				continue
+			}
+		}
+		if len(points) == 0 {
+			continue
+		}
+		r.appendMetric(ms.AppendEmpty(), mc, points, ts)
+	}
+
+	if ms.Len() == 0 {
+		return nil
+	}
+	return r.next.ConsumeMetrics(ctx, metrics)
+}
+
+// getScalars reads the values of all scalar objects, keyed by their
+// normalized OIDs.
+func (r *metricsReceiver) getScalars(c client) (map[string]gosnmp.SnmpPDU, error) {
+	var oids []string
+	for _, mc := range r.cfg.Metrics {
+		if mc.OID != "" {
+			oids = append(oids, normalizeOID(mc.OID))
+		}
+	}
+
+	res := make(map[string]gosnmp.SnmpPDU, len(oids))
+	for len(oids) > 0 {
+		n := len(oids)
+		if n > gosnmp.MaxOids {
+			n = gosnmp.MaxOids
+		}
+		packet, err := c.Get(oids[:n])
+		if err != nil {
+			return nil, fmt.Errorf("failed to get scalar objects: %w", err)
+		}
+		for _, pdu := range packet.Variables {
+			res[normalizeOID(pdu.Name)] = pdu
+		}
+		oids = oids[n:]
+	}
+	return res, nil
+}
+
+// dataPoint is a value read from the SNMP agent. index is the index of the
+// table row the value was read from, if any.
+type dataPoint struct {
+	index string
+	value float64
+}
+
+// walkColumn reads every row of the table column at oid.
+func (r *metricsReceiver) walkColumn(c client, oid string) ([]dataPoint, error) {
+	root := normalizeOID(oid)
+
+	var points []dataPoint
+	err := c.Walk(root, func(pdu gosnmp.SnmpPDU) error {
+		v, ok := pduValue(pdu)
+		if !ok {
+			return nil
+		}
+		points = append(points, dataPoint{
+			index: strings.TrimPrefix(normalizeOID(pdu.Name), root+"."),
+			value: v,
+		})
+		return nil
+	})
+	return points, err
+}
+
+func (r *metricsReceiver) appendMetric(m pmetric.Metric, mc MetricConfig, points []dataPoint, ts pcommon.Timestamp) {
+	m.SetName(mc.Name)
+	m.SetDescription(mc.Description)
+	m.SetUnit(mc.Unit)
+
+	var dps pmetric.NumberDataPointSlice
+	switch mc.Type {
+	case MetricTypeSum:
+		sum := m.SetEmptySum()
+		sum.SetIsMonotonic(true)
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+		dps = sum.DataPoints()
+	default:
+		dps = m.SetEmptyGauge().DataPoints()
+	}
+
+	for _, p := range points {
+		dp := dps.AppendEmpty()
+		if mc.Type == MetricTypeSum {
+			dp.SetStartTimestamp(r.startTime)
+		}
+		dp.SetTimestamp(ts)
+		dp.SetDoubleValue(p.value)
+		if p.index != "" {
+			dp.Attributes().PutStr(attrIndex, p.index)
+		}
+	}
+}
+
+// pduValue returns the numeric value of pdu. Non-numeric values and
+// exceptions such as noSuchObject are skipped.
+func pduValue(pdu gosnmp.SnmpPDU) (float64, bool) {
+	switch pdu.Type {
+	case gosnmp.Integer, gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Counter64, gosnmp.Uinteger32:
+		f, _ := new(big.Float).SetInt(gosnmp.ToBigInt(pdu.Value)).Float64()
+		return f, true
+	case gosnmp.OpaqueFloat:
+		v, ok := pdu.Value.(float32)
+		return float64(v), ok
+	case gosnmp.OpaqueDouble:
+		v, ok := pdu.Value.(float64)
+		return v, ok
+	default:
+		return 0, false
+	}
+}
+
+// normalizeOID returns oid with a leading dot, as returned by gosnmp.
+func normalizeOID(oid string) string {
+	if strings.HasPrefix(oid, ".") {
+		return oid
+	}
+	return "." + oid
+}
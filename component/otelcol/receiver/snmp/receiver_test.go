@@ -0,0 +1,121 @@
+package snmp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// fakeClient serves a fixed set of SNMP objects.
+type fakeClient struct {
+	objects []gosnmp.SnmpPDU
+	closed  bool
+}
+
+func (c *fakeClient) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	var packet gosnmp.SnmpPacket
+	for _, oid := range oids {
+		pdu := gosnmp.SnmpPDU{Name: oid, Type: gosnmp.NoSuchObject}
+		for _, obj := range c.objects {
+			if obj.Name == oid {
+				pdu = obj
+			}
+		}
+		packet.Variables = append(packet.Variables, pdu)
+	}
+	return &packet, nil
+}
+
+func (c *fakeClient) Walk(rootOid string, walkFn gosnmp.WalkFunc) error {
+	for _, obj := range c.objects {
+		if strings.HasPrefix(obj.Name, rootOid+".") {
+			if err := walkFn(obj); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *fakeClient) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestMetricsReceiver_Collect(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = "switch-1"
+	cfg.Metrics = []MetricConfig{
+		{Name: "system.uptime", OID: "1.3.6.1.2.1.1.3.0", Type: MetricTypeGauge},
+		{Name: "missing", OID: "1.3.6.1.2.1.1.99.0", Type: MetricTypeGauge},
+		{Name: "network.io.receive", ColumnOID: "1.3.6.1.2.1.2.2.1.10", Type: MetricTypeSum},
+	}
+	require.NoError(t, cfg.Validate())
+
+	fake := &fakeClient{objects: []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(12345)},
+		{Name: ".1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(100)},
+		{Name: ".1.3.6.1.2.1.2.2.1.10.2", Type: gosnmp.Counter32, Value: uint(200)},
+		{Name: ".1.3.6.1.2.1.2.2.1.2.1", Type: gosnmp.OctetString, Value: []byte("eth0")},
+	}}
+
+	sink := new(consumertest.MetricsSink)
+	r := newMetricsReceiver(zap.NewNop(), cfg, sink)
+	r.newClient = func(context.Context, *Config) (client, error) { return fake, nil }
+
+	require.NoError(t, r.collect(context.Background(), time.Now()))
+	require.True(t, fake.closed)
+
+	all := sink.AllMetrics()
+	require.Len(t, all, 1)
+	rm := all[0].ResourceMetrics().At(0)
+	endpoint, _ := rm.Resource().Attributes().Get(attrEndpoint)
+	require.Equal(t, "switch-1", endpoint.Str())
+
+	ms := rm.ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, ms.Len())
+
+	uptime := ms.At(0)
+	require.Equal(t, "system.uptime", uptime.Name())
+	require.Equal(t, pmetric.MetricTypeGauge, uptime.Type())
+	require.Equal(t, 12345.0, uptime.Gauge().DataPoints().At(0).DoubleValue())
+
+	received := ms.At(1)
+	require.Equal(t, "network.io.receive", received.Name())
+	require.Equal(t, pmetric.MetricTypeSum, received.Type())
+	require.True(t, received.Sum().IsMonotonic())
+	dps := received.Sum().DataPoints()
+	require.Equal(t, 2, dps.Len())
+	index, _ := dps.At(1).Attributes().Get(attrIndex)
+	require.Equal(t, "2", index.Str())
+	require.Equal(t, 200.0, dps.At(1).DoubleValue())
+}
+
+func TestConfig_ParseEndpoint(t *testing.T) {
+	tt := []struct {
+		endpoint  string
+		transport string
+		host      string
+		port      uint16
+	}{
+		{endpoint: "switch-1", transport: "udp", host: "switch-1", port: 161},
+		{endpoint: "switch-1:1161", transport: "udp", host: "switch-1", port: 1161},
+		{endpoint: "tcp://switch-1:1161", transport: "tcp", host: "switch-1", port: 1161},
+		{endpoint: "udp://[::1]:161", transport: "udp", host: "::1", port: 161},
+	}
+	for _, tc := range tt {
+		cfg := Config{Endpoint: tc.endpoint}
+		transport, host, port, err := cfg.parseEndpoint()
+		require.NoError(t, err, tc.endpoint)
+		require.Equal(t, tc.transport, transport, tc.endpoint)
+		require.Equal(t, tc.host, host, tc.endpoint)
+		require.Equal(t, tc.port, port, tc.endpoint)
+	}
+}
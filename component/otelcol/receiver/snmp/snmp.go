@@ -0,0 +1,161 @@
+// Package snmp provides an otelcol.receiver.snmp component.
+package snmp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/receiver"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/river"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name: "otelcol.receiver.snmp",
+		Args: Arguments{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return receiver.New(opts, newFactory(), args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.receiver.snmp component.
+type Arguments struct {
+	Endpoint           string            `river:"endpoint,attr"`
+	Version            string            `river:"version,attr,optional"`
+	Community          rivertypes.Secret `river:"community,attr,optional"`
+	CollectionInterval time.Duration     `river:"collection_interval,attr,optional"`
+	Timeout            time.Duration     `river:"timeout,attr,optional"`
+
+	Security *SecurityArguments `river:"security,block,optional"`
+	Metrics  []MetricArguments  `river:"metric,block"`
+
+	// Output configures where to send received data. Required.
+	Output *otelcol.ConsumerArguments `river:"output,block"`
+}
+
+// SecurityArguments holds the SNMPv3 credentials.
+type SecurityArguments struct {
+	User            string            `river:"user,attr"`
+	Level           string            `river:"level,attr,optional"`
+	AuthType        string            `river:"auth_type,attr,optional"`
+	AuthPassword    rivertypes.Secret `river:"auth_password,attr,optional"`
+	PrivacyType     string            `river:"privacy_type,attr,optional"`
+	PrivacyPassword rivertypes.Secret `river:"privacy_password,attr,optional"`
+}
+
+// DefaultSecurityArguments holds default settings for the SNMPv3 credentials.
+var DefaultSecurityArguments = SecurityArguments{
+	Level:       SecurityLevelNoAuthNoPriv,
+	AuthType:    "MD5",
+	PrivacyType: "DES",
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *SecurityArguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultSecurityArguments
+
+	type arguments SecurityArguments
+	return f((*arguments)(args))
+}
+
+// MetricArguments maps an SNMP object to a metric.
+type MetricArguments struct {
+	Name        string `river:"name,attr"`
+	OID         string `river:"oid,attr,optional"`
+	ColumnOID   string `river:"column_oid,attr,optional"`
+	Type        string `river:"type,attr,optional"`
+	Unit        string `river:"unit,attr,optional"`
+	Description string `river:"description,attr,optional"`
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *MetricArguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = MetricArguments{Type: MetricTypeGauge}
+
+	type arguments MetricArguments
+	return f((*arguments)(args))
+}
+
+var (
+	_ receiver.Arguments = Arguments{}
+	_ river.Unmarshaler  = (*Arguments)(nil)
+)
+
+// DefaultArguments holds default settings for otelcol.receiver.snmp.
+var DefaultArguments = Arguments{
+	Version:            Version2c,
+	Community:          "public",
+	CollectionInterval: 10 * time.Second,
+	Timeout:            5 * time.Second,
+}
+
+// UnmarshalRiver applies defaults to args before unmarshaling.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	_, err := args.Convert()
+	return err
+}
+
+// Convert implements receiver.Arguments.
+func (args Arguments) Convert() (otelconfig.Receiver, error) {
+	cfg := createDefaultConfig().(*Config)
+
+	cfg.Endpoint = args.Endpoint
+	cfg.Version = args.Version
+	cfg.Community = string(args.Community)
+	cfg.CollectionInterval = args.CollectionInterval
+	cfg.Timeout = args.Timeout
+
+	if s := args.Security; s != nil {
+		cfg.User = s.User
+		cfg.SecurityLevel = s.Level
+		cfg.AuthType = s.AuthType
+		cfg.AuthPassword = string(s.AuthPassword)
+		cfg.PrivacyType = s.PrivacyType
+		cfg.PrivacyPassword = string(s.PrivacyPassword)
+	}
+
+	for _, m := range args.Metrics {
+		cfg.Metrics = append(cfg.Metrics, MetricConfig{
+			Name:        m.Name,
+			Description: m.Description,
+			Unit:        m.Unit,
+			Type:        m.Type,
+			OID:         m.OID,
+			ColumnOID:   m.ColumnOID,
+		})
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// Extensions implements receiver.Arguments.
+func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return nil
+}
+
+// Exporters implements receiver.Arguments.
+func (args Arguments) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
+
+// NextConsumers implements receiver.Arguments.
+func (args Arguments) NextConsumers() *otelcol.ConsumerArguments {
+	return args.Output
+}
@@ -0,0 +1,143 @@
+package snmp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/otelcol/receiver/snmp"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	tt := []struct {
+		name   string
+		cfg    string
+		expect string
+	}{
+		{
+			name: "no metrics",
+			cfg: `
+				endpoint = "localhost:161"
+				output {}
+			`,
+			expect: `missing required block "metric"`,
+		},
+		{
+			name: "oid and column_oid",
+			cfg: `
+				endpoint = "localhost:161"
+				metric {
+					name       = "uptime"
+					oid        = "1.3.6.1.2.1.1.3.0"
+					column_oid = "1.3.6.1.2.1.2.2.1.10"
+				}
+				output {}
+			`,
+			expect: `exactly one of oid or column_oid must be specified for metric "uptime"`,
+		},
+		{
+			name: "unknown version",
+			cfg: `
+				endpoint = "localhost:161"
+				version  = "v4"
+				metric {
+					name = "uptime"
+					oid  = "1.3.6.1.2.1.1.3.0"
+				}
+				output {}
+			`,
+			expect: `unsupported version "v4"`,
+		},
+		{
+			name: "v3 without privacy password",
+			cfg: `
+				endpoint = "localhost:161"
+				version  = "v3"
+				security {
+					user          = "monitor"
+					level         = "auth_priv"
+					auth_type     = "SHA"
+					auth_password = "auth-secret"
+				}
+				metric {
+					name = "uptime"
+					oid  = "1.3.6.1.2.1.1.3.0"
+				}
+				output {}
+			`,
+			expect: `privacy_password must be specified for security_level "auth_priv"`,
+		},
+		{
+			name: "unknown endpoint scheme",
+			cfg: `
+				endpoint = "http://localhost:161"
+				metric {
+					name = "uptime"
+					oid  = "1.3.6.1.2.1.1.3.0"
+				}
+				output {}
+			`,
+			expect: `unsupported endpoint scheme "http"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var args snmp.Arguments
+			err := river.Unmarshal([]byte(tc.cfg), &args)
+			require.ErrorContains(t, err, tc.expect)
+		})
+	}
+}
+
+func TestArguments_Convert(t *testing.T) {
+	cfg := `
+		endpoint            = "udp://switch-1:1161"
+		version             = "v3"
+		collection_interval = "1m"
+
+		security {
+			user             = "monitor"
+			level            = "auth_priv"
+			auth_type        = "SHA256"
+			auth_password    = "auth-secret"
+			privacy_type     = "AES"
+			privacy_password = "privacy-secret"
+		}
+
+		metric {
+			name = "system.uptime"
+			oid  = "1.3.6.1.2.1.1.3.0"
+			unit = "cs"
+		}
+
+		metric {
+			name       = "network.io.receive"
+			column_oid = "1.3.6.1.2.1.2.2.1.10"
+			type       = "sum"
+			unit       = "By"
+		}
+
+		output {}
+	`
+	var args snmp.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	out, err := args.Convert()
+	require.NoError(t, err)
+
+	conf := out.(*snmp.Config)
+	require.Equal(t, "udp://switch-1:1161", conf.Endpoint)
+	require.Equal(t, snmp.Version3, conf.Version)
+	require.Equal(t, time.Minute, conf.CollectionInterval)
+	require.Equal(t, 5*time.Second, conf.Timeout)
+	require.Equal(t, "monitor", conf.User)
+	require.Equal(t, snmp.SecurityLevelAuthPriv, conf.SecurityLevel)
+	require.Equal(t, "auth-secret", conf.AuthPassword)
+	require.Equal(t, "privacy-secret", conf.PrivacyPassword)
+	require.Equal(t, []snmp.MetricConfig{
+		{Name: "system.uptime", OID: "1.3.6.1.2.1.1.3.0", Unit: "cs", Type: snmp.MetricTypeGauge},
+		{Name: "network.io.receive", ColumnOID: "1.3.6.1.2.1.2.2.1.10", Unit: "By", Type: snmp.MetricTypeSum},
+	}, conf.Metrics)
+}
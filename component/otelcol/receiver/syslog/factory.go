@@ -0,0 +1,50 @@
+package syslog
+
+import (
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/adapter"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/syslog"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+)
+
+// typeStr is the type of the upstream receiver.
+const typeStr = "syslog"
+
+// Config is the upstream configuration of the syslog receiver. The upstream
+// syslogreceiver package is a thin wrapper around the stanza syslog input
+// operator, so the receiver is built from the operator directly.
+type Config struct {
+	adapter.BaseConfig `mapstructure:",squash"`
+	InputConfig        syslog.Config `mapstructure:",squash"`
+}
+
+// newFactory creates a receiver factory for syslog receivers.
+func newFactory() otelcomponent.ReceiverFactory {
+	return adapter.NewFactory(receiverType{}, otelcomponent.StabilityLevelAlpha)
+}
+
+// receiverType implements adapter.LogReceiverType.
+type receiverType struct{}
+
+var _ adapter.LogReceiverType = receiverType{}
+
+func (receiverType) Type() otelconfig.Type { return typeStr }
+
+func (receiverType) CreateDefaultConfig() otelconfig.Receiver {
+	return &Config{
+		BaseConfig: adapter.BaseConfig{
+			ReceiverSettings: otelconfig.NewReceiverSettings(otelconfig.NewComponentID(typeStr)),
+			Operators:        []operator.Config{},
+		},
+		InputConfig: *syslog.NewConfig(),
+	}
+}
+
+func (receiverType) BaseConfig(cfg otelconfig.Receiver) adapter.BaseConfig {
+	return cfg.(*Config).BaseConfig
+}
+
+func (receiverType) InputConfig(cfg otelconfig.Receiver) operator.Config {
+	return operator.NewConfig(&cfg.(*Config).InputConfig)
+}
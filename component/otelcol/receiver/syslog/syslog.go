@@ -0,0 +1,192 @@
+// Package syslog provides an otelcol.receiver.syslog component.
+package syslog
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alecthomas/units"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/receiver"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/helper"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/tcp"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/udp"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name: "otelcol.receiver.syslog",
+		Args: Arguments{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return receiver.New(opts, newFactory(), args.(Arguments))
+		},
+	})
+}
+
+// Supported syslog protocols.
+const (
+	ProtocolRFC3164 = "rfc3164"
+	ProtocolRFC5424 = "rfc5424"
+)
+
+// Arguments configures the otelcol.receiver.syslog component.
+type Arguments struct {
+	Protocol                     string  `river:"protocol,attr,optional"`
+	Location                     string  `river:"location,attr,optional"`
+	EnableOctetCounting          bool    `river:"enable_octet_counting,attr,optional"`
+	NonTransparentFramingTrailer *string `river:"non_transparent_framing_trailer,attr,optional"`
+
+	TCP *TCPArguments `river:"tcp,block,optional"`
+	UDP *UDPArguments `river:"udp,block,optional"`
+
+	// Output configures where to send received data. Required.
+	Output *otelcol.ConsumerArguments `river:"output,block"`
+}
+
+// TCPArguments configures receiving syslog messages over TCP.
+type TCPArguments struct {
+	ListenAddress string                      `river:"listen_address,attr,optional"`
+	MaxLogSize    units.Base2Bytes            `river:"max_log_size,attr,optional"`
+	AddAttributes bool                        `river:"add_attributes,attr,optional"`
+	TLS           *otelcol.TLSServerArguments `river:"tls,block,optional"`
+}
+
+// UDPArguments configures receiving syslog messages over UDP.
+type UDPArguments struct {
+	ListenAddress string `river:"listen_address,attr,optional"`
+	AddAttributes bool   `river:"add_attributes,attr,optional"`
+}
+
+var (
+	_ receiver.Arguments = Arguments{}
+	_ river.Unmarshaler  = (*Arguments)(nil)
+	_ river.Unmarshaler  = (*TCPArguments)(nil)
+	_ river.Unmarshaler  = (*UDPArguments)(nil)
+)
+
+// DefaultArguments holds default settings for otelcol.receiver.syslog.
+var DefaultArguments = Arguments{
+	Protocol: ProtocolRFC5424,
+	Location: "UTC",
+}
+
+// DefaultTCPArguments holds default settings for the tcp block.
+var DefaultTCPArguments = TCPArguments{
+	ListenAddress: "0.0.0.0:54526",
+	MaxLogSize:    units.MiB,
+}
+
+// DefaultUDPArguments holds default settings for the udp block.
+var DefaultUDPArguments = UDPArguments{
+	ListenAddress: "0.0.0.0:54526",
+}
+
+// UnmarshalRiver applies defaults to args before unmarshaling.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	switch args.Protocol {
+	case ProtocolRFC3164, ProtocolRFC5424:
+	default:
+		return fmt.Errorf("unsupported protocol %q, expected %q or %q", args.Protocol, ProtocolRFC3164, ProtocolRFC5424)
+	}
+
+	if _, err := time.LoadLocation(args.Location); err != nil {
+		return fmt.Errorf("invalid location %q: %w", args.Location, err)
+	}
+
+	if t := args.NonTransparentFramingTrailer; t != nil && *t != "LF" && *t != "NUL" {
+		return fmt.Errorf(`non_transparent_framing_trailer must be "LF" or "NUL", got %q`, *t)
+	}
+	if args.EnableOctetCounting && args.NonTransparentFramingTrailer != nil {
+		return fmt.Errorf("enable_octet_counting and non_transparent_framing_trailer are mutually exclusive")
+	}
+
+	switch {
+	case args.TCP == nil && args.UDP == nil:
+		return fmt.Errorf("exactly one of the tcp or udp blocks must be provided")
+	case args.TCP != nil && args.UDP != nil:
+		return fmt.Errorf("only one of the tcp or udp blocks may be provided")
+	case args.UDP != nil && (args.EnableOctetCounting || args.NonTransparentFramingTrailer != nil):
+		return fmt.Errorf("enable_octet_counting and non_transparent_framing_trailer are not supported with udp")
+	case args.Protocol != ProtocolRFC5424 && (args.EnableOctetCounting || args.NonTransparentFramingTrailer != nil):
+		return fmt.Errorf("enable_octet_counting and non_transparent_framing_trailer are only supported with the %s protocol", ProtocolRFC5424)
+	}
+
+	return nil
+}
+
+// UnmarshalRiver applies defaults to args before unmarshaling.
+func (args *TCPArguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultTCPArguments
+
+	type arguments TCPArguments
+	return f((*arguments)(args))
+}
+
+// UnmarshalRiver applies defaults to args before unmarshaling.
+func (args *UDPArguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultUDPArguments
+
+	type arguments UDPArguments
+	return f((*arguments)(args))
+}
+
+// Convert implements receiver.Arguments.
+func (args Arguments) Convert() (otelconfig.Receiver, error) {
+	cfg := newFactory().CreateDefaultConfig().(*Config)
+
+	cfg.InputConfig.Protocol = args.Protocol
+	cfg.InputConfig.Location = args.Location
+	cfg.InputConfig.EnableOctetCounting = args.EnableOctetCounting
+	cfg.InputConfig.NonTransparentFramingTrailer = args.NonTransparentFramingTrailer
+
+	if args.TCP != nil {
+		cfg.InputConfig.TCP = &tcp.BaseConfig{
+			MaxLogSize:    helper.ByteSize(args.TCP.MaxLogSize),
+			ListenAddress: args.TCP.ListenAddress,
+			TLS:           args.TCP.TLS.Convert(),
+			AddAttributes: args.TCP.AddAttributes,
+			Encoding:      helper.NewEncodingConfig(),
+			Multiline:     helper.NewMultilineConfig(),
+		}
+	}
+	if args.UDP != nil {
+		cfg.InputConfig.UDP = &udp.BaseConfig{
+			ListenAddress: args.UDP.ListenAddress,
+			AddAttributes: args.UDP.AddAttributes,
+			Encoding:      helper.NewEncodingConfig(),
+			Multiline: helper.MultilineConfig{
+				// Never split UDP datagrams into multiple messages.
+				LineEndPattern: ".^",
+			},
+		}
+	}
+
+	return cfg, nil
+}
+
+// Extensions implements receiver.Arguments.
+func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return nil
+}
+
+// Exporters implements receiver.Arguments.
+func (args Arguments) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
+
+// NextConsumers implements receiver.Arguments.
+func (args Arguments) NextConsumers() *otelcol.ConsumerArguments {
+	return args.Output
+}
@@ -0,0 +1,202 @@
+package syslog_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/otelcol"
+	"github.com/grafana/agent/component/otelcol/internal/fakeconsumer"
+	"github.com/grafana/agent/component/otelcol/receiver/syslog"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestRun(t *testing.T) {
+	addr := getFreeAddr(t)
+
+	ctx := componenttest.TestContext(t)
+	l := util.TestLogger(t)
+
+	ctrl, err := componenttest.NewControllerFromID(l, "otelcol.receiver.syslog")
+	require.NoError(t, err)
+
+	cfg := fmt.Sprintf(`
+		protocol = "rfc5424"
+
+		udp {
+			listen_address = "%s"
+		}
+
+		output { /* no-op */ }
+	`, addr)
+
+	var args syslog.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	logCh := make(chan plog.Logs)
+	args.Output = makeLogsOutput(logCh)
+
+	go func() {
+		err := ctrl.Run(ctx, args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, ctrl.WaitRunning(time.Second))
+
+	conn, err := net.Dial("udp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	msg := `<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 - 'su root' failed for lonvick on /dev/pts/8`
+
+	// The receiver may not be listening yet, so keep sending until a log is
+	// received.
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case <-ticker.C:
+			_, err := conn.Write([]byte(msg))
+			require.NoError(t, err)
+		case <-timeout:
+			require.FailNow(t, "failed waiting for logs")
+		case logs := <-logCh:
+			require.Equal(t, 1, logs.LogRecordCount())
+
+			rec := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+			attrs := rec.Attributes().AsRaw()
+			require.Equal(t, "mymachine.example.com", attrs["hostname"])
+			require.Equal(t, "su", attrs["appname"])
+			require.Equal(t, "'su root' failed for lonvick on /dev/pts/8", attrs["message"])
+			return
+		}
+	}
+}
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	tt := []struct {
+		name   string
+		cfg    string
+		expect string
+	}{
+		{
+			name:   "no listener",
+			cfg:    `output {}`,
+			expect: "exactly one of the tcp or udp blocks must be provided",
+		},
+		{
+			name: "both listeners",
+			cfg: `
+				tcp {}
+				udp {}
+				output {}
+			`,
+			expect: "only one of the tcp or udp blocks may be provided",
+		},
+		{
+			name: "unknown protocol",
+			cfg: `
+				protocol = "rfc9999"
+				tcp {}
+				output {}
+			`,
+			expect: `unsupported protocol "rfc9999"`,
+		},
+		{
+			name: "octet counting over udp",
+			cfg: `
+				enable_octet_counting = true
+				udp {}
+				output {}
+			`,
+			expect: "not supported with udp",
+		},
+		{
+			name: "framing with rfc3164",
+			cfg: `
+				protocol                        = "rfc3164"
+				non_transparent_framing_trailer = "LF"
+				tcp {}
+				output {}
+			`,
+			expect: "only supported with the rfc5424 protocol",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var args syslog.Arguments
+			err := river.Unmarshal([]byte(tc.cfg), &args)
+			require.ErrorContains(t, err, tc.expect)
+		})
+	}
+}
+
+func TestArguments_Convert(t *testing.T) {
+	cfg := `
+		protocol              = "rfc5424"
+		enable_octet_counting = true
+
+		tcp {
+			listen_address = "127.0.0.1:1514"
+			max_log_size   = "2MiB"
+
+			tls {
+				cert_file = "/etc/certs/cert.pem"
+				key_file  = "/etc/certs/key.pem"
+			}
+		}
+
+		output {}
+	`
+	var args syslog.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	out, err := args.Convert()
+	require.NoError(t, err)
+
+	conf := out.(*syslog.Config)
+	require.Equal(t, "rfc5424", conf.InputConfig.Protocol)
+	require.True(t, conf.InputConfig.EnableOctetCounting)
+	require.Nil(t, conf.InputConfig.UDP)
+	require.Equal(t, "127.0.0.1:1514", conf.InputConfig.TCP.ListenAddress)
+	require.EqualValues(t, 2*1024*1024, conf.InputConfig.TCP.MaxLogSize)
+	require.Equal(t, "/etc/certs/cert.pem", conf.InputConfig.TCP.TLS.CertFile)
+}
+
+// makeLogsOutput returns ConsumerArguments which will forward logs to the
+// provided channel.
+func makeLogsOutput(ch chan plog.Logs) *otelcol.ConsumerArguments {
+	logsConsumer := fakeconsumer.Consumer{
+		ConsumeLogsFunc: func(ctx context.Context, l plog.Logs) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ch <- l:
+				return nil
+			}
+		},
+	}
+
+	return &otelcol.ConsumerArguments{
+		Logs: []otelcol.Consumer{&logsConsumer},
+	}
+}
+
+func getFreeAddr(t *testing.T) string {
+	t.Helper()
+
+	portNumber, err := freeport.GetFreePort()
+	require.NoError(t, err)
+
+	return fmt.Sprintf("localhost:%d", portNumber)
+}
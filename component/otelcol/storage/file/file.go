@@ -0,0 +1,98 @@
+// Package file provides an otelcol.storage.file component.
+package file
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alecthomas/units"
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/otelcol/storage"
+	"github.com/grafana/agent/component/otelcol/storage/file/internal/filestorage"
+	"github.com/grafana/agent/pkg/river"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "otelcol.storage.file",
+		Args:    Arguments{},
+		Exports: storage.Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			// Storage files are kept in the data directory of the component
+			// unless a directory is configured.
+			return storage.New(opts, filestorage.NewFactory(opts.DataPath), args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.storage.file component.
+type Arguments struct {
+	Directory  string              `river:"directory,attr,optional"`
+	Timeout    time.Duration       `river:"timeout,attr,optional"`
+	Compaction CompactionArguments `river:"compaction,block,optional"`
+}
+
+// CompactionArguments configures the compaction of storage files.
+type CompactionArguments struct {
+	OnStart            bool             `river:"on_start,attr,optional"`
+	Directory          string           `river:"directory,attr,optional"`
+	MaxTransactionSize units.Base2Bytes `river:"max_transaction_size,attr,optional"`
+}
+
+var (
+	_ storage.Arguments = Arguments{}
+	_ river.Unmarshaler = (*Arguments)(nil)
+)
+
+// DefaultArguments holds default settings for otelcol.storage.file.
+var DefaultArguments = Arguments{
+	Timeout: time.Second,
+	Compaction: CompactionArguments{
+		MaxTransactionSize: 64 * units.KiB,
+	},
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	if args.Timeout <= 0 {
+		return fmt.Errorf("timeout must be greater than 0")
+	}
+	if args.Compaction.MaxTransactionSize < 0 {
+		return fmt.Errorf("max_transaction_size must not be negative")
+	}
+	return nil
+}
+
+// Convert implements storage.Arguments.
+func (args Arguments) Convert() (otelconfig.Extension, error) {
+	return &filestorage.Config{
+		ExtensionSettings: otelconfig.NewExtensionSettings(otelconfig.NewComponentID("file_storage")),
+		Directory:         args.Directory,
+		Timeout:           args.Timeout,
+		Compaction: filestorage.CompactionConfig{
+			OnStart:            args.Compaction.OnStart,
+			Directory:          args.Compaction.Directory,
+			MaxTransactionSize: int64(args.Compaction.MaxTransactionSize),
+		},
+	}, nil
+}
+
+// Extensions implements storage.Arguments.
+func (args Arguments) Extensions() map[otelconfig.ComponentID]otelcomponent.Extension {
+	return nil
+}
+
+// Exporters implements storage.Arguments.
+func (args Arguments) Exporters() map[otelconfig.DataType]map[otelconfig.ComponentID]otelcomponent.Exporter {
+	return nil
+}
@@ -0,0 +1,103 @@
+package file_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/units"
+	"github.com/grafana/agent/component/otelcol/storage"
+	"github.com/grafana/agent/component/otelcol/storage/file"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/stretchr/testify/require"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconfig "go.opentelemetry.io/collector/config"
+	otelstorage "go.opentelemetry.io/collector/extension/experimental/storage"
+)
+
+// Test performs a basic integration test which runs the otelcol.storage.file
+// component and ensures that clients can persist data in its directory.
+func Test(t *testing.T) {
+	ctx := componenttest.TestContext(t)
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	l := util.TestLogger(t)
+
+	ctrl, err := componenttest.NewControllerFromID(l, "otelcol.storage.file")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	cfg := fmt.Sprintf(`
+		directory = %q
+	`, dir)
+	var args file.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	go func() {
+		err := ctrl.Run(ctx, args)
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, ctrl.WaitRunning(time.Second), "component never started")
+	require.NoError(t, ctrl.WaitExports(time.Second), "component never exported anything")
+
+	exports := ctrl.Exports().(storage.Exports)
+	require.NotNil(t, exports.Handler.Extension, "handler extension is nil")
+
+	ext, ok := exports.Handler.Extension.(otelstorage.Extension)
+	require.True(t, ok, "handler does not implement storage.Extension")
+
+	client, err := ext.GetClient(ctx, otelcomponent.KindExporter, otelconfig.NewComponentID("otlp"), "traces")
+	require.NoError(t, err)
+	defer client.Close(ctx)
+
+	require.NoError(t, client.Set(ctx, "key", []byte("value")))
+	value, err := client.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), value)
+
+	_, err = os.Stat(filepath.Join(dir, "exporter_otlp_traces"))
+	require.NoError(t, err)
+}
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		var args file.Arguments
+		require.NoError(t, river.Unmarshal([]byte(``), &args))
+		require.Equal(t, file.DefaultArguments, args)
+	})
+
+	t.Run("custom settings", func(t *testing.T) {
+		cfg := `
+			directory = "/var/lib/agent/storage"
+			timeout   = "5s"
+
+			compaction {
+				on_start             = true
+				max_transaction_size = "1MiB"
+			}
+		`
+		var args file.Arguments
+		require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+		require.Equal(t, file.Arguments{
+			Directory: "/var/lib/agent/storage",
+			Timeout:   5 * time.Second,
+			Compaction: file.CompactionArguments{
+				OnStart:            true,
+				MaxTransactionSize: units.MiB,
+			},
+		}, args)
+	})
+
+	t.Run("invalid timeout", func(t *testing.T) {
+		var args file.Arguments
+		err := river.Unmarshal([]byte(`timeout = "0s"`), &args)
+		require.EqualError(t, err, "timeout must be greater than 0")
+	})
+}
@@ -0,0 +1,232 @@
+package filestorage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.uber.org/zap"
+)
+
+// defaultBucket is the bucket of a database file which holds the data of a
+// client.
+var defaultBucket = []byte("default")
+
+// fileStorage is the storage extension. Every client stores its data in a
+// separate database file, named after the component which owns it.
+type fileStorage struct {
+	cfg *Config
+	log *zap.Logger
+}
+
+var _ storage.Extension = (*fileStorage)(nil)
+
+func newExtension(cfg *Config, log *zap.Logger) *fileStorage {
+	return &fileStorage{cfg: cfg, log: log}
+}
+
+// Start implements component.Component.
+func (fs *fileStorage) Start(context.Context, component.Host) error { return nil }
+
+// Shutdown implements component.Component. Clients are closed by the
+// components which own them.
+func (fs *fileStorage) Shutdown(context.Context) error { return nil }
+
+// GetClient implements storage.Extension.
+func (fs *fileStorage) GetClient(_ context.Context, kind component.Kind, id config.ComponentID, name string) (storage.Client, error) {
+	fileName := clientFileName(kind, id, name)
+	path := filepath.Join(fs.cfg.Directory, fileName)
+
+	if fs.cfg.Compaction.OnStart {
+		if err := fs.compact(path, fileName); err != nil {
+			// A failed compaction leaves the original file in place, which can
+			// still be used.
+			fs.log.Warn("failed to compact storage file", zap.String("path", path), zap.Error(err))
+		}
+	}
+
+	db, err := fs.open(path)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(defaultBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialize storage file %q: %w", path, err)
+	}
+	return &client{db: db}, nil
+}
+
+func (fs *fileStorage) open(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{
+		Timeout:        fs.cfg.Timeout,
+		NoSync:         true,
+		NoFreelistSync: true,
+		FreelistType:   bolt.FreelistMapType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage file %q: %w", path, err)
+	}
+	return db, nil
+}
+
+// compact rewrites the database file at path into a temporary file, which
+// then replaces the original file.
+func (fs *fileStorage) compact(path, fileName string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	tmpFile, err := os.CreateTemp(fs.cfg.Compaction.Directory, fileName+".compact-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpFile.Name()
+	_ = tmpFile.Close()
+	defer os.Remove(tmpPath)
+
+	src, err := fs.open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := fs.open(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	if err := bolt.Compact(dst, src, fs.cfg.Compaction.MaxTransactionSize); err != nil {
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	if err := src.Close(); err != nil {
+		return err
+	}
+	return moveFile(tmpPath, path)
+}
+
+// moveFile renames src to dst, falling back to copying when they're on
+// different file systems.
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	bb, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, bb, 0600)
+}
+
+// clientFileName returns the name of the database file of a client.
+func clientFileName(kind component.Kind, id config.ComponentID, name string) string {
+	parts := []string{kindString(kind), string(id.Type())}
+	if id.Name() != "" {
+		parts = append(parts, id.Name())
+	}
+	if name != "" {
+		parts = append(parts, name)
+	}
+	return sanitizeFileName(strings.Join(parts, "_"))
+}
+
+func kindString(kind component.Kind) string {
+	switch kind {
+	case component.KindReceiver:
+		return "receiver"
+	case component.KindProcessor:
+		return "processor"
+	case component.KindExporter:
+		return "exporter"
+	case component.KindExtension:
+		return "extension"
+	default:
+		return "other"
+	}
+}
+
+// sanitizeFileName replaces characters which aren't safe in file names, such
+// as the slashes and dots in the IDs of Flow components.
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		default:
+			return '~'
+		}
+	}, s)
+}
+
+// client stores the data of a single component in a database file.
+type client struct {
+	db *bolt.DB
+}
+
+var _ storage.Client = (*client)(nil)
+
+// Get implements storage.Client.
+func (c *client) Get(ctx context.Context, key string) ([]byte, error) {
+	op := storage.GetOperation(key)
+	if err := c.Batch(ctx, op); err != nil {
+		return nil, err
+	}
+	return op.Value, nil
+}
+
+// Set implements storage.Client.
+func (c *client) Set(ctx context.Context, key string, value []byte) error {
+	return c.Batch(ctx, storage.SetOperation(key, value))
+}
+
+// Delete implements storage.Client.
+func (c *client) Delete(ctx context.Context, key string) error {
+	return c.Batch(ctx, storage.DeleteOperation(key))
+}
+
+// Batch implements storage.Client. All operations are applied in a single
+// transaction.
+func (c *client) Batch(_ context.Context, ops ...storage.Operation) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(defaultBucket)
+		for _, op := range ops {
+			var err error
+			switch op.Type {
+			case storage.Get:
+				if v := bucket.Get([]byte(op.Key)); v != nil {
+					// The value is only valid during the transaction.
+					op.Value = append([]byte(nil), v...)
+				} else {
+					op.Value = nil
+				}
+			case storage.Set:
+				err = bucket.Put([]byte(op.Key), op.Value)
+			case storage.Delete:
+				err = bucket.Delete([]byte(op.Key))
+			default:
+				err = fmt.Errorf("unknown storage operation %v", op.Type)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Close implements storage.Client.
+func (c *client) Close(context.Context) error {
+	return c.db.Close()
+}
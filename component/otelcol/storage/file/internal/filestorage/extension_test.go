@@ -0,0 +1,105 @@
+package filestorage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+)
+
+func newTestExtension(t *testing.T, defaultDir string, cfg *Config) storage.Extension {
+	t.Helper()
+
+	ext, err := NewFactory(defaultDir).CreateExtension(context.Background(), componenttest.NewNopExtensionCreateSettings(), cfg)
+	require.NoError(t, err)
+	return ext.(storage.Extension)
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	ext := newTestExtension(t, dir, createDefaultConfig().(*Config))
+
+	client, err := ext.GetClient(ctx, component.KindReceiver, config.NewComponentIDWithName("filelog", "default"), "")
+	require.NoError(t, err)
+
+	value, err := client.Get(ctx, "missing")
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	require.NoError(t, client.Set(ctx, "a", []byte("1")))
+	require.NoError(t, client.Batch(ctx,
+		storage.SetOperation("b", []byte("2")),
+		storage.DeleteOperation("a"),
+	))
+
+	get := storage.GetOperation("b")
+	require.NoError(t, client.Batch(ctx, get))
+	require.Equal(t, []byte("2"), get.Value)
+
+	value, err = client.Get(ctx, "a")
+	require.NoError(t, err)
+	require.Nil(t, value)
+	require.NoError(t, client.Close(ctx))
+
+	// Data must survive reopening the client, and the database file must be
+	// kept in the default directory.
+	_, err = os.Stat(filepath.Join(dir, "receiver_filelog_default"))
+	require.NoError(t, err)
+
+	client, err = ext.GetClient(ctx, component.KindReceiver, config.NewComponentIDWithName("filelog", "default"), "")
+	require.NoError(t, err)
+	defer client.Close(ctx)
+
+	value, err = client.Get(ctx, "b")
+	require.NoError(t, err)
+	require.Equal(t, []byte("2"), value)
+}
+
+func TestCompaction(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	id := config.NewComponentID("otlp")
+
+	ext := newTestExtension(t, dir, createDefaultConfig().(*Config))
+	client, err := ext.GetClient(ctx, component.KindExporter, id, "traces")
+	require.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, client.Set(ctx, fmt.Sprint(i), make([]byte, 1024)))
+	}
+	for i := 1; i < 1000; i++ {
+		require.NoError(t, client.Delete(ctx, fmt.Sprint(i)))
+	}
+	require.NoError(t, client.Close(ctx))
+
+	path := filepath.Join(dir, "exporter_otlp_traces")
+	before, err := os.Stat(path)
+	require.NoError(t, err)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Compaction.OnStart = true
+	ext = newTestExtension(t, dir, cfg)
+	client, err = ext.GetClient(ctx, component.KindExporter, id, "traces")
+	require.NoError(t, err)
+	defer client.Close(ctx)
+
+	after, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Less(t, after.Size(), before.Size())
+
+	value, err := client.Get(ctx, "0")
+	require.NoError(t, err)
+	require.Len(t, value, 1024)
+}
+
+func TestClientFileName(t *testing.T) {
+	name := clientFileName(component.KindExporter, config.NewComponentIDWithName("otlp", "otelcol.exporter.otlp.default"), "traces")
+	require.Equal(t, "exporter_otlp_otelcol~exporter~otlp~default_traces", name)
+}
@@ -0,0 +1,101 @@
+// Package filestorage implements an OpenTelemetry Collector storage extension
+// which persists state in bbolt databases on disk, one file per client. It's
+// modeled after the file_storage extension from
+// opentelemetry-collector-contrib.
+package filestorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+)
+
+// typeStr is the type of the extension.
+const typeStr = "file_storage"
+
+// Config configures the file storage extension.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"`
+
+	// Directory holds the database files of the clients. Defaults to the
+	// directory passed to NewFactory.
+	Directory string `mapstructure:"directory"`
+	// Timeout is how long to wait for the lock on a database file.
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	Compaction CompactionConfig `mapstructure:"compaction"`
+}
+
+// CompactionConfig configures the compaction of database files, which
+// reclaims the space of deleted data.
+type CompactionConfig struct {
+	// OnStart compacts the database file of every client before it's opened.
+	OnStart bool `mapstructure:"on_start"`
+	// Directory holds the temporary files used during compaction. Defaults to
+	// the storage directory.
+	Directory string `mapstructure:"directory"`
+	// MaxTransactionSize is the maximum number of bytes copied in a single
+	// transaction during compaction.
+	MaxTransactionSize int64 `mapstructure:"max_transaction_size"`
+}
+
+var _ config.Extension = (*Config)(nil)
+
+// Validate checks that cfg is valid.
+func (cfg *Config) Validate() error {
+	if cfg.Timeout <= 0 {
+		return errors.New("timeout must be greater than 0")
+	}
+	if cfg.Compaction.MaxTransactionSize < 0 {
+		return errors.New("max_transaction_size must not be negative")
+	}
+	return nil
+}
+
+// NewFactory creates a factory for the file storage extension. Database
+// files are kept in defaultDirectory unless a directory is configured.
+func NewFactory(defaultDirectory string) component.ExtensionFactory {
+	return component.NewExtensionFactory(
+		typeStr,
+		createDefaultConfig,
+		func(ctx context.Context, set component.ExtensionCreateSettings, cfg config.Extension) (component.Extension, error) {
+			return createExtension(ctx, set, cfg, defaultDirectory)
+		},
+		component.StabilityLevelAlpha,
+	)
+}
+
+func createDefaultConfig() config.Extension {
+	return &Config{
+		ExtensionSettings: config.NewExtensionSettings(config.NewComponentID(typeStr)),
+		Timeout:           time.Second,
+		Compaction: CompactionConfig{
+			MaxTransactionSize: 65536,
+		},
+	}
+}
+
+func createExtension(_ context.Context, set component.ExtensionCreateSettings, cfg config.Extension, defaultDirectory string) (component.Extension, error) {
+	c := *cfg.(*Config)
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if c.Directory == "" {
+		c.Directory = defaultDirectory
+	}
+	if c.Directory == "" {
+		return nil, errors.New("directory must be specified")
+	}
+	if c.Compaction.Directory == "" {
+		c.Compaction.Directory = c.Directory
+	}
+	if err := os.MkdirAll(c.Directory, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return newExtension(&c, set.Logger), nil
+}
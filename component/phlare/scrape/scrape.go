@@ -219,7 +219,10 @@ type Component struct {
 	appendable *phlare.Fanout
 }
 
-var _ component.Component = (*Component)(nil)
+var (
+	_ component.Component       = (*Component)(nil)
+	_ component.ScrapeComponent = (*Component)(nil)
+)
 
 // New creates a new pprof.scrape component.
 func New(o component.Options, args Arguments) (*Component, error) {
@@ -321,6 +324,15 @@ func convertLabelSet(tg discovery.Target) model.LabelSet {
 // DebugInfo implements component.DebugComponent
 func (c *Component) DebugInfo() interface{} {
 	var res []scrape.TargetStatus
+	for _, t := range c.ScrapeTargets() {
+		res = append(res, scrape.TargetStatus(t))
+	}
+	return scrape.ScraperStatus{TargetStatus: res}
+}
+
+// ScrapeTargets implements component.ScrapeComponent.
+func (c *Component) ScrapeTargets() []component.ScrapeTarget {
+	var res []component.ScrapeTarget
 
 	for job, stt := range c.scraper.TargetsActive() {
 		for _, st := range stt {
@@ -329,7 +341,7 @@ func (c *Component) DebugInfo() interface{} {
 				lastError = st.LastError().Error()
 			}
 			if st != nil {
-				res = append(res, scrape.TargetStatus{
+				res = append(res, component.ScrapeTarget{
 					JobName:            job,
 					URL:                st.URL().String(),
 					Health:             string(st.Health()),
@@ -342,5 +354,5 @@ func (c *Component) DebugInfo() interface{} {
 		}
 	}
 
-	return scrape.ScraperStatus{TargetStatus: res}
+	return res
 }
@@ -0,0 +1,145 @@
+package prometheus
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/metadata"
+	"github.com/prometheus/prometheus/storage"
+)
+
+// Sample is a float sample passed between components in a batch.
+type Sample struct {
+	Ref    storage.SeriesRef
+	Labels labels.Labels
+	T      int64
+	V      float64
+}
+
+// BatchAppender is a storage.Appender which can append a batch of samples at
+// once. Components implement it to process a batch with a single acquisition
+// of their locks rather than one per sample.
+type BatchAppender interface {
+	storage.Appender
+
+	// AppendBatch appends samples. Samples with a Ref of 0 may have their
+	// global ref assigned in place. Samples which fail to be appended don't
+	// prevent the remaining samples from being appended; their errors are
+	// combined into the returned error.
+	AppendBatch(samples []Sample) error
+}
+
+// AppendBatch appends samples to app. If app is a BatchAppender, the samples
+// are appended in a single call. Otherwise, every sample is appended with a
+// separate call to Append.
+func AppendBatch(app storage.Appender, samples []Sample) error {
+	if ba, ok := app.(BatchAppender); ok {
+		return ba.AppendBatch(samples)
+	}
+
+	var multiErr error
+	for _, s := range samples {
+		if _, err := app.Append(s.Ref, s.Labels, s.T, s.V); err != nil {
+			multiErr = multierror.Append(multiErr, err)
+		}
+	}
+	return multiErr
+}
+
+// Batcher is a storage.Appendable which collects the samples appended to its
+// appenders and passes them to the next appendable in a single batch when
+// they're committed. It lets producers which append one sample at a time,
+// such as scrape loops, take advantage of components implementing
+// BatchAppender.
+//
+// Errors from appending the batch are returned by Commit rather than by
+// Append.
+type Batcher struct {
+	next storage.Appendable
+}
+
+var _ storage.Appendable = (*Batcher)(nil)
+
+// NewBatcher creates a Batcher which passes batches of samples to next.
+func NewBatcher(next storage.Appendable) *Batcher {
+	return &Batcher{next: next}
+}
+
+// Appender satisfies the Appendable interface.
+func (b *Batcher) Appender(ctx context.Context) storage.Appender {
+	return &batchingAppender{next: b.next.Appender(ctx)}
+}
+
+type batchingAppender struct {
+	next    storage.Appender
+	pending []Sample
+	err     error
+}
+
+var _ storage.Appender = (*batchingAppender)(nil)
+
+// Append satisfies the Appender interface. The sample is appended to the next
+// appender when the batch is flushed.
+func (a *batchingAppender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v float64) (storage.SeriesRef, error) {
+	// The global ref is returned right away so that callers caching refs,
+	// like scrape loops, don't look it up again on their next append.
+	if ref == 0 {
+		ref = storage.SeriesRef(GlobalRefMapping.GetOrAddGlobalRefID(l))
+	}
+	a.pending = append(a.pending, Sample{Ref: ref, Labels: l, T: t, V: v})
+	return ref, nil
+}
+
+// flush appends the pending samples to the next appender.
+func (a *batchingAppender) flush() {
+	if len(a.pending) == 0 {
+		return
+	}
+	if err := AppendBatch(a.next, a.pending); err != nil {
+		a.err = multierror.Append(a.err, err)
+	}
+	a.pending = a.pending[:0]
+}
+
+// Commit satisfies the Appender interface.
+func (a *batchingAppender) Commit() error {
+	a.flush()
+	var multiErr error
+	if a.err != nil {
+		multiErr = multierror.Append(multiErr, a.err)
+	}
+	if err := a.next.Commit(); err != nil {
+		multiErr = multierror.Append(multiErr, err)
+	}
+	return multiErr
+}
+
+// Rollback satisfies the Appender interface.
+func (a *batchingAppender) Rollback() error {
+	a.pending = nil
+	return a.next.Rollback()
+}
+
+// AppendExemplar satisfies the Appender interface. Pending samples are
+// flushed first so that the exemplar follows its sample.
+func (a *batchingAppender) AppendExemplar(ref storage.SeriesRef, l labels.Labels, e exemplar.Exemplar) (storage.SeriesRef, error) {
+	a.flush()
+	return a.next.AppendExemplar(ref, l, e)
+}
+
+// UpdateMetadata satisfies the Appender interface. Pending samples are
+// flushed first.
+func (a *batchingAppender) UpdateMetadata(ref storage.SeriesRef, l labels.Labels, m metadata.Metadata) (storage.SeriesRef, error) {
+	a.flush()
+	return a.next.UpdateMetadata(ref, l, m)
+}
+
+// AppendHistogram satisfies the Appender interface. Pending samples are
+// flushed first.
+func (a *batchingAppender) AppendHistogram(ref storage.SeriesRef, l labels.Labels, t int64, h *histogram.Histogram) (storage.SeriesRef, error) {
+	a.flush()
+	return a.next.AppendHistogram(ref, l, t, h)
+}
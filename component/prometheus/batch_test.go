@@ -0,0 +1,186 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/metadata"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatcher(t *testing.T) {
+	var rec recordingAppendable
+	batcher := NewBatcher(NewFanout([]storage.Appendable{&rec}, "batcher", prometheus.NewRegistry()))
+
+	app := batcher.Appender(context.Background())
+	a, b := labels.FromStrings("series", "a"), labels.FromStrings("series", "b")
+	refA, err := app.Append(0, a, 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, storage.SeriesRef(GlobalRefMapping.GetOrAddGlobalRefID(a)), refA)
+	_, err = app.Append(0, b, 1, 2)
+	require.NoError(t, err)
+
+	// Samples are held back until they're committed or flushed by a call
+	// which must follow them.
+	require.Empty(t, rec.calls)
+	_, err = app.AppendExemplar(refA, a, exemplar.Exemplar{Value: 1})
+	require.NoError(t, err)
+	_, err = app.Append(refA, a, 2, 3)
+	require.NoError(t, err)
+	require.NoError(t, app.Commit())
+
+	require.Equal(t, []string{
+		`append {series="a"} 1 1`,
+		`append {series="b"} 1 2`,
+		`exemplar {series="a"}`,
+		`append {series="a"} 2 3`,
+		"commit",
+	}, rec.calls)
+}
+
+func TestBatcher_Rollback(t *testing.T) {
+	var rec recordingAppendable
+	batcher := NewBatcher(&rec)
+
+	app := batcher.Appender(context.Background())
+	_, err := app.Append(0, labels.FromStrings("series", "a"), 1, 1)
+	require.NoError(t, err)
+	require.NoError(t, app.Rollback())
+	require.Equal(t, []string{"rollback"}, rec.calls)
+}
+
+func TestInterceptor_AppendBatch(t *testing.T) {
+	var rec recordingAppendable
+	interceptor := NewInterceptor(&rec, WithAppendHook(func(ref storage.SeriesRef, l labels.Labels, t int64, v float64, next storage.Appender) (storage.SeriesRef, error) {
+		return next.Append(ref, l, t, v*10)
+	}))
+
+	// Without a batch hook, the batch is passed to the append hook one sample
+	// at a time.
+	app := interceptor.Appender(context.Background()).(BatchAppender)
+	samples := []Sample{
+		{Labels: labels.FromStrings("series", "a"), T: 1, V: 1},
+		{Labels: labels.FromStrings("series", "b"), T: 1, V: 2},
+	}
+	require.NoError(t, app.AppendBatch(samples))
+	require.Equal(t, []string{
+		`append {series="a"} 1 10`,
+		`append {series="b"} 1 20`,
+	}, rec.calls)
+	for _, s := range samples {
+		require.Equal(t, storage.SeriesRef(GlobalRefMapping.GetOrAddGlobalRefID(s.Labels)), s.Ref)
+	}
+}
+
+func BenchmarkAppend(b *testing.B) {
+	const numSeries = 1000
+
+	samples := make([]Sample, numSeries)
+	for i := range samples {
+		samples[i] = Sample{Labels: labels.FromStrings("__name__", "metric", "series", fmt.Sprint(i))}
+	}
+
+	// The pipeline resembles prometheus.scrape forwarding to a relabeling
+	// component, which forwards to a remote_write component.
+	newPipeline := func() storage.Appendable {
+		sink := NewInterceptor(nil, WithAppendHook(func(ref storage.SeriesRef, _ labels.Labels, _ int64, _ float64, _ storage.Appender) (storage.SeriesRef, error) {
+			return ref, nil
+		}), WithAppendBatchHook(func(_ []Sample, _ storage.Appender) error {
+			return nil
+		}))
+		fanout := NewFanout([]storage.Appendable{sink}, "relabel", prometheus.NewRegistry())
+		relabel := NewInterceptor(fanout, WithAppendHook(func(_ storage.SeriesRef, l labels.Labels, t int64, v float64, next storage.Appender) (storage.SeriesRef, error) {
+			return next.Append(0, l, t, v)
+		}), WithAppendBatchHook(func(samples []Sample, next storage.Appender) error {
+			out := make([]Sample, len(samples))
+			for i, s := range samples {
+				out[i] = Sample{Labels: s.Labels, T: s.T, V: s.V}
+			}
+			return AppendBatch(next, out)
+		}))
+		return NewFanout([]storage.Appendable{relabel}, "scrape", prometheus.NewRegistry())
+	}
+
+	// Appends run in parallel, like the scrape loops of many targets, so that
+	// the benchmark includes contention on the locks.
+	b.Run("per sample", func(b *testing.B) {
+		pipeline := newPipeline()
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				app := pipeline.Appender(context.Background())
+				for _, s := range samples {
+					_, _ = app.Append(0, s.Labels, 1, 1)
+				}
+				_ = app.Commit()
+			}
+		})
+	})
+
+	b.Run("batch", func(b *testing.B) {
+		pipeline := newPipeline()
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			batch := make([]Sample, numSeries)
+			for pb.Next() {
+				copy(batch, samples)
+				for j := range batch {
+					batch[j].T = 1
+					batch[j].V = 1
+				}
+				app := pipeline.Appender(context.Background())
+				_ = AppendBatch(app, batch)
+				_ = app.Commit()
+			}
+		})
+	})
+}
+
+// recordingAppendable records the calls made to its appenders.
+type recordingAppendable struct {
+	calls []string
+}
+
+func (r *recordingAppendable) Appender(_ context.Context) storage.Appender {
+	return &recordingAppender{r: r}
+}
+
+type recordingAppender struct {
+	r *recordingAppendable
+}
+
+func (a *recordingAppender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v float64) (storage.SeriesRef, error) {
+	a.r.calls = append(a.r.calls, fmt.Sprintf("append %s %d %v", l, t, v))
+	return ref, nil
+}
+
+func (a *recordingAppender) AppendExemplar(ref storage.SeriesRef, l labels.Labels, _ exemplar.Exemplar) (storage.SeriesRef, error) {
+	a.r.calls = append(a.r.calls, fmt.Sprintf("exemplar %s", l))
+	return ref, nil
+}
+
+func (a *recordingAppender) AppendHistogram(ref storage.SeriesRef, l labels.Labels, _ int64, _ *histogram.Histogram) (storage.SeriesRef, error) {
+	a.r.calls = append(a.r.calls, fmt.Sprintf("histogram %s", l))
+	return ref, nil
+}
+
+func (a *recordingAppender) UpdateMetadata(ref storage.SeriesRef, l labels.Labels, _ metadata.Metadata) (storage.SeriesRef, error) {
+	a.r.calls = append(a.r.calls, fmt.Sprintf("metadata %s", l))
+	return ref, nil
+}
+
+func (a *recordingAppender) Commit() error {
+	a.r.calls = append(a.r.calls, "commit")
+	return nil
+}
+
+func (a *recordingAppender) Rollback() error {
+	a.r.calls = append(a.r.calls, "rollback")
+	return nil
+}
@@ -0,0 +1,52 @@
+package cadvisor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/pkg/integrations/cadvisor"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiverUnmarshal(t *testing.T) {
+	riverCfg := `
+	store_container_labels       = false
+	allowlisted_container_labels = ["label1", "label2"]
+	enabled_metrics              = ["cpu", "memory"]
+	storage_duration             = "5m"
+	max_housekeeping_interval    = "30s"
+	allow_dynamic_housekeeping   = false
+	containerd_host              = "/run/k3s/containerd/containerd.sock"
+	docker_host                  = "unix:///run/docker.sock"
+	docker_only                  = true
+`
+	var args Arguments
+	err := river.Unmarshal([]byte(riverCfg), &args)
+	require.NoError(t, err)
+
+	expected := DefaultArguments
+	expected.StoreContainerLabels = false
+	expected.AllowlistedContainerLabels = []string{"label1", "label2"}
+	expected.EnabledMetrics = []string{"cpu", "memory"}
+	expected.StorageDuration = 5 * time.Minute
+	expected.MaxHousekeepingInterval = 30 * time.Second
+	expected.AllowDynamicHousekeeping = false
+	expected.Containerd = "/run/k3s/containerd/containerd.sock"
+	expected.Docker = "unix:///run/docker.sock"
+	expected.DockerOnly = true
+	require.Equal(t, expected, args)
+}
+
+func TestConvert(t *testing.T) {
+	var args Arguments
+	err := river.Unmarshal([]byte(`enabled_metrics = ["cpu"]`), &args)
+	require.NoError(t, err)
+
+	expected := cadvisor.DefaultConfig
+	expected.EnabledMetrics = []string{"cpu"}
+	expected.AllowlistedContainerLabels = []string{""}
+	expected.RawCgroupPrefixAllowlist = []string{""}
+	expected.EnvMetadataAllowlist = []string{""}
+	require.Equal(t, &expected, args.Convert())
+}
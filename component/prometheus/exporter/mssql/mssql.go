@@ -0,0 +1,78 @@
+package mssql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/prometheus/exporter"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/integrations"
+	"github.com/grafana/agent/pkg/integrations/mssql"
+	config_util "github.com/prometheus/common/config"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "prometheus.exporter.mssql",
+		Args:    Arguments{},
+		Exports: exporter.Exports{},
+		Build:   exporter.New(createExporter, "mssql"),
+	})
+}
+
+func createExporter(opts component.Options, args component.Arguments) (integrations.Integration, error) {
+	cfg := args.(Arguments)
+	return cfg.Convert().NewIntegration(opts.Logger)
+}
+
+// DefaultArguments holds the default settings for the mssql exporter
+var DefaultArguments = Arguments{
+	MaxIdleConnections: 3,
+	MaxOpenConnections: 3,
+	Timeout:            10 * time.Second,
+}
+
+// Arguments controls the mssql exporter.
+type Arguments struct {
+	ConnectionString   rivertypes.Secret `river:"connection_string,attr"`
+	MaxIdleConnections int               `river:"max_idle_connections,attr,optional"`
+	MaxOpenConnections int               `river:"max_open_connections,attr,optional"`
+	Timeout            time.Duration     `river:"timeout,attr,optional"`
+	QueryConfigFile    string            `river:"query_config_file,attr,optional"`
+}
+
+// UnmarshalRiver implements River unmarshalling for Arguments.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type args Arguments
+	if err := f((*args)(a)); err != nil {
+		return err
+	}
+	return a.Validate()
+}
+
+// Validate returns an error if the arguments are invalid.
+func (a *Arguments) Validate() error {
+	if a.MaxOpenConnections < 1 {
+		return fmt.Errorf("max_open_connections must be at least 1")
+	}
+	if a.MaxIdleConnections < 1 {
+		return fmt.Errorf("max_idle_connections must be at least 1")
+	}
+	if a.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+	return nil
+}
+
+func (a *Arguments) Convert() *mssql.Config {
+	return &mssql.Config{
+		ConnectionString:   config_util.Secret(a.ConnectionString),
+		MaxIdleConnections: a.MaxIdleConnections,
+		MaxOpenConnections: a.MaxOpenConnections,
+		Timeout:            a.Timeout,
+		QueryConfigFile:    a.QueryConfigFile,
+	}
+}
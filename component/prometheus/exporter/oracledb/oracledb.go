@@ -0,0 +1,77 @@
+package oracledb
+
+import (
+	"fmt"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/prometheus/exporter"
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/integrations"
+	oracledbexporter "github.com/grafana/agent/pkg/integrations/oracledb_exporter"
+	config_util "github.com/prometheus/common/config"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "prometheus.exporter.oracledb",
+		Args:    Arguments{},
+		Exports: exporter.Exports{},
+		Build:   exporter.New(createExporter, "oracledb"),
+	})
+}
+
+func createExporter(opts component.Options, args component.Arguments) (integrations.Integration, error) {
+	cfg := args.(Arguments)
+	return cfg.Convert().NewIntegration(opts.Logger)
+}
+
+// DefaultArguments holds the default settings for the oracledb exporter
+var DefaultArguments = Arguments{
+	MaxIdleConns: 0,
+	MaxOpenConns: 10,
+	QueryTimeout: 5,
+}
+
+// Arguments controls the oracledb exporter.
+type Arguments struct {
+	ConnectionString rivertypes.Secret `river:"connection_string,attr"`
+	MaxIdleConns     int               `river:"max_idle_connections,attr,optional"`
+	MaxOpenConns     int               `river:"max_open_connections,attr,optional"`
+	QueryTimeout     int               `river:"query_timeout,attr,optional"`
+	CustomMetrics    []string          `river:"custom_metrics,attr,optional"`
+}
+
+// UnmarshalRiver implements River unmarshalling for Arguments.
+func (a *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*a = DefaultArguments
+
+	type args Arguments
+	if err := f((*args)(a)); err != nil {
+		return err
+	}
+	return a.Validate()
+}
+
+// Validate returns an error if the arguments are invalid.
+func (a *Arguments) Validate() error {
+	if a.MaxOpenConns < 1 {
+		return fmt.Errorf("max_open_connections must be at least 1")
+	}
+	if a.MaxIdleConns < 0 {
+		return fmt.Errorf("max_idle_connections must not be negative")
+	}
+	if a.QueryTimeout <= 0 {
+		return fmt.Errorf("query_timeout must be positive")
+	}
+	return nil
+}
+
+func (a *Arguments) Convert() *oracledbexporter.Config {
+	return &oracledbexporter.Config{
+		ConnectionString: config_util.Secret(a.ConnectionString),
+		MaxIdleConns:     a.MaxIdleConns,
+		MaxOpenConns:     a.MaxOpenConns,
+		QueryTimeout:     a.QueryTimeout,
+		CustomMetrics:    a.CustomMetrics,
+	}
+}
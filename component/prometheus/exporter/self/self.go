@@ -0,0 +1,31 @@
+package self
+
+import (
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/prometheus/exporter"
+	"github.com/grafana/agent/pkg/integrations"
+	"github.com/grafana/agent/pkg/integrations/agent"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "prometheus.exporter.self",
+		Args:    Arguments{},
+		Exports: exporter.Exports{},
+		Build:   exporter.New(createExporter, "agent"),
+	})
+}
+
+func createExporter(opts component.Options, args component.Arguments) (integrations.Integration, error) {
+	a := args.(Arguments)
+	return a.Convert().NewIntegration(opts.Logger)
+}
+
+// Arguments holds the settings for the self exporter. The exporter currently
+// has no settings.
+type Arguments struct{}
+
+// Convert converts the component's Arguments to the integration's Config.
+func (a *Arguments) Convert() *agent.Config {
+	return &agent.Config{}
+}
@@ -97,7 +97,7 @@ type appender struct {
 	start          time.Time
 }
 
-var _ storage.Appender = (*appender)(nil)
+var _ BatchAppender = (*appender)(nil)
 
 // Append satisfies the Appender interface.
 func (a *appender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v float64) (storage.SeriesRef, error) {
@@ -123,6 +123,31 @@ func (a *appender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v flo
 	return ref, multiErr
 }
 
+// AppendBatch satisfies the BatchAppender interface. The global refs of the
+// samples are assigned with a single lookup before the batch is passed to
+// every child.
+func (a *appender) AppendBatch(samples []Sample) error {
+	if a.start.IsZero() {
+		a.start = time.Now()
+	}
+	GlobalRefMapping.GetOrAddGlobalRefIDs(samples)
+
+	var multiErr error
+	updated := false
+	for _, x := range a.children {
+		err := AppendBatch(x, samples)
+		if err != nil {
+			multiErr = multierror.Append(multiErr, err)
+		} else {
+			updated = true
+		}
+	}
+	if updated {
+		a.samplesCounter.Add(float64(len(samples)))
+	}
+	return multiErr
+}
+
 // Commit satisfies the Appender interface.
 func (a *appender) Commit() error {
 	defer a.recordLatency()
@@ -0,0 +1,375 @@
+// Package fanout provides the prometheus.fanout component.
+package fanout
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/grafana/agent/component"
+	flow_relabel "github.com/grafana/agent/component/common/relabel"
+	"github.com/grafana/agent/component/prometheus"
+	"github.com/hashicorp/go-multierror"
+	prometheus_client "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/metadata"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/storage"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "prometheus.fanout",
+		Args:    Arguments{},
+		Exports: Exports{},
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Arguments holds values which are used to configure the prometheus.fanout
+// component.
+type Arguments struct {
+	// Routes are evaluated in order against the labels of each sample.
+	Routes []Route `river:"route,block,optional"`
+
+	// Where samples which don't match any route should be forwarded to.
+	DefaultForwardTo []storage.Appendable `river:"default_forward_to,attr,optional"`
+}
+
+// Route forwards samples whose labels match a regular expression to a set of
+// receivers.
+type Route struct {
+	Name         string               `river:"name,attr,optional"`
+	SourceLabels []string             `river:"source_labels,attr"`
+	Separator    string               `river:"separator,attr,optional"`
+	Regex        flow_relabel.Regexp  `river:"regex,attr"`
+	ForwardTo    []storage.Appendable `river:"forward_to,attr"`
+	Continue     bool                 `river:"continue,attr,optional"`
+}
+
+// DefaultRoute holds default values for Route.
+var DefaultRoute = Route{
+	Separator: ";",
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (r *Route) UnmarshalRiver(f func(interface{}) error) error {
+	*r = DefaultRoute
+
+	type route Route
+	if err := f((*route)(r)); err != nil {
+		return err
+	}
+
+	if len(r.SourceLabels) == 0 {
+		return fmt.Errorf("source_labels must not be empty")
+	}
+	return nil
+}
+
+// match reports whether lbls match the route.
+func (r *Route) match(lbls labels.Labels) bool {
+	values := make([]string, 0, len(r.SourceLabels))
+	for _, name := range r.SourceLabels {
+		values = append(values, lbls.Get(name))
+	}
+	return r.Regex.MatchString(strings.Join(values, r.Separator))
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	names := make(map[string]struct{}, len(args.Routes))
+	for i, r := range args.Routes {
+		name := routeName(i, r)
+		if _, exists := names[name]; exists {
+			return fmt.Errorf("found two routes named %q", name)
+		}
+		names[name] = struct{}{}
+	}
+	return nil
+}
+
+// routeName returns the name of the route at index i, used for debug metrics.
+func routeName(i int, r Route) string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return strconv.Itoa(i)
+}
+
+// Exports holds values which are exported by the prometheus.fanout component.
+type Exports struct {
+	Receiver storage.Appendable `river:"receiver,attr"`
+}
+
+// Component implements the prometheus.fanout component.
+type Component struct {
+	opts component.Options
+
+	routedSamples    *prometheus_client.CounterVec
+	unmatchedSamples prometheus_client.Counter
+
+	mut       sync.RWMutex
+	table     *routeTable
+	defaultTo []storage.Appendable
+}
+
+// routeTable holds the routes of the component along with a cache of which
+// routes match a series. A new routeTable is created whenever the component
+// is updated, so appenders created before an update keep using a consistent
+// set of routes.
+type routeTable struct {
+	routes []Route
+	ids    []string
+
+	cacheMut sync.RWMutex
+	cache    map[uint64][]int // Indices of matching routes by global ref ID.
+}
+
+func newRouteTable(routes []Route) *routeTable {
+	t := &routeTable{
+		routes: routes,
+		ids:    make([]string, len(routes)),
+		cache:  make(map[uint64][]int),
+	}
+	for i, r := range routes {
+		t.ids[i] = routeName(i, r)
+	}
+	return t
+}
+
+var (
+	_ component.Component = (*Component)(nil)
+	_ storage.Appendable  = (*Component)(nil)
+)
+
+// New creates a new prometheus.fanout component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{opts: o}
+	c.routedSamples = prometheus_client.NewCounterVec(prometheus_client.CounterOpts{
+		Name: "agent_prometheus_fanout_routed_samples_total",
+		Help: "Total number of samples forwarded to a route",
+	}, []string{"route"})
+	c.unmatchedSamples = prometheus_client.NewCounter(prometheus_client.CounterOpts{
+		Name: "agent_prometheus_fanout_unmatched_samples_total",
+		Help: "Total number of samples which didn't match any route",
+	})
+	for _, metric := range []prometheus_client.Collector{c.routedSamples, c.unmatchedSamples} {
+		if err := o.Registerer.Register(metric); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+
+	// Immediately export the receiver which remains the same for the component
+	// lifetime.
+	o.OnStateChange(Exports{Receiver: c})
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.table = newRouteTable(newArgs.Routes)
+	c.defaultTo = newArgs.DefaultForwardTo
+	c.routedSamples.Reset()
+	return nil
+}
+
+// Appender implements storage.Appendable. The returned appender holds an
+// appender for every receiver of every route, using the routes which were
+// configured at the time Appender was called.
+func (c *Component) Appender(ctx context.Context) storage.Appender {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	app := &appender{
+		component: c,
+		table:     c.table,
+		children:  make([][]storage.Appender, len(c.table.routes)),
+	}
+	for i, r := range c.table.routes {
+		app.children[i] = childAppenders(ctx, r.ForwardTo)
+	}
+	app.defaultTo = childAppenders(ctx, c.defaultTo)
+	return app
+}
+
+func childAppenders(ctx context.Context, appendables []storage.Appendable) []storage.Appender {
+	res := make([]storage.Appender, 0, len(appendables))
+	for _, x := range appendables {
+		if x == nil {
+			continue
+		}
+		res = append(res, x.Appender(ctx))
+	}
+	return res
+}
+
+// matchingRoutes returns the indices of the routes matching lbls. The result
+// is cached by the global ref ID of lbls; the cache entry is removed when a
+// stale marker is seen for the series.
+func (t *routeTable) matchingRoutes(ref uint64, lbls labels.Labels, val float64) []int {
+	t.cacheMut.RLock()
+	matches, found := t.cache[ref]
+	t.cacheMut.RUnlock()
+
+	if !found {
+		matches = []int{}
+		for i := range t.routes {
+			if !t.routes[i].match(lbls) {
+				continue
+			}
+			matches = append(matches, i)
+			if !t.routes[i].Continue {
+				break
+			}
+		}
+
+		t.cacheMut.Lock()
+		t.cache[ref] = matches
+		t.cacheMut.Unlock()
+	}
+
+	// If stale remove from the cache. The stale marker is still routed so it
+	// can propagate to the receivers.
+	if value.IsStaleNaN(val) {
+		t.cacheMut.Lock()
+		delete(t.cache, ref)
+		t.cacheMut.Unlock()
+	}
+	return matches
+}
+
+type appender struct {
+	component *Component
+	table     *routeTable
+	children  [][]storage.Appender
+	defaultTo []storage.Appender
+}
+
+var _ storage.Appender = (*appender)(nil)
+
+// forEach calls fn for every child appender which should receive data for
+// lbls, returning the combined errors.
+func (a *appender) forEach(ref storage.SeriesRef, lbls labels.Labels, val float64, countSample bool, fn func(ref storage.SeriesRef, app storage.Appender) error) (storage.SeriesRef, error) {
+	if ref == 0 {
+		ref = storage.SeriesRef(prometheus.GlobalRefMapping.GetOrAddGlobalRefID(lbls))
+	}
+
+	var (
+		matches  = a.table.matchingRoutes(uint64(ref), lbls, val)
+		multiErr error
+	)
+
+	if len(matches) == 0 {
+		if countSample {
+			a.component.unmatchedSamples.Inc()
+		}
+		for _, x := range a.defaultTo {
+			if err := fn(ref, x); err != nil {
+				multiErr = multierror.Append(multiErr, err)
+			}
+		}
+		return ref, multiErr
+	}
+
+	for _, idx := range matches {
+		if countSample {
+			a.component.routedSamples.WithLabelValues(a.table.ids[idx]).Inc()
+		}
+		for _, x := range a.children[idx] {
+			if err := fn(ref, x); err != nil {
+				multiErr = multierror.Append(multiErr, err)
+			}
+		}
+	}
+	return ref, multiErr
+}
+
+// all returns every child appender.
+func (a *appender) all() []storage.Appender {
+	res := append([]storage.Appender{}, a.defaultTo...)
+	for _, children := range a.children {
+		res = append(res, children...)
+	}
+	return res
+}
+
+// Append satisfies the Appender interface.
+func (a *appender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v float64) (storage.SeriesRef, error) {
+	return a.forEach(ref, l, v, true, func(ref storage.SeriesRef, app storage.Appender) error {
+		_, err := app.Append(ref, l, t, v)
+		return err
+	})
+}
+
+// AppendExemplar satisfies the Appender interface.
+func (a *appender) AppendExemplar(ref storage.SeriesRef, l labels.Labels, e exemplar.Exemplar) (storage.SeriesRef, error) {
+	return a.forEach(ref, l, 0, false, func(ref storage.SeriesRef, app storage.Appender) error {
+		_, err := app.AppendExemplar(ref, l, e)
+		return err
+	})
+}
+
+// UpdateMetadata satisfies the Appender interface.
+func (a *appender) UpdateMetadata(ref storage.SeriesRef, l labels.Labels, m metadata.Metadata) (storage.SeriesRef, error) {
+	return a.forEach(ref, l, 0, false, func(ref storage.SeriesRef, app storage.Appender) error {
+		_, err := app.UpdateMetadata(ref, l, m)
+		return err
+	})
+}
+
+// AppendHistogram satisfies the Appender interface.
+func (a *appender) AppendHistogram(ref storage.SeriesRef, l labels.Labels, t int64, h *histogram.Histogram) (storage.SeriesRef, error) {
+	return a.forEach(ref, l, 0, true, func(ref storage.SeriesRef, app storage.Appender) error {
+		_, err := app.AppendHistogram(ref, l, t, h)
+		return err
+	})
+}
+
+// Commit satisfies the Appender interface.
+func (a *appender) Commit() error {
+	var multiErr error
+	for _, x := range a.all() {
+		if err := x.Commit(); err != nil {
+			multiErr = multierror.Append(multiErr, err)
+		}
+	}
+	return multiErr
+}
+
+// Rollback satisfies the Appender interface.
+func (a *appender) Rollback() error {
+	var multiErr error
+	for _, x := range a.all() {
+		if err := x.Rollback(); err != nil {
+			multiErr = multierror.Append(multiErr, err)
+		}
+	}
+	return multiErr
+}
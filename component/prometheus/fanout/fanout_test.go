@@ -0,0 +1,159 @@
+package fanout
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/grafana/agent/component"
+	"github.com/grafana/agent/component/prometheus"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouting(t *testing.T) {
+	var teamA, teamB, both, fallback collector
+
+	args := parseArguments(t, `
+		route {
+			name          = "team_a"
+			source_labels = ["tenant"]
+			regex         = "team-a"
+			forward_to    = []
+			continue      = true
+		}
+		route {
+			source_labels = ["tenant"]
+			regex         = "team-.*"
+			forward_to    = []
+		}
+		route {
+			source_labels = ["tenant"]
+			regex         = "team-b"
+			forward_to    = []
+		}
+	`)
+	args.Routes[0].ForwardTo = []storage.Appendable{teamA.appendable()}
+	args.Routes[1].ForwardTo = []storage.Appendable{both.appendable()}
+	args.Routes[2].ForwardTo = []storage.Appendable{teamB.appendable()}
+	args.DefaultForwardTo = []storage.Appendable{fallback.appendable()}
+
+	c := newComponent(t, args)
+
+	app := c.Appender(context.Background())
+	for _, tenant := range []string{"team-a", "team-b", "other"} {
+		_, err := app.Append(0, labels.FromStrings("__name__", "up", "tenant", tenant), 0, 1)
+		require.NoError(t, err)
+	}
+	require.NoError(t, app.Commit())
+
+	require.Equal(t, []string{"team-a"}, teamA.tenants())
+	require.Equal(t, []string{"team-a", "team-b"}, both.tenants())
+	require.Empty(t, teamB.tenants(), "route after a matching route without continue should not receive samples")
+	require.Equal(t, []string{"other"}, fallback.tenants())
+}
+
+func TestArguments(t *testing.T) {
+	var args Arguments
+	err := river.Unmarshal([]byte(`
+		route {
+			source_labels = ["tenant"]
+			regex         = "a"
+			forward_to    = []
+		}
+		route {
+			name          = "0"
+			source_labels = ["tenant"]
+			regex         = "b"
+			forward_to    = []
+		}
+	`), &args)
+	require.EqualError(t, err, `found two routes named "0"`)
+
+	err = river.Unmarshal([]byte(`
+		route {
+			source_labels = []
+			regex         = "a"
+			forward_to    = []
+		}
+	`), &args)
+	require.Error(t, err)
+}
+
+func TestCache(t *testing.T) {
+	var sink collector
+
+	args := parseArguments(t, `
+		route {
+			source_labels = ["tenant"]
+			regex         = "team-a"
+			forward_to    = []
+		}
+	`)
+	args.Routes[0].ForwardTo = []storage.Appendable{sink.appendable()}
+	c := newComponent(t, args)
+
+	lbls := labels.FromStrings("__name__", "up", "tenant", "team-a")
+	ref := prometheus.GlobalRefMapping.GetOrAddGlobalRefID(lbls)
+
+	app := c.Appender(context.Background())
+	_, err := app.Append(0, lbls, 0, 1)
+	require.NoError(t, err)
+	require.Contains(t, c.table.cache, ref)
+
+	_, err = app.Append(0, lbls, 0, math.Float64frombits(value.StaleNaN))
+	require.NoError(t, err)
+	require.NotContains(t, c.table.cache, ref)
+	require.Len(t, sink.tenants(), 2, "stale markers should be forwarded")
+
+	_, err = app.Append(0, lbls, 0, 1)
+	require.NoError(t, err)
+	require.NoError(t, c.Update(args))
+	require.Empty(t, c.table.cache, "cache should be reset on update")
+}
+
+func parseArguments(t *testing.T, cfg string) Arguments {
+	t.Helper()
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+	return args
+}
+
+func newComponent(t *testing.T, args Arguments) *Component {
+	t.Helper()
+	c, err := New(component.Options{
+		ID:            "prometheus.fanout.test",
+		Logger:        util.TestFlowLogger(t),
+		OnStateChange: func(e component.Exports) {},
+		Registerer:    prom.NewRegistry(),
+	}, args)
+	require.NoError(t, err)
+	return c
+}
+
+// collector records the tenant label of samples appended to it.
+type collector struct {
+	mut  sync.Mutex
+	seen []string
+}
+
+func (c *collector) tenants() []string {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.seen
+}
+
+func (c *collector) appendable() storage.Appendable {
+	return prometheus.NewInterceptor(nil, prometheus.WithAppendHook(func(ref storage.SeriesRef, l labels.Labels, _ int64, _ float64, _ storage.Appender) (storage.SeriesRef, error) {
+		c.mut.Lock()
+		defer c.mut.Unlock()
+		c.seen = append(c.seen, l.Get("tenant"))
+		return ref, nil
+	}))
+}
@@ -1,10 +1,14 @@
 package prometheus
 
 import (
+	"context"
+	"sort"
 	"sync"
 	"time"
 
+	promclient "github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
 )
 
 // GlobalRefMapping is used when translating to and from remote writes and the rest of the system (mostly scrapers)
@@ -18,6 +22,25 @@ func init() {
 // staleDuration determines how often we should wait after a stale value is received to GC that value
 var staleDuration = time.Minute * 10
 
+// evictionInterval determines how often idle series are evicted by Run.
+var evictionInterval = time.Minute
+
+// RefMapOptions configures how series are evicted from a GlobalRefMap.
+type RefMapOptions struct {
+	// IdleTimeout is how long a series may go unused before it's evicted. 0
+	// disables evicting idle series.
+	IdleTimeout time.Duration
+
+	// MaxSeries is the maximum number of series to track. When exceeded, the
+	// least recently used series are evicted. 0 means no limit.
+	MaxSeries int
+}
+
+// DefaultRefMapOptions holds the default eviction settings.
+var DefaultRefMapOptions = RefMapOptions{
+	IdleTimeout: time.Hour,
+}
+
 // GlobalRefMap allows conversion from remote_write refids to global refs ids that everything else can use
 type GlobalRefMap struct {
 	mut                sync.Mutex
@@ -25,6 +48,18 @@ type GlobalRefMap struct {
 	mappings           map[string]*remoteWriteMapping
 	labelsHashToGlobal map[uint64]uint64
 	staleGlobals       map[uint64]*staleMarker
+	activity           map[uint64]*seriesActivity
+	evicted            uint64
+
+	seriesDesc  *promclient.Desc
+	mappedDesc  *promclient.Desc
+	evictedDesc *promclient.Desc
+}
+
+// seriesActivity tracks when a global id was last used.
+type seriesActivity struct {
+	labelHash uint64
+	lastSeen  time.Time
 }
 
 type staleMarker struct {
@@ -40,6 +75,23 @@ func newGlobalRefMap() *GlobalRefMap {
 		mappings:           make(map[string]*remoteWriteMapping),
 		labelsHashToGlobal: make(map[uint64]uint64),
 		staleGlobals:       make(map[uint64]*staleMarker),
+		activity:           make(map[uint64]*seriesActivity),
+
+		seriesDesc: promclient.NewDesc(
+			"agent_prometheus_global_series_refs",
+			"Number of series tracked in the global series ref mapping.",
+			nil, nil,
+		),
+		mappedDesc: promclient.NewDesc(
+			"agent_prometheus_component_series_refs",
+			"Number of series refs mapped for a component.",
+			[]string{"component_id"}, nil,
+		),
+		evictedDesc: promclient.NewDesc(
+			"agent_prometheus_global_series_refs_evicted_total",
+			"Total number of series evicted from the global series ref mapping.",
+			nil, nil,
+		),
 	}
 }
 
@@ -64,6 +116,7 @@ func (g *GlobalRefMap) GetOrAddLink(componentID string, localRefID uint64, lbls
 	if found {
 		m.localToGlobal[localRefID] = globalID
 		m.globalToLocal[globalID] = localRefID
+		g.touch(globalID, labelHash)
 		return globalID
 	}
 	// We have a value we have never seen before so increment the globalrefid and assign
@@ -71,6 +124,7 @@ func (g *GlobalRefMap) GetOrAddLink(componentID string, localRefID uint64, lbls
 	g.labelsHashToGlobal[labelHash] = g.globalRefID
 	m.localToGlobal[localRefID] = g.globalRefID
 	m.globalToLocal[g.globalRefID] = localRefID
+	g.touch(g.globalRefID, labelHash)
 	return g.globalRefID
 }
 
@@ -87,13 +141,39 @@ func (g *GlobalRefMap) GetOrAddGlobalRefID(l labels.Labels) uint64 {
 	labelHash := l.Hash()
 	globalID, found := g.labelsHashToGlobal[labelHash]
 	if found {
+		g.touch(globalID, labelHash)
 		return globalID
 	}
 	g.globalRefID++
 	g.labelsHashToGlobal[labelHash] = g.globalRefID
+	g.touch(g.globalRefID, labelHash)
 	return g.globalRefID
 }
 
+// GetOrAddGlobalRefIDs is like GetOrAddGlobalRefID, but assigns the global
+// refids of all samples with a zero Ref in place while holding the lock once.
+func (g *GlobalRefMap) GetOrAddGlobalRefIDs(samples []Sample) {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+
+	for i := range samples {
+		s := &samples[i]
+		if s.Ref != 0 || s.Labels == nil {
+			continue
+		}
+
+		labelHash := s.Labels.Hash()
+		globalID, found := g.labelsHashToGlobal[labelHash]
+		if !found {
+			g.globalRefID++
+			globalID = g.globalRefID
+			g.labelsHashToGlobal[labelHash] = globalID
+		}
+		g.touch(globalID, labelHash)
+		s.Ref = storage.SeriesRef(globalID)
+	}
+}
+
 // GetGlobalRefID returns the global refid for a component local combo, or 0 if not found
 func (g *GlobalRefMap) GetGlobalRefID(componentID string, localRefID uint64) uint64 {
 	g.mut.Lock()
@@ -116,10 +196,72 @@ func (g *GlobalRefMap) GetLocalRefID(componentID string, globalRefID uint64) uin
 	if !found {
 		return 0
 	}
-	local := m.globalToLocal[globalRefID]
+	local, found := m.globalToLocal[globalRefID]
+	if found {
+		// Components sending samples for a global id keep it from being
+		// evicted even if they never look up the id by its labels again.
+		if a, ok := g.activity[globalRefID]; ok {
+			a.lastSeen = time.Now()
+		}
+	}
 	return local
 }
 
+// GetLocalRefIDs is like GetLocalRefID, but returns the local refids of the
+// global refids of all samples while holding the lock once.
+func (g *GlobalRefMap) GetLocalRefIDs(componentID string, samples []Sample) []uint64 {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+
+	locals := make([]uint64, len(samples))
+	m, found := g.mappings[componentID]
+	if !found {
+		return locals
+	}
+	now := time.Now()
+	for i, s := range samples {
+		local, found := m.globalToLocal[uint64(s.Ref)]
+		if !found {
+			continue
+		}
+		locals[i] = local
+		if a, ok := g.activity[uint64(s.Ref)]; ok {
+			a.lastSeen = now
+		}
+	}
+	return locals
+}
+
+// GetOrAddLinks is like GetOrAddLink, but links the local refids to the
+// labels of the corresponding samples while holding the lock once.
+func (g *GlobalRefMap) GetOrAddLinks(componentID string, localRefIDs []uint64, samples []Sample) {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+
+	m, found := g.mappings[componentID]
+	if !found {
+		m = &remoteWriteMapping{
+			RemoteWriteID: componentID,
+			localToGlobal: make(map[uint64]uint64),
+			globalToLocal: make(map[uint64]uint64),
+		}
+		g.mappings[componentID] = m
+	}
+
+	for i, s := range samples {
+		labelHash := s.Labels.Hash()
+		globalID, found := g.labelsHashToGlobal[labelHash]
+		if !found {
+			g.globalRefID++
+			globalID = g.globalRefID
+			g.labelsHashToGlobal[labelHash] = globalID
+		}
+		m.localToGlobal[localRefIDs[i]] = globalID
+		m.globalToLocal[globalID] = localRefIDs[i]
+		g.touch(globalID, labelHash)
+	}
+}
+
 // AddStaleMarker adds a stale marker
 func (g *GlobalRefMap) AddStaleMarker(globalRefID uint64, l labels.Labels) {
 	g.mut.Lock()
@@ -155,11 +297,100 @@ func (g *GlobalRefMap) CheckStaleMarkers() {
 		idsToBeGCed = append(idsToBeGCed, stale)
 	}
 	for _, marker := range idsToBeGCed {
-		delete(g.staleGlobals, marker.globalID)
-		delete(g.labelsHashToGlobal, marker.labelHash)
-		// Delete our mapping keys
-		for _, mapping := range g.mappings {
-			mapping.deleteStaleIDs(marker.globalID)
+		g.deleteLocked(marker.globalID, marker.labelHash)
+	}
+}
+
+// Run evicts series from g according to opts until ctx is canceled.
+func (g *GlobalRefMap) Run(ctx context.Context, opts RefMapOptions) {
+	ticker := time.NewTicker(evictionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.CheckStaleMarkers()
+			g.evict(time.Now(), opts)
+		}
+	}
+}
+
+// evict removes series which have been idle for longer than opts.IdleTimeout
+// at the time now, followed by the least recently used series until at most
+// opts.MaxSeries remain.
+func (g *GlobalRefMap) evict(now time.Time, opts RefMapOptions) {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+
+	if opts.IdleTimeout > 0 {
+		for globalID, a := range g.activity {
+			if now.Sub(a.lastSeen) > opts.IdleTimeout {
+				g.deleteLocked(globalID, a.labelHash)
+				g.evicted++
+			}
 		}
 	}
+
+	if opts.MaxSeries > 0 && len(g.activity) > opts.MaxSeries {
+		type entry struct {
+			globalID uint64
+			*seriesActivity
+		}
+		entries := make([]entry, 0, len(g.activity))
+		for globalID, a := range g.activity {
+			entries = append(entries, entry{globalID: globalID, seriesActivity: a})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].lastSeen.Before(entries[j].lastSeen)
+		})
+
+		for _, e := range entries[:len(entries)-opts.MaxSeries] {
+			g.deleteLocked(e.globalID, e.labelHash)
+			g.evicted++
+		}
+	}
+}
+
+// touch marks globalID as used. g.mut must be held.
+func (g *GlobalRefMap) touch(globalID, labelHash uint64) {
+	if a, ok := g.activity[globalID]; ok {
+		a.lastSeen = time.Now()
+		return
+	}
+	g.activity[globalID] = &seriesActivity{labelHash: labelHash, lastSeen: time.Now()}
+}
+
+// deleteLocked removes all references to globalID. g.mut must be held.
+func (g *GlobalRefMap) deleteLocked(globalID, labelHash uint64) {
+	delete(g.staleGlobals, globalID)
+	delete(g.activity, globalID)
+	// Only remove the labels hash if it hasn't been reassigned to a newer id.
+	if g.labelsHashToGlobal[labelHash] == globalID {
+		delete(g.labelsHashToGlobal, labelHash)
+	}
+	// Delete our mapping keys
+	for _, mapping := range g.mappings {
+		mapping.deleteStaleIDs(globalID)
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (g *GlobalRefMap) Describe(ch chan<- *promclient.Desc) {
+	ch <- g.seriesDesc
+	ch <- g.mappedDesc
+	ch <- g.evictedDesc
+}
+
+// Collect implements prometheus.Collector.
+func (g *GlobalRefMap) Collect(ch chan<- promclient.Metric) {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+
+	ch <- promclient.MustNewConstMetric(g.seriesDesc, promclient.GaugeValue, float64(len(g.labelsHashToGlobal)))
+	for componentID, m := range g.mappings {
+		ch <- promclient.MustNewConstMetric(g.mappedDesc, promclient.GaugeValue, float64(len(m.globalToLocal)), componentID)
+	}
+	ch <- promclient.MustNewConstMetric(g.evictedDesc, promclient.CounterValue, float64(g.evicted))
 }
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
 	"github.com/stretchr/testify/require"
 )
 
@@ -144,3 +145,72 @@ func TestRemovingStaleness(t *testing.T) {
 	mapping.RemoveStaleMarker(global1)
 	require.Len(t, mapping.staleGlobals, 0)
 }
+
+func TestEvictIdle(t *testing.T) {
+	mapping := newGlobalRefMap()
+	l := labels.FromStrings("__name__", "test")
+	l2 := labels.FromStrings("__name__", "test2")
+
+	global1 := mapping.GetOrAddLink("1", 1, l)
+	global2 := mapping.GetOrAddLink("1", 2, l2)
+
+	// Mark the first series as idle.
+	mapping.activity[global1].lastSeen = time.Now().Add(-2 * time.Hour)
+
+	mapping.evict(time.Now(), RefMapOptions{IdleTimeout: time.Hour})
+	require.Len(t, mapping.labelsHashToGlobal, 1)
+	require.Len(t, mapping.activity, 1)
+	require.Zero(t, mapping.GetLocalRefID("1", global1))
+	require.Equal(t, uint64(2), mapping.GetLocalRefID("1", global2))
+	require.Equal(t, uint64(1), mapping.evicted)
+
+	// An evicted series gets a new global id when it's seen again.
+	require.NotEqual(t, global1, mapping.GetOrAddGlobalRefID(l))
+}
+
+func TestEvictIdle_UsedByComponent(t *testing.T) {
+	mapping := newGlobalRefMap()
+	l := labels.FromStrings("__name__", "test")
+
+	global := mapping.GetOrAddLink("1", 1, l)
+	mapping.activity[global].lastSeen = time.Now().Add(-2 * time.Hour)
+
+	// Looking up the local id of a series keeps it alive.
+	require.Equal(t, uint64(1), mapping.GetLocalRefID("1", global))
+
+	mapping.evict(time.Now(), RefMapOptions{IdleTimeout: time.Hour})
+	require.Len(t, mapping.labelsHashToGlobal, 1)
+}
+
+func TestEvictMaxSeries(t *testing.T) {
+	mapping := newGlobalRefMap()
+
+	var globals []uint64
+	for i, name := range []string{"a", "b", "c"} {
+		global := mapping.GetOrAddGlobalRefID(labels.FromStrings("__name__", name))
+		mapping.activity[global].lastSeen = time.Now().Add(time.Duration(i) * time.Second)
+		globals = append(globals, global)
+	}
+
+	mapping.evict(time.Now(), RefMapOptions{MaxSeries: 2})
+	require.Len(t, mapping.labelsHashToGlobal, 2)
+	require.NotContains(t, mapping.activity, globals[0])
+	require.Contains(t, mapping.activity, globals[1])
+	require.Contains(t, mapping.activity, globals[2])
+}
+
+func TestBatchedRefIDs(t *testing.T) {
+	mapping := newGlobalRefMap()
+	a, b := labels.FromStrings("__name__", "a"), labels.FromStrings("__name__", "b")
+	globalA := mapping.GetOrAddGlobalRefID(a)
+
+	samples := []Sample{{Labels: a}, {Labels: b}, {Ref: 1234, Labels: b}}
+	mapping.GetOrAddGlobalRefIDs(samples)
+	require.Equal(t, storage.SeriesRef(globalA), samples[0].Ref)
+	require.Equal(t, storage.SeriesRef(mapping.GetOrAddGlobalRefID(b)), samples[1].Ref)
+	require.Equal(t, storage.SeriesRef(1234), samples[2].Ref, "non-zero refs must be kept")
+
+	mapping.GetOrAddLinks("remote_write", []uint64{1}, samples[:1])
+	require.Equal(t, []uint64{1, 0}, mapping.GetLocalRefIDs("remote_write", samples[:2]))
+	require.Equal(t, globalA, mapping.GetGlobalRefID("remote_write", 1))
+}
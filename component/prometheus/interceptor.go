@@ -3,6 +3,7 @@ package prometheus
 import (
 	"context"
 
+	"github.com/hashicorp/go-multierror"
 	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
@@ -18,6 +19,7 @@ type Interceptor struct {
 	onAppendExemplar  func(ref storage.SeriesRef, l labels.Labels, e exemplar.Exemplar, next storage.Appender) (storage.SeriesRef, error)
 	onUpdateMetadata  func(ref storage.SeriesRef, l labels.Labels, m metadata.Metadata, next storage.Appender) (storage.SeriesRef, error)
 	onAppendHistogram func(ref storage.SeriesRef, l labels.Labels, t int64, h *histogram.Histogram, next storage.Appender) (storage.SeriesRef, error)
+	onAppendBatch     func(samples []Sample, next storage.Appender) error
 
 	// next is the next appendable to pass in the chain.
 	next storage.Appendable
@@ -70,6 +72,15 @@ func WithAppendHistogram(f func(ref storage.SeriesRef, l labels.Labels, t int64,
 	}
 }
 
+// WithAppendBatchHook returns an InterceptorOption which hooks into calls to
+// AppendBatch. If unset, batches are passed to the Append hook one sample at
+// a time.
+func WithAppendBatchHook(f func(samples []Sample, next storage.Appender) error) InterceptorOption {
+	return func(i *Interceptor) {
+		i.onAppendBatch = f
+	}
+}
+
 // Appender satisfies the Appendable interface.
 func (f *Interceptor) Appender(ctx context.Context) storage.Appender {
 	app := &interceptappender{
@@ -86,7 +97,7 @@ type interceptappender struct {
 	child       storage.Appender
 }
 
-var _ storage.Appender = (*interceptappender)(nil)
+var _ BatchAppender = (*interceptappender)(nil)
 
 // Append satisfies the Appender interface.
 func (a *interceptappender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v float64) (storage.SeriesRef, error) {
@@ -100,6 +111,25 @@ func (a *interceptappender) Append(ref storage.SeriesRef, l labels.Labels, t int
 	return a.child.Append(ref, l, t, v)
 }
 
+// AppendBatch satisfies the BatchAppender interface.
+func (a *interceptappender) AppendBatch(samples []Sample) error {
+	GlobalRefMapping.GetOrAddGlobalRefIDs(samples)
+
+	if a.interceptor.onAppendBatch != nil {
+		return a.interceptor.onAppendBatch(samples, a.child)
+	}
+	if a.interceptor.onAppend != nil {
+		var multiErr error
+		for _, s := range samples {
+			if _, err := a.interceptor.onAppend(s.Ref, s.Labels, s.T, s.V, a.child); err != nil {
+				multiErr = multierror.Append(multiErr, err)
+			}
+		}
+		return multiErr
+	}
+	return AppendBatch(a.child, samples)
+}
+
 // Commit satisfies the Appender interface.
 func (a *interceptappender) Commit() error {
 	if a.child == nil {
@@ -38,10 +38,22 @@ type Arguments struct {
 	// Where the relabelled metrics should be forwarded to.
 	ForwardTo []storage.Appendable `river:"forward_to,attr"`
 
+	// Relabelling rules exported by another component, applied before the
+	// rules defined in MetricRelabelConfigs.
+	Rules flow_relabel.Rules `river:"rules,attr,optional"`
+
 	// The relabelling rules to apply to each metric before it's forwarded.
 	MetricRelabelConfigs []*flow_relabel.Config `river:"rule,block,optional"`
 }
 
+// allRules returns the imported rules followed by the rules defined in rule
+// blocks.
+func (args Arguments) allRules() flow_relabel.Rules {
+	res := make(flow_relabel.Rules, 0, len(args.Rules)+len(args.MetricRelabelConfigs))
+	res = append(res, args.Rules...)
+	return append(res, args.MetricRelabelConfigs...)
+}
+
 // Exports holds values which are exported by the prometheus.relabel component.
 type Exports struct {
 	Receiver storage.Appendable `river:"receiver,attr"`
@@ -120,6 +132,18 @@ func New(o component.Options, args Arguments) (*Component, error) {
 			c.metricsOutgoing.Inc()
 			return next.Append(0, newLbl, t, v)
 		}),
+		prometheus.WithAppendBatchHook(func(samples []prometheus.Sample, next storage.Appender) error {
+			if c.exited.Load() {
+				return fmt.Errorf("%s has exited", o.ID)
+			}
+
+			relabelled := c.relabelBatch(samples)
+			if len(relabelled) == 0 {
+				return nil
+			}
+			c.metricsOutgoing.Add(float64(len(relabelled)))
+			return prometheus.AppendBatch(next, relabelled)
+		}),
 		prometheus.WithExemplarHook(func(_ storage.SeriesRef, l labels.Labels, e exemplar.Exemplar, next storage.Appender) (storage.SeriesRef, error) {
 			if c.exited.Load() {
 				return 0, fmt.Errorf("%s has exited", o.ID)
@@ -146,7 +170,7 @@ func New(o component.Options, args Arguments) (*Component, error) {
 
 	// Immediately export the receiver which remains the same for the component
 	// lifetime.
-	o.OnStateChange(Exports{Receiver: c.receiver, Rules: args.MetricRelabelConfigs})
+	o.OnStateChange(Exports{Receiver: c.receiver, Rules: args.allRules()})
 
 	// Call to Update() to set the relabelling rules once at the start.
 	if err = c.Update(args); err != nil {
@@ -170,11 +194,12 @@ func (c *Component) Update(args component.Arguments) error {
 	defer c.mut.Unlock()
 
 	newArgs := args.(Arguments)
+	rules := newArgs.allRules()
 	c.clearCache()
-	c.mrc = flow_relabel.ComponentToPromRelabelConfigs(newArgs.MetricRelabelConfigs)
+	c.mrc = flow_relabel.ComponentToPromRelabelConfigs(rules)
 	c.fanout.UpdateChildren(newArgs.ForwardTo)
 
-	c.opts.OnStateChange(Exports{Receiver: c.receiver, Rules: newArgs.MetricRelabelConfigs})
+	c.opts.OnStateChange(Exports{Receiver: c.receiver, Rules: rules})
 
 	return nil
 }
@@ -214,6 +239,90 @@ func (c *Component) relabel(val float64, lbls labels.Labels) labels.Labels {
 	return relabelled
 }
 
+// relabelBatch is like relabel, but relabels a batch of samples while
+// acquiring each lock once. Dropped samples are left out of the returned
+// batch, and the returned samples carry the global refs of their relabelled
+// labels.
+func (c *Component) relabelBatch(samples []prometheus.Sample) []prometheus.Sample {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	// Look up the global refs of the original labels, like relabel does,
+	// rather than trusting the refs of the incoming samples.
+	originals := make([]prometheus.Sample, len(samples))
+	for i, s := range samples {
+		originals[i] = prometheus.Sample{Labels: s.Labels}
+	}
+	prometheus.GlobalRefMapping.GetOrAddGlobalRefIDs(originals)
+
+	entries := make([]*labelAndID, len(samples))
+	var misses []int
+	c.cacheMut.RLock()
+	for i, o := range originals {
+		entry, found := c.cache[uint64(o.Ref)]
+		if !found {
+			misses = append(misses, i)
+			continue
+		}
+		entries[i] = entry
+	}
+	c.cacheMut.RUnlock()
+	c.cacheHits.Add(float64(len(samples) - len(misses)))
+
+	if len(misses) > 0 {
+		// Relabel against copies of the labels to prevent modifying the
+		// original slices.
+		var kept []prometheus.Sample
+		var keptIdx []int
+		for _, i := range misses {
+			relabelled := relabel.Process(samples[i].Labels.Copy(), c.mrc...)
+			if relabelled != nil {
+				kept = append(kept, prometheus.Sample{Labels: relabelled})
+				keptIdx = append(keptIdx, i)
+			}
+		}
+		prometheus.GlobalRefMapping.GetOrAddGlobalRefIDs(kept)
+		for j, i := range keptIdx {
+			entries[i] = &labelAndID{labels: kept[j].Labels, id: uint64(kept[j].Ref)}
+		}
+
+		c.cacheMut.Lock()
+		for _, i := range misses {
+			c.cache[uint64(originals[i].Ref)] = entries[i]
+		}
+		c.cacheMut.Unlock()
+		c.cacheMisses.Add(float64(len(misses)))
+		c.cacheSize.Add(float64(len(misses)))
+	}
+
+	out := make([]prometheus.Sample, 0, len(samples))
+	var stale []uint64
+	for i, s := range samples {
+		// If stale remove from the cache, the stale value still propagates.
+		if value.IsStaleNaN(s.V) {
+			stale = append(stale, uint64(originals[i].Ref))
+		}
+		if entries[i] == nil {
+			continue
+		}
+		out = append(out, prometheus.Sample{
+			Ref:    storage.SeriesRef(entries[i].id),
+			Labels: entries[i].labels,
+			T:      s.T,
+			V:      s.V,
+		})
+	}
+	if len(stale) > 0 {
+		c.cacheMut.Lock()
+		for _, id := range stale {
+			delete(c.cache, id)
+		}
+		c.cacheMut.Unlock()
+		c.cacheSize.Sub(float64(len(stale)))
+	}
+	return out
+}
+
 func (c *Component) getFromCache(id uint64) (*labelAndID, bool) {
 	c.cacheMut.RLock()
 	defer c.cacheMut.RUnlock()
@@ -2,6 +2,7 @@ package relabel
 
 import (
 	"math"
+	"strconv"
 	"testing"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/grafana/agent/pkg/river"
 	"github.com/grafana/agent/pkg/util"
 	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/relabel"
 	"github.com/prometheus/prometheus/model/value"
@@ -82,6 +84,36 @@ func TestNil(t *testing.T) {
 	relabeller.relabel(0, lbls)
 }
 
+func TestRelabelBatch(t *testing.T) {
+	relabeller := generateRelabel(t)
+	kept := labels.FromStrings("__address__", "localhost")
+	dropped := labels.FromStrings("job", "no_address")
+	relabeller.mrc = append(relabeller.mrc, &relabel.Config{
+		SourceLabels: []model.LabelName{"__address__"},
+		Regex:        relabel.MustNewRegexp(""),
+		Action:       relabel.Drop,
+	})
+
+	// The batch is relabeled like single samples are, and dropped samples are
+	// left out.
+	out := relabeller.relabelBatch([]prometheus.Sample{
+		{Labels: kept, T: 1, V: 1},
+		{Labels: dropped, T: 1, V: 2},
+	})
+	require.Len(t, out, 1)
+	require.Equal(t, relabeller.relabel(1, kept), out[0].Labels)
+	require.Equal(t, storage.SeriesRef(prometheus.GlobalRefMapping.GetOrAddGlobalRefID(out[0].Labels)), out[0].Ref)
+	require.Equal(t, 1.0, out[0].V)
+	require.Len(t, relabeller.cache, 2)
+
+	// Stale samples evict their series from the cache.
+	out = relabeller.relabelBatch([]prometheus.Sample{
+		{Labels: kept, T: 2, V: math.Float64frombits(value.StaleNaN)},
+	})
+	require.Len(t, out, 1)
+	require.Len(t, relabeller.cache, 1)
+}
+
 func BenchmarkCache(b *testing.B) {
 	fanout := prometheus.NewInterceptor(nil, prometheus.WithAppendHook(func(ref storage.SeriesRef, l labels.Labels, _ int64, _ float64, _ storage.Appender) (storage.SeriesRef, error) {
 		require.True(b, l.Has("new_label"))
@@ -118,6 +150,50 @@ func BenchmarkCache(b *testing.B) {
 	app.Commit()
 }
 
+func BenchmarkCacheBatch(b *testing.B) {
+	fanout := prometheus.NewInterceptor(nil, prometheus.WithAppendBatchHook(func(samples []prometheus.Sample, _ storage.Appender) error {
+		for _, s := range samples {
+			require.True(b, s.Labels.Has("new_label"))
+		}
+		return nil
+	}))
+	var entry storage.Appendable
+	_, _ = New(component.Options{
+		ID:     "1",
+		Logger: util.TestFlowLogger(b),
+		OnStateChange: func(e component.Exports) {
+			newE := e.(Exports)
+			entry = newE.Receiver
+		},
+		Registerer: prom.NewRegistry(),
+	}, Arguments{
+		ForwardTo: []storage.Appendable{fanout},
+		MetricRelabelConfigs: []*flow_relabel.Config{
+			{
+				SourceLabels: []string{"__address__"},
+				Regex:        flow_relabel.Regexp(relabel.MustNewRegexp("(.+)")),
+				TargetLabel:  "new_label",
+				Replacement:  "new_value",
+				Action:       "replace",
+			},
+		},
+	})
+
+	batch := make([]prometheus.Sample, 100)
+	for i := range batch {
+		batch[i].Labels = labels.FromStrings("__address__", "localhost", "series", strconv.Itoa(i))
+	}
+	app := entry.Appender(context.Background())
+
+	for i := 0; i < b.N; i += len(batch) {
+		for j := range batch {
+			batch[j].T = time.Now().UnixMilli()
+		}
+		_ = prometheus.AppendBatch(app, batch)
+	}
+	app.Commit()
+}
+
 func generateRelabel(t *testing.T) *Component {
 	fanout := prometheus.NewInterceptor(nil, prometheus.WithAppendHook(func(ref storage.SeriesRef, l labels.Labels, _ int64, _ float64, _ storage.Appender) (storage.SeriesRef, error) {
 		require.True(t, l.Has("new_label"))
@@ -191,3 +267,38 @@ func TestRuleGetter(t *testing.T) {
 	require.Equal(t, gotUpdated[0].SourceLabels, gotOriginal[0].SourceLabels)
 	require.Equal(t, gotUpdated[0].Regex, gotOriginal[0].Regex)
 }
+
+func TestImportedRules(t *testing.T) {
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(`
+		forward_to = []
+		rule {
+			source_labels = ["new_label"]
+			target_label  = "copied"
+			action        = "replace"
+		}`), &args))
+	args.Rules = flow_relabel.Rules{{
+		SourceLabels: []string{"__address__"},
+		Separator:    ";",
+		Regex:        flow_relabel.Regexp(relabel.MustNewRegexp("(.+)")),
+		TargetLabel:  "new_label",
+		Replacement:  "new_value",
+		Action:       "replace",
+	}}
+
+	var exports Exports
+	relabeller, err := New(component.Options{
+		ID:            "1",
+		Logger:        util.TestFlowLogger(t),
+		OnStateChange: func(e component.Exports) { exports = e.(Exports) },
+		Registerer:    prom.NewRegistry(),
+	}, args)
+	require.NoError(t, err)
+
+	// Imported rules run before the component's own rules.
+	res := relabeller.relabel(0, labels.FromStrings("__address__", "localhost"))
+	require.Equal(t, labels.FromStrings("__address__", "localhost", "new_label", "new_value", "copied", "new_value"), res)
+
+	require.Len(t, exports.Rules, 2)
+	require.Equal(t, args.Rules[0], exports.Rules[0])
+}
@@ -3,6 +3,7 @@ package remotewrite
 import (
 	"context"
 	"fmt"
+	"io/fs"
 	"math"
 	"path/filepath"
 	"sync"
@@ -21,6 +22,8 @@ import (
 	"github.com/grafana/agent/component"
 	"github.com/grafana/agent/pkg/build"
 	"github.com/grafana/agent/pkg/metrics/wal"
+	"github.com/hashicorp/go-multierror"
+	promclient "github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/prometheus/model/timestamp"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/storage/remote"
@@ -58,12 +61,23 @@ type Component struct {
 	cfg Arguments
 
 	receiver *prometheus.Interceptor
+
+	// walDir is the directory the WAL was opened in. It can't be changed
+	// without restarting the component.
+	walDir string
+	// walDiskUsage caches the size of walDir in bytes, which is too costly to
+	// compute on every scrape.
+	walDiskUsage atomic.Int64
 }
 
+// walDiskUsageInterval is how often the cached disk usage of the WAL is
+// refreshed between truncations.
+const walDiskUsageInterval = time.Minute
+
 // NewComponent creates a new prometheus.remote_write component.
 func NewComponent(o component.Options, c Arguments) (*Component, error) {
 	walLogger := log.With(o.Logger, "subcomponent", "wal")
-	dataPath := filepath.Join(o.DataPath, "wal", o.ID)
+	dataPath := walDirectory(o, c.WALOptions)
 	walStorage, err := wal.NewStorage(walLogger, o.Registerer, dataPath)
 	if err != nil {
 		return nil, err
@@ -78,7 +92,20 @@ func NewComponent(o component.Options, c Arguments) (*Component, error) {
 		walStore:    walStorage,
 		remoteStore: remoteStore,
 		storage:     storage.NewFanout(o.Logger, walStorage, remoteStore),
+		walDir:      dataPath,
 	}
+	res.updateWALDiskUsage()
+
+	walDiskUsage := promclient.NewGaugeFunc(promclient.GaugeOpts{
+		Name: "agent_wal_disk_usage_bytes",
+		Help: "Current disk usage of the WAL in bytes, including remote_write checkpoints.",
+	}, func() float64 {
+		return float64(res.walDiskUsage.Load())
+	})
+	if err := o.Registerer.Register(walDiskUsage); err != nil {
+		return nil, err
+	}
+
 	res.receiver = prometheus.NewInterceptor(
 		res.storage,
 
@@ -100,6 +127,31 @@ func NewComponent(o component.Options, c Arguments) (*Component, error) {
 			}
 			return globalRef, nextErr
 		}),
+		prometheus.WithAppendBatchHook(func(samples []prometheus.Sample, next storage.Appender) error {
+			if res.exited.Load() {
+				return fmt.Errorf("%s has exited", o.ID)
+			}
+
+			localIDs := prometheus.GlobalRefMapping.GetLocalRefIDs(res.opts.ID, samples)
+			var (
+				multiErr   error
+				newLocals  []uint64
+				newSamples []prometheus.Sample
+			)
+			for i, s := range samples {
+				newRef, err := next.Append(storage.SeriesRef(localIDs[i]), s.Labels, s.T, s.V)
+				if err != nil {
+					multiErr = multierror.Append(multiErr, err)
+				} else if localIDs[i] == 0 {
+					newLocals = append(newLocals, uint64(newRef))
+					newSamples = append(newSamples, s)
+				}
+			}
+			if len(newSamples) > 0 {
+				prometheus.GlobalRefMapping.GetOrAddLinks(res.opts.ID, newLocals, newSamples)
+			}
+			return multiErr
+		}),
 		prometheus.WithMetadataHook(func(globalRef storage.SeriesRef, l labels.Labels, m metadata.Metadata, next storage.Appender) (storage.SeriesRef, error) {
 			if res.exited.Load() {
 				return 0, fmt.Errorf("%s has exited", o.ID)
@@ -138,7 +190,36 @@ func NewComponent(o component.Options, c Arguments) (*Component, error) {
 
 func startTime() (int64, error) { return 0, nil }
 
-var _ component.Component = (*Component)(nil)
+// walDirectory returns the directory to store the WAL for the component in.
+// Each component is given its own directory so WALs never overlap, even when
+// several components share the same base directory.
+func walDirectory(o component.Options, opts WALOptions) string {
+	if opts.Directory != "" {
+		return filepath.Join(opts.Directory, o.ID)
+	}
+	return filepath.Join(o.DataPath, "wal", o.ID)
+}
+
+// dirSize returns the total size in bytes of files within dir. Files which
+// disappear while walking, such as WAL segments being truncated, are ignored.
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if fi, err := d.Info(); err == nil {
+			size += fi.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+var (
+	_ component.Component        = (*Component)(nil)
+	_ component.StorageComponent = (*Component)(nil)
+)
 
 // Run implements Component.
 func (c *Component) Run(ctx context.Context) error {
@@ -157,11 +238,21 @@ func (c *Component) Run(ctx context.Context) error {
 	// deleted until at least some new data has been sent.
 	var lastTs = int64(math.MinInt64)
 
+	// The truncation timer is only reset once it fires, so that refreshing the
+	// disk usage doesn't delay truncating.
+	truncateCh := time.After(c.truncateFrequency())
+	diskUsageTicker := time.NewTicker(walDiskUsageInterval)
+	defer diskUsageTicker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
-		case <-time.After(c.truncateFrequency()):
+		case <-diskUsageTicker.C:
+			c.updateWALDiskUsage()
+		case <-truncateCh:
+			truncateCh = time.After(c.truncateFrequency())
+
 			// We retrieve the current min/max keepalive time at once, since
 			// retrieving them separately could lead to issues where we have an older
 			// value for min which is now larger than max.
@@ -205,10 +296,16 @@ func (c *Component) Run(ctx context.Context) error {
 				// so we'll only log this as a warning.
 				level.Warn(c.log).Log("msg", "could not truncate WAL", "err", err)
 			}
+			c.updateWALDiskUsage()
 		}
 	}
 }
 
+// updateWALDiskUsage refreshes the cached disk usage of the WAL.
+func (c *Component) updateWALDiskUsage() {
+	c.walDiskUsage.Store(dirSize(c.walDir))
+}
+
 func (c *Component) truncateFrequency() time.Duration {
 	c.mut.RLock()
 	defer c.mut.RUnlock()
@@ -222,6 +319,10 @@ func (c *Component) Update(newConfig component.Arguments) error {
 	c.mut.Lock()
 	defer c.mut.Unlock()
 
+	if dir := walDirectory(c.opts, cfg.WALOptions); dir != c.walDir {
+		return fmt.Errorf("the WAL directory cannot be changed from %q to %q without restarting the agent", c.walDir, dir)
+	}
+
 	convertedConfig, err := convertConfigs(cfg)
 	if err != nil {
 		return err
@@ -234,3 +335,9 @@ func (c *Component) Update(newConfig component.Arguments) error {
 	c.cfg = cfg
 	return nil
 }
+
+// StoragePaths implements component.StorageComponent.
+func (c *Component) StoragePaths() []component.StoragePath {
+	// walDir never changes, so it can be read without holding c.mut.
+	return []component.StoragePath{{Path: c.walDir, Buffer: true}}
+}
@@ -5,13 +5,16 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/grafana/agent/component"
 	"github.com/grafana/agent/component/prometheus/remotewrite"
 	"github.com/grafana/agent/pkg/flow/componenttest"
 	"github.com/grafana/agent/pkg/river"
 	"github.com/grafana/agent/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/prompb"
 	"github.com/prometheus/prometheus/storage/remote"
@@ -114,3 +117,63 @@ func Test(t *testing.T) {
 		require.Equal(t, expect, res.Timeseries)
 	}
 }
+
+func TestWALDirectory(t *testing.T) {
+	walDir := t.TempDir()
+
+	var args remotewrite.Arguments
+	require.NoError(t, river.Unmarshal([]byte(fmt.Sprintf(`
+		wal {
+			directory          = %q
+			truncate_frequency = "10ms"
+		}
+	`, walDir)), &args))
+
+	var exports remotewrite.Exports
+	reg := prometheus.NewRegistry()
+	opts := component.Options{
+		ID:            "prometheus.remote_write.test",
+		Logger:        util.TestFlowLogger(t),
+		DataPath:      t.TempDir(),
+		Registerer:    reg,
+		OnStateChange: func(e component.Exports) { exports = e.(remotewrite.Exports) },
+	}
+	c, err := remotewrite.NewComponent(opts, args)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = c.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// The WAL is isolated in a directory for the component inside the
+	// configured directory.
+	require.DirExists(t, filepath.Join(walDir, opts.ID, "wal"))
+
+	app := exports.Receiver.Appender(ctx)
+	_, err = app.Append(0, labels.FromStrings("foo", "bar"), time.Now().UnixMilli(), 1)
+	require.NoError(t, err)
+	require.NoError(t, app.Commit())
+
+	// The disk usage is cached, and refreshed once the WAL is truncated.
+	require.Eventually(t, func() bool {
+		mfs, err := reg.Gather()
+		require.NoError(t, err)
+		for _, mf := range mfs {
+			if mf.GetName() == "agent_wal_disk_usage_bytes" {
+				return mf.GetMetric()[0].GetGauge().GetValue() > 0
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond, "expected WAL disk usage to be reported")
+
+	// The WAL directory can't be moved while the component is running.
+	args.WALOptions.Directory = t.TempDir()
+	require.ErrorContains(t, c.Update(args), "the WAL directory cannot be changed")
+}
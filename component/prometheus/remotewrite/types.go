@@ -174,6 +174,10 @@ func (o *MetadataOptions) toPrometheusType() config.MetadataConfig {
 
 // WALOptions configures behavior within the WAL.
 type WALOptions struct {
+	// Directory is the base directory to store the WAL in. If empty, the WAL
+	// is stored inside the agent's data path.
+	Directory string `river:"directory,attr,optional"`
+
 	TruncateFrequency time.Duration `river:"truncate_frequency,attr,optional"`
 	MinKeepaliveTime  time.Duration `river:"min_keepalive_time,attr,optional"`
 	MaxKeepaliveTime  time.Duration `river:"max_keepalive_time,attr,optional"`
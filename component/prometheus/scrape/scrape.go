@@ -13,6 +13,7 @@ import (
 	"github.com/go-kit/log/level"
 	"github.com/grafana/agent/component"
 	component_config "github.com/grafana/agent/component/common/config"
+	flow_relabel "github.com/grafana/agent/component/common/relabel"
 	"github.com/grafana/agent/component/discovery"
 	"github.com/grafana/agent/component/prometheus"
 	"github.com/grafana/agent/pkg/build"
@@ -77,6 +78,13 @@ type Arguments struct {
 	// scrape to fail.
 	LabelValueLengthLimit uint `river:"label_value_length_limit,attr,optional"`
 
+	// Relabelling rules exported by another component, applied to targets
+	// before scraping.
+	RelabelRules flow_relabel.Rules `river:"relabel_rules,attr,optional"`
+	// Relabelling rules exported by another component, applied to scraped
+	// samples before they're forwarded.
+	MetricRelabelRules flow_relabel.Rules `river:"metric_relabel_rules,attr,optional"`
+
 	HTTPClientConfig component_config.HTTPClientConfig `river:",squash"`
 
 	// Scrape Options
@@ -122,14 +130,17 @@ type Component struct {
 }
 
 var (
-	_ component.Component = (*Component)(nil)
+	_ component.Component       = (*Component)(nil)
+	_ component.ScrapeComponent = (*Component)(nil)
 )
 
 // New creates a new prometheus.scrape component.
 func New(o component.Options, args Arguments) (*Component, error) {
 	flowAppendable := prometheus.NewFanout(args.ForwardTo, o.ID, o.Registerer)
 	scrapeOptions := &scrape.Options{ExtraMetrics: args.ExtraMetrics}
-	scraper := scrape.NewManager(scrapeOptions, o.Logger, flowAppendable)
+	// The samples of every scrape are passed to the downstream components in
+	// a single batch when the scrape is committed.
+	scraper := scrape.NewManager(scrapeOptions, o.Logger, prometheus.NewBatcher(flowAppendable))
 
 	targetsGauge := client_prometheus.NewGauge(client_prometheus.GaugeOpts{
 		Name: "agent_prometheus_scrape_targets_gauge",
@@ -224,11 +235,10 @@ func (c *Component) Update(args component.Arguments) error {
 
 // Helper function to bridge the in-house configuration with the Prometheus
 // scrape_config.
-// As explained in the Config struct, the following fields are purposefully
-// missing out, as they're being implemented by another components.
-// - RelabelConfigs
-// - MetricsRelabelConfigs
-// - ServiceDiscoveryConfigs
+// As explained in the Config struct, service discovery is purposefully
+// missing out, as it's implemented by other components. Relabeling rules are
+// only set when they're imported from another component through the
+// relabel_rules and metric_relabel_rules arguments.
 func getPromScrapeConfigs(jobName string, c Arguments) *config.ScrapeConfig {
 	dec := config.DefaultScrapeConfig
 	if c.JobName != "" {
@@ -249,6 +259,8 @@ func getPromScrapeConfigs(jobName string, c Arguments) *config.ScrapeConfig {
 	dec.LabelLimit = c.LabelLimit
 	dec.LabelNameLengthLimit = c.LabelNameLengthLimit
 	dec.LabelValueLengthLimit = c.LabelValueLengthLimit
+	dec.RelabelConfigs = flow_relabel.ComponentToPromRelabelConfigs(c.RelabelRules)
+	dec.MetricRelabelConfigs = flow_relabel.ComponentToPromRelabelConfigs(c.MetricRelabelRules)
 
 	// HTTP scrape client settings
 	dec.HTTPClientConfig = *c.HTTPClientConfig.Convert()
@@ -271,9 +283,9 @@ type TargetStatus struct {
 	LastScrapeDuration time.Duration     `river:"last_scrape_duration,attr,optional"`
 }
 
-// DebugInfo implements component.DebugComponent
-func (c *Component) DebugInfo() interface{} {
-	var res []TargetStatus
+// ScrapeTargets implements component.ScrapeComponent.
+func (c *Component) ScrapeTargets() []component.ScrapeTarget {
+	var res []component.ScrapeTarget
 
 	for job, stt := range c.scraper.TargetsActive() {
 		for _, st := range stt {
@@ -282,7 +294,7 @@ func (c *Component) DebugInfo() interface{} {
 				lastError = st.LastError().Error()
 			}
 			if st != nil {
-				res = append(res, TargetStatus{
+				res = append(res, component.ScrapeTarget{
 					JobName:            job,
 					URL:                st.URL().String(),
 					Health:             string(st.Health()),
@@ -295,6 +307,15 @@ func (c *Component) DebugInfo() interface{} {
 		}
 	}
 
+	return res
+}
+
+// DebugInfo implements component.DebugComponent
+func (c *Component) DebugInfo() interface{} {
+	var res []TargetStatus
+	for _, t := range c.ScrapeTargets() {
+		res = append(res, TargetStatus(t))
+	}
 	return ScraperStatus{TargetStatus: res}
 }
 
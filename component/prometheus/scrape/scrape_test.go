@@ -6,11 +6,14 @@ import (
 	"time"
 
 	"github.com/grafana/agent/component"
+	flow_relabel "github.com/grafana/agent/component/common/relabel"
 	"github.com/grafana/agent/component/prometheus"
 	"github.com/grafana/agent/pkg/river"
 	"github.com/grafana/agent/pkg/util"
 	prometheus_client "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/stretchr/testify/require"
 )
@@ -62,6 +65,31 @@ func TestBadRiverConfig(t *testing.T) {
 	require.ErrorContains(t, err, "at most one of bearer_token & bearer_token_file must be configured")
 }
 
+func TestImportedRules(t *testing.T) {
+	var args Arguments
+	require.NoError(t, river.Unmarshal([]byte(`
+		targets    = []
+		forward_to = []
+	`), &args))
+	args.RelabelRules = flow_relabel.Rules{{
+		SourceLabels: []string{"__address__"},
+		Regex:        flow_relabel.Regexp(relabel.MustNewRegexp("localhost.*")),
+		Action:       flow_relabel.Keep,
+	}}
+	args.MetricRelabelRules = flow_relabel.Rules{{
+		SourceLabels: []string{"__name__"},
+		Regex:        flow_relabel.Regexp(relabel.MustNewRegexp("go_.*")),
+		Action:       flow_relabel.Drop,
+	}}
+
+	sc := getPromScrapeConfigs("prometheus.scrape.test", args)
+	require.Len(t, sc.RelabelConfigs, 1)
+	require.Equal(t, relabel.Keep, sc.RelabelConfigs[0].Action)
+	require.Equal(t, model.LabelNames{model.AddressLabel}, sc.RelabelConfigs[0].SourceLabels)
+	require.Len(t, sc.MetricRelabelConfigs, 1)
+	require.Equal(t, relabel.Drop, sc.MetricRelabelConfigs[0].Action)
+}
+
 func TestForwardingToAppendable(t *testing.T) {
 	opts := component.Options{
 		Logger:     util.TestFlowLogger(t),
@@ -0,0 +1,99 @@
+package queue
+
+import (
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/metadata"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/storage/remote"
+)
+
+// appender collects appended data in memory until it's committed to the
+// component's batch.
+type appender struct {
+	c              *Component
+	externalLabels labels.Labels
+
+	series []prompb.TimeSeries
+}
+
+var _ storage.Appender = (*appender)(nil)
+
+// Append implements storage.Appender.
+func (a *appender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v float64) (storage.SeriesRef, error) {
+	a.series = append(a.series, prompb.TimeSeries{
+		Labels:  a.labelsProto(l),
+		Samples: []prompb.Sample{{Timestamp: t, Value: v}},
+	})
+	return ref, nil
+}
+
+// AppendExemplar implements storage.Appender.
+func (a *appender) AppendExemplar(ref storage.SeriesRef, l labels.Labels, e exemplar.Exemplar) (storage.SeriesRef, error) {
+	a.series = append(a.series, prompb.TimeSeries{
+		Labels: a.labelsProto(l),
+		Exemplars: []prompb.Exemplar{{
+			Labels:    labelsProto(e.Labels),
+			Value:     e.Value,
+			Timestamp: e.Ts,
+		}},
+	})
+	return ref, nil
+}
+
+// AppendHistogram implements storage.Appender.
+func (a *appender) AppendHistogram(ref storage.SeriesRef, l labels.Labels, t int64, h *histogram.Histogram) (storage.SeriesRef, error) {
+	a.series = append(a.series, prompb.TimeSeries{
+		Labels:     a.labelsProto(l),
+		Histograms: []prompb.Histogram{remote.HistogramToHistogramProto(t, h)},
+	})
+	return ref, nil
+}
+
+// UpdateMetadata implements storage.Appender. Metadata isn't queued.
+func (a *appender) UpdateMetadata(ref storage.SeriesRef, _ labels.Labels, _ metadata.Metadata) (storage.SeriesRef, error) {
+	return ref, nil
+}
+
+// Commit implements storage.Appender, adding all collected data to the
+// component's batch.
+func (a *appender) Commit() error {
+	if len(a.series) == 0 {
+		return nil
+	}
+	a.c.enqueue(a.series)
+	a.series = nil
+	return nil
+}
+
+// Rollback implements storage.Appender, discarding all collected data.
+func (a *appender) Rollback() error {
+	a.series = nil
+	return nil
+}
+
+// labelsProto converts l into its protobuf representation, adding external
+// labels which aren't already set.
+func (a *appender) labelsProto(l labels.Labels) []prompb.Label {
+	if len(a.externalLabels) == 0 {
+		return labelsProto(l)
+	}
+
+	lb := labels.NewBuilder(l)
+	for _, el := range a.externalLabels {
+		if l.Get(el.Name) == "" {
+			lb.Set(el.Name, el.Value)
+		}
+	}
+	return labelsProto(lb.Labels(nil))
+}
+
+func labelsProto(l labels.Labels) []prompb.Label {
+	res := make([]prompb.Label, 0, len(l))
+	for _, lbl := range l {
+		res = append(res, prompb.Label{Name: lbl.Name, Value: lbl.Value})
+	}
+	return res
+}
@@ -0,0 +1,202 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const segmentSuffix = ".batch"
+
+// segment is a single batch stored in a diskQueue.
+type segment struct {
+	seq  uint64
+	size int64
+}
+
+// diskQueue is a FIFO queue of batches stored as individual files in a
+// directory. Batches remain on disk until they're removed from the queue, so
+// queued data survives restarts.
+//
+// When the total size of the queue exceeds its maximum size, the oldest
+// batches which aren't currently being processed are dropped.
+type diskQueue struct {
+	dir     string
+	maxSize int64
+
+	// notify is signaled whenever a batch becomes available.
+	notify chan struct{}
+
+	mut     sync.Mutex
+	nextSeq uint64
+	pending []segment // Batches which can be returned by Next, ordered by seq.
+	size    int64     // Total size of all batches on disk, including in-flight batches.
+}
+
+// openDiskQueue opens the queue stored in dir, creating it if it doesn't
+// exist. Batches left over from a previous run are queued again.
+func openDiskQueue(dir string, maxSize int64) (*diskQueue, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create queue directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue directory: %w", err)
+	}
+
+	q := &diskQueue{
+		dir:     dir,
+		maxSize: maxSize,
+		notify:  make(chan struct{}, 1),
+	}
+
+	for _, ent := range entries {
+		name := ent.Name()
+		if ent.IsDir() || !strings.HasSuffix(name, segmentSuffix) {
+			// Remove leftover temporary files from interrupted writes.
+			if !ent.IsDir() && strings.HasSuffix(name, ".tmp") {
+				_ = os.Remove(filepath.Join(dir, name))
+			}
+			continue
+		}
+
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		fi, err := ent.Info()
+		if err != nil {
+			continue
+		}
+
+		q.pending = append(q.pending, segment{seq: seq, size: fi.Size()})
+		q.size += fi.Size()
+		if seq >= q.nextSeq {
+			q.nextSeq = seq + 1
+		}
+	}
+
+	sort.Slice(q.pending, func(i, j int) bool { return q.pending[i].seq < q.pending[j].seq })
+	if len(q.pending) > 0 {
+		q.signal()
+	}
+	return q, nil
+}
+
+// Push writes data to the end of the queue. It returns the number of older
+// batches which were dropped to stay within the maximum size of the queue.
+func (q *diskQueue) Push(data []byte) (dropped int, err error) {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+
+	seq := q.nextSeq
+	q.nextSeq++
+
+	// Write to a temporary file first so that a partially written batch is
+	// never sent.
+	path := q.segmentPath(seq)
+	if err := os.WriteFile(path+".tmp", data, 0640); err != nil {
+		return 0, fmt.Errorf("failed to write batch: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return 0, fmt.Errorf("failed to write batch: %w", err)
+	}
+
+	q.pending = append(q.pending, segment{seq: seq, size: int64(len(data))})
+	q.size += int64(len(data))
+
+	// Drop the oldest pending batches until the queue fits. The batch which
+	// was just written is always kept.
+	for q.size > q.maxSize && len(q.pending) > 1 {
+		oldest := q.pending[0]
+		q.pending = q.pending[1:]
+		q.removeLocked(oldest)
+		dropped++
+	}
+
+	q.signal()
+	return dropped, nil
+}
+
+// Next blocks until a batch is available or ctx is canceled. The returned
+// batch must be passed to either Remove or Release once processed.
+func (q *diskQueue) Next(ctx context.Context) (segment, []byte, error) {
+	for {
+		q.mut.Lock()
+		if len(q.pending) > 0 {
+			seg := q.pending[0]
+			q.pending = q.pending[1:]
+			if len(q.pending) > 0 {
+				// Wake up another waiter for the remaining batches.
+				q.signal()
+			}
+			q.mut.Unlock()
+
+			data, err := os.ReadFile(q.segmentPath(seg.seq))
+			if err != nil {
+				// The batch can't be read; drop it and move on.
+				q.Remove(seg)
+				continue
+			}
+			return seg, data, nil
+		}
+		q.mut.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return segment{}, nil, ctx.Err()
+		case <-q.notify:
+		}
+	}
+}
+
+// Remove permanently deletes seg from the queue.
+func (q *diskQueue) Remove(seg segment) {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+	q.removeLocked(seg)
+}
+
+func (q *diskQueue) removeLocked(seg segment) {
+	if err := os.Remove(q.segmentPath(seg.seq)); err == nil || os.IsNotExist(err) {
+		q.size -= seg.size
+	}
+}
+
+// Release returns seg to the queue so it can be returned by Next again.
+func (q *diskQueue) Release(seg segment) {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+
+	idx := sort.Search(len(q.pending), func(i int) bool { return q.pending[i].seq > seg.seq })
+	q.pending = append(q.pending, segment{})
+	copy(q.pending[idx+1:], q.pending[idx:])
+	q.pending[idx] = seg
+
+	q.signal()
+}
+
+// Stats returns the number of pending batches and the total size of the
+// queue on disk.
+func (q *diskQueue) Stats() (pending int, size int64) {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+	return len(q.pending), q.size
+}
+
+func (q *diskQueue) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (q *diskQueue) segmentPath(seq uint64) string {
+	return filepath.Join(q.dir, strconv.FormatUint(seq, 10)+segmentSuffix)
+}
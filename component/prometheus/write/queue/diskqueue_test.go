@@ -0,0 +1,96 @@
+package queue
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskQueue(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	q, err := openDiskQueue(dir, 1024)
+	require.NoError(t, err)
+
+	for _, data := range []string{"first", "second", "third"} {
+		dropped, err := q.Push([]byte(data))
+		require.NoError(t, err)
+		require.Zero(t, dropped)
+	}
+
+	// Batches are returned in order.
+	seg, data, err := q.Next(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "first", string(data))
+	q.Remove(seg)
+
+	// Released batches are returned again.
+	seg, data, err = q.Next(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "second", string(data))
+	q.Release(seg)
+
+	pending, size := q.Stats()
+	require.Equal(t, 2, pending)
+	require.Equal(t, int64(len("second")+len("third")), size)
+
+	// Reopening the queue picks up batches which weren't removed and cleans
+	// up leftover temporary files.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "10.batch.tmp"), []byte("partial"), 0640))
+
+	q, err = openDiskQueue(dir, 1024)
+	require.NoError(t, err)
+
+	_, data, err = q.Next(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "second", string(data))
+	_, data, err = q.Next(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "third", string(data))
+
+	require.NoFileExists(t, filepath.Join(dir, "10.batch.tmp"))
+
+	// Pushing after a reopen doesn't overwrite existing batches.
+	_, err = q.Push([]byte("fourth"))
+	require.NoError(t, err)
+	_, data, err = q.Next(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "fourth", string(data))
+}
+
+func TestDiskQueue_MaxSize(t *testing.T) {
+	q, err := openDiskQueue(t.TempDir(), 10)
+	require.NoError(t, err)
+
+	dropped, err := q.Push([]byte("aaaaa"))
+	require.NoError(t, err)
+	require.Zero(t, dropped)
+
+	dropped, err = q.Push([]byte("bbbbb"))
+	require.NoError(t, err)
+	require.Zero(t, dropped)
+
+	// The oldest batch is dropped to stay within the maximum size.
+	dropped, err = q.Push([]byte("ccccc"))
+	require.NoError(t, err)
+	require.Equal(t, 1, dropped)
+
+	_, data, err := q.Next(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "bbbbb", string(data))
+}
+
+func TestDiskQueue_NextCanceled(t *testing.T) {
+	q, err := openDiskQueue(t.TempDir(), 1024)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err = q.Next(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,141 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/backoff"
+	"github.com/prometheus/prometheus/storage/remote"
+)
+
+// endpoint sends batches from its disk queue to a remote_write endpoint.
+type endpoint struct {
+	log     log.Logger
+	name    string
+	opts    *EndpointOptions
+	queue   *diskQueue
+	client  remote.WriteClient
+	metrics *metrics
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// newEndpoint opens the disk queue for an endpoint stored in dir and starts
+// sending queued batches.
+func newEndpoint(l log.Logger, dir string, opts *EndpointOptions, maxDiskSize int64, m *metrics) (*endpoint, error) {
+	clientConfig, err := opts.clientConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := remote.NewWriteClient(opts.name(), clientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	queue, err := openDiskQueue(dir, maxDiskSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ep := &endpoint{
+		log:     log.With(l, "endpoint", opts.name()),
+		name:    opts.name(),
+		opts:    opts,
+		queue:   queue,
+		client:  client,
+		metrics: m,
+		cancel:  cancel,
+	}
+	ep.updateQueueMetrics()
+
+	ep.wg.Add(opts.Parallelism)
+	for i := 0; i < opts.Parallelism; i++ {
+		go func() {
+			defer ep.wg.Done()
+			ep.run(ctx)
+		}()
+	}
+	return ep, nil
+}
+
+// push adds a serialized batch to the endpoint's queue.
+func (ep *endpoint) push(data []byte) {
+	dropped, err := ep.queue.Push(data)
+	if err != nil {
+		level.Error(ep.log).Log("msg", "failed to queue batch", "err", err)
+		ep.metrics.batchesDropped.WithLabelValues(ep.name).Inc()
+	}
+	if dropped > 0 {
+		level.Warn(ep.log).Log("msg", "queue is full, dropped oldest batches", "count", dropped)
+		ep.metrics.batchesDropped.WithLabelValues(ep.name).Add(float64(dropped))
+	}
+	ep.updateQueueMetrics()
+}
+
+// run sends batches from the queue until ctx is canceled.
+func (ep *endpoint) run(ctx context.Context) {
+	for {
+		seg, data, err := ep.queue.Next(ctx)
+		if err != nil {
+			return
+		}
+
+		if err := ep.send(ctx, data); err != nil {
+			// The batch couldn't be sent before shutting down. Return it to the
+			// queue so it's sent after the next start.
+			ep.queue.Release(seg)
+			return
+		}
+		ep.queue.Remove(seg)
+		ep.updateQueueMetrics()
+	}
+}
+
+// send sends data to the endpoint, retrying recoverable errors. Batches which
+// fail with unrecoverable errors are dropped. send only returns an error if
+// ctx is canceled before the batch is handled.
+func (ep *endpoint) send(ctx context.Context, data []byte) error {
+	bo := backoff.New(ctx, backoff.Config{
+		MinBackoff: ep.opts.MinBackoff,
+		MaxBackoff: ep.opts.MaxBackoff,
+	})
+
+	for bo.Ongoing() {
+		err := ep.client.Store(ctx, data)
+		if err == nil {
+			ep.metrics.batchesSent.WithLabelValues(ep.name).Inc()
+			return nil
+		}
+
+		var recoverable remote.RecoverableError
+		if !errors.As(err, &recoverable) {
+			level.Error(ep.log).Log("msg", "non-recoverable error while sending batch, dropping it", "err", err)
+			ep.metrics.batchesFailed.WithLabelValues(ep.name).Inc()
+			return nil
+		}
+
+		level.Warn(ep.log).Log("msg", "failed to send batch, retrying", "err", err)
+		ep.metrics.retries.WithLabelValues(ep.name).Inc()
+		bo.Wait()
+	}
+	return bo.Err()
+}
+
+// stop stops sending batches and waits for in-flight requests to return.
+// Unsent batches remain on disk.
+func (ep *endpoint) stop() {
+	ep.cancel()
+	ep.wg.Wait()
+}
+
+func (ep *endpoint) updateQueueMetrics() {
+	pending, size := ep.queue.Stats()
+	ep.metrics.pendingBatches.WithLabelValues(ep.name).Set(float64(pending))
+	ep.metrics.diskBytes.WithLabelValues(ep.name).Set(float64(size))
+}
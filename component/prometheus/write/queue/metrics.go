@@ -0,0 +1,75 @@
+package queue
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	seriesReceived prometheus.Counter
+
+	batchesSent    *prometheus.CounterVec
+	batchesFailed  *prometheus.CounterVec
+	batchesDropped *prometheus.CounterVec
+	retries        *prometheus.CounterVec
+	pendingBatches *prometheus.GaugeVec
+	diskBytes      *prometheus.GaugeVec
+}
+
+func newMetrics(reg prometheus.Registerer) (*metrics, error) {
+	m := &metrics{
+		seriesReceived: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "prometheus_write_queue_series_received_total",
+			Help: "Total number of series entries received by the queue.",
+		}),
+
+		batchesSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "prometheus_write_queue_batches_sent_total",
+			Help: "Total number of batches successfully sent to an endpoint.",
+		}, []string{"endpoint"}),
+		batchesFailed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "prometheus_write_queue_batches_failed_total",
+			Help: "Total number of batches rejected by an endpoint with a non-recoverable error.",
+		}, []string{"endpoint"}),
+		batchesDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "prometheus_write_queue_batches_dropped_total",
+			Help: "Total number of batches dropped because the queue exceeded its maximum size.",
+		}, []string{"endpoint"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "prometheus_write_queue_retries_total",
+			Help: "Total number of retried requests to an endpoint.",
+		}, []string{"endpoint"}),
+		pendingBatches: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "prometheus_write_queue_pending_batches",
+			Help: "Number of batches waiting to be sent to an endpoint.",
+		}, []string{"endpoint"}),
+		diskBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "prometheus_write_queue_disk_bytes",
+			Help: "Size in bytes of the queue of an endpoint on disk.",
+		}, []string{"endpoint"}),
+	}
+
+	for _, c := range []prometheus.Collector{
+		m.seriesReceived,
+		m.batchesSent,
+		m.batchesFailed,
+		m.batchesDropped,
+		m.retries,
+		m.pendingBatches,
+		m.diskBytes,
+	} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// deleteEndpoint removes all series for the endpoint with the given name.
+func (m *metrics) deleteEndpoint(name string) {
+	m.batchesSent.DeleteLabelValues(name)
+	m.batchesFailed.DeleteLabelValues(name)
+	m.batchesDropped.DeleteLabelValues(name)
+	m.retries.DeleteLabelValues(name)
+	m.pendingBatches.DeleteLabelValues(name)
+	m.diskBytes.DeleteLabelValues(name)
+}
@@ -0,0 +1,260 @@
+// Package queue provides the prometheus.write.queue component, which sends
+// metrics to remote_write endpoints through a disk-backed queue.
+package queue
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/agent/component"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/storage"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:    "prometheus.write.queue",
+		Args:    Arguments{},
+		Exports: Exports{},
+		Build: func(o component.Options, a component.Arguments) (component.Component, error) {
+			return New(o, a.(Arguments))
+		},
+	})
+}
+
+// Exports are the set of fields exposed by the prometheus.write.queue
+// component.
+type Exports struct {
+	Receiver storage.Appendable `river:"receiver,attr"`
+}
+
+// Component is the prometheus.write.queue component.
+type Component struct {
+	log     log.Logger
+	opts    component.Options
+	metrics *metrics
+
+	updateCh chan struct{}
+
+	mut            sync.RWMutex
+	args           Arguments
+	externalLabels labels.Labels
+	endpoints      map[string]*endpoint
+
+	// batchMut guards the in-memory batch. The batch never holds more than
+	// batch_size series; it's written to the endpoint queues once it's full or
+	// when the flush interval elapses.
+	batchMut sync.Mutex
+	batch    []prompb.TimeSeries
+}
+
+var (
+	_ component.Component        = (*Component)(nil)
+	_ component.StorageComponent = (*Component)(nil)
+	_ storage.Appendable         = (*Component)(nil)
+)
+
+// New creates a new prometheus.write.queue component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	m, err := newMetrics(o.Registerer)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Component{
+		log:       o.Logger,
+		opts:      o,
+		metrics:   m,
+		updateCh:  make(chan struct{}, 1),
+		endpoints: make(map[string]*endpoint),
+	}
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+
+	// The receiver remains the same for the component's lifetime, so export
+	// it once during construction.
+	o.OnStateChange(Exports{Receiver: c})
+	return c, nil
+}
+
+// Run implements Component.
+func (c *Component) Run(ctx context.Context) error {
+	defer func() {
+		// Write out whatever is still batched in memory so that it's sent after
+		// the next start.
+		c.flush()
+
+		c.mut.Lock()
+		defer c.mut.Unlock()
+		for _, ep := range c.endpoints {
+			ep.stop()
+		}
+	}()
+
+	ticker := time.NewTicker(c.flushInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-c.updateCh:
+			ticker.Reset(c.flushInterval())
+		case <-ticker.C:
+			c.flush()
+		}
+	}
+}
+
+func (c *Component) flushInterval() time.Duration {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return c.args.Persistence.FlushInterval
+}
+
+// Update implements Component.
+func (c *Component) Update(newArgs component.Arguments) error {
+	args := newArgs.(Arguments)
+
+	// Write out the current batch before endpoints change so it's delivered
+	// to the endpoints it was received for.
+	c.flush()
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	// Endpoints are always recreated. Stopping an endpoint leaves its unsent
+	// batches on disk, where the new endpoint picks them up again.
+	newNames := make(map[string]struct{}, len(args.Endpoints))
+	for _, opts := range args.Endpoints {
+		newNames[opts.name()] = struct{}{}
+	}
+	for name, ep := range c.endpoints {
+		ep.stop()
+		delete(c.endpoints, name)
+		if _, keep := newNames[name]; !keep {
+			c.metrics.deleteEndpoint(name)
+		}
+	}
+
+	var firstErr error
+	for _, opts := range args.Endpoints {
+		dir := c.queueDirectory(args.Persistence, opts.name())
+		ep, err := newEndpoint(c.log, dir, opts, int64(args.Persistence.MaxDiskSize), c.metrics)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to create endpoint %q: %w", opts.name(), err)
+			}
+			continue
+		}
+		c.endpoints[opts.name()] = ep
+	}
+
+	c.args = args
+	c.externalLabels = toLabels(args.ExternalLabels)
+
+	select {
+	case c.updateCh <- struct{}{}:
+	default:
+	}
+	return firstErr
+}
+
+// queueDirectory returns the directory to store the queue of an endpoint in.
+func (c *Component) queueDirectory(opts PersistenceOptions, endpointName string) string {
+	if opts.Directory != "" {
+		return filepath.Join(opts.Directory, c.opts.ID, endpointName)
+	}
+	return filepath.Join(c.opts.DataPath, endpointName)
+}
+
+// StoragePaths implements component.StorageComponent. The queues of all
+// endpoints, including endpoints which were removed but may still have
+// unsent batches, are stored under a single directory.
+func (c *Component) StoragePaths() []component.StoragePath {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	root := c.opts.DataPath
+	if c.args.Persistence.Directory != "" {
+		root = filepath.Join(c.args.Persistence.Directory, c.opts.ID)
+	}
+	return []component.StoragePath{{Path: root, Buffer: true}}
+}
+
+// Appender implements storage.Appendable.
+func (c *Component) Appender(_ context.Context) storage.Appender {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return &appender{c: c, externalLabels: c.externalLabels}
+}
+
+// enqueue adds series to the in-memory batch, writing out full batches to
+// the endpoint queues.
+func (c *Component) enqueue(series []prompb.TimeSeries) {
+	c.metrics.seriesReceived.Add(float64(len(series)))
+
+	c.mut.RLock()
+	batchSize := c.args.Persistence.BatchSize
+	c.mut.RUnlock()
+
+	c.batchMut.Lock()
+	defer c.batchMut.Unlock()
+
+	for len(series) > 0 {
+		n := batchSize - len(c.batch)
+		if n > len(series) {
+			n = len(series)
+		}
+		c.batch = append(c.batch, series[:n]...)
+		series = series[n:]
+
+		if len(c.batch) >= batchSize {
+			c.writeBatch(c.batch)
+			c.batch = nil
+		}
+	}
+}
+
+// flush writes out the current in-memory batch, if any.
+func (c *Component) flush() {
+	c.batchMut.Lock()
+	defer c.batchMut.Unlock()
+
+	if len(c.batch) == 0 {
+		return
+	}
+	c.writeBatch(c.batch)
+	c.batch = nil
+}
+
+// writeBatch encodes series as a remote_write request and adds it to the
+// queue of every endpoint. batchMut must be held.
+func (c *Component) writeBatch(series []prompb.TimeSeries) {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	if len(c.endpoints) == 0 {
+		return
+	}
+
+	raw, err := proto.Marshal(&prompb.WriteRequest{Timeseries: series})
+	if err != nil {
+		level.Error(c.log).Log("msg", "failed to encode batch", "err", err)
+		return
+	}
+	data := snappy.Encode(nil, raw)
+
+	for _, ep := range c.endpoints {
+		ep.push(data)
+	}
+}
@@ -0,0 +1,175 @@
+package queue_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/prometheus/write/queue"
+	"github.com/grafana/agent/pkg/flow/componenttest"
+	"github.com/grafana/agent/pkg/river"
+	"github.com/grafana/agent/pkg/util"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/storage/remote"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+// Test is an integration-level test which ensures that metrics sent to a
+// prometheus.write.queue component are forwarded to a remote_write-compatible
+// server, retrying recoverable errors.
+func Test(t *testing.T) {
+	writeResult := make(chan *prompb.WriteRequest, 1)
+
+	// Create a remote_write server which fails the first request with a
+	// recoverable error and forwards all other payloads to the writeResult
+	// channel.
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Inc() == 1 {
+			http.Error(w, "try again", http.StatusInternalServerError)
+			return
+		}
+
+		req, err := remote.DecodeWriteRequest(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case writeResult <- req:
+		default:
+			require.Fail(t, "failed to send remote_write result over channel")
+		}
+	}))
+	defer srv.Close()
+
+	cfg := fmt.Sprintf(`
+		external_labels = {
+			cluster = "local",
+		}
+
+		endpoint {
+			name        = "test-url"
+			url         = "%s/api/v1/write"
+			min_backoff = "10ms"
+			max_backoff = "10ms"
+		}
+
+		persistence {
+			directory      = "%s"
+			flush_interval = "100ms"
+		}
+	`, srv.URL, t.TempDir())
+
+	var args queue.Arguments
+	require.NoError(t, river.Unmarshal([]byte(cfg), &args))
+
+	tc, err := componenttest.NewControllerFromID(util.TestLogger(t), "prometheus.write.queue")
+	require.NoError(t, err)
+	go func() {
+		err = tc.Run(componenttest.TestContext(t), args)
+		require.NoError(t, err)
+	}()
+	require.NoError(t, tc.WaitRunning(time.Second))
+
+	sampleTimestamp := time.Now().UnixMilli()
+
+	exports := tc.Exports().(queue.Exports)
+	appender := exports.Receiver.Appender(context.Background())
+	_, err = appender.Append(0, labels.FromStrings("foo", "bar"), sampleTimestamp, 12)
+	require.NoError(t, err)
+	_, err = appender.Append(0, labels.FromStrings("cluster", "remote", "fizz", "buzz"), sampleTimestamp, 34)
+	require.NoError(t, err)
+	require.NoError(t, appender.Commit())
+
+	expect := []prompb.TimeSeries{{
+		Labels: []prompb.Label{
+			{Name: "cluster", Value: "local"},
+			{Name: "foo", Value: "bar"},
+		},
+		Samples: []prompb.Sample{
+			{Timestamp: sampleTimestamp, Value: 12},
+		},
+	}, {
+		Labels: []prompb.Label{
+			{Name: "cluster", Value: "remote"},
+			{Name: "fizz", Value: "buzz"},
+		},
+		Samples: []prompb.Sample{
+			{Timestamp: sampleTimestamp, Value: 34},
+		},
+	}}
+
+	select {
+	case <-time.After(time.Minute):
+		require.FailNow(t, "timed out waiting for metrics")
+	case res := <-writeResult:
+		require.Equal(t, expect, res.Timeseries)
+	}
+	require.Equal(t, int64(2), requests.Load())
+}
+
+func TestArguments_UnmarshalRiver(t *testing.T) {
+	tt := []struct {
+		name   string
+		cfg    string
+		expect string
+	}{
+		{
+			name: "duplicate endpoint names",
+			cfg: `
+				endpoint {
+					url = "http://localhost:9009/api/prom/push"
+				}
+				endpoint {
+					url = "http://localhost:9009/api/prom/push"
+				}
+			`,
+			expect: "found duplicate endpoint name",
+		},
+		{
+			name: "invalid parallelism",
+			cfg: `
+				endpoint {
+					url         = "http://localhost:9009/api/prom/push"
+					parallelism = 0
+				}
+			`,
+			expect: "parallelism must be greater than 0",
+		},
+		{
+			name: "invalid backoff",
+			cfg: `
+				endpoint {
+					url         = "http://localhost:9009/api/prom/push"
+					min_backoff = "1s"
+					max_backoff = "100ms"
+				}
+			`,
+			expect: "max_backoff must not be smaller than min_backoff",
+		},
+		{
+			name: "invalid batch size",
+			cfg: `
+				persistence {
+					batch_size = 0
+				}
+			`,
+			expect: "batch_size must be greater than 0",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var args queue.Arguments
+			err := river.Unmarshal([]byte(tc.cfg), &args)
+			require.ErrorContains(t, err, tc.expect)
+		})
+	}
+}
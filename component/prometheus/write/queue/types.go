@@ -0,0 +1,187 @@
+package queue
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/alecthomas/units"
+	types "github.com/grafana/agent/component/common/config"
+	"github.com/grafana/agent/pkg/river"
+	common "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage/remote"
+)
+
+// Defaults for config blocks.
+var (
+	DefaultArguments = Arguments{
+		Persistence: DefaultPersistenceOptions,
+	}
+
+	DefaultPersistenceOptions = PersistenceOptions{
+		BatchSize:     10000,
+		FlushInterval: time.Second,
+		MaxDiskSize:   units.GiB,
+	}
+
+	_ river.Unmarshaler = (*Arguments)(nil)
+	_ river.Unmarshaler = (*EndpointOptions)(nil)
+	_ river.Unmarshaler = (*PersistenceOptions)(nil)
+)
+
+// Arguments represents the input state of the prometheus.write.queue
+// component.
+type Arguments struct {
+	ExternalLabels map[string]string  `river:"external_labels,attr,optional"`
+	Endpoints      []*EndpointOptions `river:"endpoint,block,optional"`
+	Persistence    PersistenceOptions `river:"persistence,block,optional"`
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (args *Arguments) UnmarshalRiver(f func(interface{}) error) error {
+	*args = DefaultArguments
+
+	type arguments Arguments
+	if err := f((*arguments)(args)); err != nil {
+		return err
+	}
+
+	names := make(map[string]struct{}, len(args.Endpoints))
+	for _, ep := range args.Endpoints {
+		name := ep.name()
+		if _, exists := names[name]; exists {
+			return fmt.Errorf("found duplicate endpoint name %q", name)
+		}
+		names[name] = struct{}{}
+	}
+	return nil
+}
+
+// EndpointOptions describes an individual location for where queued metrics
+// should be delivered to using the remote_write protocol.
+type EndpointOptions struct {
+	Name             string                  `river:"name,attr,optional"`
+	URL              string                  `river:"url,attr"`
+	RemoteTimeout    time.Duration           `river:"remote_timeout,attr,optional"`
+	Headers          map[string]string       `river:"headers,attr,optional"`
+	Parallelism      int                     `river:"parallelism,attr,optional"`
+	MinBackoff       time.Duration           `river:"min_backoff,attr,optional"`
+	MaxBackoff       time.Duration           `river:"max_backoff,attr,optional"`
+	RetryOnHTTP429   bool                    `river:"retry_on_http_429,attr,optional"`
+	HTTPClientConfig *types.HTTPClientConfig `river:",squash"`
+}
+
+// GetDefaultEndpointOptions returns the default settings for an endpoint
+// block.
+func GetDefaultEndpointOptions() EndpointOptions {
+	return EndpointOptions{
+		RemoteTimeout:    30 * time.Second,
+		Parallelism:      1,
+		MinBackoff:       30 * time.Millisecond,
+		MaxBackoff:       5 * time.Second,
+		RetryOnHTTP429:   true,
+		HTTPClientConfig: types.CloneDefaultHTTPClientConfig(),
+	}
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (r *EndpointOptions) UnmarshalRiver(f func(v interface{}) error) error {
+	*r = GetDefaultEndpointOptions()
+
+	type arguments EndpointOptions
+	if err := f((*arguments)(r)); err != nil {
+		return err
+	}
+
+	if _, err := url.Parse(r.URL); err != nil {
+		return fmt.Errorf("cannot parse endpoint url %q: %w", r.URL, err)
+	}
+
+	switch {
+	case r.Parallelism <= 0:
+		return fmt.Errorf("parallelism must be greater than 0")
+	case r.MinBackoff <= 0:
+		return fmt.Errorf("min_backoff must be greater than 0")
+	case r.MaxBackoff < r.MinBackoff:
+		return fmt.Errorf("max_backoff must not be smaller than min_backoff")
+	}
+
+	// We must explicitly Validate because HTTPClientConfig is squashed and it won't run otherwise
+	if r.HTTPClientConfig != nil {
+		return r.HTTPClientConfig.Validate()
+	}
+	return nil
+}
+
+// name returns the name of the endpoint, which is also used as the name of
+// its queue directory. If no name is configured, a name is derived from the
+// URL so that queued data is found again after a restart.
+func (r *EndpointOptions) name() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	hash := sha256.Sum256([]byte(r.URL))
+	return hex.EncodeToString(hash[:])[:12]
+}
+
+// clientConfig converts r into the configuration for a remote write client.
+func (r *EndpointOptions) clientConfig() (*remote.ClientConfig, error) {
+	parsedURL, err := url.Parse(r.URL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse endpoint url %q: %w", r.URL, err)
+	}
+
+	return &remote.ClientConfig{
+		URL:              &common.URL{URL: parsedURL},
+		Timeout:          model.Duration(r.RemoteTimeout),
+		HTTPClientConfig: *r.HTTPClientConfig.Convert(),
+		Headers:          r.Headers,
+		RetryOnRateLimit: r.RetryOnHTTP429,
+	}, nil
+}
+
+// PersistenceOptions configures how samples are buffered in memory and on
+// disk.
+type PersistenceOptions struct {
+	// Directory is the base directory to store queued data in. If empty, data
+	// is stored inside the agent's data path.
+	Directory string `river:"directory,attr,optional"`
+
+	BatchSize     int              `river:"batch_size,attr,optional"`
+	FlushInterval time.Duration    `river:"flush_interval,attr,optional"`
+	MaxDiskSize   units.Base2Bytes `river:"max_disk_size,attr,optional"`
+}
+
+// UnmarshalRiver implements river.Unmarshaler.
+func (o *PersistenceOptions) UnmarshalRiver(f func(interface{}) error) error {
+	*o = DefaultPersistenceOptions
+
+	type options PersistenceOptions
+	if err := f((*options)(o)); err != nil {
+		return err
+	}
+
+	switch {
+	case o.BatchSize <= 0:
+		return fmt.Errorf("batch_size must be greater than 0")
+	case o.FlushInterval <= 0:
+		return fmt.Errorf("flush_interval must be greater than 0")
+	case o.MaxDiskSize <= 0:
+		return fmt.Errorf("max_disk_size must be greater than 0")
+	}
+	return nil
+}
+
+func toLabels(in map[string]string) labels.Labels {
+	res := make(labels.Labels, 0, len(in))
+	for k, v := range in {
+		res = append(res, labels.Label{Name: k, Value: v})
+	}
+	sort.Sort(res)
+	return res
+}
@@ -0,0 +1,366 @@
+package agentctl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/prometheus/prometheus/model/textparse"
+	"github.com/prometheus/prometheus/scrape"
+)
+
+// scrapeAcceptHeader mirrors the Accept header used by the Prometheus scraper.
+const scrapeAcceptHeader = `application/openmetrics-text;version=1.0.0,application/openmetrics-text;version=0.0.1;q=0.75,text/plain;version=0.0.4;q=0.5,*/*;q=0.1`
+
+// ScrapeDebugOptions configures a one-off debug scrape.
+type ScrapeDebugOptions struct {
+	// Target holds the discovered labels of the target to scrape. If
+	// __address__ isn't set, the scrape fails.
+	Target map[string]string
+
+	JobName        string
+	Scheme         string
+	MetricsPath    string
+	Params         url.Values
+	HonorLabels    bool
+	ScrapeTimeout  time.Duration
+	ScrapeInterval time.Duration
+
+	// RelabelConfigs are applied to the target before scraping.
+	RelabelConfigs []*relabel.Config
+	// MetricRelabels are applied to each scraped sample.
+	MetricRelabels []*relabel.Config
+
+	// Client is used to perform the scrape. http.DefaultClient is used if nil.
+	Client *http.Client
+}
+
+// DefaultScrapeDebugOptions holds defaults for ScrapeDebugOptions.
+var DefaultScrapeDebugOptions = ScrapeDebugOptions{
+	JobName:        "debug",
+	Scheme:         "http",
+	MetricsPath:    "/metrics",
+	ScrapeTimeout:  10 * time.Second,
+	ScrapeInterval: time.Minute,
+}
+
+// ScrapeDebugResult holds the outcome of each step of a debug scrape.
+type ScrapeDebugResult struct {
+	// DiscoveredLabels are the labels of the target before target relabeling.
+	DiscoveredLabels labels.Labels
+	// TargetLabels are the labels of the target after target relabeling. nil
+	// if the target was dropped.
+	TargetLabels labels.Labels
+	// URL is the URL which was scraped.
+	URL string
+
+	// Samples holds the parsed samples, with Dropped set for samples which
+	// were dropped by metric relabeling.
+	Samples []ScrapeDebugSample
+
+	// Err is the first error encountered while processing the target.
+	Err error
+}
+
+// ScrapeDebugSample is an individual sample from a debug scrape.
+type ScrapeDebugSample struct {
+	// Original labels of the sample as exposed by the target.
+	Original labels.Labels
+	// Labels of the sample after merging target labels and applying metric
+	// relabeling. nil if the sample was dropped.
+	Labels    labels.Labels
+	Value     float64
+	Timestamp *int64
+}
+
+// Dropped returns true if the sample was dropped by metric relabeling.
+func (s ScrapeDebugSample) Dropped() bool { return s.Labels == nil }
+
+// ScrapeDebug performs a single scrape of the target described by opts,
+// recording the result of each stage of the scrape pipeline. Errors from
+// scraping the target are reported in the result's Err field; the returned
+// error is only non-nil if opts is invalid.
+func ScrapeDebug(ctx context.Context, opts ScrapeDebugOptions) (*ScrapeDebugResult, error) {
+	if len(opts.Target) == 0 {
+		return nil, fmt.Errorf("a target must be provided")
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	cfg := config.DefaultScrapeConfig
+	cfg.JobName = opts.JobName
+	cfg.Scheme = opts.Scheme
+	cfg.MetricsPath = opts.MetricsPath
+	cfg.Params = opts.Params
+	cfg.HonorLabels = opts.HonorLabels
+	cfg.ScrapeTimeout = model.Duration(opts.ScrapeTimeout)
+	cfg.ScrapeInterval = model.Duration(opts.ScrapeInterval)
+	cfg.RelabelConfigs = opts.RelabelConfigs
+
+	var res ScrapeDebugResult
+
+	discovered := labels.FromMap(opts.Target)
+	targetLabels, origLabels, err := scrape.PopulateLabels(discovered, &cfg, false)
+	res.DiscoveredLabels = origLabels
+	if res.DiscoveredLabels == nil {
+		res.DiscoveredLabels = discovered
+	}
+	if err != nil {
+		res.Err = fmt.Errorf("invalid target: %w", err)
+		return &res, nil
+	} else if targetLabels == nil {
+		res.Err = fmt.Errorf("target was dropped by relabeling")
+		return &res, nil
+	}
+	res.TargetLabels = targetLabels
+
+	target := scrape.NewTarget(targetLabels, origLabels, cfg.Params)
+	res.URL = target.URL().String()
+
+	ctx, cancel := context.WithTimeout(ctx, opts.ScrapeTimeout)
+	defer cancel()
+
+	body, contentType, err := fetchScrape(ctx, client, res.URL)
+	if err != nil {
+		res.Err = err
+		return &res, nil
+	}
+
+	res.Samples, res.Err = parseScrape(body, contentType, targetLabels, opts.HonorLabels, opts.MetricRelabels)
+	return &res, nil
+}
+
+func fetchScrape(ctx context.Context, client *http.Client, target string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", scrapeAcceptHeader)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("scrape failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("server returned HTTP status %s", resp.Status)
+	}
+
+	bb, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("reading scrape response: %w", err)
+	}
+	return bb, resp.Header.Get("Content-Type"), nil
+}
+
+func parseScrape(body []byte, contentType string, targetLabels labels.Labels, honorLabels bool, rcs []*relabel.Config) ([]ScrapeDebugSample, error) {
+	p, err := textparse.New(body, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported content type %q: %w", contentType, err)
+	}
+
+	var samples []ScrapeDebugSample
+	for {
+		entry, err := p.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return samples, fmt.Errorf("parsing scrape response: %w", err)
+		}
+		if entry != textparse.EntrySeries {
+			continue
+		}
+
+		var lset labels.Labels
+		_, ts, v := p.Series()
+		p.Metric(&lset)
+
+		final := relabel.Process(mergeTargetLabels(lset, targetLabels, honorLabels), rcs...)
+		samples = append(samples, ScrapeDebugSample{
+			Original:  lset,
+			Labels:    final,
+			Value:     v,
+			Timestamp: ts,
+		})
+	}
+	return samples, nil
+}
+
+// mergeTargetLabels attaches target labels to a scraped sample, following
+// the same conflict rules as the Prometheus scraper.
+func mergeTargetLabels(lset, target labels.Labels, honorLabels bool) labels.Labels {
+	lb := labels.NewBuilder(lset)
+
+	for _, l := range target {
+		if strings.HasPrefix(l.Name, model.ReservedLabelPrefix) {
+			continue
+		}
+		existing := lset.Get(l.Name)
+		switch {
+		case existing == "":
+			lb.Set(l.Name, l.Value)
+		case honorLabels:
+			// Keep the scraped value.
+		default:
+			lb.Set(model.ExportedLabelPrefix+l.Name, existing)
+			lb.Set(l.Name, l.Value)
+		}
+	}
+
+	return lb.Labels(nil)
+}
+
+// Fprint writes a human-readable report of r to w. At most maxSamples
+// samples are printed; 0 prints all samples.
+func (r *ScrapeDebugResult) Fprint(w io.Writer, maxSamples int) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "Discovered labels:")
+	for _, l := range r.DiscoveredLabels {
+		fmt.Fprintf(tw, "  %s\t%q\n", l.Name, l.Value)
+	}
+
+	fmt.Fprintln(tw, "\nTarget labels after relabeling:")
+	if r.TargetLabels == nil {
+		fmt.Fprintln(tw, "  <dropped>")
+	}
+	for _, l := range r.TargetLabels {
+		fmt.Fprintf(tw, "  %s\t%q\n", l.Name, l.Value)
+	}
+
+	if r.URL != "" {
+		fmt.Fprintf(tw, "\nScrape URL: %s\n", r.URL)
+	}
+
+	if len(r.Samples) > 0 {
+		var dropped int
+		for _, s := range r.Samples {
+			if s.Dropped() {
+				dropped++
+			}
+		}
+		fmt.Fprintf(tw, "\nSamples (%d scraped, %d dropped by metric relabeling):\n", len(r.Samples), dropped)
+
+		samples := r.Samples
+		if maxSamples > 0 && len(samples) > maxSamples {
+			samples = samples[:maxSamples]
+		}
+		for _, s := range samples {
+			if s.Dropped() {
+				fmt.Fprintf(tw, "  %s\t%g\t<dropped>\n", s.Original, s.Value)
+			} else {
+				fmt.Fprintf(tw, "  %s\t%g\n", s.Labels, s.Value)
+			}
+		}
+		if len(samples) < len(r.Samples) {
+			fmt.Fprintf(tw, "  ... %d more samples not shown\n", len(r.Samples)-len(samples))
+		}
+	}
+
+	if r.Err != nil {
+		fmt.Fprintf(tw, "\nError: %s\n", r.Err)
+	}
+
+	return tw.Flush()
+}
+
+// ComponentTargets extracts the discovered targets from the JSON
+// representation of a prometheus.scrape component as returned by the Flow
+// API at /api/v0/web/components/{id}.
+//
+// The returned targets hold the labels of each active target after target
+// relabeling, along with the __address__, __scheme__, and __metrics_path__
+// labels derived from the target URL so they can be scraped again.
+func ComponentTargets(r io.Reader) ([]map[string]string, error) {
+	var info struct {
+		Name      string            `json:"name"`
+		DebugInfo []riverJSONObject `json:"debugInfo"`
+	}
+	if err := json.NewDecoder(r).Decode(&info); err != nil {
+		return nil, fmt.Errorf("decoding component: %w", err)
+	}
+	if info.Name != "prometheus.scrape" {
+		return nil, fmt.Errorf("expected a prometheus.scrape component, got %q", info.Name)
+	}
+
+	var res []map[string]string
+	for _, block := range info.DebugInfo {
+		if block.Type != "block" || block.Name != "target" {
+			continue
+		}
+
+		target := make(map[string]string)
+		for _, attr := range block.Body {
+			switch attr.Name {
+			case "url":
+				var raw string
+				if err := json.Unmarshal(attr.Value.Value, &raw); err != nil {
+					return nil, fmt.Errorf("decoding target URL: %w", err)
+				}
+				u, err := url.Parse(raw)
+				if err != nil {
+					return nil, fmt.Errorf("invalid target URL %q: %w", raw, err)
+				}
+				target[model.AddressLabel] = u.Host
+				target[model.SchemeLabel] = u.Scheme
+				target[model.MetricsPathLabel] = u.Path
+				for k, vv := range u.Query() {
+					if len(vv) > 0 {
+						target[model.ParamLabelPrefix+k] = vv[0]
+					}
+				}
+
+			case "labels":
+				var kvs []struct {
+					Key   string `json:"key"`
+					Value struct {
+						Value string `json:"value"`
+					} `json:"value"`
+				}
+				if err := json.Unmarshal(attr.Value.Value, &kvs); err != nil {
+					return nil, fmt.Errorf("decoding target labels: %w", err)
+				}
+				for _, kv := range kvs {
+					// Labels derived from the URL take precedence.
+					if _, ok := target[kv.Key]; !ok {
+						target[kv.Key] = kv.Value.Value
+					}
+				}
+			}
+		}
+		res = append(res, target)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i][model.AddressLabel] < res[j][model.AddressLabel]
+	})
+	return res, nil
+}
+
+// riverJSONObject is a block or attribute in the JSON representation of a
+// River body.
+type riverJSONObject struct {
+	Name  string            `json:"name"`
+	Type  string            `json:"type"`
+	Body  []riverJSONObject `json:"body"`
+	Value struct {
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+	} `json:"value"`
+}
@@ -0,0 +1,127 @@
+package agentctl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/component/prometheus/scrape"
+	"github.com/grafana/agent/pkg/river/encoding"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrapeDebug(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/custom", r.URL.Path)
+		fmt.Fprintln(w, `# TYPE up_total counter`)
+		fmt.Fprintln(w, `up_total{instance="scraped"} 1`)
+		fmt.Fprintln(w, `go_goroutines 10`)
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	opts := DefaultScrapeDebugOptions
+	opts.Target = map[string]string{
+		model.AddressLabel: u.Host,
+		"__meta_path":      "/custom",
+	}
+	opts.RelabelConfigs = []*relabel.Config{{
+		SourceLabels: model.LabelNames{"__meta_path"},
+		Regex:        relabel.MustNewRegexp("(.*)"),
+		Separator:    ";",
+		TargetLabel:  model.MetricsPathLabel,
+		Replacement:  "$1",
+		Action:       relabel.Replace,
+	}}
+	opts.MetricRelabels = []*relabel.Config{{
+		SourceLabels: model.LabelNames{model.MetricNameLabel},
+		Regex:        relabel.MustNewRegexp("go_.*"),
+		Separator:    ";",
+		Action:       relabel.Drop,
+	}}
+
+	res, err := ScrapeDebug(context.Background(), opts)
+	require.NoError(t, err)
+	require.NoError(t, res.Err)
+	require.Equal(t, srv.URL+"/custom", res.URL)
+	require.Equal(t, u.Host, res.TargetLabels.Get(model.InstanceLabel))
+
+	require.Len(t, res.Samples, 2)
+	require.Equal(t, labels.FromStrings(
+		model.MetricNameLabel, "up_total",
+		"exported_instance", "scraped",
+		model.InstanceLabel, u.Host,
+		model.JobLabel, "debug",
+	), res.Samples[0].Labels)
+	require.True(t, res.Samples[1].Dropped())
+
+	var buf bytes.Buffer
+	require.NoError(t, res.Fprint(&buf, 0))
+	require.Contains(t, buf.String(), "2 scraped, 1 dropped by metric relabeling")
+}
+
+func TestScrapeDebug_Dropped(t *testing.T) {
+	opts := DefaultScrapeDebugOptions
+	opts.Target = map[string]string{model.AddressLabel: "localhost:1"}
+	opts.RelabelConfigs = []*relabel.Config{{
+		SourceLabels: model.LabelNames{model.AddressLabel},
+		Regex:        relabel.MustNewRegexp("localhost.*"),
+		Separator:    ";",
+		Action:       relabel.Drop,
+	}}
+
+	res, err := ScrapeDebug(context.Background(), opts)
+	require.NoError(t, err)
+	require.Nil(t, res.TargetLabels)
+	require.EqualError(t, res.Err, "target was dropped by relabeling")
+}
+
+func TestScrapeDebug_HTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	opts := DefaultScrapeDebugOptions
+	opts.Target = map[string]string{model.AddressLabel: strings.TrimPrefix(srv.URL, "http://")}
+
+	res, err := ScrapeDebug(context.Background(), opts)
+	require.NoError(t, err)
+	require.EqualError(t, res.Err, "server returned HTTP status 403 Forbidden")
+}
+
+func TestComponentTargets(t *testing.T) {
+	debugInfo, err := encoding.ConvertRiverBodyToJSON(scrape.ScraperStatus{
+		TargetStatus: []scrape.TargetStatus{{
+			JobName:    "prometheus.scrape.default",
+			URL:        "https://example.com:9090/federate?match=up",
+			Health:     "up",
+			Labels:     map[string]string{"job": "prometheus.scrape.default", "instance": "example.com:9090"},
+			LastScrape: time.Now(),
+		}},
+	})
+	require.NoError(t, err)
+
+	info := fmt.Sprintf(`{"name": "prometheus.scrape", "id": "prometheus.scrape.default", "debugInfo": %s}`, debugInfo)
+	targets, err := ComponentTargets(strings.NewReader(info))
+	require.NoError(t, err)
+	require.Equal(t, []map[string]string{{
+		model.AddressLabel:               "example.com:9090",
+		model.SchemeLabel:                "https",
+		model.MetricsPathLabel:           "/federate",
+		model.ParamLabelPrefix + "match": "up",
+		"job":                            "prometheus.scrape.default",
+		"instance":                       "example.com:9090",
+	}}, targets)
+}
@@ -0,0 +1,66 @@
+// Package agentid generates and persists a unique identifier for an agent.
+//
+// The identifier is a random UUID generated the first time the agent starts,
+// which allows individual agents to be tracked across hostname changes.
+package agentid
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Filename is the name of the file the agent ID is persisted to.
+	Filename = "agent_id"
+	// HeaderName is the HTTP header used to send the agent ID.
+	HeaderName = "X-Agent-Id"
+	// LabelName is the label name used to send the agent ID.
+	LabelName = "agent_id"
+)
+
+// LoadOrCreate returns the agent ID persisted in dir. If dir doesn't contain
+// an agent ID, or the persisted agent ID is invalid, a new agent ID is
+// generated and persisted.
+func LoadOrCreate(dir string) (string, error) {
+	path := filepath.Join(dir, Filename)
+
+	bb, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		id := strings.TrimSpace(string(bb))
+		if _, err := uuid.Parse(id); err == nil {
+			return id, nil
+		}
+		// Invalid ID; fall through to generate a new one.
+	case !errors.Is(err, os.ErrNotExist):
+		return "", fmt.Errorf("reading agent ID: %w", err)
+	}
+
+	id := uuid.NewString()
+	if err := write(dir, path, id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// write atomically writes id to path.
+func write(dir, path, id string) error {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("creating agent ID directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(id+"\n"), 0640); err != nil {
+		return fmt.Errorf("writing agent ID: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("writing agent ID: %w", err)
+	}
+	return nil
+}
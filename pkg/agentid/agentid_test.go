@@ -0,0 +1,37 @@
+package agentid
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadOrCreate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+
+	id, err := LoadOrCreate(dir)
+	require.NoError(t, err)
+	_, err = uuid.Parse(id)
+	require.NoError(t, err)
+
+	// The ID should be stable across calls.
+	again, err := LoadOrCreate(dir)
+	require.NoError(t, err)
+	require.Equal(t, id, again)
+}
+
+func TestLoadOrCreate_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, Filename), []byte("not-a-uuid"), 0640))
+
+	id, err := LoadOrCreate(dir)
+	require.NoError(t, err)
+	require.NotEqual(t, "not-a-uuid", id)
+
+	bb, err := os.ReadFile(filepath.Join(dir, Filename))
+	require.NoError(t, err)
+	require.Equal(t, id+"\n", string(bb))
+}
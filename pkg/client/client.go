@@ -9,7 +9,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
 	"strings"
+	"time"
 
 	"github.com/grafana/agent/pkg/metrics/cluster/configapi"
 	"github.com/grafana/agent/pkg/metrics/instance"
@@ -19,12 +21,14 @@ import (
 // Client is a collection of all subsystem clients.
 type Client struct {
 	PrometheusClient
+	AgentManagementClient
 }
 
 // New creates a new Client.
 func New(addr string) *Client {
 	return &Client{
-		PrometheusClient: &prometheusClient{addr: addr},
+		PrometheusClient:      &prometheusClient{addr: addr},
+		AgentManagementClient: &agentManagementClient{addr: addr},
 	}
 }
 
@@ -177,3 +181,92 @@ func unmarshalPrometheusAPIResponse(r io.ReadCloser, v interface{}) error {
 
 	return nil
 }
+
+// AgentManagementClient is the client interface to the API for the remote
+// config of an Agent using Agent Management.
+type AgentManagementClient interface {
+	// RemoteConfigVersions lists the versions of the remote config kept in the
+	// Agent's cache history, newest version first.
+	RemoteConfigVersions(ctx context.Context) ([]RemoteConfigVersion, error)
+
+	// RollbackRemoteConfig rolls the remote config of the Agent back to a
+	// cached version and reloads its config.
+	RollbackRemoteConfig(ctx context.Context, version string) error
+
+	// RefreshRemoteConfig requests the Agent to fetch its remote config from
+	// the Agent Management API without waiting for the polling interval.
+	RefreshRemoteConfig(ctx context.Context) error
+}
+
+// RemoteConfigVersion describes a version of the remote config kept in the
+// Agent's cache history, as returned by RemoteConfigVersions.
+type RemoteConfigVersion struct {
+	// Version identifies the version when rolling back to it.
+	Version  string    `json:"version"`
+	CachedAt time.Time `json:"cached_at"`
+	// ConfigHash is the SHA-256 hash of the raw remote config.
+	ConfigHash string `json:"config_hash"`
+	// Current is true for the version which is currently cached.
+	Current bool `json:"current"`
+}
+
+type agentManagementClient struct {
+	addr string
+}
+
+func (c *agentManagementClient) RemoteConfigVersions(ctx context.Context) ([]RemoteConfigVersion, error) {
+	url := fmt.Sprintf("%s/-/remote-config/versions", c.addr)
+
+	resp, err := c.doRequest(ctx, "GET", url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var data []RemoteConfigVersion
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("could not read response: %w", err)
+	}
+	return data, nil
+}
+
+func (c *agentManagementClient) RollbackRemoteConfig(ctx context.Context, version string) error {
+	url := fmt.Sprintf("%s/-/remote-config/rollback?version=%s", c.addr, neturl.QueryEscape(version))
+
+	resp, err := c.doRequest(ctx, "POST", url)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+func (c *agentManagementClient) RefreshRemoteConfig(ctx context.Context) error {
+	url := fmt.Sprintf("%s/-/remote-config/refresh", c.addr)
+
+	resp, err := c.doRequest(ctx, "POST", url)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// doRequest sends a request to the Agent. Responses with a status code other
+// than 200 are returned as errors.
+func (c *agentManagementClient) doRequest(ctx context.Context, method string, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
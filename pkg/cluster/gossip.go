@@ -7,6 +7,8 @@ import (
 	stdlog "log"
 	"net"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/go-kit/log"
 	"github.com/grafana/dskit/flagext"
@@ -101,7 +103,7 @@ func (c *GossipConfig) ApplyDefaults(defaultPort int) error {
 		if err != nil {
 			return fmt.Errorf("determining advertise address: %w", err)
 		}
-		c.AdvertiseAddr = fmt.Sprintf("%s:%d", addr.String(), defaultPort)
+		c.AdvertiseAddr = net.JoinHostPort(addr.String(), strconv.Itoa(defaultPort))
 	} else {
 		c.AdvertiseAddr = appendDefaultPort(c.AdvertiseAddr, defaultPort)
 	}
@@ -149,7 +151,10 @@ func appendDefaultPort(addr string, port int) string {
 		// No error means there was a port in the string
 		return addr
 	}
-	return fmt.Sprintf("%s:%d", addr, port)
+	// Remove brackets from IPv6 literals without a port, such as "[::1]", so
+	// they're not added twice.
+	addr = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+	return net.JoinHostPort(addr, strconv.Itoa(port))
 }
 
 // GossipNode is a Node which uses gRPC and gossip to discover peers.
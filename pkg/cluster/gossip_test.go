@@ -5,6 +5,7 @@ import (
 	stdlog "log"
 	"net"
 	"os"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -74,7 +75,7 @@ func TestConfig_ApplyDefaults(t *testing.T) {
 
 		expect, err := advertise.FirstAddress(gc.AdvertiseInterfaces)
 		require.NoError(t, err)
-		require.Equal(t, fmt.Sprintf("%s:%d", expect, examplePort), gc.AdvertiseAddr)
+		require.Equal(t, net.JoinHostPort(expect.String(), strconv.Itoa(examplePort)), gc.AdvertiseAddr)
 	})
 
 	t.Run("explicit advertise address can be set", func(t *testing.T) {
@@ -95,6 +96,17 @@ func TestConfig_ApplyDefaults(t *testing.T) {
 		require.Equal(t, fmt.Sprintf("foobar:%d", examplePort), gc.AdvertiseAddr)
 	})
 
+	t.Run("explicit advertise address can be an IPv6 address", func(t *testing.T) {
+		for _, addr := range []string{"::1", "[::1]"} {
+			gc := defaultConfig
+			gc.AdvertiseAddr = addr
+
+			err := gc.ApplyDefaults(examplePort)
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprintf("[::1]:%d", examplePort), gc.AdvertiseAddr)
+		}
+	})
+
 	t.Run("join peers and discover peers can't both be set", func(t *testing.T) {
 		gc := defaultConfig
 		gc.JoinPeers = []string{"foobar:9999"}
@@ -11,9 +11,11 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/pkg/agentid"
 	"github.com/grafana/agent/pkg/config/instrumentation"
 	"github.com/grafana/agent/pkg/server"
 	"github.com/prometheus/common/config"
@@ -22,14 +24,62 @@ import (
 
 const cacheFilename = "remote-config-cache.yaml"
 
+// ErrRemoteConfigNotModified is returned when loading the config from the
+// Agent Management API if the remote config hasn't changed since it was last
+// loaded. The currently running config can be kept as-is.
+var ErrRemoteConfigNotModified = errors.New("remote config not modified since it was last loaded")
+
+// loadedRemoteConfig identifies the remote config which was last loaded by
+// the hashes of the initial config and the raw remote config. It's used to
+// skip reloads when neither changed, which conditional requests can't detect
+// for every protocol or when the cached remote config is used.
+type loadedRemoteConfig struct {
+	initialConfigHash string
+	configHash        string
+}
+
+// setLoadedRemoteConfig records the remote config identified by
+// initialConfigHash and configHash as loaded. It returns false if the same
+// remote config was already loaded last.
+func (p *RemoteConfigPoller) setLoadedRemoteConfig(initialConfigHash, configHash string) bool {
+	if p == nil {
+		return true
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	loaded := loadedRemoteConfig{initialConfigHash: initialConfigHash, configHash: configHash}
+	if p.loaded == loaded {
+		return false
+	}
+	p.loaded = loaded
+	return true
+}
+
 type remoteConfigProvider interface {
 	GetCachedRemoteConfig() ([]byte, error)
 	CacheRemoteConfig(remoteConfigBytes []byte) error
+	// RestoreCachedRemoteConfig restores previousConfig as the cached remote
+	// config after the remote config which replaced it failed to apply.
+	RestoreCachedRemoteConfig(previousConfig []byte) error
+	// RolledBackConfigHash returns the hash of the remote config which was
+	// rolled back from, if the cached remote config was rolled back.
+	RolledBackConfigHash() string
+	// StartCanary records that the cached remote config with the given hash
+	// is a canary which replaced previousConfig.
+	StartCanary(previousConfig []byte, configHash string, settings CanaryConfig) error
 	FetchRemoteConfig() ([]byte, error)
+	ReportStatus(status remoteConfigStatus) error
 }
 
 type remoteConfigHTTPProvider struct {
 	InitialConfig *AgentManagementConfig
+	// AgentID uniquely identifies this agent to the Agent Management API.
+	AgentID string
+
+	// poller holds the cache validators for conditional requests. Requests
+	// are unconditional if it's nil.
+	poller *RemoteConfigPoller
 }
 
 func newRemoteConfigHTTPProvider(c *Config) (*remoteConfigHTTPProvider, error) {
@@ -37,8 +87,22 @@ func newRemoteConfigHTTPProvider(c *Config) (*remoteConfigHTTPProvider, error) {
 	if err != nil {
 		return nil, err
 	}
+	agentID, err := agentid.LoadOrCreate(c.AgentManagement.CacheLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	// Requests are made with the rendered label values, so that they describe
+	// the node the agent is running on.
+	initialConfig := c.AgentManagement
+	initialConfig.RemoteConfiguration.Labels, err = c.AgentManagement.RemoteConfiguration.Labels.render()
+	if err != nil {
+		return nil, err
+	}
+
 	return &remoteConfigHTTPProvider{
-		InitialConfig: &c.AgentManagement,
+		InitialConfig: &initialConfig,
+		AgentID:       agentID,
 	}, nil
 }
 
@@ -95,9 +159,9 @@ func (r remoteConfigHTTPProvider) GetCachedRemoteConfig() ([]byte, error) {
 }
 
 // CacheRemoteConfig caches the remote config to the location specified in
-// r.AgentManagement.CacheLocation
+// r.AgentManagement.CacheLocation. The remote config is also added to the
+// cache history, and any previous rollback is cleared.
 func (r remoteConfigHTTPProvider) CacheRemoteConfig(remoteConfigBytes []byte) error {
-	cachePath := filepath.Join(r.InitialConfig.CacheLocation, cacheFilename)
 	initialConfigHash, err := hashInitialConfig(*r.InitialConfig)
 	if err != nil {
 		return err
@@ -106,6 +170,40 @@ func (r remoteConfigHTTPProvider) CacheRemoteConfig(remoteConfigBytes []byte) er
 		InitialConfigHash: initialConfigHash,
 		Config:            string(remoteConfigBytes),
 	}
+	if err := r.writeCache(configCache); err != nil {
+		return err
+	}
+	if err := writeRemoteConfigHistory(r.InitialConfig.CacheLocation, configCache, r.InitialConfig.cacheHistory(), time.Now()); err != nil {
+		return err
+	}
+	return r.clearRollback()
+}
+
+// RestoreCachedRemoteConfig restores previousConfig as the cached remote
+// config after the remote config which replaced it failed to apply. The cache
+// is removed if previousConfig is nil. The cache history is left as-is.
+func (r remoteConfigHTTPProvider) RestoreCachedRemoteConfig(previousConfig []byte) error {
+	if previousConfig == nil {
+		err := os.Remove(filepath.Join(r.InitialConfig.CacheLocation, cacheFilename))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not remove remote config cache: %w", err)
+		}
+		return nil
+	}
+
+	initialConfigHash, err := hashInitialConfig(*r.InitialConfig)
+	if err != nil {
+		return err
+	}
+	return r.writeCache(remoteConfigCache{
+		InitialConfigHash: initialConfigHash,
+		Config:            string(previousConfig),
+	})
+}
+
+// writeCache writes configCache as the cached remote config.
+func (r remoteConfigHTTPProvider) writeCache(configCache remoteConfigCache) error {
+	cachePath := filepath.Join(r.InitialConfig.CacheLocation, cacheFilename)
 	marshalled, err := json.Marshal(configCache)
 	if err != nil {
 		return fmt.Errorf("could not marshal remote config cache: %w", err)
@@ -116,33 +214,119 @@ func (r remoteConfigHTTPProvider) CacheRemoteConfig(remoteConfigBytes []byte) er
 // FetchRemoteConfig fetches the raw bytes of the config from a remote API using
 // the values in r.AgentManagement.
 //
-// Sleeps for a short period of time to apply jitter to API requests.
+// The request is conditional on the remote config having changed since it was
+// last loaded. ErrRemoteConfigNotModified is returned if it hasn't.
 func (r remoteConfigHTTPProvider) FetchRemoteConfig() ([]byte, error) {
-	httpClientConfig := &config.HTTPClientConfig{
-		BasicAuth: &r.InitialConfig.BasicAuth,
+	bb, contentType, err := r.fetch(contentTypeYAML)
+	if err != nil {
+		return nil, err
+	}
+	if contentType == ContentTypeRiver {
+		// Forget the validators so that the config is fetched again rather
+		// than reported as unchanged.
+		r.poller.setRemoteConfigValidators("", cacheValidators{})
+		return nil, errors.New("remote config is a River config, which can only be loaded in Flow mode")
 	}
+	return bb, nil
+}
 
-	dir, err := os.Getwd()
+// fetch fetches the raw bytes of the config, asking the API for a config in
+// the format of the accept media type. The media type of the response is
+// returned along with the config.
+func (r remoteConfigHTTPProvider) fetch(accept string) ([]byte, string, error) {
+	initialConfigHash, err := hashInitialConfig(*r.InitialConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get current working directory: %w", err)
+		return nil, "", err
 	}
-	httpClientConfig.SetDirectory(dir)
+	validators := r.poller.remoteConfigValidators(initialConfigHash)
 
+	var contentType string
 	remoteOpts := &remoteOpts{
-		HTTPClientConfig: httpClientConfig,
+		AcceptEncodings: r.InitialConfig.acceptEncodings(),
+		Validators:      &validators,
+		Accept:          accept,
+		ContentType:     &contentType,
+	}
+	remoteOpts.Headers = remoteConfigHeaders()
+	if r.AgentID != "" {
+		remoteOpts.Headers[agentid.HeaderName] = r.AgentID
+	}
+
+	if sv := r.InitialConfig.SignatureVerification; sv != nil {
+		remoteOpts.Verify, err = sv.verifier()
+		if err != nil {
+			return nil, "", fmt.Errorf("error loading remote config signature verification key: %w", err)
+		}
+	}
+
+	// Try the API URLs in order until one of them succeeds.
+	var failures []string
+	for _, baseURL := range r.InitialConfig.Url {
+		bb, err := r.fetchRemoteConfigFrom(baseURL, remoteOpts)
+		if errors.Is(err, ErrRemoteConfigNotModified) {
+			return nil, "", err
+		} else if err != nil {
+			if len(r.InitialConfig.Url) == 1 {
+				return nil, "", err
+			}
+			failures = append(failures, fmt.Sprintf("%s: %s", baseURL, err))
+			continue
+		}
+		r.poller.setRemoteConfigValidators(initialConfigHash, validators)
+		return bb, contentType, nil
+	}
+	return nil, "", fmt.Errorf("could not fetch remote config from any API URL: %s", strings.Join(failures, "; "))
+}
+
+// fetchRemoteConfigFrom reads the raw bytes of the config from the API at
+// baseURL.
+func (r remoteConfigHTTPProvider) fetchRemoteConfigFrom(baseURL string, remoteOpts *remoteOpts) ([]byte, error) {
+	url, err := r.InitialConfig.fullUrlFor(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("error trying to create full url: %w", err)
+	}
+	if r.AgentID != "" && r.InitialConfig.RemoteConfiguration.AgentIDAsLabel {
+		url, err = addQueryParam(url, agentid.LabelName, r.AgentID)
+		if err != nil {
+			return nil, fmt.Errorf("error trying to create full url: %w", err)
+		}
 	}
 
-	url, err := r.InitialConfig.fullUrl()
+	url, err = r.poller.addClusterParams(r.InitialConfig, url)
 	if err != nil {
 		return nil, fmt.Errorf("error trying to create full url: %w", err)
 	}
-	rc, err := newRemoteProvider(url, remoteOpts)
+
+	// The HTTP client config depends on the URL, since requests to some hosts
+	// may bypass the proxy.
+	httpClientConfig, err := r.InitialConfig.httpClientConfigFor(r.poller, url)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	httpClientConfig.SetDirectory(dir)
+	opts := *remoteOpts
+	opts.HTTPClientConfig = httpClientConfig
+
+	rc, err := newRemoteProvider(url, &opts)
 	if err != nil {
 		return nil, fmt.Errorf("error reading remote config: %w", err)
 	}
 
 	bb, err := rc.retrieve()
-	if err != nil {
+	if errors.Is(err, errNotModified) {
+		return nil, ErrRemoteConfigNotModified
+	} else if errors.Is(err, errSchemaNotAcceptable) {
+		// The API is too new for this agent. Fall back to the older API
+		// pinned in the initial config, if any.
+		if fallback := r.InitialConfig.FallbackApiUrl; fallback != "" && fallback != baseURL {
+			return r.fetchRemoteConfigFrom(fallback, remoteOpts)
+		}
+		return nil, schemaNotAcceptableError(baseURL)
+	} else if err != nil {
 		return nil, fmt.Errorf("error retrieving remote config: %w", err)
 	}
 	return bb, nil
@@ -151,67 +335,337 @@ func (r remoteConfigHTTPProvider) FetchRemoteConfig() ([]byte, error) {
 type labelMap map[string]string
 
 type RemoteConfiguration struct {
+	// Labels select the remote config of the agent. Label values may be
+	// templates, which are rendered using values from the environment, such as
+	// environment variables or cloud instance metadata.
 	Labels    labelMap `yaml:"labels"`
 	Namespace string   `yaml:"namespace"`
+	// AgentIDAsLabel sends the agent ID as the agent_id label alongside Labels.
+	AgentIDAsLabel bool `yaml:"agent_id_as_label,omitempty"`
+	// ClusterName is sent along with the shard of the agent when the agent is
+	// clustered, so that the API can tell clusters sharing a namespace apart.
+	ClusterName string `yaml:"cluster_name,omitempty"`
+	// PartialApply applies the valid scrape configs of a remote config whose
+	// snippets contain invalid scrape configs instead of rejecting it as a
+	// whole. The base config must still be valid.
+	PartialApply bool `yaml:"partial_apply,omitempty"`
+}
+
+// apiURLs are the URLs of the Agent Management API. Remote configs are
+// fetched from the first URL, and the other URLs are tried in order when
+// fetching fails. In YAML, it's written as a single URL or a list of URLs.
+type apiURLs []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (u *apiURLs) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*u = apiURLs{single}
+		return nil
+	}
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*u = list
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler. A single URL is marshaled as a
+// string, so that the initial config hashes the same as before lists were
+// supported.
+func (u apiURLs) MarshalYAML() (interface{}, error) {
+	if len(u) <= 1 {
+		return u.primary(), nil
+	}
+	return []string(u), nil
+}
+
+// primary returns the first URL, or an empty string if there are none.
+func (u apiURLs) primary() string {
+	if len(u) == 0 {
+		return ""
+	}
+	return u[0]
 }
 
 type AgentManagementConfig struct {
 	Enabled         bool             `yaml:"-"` // Derived from enable-features=agent-management
-	Url             string           `yaml:"api_url"`
+	Url             apiURLs          `yaml:"api_url"`
 	BasicAuth       config.BasicAuth `yaml:"basic_auth"`
 	Protocol        string           `yaml:"protocol"`
 	PollingInterval time.Duration    `yaml:"polling_interval"`
 	CacheLocation   string           `yaml:"remote_config_cache_location"`
+	AcceptEncoding  []string         `yaml:"accept_encoding,omitempty"`
+
+	// NamespaceBasicAuth holds the credentials to fetch remote configs with
+	// for specific namespaces, keyed by namespace. The credentials for
+	// RemoteConfiguration.Namespace are used instead of BasicAuth, so that an
+	// API with distinct credentials for every namespace can be used with a
+	// shared initial config.
+	NamespaceBasicAuth map[string]config.BasicAuth `yaml:"namespace_basic_auth,omitempty"`
+
+	// HTTPClientConfig configures the HTTP client used to fetch remote
+	// configs, allowing authentication methods other than BasicAuth, such as
+	// OAuth2 or bearer tokens. It can't be used together with BasicAuth.
+	HTTPClientConfig *config.HTTPClientConfig `yaml:"http_client_config,omitempty"`
+
+	// TLSConfig configures TLS for requests to the API, such as client
+	// certificates for APIs requiring mutual TLS. Unlike HTTPClientConfig, it
+	// can be used together with BasicAuth. A client certificate replaces
+	// BasicAuth as the required authentication method.
+	TLSConfig *config.TLSConfig `yaml:"tls_config,omitempty"`
+
+	// ProxyURL is the URL of the proxy to send requests to the API through.
+	// NoProxy is a comma-separated list of hosts which are reached directly
+	// instead, in the format of the NO_PROXY environment variable.
+	ProxyURL config.URL `yaml:"proxy_url,omitempty"`
+	NoProxy  string     `yaml:"no_proxy,omitempty"`
+
+	// FallbackApiUrl is the URL of an older Agent Management API to fetch
+	// remote configs from when the API at Url rejects the config schema
+	// version of this agent with 406 Not Acceptable. It allows agents which
+	// lag behind the rest of the fleet to keep receiving remote configs they
+	// can parse.
+	FallbackApiUrl string `yaml:"fallback_api_url,omitempty"`
+
+	// PollingJitter is the upper bound of a random delay added to every
+	// polling interval.
+	PollingJitter time.Duration `yaml:"polling_jitter,omitempty"`
+	// PollingMaxBackoff is the longest time to wait in between config fetches
+	// when fetching the remote config fails repeatedly. Backoff is disabled
+	// when unset.
+	PollingMaxBackoff time.Duration `yaml:"polling_max_backoff,omitempty"`
+
+	// CircuitBreakerThreshold is the number of consecutive failed fetches
+	// after which the circuit breaker opens. While it's open, fetching is only
+	// retried every CircuitBreakerInterval, until a fetch succeeds. The
+	// circuit breaker is disabled when unset.
+	CircuitBreakerThreshold int           `yaml:"circuit_breaker_threshold,omitempty"`
+	CircuitBreakerInterval  time.Duration `yaml:"circuit_breaker_interval,omitempty"`
+
+	// CacheHistory is the number of previously cached remote configs to keep
+	// for rolling back to. Defaults to 5 if unset.
+	CacheHistory int `yaml:"remote_config_cache_history,omitempty"`
+
+	// LocalOverridesFile is the path of a YAML file which is deep-merged over
+	// the base config of the remote config before it's validated, so that
+	// host-specific values unknown to the API can be pinned locally.
+	LocalOverridesFile string `yaml:"local_overrides_file,omitempty"`
+
+	// TemplateVariables are substituted for references of the form ${name} in
+	// remote configs before they're loaded, so that one remote config can
+	// serve many agents with per-host values defined in their initial config.
+	TemplateVariables map[string]string `yaml:"template_variables,omitempty"`
+
+	// StatusUrl, if set, is sent the hash of the applied remote config along
+	// with the agent ID, version, and any error after every attempt to load
+	// and apply the remote config.
+	StatusUrl string `yaml:"status_url,omitempty"`
+
+	// RegistrationUrl, if set, is sent the identity of the agent when it
+	// starts and then every HeartbeatInterval, which defaults to the polling
+	// interval.
+	RegistrationUrl   string        `yaml:"registration_url,omitempty"`
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval,omitempty"`
+
+	// Git configures which revision and path of the repository remote configs
+	// are read from when Protocol is git.
+	Git *GitConfig `yaml:"git,omitempty"`
+
+	// SignatureVerification, if set, requires remote configs to carry a valid
+	// detached signature before they're applied or cached.
+	SignatureVerification *SignatureVerificationConfig `yaml:"signature_verification,omitempty"`
+
+	// Vault, if set, reads the basic_auth password and secrets referenced by
+	// remote configs from HashiCorp Vault.
+	Vault *VaultConfig `yaml:"vault,omitempty"`
 
 	RemoteConfiguration RemoteConfiguration `yaml:"remote_configuration"`
 }
 
+// remoteConfigOptions configures how the remote config is fetched and loaded.
+type remoteConfigOptions struct {
+	ExpandEnvVars bool
+	// EnvAllowlist limits the environment variables which are expanded; see
+	// envAllowed.
+	EnvAllowlist []string
+	// PartialApply skips invalid scrape configs in snippets rather than
+	// rejecting the whole remote config.
+	PartialApply bool
+	// TemplateVariables are substituted in the remote config before it's
+	// loaded.
+	TemplateVariables map[string]string
+	// LocalOverrides are merged over the base config of the remote config.
+	LocalOverrides []byte
+	// Poller counts the failures to fetch the remote config.
+	Poller *RemoteConfigPoller
+
+	Provider   remoteConfigProvider
+	Log        *server.Logger
+	Flags      *flag.FlagSet
+	Args       []string
+	ConfigPath string
+}
+
 // getRemoteConfig gets the remote config specified in the initial config, falling back to a local, cached copy
 // of the remote config if the request to the remote fails. If both fail, an empty config and an
-// error will be returned.
-func getRemoteConfig(expandEnvVars bool, configProvider remoteConfigProvider, log *server.Logger, fs *flag.FlagSet, args []string, configPath string) (*Config, error) {
+// error will be returned. If the remote config hasn't changed since it was last loaded,
+// ErrRemoteConfigNotModified is returned. The hash of the raw remote config is
+// returned along with the loaded config.
+//
+// The loaded remote config is pending on opts.Poller until the outcome of
+// applying it is recorded by RemoteConfigPoller.RemoteConfigApplied, which
+// reports it through opts.Provider.ReportStatus. Failures to load any remote
+// config are reported right away.
+func getRemoteConfig(opts remoteConfigOptions) (*Config, string, error) {
+	configProvider, log := opts.Provider, opts.Log
+	opts.Poller.setPendingRemoteConfig(nil)
 	remoteConfigBytes, err := configProvider.FetchRemoteConfig()
-	if err != nil {
+	opts.Poller.recordFetchTime(time.Now())
+	opts.Poller.recordFetch(err)
+	if errors.Is(err, ErrRemoteConfigNotModified) {
+		level.Debug(log).Log("msg", "remote config has not changed since it was last loaded")
+		return nil, "", err
+	} else if err != nil {
 		level.Error(log).Log("msg", "could not fetch from API, falling back to cache", "err", err)
-		return getCachedRemoteConfig(expandEnvVars, configProvider, fs, args, configPath)
+		return getCachedRemoteConfig(opts, err)
+	}
+
+	// Keep loading the cached remote config after a rollback until the API
+	// serves a remote config other than the one which was rolled back from.
+	if h := configProvider.RolledBackConfigHash(); h != "" && h == hashRemoteConfig(remoteConfigBytes) {
+		level.Info(log).Log("msg", "remote config was rolled back, loading the cached config", "hash", h)
+		return getCachedRemoteConfig(opts, errRemoteConfigRolledBack)
 	}
 
-	config, err := loadRemoteConfig(remoteConfigBytes, expandEnvVars, fs, args, configPath)
+	config, skipped, err := loadRemoteConfig(remoteConfigBytes, opts)
 	if err != nil {
+		// Forget the validators of the invalid config so that it isn't reported
+		// as unchanged by the next fetch.
+		opts.Poller.setRemoteConfigValidators("", cacheValidators{})
 		level.Error(log).Log("msg", "could not load remote config, falling back to cache", "err", err)
-		return getCachedRemoteConfig(expandEnvVars, configProvider, fs, args, configPath)
+		return getCachedRemoteConfig(opts, err)
 	}
+	logSkippedScrapeConfigs(log, skipped)
 
 	level.Info(log).Log("msg", "fetched and loaded remote config from API")
 
+	// The previous remote config is restored if the remote config fails to
+	// apply or is a canary which fails, so it has to be read before it's
+	// replaced in the cache.
+	previousConfig, cacheErr := configProvider.GetCachedRemoteConfig()
+	if cacheErr != nil {
+		previousConfig = nil
+	}
+	canary := remoteConfigCanarySettings(remoteConfigBytes)
+	if canary != nil && cacheErr != nil {
+		level.Warn(log).Log("msg", "remote config is a canary, but there's no cached config to restore if it fails", "err", cacheErr)
+		canary = nil
+	}
+
+	pending := newPendingRemoteConfig(opts)
 	if err = configProvider.CacheRemoteConfig(remoteConfigBytes); err != nil {
 		level.Error(log).Log("err", fmt.Errorf("could not cache config locally: %w", err))
+	} else {
+		pending.restoreCache = func() error { return configProvider.RestoreCachedRemoteConfig(previousConfig) }
+	}
+	configHash := hashRemoteConfig(remoteConfigBytes)
+	pending.status = remoteConfigStatus{
+		ConfigHash: configHash,
+		Source:     remoteConfigSourceRemote,
+		Skipped:    skipped,
+	}
+	if canary != nil && hashRemoteConfig(previousConfig) != configHash {
+		if err := configProvider.StartCanary(previousConfig, configHash, *canary); err != nil {
+			level.Error(log).Log("msg", "could not start canary, keeping the remote config", "err", err)
+		} else {
+			level.Info(log).Log("msg", "remote config is a canary, watching agent health before keeping it", "hash", configHash, "ttl", canary.TTL)
+			pending.status.Canary = remoteConfigCanaryStarted
+		}
+	}
+	opts.Poller.setPendingRemoteConfig(pending)
+	return config, configHash, nil
+}
+
+// newPendingRemoteConfig returns a pending remote config whose status is
+// reported through opts.Provider.
+func newPendingRemoteConfig(opts remoteConfigOptions) *pendingRemoteConfig {
+	return &pendingRemoteConfig{
+		report: func(status remoteConfigStatus) {
+			reportRemoteConfigStatus(opts.Poller, opts.Provider, opts.Log, status)
+		},
 	}
-	return config, nil
 }
 
-func getCachedRemoteConfig(expandEnvVars bool, configProvider remoteConfigProvider, fs *flag.FlagSet, args []string, configPath string) (*Config, error) {
+// getCachedRemoteConfig loads the cached remote config after the remote
+// config couldn't be fetched or loaded because of remoteErr.
+func getCachedRemoteConfig(opts remoteConfigOptions, remoteErr error) (*Config, string, error) {
+	configProvider, log := opts.Provider, opts.Log
+	pending := newPendingRemoteConfig(opts)
+
 	rc, err := configProvider.GetCachedRemoteConfig()
 	if err != nil {
-		return nil, fmt.Errorf("could not load cached config: %w", err)
+		pending.report(remoteConfigStatus{Error: fmt.Sprintf("%s; could not load cached config: %s", remoteErr, err)})
+		return nil, "", fmt.Errorf("could not load cached config: %w", err)
 	}
-	return loadRemoteConfig(rc, expandEnvVars, fs, args, configPath)
+	config, skipped, err := loadRemoteConfig(rc, opts)
+	if err != nil {
+		pending.report(remoteConfigStatus{Error: fmt.Sprintf("%s; could not load cached config: %s", remoteErr, err)})
+		return nil, "", err
+	}
+	logSkippedScrapeConfigs(log, skipped)
+	pending.status = remoteConfigStatus{
+		ConfigHash: hashRemoteConfig(rc),
+		Source:     remoteConfigSourceCache,
+		Error:      remoteErr.Error(),
+		Skipped:    skipped,
+	}
+	opts.Poller.setPendingRemoteConfig(pending)
+	return config, pending.status.ConfigHash, nil
 }
 
 // loadRemoteConfig parses and validates the remote config, both syntactically and semantically.
-func loadRemoteConfig(remoteConfigBytes []byte, expandEnvVars bool, fs *flag.FlagSet, args []string, configPath string) (*Config, error) {
-	expandedRemoteConfigBytes, err := performEnvVarExpansion(remoteConfigBytes, expandEnvVars)
+//
+// If opts.PartialApply is set and the remote config is invalid, the valid
+// subset of its scrape configs is loaded instead, and the skipped scrape
+// configs are described in the returned list.
+func loadRemoteConfig(remoteConfigBytes []byte, opts remoteConfigOptions) (*Config, []string, error) {
+	// Template variables are substituted before environment variables, which
+	// take care of the remaining references.
+	remoteConfigBytes = substituteTemplateVariables(remoteConfigBytes, opts.TemplateVariables)
+	expandedRemoteConfigBytes, err := performEnvVarExpansion(remoteConfigBytes, opts.ExpandEnvVars, opts.EnvAllowlist)
 	if err != nil {
 		instrumentation.InstrumentInvalidRemoteConfig("env_var_expansion")
-		return nil, fmt.Errorf("could not expand env vars for remote config: %w", err)
+		return nil, nil, fmt.Errorf("could not expand env vars for remote config: %w", err)
 	}
 
 	remoteConfig, err := NewRemoteConfig(expandedRemoteConfigBytes)
 	if err != nil {
 		instrumentation.InstrumentInvalidRemoteConfig("invalid_yaml")
-		return nil, fmt.Errorf("could not unmarshal remote config: %w", err)
+		return nil, nil, fmt.Errorf("could not unmarshal remote config: %w", err)
+	}
+	remoteConfig.BaseConfig, err = applyLocalOverrides(remoteConfig.BaseConfig, opts.LocalOverrides)
+	if err != nil {
+		instrumentation.InstrumentInvalidRemoteConfig("invalid_local_overrides")
+		return nil, nil, err
 	}
 
+	config, err := buildRemoteConfig(remoteConfig, opts.Flags, opts.Args, opts.ConfigPath)
+	if err != nil && opts.PartialApply {
+		var skipped []string
+		config, skipped, err = remoteConfig.buildPartialAgentConfig(opts.Flags, opts.Args, opts.ConfigPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not build agent config: %w", err)
+		}
+		instrumentation.InstrumentInvalidRemoteConfig("partially_applied")
+		return config, skipped, nil
+	}
+	return config, nil, err
+}
+
+// buildRemoteConfig builds the agent config from remoteConfig and validates it.
+func buildRemoteConfig(remoteConfig *RemoteConfig, fs *flag.FlagSet, args []string, configPath string) (*Config, error) {
 	config, err := remoteConfig.BuildAgentConfig()
 	if err != nil {
 		instrumentation.InstrumentInvalidRemoteConfig("invalid_remote_config")
@@ -230,12 +684,35 @@ func loadRemoteConfig(remoteConfigBytes []byte, expandEnvVars bool, fs *flag.Fla
 	return config, nil
 }
 
+// logSkippedScrapeConfigs logs the scrape configs which were skipped when
+// partially applying a remote config.
+func logSkippedScrapeConfigs(log *server.Logger, skipped []string) {
+	for _, reason := range skipped {
+		level.Warn(log).Log("msg", "skipped invalid part of remote config", "reason", reason)
+	}
+}
+
 // newRemoteConfigProvider creates a remoteConfigProvider based on the protocol
-// specified in c.AgentManagement
-func newRemoteConfigProvider(c *Config) (*remoteConfigHTTPProvider, error) {
+// specified in c.AgentManagement. The cache validators of poller are used to
+// detect unchanged remote configs.
+func newRemoteConfigProvider(c *Config, poller *RemoteConfigPoller) (remoteConfigProvider, error) {
 	switch p := c.AgentManagement.Protocol; {
 	case p == "http":
-		return newRemoteConfigHTTPProvider(c)
+		r, err := newRemoteConfigHTTPProvider(c)
+		if err != nil {
+			return nil, err
+		}
+		r.poller = poller
+		return r, nil
+	case isObjectStorageProtocol(p):
+		return newRemoteConfigObjectStorageProvider(c)
+	case p == protocolGit:
+		r, err := newRemoteConfigGitProvider(c)
+		if err != nil {
+			return nil, err
+		}
+		r.poller = poller
+		return r, nil
 	default:
 		return nil, fmt.Errorf("unsupported protocol for agent management api: %s", p)
 	}
@@ -244,7 +721,12 @@ func newRemoteConfigProvider(c *Config) (*remoteConfigHTTPProvider, error) {
 // fullUrl creates and returns the URL that should be used when querying the Agent Management API,
 // including the namespace, base config id, and any labels that have been specified.
 func (am *AgentManagementConfig) fullUrl() (string, error) {
-	fullPath, err := url.JoinPath(am.Url, "namespace", am.RemoteConfiguration.Namespace, "remote_config")
+	return am.fullUrlFor(am.Url.primary())
+}
+
+// fullUrlFor is like fullUrl, but for querying the API at baseURL.
+func (am *AgentManagementConfig) fullUrlFor(baseURL string) (string, error) {
+	fullPath, err := url.JoinPath(baseURL, "namespace", am.RemoteConfiguration.Namespace, "remote_config")
 	if err != nil {
 		return "", fmt.Errorf("error trying to join url: %w", err)
 	}
@@ -260,9 +742,111 @@ func (am *AgentManagementConfig) fullUrl() (string, error) {
 	return u.String(), nil
 }
 
-// SleepTime returns the duration in between config fetches.
-func (am *AgentManagementConfig) SleepTime() time.Duration {
-	return am.PollingInterval
+// httpClientConfig returns the HTTP client config to fetch remote configs
+// with.
+func (am *AgentManagementConfig) httpClientConfig() *config.HTTPClientConfig {
+	if am.HTTPClientConfig != nil {
+		return am.HTTPClientConfig
+	}
+	var res config.HTTPClientConfig
+	if basicAuth := am.basicAuth(); basicAuth != (config.BasicAuth{}) {
+		res.BasicAuth = &basicAuth
+	}
+	if am.TLSConfig != nil {
+		res.TLSConfig = *am.TLSConfig
+	}
+	return &res
+}
+
+// basicAuth returns the credentials to fetch remote configs with. The
+// credentials in NamespaceBasicAuth for the configured namespace take
+// precedence over BasicAuth.
+func (am *AgentManagementConfig) basicAuth() config.BasicAuth {
+	if basicAuth, ok := am.NamespaceBasicAuth[am.RemoteConfiguration.Namespace]; ok {
+		return basicAuth
+	}
+	return am.BasicAuth
+}
+
+// validateNamespaceBasicAuth checks that the credentials of every namespace
+// are complete.
+func (am *AgentManagementConfig) validateNamespaceBasicAuth() error {
+	if len(am.NamespaceBasicAuth) == 0 {
+		return nil
+	}
+	if am.Protocol == protocolGit || isObjectStorageProtocol(am.Protocol) {
+		return fmt.Errorf("'agent_management.namespace_basic_auth' is not supported with the %s protocol", am.Protocol)
+	}
+	if am.HTTPClientConfig != nil {
+		return errors.New("at most one of 'agent_management.namespace_basic_auth' and 'agent_management.http_client_config' must be specified")
+	}
+	for namespace, basicAuth := range am.NamespaceBasicAuth {
+		if basicAuth.Username == "" || basicAuth.PasswordFile == "" {
+			return fmt.Errorf("both username and password_file fields must be specified for namespace %q in 'agent_management.namespace_basic_auth'", namespace)
+		}
+	}
+	return nil
+}
+
+// defaultAcceptEncodings are the encodings advertised when fetching remote
+// configs if none are configured, in order of preference.
+var defaultAcceptEncodings = []string{encodingZstd, encodingSnappy, encodingGzip}
+
+// acceptEncodings returns the encodings to advertise when fetching remote
+// configs.
+func (am *AgentManagementConfig) acceptEncodings() []string {
+	if len(am.AcceptEncoding) == 0 {
+		return defaultAcceptEncodings
+	}
+	return am.AcceptEncoding
+}
+
+// addQueryParam adds a query parameter to rawURL.
+func addQueryParam(rawURL, key, value string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set(key, value)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// SleepTime returns the duration to wait before the next config fetch, after
+// the given number of consecutive failures to fetch the remote config.
+//
+// The polling interval is doubled for every consecutive failure, up to
+// am.PollingMaxBackoff. Once the circuit breaker opens, the polling interval
+// is am.CircuitBreakerInterval instead. A random duration in the range
+// [0, am.PollingJitter) is added so that agents sharing the same polling
+// interval don't all fetch at the same time.
+func (am *AgentManagementConfig) SleepTime(failures int64) time.Duration {
+	sleepTime := am.backoffTime(failures)
+	if am.circuitBreakerOpen(failures) && sleepTime < am.CircuitBreakerInterval {
+		sleepTime = am.CircuitBreakerInterval
+	}
+	if am.PollingJitter > 0 {
+		sleepTime += time.Duration(rand.Int63n(int64(am.PollingJitter)))
+	}
+	return sleepTime
+}
+
+// backoffTime returns the polling interval to use after the given number of
+// consecutive fetch failures.
+func (am *AgentManagementConfig) backoffTime(failures int64) time.Duration {
+	interval := am.PollingInterval
+	if am.PollingMaxBackoff <= interval {
+		return interval
+	}
+
+	for i := int64(0); i < failures && interval < am.PollingMaxBackoff; i++ {
+		interval *= 2
+	}
+	if interval > am.PollingMaxBackoff {
+		interval = am.PollingMaxBackoff
+	}
+	return interval
 }
 
 // jitterTime returns a random duration in the range [0, am.PollingInterval).
@@ -272,14 +856,69 @@ func (am *AgentManagementConfig) JitterTime() time.Duration {
 
 // Validate checks that necessary portions of the config have been set.
 func (am *AgentManagementConfig) Validate() error {
-	if am.BasicAuth.Username == "" || am.BasicAuth.PasswordFile == "" {
-		return errors.New("both username and password_file fields must be specified")
+	if err := am.validateTLS(); err != nil {
+		return err
+	}
+
+	if err := am.validateProxy(); err != nil {
+		return err
+	}
+
+	if err := am.validateTemplateVariables(); err != nil {
+		return err
+	}
+
+	if err := am.validateNamespaceBasicAuth(); err != nil {
+		return err
+	}
+
+	if err := am.validateVault(); err != nil {
+		return err
+	}
+
+	if len(am.Url) > 1 && (am.Protocol == protocolGit || isObjectStorageProtocol(am.Protocol)) {
+		return fmt.Errorf("'agent_management.api_url' must be a single URL when using the %s protocol", am.Protocol)
+	}
+
+	if am.Protocol == protocolGit {
+		if err := am.validateGit(); err != nil {
+			return err
+		}
+	} else if isObjectStorageProtocol(am.Protocol) {
+		// Object storage clients are authenticated using the credentials of
+		// the environment rather than basic_auth or http_client_config.
+		if err := am.validateObjectStorage(); err != nil {
+			return err
+		}
+	} else if am.HTTPClientConfig != nil {
+		if am.BasicAuth != (config.BasicAuth{}) {
+			return errors.New("at most one of 'agent_management.basic_auth' and 'agent_management.http_client_config' must be specified")
+		}
+		if err := am.HTTPClientConfig.Validate(); err != nil {
+			return fmt.Errorf("invalid 'agent_management.http_client_config': %w", err)
+		}
+	} else if basicAuth := am.basicAuth(); basicAuth != (config.BasicAuth{}) || !am.hasClientCertificate() {
+		if basicAuth.Username == "" || (basicAuth.PasswordFile == "" && !am.hasVaultPassword()) {
+			return errors.New("both username and password_file fields must be specified")
+		}
 	}
 
 	if am.PollingInterval <= 0 {
 		return fmt.Errorf("polling interval must be >0")
 	}
 
+	if am.PollingJitter < 0 {
+		return fmt.Errorf("polling jitter must be >=0")
+	}
+
+	if am.PollingMaxBackoff != 0 && am.PollingMaxBackoff < am.PollingInterval {
+		return fmt.Errorf("polling max backoff must be 0 or at least the polling interval")
+	}
+
+	if err := am.validateCircuitBreaker(); err != nil {
+		return err
+	}
+
 	if am.RemoteConfiguration.Namespace == "" {
 		return errors.New("namespace must be specified in 'remote_configuration' block of the config")
 	}
@@ -288,5 +927,58 @@ func (am *AgentManagementConfig) Validate() error {
 		return errors.New("path to cache must be specified in 'agent_management.remote_config_cache_location'")
 	}
 
+	if am.CacheHistory < 0 {
+		return errors.New("'agent_management.remote_config_cache_history' must be >=0")
+	}
+
+	if am.SignatureVerification != nil {
+		if err := am.SignatureVerification.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := am.validateFallbackApiUrl(); err != nil {
+		return err
+	}
+
+	if err := am.validateStatusUrl(); err != nil {
+		return err
+	}
+
+	if err := am.validateRegistration(); err != nil {
+		return err
+	}
+
+	for _, enc := range am.AcceptEncoding {
+		switch enc {
+		case encodingZstd, encodingSnappy, encodingGzip, encodingIdentity:
+		default:
+			return fmt.Errorf("unsupported encoding %q in 'agent_management.accept_encoding'", enc)
+		}
+	}
+
+	return nil
+}
+
+// validateTLS checks the settings of 'agent_management.tls_config'.
+func (am *AgentManagementConfig) validateTLS() error {
+	if am.TLSConfig == nil {
+		return nil
+	}
+	if am.Protocol == protocolGit || isObjectStorageProtocol(am.Protocol) {
+		return fmt.Errorf("'agent_management.tls_config' is not supported with the %s protocol", am.Protocol)
+	}
+	if am.HTTPClientConfig != nil {
+		return errors.New("at most one of 'agent_management.tls_config' and 'agent_management.http_client_config' must be specified, use 'http_client_config.tls_config' instead")
+	}
+	if (am.TLSConfig.CertFile == "") != (am.TLSConfig.KeyFile == "") {
+		return errors.New("both cert_file and key_file must be specified in 'agent_management.tls_config'")
+	}
 	return nil
 }
+
+// hasClientCertificate returns true if requests to the API are authenticated
+// with a client certificate.
+func (am *AgentManagementConfig) hasClientCertificate() bool {
+	return am.TLSConfig != nil && am.TLSConfig.CertFile != ""
+}
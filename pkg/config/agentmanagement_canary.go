@@ -0,0 +1,325 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/grafana/agent/pkg/server"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// remoteConfigCanaryFilename is the file in the cache location which records
+// the canary remote config being evaluated.
+const remoteConfigCanaryFilename = "remote-config-canary.json"
+
+// Outcomes of canary remote configs reported in remoteConfigStatus.
+const (
+	remoteConfigCanaryStarted  = "started"
+	remoteConfigCanaryPromoted = "promoted"
+	remoteConfigCanaryFailed   = "failed"
+)
+
+// canaryHealthMetrics are the counters which are watched while a canary
+// remote config is applied. The canary fails if their total grows by more
+// than the failure threshold of the canary.
+var canaryHealthMetrics = []string{
+	"agent_config_load_failures_total",
+	"agent_config_rollbacks_total",
+	"prometheus_remote_storage_samples_failed_total",
+	"promtail_dropped_entries_total",
+}
+
+// CanaryConfig marks a remote config as a canary. The agent applies the
+// remote config and watches its own health for TTL. If the agent becomes
+// unhealthy, the previously cached remote config is restored; otherwise the
+// remote config is kept.
+type CanaryConfig struct {
+	TTL time.Duration `json:"ttl" yaml:"ttl"`
+	// FailureThreshold is how much the watched failure counters may grow in
+	// total before the canary fails.
+	FailureThreshold float64 `json:"failure_threshold,omitempty" yaml:"failure_threshold,omitempty"`
+}
+
+// remoteConfigCanary records a canary remote config being evaluated.
+type remoteConfigCanary struct {
+	InitialConfigHash string `json:"initial_config_hash"`
+	// ConfigHash is the hash of the canary remote config.
+	ConfigHash       string    `json:"config_hash"`
+	Deadline         time.Time `json:"deadline"`
+	FailureThreshold float64   `json:"failure_threshold,omitempty"`
+	// PreviousConfig is the last remote config which isn't a canary. It's
+	// restored when the canary fails.
+	PreviousConfig string `json:"previous_config"`
+}
+
+// RemoteConfigCanary describes the canary remote config being evaluated.
+type RemoteConfigCanary struct {
+	ConfigHash       string
+	Deadline         time.Time
+	FailureThreshold float64
+	// Restarted is true if the canary was started before the agent restarted.
+	// An agent which restarts while a canary is applied is considered
+	// unhealthy.
+	Restarted bool
+}
+
+// remoteConfigCanarySettings returns the canary settings of the raw remote
+// config, or nil if it isn't a canary.
+func remoteConfigCanarySettings(remoteConfigBytes []byte) *CanaryConfig {
+	rc, err := NewRemoteConfig(remoteConfigBytes)
+	if err != nil || rc.Canary == nil || rc.Canary.TTL <= 0 {
+		return nil
+	}
+	return rc.Canary
+}
+
+// readCanary returns the canary being evaluated for the current initial
+// config, or nil if there's none.
+func (r remoteConfigHTTPProvider) readCanary() (*remoteConfigCanary, error) {
+	buf, err := os.ReadFile(filepath.Join(r.InitialConfig.CacheLocation, remoteConfigCanaryFilename))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading remote config canary: %w", err)
+	}
+	var canary remoteConfigCanary
+	if err := json.Unmarshal(buf, &canary); err != nil {
+		return nil, fmt.Errorf("error trying to load remote config canary: %w", err)
+	}
+	initialConfigHash, err := hashInitialConfig(*r.InitialConfig)
+	if err != nil || canary.InitialConfigHash != initialConfigHash {
+		return nil, err
+	}
+	return &canary, nil
+}
+
+// clearCanary removes the record of the canary being evaluated.
+func (r remoteConfigHTTPProvider) clearCanary() error {
+	err := os.Remove(filepath.Join(r.InitialConfig.CacheLocation, remoteConfigCanaryFilename))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not remove remote config canary: %w", err)
+	}
+	return nil
+}
+
+// StartCanary records that the remote config with the given hash is a canary
+// which replaced previousConfig. The canary is evaluated until settings.TTL
+// elapses.
+//
+// If the previous remote config was itself a canary which wasn't promoted
+// yet, the remote config it replaced is kept as the one to restore. A canary
+// which is already being evaluated isn't restarted.
+func (r remoteConfigHTTPProvider) StartCanary(previousConfig []byte, configHash string, settings CanaryConfig) error {
+	existing, err := r.readCanary()
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ConfigHash == configHash {
+		return nil
+	}
+
+	initialConfigHash, err := hashInitialConfig(*r.InitialConfig)
+	if err != nil {
+		return err
+	}
+	canary := remoteConfigCanary{
+		InitialConfigHash: initialConfigHash,
+		ConfigHash:        configHash,
+		Deadline:          time.Now().Add(settings.TTL),
+		FailureThreshold:  settings.FailureThreshold,
+		PreviousConfig:    string(previousConfig),
+	}
+	if existing != nil && existing.ConfigHash == hashRemoteConfig(previousConfig) {
+		canary.PreviousConfig = existing.PreviousConfig
+	}
+
+	marshalled, err := json.Marshal(canary)
+	if err != nil {
+		return fmt.Errorf("could not marshal remote config canary: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(r.InitialConfig.CacheLocation, remoteConfigCanaryFilename), marshalled, 0666); err != nil {
+		return err
+	}
+
+	r.poller.recordStartedCanary(configHash)
+	return nil
+}
+
+// recordStartedCanary records that the canary remote config with the given
+// hash was started by p.
+func (p *RemoteConfigPoller) recordStartedCanary(configHash string) {
+	if p == nil {
+		return
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if p.startedCanaries == nil {
+		p.startedCanaries = make(map[string]struct{})
+	}
+	p.startedCanaries[configHash] = struct{}{}
+}
+
+// startedCanary returns true if the canary remote config with the given hash
+// was started by p.
+func (p *RemoteConfigPoller) startedCanary(configHash string) bool {
+	if p == nil {
+		return false
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	_, ok := p.startedCanaries[configHash]
+	return ok
+}
+
+// ActiveRemoteConfigCanary returns the canary remote config being evaluated
+// for the initial config c, or nil if there's none. A canary which was
+// replaced by a remote config that isn't a canary is forgotten. The canary is
+// considered restarted unless it was started by poller.
+func ActiveRemoteConfigCanary(c *Config, poller *RemoteConfigPoller) (*RemoteConfigCanary, error) {
+	r, err := newRemoteConfigHTTPProvider(c)
+	if err != nil {
+		return nil, err
+	}
+	r.poller = poller
+	canary, err := r.readCanary()
+	if err != nil || canary == nil {
+		return nil, err
+	}
+	if current, err := r.GetCachedRemoteConfig(); err != nil || hashRemoteConfig(current) != canary.ConfigHash {
+		return nil, r.clearCanary()
+	}
+
+	return &RemoteConfigCanary{
+		ConfigHash:       canary.ConfigHash,
+		Deadline:         canary.Deadline,
+		FailureThreshold: canary.FailureThreshold,
+		Restarted:        !poller.startedCanary(canary.ConfigHash),
+	}, nil
+}
+
+// PromoteRemoteConfigCanary keeps the canary remote config being evaluated
+// for the initial config c, after it stayed healthy for its TTL.
+func PromoteRemoteConfigCanary(c *Config, poller *RemoteConfigPoller, log *server.Logger) error {
+	r, err := newRemoteConfigHTTPProvider(c)
+	if err != nil {
+		return err
+	}
+	r.poller = poller
+	canary, err := r.readCanary()
+	if err != nil || canary == nil {
+		return err
+	}
+	if err := r.clearCanary(); err != nil {
+		return err
+	}
+	reportRemoteConfigStatus(poller, r, log, remoteConfigStatus{
+		ConfigHash: canary.ConfigHash,
+		Source:     remoteConfigSourceRemote,
+		Canary:     remoteConfigCanaryPromoted,
+	})
+	return nil
+}
+
+// FailRemoteConfigCanary restores the remote config which the canary being
+// evaluated for the initial config c replaced, because of reason. Like after
+// a rollback, the restored config keeps being loaded until the API serves a
+// remote config other than the canary. The config must be reloaded
+// afterwards with the same poller.
+func FailRemoteConfigCanary(c *Config, poller *RemoteConfigPoller, log *server.Logger, reason string) error {
+	r, err := newRemoteConfigHTTPProvider(c)
+	if err != nil {
+		return err
+	}
+	r.poller = poller
+	canary, err := r.readCanary()
+	if err != nil || canary == nil {
+		return err
+	}
+
+	rollback := remoteConfigRollback{
+		InitialConfigHash:    canary.InitialConfigHash,
+		RolledBackConfigHash: canary.ConfigHash,
+	}
+	err = r.writeCache(remoteConfigCache{
+		InitialConfigHash: canary.InitialConfigHash,
+		Config:            canary.PreviousConfig,
+	})
+	if err != nil {
+		return err
+	}
+	marshalled, err := json.Marshal(rollback)
+	if err != nil {
+		return fmt.Errorf("could not marshal remote config rollback: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(r.InitialConfig.CacheLocation, remoteConfigRollbackFilename), marshalled, 0666); err != nil {
+		return err
+	}
+	if err := r.clearCanary(); err != nil {
+		return err
+	}
+
+	// Forget the validators of the canary, so that the next reload isn't
+	// skipped as unchanged.
+	poller.setRemoteConfigValidators("", cacheValidators{})
+
+	reportRemoteConfigStatus(poller, r, log, remoteConfigStatus{
+		ConfigHash: hashRemoteConfig([]byte(canary.PreviousConfig)),
+		Source:     remoteConfigSourceCache,
+		Error:      fmt.Sprintf("canary remote config %s failed: %s", canary.ConfigHash, reason),
+		Canary:     remoteConfigCanaryFailed,
+	})
+	return nil
+}
+
+// CanaryHealthBaseline holds the values of the watched failure counters when
+// a canary remote config started being evaluated.
+type CanaryHealthBaseline map[string]float64
+
+// NewCanaryHealthBaseline gathers the watched failure counters from g.
+func NewCanaryHealthBaseline(g prometheus.Gatherer) (CanaryHealthBaseline, error) {
+	families, err := g.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	baseline := make(CanaryHealthBaseline, len(canaryHealthMetrics))
+	for _, name := range canaryHealthMetrics {
+		baseline[name] = 0
+	}
+	for _, mf := range families {
+		if _, ok := baseline[mf.GetName()]; !ok {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			baseline[mf.GetName()] += m.GetCounter().GetValue()
+		}
+	}
+	return baseline, nil
+}
+
+// CheckCanaryHealth compares the watched failure counters gathered from g
+// against baseline. It returns a description of the degradation if the
+// counters grew by more than threshold in total, or an empty string if the
+// agent is healthy. Counters which went down, for example because they were
+// recreated by a reload, are ignored.
+func CheckCanaryHealth(g prometheus.Gatherer, baseline CanaryHealthBaseline, threshold float64) (string, error) {
+	current, err := NewCanaryHealthBaseline(g)
+	if err != nil {
+		return "", err
+	}
+
+	var total float64
+	for _, name := range canaryHealthMetrics {
+		if delta := current[name] - baseline[name]; delta > 0 {
+			total += delta
+		}
+	}
+	if total > threshold {
+		return fmt.Sprintf("failure counters grew by %g, more than the threshold of %g", total, threshold), nil
+	}
+	return "", nil
+}
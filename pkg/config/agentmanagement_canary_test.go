@@ -0,0 +1,197 @@
+package config
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/pkg/config/features"
+	"github.com/grafana/agent/pkg/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRemoteConfig_Canary(t *testing.T) {
+	defaultCfg := DefaultConfig()
+	fetchedConfig := []byte(`
+base_config: |
+  server:
+    log_level: debug
+snippets: []
+canary:
+  ttl: 10m
+  failure_threshold: 5
+`)
+
+	tt := []struct {
+		name          string
+		cachedConfig  []byte
+		expectStarted bool
+	}{
+		{
+			name:          "started",
+			cachedConfig:  []byte("base_config: ''"),
+			expectStarted: true,
+		},
+		{
+			name:          "nothing to restore",
+			cachedConfig:  nil,
+			expectStarted: false,
+		},
+		{
+			name:          "already applied",
+			cachedConfig:  fetchedConfig,
+			expectStarted: false,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			am := validAgentManagementConfig
+			logger := server.NewLogger(defaultCfg.Server)
+			testProvider := testRemoteConfigProvider{InitialConfig: &am}
+			testProvider.fetchedConfigBytesToReturn = fetchedConfig
+			testProvider.cachedConfigToReturn = tc.cachedConfig
+			if tc.cachedConfig == nil {
+				testProvider.cachedConfigErrorToReturn = assert.AnError
+			}
+
+			fs := flag.NewFlagSet("test", flag.ExitOnError)
+			features.Register(fs, allFeatures)
+			defaultCfg.RegisterFlags(fs)
+
+			poller := NewRemoteConfigPoller()
+			cfg, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Poller: poller, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
+			require.NoError(t, err)
+			require.NoError(t, poller.RemoteConfigApplied(nil))
+			assert.Equal(t, "debug", cfg.Server.LogLevel.String())
+			assert.True(t, testProvider.didCacheRemoteConfig)
+			require.Len(t, testProvider.reportedStatuses, 1)
+
+			if !tc.expectStarted {
+				assert.Nil(t, testProvider.startedCanary)
+				assert.Empty(t, testProvider.reportedStatuses[0].Canary)
+				return
+			}
+			require.NotNil(t, testProvider.startedCanary)
+			assert.Equal(t, CanaryConfig{TTL: 10 * time.Minute, FailureThreshold: 5}, *testProvider.startedCanary)
+			assert.Equal(t, remoteConfigCanaryStarted, testProvider.reportedStatuses[0].Canary)
+		})
+	}
+}
+
+func TestRemoteConfigCanary(t *testing.T) {
+	defaultCfg := DefaultConfig()
+	logger := server.NewLogger(defaultCfg.Server)
+	poller := NewRemoteConfigPoller()
+
+	newCanary := func(t *testing.T) (*Config, *remoteConfigHTTPProvider, []byte, []byte) {
+		var cfg Config
+		cfg.AgentManagement = validAgentManagementConfig
+		cfg.AgentManagement.CacheLocation = t.TempDir()
+
+		provider, err := newRemoteConfigHTTPProvider(&cfg)
+		require.NoError(t, err)
+		provider.poller = poller
+
+		goodConfig, canaryConfig := []byte("base_config: 'good'"), []byte("base_config: 'canary'")
+		require.NoError(t, provider.CacheRemoteConfig(goodConfig))
+		require.NoError(t, provider.CacheRemoteConfig(canaryConfig))
+		require.NoError(t, provider.StartCanary(goodConfig, hashRemoteConfig(canaryConfig), CanaryConfig{TTL: time.Hour}))
+		return &cfg, provider, goodConfig, canaryConfig
+	}
+
+	t.Run("fail", func(t *testing.T) {
+		cfg, provider, goodConfig, canaryConfig := newCanary(t)
+
+		canary, err := ActiveRemoteConfigCanary(cfg, poller)
+		require.NoError(t, err)
+		require.NotNil(t, canary)
+		assert.Equal(t, hashRemoteConfig(canaryConfig), canary.ConfigHash)
+		assert.False(t, canary.Restarted)
+
+		require.NoError(t, FailRemoteConfigCanary(cfg, poller, logger, "unhealthy"))
+		cached, err := provider.GetCachedRemoteConfig()
+		require.NoError(t, err)
+		assert.Equal(t, goodConfig, cached)
+		// The canary isn't applied again while the API serves it.
+		assert.Equal(t, hashRemoteConfig(canaryConfig), provider.RolledBackConfigHash())
+		assert.Equal(t, remoteConfigCanaryFailed, GetRemoteConfigState(poller).Canary)
+
+		canary, err = ActiveRemoteConfigCanary(cfg, poller)
+		require.NoError(t, err)
+		assert.Nil(t, canary)
+	})
+
+	t.Run("promote", func(t *testing.T) {
+		cfg, provider, _, canaryConfig := newCanary(t)
+
+		require.NoError(t, PromoteRemoteConfigCanary(cfg, poller, logger))
+		cached, err := provider.GetCachedRemoteConfig()
+		require.NoError(t, err)
+		assert.Equal(t, canaryConfig, cached)
+		assert.Equal(t, remoteConfigCanaryPromoted, GetRemoteConfigState(poller).Canary)
+
+		canary, err := ActiveRemoteConfigCanary(cfg, poller)
+		require.NoError(t, err)
+		assert.Nil(t, canary)
+	})
+
+	t.Run("superseded", func(t *testing.T) {
+		cfg, provider, _, _ := newCanary(t)
+
+		require.NoError(t, provider.CacheRemoteConfig([]byte("base_config: 'newer'")))
+		canary, err := ActiveRemoteConfigCanary(cfg, poller)
+		require.NoError(t, err)
+		assert.Nil(t, canary)
+	})
+
+	t.Run("canary replaces canary", func(t *testing.T) {
+		cfg, provider, goodConfig, canaryConfig := newCanary(t)
+
+		// The remote config to restore is the last one which wasn't a canary.
+		nextConfig := []byte("base_config: 'next canary'")
+		require.NoError(t, provider.CacheRemoteConfig(nextConfig))
+		require.NoError(t, provider.StartCanary(canaryConfig, hashRemoteConfig(nextConfig), CanaryConfig{TTL: time.Hour}))
+
+		require.NoError(t, FailRemoteConfigCanary(cfg, poller, logger, "unhealthy"))
+		cached, err := provider.GetCachedRemoteConfig()
+		require.NoError(t, err)
+		assert.Equal(t, goodConfig, cached)
+	})
+
+	t.Run("restarted", func(t *testing.T) {
+		cfg, _, _, _ := newCanary(t)
+
+		// A new poller didn't start the canary, as if the agent restarted.
+		canary, err := ActiveRemoteConfigCanary(cfg, NewRemoteConfigPoller())
+		require.NoError(t, err)
+		require.NotNil(t, canary)
+		assert.True(t, canary.Restarted)
+	})
+}
+
+func TestCheckCanaryHealth(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	failures := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "prometheus_remote_storage_samples_failed_total",
+	}, []string{"url"})
+	reg.MustRegister(failures)
+	failures.WithLabelValues("a").Add(3)
+
+	baseline, err := NewCanaryHealthBaseline(reg)
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), baseline["prometheus_remote_storage_samples_failed_total"])
+
+	failures.WithLabelValues("a").Add(1)
+	failures.WithLabelValues("b").Add(1)
+	reason, err := CheckCanaryHealth(reg, baseline, 2)
+	require.NoError(t, err)
+	assert.Empty(t, reason)
+
+	failures.WithLabelValues("b").Add(1)
+	reason, err = CheckCanaryHealth(reg, baseline, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "failure counters grew by 3, more than the threshold of 2", reason)
+}
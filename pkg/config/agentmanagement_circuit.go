@@ -0,0 +1,29 @@
+package config
+
+import "errors"
+
+// circuitBreakerOpen returns true if the given number of consecutive fetch
+// failures reached 'agent_management.circuit_breaker_threshold', which opens
+// the circuit breaker.
+func (am *AgentManagementConfig) circuitBreakerOpen(failures int64) bool {
+	return am.CircuitBreakerThreshold > 0 && failures >= int64(am.CircuitBreakerThreshold)
+}
+
+// validateCircuitBreaker checks the settings of
+// 'agent_management.circuit_breaker_threshold' and
+// 'agent_management.circuit_breaker_interval'.
+func (am *AgentManagementConfig) validateCircuitBreaker() error {
+	if am.CircuitBreakerThreshold < 0 {
+		return errors.New("'agent_management.circuit_breaker_threshold' must be >=0")
+	}
+	if am.CircuitBreakerThreshold == 0 {
+		if am.CircuitBreakerInterval != 0 {
+			return errors.New("'agent_management.circuit_breaker_interval' requires 'agent_management.circuit_breaker_threshold' to be set")
+		}
+		return nil
+	}
+	if am.CircuitBreakerInterval < am.PollingInterval {
+		return errors.New("'agent_management.circuit_breaker_interval' must be at least the polling interval")
+	}
+	return nil
+}
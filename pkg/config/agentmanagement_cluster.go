@@ -0,0 +1,59 @@
+package config
+
+import "strconv"
+
+// Query parameters describing the shard of a clustered agent in requests for
+// remote configs. They allow the API to serve shard-aware remote configs,
+// such as hashmod relabeling rules, to every agent of a cluster.
+const (
+	clusterNameParam  = "cluster_name"
+	clusterShardParam = "cluster_shard"
+	clusterPeersParam = "cluster_peers"
+)
+
+// ClusterShardFunc returns the index of the agent among the agents of its
+// cluster, along with the number of agents in the cluster. ok is false if
+// clustering is disabled or the agent hasn't joined its cluster.
+type ClusterShardFunc func() (shard, peers int, ok bool)
+
+// SetClusterShardFunc sets the function which returns the shard of the agent
+// sent in requests for remote configs polled by p. Passing nil stops sending
+// the shard.
+func (p *RemoteConfigPoller) SetClusterShardFunc(f ClusterShardFunc) {
+	if p == nil {
+		return
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.clusterShard = f
+}
+
+// addClusterParams adds the cluster name of am and the shard of the agent to
+// the query of rawURL if the agent is clustered.
+func (p *RemoteConfigPoller) addClusterParams(am *AgentManagementConfig, rawURL string) (string, error) {
+	if p == nil {
+		return rawURL, nil
+	}
+	p.mut.Lock()
+	f := p.clusterShard
+	p.mut.Unlock()
+
+	if f == nil {
+		return rawURL, nil
+	}
+	shard, peers, ok := f()
+	if !ok {
+		return rawURL, nil
+	}
+
+	var err error
+	if name := am.RemoteConfiguration.ClusterName; name != "" {
+		if rawURL, err = addQueryParam(rawURL, clusterNameParam, name); err != nil {
+			return "", err
+		}
+	}
+	if rawURL, err = addQueryParam(rawURL, clusterShardParam, strconv.Itoa(shard)); err != nil {
+		return "", err
+	}
+	return addQueryParam(rawURL, clusterPeersParam, strconv.Itoa(peers))
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchRemoteConfig_ClusterShard(t *testing.T) {
+	var gotQuery url.Values
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+		_, _ = w.Write([]byte("base_config: ''"))
+	}))
+	defer svr.Close()
+
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret"), 0600))
+
+	var cfg Config
+	cfg.AgentManagement = validAgentManagementConfig
+	cfg.AgentManagement.Url = apiURLs{svr.URL}
+	cfg.AgentManagement.BasicAuth.PasswordFile = passwordFile
+	cfg.AgentManagement.CacheLocation = dir
+	cfg.AgentManagement.RemoteConfiguration.ClusterName = "eu-west"
+
+	provider, err := newRemoteConfigHTTPProvider(&cfg)
+	require.NoError(t, err)
+	provider.poller = NewRemoteConfigPoller()
+
+	// Nothing is sent before the agent joins its cluster.
+	joined := false
+	provider.poller.SetClusterShardFunc(func() (int, int, bool) { return 2, 5, joined })
+	_, err = provider.FetchRemoteConfig()
+	require.NoError(t, err)
+	require.False(t, gotQuery.Has(clusterNameParam))
+	require.False(t, gotQuery.Has(clusterShardParam))
+	require.False(t, gotQuery.Has(clusterPeersParam))
+
+	joined = true
+	_, err = provider.FetchRemoteConfig()
+	require.NoError(t, err)
+	require.Equal(t, "eu-west", gotQuery.Get(clusterNameParam))
+	require.Equal(t, "2", gotQuery.Get(clusterShardParam))
+	require.Equal(t, "5", gotQuery.Get(clusterPeersParam))
+	require.Equal(t, "A", gotQuery.Get("a"), "labels should still be sent")
+}
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigChangeType is the kind of a ConfigChange.
+type ConfigChangeType string
+
+// Types of ConfigChange.
+const (
+	ConfigChangeAdded    ConfigChangeType = "added"
+	ConfigChangeRemoved  ConfigChangeType = "removed"
+	ConfigChangeModified ConfigChangeType = "modified"
+)
+
+// ConfigChange describes a single difference between two configs. Old and
+// New hold the YAML encoding of the value at Path, with secrets redacted.
+type ConfigChange struct {
+	Type ConfigChangeType `json:"type"`
+	// Path identifies the changed value, such as
+	// "metrics.configs[name=default].scrape_configs[job_name=node].scrape_interval".
+	Path string `json:"path"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+// String formats the change for logging.
+func (c ConfigChange) String() string {
+	switch c.Type {
+	case ConfigChangeAdded:
+		return fmt.Sprintf("%s %s: %s", c.Type, c.Path, c.New)
+	case ConfigChangeRemoved:
+		return fmt.Sprintf("%s %s: %s", c.Type, c.Path, c.Old)
+	default:
+		return fmt.Sprintf("%s %s: %s -> %s", c.Type, c.Path, c.Old, c.New)
+	}
+}
+
+// DiffConfigs returns the structural differences between the configs prev
+// and next. The configs are compared by their YAML encoding, which redacts
+// secrets, so that the changes can be logged and exposed safely. Changes are
+// sorted by path.
+//
+// Lists whose elements are all named by a unique "name" or "job_name" field
+// are compared by name rather than by position, so that inserting an element
+// isn't reported as changing every element after it.
+func DiffConfigs(prev, next *Config) ([]ConfigChange, error) {
+	prevTree, err := configTree(prev)
+	if err != nil {
+		return nil, err
+	}
+	nextTree, err := configTree(next)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []ConfigChange
+	diffConfigTrees("", prevTree, nextTree, &changes)
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// configTree decodes the redacted YAML encoding of c into generic values.
+func configTree(c *Config) (interface{}, error) {
+	bb, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal config: %w", err)
+	}
+	var tree interface{}
+	if err := yaml.Unmarshal(bb, &tree); err != nil {
+		return nil, fmt.Errorf("could not unmarshal config: %w", err)
+	}
+	return tree, nil
+}
+
+// diffConfigTrees appends the differences between a and b, found at path, to
+// changes.
+func diffConfigTrees(path string, a, b interface{}, changes *[]ConfigChange) {
+	switch {
+	case a == nil && b == nil:
+		return
+	case a == nil:
+		*changes = append(*changes, ConfigChange{Type: ConfigChangeAdded, Path: path, New: encodeConfigValue(b)})
+		return
+	case b == nil:
+		*changes = append(*changes, ConfigChange{Type: ConfigChangeRemoved, Path: path, Old: encodeConfigValue(a)})
+		return
+	}
+
+	switch a := a.(type) {
+	case map[interface{}]interface{}:
+		if b, ok := b.(map[interface{}]interface{}); ok {
+			diffConfigMaps(path, a, b, changes)
+			return
+		}
+	case []interface{}:
+		if b, ok := b.([]interface{}); ok {
+			diffConfigLists(path, a, b, changes)
+			return
+		}
+	}
+
+	if ea, eb := encodeConfigValue(a), encodeConfigValue(b); ea != eb {
+		*changes = append(*changes, ConfigChange{Type: ConfigChangeModified, Path: path, Old: ea, New: eb})
+	}
+}
+
+func diffConfigMaps(path string, a, b map[interface{}]interface{}, changes *[]ConfigChange) {
+	keys := make(map[string]struct{}, len(a)+len(b))
+	for k := range a {
+		keys[fmt.Sprint(k)] = struct{}{}
+	}
+	for k := range b {
+		keys[fmt.Sprint(k)] = struct{}{}
+	}
+	for k := range keys {
+		diffConfigTrees(joinConfigPath(path, k), lookupConfigKey(a, k), lookupConfigKey(b, k), changes)
+	}
+}
+
+func diffConfigLists(path string, a, b []interface{}, changes *[]ConfigChange) {
+	nameField := configListNameField(a, b)
+	if nameField == "" {
+		for i := 0; i < len(a) || i < len(b); i++ {
+			var ea, eb interface{}
+			if i < len(a) {
+				ea = a[i]
+			}
+			if i < len(b) {
+				eb = b[i]
+			}
+			diffConfigTrees(fmt.Sprintf("%s[%d]", path, i), ea, eb, changes)
+		}
+		return
+	}
+
+	byName := func(list []interface{}) map[string]interface{} {
+		m := make(map[string]interface{}, len(list))
+		for _, e := range list {
+			m[fmt.Sprint(lookupConfigKey(e.(map[interface{}]interface{}), nameField))] = e
+		}
+		return m
+	}
+	am, bm := byName(a), byName(b)
+	names := make(map[string]struct{}, len(am)+len(bm))
+	for n := range am {
+		names[n] = struct{}{}
+	}
+	for n := range bm {
+		names[n] = struct{}{}
+	}
+	for n := range names {
+		diffConfigTrees(fmt.Sprintf("%s[%s=%s]", path, nameField, n), am[n], bm[n], changes)
+	}
+}
+
+// configListNameField returns the field which uniquely names every element of
+// the lists a and b, or an empty string if there is none.
+func configListNameField(a, b []interface{}) string {
+	if len(a) == 0 && len(b) == 0 {
+		return ""
+	}
+NextField:
+	for _, field := range []string{"name", "job_name"} {
+		for _, list := range [][]interface{}{a, b} {
+			seen := make(map[string]struct{}, len(list))
+			for _, e := range list {
+				m, ok := e.(map[interface{}]interface{})
+				if !ok {
+					return ""
+				}
+				v := lookupConfigKey(m, field)
+				if v == nil {
+					continue NextField
+				}
+				name := fmt.Sprint(v)
+				if _, dup := seen[name]; dup {
+					continue NextField
+				}
+				seen[name] = struct{}{}
+			}
+		}
+		return field
+	}
+	return ""
+}
+
+func lookupConfigKey(m map[interface{}]interface{}, key string) interface{} {
+	for k, v := range m {
+		if fmt.Sprint(k) == key {
+			return v
+		}
+	}
+	return nil
+}
+
+func joinConfigPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// encodeConfigValue encodes v as single-line YAML.
+func encodeConfigValue(v interface{}) string {
+	switch v.(type) {
+	case map[interface{}]interface{}, []interface{}:
+		// Flow style keeps nested values on a single line.
+		bb, err := yaml.Marshal(struct {
+			V interface{} `yaml:"v,flow"`
+		}{v})
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return strings.TrimSpace(strings.TrimPrefix(string(bb), "v:"))
+	default:
+		bb, err := yaml.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return strings.TrimSpace(string(bb))
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	load := func(t *testing.T, in string) *Config {
+		t.Helper()
+		var c Config
+		require.NoError(t, LoadBytes([]byte(in), false, &c))
+		return &c
+	}
+
+	prev := load(t, `
+metrics:
+  global:
+    scrape_interval: 15s
+  configs:
+  - name: default
+    scrape_configs:
+    - job_name: node
+      static_configs:
+      - targets: ['localhost:9100']
+    remote_write:
+    - url: http://localhost:9009/api/prom/push
+      basic_auth:
+        username: user
+        password: old-password
+`)
+	next := load(t, `
+metrics:
+  global:
+    scrape_interval: 30s
+  configs:
+  - name: default
+    scrape_configs:
+    - job_name: agent
+      static_configs:
+      - targets: ['localhost:12345']
+    - job_name: node
+      static_configs:
+      - targets: ['localhost:9100']
+    remote_write:
+    - url: http://localhost:9009/api/prom/push
+      basic_auth:
+        username: user
+        password: new-password
+`)
+
+	changes, err := DiffConfigs(prev, next)
+	require.NoError(t, err)
+	require.Equal(t, []ConfigChange{
+		{
+			Type: ConfigChangeAdded,
+			Path: "metrics.configs[name=default].scrape_configs[job_name=agent]",
+			New:  changes[0].New,
+		},
+		{
+			Type: ConfigChangeModified,
+			Path: "metrics.global.scrape_interval",
+			Old:  "15s",
+			New:  "30s",
+		},
+	}, changes)
+	require.Contains(t, changes[0].New, "localhost:12345")
+
+	for _, c := range changes {
+		require.NotContains(t, c.String(), "password")
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		changes, err := DiffConfigs(prev, prev)
+		require.NoError(t, err)
+		require.Empty(t, changes)
+	})
+}
+
+func TestDiffConfigs_ListsWithoutNames(t *testing.T) {
+	var changes []ConfigChange
+	diffConfigTrees("targets", []interface{}{"a", "b"}, []interface{}{"a", "c", "d"}, &changes)
+	require.Equal(t, []ConfigChange{
+		{Type: ConfigChangeModified, Path: "targets[1]", Old: "b", New: "c"},
+		{Type: ConfigChangeAdded, Path: "targets[2]", New: "d"},
+	}, changes)
+}
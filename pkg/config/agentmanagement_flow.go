@@ -0,0 +1,245 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"gopkg.in/yaml.v2"
+)
+
+// Media types of remote configs. The Agent Management API is asked for the
+// format the agent can load through the Accept header. Responses with a
+// media type other than ContentTypeRiver are assumed to be YAML.
+const (
+	contentTypeYAML = "application/yaml"
+	// ContentTypeRiver is the media type of River configs for Flow mode.
+	ContentTypeRiver = "application/x-river"
+)
+
+// flowCacheFilename is the name of the file the River config for Flow mode
+// is cached in. It's kept apart from the cache of static mode.
+const flowCacheFilename = "remote-config-cache-flow.json"
+
+// LoadAgentManagementFile reads the agent_management block from the YAML
+// file at path. Flow mode uses it to fetch its River config from the Agent
+// Management API.
+func LoadAgentManagementFile(path string) (*AgentManagementConfig, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading agent management config file: %w", err)
+	}
+
+	var file struct {
+		AgentManagement AgentManagementConfig `yaml:"agent_management"`
+	}
+	if err := yaml.UnmarshalStrict(buf, &file); err != nil {
+		return nil, fmt.Errorf("error parsing agent management config file: %w", err)
+	}
+	am := file.AgentManagement
+	am.Enabled = true
+
+	if am.Protocol != httpScheme && am.Protocol != httpsScheme {
+		return nil, fmt.Errorf("unsupported protocol for River remote configs: %s", am.Protocol)
+	}
+	if err := am.Validate(); err != nil {
+		return nil, err
+	}
+	return &am, nil
+}
+
+// GetFlowRemoteConfig fetches the River config for Flow mode from the Agent
+// Management API configured by am. The fetched config is checked by validate,
+// which should parse it with the Flow loader, and cached if it's valid.
+//
+// If the config can't be fetched or is invalid, the cached config is returned
+// instead. ErrRemoteConfigNotModified is returned if the config hasn't
+// changed since it was last loaded by poller.
+//
+// The returned config is pending on poller until the outcome of loading it
+// is recorded by RemoteConfigPoller.RemoteConfigApplied.
+func GetFlowRemoteConfig(am *AgentManagementConfig, poller *RemoteConfigPoller, validate func([]byte) error, logger log.Logger) ([]byte, error) {
+	provider, err := poller.flowRemoteConfigProvider(am)
+	if err != nil {
+		return nil, err
+	}
+	poller.setPendingRemoteConfig(nil)
+
+	bb, contentType, err := provider.fetch(ContentTypeRiver)
+	poller.recordFetchTime(time.Now())
+	poller.recordFetch(err)
+	if errors.Is(err, ErrRemoteConfigNotModified) {
+		level.Debug(logger).Log("msg", "remote config has not changed since it was last loaded")
+		return nil, err
+	} else if err != nil {
+		level.Error(logger).Log("msg", "could not fetch from API, falling back to cache", "err", err)
+		return provider.getCachedFlowRemoteConfig(validate, logger, err)
+	}
+
+	// Secrets read from Vault are substituted last, so that they're never
+	// written to the cache.
+	var rendered []byte
+	if contentType != ContentTypeRiver {
+		err = fmt.Errorf("expected a remote config of type %s, got %q", ContentTypeRiver, contentType)
+	} else {
+		bb = substituteTemplateVariables(bb, am.TemplateVariables)
+		rendered, err = provider.substituteVaultSecrets(bb)
+		if err == nil {
+			err = validate(rendered)
+		}
+	}
+	if err != nil {
+		// Forget the validators of the invalid config so that it isn't reported
+		// as unchanged by the next fetch.
+		poller.setRemoteConfigValidators("", cacheValidators{})
+		level.Error(logger).Log("msg", "could not load remote config, falling back to cache", "err", err)
+		return provider.getCachedFlowRemoteConfig(validate, logger, err)
+	}
+
+	level.Info(logger).Log("msg", "fetched and loaded remote config from API")
+	pending := provider.newPendingFlowRemoteConfig(logger)
+	// The cache is unusable if it can't be read, so it's removed rather than
+	// restored if the new config fails to apply.
+	previous, _ := provider.readFlowCache()
+	if err := provider.cacheFlowRemoteConfig(bb); err != nil {
+		level.Error(logger).Log("msg", "could not cache config locally", "err", err)
+	} else {
+		pending.restoreCache = func() error { return provider.restoreFlowCache(previous) }
+	}
+	pending.status = remoteConfigStatus{
+		ConfigHash: hashRemoteConfig(bb),
+		Source:     remoteConfigSourceRemote,
+	}
+	poller.setPendingRemoteConfig(pending)
+	return rendered, nil
+}
+
+// flowRemoteConfigProvider returns the provider which fetches the River
+// configs configured by am. Building it reads the agent ID and renders the
+// labels, so it's only built once for the same am, unless p is nil.
+func (p *RemoteConfigPoller) flowRemoteConfigProvider(am *AgentManagementConfig) (*remoteConfigHTTPProvider, error) {
+	if p == nil {
+		return newRemoteConfigHTTPProvider(&Config{AgentManagement: *am})
+	}
+
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if p.flowProvider != nil && p.flowProviderConfig == am {
+		return p.flowProvider, nil
+	}
+
+	provider, err := newRemoteConfigHTTPProvider(&Config{AgentManagement: *am})
+	if err != nil {
+		return nil, err
+	}
+	provider.poller = p
+	p.flowProvider, p.flowProviderConfig = provider, am
+	return provider, nil
+}
+
+// newPendingFlowRemoteConfig returns a pending remote config whose status is
+// reported through r.
+func (r remoteConfigHTTPProvider) newPendingFlowRemoteConfig(logger log.Logger) *pendingRemoteConfig {
+	return &pendingRemoteConfig{
+		report: func(status remoteConfigStatus) { r.reportFlowStatus(logger, status) },
+	}
+}
+
+// getCachedFlowRemoteConfig loads the cached River config after the remote
+// config couldn't be fetched or loaded because of remoteErr.
+func (r remoteConfigHTTPProvider) getCachedFlowRemoteConfig(validate func([]byte) error, logger log.Logger, remoteErr error) ([]byte, error) {
+	pending := r.newPendingFlowRemoteConfig(logger)
+
+	bb, err := r.readFlowCache()
+	var rendered []byte
+	if err == nil {
+		rendered, err = r.substituteVaultSecrets(bb)
+	}
+	if err == nil {
+		err = validate(rendered)
+	}
+	if err != nil {
+		pending.report(remoteConfigStatus{Error: fmt.Sprintf("%s; could not load cached config: %s", remoteErr, err)})
+		return nil, fmt.Errorf("could not load cached config: %w", err)
+	}
+	pending.status = remoteConfigStatus{
+		ConfigHash: hashRemoteConfig(bb),
+		Source:     remoteConfigSourceCache,
+		Error:      remoteErr.Error(),
+	}
+	r.poller.setPendingRemoteConfig(pending)
+	return rendered, nil
+}
+
+// substituteVaultSecrets substitutes the secrets of
+// 'agent_management.vault.secrets' in the River config bb.
+func (r remoteConfigHTTPProvider) substituteVaultSecrets(bb []byte) ([]byte, error) {
+	secrets, err := r.InitialConfig.vaultSecrets(r.poller)
+	if err != nil {
+		return nil, err
+	}
+	return substituteTemplateVariables(bb, secrets), nil
+}
+
+// readFlowCache reads the cached River config, which must have been cached
+// for the same initial config.
+func (r remoteConfigHTTPProvider) readFlowCache() ([]byte, error) {
+	buf, err := os.ReadFile(filepath.Join(r.InitialConfig.CacheLocation, flowCacheFilename))
+	if err != nil {
+		return nil, fmt.Errorf("error reading remote config cache: %w", err)
+	}
+
+	var configCache remoteConfigCache
+	if err := json.Unmarshal(buf, &configCache); err != nil {
+		return nil, fmt.Errorf("error trying to load cached remote config from file: %w", err)
+	}
+	if err := initialConfigHashCheck(*r.InitialConfig, configCache); err != nil {
+		return nil, err
+	}
+	return []byte(configCache.Config), nil
+}
+
+// cacheFlowRemoteConfig caches the River config.
+func (r remoteConfigHTTPProvider) cacheFlowRemoteConfig(bb []byte) error {
+	initialConfigHash, err := hashInitialConfig(*r.InitialConfig)
+	if err != nil {
+		return err
+	}
+	marshalled, err := json.Marshal(remoteConfigCache{
+		InitialConfigHash: initialConfigHash,
+		Config:            string(bb),
+	})
+	if err != nil {
+		return fmt.Errorf("could not marshal remote config cache: %w", err)
+	}
+	return os.WriteFile(filepath.Join(r.InitialConfig.CacheLocation, flowCacheFilename), marshalled, 0666)
+}
+
+// restoreFlowCache restores previous as the cached River config after the
+// config which replaced it failed to load. The cache is removed if previous is
+// nil.
+func (r remoteConfigHTTPProvider) restoreFlowCache(previous []byte) error {
+	if previous == nil {
+		err := os.Remove(filepath.Join(r.InitialConfig.CacheLocation, flowCacheFilename))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not remove remote config cache: %w", err)
+		}
+		return nil
+	}
+	return r.cacheFlowRemoteConfig(previous)
+}
+
+// reportFlowStatus records status on the poller of r for
+// GetRemoteConfigState and reports it through r. Failures are logged, but
+// otherwise ignored.
+func (r remoteConfigHTTPProvider) reportFlowStatus(logger log.Logger, status remoteConfigStatus) {
+	r.poller.recordStatus(status)
+	if err := r.ReportStatus(status); err != nil {
+		level.Warn(logger).Log("msg", "could not report remote config status", "err", err)
+	}
+}
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAgentManagementFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+agent_management:
+  api_url: "https://localhost:1234/example/api"
+  basic_auth:
+    username: test
+    password_file: /test/path
+  protocol: https
+  polling_interval: 1m
+  remote_config_cache_location: /test/path/
+  remote_configuration:
+    namespace: test_namespace
+`), 0600))
+
+	am, err := LoadAgentManagementFile(path)
+	require.NoError(t, err)
+	require.True(t, am.Enabled)
+	require.Equal(t, "test_namespace", am.RemoteConfiguration.Namespace)
+
+	require.NoError(t, os.WriteFile(path, []byte(`
+agent_management:
+  api_url: "s3://bucket/configs"
+  protocol: s3
+  polling_interval: 1m
+  remote_config_cache_location: /test/path/
+  remote_configuration:
+    namespace: test_namespace
+`), 0600))
+	_, err = LoadAgentManagementFile(path)
+	require.EqualError(t, err, "unsupported protocol for River remote configs: s3")
+}
+
+func TestGetFlowRemoteConfig(t *testing.T) {
+	var (
+		contentType = ContentTypeRiver
+		body        = `logging { level = "${level}" }`
+		accept      string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret"), 0600))
+
+	am := validAgentManagementConfig
+	am.Url = apiURLs{srv.URL}
+	am.BasicAuth.PasswordFile = passwordFile
+	am.CacheLocation = dir
+	am.TemplateVariables = map[string]string{"level": "debug"}
+
+	var validated []string
+	validate := func(bb []byte) error {
+		validated = append(validated, string(bb))
+		if string(bb) == "invalid" {
+			return errors.New("invalid River config")
+		}
+		return nil
+	}
+
+	// The fetched config is validated and cached after template variables are
+	// substituted.
+	poller := NewRemoteConfigPoller()
+	bb, err := GetFlowRemoteConfig(&am, poller, validate, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Equal(t, ContentTypeRiver, accept)
+	require.Equal(t, `logging { level = "debug" }`, string(bb))
+	provider := poller.flowProvider
+	require.NotNil(t, provider)
+
+	// The config is only reported as applied once it was loaded.
+	require.Empty(t, GetRemoteConfigState(poller).ConfigHash)
+	require.NoError(t, poller.RemoteConfigApplied(nil))
+	require.Equal(t, hashRemoteConfig(bb), GetRemoteConfigState(poller).ConfigHash)
+
+	// Invalid configs fall back to the cache.
+	body = "invalid"
+	bb, err = GetFlowRemoteConfig(&am, poller, validate, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Equal(t, `logging { level = "debug" }`, string(bb))
+
+	// So do configs which aren't River configs.
+	contentType, body = contentTypeYAML, "server: {}"
+	bb, err = GetFlowRemoteConfig(&am, poller, validate, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Equal(t, `logging { level = "debug" }`, string(bb))
+
+	require.Equal(t, []string{
+		`logging { level = "debug" }`,
+		"invalid",
+		`logging { level = "debug" }`,
+		`logging { level = "debug" }`,
+	}, validated)
+
+	// The provider is only built once.
+	require.Same(t, provider, poller.flowProvider)
+}
+
+func TestGetFlowRemoteConfig_LoadFails(t *testing.T) {
+	lastModified := "Wed, 21 Oct 2015 07:28:00 GMT"
+	body := `logging { level = "info" }`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", ContentTypeRiver)
+		w.Header().Set("Last-Modified", lastModified)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret"), 0600))
+
+	am := validAgentManagementConfig
+	am.Url = apiURLs{srv.URL}
+	am.BasicAuth.PasswordFile = passwordFile
+	am.CacheLocation = dir
+
+	provider, err := newRemoteConfigHTTPProvider(&Config{AgentManagement: am})
+	require.NoError(t, err)
+	validate := func([]byte) error { return nil }
+
+	poller := NewRemoteConfigPoller()
+	_, err = GetFlowRemoteConfig(&am, poller, validate, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, poller.RemoteConfigApplied(nil))
+
+	// A config which fails to load is removed from the cache, and fetched and
+	// loaded again by the next poll.
+	goodBody := body
+	body = `logging { level = "debug" }`
+	lastModified = "Thu, 22 Oct 2015 07:28:00 GMT"
+	_, err = GetFlowRemoteConfig(&am, poller, validate, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, poller.RemoteConfigApplied(errors.New("load failed")))
+
+	cached, err := provider.readFlowCache()
+	require.NoError(t, err)
+	require.Equal(t, goodBody, string(cached))
+	require.Contains(t, GetRemoteConfigState(poller).LastError, "load failed")
+
+	bb, err := GetFlowRemoteConfig(&am, poller, validate, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Equal(t, body, string(bb))
+}
+
+func TestFetchRemoteConfig_River(t *testing.T) {
+	var accept string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		w.Header().Set("Content-Type", ContentTypeRiver+"; charset=utf-8")
+		_, _ = w.Write([]byte(`logging {}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret"), 0600))
+
+	var cfg Config
+	cfg.AgentManagement = validAgentManagementConfig
+	cfg.AgentManagement.Url = apiURLs{srv.URL}
+	cfg.AgentManagement.BasicAuth.PasswordFile = passwordFile
+	cfg.AgentManagement.CacheLocation = dir
+
+	provider, err := newRemoteConfigHTTPProvider(&cfg)
+	require.NoError(t, err)
+
+	// Static mode can't load River configs.
+	_, err = provider.FetchRemoteConfig()
+	require.EqualError(t, err, "remote config is a River config, which can only be loaded in Flow mode")
+	require.Equal(t, contentTypeYAML, accept)
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/grafana/agent/pkg/agentid"
+	"github.com/prometheus/common/config"
+)
+
+const (
+	protocolGit = "git"
+
+	// gitRepositoryDir is the directory below the cache location where the
+	// repository holding remote configs is cloned to.
+	gitRepositoryDir = "remote-config-git"
+
+	// DefaultGitBranch is the branch remote configs are read from if neither
+	// a branch nor a revision is configured.
+	DefaultGitBranch = "main"
+)
+
+// GitConfig configures reading remote configs from a Git repository when the
+// agent management protocol is git.
+type GitConfig struct {
+	// Branch to read remote configs from. Defaults to DefaultGitBranch.
+	Branch string `yaml:"branch,omitempty"`
+	// Revision, if set, pins remote configs to a commit hash or tag instead of
+	// the latest commit of Branch.
+	Revision string `yaml:"revision,omitempty"`
+	// Path is the directory in the repository which holds remote configs.
+	// Defaults to the root of the repository.
+	Path string `yaml:"path,omitempty"`
+}
+
+func (gc *GitConfig) branch() string {
+	if gc == nil || gc.Branch == "" {
+		return DefaultGitBranch
+	}
+	return gc.Branch
+}
+
+func (gc *GitConfig) revision() string {
+	if gc == nil {
+		return ""
+	}
+	return gc.Revision
+}
+
+func (gc *GitConfig) path() string {
+	if gc == nil {
+		return ""
+	}
+	return gc.Path
+}
+
+// validateGit checks settings which are specific to reading remote configs
+// from a Git repository.
+func (am *AgentManagementConfig) validateGit() error {
+	if am.Url.primary() == "" {
+		return errors.New("'agent_management.api_url' must be set to the URL of the repository when using the git protocol")
+	}
+	if am.BasicAuth != (config.BasicAuth{}) && (am.BasicAuth.Username == "" || am.BasicAuth.PasswordFile == "") {
+		return errors.New("both username and password_file fields must be specified")
+	}
+	if am.HTTPClientConfig != nil {
+		return errors.New("'agent_management.http_client_config' is not supported with the git protocol")
+	}
+	if am.SignatureVerification != nil {
+		return errors.New("'agent_management.signature_verification' is not supported with the git protocol")
+	}
+	return nil
+}
+
+// remoteConfigGitProvider reads remote configs from a Git repository instead
+// of the Agent Management API. The repository is cloned to the cache location
+// and updated on every fetch. Caching the remote config works the same way as
+// for remoteConfigHTTPProvider.
+type remoteConfigGitProvider struct {
+	remoteConfigHTTPProvider
+}
+
+func newRemoteConfigGitProvider(c *Config) (*remoteConfigGitProvider, error) {
+	httpProvider, err := newRemoteConfigHTTPProvider(c)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteConfigGitProvider{remoteConfigHTTPProvider: *httpProvider}, nil
+}
+
+// FetchRemoteConfig updates the clone of the repository and reads the raw
+// bytes of the config identified by the namespace and labels in
+// r.InitialConfig at the configured branch or revision.
+//
+// ErrRemoteConfigNotModified is returned if the config is unchanged since it
+// was last loaded.
+func (r remoteConfigGitProvider) FetchRemoteConfig() ([]byte, error) {
+	gc := r.InitialConfig.Git
+
+	auth, err := r.InitialConfig.gitAuth()
+	if err != nil {
+		return nil, err
+	}
+	repo, err := r.openRepository(auth)
+	if err != nil {
+		return nil, fmt.Errorf("error updating git repository: %w", err)
+	}
+
+	rev := gc.revision()
+	if rev == "" {
+		rev = plumbing.NewRemoteReferenceName(git.DefaultRemoteName, gc.branch()).String()
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("error resolving git revision %q: %w", rev, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("error reading git commit %s: %w", hash, err)
+	}
+
+	var extraLabels map[string]string
+	if r.AgentID != "" && r.InitialConfig.RemoteConfiguration.AgentIDAsLabel {
+		extraLabels = map[string]string{agentid.LabelName: r.AgentID}
+	}
+	key := strings.TrimPrefix(r.InitialConfig.remoteConfigKey(gc.path(), extraLabels), "/")
+
+	file, err := commit.File(key)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s at git commit %s: %w", key, hash, err)
+	}
+
+	// The hash of the file identifies its contents, so it's used to detect
+	// unchanged configs the same way as an ETag.
+	initialConfigHash, err := hashInitialConfig(*r.InitialConfig)
+	if err != nil {
+		return nil, err
+	}
+	validators := cacheValidators{ETag: file.Hash.String()}
+	if r.poller.remoteConfigValidators(initialConfigHash) == validators {
+		return nil, ErrRemoteConfigNotModified
+	}
+
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s at git commit %s: %w", key, hash, err)
+	}
+	r.poller.setRemoteConfigValidators(initialConfigHash, validators)
+	return []byte(contents), nil
+}
+
+// openRepository opens the clone of the repository and fetches the latest
+// changes, cloning the repository first if needed.
+func (r remoteConfigGitProvider) openRepository(auth transport.AuthMethod) (*git.Repository, error) {
+	dir := filepath.Join(r.InitialConfig.CacheLocation, gitRepositoryDir)
+
+	repo, err := git.PlainOpen(dir)
+	if err == nil && !hasRemoteURL(repo, r.InitialConfig.Url.primary()) {
+		// The repository changed; start over with a new clone.
+		if err := os.RemoveAll(dir); err != nil {
+			return nil, err
+		}
+		err = git.ErrRepositoryNotExists
+	}
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return git.PlainClone(dir, true, &git.CloneOptions{
+			URL:  r.InitialConfig.Url.primary(),
+			Auth: auth,
+			Tags: git.AllTags,
+		})
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = repo.Fetch(&git.FetchOptions{
+		Auth: auth,
+		Tags: git.AllTags,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// hasRemoteURL returns true if the default remote of repo points at url.
+func hasRemoteURL(repo *git.Repository, url string) bool {
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return false
+	}
+	urls := remote.Config().URLs
+	return len(urls) == 1 && urls[0] == url
+}
+
+// gitAuth returns the credentials to access the repository with. Only
+// basic_auth is supported.
+func (am *AgentManagementConfig) gitAuth() (transport.AuthMethod, error) {
+	if am.BasicAuth.Username == "" {
+		return nil, nil
+	}
+	password, err := os.ReadFile(am.BasicAuth.PasswordFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading password file: %w", err)
+	}
+	return &githttp.BasicAuth{
+		Username: am.BasicAuth.Username,
+		Password: strings.TrimSpace(string(password)),
+	}, nil
+}
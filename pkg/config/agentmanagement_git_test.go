@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/prometheus/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateGit(t *testing.T) {
+	cfg := validAgentManagementConfig
+	cfg.Protocol = protocolGit
+	cfg.Url = apiURLs{"https://git.example.com/fleet.git"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.BasicAuth = config.BasicAuth{}
+	assert.NoError(t, cfg.Validate(), "basic_auth must be optional")
+
+	cfg.BasicAuth.Username = "test"
+	assert.EqualError(t, cfg.Validate(), "both username and password_file fields must be specified")
+
+	cfg.BasicAuth = config.BasicAuth{}
+	cfg.HTTPClientConfig = &config.HTTPClientConfig{}
+	assert.EqualError(t, cfg.Validate(), "'agent_management.http_client_config' is not supported with the git protocol")
+}
+
+func TestFetchRemoteConfig_Git(t *testing.T) {
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+
+	const key = "agents/namespace/test_namespace/remote_config/a=A,b=B.yaml"
+	commit := func(contents string) string {
+		path := filepath.Join(repoDir, filepath.FromSlash(key))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+		_, err := wt.Add(key)
+		require.NoError(t, err)
+		hash, err := wt.Commit("update config", &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+		return hash.String()
+	}
+	first := commit("base_config: 'first'")
+
+	// Name the branch explicitly since the default branch of new repositories
+	// depends on the local git configuration.
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	var cfg Config
+	cfg.AgentManagement = validAgentManagementConfig
+	cfg.AgentManagement.Protocol = protocolGit
+	cfg.AgentManagement.Url = apiURLs{repoDir}
+	cfg.AgentManagement.BasicAuth = config.BasicAuth{}
+	cfg.AgentManagement.CacheLocation = t.TempDir()
+	cfg.AgentManagement.Git = &GitConfig{Branch: head.Name().Short(), Path: "agents"}
+
+	poller := NewRemoteConfigPoller()
+	provider, err := newRemoteConfigProvider(&cfg, poller)
+	require.NoError(t, err)
+
+	bb, err := provider.FetchRemoteConfig()
+	require.NoError(t, err)
+	require.Equal(t, "base_config: 'first'", string(bb))
+
+	_, err = provider.FetchRemoteConfig()
+	require.ErrorIs(t, err, ErrRemoteConfigNotModified)
+
+	// New commits are picked up by the next fetch.
+	commit("base_config: 'second'")
+	bb, err = provider.FetchRemoteConfig()
+	require.NoError(t, err)
+	require.Equal(t, "base_config: 'second'", string(bb))
+
+	// Pinning a revision reads the config at that commit.
+	cfg.AgentManagement.Git.Revision = first
+	provider, err = newRemoteConfigProvider(&cfg, poller)
+	require.NoError(t, err)
+	bb, err = provider.FetchRemoteConfig()
+	require.NoError(t, err)
+	require.Equal(t, "base_config: 'first'", string(bb))
+}
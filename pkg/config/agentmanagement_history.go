@@ -0,0 +1,269 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// remoteConfigHistoryDir is the directory in the cache location holding
+	// the previously cached versions of the remote config.
+	remoteConfigHistoryDir = "remote-config-cache-history"
+	// remoteConfigRollbackFilename is the file in the cache location which
+	// records the remote config that was rolled back from.
+	remoteConfigRollbackFilename = "remote-config-rollback.json"
+	// remoteConfigVersionFormat formats the timestamps identifying versions of
+	// the remote config. Versions sort in the order they were cached.
+	remoteConfigVersionFormat = "20060102T150405.000000000Z"
+
+	// defaultRemoteConfigCacheHistory is the number of cached remote configs
+	// which are kept if 'agent_management.remote_config_cache_history' is
+	// unset.
+	defaultRemoteConfigCacheHistory = 5
+)
+
+// ErrUnknownRemoteConfigVersion is returned when rolling back to a version of
+// the remote config which isn't in the cache history.
+var ErrUnknownRemoteConfigVersion = errors.New("unknown remote config version")
+
+// errRemoteConfigRolledBack is the reason reported for loading the cached
+// remote config while the fetched remote config is the one which was rolled
+// back from.
+var errRemoteConfigRolledBack = errors.New("remote config was rolled back to a cached version")
+
+// RemoteConfigVersion describes a version of the remote config kept in the
+// cache history.
+type RemoteConfigVersion struct {
+	// Version identifies the version when rolling back to it.
+	Version  string    `json:"version"`
+	CachedAt time.Time `json:"cached_at"`
+	// ConfigHash is the SHA-256 hash of the raw remote config, as reported to
+	// 'agent_management.status_url'.
+	ConfigHash string `json:"config_hash"`
+	// Current is true for the version which is currently cached.
+	Current bool `json:"current"`
+}
+
+// remoteConfigRollback records a rollback to a cached version of the remote
+// config.
+type remoteConfigRollback struct {
+	InitialConfigHash string `json:"initial_config_hash"`
+	// RolledBackConfigHash is the hash of the remote config which was rolled
+	// back from. The cached config is loaded instead as long as the API serves
+	// this remote config.
+	RolledBackConfigHash string `json:"rolled_back_config_hash"`
+	Version              string `json:"version"`
+}
+
+// cacheHistory returns the number of cached remote configs to keep.
+func (am *AgentManagementConfig) cacheHistory() int {
+	if am.CacheHistory == 0 {
+		return defaultRemoteConfigCacheHistory
+	}
+	return am.CacheHistory
+}
+
+// remoteConfigHistoryEntry is a version of the remote config in the cache
+// history.
+type remoteConfigHistoryEntry struct {
+	version string
+	cache   remoteConfigCache
+}
+
+// readRemoteConfigHistory reads the cache history from cacheLocation, newest
+// version first.
+func readRemoteConfigHistory(cacheLocation string) ([]remoteConfigHistoryEntry, error) {
+	dir := filepath.Join(cacheLocation, remoteConfigHistoryDir)
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading remote config cache history: %w", err)
+	}
+
+	var entries []remoteConfigHistoryEntry
+	for _, f := range files {
+		version := strings.TrimSuffix(f.Name(), ".json")
+		if f.IsDir() || version == f.Name() {
+			continue
+		}
+		if _, err := time.Parse(remoteConfigVersionFormat, version); err != nil {
+			continue
+		}
+		buf, err := os.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error reading remote config cache history: %w", err)
+		}
+		entry := remoteConfigHistoryEntry{version: version}
+		if err := json.Unmarshal(buf, &entry.cache); err != nil {
+			return nil, fmt.Errorf("error trying to load cached remote config version %s: %w", version, err)
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].version > entries[j].version
+	})
+	return entries, nil
+}
+
+// writeRemoteConfigHistory adds configCache to the cache history as the
+// version cached at now, unless it's the same as the newest version. Only the
+// newest keep versions are kept.
+func writeRemoteConfigHistory(cacheLocation string, configCache remoteConfigCache, keep int, now time.Time) error {
+	entries, err := readRemoteConfigHistory(cacheLocation)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(cacheLocation, remoteConfigHistoryDir)
+	if len(entries) == 0 || entries[0].cache != configCache {
+		if err := os.MkdirAll(dir, 0777); err != nil {
+			return fmt.Errorf("could not create remote config cache history: %w", err)
+		}
+		marshalled, err := json.Marshal(configCache)
+		if err != nil {
+			return fmt.Errorf("could not marshal remote config cache: %w", err)
+		}
+		version := now.UTC().Format(remoteConfigVersionFormat)
+		if err := os.WriteFile(filepath.Join(dir, version+".json"), marshalled, 0666); err != nil {
+			return err
+		}
+		entries = append([]remoteConfigHistoryEntry{{version: version, cache: configCache}}, entries...)
+	}
+
+	if len(entries) <= keep {
+		return nil
+	}
+	for _, entry := range entries[keep:] {
+		if err := os.Remove(filepath.Join(dir, entry.version+".json")); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not remove old remote config version %s: %w", entry.version, err)
+		}
+	}
+	return nil
+}
+
+// RemoteConfigVersions lists the versions of the remote config kept in the
+// cache history for the initial config c, newest version first. Versions
+// cached for a different initial config are left out, since they can't be
+// rolled back to.
+func RemoteConfigVersions(c *Config) ([]RemoteConfigVersion, error) {
+	r, err := newRemoteConfigHTTPProvider(c)
+	if err != nil {
+		return nil, err
+	}
+	initialConfigHash, err := hashInitialConfig(*r.InitialConfig)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readRemoteConfigHistory(r.InitialConfig.CacheLocation)
+	if err != nil {
+		return nil, err
+	}
+	current, _ := r.GetCachedRemoteConfig()
+
+	versions := []RemoteConfigVersion{}
+	for _, entry := range entries {
+		if entry.cache.InitialConfigHash != initialConfigHash {
+			continue
+		}
+		cachedAt, _ := time.Parse(remoteConfigVersionFormat, entry.version)
+		versions = append(versions, RemoteConfigVersion{
+			Version:    entry.version,
+			CachedAt:   cachedAt,
+			ConfigHash: hashRemoteConfig([]byte(entry.cache.Config)),
+			Current:    current != nil && string(current) == entry.cache.Config,
+		})
+	}
+	return versions, nil
+}
+
+// RollbackRemoteConfig replaces the cached remote config for the initial
+// config c with the given version from the cache history. The cached config
+// is loaded instead of the remote config on the next reload, and keeps being
+// loaded until the API serves a remote config other than the one which was
+// rolled back from. poller must be the one the config is reloaded with.
+func RollbackRemoteConfig(c *Config, poller *RemoteConfigPoller, version string) error {
+	r, err := newRemoteConfigHTTPProvider(c)
+	if err != nil {
+		return err
+	}
+	initialConfigHash, err := hashInitialConfig(*r.InitialConfig)
+	if err != nil {
+		return err
+	}
+	entries, err := readRemoteConfigHistory(r.InitialConfig.CacheLocation)
+	if err != nil {
+		return err
+	}
+
+	var target *remoteConfigCache
+	for _, entry := range entries {
+		if entry.version == version && entry.cache.InitialConfigHash == initialConfigHash {
+			target = &entry.cache
+			break
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownRemoteConfigVersion, version)
+	}
+
+	rollback := remoteConfigRollback{
+		InitialConfigHash: initialConfigHash,
+		Version:           version,
+	}
+	if current, err := r.GetCachedRemoteConfig(); err == nil {
+		rollback.RolledBackConfigHash = hashRemoteConfig(current)
+	}
+
+	if err := r.writeCache(*target); err != nil {
+		return err
+	}
+	marshalled, err := json.Marshal(rollback)
+	if err != nil {
+		return fmt.Errorf("could not marshal remote config rollback: %w", err)
+	}
+	rollbackPath := filepath.Join(r.InitialConfig.CacheLocation, remoteConfigRollbackFilename)
+	if err := os.WriteFile(rollbackPath, marshalled, 0666); err != nil {
+		return err
+	}
+
+	// Forget the validators of the remote config which was rolled back from,
+	// so that the next reload isn't skipped as unchanged.
+	poller.setRemoteConfigValidators("", cacheValidators{})
+	return nil
+}
+
+// RolledBackConfigHash returns the hash of the remote config which was rolled
+// back from, or an empty string if the cached remote config wasn't rolled
+// back for the current initial config.
+func (r remoteConfigHTTPProvider) RolledBackConfigHash() string {
+	buf, err := os.ReadFile(filepath.Join(r.InitialConfig.CacheLocation, remoteConfigRollbackFilename))
+	if err != nil {
+		return ""
+	}
+	var rollback remoteConfigRollback
+	if err := json.Unmarshal(buf, &rollback); err != nil {
+		return ""
+	}
+	initialConfigHash, err := hashInitialConfig(*r.InitialConfig)
+	if err != nil || rollback.InitialConfigHash != initialConfigHash {
+		return ""
+	}
+	return rollback.RolledBackConfigHash
+}
+
+// clearRollback removes the record of a rollback once a new remote config is
+// cached.
+func (r remoteConfigHTTPProvider) clearRollback() error {
+	err := os.Remove(filepath.Join(r.InitialConfig.CacheLocation, remoteConfigRollbackFilename))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not remove remote config rollback: %w", err)
+	}
+	return nil
+}
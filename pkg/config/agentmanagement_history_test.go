@@ -0,0 +1,99 @@
+package config
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/pkg/config/features"
+	"github.com/grafana/agent/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteRemoteConfigHistory(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, config := range []string{"a", "b", "b", "c"} {
+		configCache := remoteConfigCache{InitialConfigHash: "hash", Config: config}
+		require.NoError(t, writeRemoteConfigHistory(dir, configCache, 2, now.Add(time.Duration(i)*time.Minute)))
+	}
+
+	// The duplicate version is skipped and only the newest 2 versions are
+	// kept.
+	entries, err := readRemoteConfigHistory(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "20230101T000300.000000000Z", entries[0].version)
+	require.Equal(t, "c", entries[0].cache.Config)
+	require.Equal(t, "20230101T000100.000000000Z", entries[1].version)
+	require.Equal(t, "b", entries[1].cache.Config)
+}
+
+func TestRollbackRemoteConfig(t *testing.T) {
+	var cfg Config
+	cfg.AgentManagement = validAgentManagementConfig
+	cfg.AgentManagement.CacheLocation = t.TempDir()
+
+	provider, err := newRemoteConfigHTTPProvider(&cfg)
+	require.NoError(t, err)
+
+	goodConfig, badConfig := []byte("base_config: 'good'"), []byte("base_config: 'bad'")
+	require.NoError(t, provider.CacheRemoteConfig(goodConfig))
+	time.Sleep(time.Millisecond)
+	require.NoError(t, provider.CacheRemoteConfig(badConfig))
+
+	versions, err := RemoteConfigVersions(&cfg)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	require.True(t, versions[0].Current)
+	require.Equal(t, hashRemoteConfig(badConfig), versions[0].ConfigHash)
+	require.False(t, versions[1].Current)
+	require.Equal(t, hashRemoteConfig(goodConfig), versions[1].ConfigHash)
+
+	require.ErrorIs(t, RollbackRemoteConfig(&cfg, NewRemoteConfigPoller(), "20000101T000000.000000000Z"), ErrUnknownRemoteConfigVersion)
+
+	require.NoError(t, RollbackRemoteConfig(&cfg, NewRemoteConfigPoller(), versions[1].Version))
+	cached, err := provider.GetCachedRemoteConfig()
+	require.NoError(t, err)
+	require.Equal(t, goodConfig, cached)
+	require.Equal(t, hashRemoteConfig(badConfig), provider.RolledBackConfigHash())
+
+	// Caching a new remote config clears the rollback.
+	require.NoError(t, provider.CacheRemoteConfig([]byte("base_config: 'fixed'")))
+	require.Empty(t, provider.RolledBackConfigHash())
+}
+
+func TestGetRemoteConfig_RolledBack(t *testing.T) {
+	defaultCfg := DefaultConfig()
+	fetchedConfig := []byte(`
+base_config: |
+  server:
+    log_level: debug
+snippets: []
+`)
+
+	am := validAgentManagementConfig
+	logger := server.NewLogger(defaultCfg.Server)
+	testProvider := testRemoteConfigProvider{InitialConfig: &am}
+	testProvider.fetchedConfigBytesToReturn = fetchedConfig
+	testProvider.cachedConfigToReturn = cachedConfig
+	testProvider.rolledBackConfigHash = hashRemoteConfig(fetchedConfig)
+
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	features.Register(fs, allFeatures)
+	defaultCfg.RegisterFlags(fs)
+
+	// The cached config is loaded while the API serves the config which was
+	// rolled back from.
+	poller := NewRemoteConfigPoller()
+	cfg, configHash, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Poller: poller, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
+	require.NoError(t, err)
+	require.NoError(t, poller.RemoteConfigApplied(nil))
+	assert.False(t, testProvider.didCacheRemoteConfig)
+	assert.NotEqual(t, "debug", cfg.Server.LogLevel.String())
+	assert.Equal(t, hashRemoteConfig(cachedConfig), configHash)
+	require.Len(t, testProvider.reportedStatuses, 1)
+	assert.Equal(t, remoteConfigSourceCache, testProvider.reportedStatuses[0].Source)
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hairyhenderson/gomplate/v3/loader"
+)
+
+// render returns a copy of l with label values rendered as gomplate
+// templates, so that labels can describe the node the agent is running on
+// without per-host configs. For example:
+//
+//	labels:
+//	  hostname: '{{ env.Getenv "HOSTNAME" }}'
+//	  instance_id: '{{ aws.EC2Meta "instance-id" }}'
+//	  zone: '{{ gcp.Meta "zone" }}'
+//	  pod_namespace: '{{ file.Read "/etc/podinfo/namespace" }}'
+//
+// Values which don't contain a template are returned as-is. Leading and
+// trailing whitespace is removed from rendered values.
+func (l labelMap) render() (labelMap, error) {
+	var cl *loader.ConfigLoader
+
+	out := make(labelMap, len(l))
+	for name, value := range l {
+		if !strings.Contains(value, "{{") {
+			out[name] = value
+			continue
+		}
+
+		if cl == nil {
+			cl = loader.NewConfigLoader(context.Background(), nil)
+		}
+		rendered, err := cl.GenerateTemplate(name, value)
+		if err != nil {
+			return nil, fmt.Errorf("error rendering value of label %q: %w", name, err)
+		}
+		out[name] = strings.TrimSpace(rendered)
+	}
+	return out, nil
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelMapRender(t *testing.T) {
+	t.Setenv("TEST_NODE_NAME", "node-1")
+
+	// Kubernetes downward API volumes expose pod metadata as files.
+	namespaceFile := filepath.Join(t.TempDir(), "namespace")
+	require.NoError(t, os.WriteFile(namespaceFile, []byte("monitoring\n"), 0644))
+
+	labels := labelMap{
+		"static":    "value",
+		"node":      `{{ env.Getenv "TEST_NODE_NAME" }}`,
+		"namespace": `{{ file.Read "` + filepath.ToSlash(namespaceFile) + `" }}`,
+		"default":   `{{ env.Getenv "TEST_MISSING_ENV" "fallback" }}`,
+	}
+	rendered, err := labels.render()
+	require.NoError(t, err)
+	require.Equal(t, labelMap{
+		"static":    "value",
+		"node":      "node-1",
+		"namespace": "monitoring",
+		"default":   "fallback",
+	}, rendered)
+
+	// The original labels must be left untouched.
+	require.Equal(t, `{{ env.Getenv "TEST_NODE_NAME" }}`, labels["node"])
+
+	_, err = labelMap{"broken": `{{ env.Getenv "X"`}.render()
+	require.ErrorContains(t, err, `error rendering value of label "broken"`)
+}
+
+func TestNewRemoteConfigProvider_RendersLabels(t *testing.T) {
+	t.Setenv("TEST_NODE_NAME", "node-1")
+
+	var cfg Config
+	cfg.AgentManagement = validAgentManagementConfig
+	cfg.AgentManagement.Protocol = "http"
+	cfg.AgentManagement.CacheLocation = t.TempDir()
+	cfg.AgentManagement.RemoteConfiguration.Labels = labelMap{"node": `{{ env.Getenv "TEST_NODE_NAME" }}`}
+
+	provider, err := newRemoteConfigHTTPProvider(&cfg)
+	require.NoError(t, err)
+
+	url, err := provider.InitialConfig.fullUrl()
+	require.NoError(t, err)
+	require.Equal(t, "https://localhost:1234/example/api/namespace/test_namespace/remote_config?node=node-1", url)
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/grafana/agent/pkg/agentid"
+	"github.com/hairyhenderson/gomplate/v3/data"
+)
+
+// supported agent management protocols for object storage
+const (
+	protocolS3  = "s3"
+	protocolGCS = "gs"
+)
+
+// isObjectStorageProtocol returns true if remote configs for protocol are read
+// from an object storage bucket rather than an HTTP API.
+func isObjectStorageProtocol(protocol string) bool {
+	return protocol == protocolS3 || protocol == protocolGCS
+}
+
+// readBlob reads an object from object storage. It's a variable so it can be
+// replaced in tests.
+var readBlob = data.ReadBlob
+
+// remoteConfigObjectStorageProvider reads remote configs from an S3 or GCS
+// bucket instead of the Agent Management API. Caching the remote config works
+// the same way as for remoteConfigHTTPProvider.
+type remoteConfigObjectStorageProvider struct {
+	remoteConfigHTTPProvider
+}
+
+func newRemoteConfigObjectStorageProvider(c *Config) (*remoteConfigObjectStorageProvider, error) {
+	httpProvider, err := newRemoteConfigHTTPProvider(c)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteConfigObjectStorageProvider{remoteConfigHTTPProvider: *httpProvider}, nil
+}
+
+// FetchRemoteConfig reads the raw bytes of the config from the object
+// identified by the namespace and labels in r.InitialConfig.
+func (r remoteConfigObjectStorageProvider) FetchRemoteConfig() ([]byte, error) {
+	var extraLabels map[string]string
+	if r.AgentID != "" && r.InitialConfig.RemoteConfiguration.AgentIDAsLabel {
+		extraLabels = map[string]string{agentid.LabelName: r.AgentID}
+	}
+
+	u, err := r.InitialConfig.objectURL(extraLabels)
+	if err != nil {
+		return nil, fmt.Errorf("error trying to create object url: %w", err)
+	}
+
+	bb, err := readBlob(*u)
+	if err != nil {
+		return nil, fmt.Errorf("error reading remote config from %s: %w", u.Redacted(), err)
+	}
+	return bb, nil
+}
+
+// validateObjectStorage checks settings which are specific to reading remote
+// configs from object storage.
+func (am *AgentManagementConfig) validateObjectStorage() error {
+	u, err := url.Parse(am.Url.primary())
+	if err != nil {
+		return fmt.Errorf("invalid 'agent_management.api_url': %w", err)
+	}
+	if u.Scheme != am.Protocol || u.Host == "" {
+		return fmt.Errorf("'agent_management.api_url' must be of the form %s://<bucket>/<path> when using the %s protocol", am.Protocol, am.Protocol)
+	}
+	if am.SignatureVerification != nil {
+		return fmt.Errorf("'agent_management.signature_verification' is not supported with the %s protocol", am.Protocol)
+	}
+	return nil
+}
+
+// objectURL returns the URL of the object holding the remote config in
+// object storage. The object key is derived from the path of the API URL as
+// described by remoteConfigKey.
+func (am *AgentManagementConfig) objectURL(extraLabels map[string]string) (*url.URL, error) {
+	u, err := url.Parse(am.Url.primary())
+	if err != nil {
+		return nil, fmt.Errorf("error trying to parse url: %w", err)
+	}
+
+	u.Path = path.Join("/", am.remoteConfigKey(u.Path, extraLabels))
+	u.RawPath = ""
+	return u, nil
+}
+
+// remoteConfigKey returns the key of the remote config below prefix in
+// storage which isn't queried with labels, such as object storage or Git
+// repositories. The key is derived from the namespace and the labels of the
+// agent:
+//
+//	<prefix>/namespace/<namespace>/remote_config.yaml
+//	<prefix>/namespace/<namespace>/remote_config/<name>=<value>,<name>=<value>.yaml
+//
+// Labels are sorted by name. extraLabels are added to the configured labels.
+func (am *AgentManagementConfig) remoteConfigKey(prefix string, extraLabels map[string]string) string {
+	labels := make(map[string]string, len(am.RemoteConfiguration.Labels)+len(extraLabels))
+	for name, value := range am.RemoteConfiguration.Labels {
+		labels[name] = value
+	}
+	for name, value := range extraLabels {
+		labels[name] = value
+	}
+
+	key := path.Join(prefix, "namespace", am.RemoteConfiguration.Namespace)
+	if len(labels) == 0 {
+		return path.Join(key, "remote_config.yaml")
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+"="+labels[name])
+	}
+	return path.Join(key, "remote_config", strings.Join(pairs, ",")+".yaml")
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/grafana/agent/pkg/agentid"
+	"github.com/prometheus/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func objectStorageConfig(t *testing.T) AgentManagementConfig {
+	cfg := validAgentManagementConfig
+	cfg.Protocol = protocolS3
+	cfg.Url = apiURLs{"s3://fleet-configs/agents?region=eu-west-1"}
+	cfg.BasicAuth = config.BasicAuth{}
+	cfg.CacheLocation = t.TempDir()
+	return cfg
+}
+
+func TestValidateObjectStorage(t *testing.T) {
+	cfg := objectStorageConfig(t)
+	assert.NoError(t, cfg.Validate(), "basic_auth must not be required")
+
+	cfg.Url = apiURLs{"https://fleet-configs/agents"}
+	assert.EqualError(t, cfg.Validate(), "'agent_management.api_url' must be of the form s3://<bucket>/<path> when using the s3 protocol")
+
+	cfg.Url = apiURLs{"gs://fleet-configs/agents"}
+	cfg.Protocol = protocolGCS
+	assert.NoError(t, cfg.Validate())
+
+	cfg.SignatureVerification = &SignatureVerificationConfig{PublicKeyFile: "/etc/agent/config.pub"}
+	assert.EqualError(t, cfg.Validate(), "'agent_management.signature_verification' is not supported with the gs protocol")
+}
+
+func TestObjectURL(t *testing.T) {
+	cfg := objectStorageConfig(t)
+
+	u, err := cfg.objectURL(nil)
+	require.NoError(t, err)
+	require.Equal(t, "s3://fleet-configs/agents/namespace/test_namespace/remote_config/a=A,b=B.yaml?region=eu-west-1", u.String())
+
+	u, err = cfg.objectURL(map[string]string{"agent_id": "1234"})
+	require.NoError(t, err)
+	require.Equal(t, "s3://fleet-configs/agents/namespace/test_namespace/remote_config/a=A,agent_id=1234,b=B.yaml?region=eu-west-1", u.String())
+
+	cfg.RemoteConfiguration.Labels = nil
+	cfg.Url = apiURLs{"gs://fleet-configs"}
+	u, err = cfg.objectURL(nil)
+	require.NoError(t, err)
+	require.Equal(t, "gs://fleet-configs/namespace/test_namespace/remote_config.yaml", u.String())
+}
+
+func TestFetchRemoteConfig_ObjectStorage(t *testing.T) {
+	var gotURL url.URL
+	oldReadBlob := readBlob
+	readBlob = func(u url.URL) ([]byte, error) {
+		gotURL = u
+		return []byte("base_config: ''"), nil
+	}
+	t.Cleanup(func() { readBlob = oldReadBlob })
+
+	var cfg Config
+	cfg.AgentManagement = objectStorageConfig(t)
+	cfg.AgentManagement.RemoteConfiguration.AgentIDAsLabel = true
+
+	provider, err := newRemoteConfigProvider(&cfg, NewRemoteConfigPoller())
+	require.NoError(t, err)
+
+	bb, err := provider.FetchRemoteConfig()
+	require.NoError(t, err)
+	require.Equal(t, "base_config: ''", string(bb))
+
+	agentID, err := agentid.LoadOrCreate(cfg.AgentManagement.CacheLocation)
+	require.NoError(t, err)
+	require.Equal(t, "/agents/namespace/test_namespace/remote_config/a=A,agent_id="+agentID+",b=B.yaml", gotURL.Path)
+
+	// Caching works the same way as for the HTTP API.
+	require.NoError(t, provider.CacheRemoteConfig(bb))
+	cached, err := provider.GetCachedRemoteConfig()
+	require.NoError(t, err)
+	require.Equal(t, bb, cached)
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// readLocalOverrides reads 'agent_management.local_overrides_file'. No
+// overrides are returned if it's unset.
+func (am *AgentManagementConfig) readLocalOverrides() ([]byte, error) {
+	if am.LocalOverridesFile == "" {
+		return nil, nil
+	}
+	bb, err := os.ReadFile(am.LocalOverridesFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading local overrides file: %w", err)
+	}
+	return bb, nil
+}
+
+// checkLocalOverridesChanged makes poller forget the last loaded remote
+// config if the local overrides changed since they were last read, so that
+// the remote config is fetched and loaded again with the new overrides even
+// if it's unchanged.
+func checkLocalOverridesChanged(poller *RemoteConfigPoller, overrides []byte) {
+	if poller == nil {
+		return
+	}
+	hash := sha256.Sum256(overrides)
+
+	poller.mut.Lock()
+	defer poller.mut.Unlock()
+	if poller.localOverridesHash == hash {
+		return
+	}
+	poller.localOverridesHash = hash
+	poller.initialConfigHash, poller.validators = "", cacheValidators{}
+	poller.loaded = loadedRemoteConfig{}
+}
+
+// applyLocalOverrides deep-merges the YAML document overrides over the base
+// config. Mappings are merged key by key, while any other value in overrides,
+// including lists, replaces the value in the base config.
+func applyLocalOverrides(baseConfig BaseConfigContent, overrides []byte) (BaseConfigContent, error) {
+	if len(overrides) == 0 {
+		return baseConfig, nil
+	}
+
+	var base, over yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(baseConfig), &base); err != nil {
+		return "", fmt.Errorf("could not unmarshal base config: %w", err)
+	}
+	if err := yaml.Unmarshal(overrides, &over); err != nil {
+		return "", fmt.Errorf("could not unmarshal local overrides: %w", err)
+	}
+
+	bb, err := yaml.Marshal(mergeMapSlices(base, over))
+	if err != nil {
+		return "", fmt.Errorf("could not marshal base config with local overrides: %w", err)
+	}
+	return BaseConfigContent(bb), nil
+}
+
+// mergeMapSlices returns dst with the keys of src merged over it.
+func mergeMapSlices(dst, src yaml.MapSlice) yaml.MapSlice {
+	res := make(yaml.MapSlice, len(dst), len(dst)+len(src))
+	copy(res, dst)
+
+NextKey:
+	for _, item := range src {
+		for i := range res {
+			if res[i].Key != item.Key {
+				continue
+			}
+			dstMap, dstOK := res[i].Value.(yaml.MapSlice)
+			srcMap, srcOK := item.Value.(yaml.MapSlice)
+			if dstOK && srcOK {
+				res[i].Value = mergeMapSlices(dstMap, srcMap)
+			} else {
+				res[i].Value = item.Value
+			}
+			continue NextKey
+		}
+		res = append(res, item)
+	}
+	return res
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/grafana/agent/pkg/config/features"
+	"github.com/grafana/agent/pkg/server"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyLocalOverrides(t *testing.T) {
+	base := BaseConfigContent(`
+server:
+  log_level: info
+  http_listen_port: 12345
+metrics:
+  wal_directory: /tmp/wal
+  global:
+    scrape_interval: 15s
+    external_labels:
+      cluster: prod
+`)
+	overrides := []byte(`
+server:
+  http_listen_port: 9090
+metrics:
+  wal_directory: /var/lib/agent/wal
+  global:
+    external_labels:
+      host: host-1
+`)
+
+	res, err := applyLocalOverrides(base, overrides)
+	require.NoError(t, err)
+	require.YAMLEq(t, `
+server:
+  log_level: info
+  http_listen_port: 9090
+metrics:
+  wal_directory: /var/lib/agent/wal
+  global:
+    scrape_interval: 15s
+    external_labels:
+      cluster: prod
+      host: host-1
+`, string(res))
+
+	t.Run("no overrides", func(t *testing.T) {
+		res, err := applyLocalOverrides(base, nil)
+		require.NoError(t, err)
+		require.Equal(t, base, res)
+	})
+
+	t.Run("invalid overrides", func(t *testing.T) {
+		_, err := applyLocalOverrides(base, []byte("- not a mapping"))
+		require.ErrorContains(t, err, "could not unmarshal local overrides")
+	})
+}
+
+func TestGetRemoteConfig_LocalOverrides(t *testing.T) {
+	defaultCfg := DefaultConfig()
+	remoteConfig := `
+base_config: |
+  server:
+    log_level: info
+snippets: []
+`
+
+	am := validAgentManagementConfig
+	logger := server.NewLogger(defaultCfg.Server)
+	testProvider := testRemoteConfigProvider{InitialConfig: &am}
+	testProvider.fetchedConfigBytesToReturn = []byte(remoteConfig)
+	testProvider.cachedConfigToReturn = cachedConfig
+
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	features.Register(fs, allFeatures)
+	defaultCfg.RegisterFlags(fs)
+
+	overrides := []byte("server:\n  log_level: debug\n")
+	poller := NewRemoteConfigPoller()
+	cfg, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, LocalOverrides: overrides, Poller: poller, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
+	require.NoError(t, err)
+	require.NoError(t, poller.RemoteConfigApplied(nil))
+	require.Equal(t, "debug", cfg.Server.LogLevel.String())
+
+	// The raw remote config is cached without the overrides.
+	require.True(t, testProvider.didCacheRemoteConfig)
+	require.Equal(t, hashRemoteConfig([]byte(remoteConfig)), testProvider.reportedStatuses[0].ConfigHash)
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/grafana/loki/clients/pkg/promtail/scrapeconfig"
+	pc "github.com/prometheus/prometheus/config"
+	"gopkg.in/yaml.v2"
+)
+
+// rawSnippetContent holds the scrape configs of a snippet without decoding
+// them, so that every scrape config can be decoded and validated on its own.
+type rawSnippetContent struct {
+	MetricsScrapeConfigs []yaml.MapSlice `yaml:"metrics_scrape_configs,omitempty"`
+	LogsScrapeConfigs    []yaml.MapSlice `yaml:"logs_scrape_configs,omitempty"`
+}
+
+// buildPartialAgentConfig builds an agent configuration from the base config
+// and the valid subset of the scrape configs in rc's snippets. Every scrape
+// config is validated together with the base config and the scrape configs
+// accepted before it; scrape configs which fail are skipped and described in
+// the returned list.
+//
+// The base config must be valid on its own, otherwise an error is returned.
+func (rc *RemoteConfig) buildPartialAgentConfig(fs *flag.FlagSet, args []string, configPath string) (*Config, []string, error) {
+	validate := func(contents []SnippetContent) (*Config, error) {
+		c, err := rc.buildAgentConfig(contents)
+		if err != nil {
+			return nil, err
+		}
+		if err := applyIntegrationValuesFromFlagset(fs, args, configPath, c); err != nil {
+			return nil, err
+		}
+		if err := c.Validate(fs); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+
+	config, err := validate(nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid base config: %w", err)
+	}
+
+	var (
+		accepted []SnippetContent
+		skipped  []string
+	)
+	// try validates the config with content added to the accepted snippets,
+	// and accepts content if it's valid.
+	try := func(content SnippetContent) error {
+		candidate := append(accepted[:len(accepted):len(accepted)], content)
+		c, err := validate(candidate)
+		if err != nil {
+			return err
+		}
+		accepted, config = candidate, c
+		return nil
+	}
+
+	for i, snippet := range rc.Snippets {
+		var raw rawSnippetContent
+		if err := yaml.Unmarshal([]byte(snippet.Config), &raw); err != nil {
+			skipped = append(skipped, fmt.Sprintf("snippet %d: %s", i, err))
+			continue
+		}
+
+		for _, item := range raw.MetricsScrapeConfigs {
+			var sc pc.ScrapeConfig
+			err := remarshal(item, &sc)
+			if err == nil {
+				err = try(SnippetContent{MetricsScrapeConfigs: []*pc.ScrapeConfig{&sc}})
+			}
+			if err != nil {
+				skipped = append(skipped, fmt.Sprintf("snippet %d: metrics scrape config %q: %s", i, jobName(item), err))
+			}
+		}
+		for _, item := range raw.LogsScrapeConfigs {
+			var sc scrapeconfig.Config
+			err := remarshal(item, &sc)
+			if err == nil {
+				err = try(SnippetContent{LogsScrapeConfigs: []scrapeconfig.Config{sc}})
+			}
+			if err != nil {
+				skipped = append(skipped, fmt.Sprintf("snippet %d: logs scrape config %q: %s", i, jobName(item), err))
+			}
+		}
+	}
+	return config, skipped, nil
+}
+
+// remarshal decodes the YAML value in into out.
+func remarshal(in interface{}, out interface{}) error {
+	bb, err := yaml.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(bb, out)
+}
+
+// jobName returns the job_name of a raw scrape config, or an empty string if
+// it has none.
+func jobName(item yaml.MapSlice) string {
+	for _, kv := range item {
+		if kv.Key == "job_name" {
+			return fmt.Sprint(kv.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/grafana/agent/pkg/config/instrumentation"
+)
+
+// RemoteConfigPoller holds the state of polling the remote config: how many
+// fetches failed in a row, whether the circuit breaker is open, the cache
+// validators and hash of the last remote config which was loaded, and the
+// state served by GetRemoteConfigState. It's owned by the loop which polls
+// the remote config, and should be used for as long as the agent runs.
+//
+// A nil *RemoteConfigPoller keeps no state: requests aren't conditional,
+// polling never backs off, and every remote config is loaded as if it
+// changed.
+type RemoteConfigPoller struct {
+	// fetchFailures counts how many times in a row fetching the remote config
+	// has failed.
+	fetchFailures atomic.Int64
+	// circuitOpen is set while the circuit breaker is open.
+	circuitOpen atomic.Bool
+
+	// vault holds the Vault client used to read secrets, so that the token
+	// renewal schedule survives across fetches.
+	vault vaultClientCache
+
+	mut sync.Mutex
+	// initialConfigHash and validators are the cache validators of the last
+	// remote config which was fetched and loaded successfully. They're only
+	// used while the initial config is unchanged, identified by
+	// initialConfigHash.
+	initialConfigHash string
+	validators        cacheValidators
+	// loaded identifies the remote config which was last loaded.
+	loaded loadedRemoteConfig
+	// localOverridesHash is the hash of the local overrides which were last
+	// read.
+	localOverridesHash [sha256.Size]byte
+	// state is returned by GetRemoteConfigState.
+	state RemoteConfigState
+	// startedCanaries holds the hashes of the canary remote configs which
+	// were started by this poller.
+	startedCanaries map[string]struct{}
+	// clusterShard returns the shard of the agent sent in requests for remote
+	// configs.
+	clusterShard ClusterShardFunc
+	// pending is the remote config which was last loaded, until the outcome
+	// of applying it is recorded by RemoteConfigApplied.
+	pending *pendingRemoteConfig
+	// flowProvider fetches River configs in Flow mode. It's built once for
+	// flowProviderConfig.
+	flowProvider       *remoteConfigHTTPProvider
+	flowProviderConfig *AgentManagementConfig
+}
+
+// pendingRemoteConfig is a remote config which was loaded, but not applied
+// yet.
+type pendingRemoteConfig struct {
+	// status is reported through report once the pending remote config is
+	// applied.
+	status remoteConfigStatus
+	report func(remoteConfigStatus)
+	// restoreCache restores the cached remote config which was replaced by
+	// the pending remote config. It's nil if the cache wasn't changed.
+	restoreCache func() error
+}
+
+// NewRemoteConfigPoller returns a RemoteConfigPoller which hasn't fetched the
+// remote config yet.
+func NewRemoteConfigPoller() *RemoteConfigPoller {
+	return &RemoteConfigPoller{}
+}
+
+// SleepTime returns the duration to wait before the next fetch of the remote
+// config, given the polling settings in am and the failures of the previous
+// fetches. It opens or closes the circuit breaker accordingly.
+func (p *RemoteConfigPoller) SleepTime(am *AgentManagementConfig) time.Duration {
+	var failures int64
+	if p != nil {
+		failures = p.fetchFailures.Load()
+	}
+
+	open := am.circuitBreakerOpen(failures)
+	if p != nil {
+		p.circuitOpen.Store(open)
+	}
+	instrumentation.InstrumentRemoteConfigCircuitBreaker(open)
+	return am.SleepTime(failures)
+}
+
+// CircuitBreakerOpen returns true if the circuit breaker was open when the
+// polling interval was last computed by SleepTime.
+func (p *RemoteConfigPoller) CircuitBreakerOpen() bool {
+	return p != nil && p.circuitOpen.Load()
+}
+
+// recordFetch records the outcome of fetching the remote config. A remote
+// config which hasn't changed counts as a successful fetch.
+func (p *RemoteConfigPoller) recordFetch(err error) {
+	if p == nil {
+		return
+	}
+	if err != nil && !errors.Is(err, ErrRemoteConfigNotModified) {
+		p.fetchFailures.Add(1)
+		return
+	}
+	p.fetchFailures.Store(0)
+}
+
+// remoteConfigValidators returns the validators to make a conditional request
+// with. No validators are returned if the initial config changed.
+func (p *RemoteConfigPoller) remoteConfigValidators(initialConfigHash string) cacheValidators {
+	if p == nil {
+		return cacheValidators{}
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if p.initialConfigHash != initialConfigHash {
+		return cacheValidators{}
+	}
+	return p.validators
+}
+
+// setRemoteConfigValidators stores the validators of the last fetched remote
+// config.
+func (p *RemoteConfigPoller) setRemoteConfigValidators(initialConfigHash string, v cacheValidators) {
+	if p == nil {
+		return
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.initialConfigHash = initialConfigHash
+	p.validators = v
+}
+
+// setPendingRemoteConfig records pending as the remote config which was last
+// loaded. Passing nil forgets the pending remote config.
+func (p *RemoteConfigPoller) setPendingRemoteConfig(pending *pendingRemoteConfig) {
+	if p == nil {
+		return
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.pending = pending
+}
+
+// RemoteConfigApplied records the outcome of applying the remote config which
+// was last loaded with p, and reports it to
+// 'agent_management.status_url'. If err is non-nil, the remote config is
+// forgotten so that the next poll loads it again even if it's unchanged, and
+// the cached remote config which it replaced is restored. An error is
+// returned if the cache couldn't be restored.
+func (p *RemoteConfigPoller) RemoteConfigApplied(err error) error {
+	if p == nil {
+		return nil
+	}
+	p.mut.Lock()
+	pending := p.pending
+	p.pending = nil
+	if err != nil {
+		p.initialConfigHash, p.validators = "", cacheValidators{}
+		p.loaded = loadedRemoteConfig{}
+	}
+	p.mut.Unlock()
+
+	if pending == nil {
+		return nil
+	}
+	if err == nil {
+		pending.report(pending.status)
+		return nil
+	}
+	pending.report(remoteConfigStatus{
+		Error: fmt.Sprintf("could not apply remote config %s: %s", pending.status.ConfigHash, err),
+	})
+	if pending.restoreCache == nil {
+		return nil
+	}
+	return pending.restoreCache()
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/prometheus/common/config"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// httpClientConfigFor returns the HTTP client config for requests to rawURL.
+// Requests are sent through the proxy set by 'agent_management.proxy_url' or
+// 'http_client_config.proxy_url', unless the host of rawURL matches
+// 'agent_management.no_proxy'. The password read from Vault, if any, is read
+// with the client of poller.
+func (am *AgentManagementConfig) httpClientConfigFor(poller *RemoteConfigPoller, rawURL string) (*config.HTTPClientConfig, error) {
+	// Copy the config so that the proxy of one request doesn't leak into
+	// another.
+	res := *am.httpClientConfig()
+	if err := am.applyVaultPassword(poller, &res); err != nil {
+		return nil, err
+	}
+	if am.ProxyURL.URL != nil {
+		res.ProxyURL = am.ProxyURL
+	}
+	if res.ProxyURL.URL == nil {
+		return &res, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("error trying to parse url: %w", err)
+	}
+	proxy := res.ProxyURL.String()
+	proxyURL, err := (&httpproxy.Config{
+		HTTPProxy:  proxy,
+		HTTPSProxy: proxy,
+		NoProxy:    am.NoProxy,
+	}).ProxyFunc()(u)
+	if err != nil {
+		return nil, fmt.Errorf("error trying to resolve proxy: %w", err)
+	}
+	res.ProxyURL = config.URL{URL: proxyURL}
+	return &res, nil
+}
+
+// validateProxy checks the settings of 'agent_management.proxy_url' and
+// 'agent_management.no_proxy'.
+func (am *AgentManagementConfig) validateProxy() error {
+	if am.ProxyURL.URL == nil && am.NoProxy == "" {
+		return nil
+	}
+	if am.Protocol == protocolGit || isObjectStorageProtocol(am.Protocol) {
+		return fmt.Errorf("'agent_management.proxy_url' and 'agent_management.no_proxy' are not supported with the %s protocol", am.Protocol)
+	}
+	if am.ProxyURL.URL != nil {
+		if am.HTTPClientConfig != nil && am.HTTPClientConfig.ProxyURL.URL != nil {
+			return errors.New("at most one of 'agent_management.proxy_url' and 'agent_management.http_client_config.proxy_url' must be specified")
+		}
+		switch am.ProxyURL.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("unsupported scheme %q in 'agent_management.proxy_url'", am.ProxyURL.Scheme)
+		}
+		if am.ProxyURL.Host == "" {
+			return errors.New("'agent_management.proxy_url' must include a host")
+		}
+	} else if am.HTTPClientConfig == nil || am.HTTPClientConfig.ProxyURL.URL == nil {
+		return errors.New("'agent_management.no_proxy' requires a proxy to be set")
+	}
+	return nil
+}
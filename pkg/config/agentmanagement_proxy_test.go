@@ -0,0 +1,125 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/common/config"
+	"github.com/stretchr/testify/require"
+)
+
+func mustParseURL(t *testing.T, rawURL string) config.URL {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	return config.URL{URL: u}
+}
+
+func TestValidateProxy(t *testing.T) {
+	tt := []struct {
+		name   string
+		modify func(am *AgentManagementConfig)
+		errMsg string
+	}{
+		{
+			name: "proxy url",
+			modify: func(am *AgentManagementConfig) {
+				am.ProxyURL = mustParseURL(t, "http://proxy.example.com:3128")
+				am.NoProxy = "internal.example.com,10.0.0.0/8"
+			},
+		},
+		{
+			name: "no_proxy with http_client_config proxy",
+			modify: func(am *AgentManagementConfig) {
+				am.BasicAuth = config.BasicAuth{}
+				am.HTTPClientConfig = &config.HTTPClientConfig{ProxyURL: mustParseURL(t, "http://proxy.example.com:3128")}
+				am.NoProxy = "internal.example.com"
+			},
+		},
+		{
+			name: "unsupported scheme",
+			modify: func(am *AgentManagementConfig) {
+				am.ProxyURL = mustParseURL(t, "ftp://proxy.example.com")
+			},
+			errMsg: `unsupported scheme "ftp" in 'agent_management.proxy_url'`,
+		},
+		{
+			name: "no_proxy without proxy",
+			modify: func(am *AgentManagementConfig) {
+				am.NoProxy = "internal.example.com"
+			},
+			errMsg: "'agent_management.no_proxy' requires a proxy to be set",
+		},
+		{
+			name: "proxy set twice",
+			modify: func(am *AgentManagementConfig) {
+				am.BasicAuth = config.BasicAuth{}
+				am.HTTPClientConfig = &config.HTTPClientConfig{ProxyURL: mustParseURL(t, "http://proxy.example.com:3128")}
+				am.ProxyURL = mustParseURL(t, "http://other-proxy.example.com:3128")
+			},
+			errMsg: "at most one of 'agent_management.proxy_url' and 'agent_management.http_client_config.proxy_url' must be specified",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			am := validAgentManagementConfig
+			tc.modify(&am)
+			err := am.Validate()
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestHTTPClientConfigFor(t *testing.T) {
+	am := validAgentManagementConfig
+	am.ProxyURL = mustParseURL(t, "http://proxy.example.com:3128")
+	am.NoProxy = "internal.example.com"
+
+	cfg, err := am.httpClientConfigFor(nil, "https://agent-management.example.com/api")
+	require.NoError(t, err)
+	require.Equal(t, "http://proxy.example.com:3128", cfg.ProxyURL.String())
+	require.NotNil(t, cfg.BasicAuth)
+
+	cfg, err = am.httpClientConfigFor(nil, "https://internal.example.com/api")
+	require.NoError(t, err)
+	require.Nil(t, cfg.ProxyURL.URL)
+}
+
+func TestFetchRemoteConfig_Proxy(t *testing.T) {
+	// The proxy receives the requests for the API with the absolute URL of the
+	// API.
+	var proxiedURL string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedURL = r.URL.String()
+		_, _ = w.Write([]byte("base_config: 'proxied'"))
+	}))
+	defer proxy.Close()
+
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret"), 0600))
+
+	var cfg Config
+	cfg.AgentManagement = validAgentManagementConfig
+	cfg.AgentManagement.Url = apiURLs{"http://agent-management.example.com/api"}
+	cfg.AgentManagement.BasicAuth.PasswordFile = passwordFile
+	cfg.AgentManagement.CacheLocation = dir
+	cfg.AgentManagement.ProxyURL = mustParseURL(t, proxy.URL)
+
+	provider, err := newRemoteConfigHTTPProvider(&cfg)
+	require.NoError(t, err)
+
+	bb, err := provider.FetchRemoteConfig()
+	require.NoError(t, err)
+	require.Equal(t, "base_config: 'proxied'", string(bb))
+	require.Contains(t, proxiedURL, "http://agent-management.example.com/api/namespace/test_namespace/remote_config")
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/grafana/agent/pkg/agentid"
+	"github.com/grafana/agent/pkg/build"
+)
+
+// RegistrationEvent is the reason an agent registration is sent.
+type RegistrationEvent string
+
+// Events of agent registrations.
+const (
+	// RegistrationEventRegister is sent once when the agent starts.
+	RegistrationEventRegister RegistrationEvent = "register"
+	// RegistrationEventHeartbeat is sent periodically while the agent runs.
+	RegistrationEventHeartbeat RegistrationEvent = "heartbeat"
+)
+
+// agentRegistration is the payload sent to
+// 'agent_management.registration_url'. It lets the API know which agents are
+// running, so that remote configs can be targeted at live agents.
+type agentRegistration struct {
+	AgentID         string            `json:"agent_id"`
+	Event           RegistrationEvent `json:"event"`
+	Hostname        string            `json:"hostname"`
+	Namespace       string            `json:"namespace"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Version         string            `json:"version"`
+	EnabledFeatures []string          `json:"enabled_features,omitempty"`
+}
+
+// RegisterAgent sends the identity of the agent to
+// 'agent_management.registration_url', if set. The identity consists of the
+// agent ID, hostname, rendered remote configuration labels, version, and
+// enabled features of c. Secrets are read from Vault with the client of
+// poller.
+func RegisterAgent(c *Config, poller *RemoteConfigPoller, event RegistrationEvent) error {
+	am := c.AgentManagement
+	if am.RegistrationUrl == "" {
+		return nil
+	}
+
+	agentID, err := agentid.LoadOrCreate(am.CacheLocation)
+	if err != nil {
+		return err
+	}
+	labels, err := am.RemoteConfiguration.Labels.render()
+	if err != nil {
+		return err
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("failed to get hostname: %w", err)
+	}
+
+	registration := agentRegistration{
+		AgentID:         agentID,
+		Event:           event,
+		Hostname:        hostname,
+		Namespace:       am.RemoteConfiguration.Namespace,
+		Labels:          labels,
+		Version:         build.Version,
+		EnabledFeatures: c.EnabledFeatures,
+	}
+	return am.postJSON(poller, am.RegistrationUrl, agentID, "agent-registration", registration)
+}
+
+// HeartbeatTime returns the duration to wait in between heartbeats. It
+// defaults to the polling interval.
+func (am *AgentManagementConfig) HeartbeatTime() time.Duration {
+	if am.HeartbeatInterval == 0 {
+		return am.PollingInterval
+	}
+	return am.HeartbeatInterval
+}
+
+// validateRegistration checks the settings of
+// 'agent_management.registration_url' and
+// 'agent_management.heartbeat_interval'.
+func (am *AgentManagementConfig) validateRegistration() error {
+	if err := validateHTTPUrl("registration_url", am.RegistrationUrl); err != nil {
+		return err
+	}
+	if am.HeartbeatInterval < 0 {
+		return errors.New("'agent_management.heartbeat_interval' must be >=0")
+	}
+	if am.HeartbeatInterval != 0 && am.RegistrationUrl == "" {
+		return errors.New("'agent_management.heartbeat_interval' requires 'agent_management.registration_url' to be set")
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/grafana/agent/pkg/agentid"
+	"github.com/grafana/agent/pkg/build"
+	"github.com/prometheus/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRegistration(t *testing.T) {
+	cfg := validAgentManagementConfig
+	cfg.RegistrationUrl = "https://localhost:1234/example/agents"
+	cfg.HeartbeatInterval = time.Minute
+	assert.NoError(t, cfg.Validate())
+
+	cfg.RegistrationUrl = "s3://bucket/agents"
+	assert.EqualError(t, cfg.Validate(), "'agent_management.registration_url' must be an http or https URL")
+
+	cfg.RegistrationUrl = ""
+	assert.EqualError(t, cfg.Validate(), "'agent_management.heartbeat_interval' requires 'agent_management.registration_url' to be set")
+}
+
+func TestHeartbeatTime(t *testing.T) {
+	cfg := validAgentManagementConfig
+	require.Equal(t, cfg.PollingInterval, cfg.HeartbeatTime())
+
+	cfg.HeartbeatInterval = 5 * time.Second
+	require.Equal(t, 5*time.Second, cfg.HeartbeatTime())
+}
+
+func TestRegisterAgent(t *testing.T) {
+	var (
+		received agentRegistration
+		headers  http.Header
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		headers = r.Header.Clone()
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	var cfg Config
+	cfg.AgentManagement = validAgentManagementConfig
+	cfg.AgentManagement.BasicAuth = config.BasicAuth{}
+	cfg.AgentManagement.CacheLocation = t.TempDir()
+	cfg.AgentManagement.RegistrationUrl = srv.URL
+	cfg.AgentManagement.RemoteConfiguration.Labels = labelMap{"team": "{{ env.Getenv \"TEAM\" }}"}
+	cfg.EnabledFeatures = []string{"agent-management"}
+	t.Setenv("TEAM", "team-a")
+
+	require.NoError(t, RegisterAgent(&cfg, nil, RegistrationEventHeartbeat))
+
+	agentID, err := agentid.LoadOrCreate(cfg.AgentManagement.CacheLocation)
+	require.NoError(t, err)
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+	require.Equal(t, agentRegistration{
+		AgentID:         agentID,
+		Event:           RegistrationEventHeartbeat,
+		Hostname:        hostname,
+		Namespace:       "test_namespace",
+		Labels:          map[string]string{"team": "team-a"},
+		Version:         build.Version,
+		EnabledFeatures: []string{"agent-management"},
+	}, received)
+	require.Equal(t, "application/json", headers.Get("Content-Type"))
+	require.Equal(t, agentID, headers.Get(agentid.HeaderName))
+
+	// Registration is a no-op without a registration URL.
+	cfg.AgentManagement.RegistrationUrl = ""
+	require.NoError(t, RegisterAgent(&cfg, nil, RegistrationEventRegister))
+}
@@ -12,6 +12,9 @@ type (
 	RemoteConfig struct {
 		BaseConfig BaseConfigContent `json:"base_config" yaml:"base_config"`
 		Snippets   []Snippet         `json:"snippets" yaml:"snippets"`
+		// Canary marks the remote config as a canary which is restored to the
+		// previous remote config if the agent becomes unhealthy.
+		Canary *CanaryConfig `json:"canary,omitempty" yaml:"canary,omitempty"`
 	}
 
 	// BaseConfigContent is the content of a base config
@@ -44,19 +47,31 @@ func NewRemoteConfig(buf []byte) (*RemoteConfig, error) {
 
 // BuildAgentConfig builds an agent configuration from a base config and a list of snippets
 func (rc *RemoteConfig) BuildAgentConfig() (*Config, error) {
+	contents := make([]SnippetContent, 0, len(rc.Snippets))
+	for _, snippet := range rc.Snippets {
+		var snippetContent SnippetContent
+		err := yaml.Unmarshal([]byte(snippet.Config), &snippetContent)
+		if err != nil {
+			return nil, err
+		}
+		contents = append(contents, snippetContent)
+	}
+	return rc.buildAgentConfig(contents)
+}
+
+// buildAgentConfig builds an agent configuration from the base config and the
+// given decoded snippets.
+func (rc *RemoteConfig) buildAgentConfig(contents []SnippetContent) (*Config, error) {
 	c := DefaultConfig()
 	err := yaml.Unmarshal([]byte(rc.BaseConfig), &c)
 	if err != nil {
 		return nil, err
 	}
-	err = appendSnippets(&c, rc.Snippets)
-	if err != nil {
-		return nil, err
-	}
+	appendSnippets(&c, contents)
 	return &c, nil
 }
 
-func appendSnippets(c *Config, snippets []Snippet) error {
+func appendSnippets(c *Config, contents []SnippetContent) {
 	metricsConfigs := instance.DefaultConfig
 	metricsConfigs.Name = "Metrics Snippets"
 	logsConfigs := logs.InstanceConfig{
@@ -65,12 +80,7 @@ func appendSnippets(c *Config, snippets []Snippet) error {
 	}
 	logsConfigs.Initialize()
 
-	for _, snippet := range snippets {
-		var snippetContent SnippetContent
-		err := yaml.Unmarshal([]byte(snippet.Config), &snippetContent)
-		if err != nil {
-			return err
-		}
+	for _, snippetContent := range contents {
 		metricsConfigs.ScrapeConfigs = append(metricsConfigs.ScrapeConfigs, snippetContent.MetricsScrapeConfigs...)
 		logsConfigs.ScrapeConfig = append(logsConfigs.ScrapeConfig, snippetContent.LogsScrapeConfigs...)
 	}
@@ -87,5 +97,4 @@ func appendSnippets(c *Config, snippets []Snippet) error {
 		}
 		c.Logs.Configs = append(c.Logs.Configs, &logsConfigs)
 	}
-	return nil
 }
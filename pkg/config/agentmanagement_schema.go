@@ -0,0 +1,54 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/agent/pkg/build"
+)
+
+const (
+	// remoteConfigSchemaVersion is the version of the remote config schema
+	// understood by this agent. It must be incremented whenever the agent
+	// learns to parse remote config fields older agents can't.
+	remoteConfigSchemaVersion = "2"
+
+	// agentVersionHeader and schemaVersionHeader are sent with every request
+	// for the remote config, so that the API can serve a remote config the
+	// agent is able to parse.
+	agentVersionHeader  = "X-Agent-Version"
+	schemaVersionHeader = "X-Agent-Config-Schema-Version"
+)
+
+// errSchemaNotAcceptable is returned by remote providers when the API
+// responds with 406 Not Acceptable, because it can't serve a remote config
+// in the schema version sent by the agent.
+var errSchemaNotAcceptable = errors.New("remote config schema version not acceptable")
+
+// remoteConfigHeaders returns the headers sent with every request for the
+// remote config.
+func remoteConfigHeaders() map[string]string {
+	return map[string]string{
+		agentVersionHeader:  build.Version,
+		schemaVersionHeader: remoteConfigSchemaVersion,
+	}
+}
+
+// schemaNotAcceptableError describes why the remote config at baseURL
+// couldn't be fetched when the API rejected the schema version.
+func schemaNotAcceptableError(baseURL string) error {
+	return fmt.Errorf("%s does not serve remote configs in schema version %s required by agent version %s, set 'agent_management.fallback_api_url' to fetch them from an older API: %w",
+		baseURL, remoteConfigSchemaVersion, build.Version, errSchemaNotAcceptable)
+}
+
+// validateFallbackApiUrl checks that 'agent_management.fallback_api_url' is
+// an HTTP URL if it's set.
+func (am *AgentManagementConfig) validateFallbackApiUrl() error {
+	if am.FallbackApiUrl == "" {
+		return nil
+	}
+	if am.Protocol == protocolGit || isObjectStorageProtocol(am.Protocol) {
+		return fmt.Errorf("'agent_management.fallback_api_url' is not supported with the %s protocol", am.Protocol)
+	}
+	return validateHTTPUrl("fallback_api_url", am.FallbackApiUrl)
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/agent/pkg/build"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchRemoteConfig_SchemaVersion(t *testing.T) {
+	var (
+		gotVersion string
+		gotSchema  string
+	)
+	current := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotVersion = r.Header.Get(agentVersionHeader)
+		gotSchema = r.Header.Get(schemaVersionHeader)
+		w.WriteHeader(http.StatusNotAcceptable)
+	}))
+	defer current.Close()
+
+	var fallbackRequests int
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fallbackRequests++
+		_, _ = w.Write([]byte("base_config: 'fallback'"))
+	}))
+	defer fallback.Close()
+
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret"), 0600))
+
+	var cfg Config
+	cfg.AgentManagement = validAgentManagementConfig
+	cfg.AgentManagement.Url = apiURLs{current.URL}
+	cfg.AgentManagement.BasicAuth.PasswordFile = passwordFile
+	cfg.AgentManagement.CacheLocation = dir
+
+	// Without a fallback, the error explains that the API rejected the schema
+	// version.
+	provider, err := newRemoteConfigHTTPProvider(&cfg)
+	require.NoError(t, err)
+	_, err = provider.FetchRemoteConfig()
+	require.ErrorIs(t, err, errSchemaNotAcceptable)
+	require.ErrorContains(t, err, "fallback_api_url")
+	require.Equal(t, build.Version, gotVersion)
+	require.Equal(t, remoteConfigSchemaVersion, gotSchema)
+
+	cfg.AgentManagement.FallbackApiUrl = fallback.URL
+	provider, err = newRemoteConfigHTTPProvider(&cfg)
+	require.NoError(t, err)
+	bb, err := provider.FetchRemoteConfig()
+	require.NoError(t, err)
+	require.Equal(t, "base_config: 'fallback'", string(bb))
+	require.Equal(t, 1, fallbackRequests)
+}
+
+func TestValidateFallbackApiUrl(t *testing.T) {
+	am := validAgentManagementConfig
+	am.FallbackApiUrl = "https://localhost:1234/v1"
+	require.NoError(t, am.validateFallbackApiUrl())
+
+	am.FallbackApiUrl = "localhost:1234"
+	require.EqualError(t, am.validateFallbackApiUrl(), "'agent_management.fallback_api_url' must be an http or https URL")
+
+	am.FallbackApiUrl = "https://localhost:1234/v1"
+	am.Protocol = protocolGit
+	require.EqualError(t, am.validateFallbackApiUrl(), "'agent_management.fallback_api_url' is not supported with the git protocol")
+}
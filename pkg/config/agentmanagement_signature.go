@@ -0,0 +1,120 @@
+package config
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// DefaultSignatureHeader is the response header which holds the signature of
+// a remote config if none is configured.
+const DefaultSignatureHeader = "X-Config-Signature"
+
+// SignatureVerificationConfig configures verification of detached signatures
+// on remote configs fetched from the Agent Management API.
+type SignatureVerificationConfig struct {
+	// PublicKeyFile is a PEM file holding either a public key or an x509
+	// certificate. Ed25519, ECDSA, and RSA keys are supported.
+	PublicKeyFile string `yaml:"public_key_file"`
+	// SignatureHeader is the response header holding the base64-encoded
+	// signature of the remote config.
+	SignatureHeader string `yaml:"signature_header,omitempty"`
+}
+
+// Validate checks that necessary portions of the config have been set.
+func (c *SignatureVerificationConfig) Validate() error {
+	if c.PublicKeyFile == "" {
+		return errors.New("public_key_file must be specified in 'agent_management.signature_verification'")
+	}
+	return nil
+}
+
+func (c *SignatureVerificationConfig) signatureHeader() string {
+	if c.SignatureHeader == "" {
+		return DefaultSignatureHeader
+	}
+	return c.SignatureHeader
+}
+
+// verifier returns a function which verifies the signature of a remote config
+// held in the response headers. The public key is read from disk every time
+// verifier is called so that keys can be rotated without restarting.
+func (c *SignatureVerificationConfig) verifier() (func(bb []byte, h http.Header) error, error) {
+	pub, err := loadPublicKey(c.PublicKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	header := c.signatureHeader()
+
+	return func(bb []byte, h http.Header) error {
+		encoded := strings.TrimSpace(h.Get(header))
+		if encoded == "" {
+			return fmt.Errorf("remote config is not signed: missing %s header", header)
+		}
+		sig, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("invalid remote config signature encoding: %w", err)
+		}
+		return verifySignature(pub, bb, sig)
+	}, nil
+}
+
+// loadPublicKey reads a public key or x509 certificate from a PEM file.
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading public key file: %w", err)
+	}
+
+	block, _ := pem.Decode(bb)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q in %s", block.Type, path)
+	}
+}
+
+// verifySignature checks that sig is a valid signature of bb for pub. ECDSA
+// and RSA signatures are expected to be made over the SHA-256 digest of bb.
+func verifySignature(pub crypto.PublicKey, bb, sig []byte) error {
+	var ok bool
+
+	switch key := pub.(type) {
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(key, bb, sig)
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(bb)
+		ok = ecdsa.VerifyASN1(key, digest[:], sig)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(bb)
+		ok = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+
+	if !ok {
+		return errors.New("remote config signature verification failed")
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package config
+
+import "time"
+
+// RemoteConfigState describes the remote config applied by the agent and the
+// outcome of the last attempt to fetch it. It's served by the
+// /agent/api/v1/remote-config endpoint.
+type RemoteConfigState struct {
+	// ConfigHash is the SHA-256 hash of the raw remote config which was last
+	// loaded. It's empty if no remote config was loaded yet.
+	ConfigHash string `json:"config_hash,omitempty"`
+	// Source is where the remote config was last loaded from: remote or cache.
+	Source string `json:"source,omitempty"`
+	// Skipped describes the parts of the remote config which were skipped
+	// because they're invalid, when partial_apply is enabled.
+	Skipped []string `json:"skipped,omitempty"`
+	// Canary is the outcome of the last canary remote config: started,
+	// promoted, or failed.
+	Canary string `json:"canary,omitempty"`
+
+	// LastFetchTime is when the remote config was last fetched, whether or
+	// not fetching succeeded. It's nil if it was never fetched.
+	LastFetchTime *time.Time `json:"last_fetch_time,omitempty"`
+	// LastError explains why the last fetched remote config wasn't applied,
+	// if it wasn't.
+	LastError string `json:"last_error,omitempty"`
+
+	CircuitBreakerOpen bool `json:"circuit_breaker_open"`
+}
+
+// GetRemoteConfigState returns the state of the remote config polled by
+// poller.
+func GetRemoteConfigState(poller *RemoteConfigPoller) RemoteConfigState {
+	if poller == nil {
+		return RemoteConfigState{}
+	}
+	poller.mut.Lock()
+	defer poller.mut.Unlock()
+
+	state := poller.state
+	state.CircuitBreakerOpen = poller.CircuitBreakerOpen()
+	return state
+}
+
+// recordFetchTime records that the remote config was fetched at t. The last
+// error is cleared until the outcome of loading the fetched remote config is
+// recorded by recordStatus.
+func (p *RemoteConfigPoller) recordFetchTime(t time.Time) {
+	if p == nil {
+		return
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	p.state.LastFetchTime = &t
+	p.state.LastError = ""
+}
+
+// recordStatus records the outcome of loading the remote config. The
+// previously loaded remote config is kept if no remote config could be
+// loaded, since the agent keeps running it.
+func (p *RemoteConfigPoller) recordStatus(status remoteConfigStatus) {
+	if p == nil {
+		return
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	if status.ConfigHash != "" {
+		p.state.ConfigHash = status.ConfigHash
+		p.state.Source = status.Source
+		p.state.Skipped = status.Skipped
+	}
+	if status.Canary != "" {
+		p.state.Canary = status.Canary
+	}
+	p.state.LastError = status.Error
+}
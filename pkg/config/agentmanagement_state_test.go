@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"testing"
+
+	"github.com/grafana/agent/pkg/config/features"
+	"github.com/grafana/agent/pkg/server"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRemoteConfigState(t *testing.T) {
+	defaultCfg := DefaultConfig()
+	logger := server.NewLogger(defaultCfg.Server)
+
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	features.Register(fs, allFeatures)
+	defaultCfg.RegisterFlags(fs)
+
+	am := validAgentManagementConfig
+	poller := NewRemoteConfigPoller()
+	fetchedConfig := []byte("base_config: |\n  server:\n    log_level: debug\nsnippets: []\n")
+
+	// The fetched config is applied from the API.
+	testProvider := testRemoteConfigProvider{InitialConfig: &am}
+	testProvider.fetchedConfigBytesToReturn = fetchedConfig
+	_, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Poller: poller, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
+	require.NoError(t, err)
+	require.NoError(t, poller.RemoteConfigApplied(nil))
+
+	state := GetRemoteConfigState(poller)
+	require.Equal(t, hashRemoteConfig(fetchedConfig), state.ConfigHash)
+	require.Equal(t, remoteConfigSourceRemote, state.Source)
+	require.NotNil(t, state.LastFetchTime)
+	require.Empty(t, state.LastError)
+	firstFetch := *state.LastFetchTime
+
+	// The previously applied config is kept when neither the API nor the
+	// cache can be loaded.
+	testProvider = testRemoteConfigProvider{InitialConfig: &am}
+	testProvider.fetchedConfigErrorToReturn = errors.New("connection refused")
+	testProvider.cachedConfigErrorToReturn = errors.New("no cache")
+	_, _, err = getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Poller: poller, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
+	require.Error(t, err)
+
+	state = GetRemoteConfigState(poller)
+	require.Equal(t, hashRemoteConfig(fetchedConfig), state.ConfigHash)
+	require.Equal(t, remoteConfigSourceRemote, state.Source)
+	require.False(t, state.LastFetchTime.Before(firstFetch))
+	require.Equal(t, "connection refused; could not load cached config: no cache", state.LastError)
+
+	// The cached config is applied when the API can't be reached.
+	testProvider.cachedConfigToReturn = cachedConfig
+	testProvider.cachedConfigErrorToReturn = nil
+	_, _, err = getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Poller: poller, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
+	require.NoError(t, err)
+	require.NoError(t, poller.RemoteConfigApplied(nil))
+
+	state = GetRemoteConfigState(poller)
+	require.Equal(t, hashRemoteConfig(cachedConfig), state.ConfigHash)
+	require.Equal(t, remoteConfigSourceCache, state.Source)
+	require.Equal(t, "connection refused", state.LastError)
+}
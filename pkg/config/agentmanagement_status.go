@@ -0,0 +1,152 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/agent/pkg/agentid"
+	"github.com/grafana/agent/pkg/build"
+	"github.com/grafana/agent/pkg/server"
+	"github.com/prometheus/common/config"
+)
+
+// Sources of the remote config reported in remoteConfigStatus.
+const (
+	remoteConfigSourceRemote = "remote"
+	remoteConfigSourceCache  = "cache"
+)
+
+// statusReportTimeout is the timeout for reporting the status of the remote
+// config and registering the agent. Reporting is best-effort, so it shouldn't
+// hold up loading the config for long.
+const statusReportTimeout = 10 * time.Second
+
+// remoteConfigStatus is the payload sent to
+// 'agent_management.status_url' after loading the remote config. It lets
+// fleet operators tell which config every agent actually applied.
+type remoteConfigStatus struct {
+	AgentID string `json:"agent_id,omitempty"`
+	Version string `json:"version"`
+	// ConfigHash is the SHA-256 hash of the raw remote config which was
+	// applied. It's empty if no remote config could be loaded.
+	ConfigHash string `json:"config_hash,omitempty"`
+	// Source is where the applied remote config was loaded from: remote or
+	// cache. It's empty if no remote config could be loaded.
+	Source string `json:"source,omitempty"`
+	// Error explains why the fetched remote config wasn't applied, if it
+	// wasn't.
+	Error string `json:"error,omitempty"`
+	// Skipped describes the parts of the applied remote config which were
+	// skipped because they're invalid, when partial_apply is enabled.
+	Skipped []string `json:"skipped,omitempty"`
+	// Canary is the outcome of a canary remote config: started, promoted, or
+	// failed. It's empty if the remote config isn't a canary.
+	Canary string `json:"canary,omitempty"`
+}
+
+// hashRemoteConfig returns the hash of the raw remote config reported in
+// remoteConfigStatus.
+func hashRemoteConfig(remoteConfigBytes []byte) string {
+	hashed := sha256.Sum256(remoteConfigBytes)
+	return hex.EncodeToString(hashed[:])
+}
+
+// reportRemoteConfigStatus records status on poller for GetRemoteConfigState
+// and reports it through configProvider. Failures are logged, but otherwise
+// ignored.
+func reportRemoteConfigStatus(poller *RemoteConfigPoller, configProvider remoteConfigProvider, log *server.Logger, status remoteConfigStatus) {
+	poller.recordStatus(status)
+	if err := configProvider.ReportStatus(status); err != nil {
+		level.Warn(log).Log("msg", "could not report remote config status", "err", err)
+	}
+}
+
+// ReportStatus sends status to 'agent_management.status_url', if set. The
+// agent ID and version are filled in before sending.
+func (r remoteConfigHTTPProvider) ReportStatus(status remoteConfigStatus) error {
+	if r.InitialConfig.StatusUrl == "" {
+		return nil
+	}
+	status.AgentID = r.AgentID
+	status.Version = build.Version
+
+	return r.InitialConfig.postJSON(r.poller, r.InitialConfig.StatusUrl, r.AgentID, "remote-config-status", status)
+}
+
+// postJSON sends payload encoded as JSON to rawURL with the HTTP client config
+// of am. The agent ID is sent in a header if it's set. Secrets are read from
+// Vault with the client of poller.
+func (am *AgentManagementConfig) postJSON(poller *RemoteConfigPoller, rawURL, agentID, clientName string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error encoding request body: %w", err)
+	}
+
+	httpClientConfig, err := am.httpClientConfigFor(poller, rawURL)
+	if err != nil {
+		return err
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	httpClientConfig.SetDirectory(dir)
+
+	client, err := config.NewClientFromConfig(*httpClientConfig, clientName)
+	if err != nil {
+		return err
+	}
+	client.Timeout = statusReportTimeout
+
+	req, err := http.NewRequest(http.MethodPost, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if agentID != "" {
+		req.Header.Set(agentid.HeaderName, agentID)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// validateHTTPUrl checks that rawURL, set by the given field of
+// 'agent_management', is an HTTP URL if it's set.
+func validateHTTPUrl(field, rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid 'agent_management.%s': %w", field, err)
+	}
+	if (u.Scheme != httpScheme && u.Scheme != httpsScheme) || u.Host == "" {
+		return fmt.Errorf("'agent_management.%s' must be an http or https URL", field)
+	}
+	return nil
+}
+
+// validateStatusUrl checks that 'agent_management.status_url' is an HTTP URL
+// if it's set.
+func (am *AgentManagementConfig) validateStatusUrl() error {
+	return validateHTTPUrl("status_url", am.StatusUrl)
+}
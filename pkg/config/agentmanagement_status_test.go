@@ -0,0 +1,136 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/agent/pkg/agentid"
+	"github.com/grafana/agent/pkg/build"
+	"github.com/grafana/agent/pkg/config/features"
+	"github.com/grafana/agent/pkg/server"
+	"github.com/prometheus/common/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateStatusUrl(t *testing.T) {
+	cfg := validAgentManagementConfig
+	cfg.StatusUrl = "https://localhost:1234/example/status"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.StatusUrl = "s3://bucket/status"
+	assert.EqualError(t, cfg.Validate(), "'agent_management.status_url' must be an http or https URL")
+}
+
+func TestGetRemoteConfig_ReportsStatus(t *testing.T) {
+	defaultCfg := DefaultConfig()
+	logger := server.NewLogger(defaultCfg.Server)
+
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	features.Register(fs, allFeatures)
+	defaultCfg.RegisterFlags(fs)
+
+	am := validAgentManagementConfig
+	fetchedConfig := []byte("base_config: |\n  server:\n    log_level: debug\nsnippets: []\n")
+
+	t.Run("fetched config", func(t *testing.T) {
+		testProvider := testRemoteConfigProvider{InitialConfig: &am}
+		testProvider.fetchedConfigBytesToReturn = fetchedConfig
+
+		poller := NewRemoteConfigPoller()
+		_, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Poller: poller, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
+		require.NoError(t, err)
+		// The status is only reported once the config was applied.
+		require.Empty(t, testProvider.reportedStatuses)
+		require.NoError(t, poller.RemoteConfigApplied(nil))
+		require.Equal(t, []remoteConfigStatus{{
+			ConfigHash: hashRemoteConfig(fetchedConfig),
+			Source:     remoteConfigSourceRemote,
+		}}, testProvider.reportedStatuses)
+	})
+
+	t.Run("fetched config fails to apply", func(t *testing.T) {
+		testProvider := testRemoteConfigProvider{InitialConfig: &am}
+		testProvider.fetchedConfigBytesToReturn = fetchedConfig
+		testProvider.cachedConfigToReturn = cachedConfig
+
+		poller := NewRemoteConfigPoller()
+		_, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Poller: poller, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
+		require.NoError(t, err)
+		require.NoError(t, poller.RemoteConfigApplied(errors.New("apply failed")))
+		require.Equal(t, []remoteConfigStatus{{
+			Error: "could not apply remote config " + hashRemoteConfig(fetchedConfig) + ": apply failed",
+		}}, testProvider.reportedStatuses)
+		require.Equal(t, cachedConfig, testProvider.cachedConfigToReturn, "cached config should be restored")
+	})
+
+	t.Run("invalid config falls back to cache", func(t *testing.T) {
+		testProvider := testRemoteConfigProvider{InitialConfig: &am}
+		testProvider.fetchedConfigBytesToReturn = []byte("not a config")
+		testProvider.cachedConfigToReturn = cachedConfig
+
+		poller := NewRemoteConfigPoller()
+		_, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Poller: poller, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
+		require.NoError(t, err)
+		require.NoError(t, poller.RemoteConfigApplied(nil))
+		require.Len(t, testProvider.reportedStatuses, 1)
+		status := testProvider.reportedStatuses[0]
+		require.Equal(t, hashRemoteConfig(cachedConfig), status.ConfigHash)
+		require.Equal(t, remoteConfigSourceCache, status.Source)
+		require.Contains(t, status.Error, "could not unmarshal remote config")
+	})
+
+	t.Run("fetch and cache fail", func(t *testing.T) {
+		testProvider := testRemoteConfigProvider{InitialConfig: &am}
+		testProvider.fetchedConfigErrorToReturn = errors.New("connection refused")
+		testProvider.cachedConfigErrorToReturn = errors.New("no cache")
+
+		_, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
+		require.Error(t, err)
+		require.Equal(t, []remoteConfigStatus{{
+			Error: "connection refused; could not load cached config: no cache",
+		}}, testProvider.reportedStatuses)
+	})
+}
+
+func TestReportStatus(t *testing.T) {
+	var (
+		received remoteConfigStatus
+		headers  http.Header
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		headers = r.Header.Clone()
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	am := validAgentManagementConfig
+	am.BasicAuth = config.BasicAuth{}
+	am.StatusUrl = srv.URL
+	provider := remoteConfigHTTPProvider{InitialConfig: &am, AgentID: "test-agent"}
+
+	err := provider.ReportStatus(remoteConfigStatus{
+		ConfigHash: "abc",
+		Source:     remoteConfigSourceRemote,
+	})
+	require.NoError(t, err)
+	require.Equal(t, remoteConfigStatus{
+		AgentID:    "test-agent",
+		Version:    build.Version,
+		ConfigHash: "abc",
+		Source:     remoteConfigSourceRemote,
+	}, received)
+	require.Equal(t, "application/json", headers.Get("Content-Type"))
+	require.Equal(t, "test-agent", headers.Get(agentid.HeaderName))
+	require.Empty(t, headers.Get("Authorization"))
+
+	// Reporting is a no-op without a status URL.
+	am.StatusUrl = ""
+	require.NoError(t, provider.ReportStatus(remoteConfigStatus{}))
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// templateVariablePattern matches references to template variables in remote
+// configs, which are written as ${name}.
+var templateVariablePattern = regexp.MustCompile(`\$\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+
+// templateVariableNamePattern matches valid names of template variables.
+var templateVariableNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// substituteTemplateVariables replaces references of the form ${name} in the
+// raw remote config with the values of vars. References to names which aren't
+// in vars are left as-is, so that they can still be expanded from environment
+// variables or be used as capture groups in relabeling rules.
+func substituteTemplateVariables(buf []byte, vars map[string]string) []byte {
+	if len(vars) == 0 {
+		return buf
+	}
+	return templateVariablePattern.ReplaceAllFunc(buf, func(ref []byte) []byte {
+		if value, ok := vars[string(ref[2:len(ref)-1])]; ok {
+			return []byte(value)
+		}
+		return ref
+	})
+}
+
+// templateVariables returns the variables to substitute in remote configs:
+// 'agent_management.template_variables' along with the secrets read from
+// 'agent_management.vault.secrets' with the client of poller.
+func (am *AgentManagementConfig) templateVariables(poller *RemoteConfigPoller) (map[string]string, error) {
+	secrets, err := am.vaultSecrets(poller)
+	if err != nil || len(secrets) == 0 {
+		return am.TemplateVariables, err
+	}
+
+	res := make(map[string]string, len(am.TemplateVariables)+len(secrets))
+	for name, value := range am.TemplateVariables {
+		res[name] = value
+	}
+	for name, value := range secrets {
+		res[name] = value
+	}
+	return res, nil
+}
+
+// validateTemplateVariables checks the names of
+// 'agent_management.template_variables'.
+func (am *AgentManagementConfig) validateTemplateVariables() error {
+	for name := range am.TemplateVariables {
+		if !templateVariableNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid name %q in 'agent_management.template_variables': names must start with a letter or underscore and contain only letters, digits, and underscores", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/grafana/agent/pkg/config/features"
+	"github.com/grafana/agent/pkg/server"
+	"github.com/prometheus/prometheus/discovery"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubstituteTemplateVariables(t *testing.T) {
+	vars := map[string]string{"cluster": "prod-eu", "scrape_interval": "30s"}
+
+	in := "cluster: ${cluster}\ninterval: ${scrape_interval}\nreplacement: ${1}\nenv: ${HOSTNAME}\n"
+	out := substituteTemplateVariables([]byte(in), vars)
+
+	// References to names which aren't template variables are kept.
+	require.Equal(t, "cluster: prod-eu\ninterval: 30s\nreplacement: ${1}\nenv: ${HOSTNAME}\n", string(out))
+}
+
+func TestValidateTemplateVariables(t *testing.T) {
+	am := validAgentManagementConfig
+	am.TemplateVariables = map[string]string{"cluster": "prod", "_region": "eu"}
+	require.NoError(t, am.Validate())
+
+	am.TemplateVariables = map[string]string{"1cluster": "prod"}
+	require.ErrorContains(t, am.Validate(), `invalid name "1cluster" in 'agent_management.template_variables'`)
+}
+
+func TestGetRemoteConfig_TemplateVariables(t *testing.T) {
+	defaultCfg := DefaultConfig()
+	remoteConfig := `
+base_config: |
+  server:
+    log_level: ${log_level}
+snippets:
+- config: |
+    metrics_scrape_configs:
+    - job_name: 'prometheus'
+      scrape_interval: ${SCRAPE_INTERVAL}
+      static_configs:
+      - targets: ['${target}']
+`
+	t.Setenv("SCRAPE_INTERVAL", "15s")
+
+	am := validAgentManagementConfig
+	logger := server.NewLogger(defaultCfg.Server)
+	testProvider := testRemoteConfigProvider{InitialConfig: &am}
+	testProvider.fetchedConfigBytesToReturn = []byte(remoteConfig)
+	testProvider.cachedConfigToReturn = cachedConfig
+
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	var configExpandEnv bool
+	fs.BoolVar(&configExpandEnv, "config.expand-env", false, "")
+	features.Register(fs, allFeatures)
+	defaultCfg.RegisterFlags(fs)
+
+	vars := map[string]string{"log_level": "debug", "target": "host-1:9100"}
+	poller := NewRemoteConfigPoller()
+	cfg, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, TemplateVariables: vars, Poller: poller, Provider: &testProvider, Log: logger, Flags: fs, Args: []string{"-config.expand-env"}, ConfigPath: "test"})
+	require.NoError(t, err)
+	require.NoError(t, poller.RemoteConfigApplied(nil))
+	require.Equal(t, "debug", cfg.Server.LogLevel.String())
+
+	// Environment variables are still expanded after template variables.
+	sc := cfg.Metrics.Configs[0].ScrapeConfigs[0]
+	require.Equal(t, "15s", sc.ScrapeInterval.String())
+	require.Equal(t, "host-1:9100", string(sc.ServiceDiscoveryConfigs[0].(discovery.StaticConfig)[0].Targets[0]["__address__"]))
+
+	// The hash reported for the remote config is the hash of the raw remote
+	// config shared by all agents.
+	require.Equal(t, hashRemoteConfig([]byte(remoteConfig)), testProvider.reportedStatuses[0].ConfigHash)
+}
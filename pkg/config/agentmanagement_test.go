@@ -1,13 +1,31 @@
 package config
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"flag"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/grafana/agent/pkg/agentid"
 	"github.com/grafana/agent/pkg/config/features"
 	"github.com/grafana/agent/pkg/server"
 	"github.com/grafana/agent/pkg/util"
@@ -29,6 +47,10 @@ type testRemoteConfigProvider struct {
 	cachedConfigToReturn      []byte
 	cachedConfigErrorToReturn error
 	didCacheRemoteConfig      bool
+	rolledBackConfigHash      string
+	startedCanary             *CanaryConfig
+
+	reportedStatuses []remoteConfigStatus
 }
 
 func (t *testRemoteConfigProvider) GetCachedRemoteConfig() ([]byte, error) {
@@ -44,9 +66,28 @@ func (t *testRemoteConfigProvider) CacheRemoteConfig(r []byte) error {
 	return nil
 }
 
+func (t *testRemoteConfigProvider) RestoreCachedRemoteConfig(previousConfig []byte) error {
+	t.cachedConfigToReturn = previousConfig
+	return nil
+}
+
+func (t *testRemoteConfigProvider) RolledBackConfigHash() string {
+	return t.rolledBackConfigHash
+}
+
+func (t *testRemoteConfigProvider) StartCanary(previousConfig []byte, configHash string, settings CanaryConfig) error {
+	t.startedCanary = &settings
+	return nil
+}
+
+func (t *testRemoteConfigProvider) ReportStatus(status remoteConfigStatus) error {
+	t.reportedStatuses = append(t.reportedStatuses, status)
+	return nil
+}
+
 var validAgentManagementConfig = AgentManagementConfig{
 	Enabled: true,
-	Url:     "https://localhost:1234/example/api",
+	Url:     apiURLs{"https://localhost:1234/example/api"},
 	BasicAuth: config.BasicAuth{
 		Username:     "test",
 		PasswordFile: "/test/path",
@@ -72,7 +113,7 @@ func TestValidateValidConfig(t *testing.T) {
 func TestValidateInvalidBasicAuth(t *testing.T) {
 	invalidConfig := &AgentManagementConfig{
 		Enabled:         true,
-		Url:             "https://localhost:1234",
+		Url:             apiURLs{"https://localhost:1234"},
 		BasicAuth:       config.BasicAuth{},
 		Protocol:        "https",
 		PollingInterval: time.Minute,
@@ -91,10 +132,44 @@ func TestValidateInvalidBasicAuth(t *testing.T) {
 	assert.Error(t, invalidConfig.Validate()) // Should still error as there is no username set
 }
 
+func TestValidateHTTPClientConfig(t *testing.T) {
+	cfg := validAgentManagementConfig
+	cfg.HTTPClientConfig = &config.HTTPClientConfig{
+		Authorization: &config.Authorization{Type: "Bearer", Credentials: "token"},
+	}
+	assert.EqualError(t, cfg.Validate(), "at most one of 'agent_management.basic_auth' and 'agent_management.http_client_config' must be specified")
+
+	cfg.BasicAuth = config.BasicAuth{}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.HTTPClientConfig.BasicAuth = &config.BasicAuth{Username: "test", PasswordFile: "/test/path"}
+	assert.Error(t, cfg.Validate(), "multiple authentication methods must be rejected")
+}
+
+func TestValidateNamespaceBasicAuth(t *testing.T) {
+	cfg := validAgentManagementConfig
+	cfg.BasicAuth = config.BasicAuth{}
+	cfg.NamespaceBasicAuth = map[string]config.BasicAuth{
+		"test_namespace": {Username: "tenant", PasswordFile: "/test/path"},
+	}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.NamespaceBasicAuth["other_namespace"] = config.BasicAuth{Username: "other"}
+	assert.EqualError(t, cfg.Validate(), `both username and password_file fields must be specified for namespace "other_namespace" in 'agent_management.namespace_basic_auth'`)
+	delete(cfg.NamespaceBasicAuth, "other_namespace")
+
+	cfg.RemoteConfiguration.Namespace = "unknown_namespace"
+	assert.Error(t, cfg.Validate(), "basic_auth is required for namespaces without credentials")
+
+	cfg.RemoteConfiguration.Namespace = "test_namespace"
+	cfg.HTTPClientConfig = &config.HTTPClientConfig{}
+	assert.EqualError(t, cfg.Validate(), "at most one of 'agent_management.namespace_basic_auth' and 'agent_management.http_client_config' must be specified")
+}
+
 func TestMissingCacheLocation(t *testing.T) {
 	invalidConfig := &AgentManagementConfig{
 		Enabled: true,
-		Url:     "https://localhost:1234",
+		Url:     apiURLs{"https://localhost:1234"},
 		BasicAuth: config.BasicAuth{
 			Username:     "test",
 			PasswordFile: "/test/path",
@@ -108,6 +183,29 @@ func TestMissingCacheLocation(t *testing.T) {
 	assert.Error(t, invalidConfig.Validate())
 }
 
+func TestValidateAcceptEncoding(t *testing.T) {
+	cfg := validAgentManagementConfig
+	cfg.AcceptEncoding = []string{"zstd", "identity"}
+	assert.NoError(t, cfg.Validate())
+	assert.Equal(t, []string{"zstd", "identity"}, cfg.acceptEncodings())
+
+	cfg.AcceptEncoding = nil
+	assert.Equal(t, defaultAcceptEncodings, cfg.acceptEncodings())
+
+	cfg.AcceptEncoding = []string{"br"}
+	assert.EqualError(t, cfg.Validate(), `unsupported encoding "br" in 'agent_management.accept_encoding'`)
+}
+
+func TestValidateSignatureVerification(t *testing.T) {
+	cfg := validAgentManagementConfig
+	cfg.SignatureVerification = &SignatureVerificationConfig{}
+	assert.EqualError(t, cfg.Validate(), "public_key_file must be specified in 'agent_management.signature_verification'")
+
+	cfg.SignatureVerification.PublicKeyFile = "/etc/agent/config.pub"
+	assert.NoError(t, cfg.Validate())
+	assert.Equal(t, DefaultSignatureHeader, cfg.SignatureVerification.signatureHeader())
+}
+
 func TestSleepTime(t *testing.T) {
 	cfg := `
 api_url: "http://localhost"
@@ -121,7 +219,7 @@ remote_configuration:
 
 	var am AgentManagementConfig
 	yaml.Unmarshal([]byte(cfg), &am)
-	assert.Equal(t, time.Minute, am.SleepTime())
+	assert.Equal(t, time.Minute, am.SleepTime(0))
 }
 
 func TestFuzzJitterTime(t *testing.T) {
@@ -138,6 +236,106 @@ func TestFuzzJitterTime(t *testing.T) {
 	}
 }
 
+func TestValidatePollingBackoff(t *testing.T) {
+	cfg := validAgentManagementConfig
+
+	cfg.PollingJitter = -time.Second
+	assert.EqualError(t, cfg.Validate(), "polling jitter must be >=0")
+
+	cfg.PollingJitter = 0
+	cfg.PollingMaxBackoff = time.Second
+	assert.EqualError(t, cfg.Validate(), "polling max backoff must be 0 or at least the polling interval")
+
+	cfg.PollingMaxBackoff = time.Hour
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestBackoffTime(t *testing.T) {
+	am := validAgentManagementConfig
+
+	// Backoff is disabled without a max backoff.
+	assert.Equal(t, time.Minute, am.backoffTime(5))
+
+	am.PollingMaxBackoff = 10 * time.Minute
+	assert.Equal(t, time.Minute, am.backoffTime(0))
+	assert.Equal(t, 2*time.Minute, am.backoffTime(1))
+	assert.Equal(t, 8*time.Minute, am.backoffTime(3))
+	assert.Equal(t, 10*time.Minute, am.backoffTime(4))
+	assert.Equal(t, 10*time.Minute, am.backoffTime(1000))
+}
+
+func TestSleepTime_Backoff(t *testing.T) {
+	am := validAgentManagementConfig
+	am.PollingMaxBackoff = time.Hour
+
+	assert.Equal(t, 4*time.Minute, am.SleepTime(2))
+	assert.Equal(t, time.Minute, am.SleepTime(0))
+}
+
+func TestSleepTime_CircuitBreaker(t *testing.T) {
+	am := validAgentManagementConfig
+	am.PollingMaxBackoff = 4 * time.Minute
+	am.CircuitBreakerThreshold = 3
+	am.CircuitBreakerInterval = 30 * time.Minute
+
+	assert.Equal(t, 4*time.Minute, am.SleepTime(2))
+	assert.Equal(t, 30*time.Minute, am.SleepTime(3))
+	assert.Equal(t, time.Minute, am.SleepTime(0))
+}
+
+func TestRemoteConfigPoller_SleepTime(t *testing.T) {
+	am := validAgentManagementConfig
+	am.PollingMaxBackoff = 4 * time.Minute
+	am.CircuitBreakerThreshold = 3
+	am.CircuitBreakerInterval = 30 * time.Minute
+
+	p := NewRemoteConfigPoller()
+	fetchErr := errors.New("connection refused")
+	for i := 0; i < 2; i++ {
+		p.recordFetch(fetchErr)
+	}
+	assert.Equal(t, 4*time.Minute, p.SleepTime(&am))
+	assert.False(t, p.CircuitBreakerOpen())
+
+	p.recordFetch(fetchErr)
+	assert.Equal(t, 30*time.Minute, p.SleepTime(&am))
+	assert.True(t, p.CircuitBreakerOpen())
+
+	// A remote config which hasn't changed was fetched successfully.
+	p.recordFetch(ErrRemoteConfigNotModified)
+	assert.Equal(t, time.Minute, p.SleepTime(&am))
+	assert.False(t, p.CircuitBreakerOpen())
+}
+
+func TestValidateCircuitBreaker(t *testing.T) {
+	cfg := validAgentManagementConfig
+
+	cfg.CircuitBreakerThreshold = -1
+	assert.EqualError(t, cfg.Validate(), "'agent_management.circuit_breaker_threshold' must be >=0")
+
+	cfg.CircuitBreakerThreshold = 0
+	cfg.CircuitBreakerInterval = time.Hour
+	assert.EqualError(t, cfg.Validate(), "'agent_management.circuit_breaker_interval' requires 'agent_management.circuit_breaker_threshold' to be set")
+
+	cfg.CircuitBreakerThreshold = 5
+	cfg.CircuitBreakerInterval = time.Second
+	assert.EqualError(t, cfg.Validate(), "'agent_management.circuit_breaker_interval' must be at least the polling interval")
+
+	cfg.CircuitBreakerInterval = time.Hour
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestFuzzSleepTimeJitter(t *testing.T) {
+	am := validAgentManagementConfig
+	am.PollingJitter = 10 * time.Second
+
+	for i := 0; i < 10_000; i++ {
+		s := am.SleepTime(0)
+		assert.GreaterOrEqual(t, s, am.PollingInterval)
+		assert.Less(t, s, am.PollingInterval+am.PollingJitter)
+	}
+}
+
 func TestFullUrl(t *testing.T) {
 	c := validAgentManagementConfig
 	actual, err := c.fullUrl()
@@ -145,6 +343,409 @@ func TestFullUrl(t *testing.T) {
 	assert.Equal(t, "https://localhost:1234/example/api/namespace/test_namespace/remote_config?a=A&b=B", actual)
 }
 
+func TestAPIURLs_YAML(t *testing.T) {
+	var am AgentManagementConfig
+	require.NoError(t, yaml.Unmarshal([]byte(`api_url: https://a.example.com`), &am))
+	require.Equal(t, apiURLs{"https://a.example.com"}, am.Url)
+
+	// A single URL is marshaled as a string so the hash of existing initial
+	// configs doesn't change.
+	bb, err := yaml.Marshal(am.Url)
+	require.NoError(t, err)
+	require.Equal(t, "https://a.example.com\n", string(bb))
+
+	require.NoError(t, yaml.Unmarshal([]byte(`api_url: [https://a.example.com, https://b.example.com]`), &am))
+	require.Equal(t, apiURLs{"https://a.example.com", "https://b.example.com"}, am.Url)
+
+	am.Protocol = protocolGit
+	require.EqualError(t, am.Validate(), "'agent_management.api_url' must be a single URL when using the git protocol")
+}
+
+func TestFetchRemoteConfig_FallbackURLs(t *testing.T) {
+	var primaryRequests int
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryRequests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("base_config: 'secondary'"))
+	}))
+	defer secondary.Close()
+
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret"), 0600))
+
+	var cfg Config
+	cfg.AgentManagement = validAgentManagementConfig
+	cfg.AgentManagement.Url = apiURLs{primary.URL, secondary.URL}
+	cfg.AgentManagement.BasicAuth.PasswordFile = passwordFile
+	cfg.AgentManagement.CacheLocation = dir
+
+	provider, err := newRemoteConfigHTTPProvider(&cfg)
+	require.NoError(t, err)
+
+	bb, err := provider.FetchRemoteConfig()
+	require.NoError(t, err)
+	require.Equal(t, "base_config: 'secondary'", string(bb))
+	require.Equal(t, 1, primaryRequests)
+
+	// An error describing every URL is returned if all of them fail.
+	secondary.Close()
+	_, err = provider.FetchRemoteConfig()
+	require.ErrorContains(t, err, "could not fetch remote config from any API URL")
+	require.ErrorContains(t, err, primary.URL)
+	require.ErrorContains(t, err, secondary.URL)
+}
+
+func TestFetchRemoteConfig_AgentID(t *testing.T) {
+	var (
+		gotHeader string
+		gotLabel  string
+	)
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get(agentid.HeaderName)
+		gotLabel = r.URL.Query().Get(agentid.LabelName)
+		_, _ = w.Write([]byte("base_config: ''"))
+	}))
+	defer svr.Close()
+
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret"), 0600))
+
+	var cfg Config
+	cfg.AgentManagement = validAgentManagementConfig
+	cfg.AgentManagement.Url = apiURLs{svr.URL}
+	cfg.AgentManagement.BasicAuth.PasswordFile = passwordFile
+	cfg.AgentManagement.CacheLocation = dir
+
+	provider, err := newRemoteConfigHTTPProvider(&cfg)
+	require.NoError(t, err)
+	require.NotEmpty(t, provider.AgentID)
+
+	_, err = provider.FetchRemoteConfig()
+	require.NoError(t, err)
+	require.Equal(t, provider.AgentID, gotHeader)
+	require.Empty(t, gotLabel)
+
+	cfg.AgentManagement.RemoteConfiguration.AgentIDAsLabel = true
+	provider, err = newRemoteConfigHTTPProvider(&cfg)
+	require.NoError(t, err)
+	require.Equal(t, gotHeader, provider.AgentID, "agent ID should be stable")
+
+	_, err = provider.FetchRemoteConfig()
+	require.NoError(t, err)
+	require.Equal(t, provider.AgentID, gotLabel)
+}
+
+func TestFetchRemoteConfig_HTTPClientConfig(t *testing.T) {
+	var gotAuthorization string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuthorization = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("base_config: ''"))
+	}))
+	defer svr.Close()
+
+	dir := t.TempDir()
+	cfgText := `
+api_url: ` + svr.URL + `
+http_client_config:
+  authorization:
+    credentials: secret-token
+protocol: http
+polling_interval: 1m
+remote_config_cache_location: ` + dir + `
+remote_configuration:
+  namespace: test_namespace
+`
+
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(cfgText), &cfg.AgentManagement))
+
+	provider, err := newRemoteConfigHTTPProvider(&cfg)
+	require.NoError(t, err)
+
+	_, err = provider.FetchRemoteConfig()
+	require.NoError(t, err)
+	require.Equal(t, "Bearer secret-token", gotAuthorization)
+}
+
+func TestFetchRemoteConfig_NamespaceBasicAuth(t *testing.T) {
+	var gotUsername, gotPassword string
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUsername, gotPassword, _ = r.BasicAuth()
+		_, _ = w.Write([]byte("base_config: ''"))
+	}))
+	defer svr.Close()
+
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "tenant-password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("tenant-secret"), 0600))
+
+	cfgText := `
+api_url: ` + svr.URL + `
+basic_auth:
+  username: global
+  password_file: /does/not/exist
+namespace_basic_auth:
+  tenant:
+    username: tenant
+    password_file: ` + passwordFile + `
+protocol: http
+polling_interval: 1m
+remote_config_cache_location: ` + dir + `
+remote_configuration:
+  namespace: tenant
+`
+
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(cfgText), &cfg.AgentManagement))
+
+	provider, err := newRemoteConfigHTTPProvider(&cfg)
+	require.NoError(t, err)
+
+	_, err = provider.FetchRemoteConfig()
+	require.NoError(t, err)
+	require.Equal(t, "tenant", gotUsername)
+	require.Equal(t, "tenant-secret", gotPassword)
+}
+
+func TestValidateTLSConfig(t *testing.T) {
+	cfg := validAgentManagementConfig
+	cfg.TLSConfig = &config.TLSConfig{CertFile: "client.crt"}
+	assert.EqualError(t, cfg.Validate(), "both cert_file and key_file must be specified in 'agent_management.tls_config'")
+
+	cfg.TLSConfig.KeyFile = "client.key"
+	assert.NoError(t, cfg.Validate())
+
+	// A client certificate is enough to authenticate with.
+	cfg.BasicAuth = config.BasicAuth{}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.TLSConfig = &config.TLSConfig{CAFile: "ca.crt"}
+	assert.EqualError(t, cfg.Validate(), "both username and password_file fields must be specified")
+
+	cfg.HTTPClientConfig = &config.HTTPClientConfig{}
+	assert.EqualError(t, cfg.Validate(), "at most one of 'agent_management.tls_config' and 'agent_management.http_client_config' must be specified, use 'http_client_config.tls_config' instead")
+}
+
+func TestFetchRemoteConfig_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := writeTestCertificate(t, dir, "ca", nil, nil)
+	writeTestCertificate(t, dir, "client", ca, caKey)
+	server, serverKey := writeTestCertificate(t, dir, "server", ca, caKey)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	svr := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("base_config: ''"))
+	}))
+	svr.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{server.Raw}, PrivateKey: serverKey}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+	svr.StartTLS()
+	defer svr.Close()
+
+	cfgText := `
+api_url: ` + svr.URL + `
+tls_config:
+  ca_file: ` + filepath.Join(dir, "ca.crt") + `
+  cert_file: ` + filepath.Join(dir, "client.crt") + `
+  key_file: ` + filepath.Join(dir, "client.key") + `
+protocol: http
+polling_interval: 1m
+remote_config_cache_location: ` + dir + `
+remote_configuration:
+  namespace: test_namespace
+`
+
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(cfgText), &cfg.AgentManagement))
+
+	provider, err := newRemoteConfigHTTPProvider(&cfg)
+	require.NoError(t, err)
+
+	bb, err := provider.FetchRemoteConfig()
+	require.NoError(t, err)
+	require.Equal(t, "base_config: ''", string(bb))
+
+	// Requests without the client certificate are rejected.
+	cfg.AgentManagement.TLSConfig.CertFile = ""
+	cfg.AgentManagement.TLSConfig.KeyFile = ""
+	cfg.AgentManagement.BasicAuth = config.BasicAuth{Username: "test", PasswordFile: filepath.Join(dir, "ca.crt")}
+	provider, err = newRemoteConfigHTTPProvider(&cfg)
+	require.NoError(t, err)
+	_, err = provider.FetchRemoteConfig()
+	require.Error(t, err)
+}
+
+// writeTestCertificate writes a certificate and key for 127.0.0.1 named
+// <name>.crt and <name>.key to dir. The certificate is a self-signed CA if
+// parent is nil, and signed by parent otherwise.
+func writeTestCertificate(t *testing.T, dir, name string, parent *x509.Certificate, parentKey crypto.Signer) (*x509.Certificate, crypto.Signer) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return cert, key
+}
+
+func TestFetchRemoteConfig_NotModified(t *testing.T) {
+	const lastModified = "Wed, 21 Oct 2015 07:28:00 GMT"
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", lastModified)
+		_, _ = w.Write([]byte("base_config: ''"))
+	}))
+	defer svr.Close()
+
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret"), 0600))
+
+	var cfg Config
+	cfg.AgentManagement = validAgentManagementConfig
+	cfg.AgentManagement.Url = apiURLs{svr.URL}
+	cfg.AgentManagement.BasicAuth.PasswordFile = passwordFile
+	cfg.AgentManagement.CacheLocation = dir
+
+	poller := NewRemoteConfigPoller()
+	provider, err := newRemoteConfigHTTPProvider(&cfg)
+	require.NoError(t, err)
+	provider.poller = poller
+
+	_, err = provider.FetchRemoteConfig()
+	require.NoError(t, err)
+
+	_, err = provider.FetchRemoteConfig()
+	require.ErrorIs(t, err, ErrRemoteConfigNotModified)
+
+	// Changing the initial config fetches the full remote config again.
+	cfg.AgentManagement.RemoteConfiguration.Namespace = "other_namespace"
+	provider, err = newRemoteConfigHTTPProvider(&cfg)
+	require.NoError(t, err)
+	provider.poller = poller
+
+	_, err = provider.FetchRemoteConfig()
+	require.NoError(t, err)
+}
+
+func TestGetRemoteConfig_NotModified(t *testing.T) {
+	defaultCfg := DefaultConfig()
+
+	am := validAgentManagementConfig
+	logger := server.NewLogger(defaultCfg.Server)
+	testProvider := testRemoteConfigProvider{InitialConfig: &am}
+	testProvider.fetchedConfigErrorToReturn = ErrRemoteConfigNotModified
+	testProvider.cachedConfigToReturn = cachedConfig
+
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	features.Register(fs, allFeatures)
+	defaultCfg.RegisterFlags(fs)
+
+	// An unchanged remote config isn't loaded again, not even from the cache.
+	_, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
+	require.ErrorIs(t, err, ErrRemoteConfigNotModified)
+	require.False(t, testProvider.didCacheRemoteConfig)
+}
+
+func TestFetchRemoteConfig_SignatureVerification(t *testing.T) {
+	remoteConfig := []byte("base_config: ''")
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecdsaDigest := sha256.Sum256(remoteConfig)
+	ecdsaSig, err := ecdsa.SignASN1(rand.Reader, ecdsaKey, ecdsaDigest[:])
+	require.NoError(t, err)
+
+	ed25519Pub, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ed25519Sig := ed25519.Sign(ed25519Key, remoteConfig)
+
+	tt := []struct {
+		name      string
+		publicKey crypto.PublicKey
+		signature []byte
+		expectErr string
+	}{
+		{name: "valid ecdsa signature", publicKey: &ecdsaKey.PublicKey, signature: ecdsaSig},
+		{name: "valid ed25519 signature", publicKey: ed25519Pub, signature: ed25519Sig},
+		{name: "signature for another key", publicKey: ed25519Pub, signature: ecdsaSig, expectErr: "signature verification failed"},
+		{name: "missing signature", publicKey: ed25519Pub, expectErr: "remote config is not signed"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.signature != nil {
+					w.Header().Set(DefaultSignatureHeader, base64.StdEncoding.EncodeToString(tc.signature))
+				}
+				_, _ = w.Write(remoteConfig)
+			}))
+			defer svr.Close()
+
+			dir := t.TempDir()
+			passwordFile := filepath.Join(dir, "password")
+			require.NoError(t, os.WriteFile(passwordFile, []byte("secret"), 0600))
+
+			der, err := x509.MarshalPKIXPublicKey(tc.publicKey)
+			require.NoError(t, err)
+			keyFile := filepath.Join(dir, "key.pem")
+			require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+			var cfg Config
+			cfg.AgentManagement = validAgentManagementConfig
+			cfg.AgentManagement.Url = apiURLs{svr.URL}
+			cfg.AgentManagement.BasicAuth.PasswordFile = passwordFile
+			cfg.AgentManagement.CacheLocation = dir
+			cfg.AgentManagement.SignatureVerification = &SignatureVerificationConfig{PublicKeyFile: keyFile}
+
+			provider, err := newRemoteConfigHTTPProvider(&cfg)
+			require.NoError(t, err)
+
+			bb, err := provider.FetchRemoteConfig()
+			if tc.expectErr != "" {
+				require.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, remoteConfig, bb)
+		})
+	}
+}
+
 func TestRemoteConfigHashCheck(t *testing.T) {
 	// not a truly valid Agent Management config, but used for testing against
 	// precomputed sha256 hash
@@ -173,7 +774,7 @@ func TestNewRemoteConfigHTTPProvider_InvalidInitialConfig(t *testing.T) {
 	// this is invalid because it is missing the password file
 	invalidAgentManagementConfig := &AgentManagementConfig{
 		Enabled: true,
-		Url:     "https://localhost:1234/example/api",
+		Url:     apiURLs{"https://localhost:1234/example/api"},
 		BasicAuth: config.BasicAuth{
 			Username: "test",
 		},
@@ -211,7 +812,7 @@ func TestGetRemoteConfig_UnmarshallableRemoteConfig(t *testing.T) {
 	features.Register(fs, allFeatures)
 	defaultCfg.RegisterFlags(fs)
 
-	cfg, err := getRemoteConfig(true, &testProvider, logger, fs, []string{}, "test")
+	cfg, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
 	assert.NoError(t, err)
 	assert.False(t, testProvider.didCacheRemoteConfig)
 
@@ -237,15 +838,24 @@ func TestGetRemoteConfig_RemoteFetchFails(t *testing.T) {
 	features.Register(fs, allFeatures)
 	defaultCfg.RegisterFlags(fs)
 
-	cfg, err := getRemoteConfig(true, &testProvider, logger, fs, []string{}, "test")
+	poller := NewRemoteConfigPoller()
+	cfg, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Poller: poller, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
 	assert.NoError(t, err)
 	assert.False(t, testProvider.didCacheRemoteConfig)
+	assert.Equal(t, int64(1), poller.fetchFailures.Load())
 
 	// check that the returned config is the cached one
 	// Note: Validate is required for the comparison as it mutates the config
 	expected := defaultCfg
 	expected.Validate(fs)
 	assert.True(t, util.CompareYAML(*cfg, expected))
+
+	// A successful fetch resets the failure count.
+	testProvider.fetchedConfigErrorToReturn = nil
+	testProvider.fetchedConfigBytesToReturn = cachedConfig
+	_, _, err = getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Poller: poller, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), poller.fetchFailures.Load())
 }
 
 func TestGetRemoteConfig_SemanticallyInvalidBaseConfig(t *testing.T) {
@@ -283,7 +893,7 @@ snippets: []
 	features.Register(fs, allFeatures)
 	defaultCfg.RegisterFlags(fs)
 
-	cfg, err := getRemoteConfig(true, &testProvider, logger, fs, []string{}, "test")
+	cfg, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
 	assert.NoError(t, err)
 	assert.False(t, testProvider.didCacheRemoteConfig)
 
@@ -322,7 +932,7 @@ snippets:
 	features.Register(fs, allFeatures)
 	defaultCfg.RegisterFlags(fs)
 
-	cfg, err := getRemoteConfig(true, &testProvider, logger, fs, []string{}, "test")
+	cfg, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
 	assert.NoError(t, err)
 	assert.False(t, testProvider.didCacheRemoteConfig)
 
@@ -351,7 +961,7 @@ snippets: []
 	features.Register(fs, allFeatures)
 	defaultCfg.RegisterFlags(fs)
 
-	cfg, err := getRemoteConfig(true, &testProvider, logger, fs, []string{}, "test")
+	cfg, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
 	assert.NoError(t, err)
 	assert.True(t, testProvider.didCacheRemoteConfig)
 
@@ -406,7 +1016,7 @@ snippets:
 	features.Register(fs, allFeatures)
 	defaultCfg.RegisterFlags(fs)
 
-	cfg, err := getRemoteConfig(true, &testProvider, logger, fs, []string{}, "test")
+	cfg, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
 	assert.NoError(t, err)
 	assert.True(t, testProvider.didCacheRemoteConfig)
 
@@ -421,6 +1031,76 @@ snippets:
 	assert.Equal(t, 1, len(cfg.Integrations.configV1.Integrations))
 }
 
+func TestGetRemoteConfig_PartialApply(t *testing.T) {
+	defaultCfg := DefaultConfig()
+	partiallyValidConfig := `
+base_config: |
+  server:
+    log_level: debug
+    log_format: logfmt
+snippets:
+- config: |
+    metrics_scrape_configs:
+    - job_name: 'good'
+      static_configs:
+      - targets: ['localhost:12345']
+    - job_name: 'bad_interval'
+      scrape_interval: not-a-duration
+      static_configs:
+      - targets: ['localhost:12345']
+- config: |
+    metrics_scrape_configs:
+    - job_name: 'good'
+      static_configs:
+      - targets: ['localhost:12346']
+    - job_name: 'also_good'
+      static_configs:
+      - targets: ['localhost:12347']
+`
+	am := validAgentManagementConfig
+	logger := server.NewLogger(defaultCfg.Server)
+
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	features.Register(fs, allFeatures)
+	defaultCfg.RegisterFlags(fs)
+
+	t.Run("disabled", func(t *testing.T) {
+		testProvider := testRemoteConfigProvider{InitialConfig: &am}
+		testProvider.fetchedConfigBytesToReturn = []byte(partiallyValidConfig)
+		testProvider.cachedConfigToReturn = cachedConfig
+
+		cfg, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
+		require.NoError(t, err)
+		assert.False(t, testProvider.didCacheRemoteConfig)
+		assert.NotEqual(t, "debug", cfg.Server.LogLevel.String())
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		testProvider := testRemoteConfigProvider{InitialConfig: &am}
+		testProvider.fetchedConfigBytesToReturn = []byte(partiallyValidConfig)
+		testProvider.cachedConfigToReturn = cachedConfig
+
+		poller := NewRemoteConfigPoller()
+		cfg, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, PartialApply: true, Poller: poller, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
+		require.NoError(t, err)
+		require.NoError(t, poller.RemoteConfigApplied(nil))
+		assert.True(t, testProvider.didCacheRemoteConfig)
+		assert.Equal(t, "debug", cfg.Server.LogLevel.String())
+
+		var jobs []string
+		for _, sc := range cfg.Metrics.Configs[0].ScrapeConfigs {
+			jobs = append(jobs, sc.JobName)
+		}
+		assert.Equal(t, []string{"good", "also_good"}, jobs)
+
+		require.Len(t, testProvider.reportedStatuses, 1)
+		skipped := testProvider.reportedStatuses[0].Skipped
+		require.Len(t, skipped, 2)
+		assert.Contains(t, skipped[0], `snippet 0: metrics scrape config "bad_interval"`)
+		assert.Contains(t, skipped[1], `snippet 1: metrics scrape config "good"`)
+	})
+}
+
 func TestGetRemoteConfig_ExpandsEnvVars(t *testing.T) {
 	defaultCfg := DefaultConfig()
 	validConfig := `
@@ -466,8 +1146,177 @@ snippets:
 	features.Register(fs, allFeatures)
 	defaultCfg.RegisterFlags(fs)
 
-	cfg, err := getRemoteConfig(true, &testProvider, logger, fs, []string{"-config.expand-env"}, "test")
+	cfg, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, Provider: &testProvider, Log: logger, Flags: fs, Args: []string{"-config.expand-env"}, ConfigPath: "test"})
 	assert.NoError(t, err)
 	assert.Equal(t, "15s", cfg.Metrics.Configs[0].ScrapeConfigs[0].ScrapeInterval.String())
 	assert.Equal(t, "json", cfg.Server.LogFormat.String())
 }
+
+func TestGetRemoteConfig_ExpandEnvAllowlist(t *testing.T) {
+	defaultCfg := DefaultConfig()
+	validConfig := `
+base_config: ''
+snippets:
+- config: |
+    metrics_scrape_configs:
+    - job_name: 'prometheus'
+      scrape_interval: ${SCRAPE_INTERVAL}
+      static_configs:
+      - targets: ['localhost:12345']
+      relabel_configs:
+      - source_labels: [__address__]
+        regex: '(?P<host>[^:]+):.*'
+        target_label: instance
+        replacement: '${host}'
+  selector:
+    hostname: machine-1
+    team: team-a
+`
+	t.Setenv("SCRAPE_INTERVAL", "15s")
+	t.Setenv("host", "not-allowed")
+
+	am := validAgentManagementConfig
+	logger := server.NewLogger(defaultCfg.Server)
+	testProvider := testRemoteConfigProvider{InitialConfig: &am}
+	testProvider.fetchedConfigBytesToReturn = []byte(validConfig)
+	testProvider.cachedConfigToReturn = cachedConfig
+
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	features.Register(fs, allFeatures)
+	defaultCfg.RegisterFlags(fs)
+
+	// Only allowlisted variables are expanded, so the named capture group
+	// reference of the relabel rule is kept.
+	cfg, _, err := getRemoteConfig(remoteConfigOptions{ExpandEnvVars: true, EnvAllowlist: []string{"SCRAPE_INTERVAL"}, Provider: &testProvider, Log: logger, Flags: fs, ConfigPath: "test"})
+	require.NoError(t, err)
+	scrapeConfig := cfg.Metrics.Configs[0].ScrapeConfigs[0]
+	assert.Equal(t, "15s", scrapeConfig.ScrapeInterval.String())
+	assert.Equal(t, "${host}", scrapeConfig.RelabelConfigs[0].Replacement)
+}
+
+func TestLoadFromAgentManagementAPI_Unchanged(t *testing.T) {
+	remoteConfig := []byte("base_config: ''")
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The response has no cache validators, so every fetch returns the full
+		// remote config.
+		_, _ = w.Write(remoteConfig)
+	}))
+	defer svr.Close()
+
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret"), 0600))
+
+	writeInitialConfig := func(namespace string) string {
+		path := filepath.Join(dir, "agent.yaml")
+		initialConfig := `
+agent_management:
+  api_url: ` + svr.URL + `
+  protocol: http
+  polling_interval: 1m
+  remote_config_cache_location: ` + dir + `
+  basic_auth:
+    username: test
+    password_file: ` + passwordFile + `
+  remote_configuration:
+    namespace: ` + namespace + `
+`
+		require.NoError(t, os.WriteFile(path, []byte(initialConfig), 0600))
+		return path
+	}
+
+	poller := NewRemoteConfigPoller()
+	load := func(path string) error {
+		defaultCfg := DefaultConfig()
+		fs := flag.NewFlagSet("test", flag.ExitOnError)
+		features.Register(fs, allFeatures)
+		defaultCfg.RegisterFlags(fs)
+
+		var c Config
+		return loadFromAgentManagementAPI(path, false, &c, poller, server.NewLogger(defaultCfg.Server), fs, []string{})
+	}
+
+	path := writeInitialConfig("test_namespace")
+	require.NoError(t, load(path))
+	require.ErrorIs(t, load(path), ErrRemoteConfigNotModified)
+
+	// A changed remote config is loaded again.
+	remoteConfig = []byte("base_config: 'server: {log_level: debug}'")
+	require.NoError(t, load(path))
+	require.ErrorIs(t, load(path), ErrRemoteConfigNotModified)
+
+	// A changed initial config loads the same remote config again.
+	path = writeInitialConfig("other_namespace")
+	require.NoError(t, load(path))
+}
+
+func TestLoadFromAgentManagementAPI_ApplyFails(t *testing.T) {
+	lastModified := "Wed, 21 Oct 2015 07:28:00 GMT"
+	var fetches int
+	remoteConfig := []byte("base_config: ''")
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		if r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", lastModified)
+		_, _ = w.Write(remoteConfig)
+	}))
+	defer svr.Close()
+
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret"), 0600))
+	path := filepath.Join(dir, "agent.yaml")
+	initialConfig := `
+agent_management:
+  api_url: ` + svr.URL + `
+  protocol: http
+  polling_interval: 1m
+  remote_config_cache_location: ` + dir + `
+  basic_auth:
+    username: test
+    password_file: ` + passwordFile + `
+  remote_configuration:
+    namespace: test_namespace
+`
+	require.NoError(t, os.WriteFile(path, []byte(initialConfig), 0600))
+
+	poller := NewRemoteConfigPoller()
+	load := func() error {
+		defaultCfg := DefaultConfig()
+		fs := flag.NewFlagSet("test", flag.ExitOnError)
+		features.Register(fs, allFeatures)
+		defaultCfg.RegisterFlags(fs)
+
+		var c Config
+		return loadFromAgentManagementAPI(path, false, &c, poller, server.NewLogger(defaultCfg.Server), fs, []string{})
+	}
+	readCache := func() string {
+		buf, err := os.ReadFile(filepath.Join(dir, cacheFilename))
+		require.NoError(t, err)
+		var configCache remoteConfigCache
+		require.NoError(t, json.Unmarshal(buf, &configCache))
+		return configCache.Config
+	}
+
+	require.NoError(t, load())
+	require.NoError(t, poller.RemoteConfigApplied(nil))
+	require.ErrorIs(t, load(), ErrRemoteConfigNotModified)
+
+	// A remote config which fails to apply is removed from the cache, and
+	// fetched and loaded again by the next poll.
+	goodConfig := string(remoteConfig)
+	remoteConfig = []byte("base_config: 'server: {log_level: debug}'")
+	lastModified = "Thu, 22 Oct 2015 07:28:00 GMT"
+	require.NoError(t, load())
+	require.Equal(t, string(remoteConfig), readCache())
+	require.NoError(t, poller.RemoteConfigApplied(errors.New("apply failed")))
+	require.Equal(t, goodConfig, readCache())
+
+	fetchesBefore := fetches
+	require.NoError(t, load())
+	require.Equal(t, fetchesBefore+1, fetches)
+	require.Equal(t, string(remoteConfig), readCache())
+}
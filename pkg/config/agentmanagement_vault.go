@@ -0,0 +1,258 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+	"github.com/prometheus/common/config"
+)
+
+// defaultVaultPasswordKey is the field of the secret at
+// 'agent_management.vault.password_path' holding the password.
+const defaultVaultPasswordKey = "password"
+
+// VaultConfig configures reading the password of the Agent Management API and
+// secrets referenced by remote configs from HashiCorp Vault, instead of
+// storing them in plaintext files on disk.
+type VaultConfig struct {
+	Address   string `yaml:"address"`
+	Namespace string `yaml:"namespace,omitempty"`
+
+	// Exactly one of Token or TokenFile must be set. TokenFile is read before
+	// every request, so that tokens rotated by a Vault Agent sink are picked
+	// up. Renewable tokens are renewed once half of their TTL has passed.
+	Token     config.Secret `yaml:"token,omitempty"`
+	TokenFile string        `yaml:"token_file,omitempty"`
+
+	// PasswordPath is the path of the secret holding the basic_auth password,
+	// which is read from its PasswordKey field. It replaces
+	// 'basic_auth.password_file'.
+	PasswordPath string `yaml:"password_path,omitempty"`
+	PasswordKey  string `yaml:"password_key,omitempty"`
+
+	// Secrets maps names of template variables to references of the form
+	// path#key. The secrets are read from Vault and substituted in remote
+	// configs along with 'agent_management.template_variables'.
+	Secrets map[string]string `yaml:"secrets,omitempty"`
+}
+
+// passwordKey returns the field of the secret holding the password.
+func (vc *VaultConfig) passwordKey() string {
+	if vc.PasswordKey == "" {
+		return defaultVaultPasswordKey
+	}
+	return vc.PasswordKey
+}
+
+// vaultClientCache holds the Vault client of the last used config, so that
+// the token renewal schedule survives across fetches.
+type vaultClientCache struct {
+	mut     sync.Mutex
+	key     string
+	client  *vault.Client
+	renewAt time.Time
+}
+
+// vaultClient returns a client for the Vault server of vc, renewing its token
+// if it's due. The client is cached on poller; a new client is created every
+// time if poller is nil.
+func (vc *VaultConfig) vaultClient(poller *RemoteConfigPoller) (*vault.Client, error) {
+	token := string(vc.Token)
+	if vc.TokenFile != "" {
+		bb, err := os.ReadFile(vc.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading vault token file: %w", err)
+		}
+		token = strings.TrimSpace(string(bb))
+	}
+
+	cache := &vaultClientCache{}
+	if poller != nil {
+		cache = &poller.vault
+	}
+	cache.mut.Lock()
+	defer cache.mut.Unlock()
+
+	key := strings.Join([]string{vc.Address, vc.Namespace, token}, "\x00")
+	if cache.key != key {
+		cfg := vault.DefaultConfig()
+		cfg.Address = vc.Address
+		client, err := vault.NewClient(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("error creating vault client: %w", err)
+		}
+		client.SetToken(token)
+		if vc.Namespace != "" {
+			client.SetNamespace(vc.Namespace)
+		}
+
+		cache.key = key
+		cache.client = client
+		cache.renewAt = time.Time{}
+	}
+
+	client := cache.client
+	if now := time.Now(); !now.Before(cache.renewAt) {
+		renewAt, err := renewVaultToken(client, now)
+		if err != nil {
+			return nil, err
+		}
+		cache.renewAt = renewAt
+	}
+	return client, nil
+}
+
+// renewVaultToken renews the token of client if it's renewable and returns
+// when it should be renewed next. Tokens which can't be renewed, such as root
+// tokens, are only checked again after an hour.
+func renewVaultToken(client *vault.Client, now time.Time) (time.Time, error) {
+	const recheckInterval = time.Hour
+
+	self, err := client.Auth().Token().LookupSelf()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error looking up vault token: %w", err)
+	}
+	renewable, err := self.TokenIsRenewable()
+	if err != nil || !renewable {
+		return now.Add(recheckInterval), nil
+	}
+
+	renewed, err := client.Auth().Token().RenewSelf(0)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error renewing vault token: %w", err)
+	}
+	ttl, err := renewed.TokenTTL()
+	if err != nil || ttl <= 0 {
+		return now.Add(recheckInterval), nil
+	}
+	return now.Add(ttl / 2), nil
+}
+
+// readSecret reads the field key of the secret at path. The data of secrets
+// in KV version 2 engines is unwrapped.
+func (vc *VaultConfig) readSecret(poller *RemoteConfigPoller, path, key string) (string, error) {
+	client, err := vc.vaultClient(poller)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := client.Logical().Read(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading vault secret %q: %w", path, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return "", fmt.Errorf("vault secret %q not found", path)
+	}
+
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, isKV2 := data["metadata"]; isKV2 {
+			data = nested
+		}
+	}
+	value, ok := data[key].(string)
+	if !ok {
+		return "", fmt.Errorf("vault secret %q has no string field %q", path, key)
+	}
+	return value, nil
+}
+
+// applyVaultPassword sets the basic_auth password of cfg to the password read
+// from 'agent_management.vault.password_path', if set.
+func (am *AgentManagementConfig) applyVaultPassword(poller *RemoteConfigPoller, cfg *config.HTTPClientConfig) error {
+	if am.Vault == nil || am.Vault.PasswordPath == "" || cfg.BasicAuth == nil {
+		return nil
+	}
+	password, err := am.Vault.readSecret(poller, am.Vault.PasswordPath, am.Vault.passwordKey())
+	if err != nil {
+		return err
+	}
+
+	basicAuth := *cfg.BasicAuth
+	basicAuth.Password = config.Secret(password)
+	basicAuth.PasswordFile = ""
+	cfg.BasicAuth = &basicAuth
+	return nil
+}
+
+// vaultSecrets reads the secrets of 'agent_management.vault.secrets', keyed
+// by the names of their template variables.
+func (am *AgentManagementConfig) vaultSecrets(poller *RemoteConfigPoller) (map[string]string, error) {
+	if am.Vault == nil || len(am.Vault.Secrets) == 0 {
+		return nil, nil
+	}
+
+	res := make(map[string]string, len(am.Vault.Secrets))
+	for name, ref := range am.Vault.Secrets {
+		path, key, _ := parseVaultSecretRef(ref)
+		value, err := am.Vault.readSecret(poller, path, key)
+		if err != nil {
+			return nil, err
+		}
+		res[name] = value
+	}
+	return res, nil
+}
+
+// parseVaultSecretRef splits a reference of the form path#key.
+func parseVaultSecretRef(ref string) (path, key string, ok bool) {
+	i := strings.LastIndex(ref, "#")
+	if i <= 0 || i == len(ref)-1 {
+		return "", "", false
+	}
+	return ref[:i], ref[i+1:], true
+}
+
+// hasVaultPassword returns true if the basic_auth password is read from
+// Vault.
+func (am *AgentManagementConfig) hasVaultPassword() bool {
+	return am.Vault != nil && am.Vault.PasswordPath != ""
+}
+
+// validateVault checks the settings of 'agent_management.vault'.
+func (am *AgentManagementConfig) validateVault() error {
+	vc := am.Vault
+	if vc == nil {
+		return nil
+	}
+
+	if vc.Address == "" {
+		return errors.New("'agent_management.vault.address' must be specified")
+	}
+	if (vc.Token == "") == (vc.TokenFile == "") {
+		return errors.New("exactly one of 'agent_management.vault.token' and 'agent_management.vault.token_file' must be specified")
+	}
+	if vc.PasswordPath == "" && len(vc.Secrets) == 0 {
+		return errors.New("at least one of 'agent_management.vault.password_path' and 'agent_management.vault.secrets' must be specified")
+	}
+
+	if vc.PasswordPath != "" {
+		if am.Protocol == protocolGit || isObjectStorageProtocol(am.Protocol) {
+			return fmt.Errorf("'agent_management.vault.password_path' is not supported with the %s protocol", am.Protocol)
+		}
+		if am.HTTPClientConfig != nil {
+			return errors.New("at most one of 'agent_management.vault.password_path' and 'agent_management.http_client_config' must be specified")
+		}
+		if am.BasicAuth.PasswordFile != "" {
+			return errors.New("at most one of 'agent_management.vault.password_path' and 'agent_management.basic_auth.password_file' must be specified")
+		}
+	}
+
+	for name, ref := range vc.Secrets {
+		if !templateVariableNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid name %q in 'agent_management.vault.secrets': names must start with a letter or underscore and contain only letters, digits, and underscores", name)
+		}
+		if _, ok := am.TemplateVariables[name]; ok {
+			return fmt.Errorf("%q is defined in both 'agent_management.vault.secrets' and 'agent_management.template_variables'", name)
+		}
+		if _, _, ok := parseVaultSecretRef(ref); !ok {
+			return fmt.Errorf("invalid reference %q for %q in 'agent_management.vault.secrets': expected path#key", ref, name)
+		}
+	}
+	return nil
+}
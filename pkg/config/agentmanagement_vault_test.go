@@ -0,0 +1,184 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/common/config"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeVault returns a Vault server serving a KV version 2 secret at
+// secret/data/agent and a renewable token. The number of token renewals is
+// counted in renewals.
+func newFakeVault(t *testing.T, renewals *int64) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/auth/token/lookup-self":
+			_, _ = w.Write([]byte(`{"data":{"id":"s.token","renewable":true,"ttl":3600}}`))
+		case "/v1/auth/token/renew-self":
+			atomic.AddInt64(renewals, 1)
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"s.token","renewable":true,"lease_duration":3600}}`))
+		case "/v1/secret/data/agent":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"vault-password","api_key":"key-123"},"metadata":{"version":1}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestValidateVault(t *testing.T) {
+	tt := []struct {
+		name   string
+		modify func(am *AgentManagementConfig)
+		errMsg string
+	}{
+		{
+			name: "password path",
+			modify: func(am *AgentManagementConfig) {
+				am.BasicAuth.PasswordFile = ""
+				am.Vault = &VaultConfig{Address: "http://vault:8200", Token: "s.token", PasswordPath: "secret/data/agent"}
+			},
+		},
+		{
+			name: "secrets",
+			modify: func(am *AgentManagementConfig) {
+				am.Vault = &VaultConfig{Address: "http://vault:8200", TokenFile: "/token", Secrets: map[string]string{"api_key": "secret/data/agent#api_key"}}
+			},
+		},
+		{
+			name: "missing address",
+			modify: func(am *AgentManagementConfig) {
+				am.Vault = &VaultConfig{Token: "s.token", PasswordPath: "secret/data/agent"}
+			},
+			errMsg: "'agent_management.vault.address' must be specified",
+		},
+		{
+			name: "token and token file",
+			modify: func(am *AgentManagementConfig) {
+				am.Vault = &VaultConfig{Address: "http://vault:8200", Token: "s.token", TokenFile: "/token", PasswordPath: "secret/data/agent"}
+			},
+			errMsg: "exactly one of 'agent_management.vault.token' and 'agent_management.vault.token_file' must be specified",
+		},
+		{
+			name: "nothing to read",
+			modify: func(am *AgentManagementConfig) {
+				am.Vault = &VaultConfig{Address: "http://vault:8200", Token: "s.token"}
+			},
+			errMsg: "at least one of 'agent_management.vault.password_path' and 'agent_management.vault.secrets' must be specified",
+		},
+		{
+			name: "password path and password file",
+			modify: func(am *AgentManagementConfig) {
+				am.Vault = &VaultConfig{Address: "http://vault:8200", Token: "s.token", PasswordPath: "secret/data/agent"}
+			},
+			errMsg: "at most one of 'agent_management.vault.password_path' and 'agent_management.basic_auth.password_file' must be specified",
+		},
+		{
+			name: "missing username",
+			modify: func(am *AgentManagementConfig) {
+				am.BasicAuth = config.BasicAuth{}
+				am.Vault = &VaultConfig{Address: "http://vault:8200", Token: "s.token", PasswordPath: "secret/data/agent"}
+			},
+			errMsg: "both username and password_file fields must be specified",
+		},
+		{
+			name: "invalid secret reference",
+			modify: func(am *AgentManagementConfig) {
+				am.Vault = &VaultConfig{Address: "http://vault:8200", Token: "s.token", Secrets: map[string]string{"api_key": "secret/data/agent"}}
+			},
+			errMsg: `invalid reference "secret/data/agent" for "api_key" in 'agent_management.vault.secrets': expected path#key`,
+		},
+		{
+			name: "secret shadows template variable",
+			modify: func(am *AgentManagementConfig) {
+				am.TemplateVariables = map[string]string{"api_key": "plain"}
+				am.Vault = &VaultConfig{Address: "http://vault:8200", Token: "s.token", Secrets: map[string]string{"api_key": "secret/data/agent#api_key"}}
+			},
+			errMsg: `"api_key" is defined in both 'agent_management.vault.secrets' and 'agent_management.template_variables'`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			am := validAgentManagementConfig
+			tc.modify(&am)
+			err := am.Validate()
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestVaultPassword(t *testing.T) {
+	var renewals int64
+	srv := newFakeVault(t, &renewals)
+
+	am := validAgentManagementConfig
+	am.BasicAuth.PasswordFile = ""
+	am.Vault = &VaultConfig{Address: srv.URL, Token: "s.token", PasswordPath: "secret/data/agent"}
+
+	poller := NewRemoteConfigPoller()
+	cfg, err := am.httpClientConfigFor(poller, "https://agent-management.example.com/api")
+	require.NoError(t, err)
+	require.Equal(t, config.Secret("vault-password"), cfg.BasicAuth.Password)
+	require.Empty(t, cfg.BasicAuth.PasswordFile)
+
+	// The token is only renewed once half of its TTL has passed.
+	_, err = am.httpClientConfigFor(poller, "https://agent-management.example.com/api")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), atomic.LoadInt64(&renewals))
+}
+
+func TestVaultSecrets(t *testing.T) {
+	var renewals int64
+	srv := newFakeVault(t, &renewals)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("s.token\n"), 0600))
+
+	am := validAgentManagementConfig
+	am.TemplateVariables = map[string]string{"region": "eu"}
+	am.Vault = &VaultConfig{
+		Address:   srv.URL,
+		TokenFile: tokenFile,
+		Secrets: map[string]string{
+			"api_key":  "secret/data/agent#api_key",
+			"password": "secret/data/agent#password",
+		},
+	}
+
+	poller := NewRemoteConfigPoller()
+	vars, err := am.templateVariables(poller)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"region":   "eu",
+		"api_key":  "key-123",
+		"password": "vault-password",
+	}, vars)
+
+	am.Vault.Secrets = map[string]string{"missing": "secret/data/agent#missing"}
+	_, err = am.templateVariables(poller)
+	require.EqualError(t, err, `vault secret "secret/data/agent" has no string field "missing"`)
+
+	require.NoError(t, os.WriteFile(tokenFile, []byte("s.revoked"), 0600))
+	_, err = am.templateVariables(poller)
+	require.ErrorContains(t, err, "error looking up vault token")
+}
@@ -17,9 +17,11 @@ import (
 	"github.com/grafana/agent/pkg/config/instrumentation"
 	"github.com/grafana/agent/pkg/logs"
 	"github.com/grafana/agent/pkg/metrics"
+	"github.com/grafana/agent/pkg/runtimelimits"
 	"github.com/grafana/agent/pkg/server"
 	"github.com/grafana/agent/pkg/traces"
 	"github.com/grafana/agent/pkg/util"
+	"github.com/grafana/dskit/flagext"
 	"github.com/prometheus/common/config"
 	"github.com/prometheus/common/version"
 	"github.com/stretchr/testify/require"
@@ -56,6 +58,7 @@ func DefaultConfig() Config {
 		// All subsystems with a DefaultConfig should be listed here.
 		Server:                &defaultServerCfg,
 		ServerFlags:           server.DefaultFlags,
+		RuntimeLimits:         runtimelimits.DefaultOptions,
 		Metrics:               metrics.DefaultConfig,
 		Integrations:          DefaultVersionedIntegrations,
 		DisableSupportBundle:  false,
@@ -74,7 +77,8 @@ type Config struct {
 	AgentManagement AgentManagementConfig `yaml:"agent_management,omitempty"`
 
 	// Flag-only fields
-	ServerFlags server.Flags `yaml:"-"`
+	ServerFlags   server.Flags          `yaml:"-"`
+	RuntimeLimits runtimelimits.Options `yaml:"-"`
 
 	// Deprecated fields user has used. Generated during UnmarshalYAML.
 	Deprecations []string `yaml:"-"`
@@ -86,6 +90,10 @@ type Config struct {
 	// Toggle for config endpoint(s)
 	EnableConfigEndpoints bool `yaml:"-"`
 
+	// Environment variables which may be expanded in the config. When empty,
+	// all environment variables may be expanded.
+	ExpandEnvAllowlist flagext.StringSliceCSV `yaml:"-"`
+
 	// Toggle for support bundle generation.
 	DisableSupportBundle bool `yaml:"-"`
 
@@ -213,6 +221,10 @@ func (c *Config) Validate(fs *flag.FlagSet) error {
 		}
 	}
 
+	if err := c.RuntimeLimits.Validate(); err != nil {
+		return err
+	}
+
 	c.Metrics.ServiceConfig.APIEnableGetConfiguration = c.EnableConfigEndpoints
 
 	// Don't validate flags if there's no FlagSet. Used for testing.
@@ -230,6 +242,7 @@ func (c *Config) Validate(fs *flag.FlagSet) error {
 func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	c.Metrics.RegisterFlags(f)
 	c.ServerFlags.RegisterFlags(f)
+	c.RuntimeLimits.RegisterFlags(f)
 
 	f.StringVar(&c.BasicAuthUser, "config.url.basic-auth-user", "",
 		"basic auth username for fetching remote config. (requires remote-configs experiment to be enabled")
@@ -237,6 +250,7 @@ func (c *Config) RegisterFlags(f *flag.FlagSet) {
 		"path to file containing basic auth password for fetching remote config. (requires remote-configs experiment to be enabled")
 
 	f.BoolVar(&c.EnableConfigEndpoints, "config.enable-read-api", false, "Enables the /-/config and /agent/api/v1/configs/{name} APIs. Be aware that secrets could be exposed by enabling these endpoints!")
+	f.Var(&c.ExpandEnvAllowlist, "config.expand-env.allowlist", "Comma-separated list of environment variables which -config.expand-env may expand. Entries ending in * match a prefix. References to other variables are left untouched. If empty, all environment variables are expanded.")
 }
 
 // LoadFile reads a file and passes the contents to Load
@@ -257,27 +271,68 @@ func LoadFile(filename string, expandEnvVars bool, c *Config) error {
 //  2. Get the remote config.
 //     a) Fetch from remote. If this fails or is invalid:
 //     b) Read the remote config from cache. If this fails, return an error.
+//  3. If neither the initial nor the remote config changed since they were
+//     last loaded, return ErrRemoteConfigNotModified.
 //  4. Merge the initial and remote config into c.
-func loadFromAgentManagementAPI(path string, expandEnvVars bool, c *Config, log *server.Logger, fs *flag.FlagSet, args []string) error {
+func loadFromAgentManagementAPI(path string, expandEnvVars bool, c *Config, poller *RemoteConfigPoller, log *server.Logger, fs *flag.FlagSet, args []string) error {
 	// Load the initial config from disk without instrumenting the config hash
 	buf, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("error reading initial config file %w", err)
 	}
 
+	// The allowlist is a flag-only field, which is reset by loading the
+	// initial config.
+	envAllowlist := c.ExpandEnvAllowlist
 	err = LoadBytes(buf, expandEnvVars, c)
 	if err != nil {
 		return fmt.Errorf("failed to load initial config: %w", err)
 	}
 
-	configProvider, err := newRemoteConfigProvider(c)
+	configProvider, err := newRemoteConfigProvider(c, poller)
+	if err != nil {
+		return err
+	}
+	localOverrides, err := c.AgentManagement.readLocalOverrides()
+	if err != nil {
+		return err
+	}
+	checkLocalOverridesChanged(poller, localOverrides)
+	templateVariables, err := c.AgentManagement.templateVariables(poller)
 	if err != nil {
 		return err
 	}
-	remoteConfig, err := getRemoteConfig(expandEnvVars, configProvider, log, fs, args, path)
+	remoteConfig, remoteConfigHash, err := getRemoteConfig(remoteConfigOptions{
+		ExpandEnvVars:     expandEnvVars,
+		EnvAllowlist:      envAllowlist,
+		PartialApply:      c.AgentManagement.RemoteConfiguration.PartialApply,
+		TemplateVariables: templateVariables,
+		LocalOverrides:    localOverrides,
+		Poller:            poller,
+
+		Provider:   configProvider,
+		Log:        log,
+		Flags:      fs,
+		Args:       args,
+		ConfigPath: path,
+	})
 	if err != nil {
 		return err
 	}
+
+	// Skip the reload if the same remote config was already loaded for the
+	// same initial config.
+	initialConfigHash, err := hashInitialConfig(c.AgentManagement)
+	if err != nil {
+		return err
+	}
+	if !poller.setLoadedRemoteConfig(initialConfigHash, remoteConfigHash) {
+		// The same remote config is already applied, so its status is
+		// reported right away.
+		_ = poller.RemoteConfigApplied(nil)
+		level.Debug(log).Log("msg", "remote config hash is unchanged since it was last loaded", "hash", remoteConfigHash)
+		return ErrRemoteConfigNotModified
+	}
 	mergeEffectiveConfig(c, remoteConfig)
 
 	effectiveConfigBytes, err := yaml.Marshal(c)
@@ -359,10 +414,15 @@ func LoadDynamicConfiguration(url string, expandvar bool, c *Config) error {
 	return nil
 }
 
-func performEnvVarExpansion(buf []byte, expandEnvVars bool) ([]byte, error) {
+// performEnvVarExpansion expands references to environment variables in buf
+// if expandEnvVars is set. If allowlist isn't empty, only the variables it
+// allows are expanded; see envAllowed.
+//
+// A literal $ can be written as $$.
+func performEnvVarExpansion(buf []byte, expandEnvVars bool, allowlist []string) ([]byte, error) {
 	// (Optionally) expand with environment variables
 	if expandEnvVars {
-		s, err := envsubst.Eval(string(buf), getenv)
+		s, err := envsubst.Eval(escapeEnvRefs(string(buf), allowlist), os.Getenv)
 		if err != nil {
 			return nil, fmt.Errorf("unable to substitute config with environment variables: %w", err)
 		}
@@ -375,7 +435,7 @@ func performEnvVarExpansion(buf []byte, expandEnvVars bool) ([]byte, error) {
 // applied to the file and must be done manually if LoadBytes
 // is called directly.
 func LoadBytes(buf []byte, expandEnvVars bool, c *Config) error {
-	expandedBuf, err := performEnvVarExpansion(buf, expandEnvVars)
+	expandedBuf, err := performEnvVarExpansion(buf, expandEnvVars, c.ExpandEnvAllowlist)
 	if err != nil {
 		return err
 	}
@@ -383,29 +443,103 @@ func LoadBytes(buf []byte, expandEnvVars bool, c *Config) error {
 	return yaml.UnmarshalStrict(expandedBuf, c)
 }
 
-// getenv is a wrapper around os.Getenv that ignores patterns that are numeric
-// regex capture groups (ie "${1}").
-func getenv(name string) string {
-	numericName := true
+// escapeEnvRefs escapes every ${...} reference in s which mustn't be
+// expanded: references to numeric regex capture groups (ie "${1}") and to
+// variables not allowed by allowlist. Escaped references are left unchanged
+// by envsubst, including any default values they hold.
+func escapeEnvRefs(s string, allowlist []string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] != '$' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			i++
+			continue
+		}
+		if s[i+1] == '$' {
+			sb.WriteString("$$")
+			i += 2
+			continue
+		}
+		if s[i+1] == '{' {
+			end := closingBrace(s, i+1)
+			if end != -1 && !expandEnvRef(s[i+2:end], allowlist) {
+				sb.WriteString(strings.ReplaceAll(s[i:end+1], "$", "$$"))
+				i = end + 1
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+		i++
+	}
+	return sb.String()
+}
 
+// closingBrace returns the index of the brace closing the one at s[open], or
+// -1 if it's never closed.
+func closingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// expandEnvRef reports whether the reference with the given contents (the
+// text between ${ and }) may be expanded.
+func expandEnvRef(ref string, allowlist []string) bool {
+	name := strings.TrimPrefix(ref, "#")
+	if end := strings.IndexFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}); end != -1 {
+		name = name[:end]
+	}
+	if name == "" {
+		// Leave invalid references for envsubst to report.
+		return true
+	}
+
+	numericName := true
 	for _, r := range name {
 		if !unicode.IsDigit(r) {
 			numericName = false
 			break
 		}
 	}
+	return !numericName && envAllowed(name, allowlist)
+}
 
-	if numericName {
-		// We need to add ${} back in since envsubst removes it.
-		return fmt.Sprintf("${%s}", name)
+// envAllowed reports whether the environment variable name may be expanded.
+// Every variable is allowed by an empty allowlist. Entries of allowlist ending
+// in * allow all variables with the preceding prefix.
+func envAllowed(name string, allowlist []string) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+	for _, entry := range allowlist {
+		if strings.HasSuffix(entry, "*") {
+			if strings.HasPrefix(name, strings.TrimSuffix(entry, "*")) {
+				return true
+			}
+		} else if entry == name {
+			return true
+		}
 	}
-	return os.Getenv(name)
+	return false
 }
 
 // Load loads a config file from a flagset. Flags will be registered
 // to the flagset before parsing them with the values specified by
-// args.
-func Load(fs *flag.FlagSet, args []string, log *server.Logger) (*Config, error) {
+// args. When the config is loaded from the Agent Management API, poller
+// holds the state of polling the remote config.
+func Load(fs *flag.FlagSet, args []string, log *server.Logger, poller *RemoteConfigPoller) (*Config, error) {
 	cfg, error := load(fs, args, func(path, fileType string, expandArgs bool, c *Config) error {
 		switch fileType {
 		case fileTypeYAML:
@@ -413,7 +547,7 @@ func Load(fs *flag.FlagSet, args []string, log *server.Logger) (*Config, error)
 				return LoadRemote(path, expandArgs, c)
 			}
 			if features.Enabled(fs, featAgentManagement) {
-				return loadFromAgentManagementAPI(path, expandArgs, c, log, fs, args)
+				return loadFromAgentManagementAPI(path, expandArgs, c, poller, log, fs, args)
 			}
 			return LoadFile(path, expandArgs, c)
 		case fileTypeDynamic:
@@ -432,7 +566,7 @@ func Load(fs *flag.FlagSet, args []string, log *server.Logger) (*Config, error)
 		}
 	})
 
-	instrumentation.InstrumentLoad(error == nil)
+	instrumentation.InstrumentLoad(error == nil || errors.Is(error, ErrRemoteConfigNotModified))
 	return cfg, error
 }
 
@@ -118,8 +118,9 @@ metrics:
   wal_directory: /tmp/wal
   global:
     external_labels:
-      foo: ${1}`
-	expect := labels.Labels{{Name: "foo", Value: "${1}"}}
+      foo: ${1}
+      bar: ${1:-default}`
+	expect := labels.FromStrings("bar", "${1:-default}", "foo", "${1}")
 
 	fs := flag.NewFlagSet("test", flag.ExitOnError)
 	c, err := load(fs, []string{"-config.file", "test"}, func(_, _ string, _ bool, c *Config) error {
@@ -129,6 +130,69 @@ metrics:
 	require.Equal(t, expect, c.Metrics.Global.Prometheus.ExternalLabels)
 }
 
+func TestConfig_OverrideByEnvironmentOnLoad_DefaultsAndEscaping(t *testing.T) {
+	cfg := `
+metrics:
+  wal_directory: /tmp/wal
+  global:
+    external_labels:
+      set: ${SET_VAR:-unused}
+      unset: ${UNSET_VAR:-fallback}
+      escaped: $${SET_VAR}
+      regex: ^foo$`
+	expect := labels.FromStrings(
+		"escaped", "${SET_VAR}",
+		"regex", "^foo$",
+		"set", "value",
+		"unset", "fallback",
+	)
+	t.Setenv("SET_VAR", "value")
+
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	c, err := load(fs, []string{"-config.file", "test"}, func(_, _ string, _ bool, c *Config) error {
+		return LoadBytes([]byte(cfg), true, c)
+	})
+	require.NoError(t, err)
+	require.Equal(t, expect, c.Metrics.Global.Prometheus.ExternalLabels)
+}
+
+func TestConfig_OverrideByEnvironmentOnLoad_Allowlist(t *testing.T) {
+	cfg := `
+metrics:
+  wal_directory: /tmp/wal
+  global:
+    external_labels:
+      exact: ${ALLOWED}
+      prefix: ${AGENT_CLUSTER}
+      defaulted: ${AGENT_UNSET:-fallback}
+      escaped: $${ALLOWED}
+      other: ${host}
+      other_defaulted: ${host:-fallback}
+      other_nested: ${host:-${ALLOWED}}
+      other_bare: $host`
+	expect := labels.FromStrings(
+		"defaulted", "fallback",
+		"escaped", "${ALLOWED}",
+		"exact", "allowed",
+		"other", "${host}",
+		"other_bare", "$host",
+		"other_defaulted", "${host:-fallback}",
+		"other_nested", "${host:-${ALLOWED}}",
+		"prefix", "cluster",
+	)
+	t.Setenv("ALLOWED", "allowed")
+	t.Setenv("AGENT_CLUSTER", "cluster")
+	t.Setenv("host", "not-allowed")
+
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	args := []string{"-config.file", "test", "-config.expand-env.allowlist", "ALLOWED,AGENT_*"}
+	c, err := load(fs, args, func(_, _ string, _ bool, c *Config) error {
+		return LoadBytes([]byte(cfg), true, c)
+	})
+	require.NoError(t, err)
+	require.Equal(t, expect, c.Metrics.Global.Prometheus.ExternalLabels)
+}
+
 func TestConfig_FlagsAreAccepted(t *testing.T) {
 	cfg := `
 metrics:
@@ -525,6 +589,6 @@ func TestConfig_EmptyServerConfigFails(t *testing.T) {
 	defaultServerCfg := server.DefaultConfig()
 	logger := server.NewLogger(&defaultServerCfg)
 	fs := flag.NewFlagSet("", flag.ExitOnError)
-	_, err := Load(fs, []string{"--config.file", "./testdata/server_empty.yml"}, logger)
+	_, err := Load(fs, []string{"--config.file", "./testdata/server_empty.yml"}, logger, NewRemoteConfigPoller())
 	require.Error(t, err)
 }
@@ -15,6 +15,7 @@ type configMetrics struct {
 	configLoadSuccess        prometheus.Gauge
 	configLoadSuccessSeconds prometheus.Gauge
 	configLoadFailures       prometheus.Counter
+	configRollbacks          prometheus.Counter
 }
 
 var confMetrics *configMetrics
@@ -46,6 +47,10 @@ func newConfigMetrics() *configMetrics {
 		Name: "agent_config_load_failures_total",
 		Help: "Configuration load failures.",
 	})
+	m.configRollbacks = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "agent_config_rollbacks_total",
+		Help: "Number of times a failed configuration load was rolled back to the previous configuration.",
+	})
 	return &m
 }
 
@@ -69,3 +74,10 @@ func InstrumentLoad(success bool) {
 		confMetrics.configLoadFailures.Inc()
 	}
 }
+
+// InstrumentRollback records that a failed config load was rolled back to the
+// previous config.
+func InstrumentRollback() {
+	configMetricsInitializer.Do(initializeConfigMetrics)
+	confMetrics.configRollbacks.Inc()
+}
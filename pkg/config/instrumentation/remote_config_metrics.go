@@ -12,6 +12,7 @@ type remoteConfigMetrics struct {
 	fetchStatusCodes   *prometheus.CounterVec
 	fetchErrors        prometheus.Counter
 	invalidConfigFetch *prometheus.CounterVec
+	circuitBreakerOpen prometheus.Gauge
 }
 
 var remoteConfMetrics *remoteConfigMetrics
@@ -46,6 +47,13 @@ func newRemoteConfigMetrics() *remoteConfigMetrics {
 		[]string{"reason"},
 	)
 
+	remoteConfigMetrics.circuitBreakerOpen = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "agent_remote_config_circuit_breaker_open",
+			Help: "1 if fetching the remote config failed repeatedly and is retried at the slower circuit breaker interval, 0 otherwise",
+		},
+	)
+
 	return &remoteConfigMetrics
 }
 
@@ -63,3 +71,12 @@ func InstrumentInvalidRemoteConfig(reason string) {
 	remoteConfMetricsInitializer.Do(initializeRemoteConfigMetrics)
 	remoteConfMetrics.invalidConfigFetch.WithLabelValues(reason).Inc()
 }
+
+func InstrumentRemoteConfigCircuitBreaker(open bool) {
+	remoteConfMetricsInitializer.Do(initializeRemoteConfigMetrics)
+	if open {
+		remoteConfMetrics.circuitBreakerOpen.Set(1)
+	} else {
+		remoteConfMetrics.circuitBreakerOpen.Set(0)
+	}
+}
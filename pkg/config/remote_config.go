@@ -1,12 +1,19 @@
 package config
 
 import (
+	"bytes"
+	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
+	"strings"
 
+	"github.com/golang/snappy"
 	"github.com/grafana/agent/pkg/config/instrumentation"
+	"github.com/klauspost/compress/zstd"
 	"github.com/prometheus/common/config"
 )
 
@@ -16,10 +23,51 @@ const (
 	httpsScheme = "https"
 )
 
+// supported content encodings for remote configs
+const (
+	encodingZstd     = "zstd"
+	encodingSnappy   = "snappy"
+	encodingGzip     = "gzip"
+	encodingIdentity = "identity"
+)
+
+// maxDecodedConfigSize is the maximum size of a remote config after
+// decompression.
+const maxDecodedConfigSize = 64 << 20
+
+// errNotModified is returned by remote providers when a conditional request
+// reports that the remote config hasn't changed.
+var errNotModified = errors.New("remote config not modified")
+
+// cacheValidators identify a version of a remote config and are used to make
+// conditional requests for it.
+type cacheValidators struct {
+	ETag         string
+	LastModified string
+}
+
 // remoteOpts struct contains agent remote config options
 type remoteOpts struct {
 	url              *url.URL
 	HTTPClientConfig *config.HTTPClientConfig
+	// AcceptEncodings are advertised to the server in the Accept-Encoding
+	// header, in order of preference. If empty, no header is sent.
+	AcceptEncodings []string
+	// Headers are additional headers to send with each request.
+	Headers map[string]string
+	// Validators, if set, are sent as If-None-Match and If-Modified-Since
+	// headers. They are updated with the validators of every successful
+	// response.
+	Validators *cacheValidators
+	// Verify, if set, is called with the decoded config and the response
+	// headers. The config is rejected if Verify returns an error.
+	Verify func(bb []byte, h http.Header) error
+	// Accept, if set, is sent as the Accept header to negotiate the format of
+	// the config.
+	Accept string
+	// ContentType, if set, is updated with the media type of every successful
+	// response, without parameters.
+	ContentType *string
 }
 
 // remoteProvider interface should be implemented by config providers
@@ -58,8 +106,14 @@ func newRemoteProvider(rawURL string, opts *remoteOpts) (remoteProvider, error)
 // Remote Config Providers
 // httpProvider - http/https provider
 type httpProvider struct {
-	myURL      *url.URL
-	httpClient *http.Client
+	myURL           *url.URL
+	httpClient      *http.Client
+	acceptEncodings []string
+	headers         map[string]string
+	validators      *cacheValidators
+	verify          func(bb []byte, h http.Header) error
+	accept          string
+	contentType     *string
 }
 
 // newHTTPProvider constructs an new httpProvider
@@ -77,14 +131,44 @@ func newHTTPProvider(opts *remoteOpts) (*httpProvider, error) {
 		return nil, err
 	}
 	return &httpProvider{
-		myURL:      opts.url,
-		httpClient: httpClient,
+		myURL:           opts.url,
+		httpClient:      httpClient,
+		acceptEncodings: opts.AcceptEncodings,
+		headers:         opts.Headers,
+		validators:      opts.Validators,
+		verify:          opts.Verify,
+		accept:          opts.Accept,
+		contentType:     opts.ContentType,
 	}, nil
 }
 
 // retrieve implements remoteProvider and fetches the config
 func (p httpProvider) retrieve() ([]byte, error) {
-	response, err := p.httpClient.Get(p.myURL.String())
+	req, err := http.NewRequest(http.MethodGet, p.myURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+	if p.accept != "" {
+		req.Header.Set("Accept", p.accept)
+	}
+	if len(p.acceptEncodings) > 0 {
+		// Setting Accept-Encoding disables transparent gzip decompression in
+		// net/http, so responses are decoded by decodeContentEncoding instead.
+		req.Header.Set("Accept-Encoding", strings.Join(p.acceptEncodings, ", "))
+	}
+	if p.validators != nil {
+		if p.validators.ETag != "" {
+			req.Header.Set("If-None-Match", p.validators.ETag)
+		}
+		if p.validators.LastModified != "" {
+			req.Header.Set("If-Modified-Since", p.validators.LastModified)
+		}
+	}
+
+	response, err := p.httpClient.Do(req)
 	if err != nil {
 		instrumentation.InstrumentRemoteConfigFetchError()
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -93,6 +177,12 @@ func (p httpProvider) retrieve() ([]byte, error) {
 
 	instrumentation.InstrumentRemoteConfigFetch(response.StatusCode)
 
+	if response.StatusCode == http.StatusNotModified && p.validators != nil {
+		return nil, errNotModified
+	}
+	if response.StatusCode == http.StatusNotAcceptable {
+		return nil, errSchemaNotAcceptable
+	}
 	if response.StatusCode/100 != 2 {
 		return nil, fmt.Errorf("error fetching config: status code: %d", response.StatusCode)
 	}
@@ -100,5 +190,76 @@ func (p httpProvider) retrieve() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	bb, err = decodeContentEncoding(response.Header.Get("Content-Encoding"), bb)
+	if err != nil {
+		return nil, err
+	}
+	if p.verify != nil {
+		if err := p.verify(bb, response.Header); err != nil {
+			instrumentation.InstrumentInvalidRemoteConfig("invalid_signature")
+			return nil, err
+		}
+	}
+
+	if p.contentType != nil {
+		*p.contentType, _, _ = mime.ParseMediaType(response.Header.Get("Content-Type"))
+	}
+	if p.validators != nil {
+		*p.validators = cacheValidators{
+			ETag:         response.Header.Get("ETag"),
+			LastModified: response.Header.Get("Last-Modified"),
+		}
+	}
 	return bb, nil
 }
+
+// decodeContentEncoding decompresses bb according to the Content-Encoding
+// of a response.
+func decodeContentEncoding(encoding string, bb []byte) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", encodingIdentity:
+		return bb, nil
+
+	case encodingZstd:
+		dec, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecodedConfigSize))
+		if err != nil {
+			return nil, err
+		}
+		defer dec.Close()
+		decoded, err := dec.DecodeAll(bb, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding zstd config: %w", err)
+		}
+		return decoded, nil
+
+	case encodingSnappy:
+		n, err := snappy.DecodedLen(bb)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding snappy config: %w", err)
+		} else if n > maxDecodedConfigSize {
+			return nil, fmt.Errorf("decoded config exceeds maximum size of %d bytes", maxDecodedConfigSize)
+		}
+		decoded, err := snappy.Decode(nil, bb)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding snappy config: %w", err)
+		}
+		return decoded, nil
+
+	case encodingGzip:
+		r, err := gzip.NewReader(bytes.NewReader(bb))
+		if err != nil {
+			return nil, fmt.Errorf("error decoding gzip config: %w", err)
+		}
+		defer r.Close()
+		decoded, err := io.ReadAll(io.LimitReader(r, maxDecodedConfigSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("error decoding gzip config: %w", err)
+		} else if len(decoded) > maxDecodedConfigSize {
+			return nil, fmt.Errorf("decoded config exceeds maximum size of %d bytes", maxDecodedConfigSize)
+		}
+		return decoded, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported content encoding for remote config: %q", encoding)
+	}
+}
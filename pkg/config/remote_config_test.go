@@ -1,12 +1,17 @@
 package config
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
 	"github.com/prometheus/common/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -127,3 +132,89 @@ metrics:
 		})
 	}
 }
+
+func TestRemoteConfigHTTP_ContentEncoding(t *testing.T) {
+	testCfg := []byte(`
+metrics:
+  global:
+    scrape_timeout: 33s
+`)
+
+	encoders := map[string]func([]byte) []byte{
+		encodingZstd: func(bb []byte) []byte {
+			enc, err := zstd.NewWriter(nil)
+			require.NoError(t, err)
+			return enc.EncodeAll(bb, nil)
+		},
+		encodingSnappy: func(bb []byte) []byte {
+			return snappy.Encode(nil, bb)
+		},
+		encodingGzip: func(bb []byte) []byte {
+			var buf bytes.Buffer
+			w := gzip.NewWriter(&buf)
+			_, err := w.Write(bb)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+			return buf.Bytes()
+		},
+	}
+
+	// The server responds with the first encoding it supports from the
+	// Accept-Encoding header, or an uncompressed config otherwise.
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+			enc = strings.TrimSpace(enc)
+			if encode, ok := encoders[enc]; ok {
+				w.Header().Set("Content-Encoding", enc)
+				_, _ = w.Write(encode(testCfg))
+				return
+			}
+		}
+		_, _ = w.Write(testCfg)
+	}))
+	defer svr.Close()
+
+	for _, enc := range []string{encodingZstd, encodingSnappy, encodingGzip, encodingIdentity} {
+		t.Run(enc, func(t *testing.T) {
+			rc, err := newRemoteProvider(svr.URL, &remoteOpts{AcceptEncodings: []string{enc}})
+			require.NoError(t, err)
+			bb, err := rc.retrieve()
+			require.NoError(t, err)
+			require.Equal(t, string(testCfg), string(bb))
+		})
+	}
+}
+
+func TestDecodeContentEncoding_Unsupported(t *testing.T) {
+	_, err := decodeContentEncoding("br", []byte("config"))
+	require.EqualError(t, err, `unsupported content encoding for remote config: "br"`)
+
+	_, err = decodeContentEncoding(encodingZstd, []byte("not zstd"))
+	require.Error(t, err)
+}
+
+func TestRemoteConfigHTTP_ConditionalRequest(t *testing.T) {
+	const etag = `"v1"`
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte("config"))
+	}))
+	defer svr.Close()
+
+	var validators cacheValidators
+	rc, err := newRemoteProvider(svr.URL, &remoteOpts{Validators: &validators})
+	require.NoError(t, err)
+
+	bb, err := rc.retrieve()
+	require.NoError(t, err)
+	require.Equal(t, "config", string(bb))
+	require.Equal(t, etag, validators.ETag)
+
+	_, err = rc.retrieve()
+	require.ErrorIs(t, err, errNotModified)
+}
@@ -48,6 +48,7 @@ package flow
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -58,6 +59,7 @@ import (
 	"github.com/grafana/agent/pkg/flow/internal/stdlib"
 	"github.com/grafana/agent/pkg/flow/logging"
 	"github.com/grafana/agent/pkg/flow/tracing"
+	"github.com/grafana/agent/pkg/river/diag"
 	"github.com/grafana/agent/pkg/river/vm"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/atomic"
@@ -112,8 +114,29 @@ type Options struct {
 	// OnExportsChange is nil, export configuration blocks are not allowed in the
 	// loaded config file.
 	OnExportsChange func(exports map[string]any)
+
+	// RollbackOnError causes the controller to return to the previously loaded
+	// config file when loading a new config file fails during component
+	// evaluation, rather than leaving the graph partially updated. It has no
+	// effect until a config file has been loaded without errors.
+	RollbackOnError bool
+}
+
+// RolledBackError is returned by LoadFile when loading a config file failed
+// and the controller rolled back to the previously loaded config file.
+type RolledBackError struct {
+	// Err is the error which caused the rollback.
+	Err error
 }
 
+// Error implements error.
+func (e *RolledBackError) Error() string {
+	return fmt.Sprintf("%s; rolled back to the previously loaded config", e.Err)
+}
+
+// Unwrap returns the error which caused the rollback.
+func (e *RolledBackError) Unwrap() error { return e.Err }
+
 // Flow is the Flow system.
 type Flow struct {
 	log    *logging.Logger
@@ -128,6 +151,15 @@ type Flow struct {
 
 	loadMut    sync.RWMutex
 	loadedOnce atomic.Bool
+
+	// lastGoodFile and lastGoodArgs hold the last config file which was loaded
+	// without errors, used for rolling back failed loads.
+	lastGoodFile *File
+	lastGoodArgs map[string]any
+
+	// argumentScope is the scope which the last loaded config file was
+	// evaluated with.
+	argumentScope *vm.Scope
 }
 
 // New creates and starts a new Flow controller. Call Close to stop
@@ -227,10 +259,53 @@ func (c *Flow) Run(ctx context.Context) {
 //
 // The controller will only start running components after Load is called once
 // without any configuration errors.
+//
+// If Options.RollbackOnError is set and loading file fails, the controller
+// returns to the previously loaded config file and a *RolledBackError is
+// returned.
 func (c *Flow) LoadFile(file *File, args map[string]any) error {
 	c.loadMut.Lock()
 	defer c.loadMut.Unlock()
 
+	diags, err := c.apply(file, args)
+	if err != nil {
+		return err
+	}
+	if !c.loadedOnce.Load() && diags.HasErrors() {
+		// The first call to Load should not run any components if there were
+		// errors in the configuration file.
+		return diags
+	}
+
+	if diags.HasErrors() && c.opts.RollbackOnError && c.lastGoodFile != nil {
+		level.Warn(c.log).Log("msg", "failed to load config file, rolling back to the previously loaded config", "err", diags)
+
+		// The previous config file was already loaded successfully, so it's
+		// expected to load again. Errors are still logged in case a component
+		// started failing to evaluate since then.
+		rollbackDiags, err := c.apply(c.lastGoodFile, c.lastGoodArgs)
+		if err == nil {
+			err = rollbackDiags.ErrorOrNil()
+		}
+		if err != nil {
+			level.Error(c.log).Log("msg", "errors while rolling back to the previously loaded config", "err", err)
+		}
+		c.signalLoadFinished()
+		return &RolledBackError{Err: diags}
+	}
+
+	c.loadedOnce.Store(true)
+	if !diags.HasErrors() {
+		c.lastGoodFile, c.lastGoodArgs = file, args
+	}
+
+	c.signalLoadFinished()
+	return diags.ErrorOrNil()
+}
+
+// apply evaluates the components of file using args. It returns an error if
+// args are invalid for file.
+func (c *Flow) apply(file *File, args map[string]any) (diag.Diagnostics, error) {
 	// Fill out the values for the scope so that argument.NAME.value can be used
 	// to reference expressions.
 	evaluatedArgs := make(map[string]any, len(file.Arguments))
@@ -240,7 +315,7 @@ func (c *Flow) LoadFile(file *File, args map[string]any) error {
 		val := arg.Default
 
 		if setVal, ok := args[arg.Name]; !ok && !arg.Optional {
-			return fmt.Errorf("required argument %q not set", arg.Name)
+			return nil, fmt.Errorf("required argument %q not set", arg.Name)
 		} else if ok {
 			val = setVal
 		}
@@ -258,20 +333,16 @@ func (c *Flow) LoadFile(file *File, args map[string]any) error {
 		},
 	}
 
-	diags := c.loader.Apply(argumentScope, file.Components, file.ConfigBlocks)
-	if !c.loadedOnce.Load() && diags.HasErrors() {
-		// The first call to Load should not run any components if there were
-		// errors in the configuration file.
-		return diags
-	}
-	c.loadedOnce.Store(true)
+	c.argumentScope = argumentScope
+	return c.loader.Apply(argumentScope, file.Components, file.ConfigBlocks), nil
+}
 
+func (c *Flow) signalLoadFinished() {
 	select {
 	case c.loadFinished <- struct{}{}:
 	default:
 		// A refresh is already scheduled
 	}
-	return diags.ErrorOrNil()
 }
 
 // Ready returns whether the Flow controller has finished its initial load.
@@ -279,6 +350,54 @@ func (c *Flow) Ready() bool {
 	return c.loadedOnce.Load()
 }
 
+// ErrComponentNotFound is returned when no component with a requested ID
+// exists.
+var ErrComponentNotFound = errors.New("component not found")
+
+// RestartComponent stops the component with the given ID, replaces it with a
+// new instance built from its current arguments, and starts it again. This
+// discards any state held by the component, such as connections to external
+// systems, without reloading the config file.
+func (c *Flow) RestartComponent(id string) error {
+	c.loadMut.RLock()
+	defer c.loadMut.RUnlock()
+
+	cn := c.findComponent(id)
+	if cn == nil {
+		return ErrComponentNotFound
+	}
+
+	level.Info(c.log).Log("msg", "restarting component", "node_id", id)
+	return c.sched.Restart(cn.NodeID(), cn.Rebuild)
+}
+
+// ReevaluateComponent evaluates the arguments of the component with the given
+// ID again and updates the component if they changed, without reloading the
+// config file.
+func (c *Flow) ReevaluateComponent(id string) error {
+	c.loadMut.RLock()
+	defer c.loadMut.RUnlock()
+
+	cn := c.findComponent(id)
+	if cn == nil {
+		return ErrComponentNotFound
+	}
+
+	level.Info(c.log).Log("msg", "re-evaluating component", "node_id", id)
+	return c.loader.EvaluateComponent(c.argumentScope, cn)
+}
+
+// findComponent returns the component with the given ID or nil if it
+// doesn't exist. loadMut must be held.
+func (c *Flow) findComponent(id string) *controller.ComponentNode {
+	for _, cn := range c.loader.Components() {
+		if cn.NodeID() == id {
+			return cn
+		}
+	}
+	return nil
+}
+
 // ComponentInfos returns the component infos.
 func (c *Flow) ComponentInfos() []*ComponentInfo {
 	c.loadMut.RLock()
@@ -0,0 +1,61 @@
+package flow
+
+import (
+	"os"
+	"sort"
+
+	"github.com/grafana/agent/pkg/river/encoding"
+)
+
+// SecretInfo describes where a component holds secrets.
+type SecretInfo struct {
+	ComponentID string `json:"componentId"`
+	// Arguments are the paths of the arguments of the component which are
+	// marked as secret.
+	Arguments []string `json:"arguments"`
+	// DataPath is the directory where the component writes data to disk, such
+	// as WALs or positions files. It's only set if the directory exists.
+	DataPath string `json:"dataPath,omitempty"`
+	// StoragePaths are the locations on disk reported by the component, such as
+	// WAL directories or dead-letter spools, which may be outside of DataPath.
+	// Only locations which exist are listed.
+	StoragePaths []string `json:"storagePaths,omitempty"`
+	// Error is set if the arguments of the component couldn't be inspected.
+	Error string `json:"error,omitempty"`
+}
+
+// SecretInfos describes the secret arguments and on-disk data of all
+// components which have either, sorted by component ID. Components inside of
+// modules aren't included.
+func (c *Flow) SecretInfos() []*SecretInfo {
+	c.loadMut.RLock()
+	defer c.loadMut.RUnlock()
+
+	var infos []*SecretInfo
+	for _, cn := range c.loader.Components() {
+		info := &SecretInfo{ComponentID: cn.NodeID(), Arguments: []string{}}
+
+		paths, err := encoding.SecretPaths(cn.Arguments())
+		if err != nil {
+			info.Error = err.Error()
+		}
+		if len(paths) > 0 {
+			info.Arguments = paths
+		}
+		if fi, err := os.Stat(cn.DataPath()); err == nil && fi.IsDir() {
+			info.DataPath = cn.DataPath()
+		}
+		for _, sp := range cn.StoragePaths() {
+			if _, err := os.Stat(sp.Path); err == nil {
+				info.StoragePaths = append(info.StoragePaths, sp.Path)
+			}
+		}
+
+		if len(info.Arguments) > 0 || info.DataPath != "" || len(info.StoragePaths) > 0 || info.Error != "" {
+			infos = append(infos, info)
+		}
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ComponentID < infos[j].ComponentID })
+	return infos
+}
@@ -0,0 +1,31 @@
+package flow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_SecretInfos(t *testing.T) {
+	f, err := ReadFile(t.Name(), []byte(testFile))
+	require.NoError(t, err)
+
+	ctrl := New(testOptions(t))
+	require.NoError(t, ctrl.LoadFile(f, nil))
+	require.Empty(t, ctrl.SecretInfos())
+
+	ctrl.loadMut.RLock()
+	dataPath := ctrl.findComponent("testcomponents.passthrough.static").DataPath()
+	ctrl.loadMut.RUnlock()
+
+	// Locations reported by components are listed once they exist.
+	require.NoError(t, os.MkdirAll(filepath.Join(dataPath, "wal"), 0770))
+	require.Equal(t, []*SecretInfo{{
+		ComponentID:  "testcomponents.passthrough.static",
+		Arguments:    []string{},
+		DataPath:     dataPath,
+		StoragePaths: []string{filepath.Join(dataPath, "wal")},
+	}}, ctrl.SecretInfos())
+}
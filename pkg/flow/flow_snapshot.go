@@ -0,0 +1,244 @@
+package flow
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-kit/log/level"
+)
+
+// maxSnapshotDataSize is the largest total size of the files in a snapshot
+// accepted by Restore.
+const maxSnapshotDataSize = 256 << 20 // 256MiB
+
+// Snapshot writes the data directories of all components, such as positions
+// files and caches, to w as a gzip-compressed tar archive. Each file is
+// stored as <component ID>/<path inside the data directory>. Buffers reported
+// by components through component.StorageComponent, like WALs and disk
+// queues, and components inside of modules aren't included.
+//
+// Files are read while components are running, so a snapshot is only
+// consistent for components which replace their files atomically.
+func (c *Flow) Snapshot(w io.Writer) error {
+	c.loadMut.RLock()
+	defer c.loadMut.RUnlock()
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	for _, cn := range c.loader.Components() {
+		skip := make(map[string]struct{})
+		for _, sp := range cn.StoragePaths() {
+			if sp.Buffer {
+				skip[filepath.Clean(sp.Path)] = struct{}{}
+			}
+		}
+		if err := snapshotDir(tw, cn.NodeID(), cn.DataPath(), skip); err != nil {
+			return fmt.Errorf("snapshotting %s: %w", cn.NodeID(), err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// snapshotDir writes the regular files inside of dir to tw, prefixed by id.
+// Files and directories in skip are left out.
+func snapshotDir(tw *tar.Writer, id, dir string, skip map[string]struct{}) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == dir && errors.Is(err, fs.ErrNotExist) {
+				// The component never wrote any data.
+				return nil
+			}
+			return err
+		}
+		if _, skipped := skip[filepath.Clean(p)]; skipped {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		return writeSnapshotFile(tw, id+"/"+filepath.ToSlash(rel), p)
+	})
+}
+
+// writeSnapshotFile streams the file at p into tw under name. Files which grow
+// while being written are cut off at the size they had when they were
+// opened.
+func writeSnapshotFile(tw *tar.Writer, name, p string) error {
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		// The file was removed after it was listed.
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	err = tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     int64(info.Mode().Perm()),
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+	})
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(tw, io.LimitReader(f, info.Size()))
+	if err != nil {
+		return err
+	} else if n < info.Size() {
+		return fmt.Errorf("%s shrank while it was being snapshotted", p)
+	}
+	return nil
+}
+
+// RestoreResult describes the components affected by Restore.
+type RestoreResult struct {
+	// Restored holds the IDs of components whose data was restored.
+	Restored []string `json:"restored"`
+	// Skipped holds the IDs of components in the snapshot which aren't defined
+	// in the loaded config.
+	Skipped []string `json:"skipped"`
+}
+
+// snapshotFile is a file read from a snapshot.
+type snapshotFile struct {
+	path string // Slash-separated path inside of the data directory.
+	mode fs.FileMode
+	data []byte
+}
+
+// Restore writes the files of a snapshot created by Snapshot into the data
+// directories of the components with matching IDs. Each restored component is
+// stopped, has its files written, and is then recreated and started again, so
+// that it loads the restored data. Files which aren't in the snapshot are
+// kept.
+func (c *Flow) Restore(r io.Reader) (*RestoreResult, error) {
+	files, err := readSnapshot(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot: %w", err)
+	}
+
+	ids := make([]string, 0, len(files))
+	for id := range files {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	c.loadMut.RLock()
+	defer c.loadMut.RUnlock()
+
+	res := &RestoreResult{Restored: []string{}, Skipped: []string{}}
+	for _, id := range ids {
+		cn := c.findComponent(id)
+		if cn == nil {
+			res.Skipped = append(res.Skipped, id)
+			continue
+		}
+
+		level.Info(c.log).Log("msg", "restoring component data", "node_id", id, "files", len(files[id]))
+		err := c.sched.Restart(cn.NodeID(), func() error {
+			writeErr := writeSnapshotFiles(cn.DataPath(), files[id])
+			// Always recreate the component so that it keeps running, even if
+			// some files couldn't be written.
+			if err := cn.Rebuild(); writeErr == nil {
+				return err
+			}
+			return writeErr
+		})
+		if err != nil {
+			return res, fmt.Errorf("restoring %s: %w", id, err)
+		}
+		res.Restored = append(res.Restored, id)
+	}
+	return res, nil
+}
+
+// readSnapshot reads the files of a snapshot, grouped by component ID.
+func readSnapshot(r io.Reader) (map[string][]snapshotFile, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+
+	var (
+		files = make(map[string][]snapshotFile)
+		total int64
+	)
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		id, rel, ok := strings.Cut(path.Clean(hdr.Name), "/")
+		if !ok || id == "" || id == ".." || rel == "" || rel == ".." || strings.HasPrefix(rel, "../") || path.IsAbs(hdr.Name) {
+			return nil, fmt.Errorf("invalid file name %q", hdr.Name)
+		}
+
+		if hdr.Size > maxSnapshotDataSize-total {
+			return nil, fmt.Errorf("snapshot holds more than %d bytes of data", maxSnapshotDataSize)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, hdr.Size))
+		if err != nil {
+			return nil, err
+		}
+		total += int64(len(data))
+		mode := hdr.FileInfo().Mode().Perm()
+		if mode == 0 {
+			mode = 0600
+		}
+		files[id] = append(files[id], snapshotFile{path: rel, mode: mode, data: data})
+	}
+
+	return files, nil
+}
+
+// writeSnapshotFiles writes files into dir, creating directories as needed.
+func writeSnapshotFiles(dir string, files []snapshotFile) error {
+	for _, f := range files {
+		p := filepath.Join(dir, filepath.FromSlash(f.path))
+		if err := os.MkdirAll(filepath.Dir(p), 0770); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, f.data, f.mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package flow
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_SnapshotRestore(t *testing.T) {
+	f, err := ReadFile(t.Name(), []byte(testFile))
+	require.NoError(t, err)
+
+	ctrl := New(testOptions(t))
+	require.NoError(t, ctrl.LoadFile(f, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ctrl.Run(ctx)
+
+	// Components can only be restored once they're scheduled.
+	require.Eventually(t, func() bool {
+		return ctrl.RestartComponent("testcomponents.passthrough.static") == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	ctrl.loadMut.RLock()
+	dataPath := ctrl.findComponent("testcomponents.passthrough.static").DataPath()
+	ctrl.loadMut.RUnlock()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dataPath, "wal"), 0770))
+	require.NoError(t, os.WriteFile(filepath.Join(dataPath, "positions.yml"), []byte("original"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dataPath, "wal", "000001"), []byte("segment"), 0600))
+
+	var buf bytes.Buffer
+	require.NoError(t, ctrl.Snapshot(&buf))
+	require.Equal(t, []string{"testcomponents.passthrough.static/positions.yml"}, snapshotNames(t, buf.Bytes()))
+
+	// Restoring the snapshot overwrites changed files.
+	require.NoError(t, os.WriteFile(filepath.Join(dataPath, "positions.yml"), []byte("changed"), 0600))
+	res, err := ctrl.Restore(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, &RestoreResult{
+		Restored: []string{"testcomponents.passthrough.static"},
+		Skipped:  []string{},
+	}, res)
+
+	content, err := os.ReadFile(filepath.Join(dataPath, "positions.yml"))
+	require.NoError(t, err)
+	require.Equal(t, "original", string(content))
+
+	// The restarted component is running again.
+	require.NoError(t, ctrl.RestartComponent("testcomponents.passthrough.static"))
+}
+
+func TestController_RestoreSkipsUnknownComponents(t *testing.T) {
+	ctrl := New(testOptions(t))
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "local.file.missing/data", Size: 4, Mode: 0600}))
+	_, err := tw.Write([]byte("data"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	res, err := ctrl.Restore(&buf)
+	require.NoError(t, err)
+	require.Equal(t, &RestoreResult{Restored: []string{}, Skipped: []string{"local.file.missing"}}, res)
+}
+
+func TestController_RestoreInvalidNames(t *testing.T) {
+	for _, name := range []string{"/etc/passwd", "../escape", "local.file.a/../../escape", "local.file.a"} {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gw)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0600}))
+		require.NoError(t, tw.Close())
+		require.NoError(t, gw.Close())
+
+		_, err := New(testOptions(t)).Restore(&buf)
+		require.ErrorContains(t, err, "invalid file name", name)
+	}
+}
+
+func TestController_RestoreTooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	// The size is checked against the header before any data is read, so the
+	// data itself doesn't need to be written.
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "local.file.a/data", Size: maxSnapshotDataSize + 1, Mode: 0600}))
+	require.NoError(t, gw.Close())
+
+	_, err := New(testOptions(t)).Restore(&buf)
+	require.ErrorContains(t, err, "snapshot holds more than")
+}
+
+func snapshotNames(t *testing.T, snapshot []byte) []string {
+	t.Helper()
+
+	gr, err := gzip.NewReader(bytes.NewReader(snapshot))
+	require.NoError(t, err)
+
+	var names []string
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+	}
+	return names
+}
@@ -0,0 +1,59 @@
+package flow
+
+import (
+	"sort"
+	"time"
+)
+
+// ScrapeTargetInfo describes a target scraped by a component.
+type ScrapeTargetInfo struct {
+	ComponentID        string            `json:"componentId"`
+	Job                string            `json:"job"`
+	URL                string            `json:"url"`
+	Health             string            `json:"health"`
+	Labels             map[string]string `json:"labels"`
+	LastError          string            `json:"lastError,omitempty"`
+	LastScrape         time.Time         `json:"lastScrape"`
+	LastScrapeDuration float64           `json:"lastScrapeDurationSeconds"`
+}
+
+// ScrapeTargets returns the targets scraped by all components which report
+// their scrape targets, sorted by component ID, job, and URL. Components
+// inside of modules aren't included.
+func (c *Flow) ScrapeTargets() []*ScrapeTargetInfo {
+	c.loadMut.RLock()
+	defer c.loadMut.RUnlock()
+
+	var infos []*ScrapeTargetInfo
+	for _, cn := range c.loader.Components() {
+		targets, ok := cn.ScrapeTargets()
+		if !ok {
+			continue
+		}
+		for _, t := range targets {
+			infos = append(infos, &ScrapeTargetInfo{
+				ComponentID:        cn.NodeID(),
+				Job:                t.JobName,
+				URL:                t.URL,
+				Health:             t.Health,
+				Labels:             t.Labels,
+				LastError:          t.LastError,
+				LastScrape:         t.LastScrape,
+				LastScrapeDuration: t.LastScrapeDuration.Seconds(),
+			})
+		}
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		a, b := infos[i], infos[j]
+		switch {
+		case a.ComponentID != b.ComponentID:
+			return a.ComponentID < b.ComponentID
+		case a.Job != b.Job:
+			return a.Job < b.Job
+		default:
+			return a.URL < b.URL
+		}
+	})
+	return infos
+}
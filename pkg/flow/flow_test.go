@@ -1,8 +1,10 @@
 package flow
 
 import (
+	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/grafana/agent/component"
 	"github.com/grafana/agent/pkg/flow/internal/controller"
@@ -49,6 +51,71 @@ func TestController_LoadFile_Evaluation(t *testing.T) {
 	require.Equal(t, "hello, world!", out.(testcomponents.PassthroughExports).Output)
 }
 
+func TestController_LoadFile_RollbackOnError(t *testing.T) {
+	opts := testOptions(t)
+	opts.RollbackOnError = true
+	ctrl := New(opts)
+
+	f, err := ReadFile(t.Name(), []byte(testFile))
+	require.NoError(t, err)
+	require.NoError(t, ctrl.LoadFile(f, nil))
+
+	// The new file parses, but the ticker fails to evaluate.
+	invalidFile := `
+		testcomponents.tick "ticker" {
+			frequency = 5
+		}
+
+		testcomponents.passthrough "static" {
+			input = "goodbye, world!"
+		}
+
+		testcomponents.passthrough "added" {
+			input = "hello, world!"
+		}
+	`
+	f, err = ReadFile(t.Name(), []byte(invalidFile))
+	require.NoError(t, err)
+
+	err = ctrl.LoadFile(f, nil)
+	var rolledBack *RolledBackError
+	require.ErrorAs(t, err, &rolledBack)
+
+	// The previous graph should be in place again.
+	require.Len(t, ctrl.loader.Components(), 4)
+	in, out := getFields(t, ctrl.loader.Graph(), "testcomponents.passthrough.static")
+	require.Equal(t, "hello, world!", in.(testcomponents.PassthroughConfig).Input)
+	require.Equal(t, "hello, world!", out.(testcomponents.PassthroughExports).Output)
+	require.Nil(t, ctrl.loader.Graph().GetByID("testcomponents.passthrough.added"))
+}
+
+func TestController_RestartComponent(t *testing.T) {
+	f, err := ReadFile(t.Name(), []byte(testFile))
+	require.NoError(t, err)
+	require.NotNil(t, f)
+
+	ctrl := New(testOptions(t))
+	require.NoError(t, ctrl.LoadFile(f, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ctrl.Run(ctx)
+
+	// Components can only be restarted once they're scheduled.
+	require.Eventually(t, func() bool {
+		return ctrl.RestartComponent("testcomponents.passthrough.static") == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	in, out := getFields(t, ctrl.loader.Graph(), "testcomponents.passthrough.static")
+	require.Equal(t, "hello, world!", in.(testcomponents.PassthroughConfig).Input)
+	require.Equal(t, "hello, world!", out.(testcomponents.PassthroughExports).Output)
+
+	require.NoError(t, ctrl.ReevaluateComponent("testcomponents.passthrough.static"))
+
+	require.ErrorIs(t, ctrl.RestartComponent("testcomponents.passthrough.missing"), ErrComponentNotFound)
+	require.ErrorIs(t, ctrl.ReevaluateComponent("testcomponents.passthrough.missing"), ErrComponentNotFound)
+}
+
 func getFields(t *testing.T, g *dag.Graph, nodeID string) (component.Arguments, component.Exports) {
 	t.Helper()
 
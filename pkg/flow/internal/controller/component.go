@@ -327,6 +327,32 @@ func (cn *ComponentNode) Run(ctx context.Context) error {
 // component is built.
 var ErrUnevaluated = errors.New("managed component not built")
 
+// Rebuild replaces the managed component with a new instance built from the
+// current arguments, discarding any state held by the previous instance.
+// Rebuild must only be called while the component isn't running.
+//
+// If building the new instance fails, the previous instance is kept.
+func (cn *ComponentNode) Rebuild() error {
+	cn.mut.Lock()
+	defer cn.mut.Unlock()
+
+	if cn.managed == nil {
+		return ErrUnevaluated
+	}
+
+	// The new instance registers its metrics again, so the metrics of the
+	// previous instance must be removed first.
+	oldCollectors := cn.register.reset()
+
+	managed, err := cn.reg.Build(cn.managedOpts, cn.args)
+	if err != nil {
+		cn.register.restore(oldCollectors)
+		return fmt.Errorf("building component: %w", err)
+	}
+	cn.managed = managed
+	return nil
+}
+
 // Arguments returns the current arguments of the managed component.
 func (cn *ComponentNode) Arguments() component.Arguments {
 	cn.mut.RLock()
@@ -334,6 +360,9 @@ func (cn *ComponentNode) Arguments() component.Arguments {
 	return cn.args
 }
 
+// DataPath returns the directory where the managed component may store data.
+func (cn *ComponentNode) DataPath() string { return cn.managedOpts.DataPath }
+
 // Block implements BlockNode and returns the current block of the managed component.
 func (cn *ComponentNode) Block() *ast.BlockStmt {
 	cn.mut.RLock()
@@ -442,6 +471,31 @@ func (cn *ComponentNode) DebugInfo() interface{} {
 	return nil
 }
 
+// ScrapeTargets returns the state of the targets scraped by the managed
+// component. It returns false if the managed component doesn't scrape
+// targets.
+func (cn *ComponentNode) ScrapeTargets() ([]component.ScrapeTarget, bool) {
+	cn.mut.RLock()
+	defer cn.mut.RUnlock()
+
+	if sc, ok := cn.managed.(component.ScrapeComponent); ok {
+		return sc.ScrapeTargets(), true
+	}
+	return nil, false
+}
+
+// StoragePaths returns where the managed component stores data on disk. It
+// returns nil if the managed component doesn't report it.
+func (cn *ComponentNode) StoragePaths() []component.StoragePath {
+	cn.mut.RLock()
+	defer cn.mut.RUnlock()
+
+	if sc, ok := cn.managed.(component.StorageComponent); ok {
+		return sc.StoragePaths()
+	}
+	return nil
+}
+
 // setEvalHealth sets the internal health from a call to Evaluate. See Health
 // for information on how overall health is calculated.
 func (cn *ComponentNode) setEvalHealth(t component.HealthType, msg string) {
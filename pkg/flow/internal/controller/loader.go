@@ -405,6 +405,16 @@ func (l *Loader) EvaluateDependencies(parentScope *vm.Scope, c *ComponentNode) {
 	})
 }
 
+// EvaluateComponent re-evaluates the arguments of c using the current exports
+// of the components it depends on. Components which depend on c are
+// re-evaluated once c updates its exports.
+func (l *Loader) EvaluateComponent(parentScope *vm.Scope, c *ComponentNode) error {
+	l.mut.RLock()
+	defer l.mut.RUnlock()
+
+	return l.evaluate(l.log, parentScope, c)
+}
+
 // evaluate constructs the final context for the special config Node and
 // evaluates it. mut must be held when calling evaluate.
 func (l *Loader) evaluate(logger log.Logger, parent *vm.Scope, bn BlockNode) error {
@@ -76,31 +76,63 @@ func (s *Scheduler) Synchronize(rr []RunnableNode) error {
 		if _, exist := s.tasks[id]; exist {
 			continue
 		}
+		s.startTask(id, r)
+	}
 
-		var (
-			nodeID      = id
-			newRunnable = r
-		)
+	// Wait for all stopping runnables to exit.
+	stopping.Wait()
+	return nil
+}
 
-		opts := taskOptions{
-			Context:  s.ctx,
-			Runnable: newRunnable,
-			OnDone: func() {
-				defer s.running.Done()
+// Restart stops the running task for the RunnableNode with the given ID and
+// starts it again. If beforeStart is non-nil, it's called after the task
+// exited and before it's started again; the task is started again even if
+// beforeStart returns an error, which is then returned by Restart.
+func (s *Scheduler) Restart(nodeID string, beforeStart func() error) error {
+	s.tasksMut.Lock()
+	defer s.tasksMut.Unlock()
 
-				s.tasksMut.Lock()
-				defer s.tasksMut.Unlock()
-				delete(s.tasks, nodeID)
-			},
-		}
+	if s.ctx.Err() != nil {
+		return fmt.Errorf("Scheduler is closed")
+	}
 
-		s.running.Add(1)
-		s.tasks[nodeID] = newTask(opts)
+	t, ok := s.tasks[nodeID]
+	if !ok {
+		return fmt.Errorf("%s is not running", nodeID)
 	}
+	t.Stop()
 
-	// Wait for all stopping runnables to exit.
-	stopping.Wait()
-	return nil
+	var err error
+	if beforeStart != nil {
+		err = beforeStart()
+	}
+	s.startTask(nodeID, t.runnable)
+	return err
+}
+
+// startTask launches a new task for r. tasksMut must be held.
+func (s *Scheduler) startTask(nodeID string, r RunnableNode) {
+	var t *task
+
+	opts := taskOptions{
+		Context:  s.ctx,
+		Runnable: r,
+		OnDone: func() {
+			defer s.running.Done()
+
+			s.tasksMut.Lock()
+			defer s.tasksMut.Unlock()
+
+			// The task may have been replaced by Restart in the meantime.
+			if s.tasks[nodeID] == t {
+				delete(s.tasks, nodeID)
+			}
+		},
+	}
+
+	s.running.Add(1)
+	t = newTask(opts)
+	s.tasks[nodeID] = t
 }
 
 // Close stops the Scheduler and returns after all running goroutines have
@@ -113,9 +145,10 @@ func (s *Scheduler) Close() error {
 
 // task is a scheduled runnable.
 type task struct {
-	ctx    context.Context
-	cancel context.CancelFunc
-	exited chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+	exited   chan struct{}
+	runnable RunnableNode
 }
 
 type taskOptions struct {
@@ -129,9 +162,10 @@ func newTask(opts taskOptions) *task {
 	ctx, cancel := context.WithCancel(opts.Context)
 
 	t := &task{
-		ctx:    ctx,
-		cancel: cancel,
-		exited: make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+		exited:   make(chan struct{}),
+		runnable: opts.Runnable,
 	}
 
 	go func() {
@@ -86,6 +86,43 @@ func TestScheduler_Synchronize(t *testing.T) {
 	})
 }
 
+func TestScheduler_Restart(t *testing.T) {
+	var (
+		starts = make(chan struct{}, 2)
+		stops  = make(chan struct{}, 2)
+	)
+	runFunc := func(ctx context.Context) error {
+		starts <- struct{}{}
+		<-ctx.Done()
+		stops <- struct{}{}
+		return nil
+	}
+
+	sched := controller.NewScheduler()
+	sched.Synchronize([]controller.RunnableNode{
+		fakeRunnable{ID: "component-a", Component: mockComponent{RunFunc: runFunc}},
+	})
+	<-starts
+
+	var calledBeforeStart bool
+	err := sched.Restart("component-a", func() error {
+		// The old task must have exited before beforeStart is called.
+		require.Len(t, stops, 1)
+		calledBeforeStart = true
+		return nil
+	})
+	require.NoError(t, err)
+	require.True(t, calledBeforeStart)
+	<-starts
+
+	require.EqualError(t, sched.Restart("component-b", nil), "component-b is not running")
+
+	// The restarted task must still be managed by the scheduler and be stopped
+	// on Close.
+	require.NoError(t, sched.Close())
+	require.Len(t, stops, 2)
+}
+
 type fakeRunnable struct {
 	ID        string
 	Component component.Component
@@ -63,3 +63,22 @@ func (w *wrappedRegisterer) Unregister(collector prometheus.Collector) bool {
 	delete(w.internalCollectors, collector)
 	return true
 }
+
+// reset unregisters all collectors. The removed collectors are returned so
+// they can be registered again with restore.
+func (w *wrappedRegisterer) reset() map[prometheus.Collector]struct{} {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+
+	old := w.internalCollectors
+	w.internalCollectors = make(map[prometheus.Collector]struct{})
+	return old
+}
+
+// restore replaces all registered collectors with collectors.
+func (w *wrappedRegisterer) restore(collectors map[prometheus.Collector]struct{}) {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+
+	w.internalCollectors = collectors
+}
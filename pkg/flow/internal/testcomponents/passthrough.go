@@ -2,6 +2,7 @@ package testcomponents
 
 import (
 	"context"
+	"path/filepath"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
@@ -48,8 +49,9 @@ func NewPassthrough(o component.Options, cfg PassthroughConfig) (*Passthrough, e
 }
 
 var (
-	_ component.Component      = (*Passthrough)(nil)
-	_ component.DebugComponent = (*Passthrough)(nil)
+	_ component.Component        = (*Passthrough)(nil)
+	_ component.DebugComponent   = (*Passthrough)(nil)
+	_ component.StorageComponent = (*Passthrough)(nil)
 )
 
 // Run implements Component.
@@ -78,6 +80,12 @@ func (t *Passthrough) DebugInfo() interface{} {
 	}
 }
 
+// StoragePaths implements StorageComponent. The wal directory is never written
+// to; it's reported so tests can check how buffers are handled.
+func (t *Passthrough) StoragePaths() []component.StoragePath {
+	return []component.StoragePath{{Path: filepath.Join(t.opts.DataPath, "wal"), Buffer: true}}
+}
+
 type passthroughDebugInfo struct {
 	ComponentVersion string `river:"component_version,attr"`
 }
@@ -0,0 +1,124 @@
+package logging
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// levelFilter filters log lines by their level. Log lines of components can
+// use a different level than the rest of the log lines.
+type levelFilter struct {
+	mut             sync.RWMutex
+	level           Level
+	componentLevels map[string]Level
+}
+
+func newLevelFilter(l Level) *levelFilter {
+	return &levelFilter{
+		level:           l,
+		componentLevels: make(map[string]Level),
+	}
+}
+
+func (f *levelFilter) SetLevel(l Level) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	f.level = l
+}
+
+func (f *levelFilter) SetComponentLevel(id string, l Level) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	f.componentLevels[id] = l
+}
+
+func (f *levelFilter) ClearComponentLevel(id string) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	delete(f.componentLevels, id)
+}
+
+func (f *levelFilter) ComponentLevels() map[string]Level {
+	f.mut.RLock()
+	defer f.mut.RUnlock()
+
+	res := make(map[string]Level, len(f.componentLevels))
+	for id, l := range f.componentLevels {
+		res[id] = l
+	}
+	return res
+}
+
+// levelFor returns the level to use for log lines of the component with the
+// given ID. Overrides for a module component also apply to the components
+// inside of the module; the override for the closest component is used.
+func (f *levelFilter) levelFor(componentID string) Level {
+	f.mut.RLock()
+	defer f.mut.RUnlock()
+
+	for id := componentID; id != ""; {
+		if l, ok := f.componentLevels[id]; ok {
+			return l
+		}
+
+		idx := strings.LastIndex(id, "/")
+		if idx == -1 {
+			break
+		}
+		id = id[:idx]
+	}
+	return f.level
+}
+
+// allow returns true if a log line with the given key/value pairs passes the
+// filter. Log lines without a level are always allowed.
+func (f *levelFilter) allow(kvps []interface{}) bool {
+	var (
+		lineLevel   level.Value
+		componentID string
+	)
+	for i := 0; i+1 < len(kvps); i += 2 {
+		switch kvps[i] {
+		case level.Key():
+			lineLevel, _ = kvps[i+1].(level.Value)
+		case "component":
+			componentID, _ = kvps[i+1].(string)
+		}
+	}
+	if lineLevel == nil {
+		return true
+	}
+	return levelRank(Level(lineLevel.String())) >= levelRank(f.levelFor(componentID))
+}
+
+// levelRank orders levels from the most to the least verbose.
+func levelRank(l Level) int {
+	switch l {
+	case LevelDebug:
+		return 0
+	case LevelInfo:
+		return 1
+	case LevelWarn:
+		return 2
+	case LevelError:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// filteredLogger forwards log lines which pass filter to next.
+type filteredLogger struct {
+	filter *levelFilter
+	next   log.Logger
+}
+
+func (l *filteredLogger) Log(kvps ...interface{}) error {
+	if !l.filter.allow(kvps) {
+		return nil
+	}
+	return l.next.Log(kvps...)
+}
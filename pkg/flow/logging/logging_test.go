@@ -1,10 +1,14 @@
 package logging_test
 
 import (
+	"bytes"
 	"os"
+	"strings"
+	"testing"
 
 	"github.com/go-kit/log/level"
 	"github.com/grafana/agent/pkg/flow/logging"
+	"github.com/stretchr/testify/require"
 )
 
 func Example() {
@@ -42,3 +46,62 @@ func Example() {
 	// component=outer/inner level=info msg="hello from the inner component!"
 	// component=outer/inner level=info msg="hello from the inner controller!"
 }
+
+func TestSink_ComponentLevels(t *testing.T) {
+	var buf bytes.Buffer
+	sink, err := logging.WriterSink(&buf, logging.SinkOptions{
+		Level:  logging.LevelInfo,
+		Format: logging.FormatLogfmt,
+	})
+	require.NoError(t, err)
+
+	var (
+		controller = logging.New(sink)
+		module     = logging.New(logging.LoggerSink(controller), logging.WithComponentID("module.string.example"))
+		inner      = logging.New(logging.LoggerSink(module), logging.WithComponentID("inner"))
+		other      = logging.New(logging.LoggerSink(controller), logging.WithComponentID("other"))
+	)
+
+	logAll := func() string {
+		buf.Reset()
+		for _, l := range []*logging.Logger{controller, module, inner, other} {
+			level.Debug(l).Log("msg", "debug")
+			level.Info(l).Log("msg", "info")
+		}
+		return buf.String()
+	}
+
+	require.Equal(t, strings.Join([]string{
+		`level=info msg=info`,
+		`component=module.string.example level=info msg=info`,
+		`component=module.string.example/inner level=info msg=info`,
+		`component=other level=info msg=info`,
+	}, "\n")+"\n", logAll())
+
+	// The override of a module applies to the components inside of it.
+	require.NoError(t, sink.SetComponentLevel("module.string.example", logging.LevelDebug))
+	require.Equal(t, strings.Join([]string{
+		`level=info msg=info`,
+		`component=module.string.example level=debug msg=debug`,
+		`component=module.string.example level=info msg=info`,
+		`component=module.string.example/inner level=debug msg=debug`,
+		`component=module.string.example/inner level=info msg=info`,
+		`component=other level=info msg=info`,
+	}, "\n")+"\n", logAll())
+
+	// Overrides are kept when the sink is updated.
+	require.NoError(t, sink.SetComponentLevel("module.string.example/inner", logging.LevelError))
+	require.NoError(t, sink.Update(logging.SinkOptions{Level: logging.LevelError, Format: logging.FormatLogfmt}))
+	require.Equal(t, strings.Join([]string{
+		`component=module.string.example level=debug msg=debug`,
+		`component=module.string.example level=info msg=info`,
+	}, "\n")+"\n", logAll())
+	require.Equal(t, map[string]logging.Level{
+		"module.string.example":       logging.LevelDebug,
+		"module.string.example/inner": logging.LevelError,
+	}, sink.ComponentLevels())
+
+	require.NoError(t, sink.ClearComponentLevel("module.string.example"))
+	require.NoError(t, sink.ClearComponentLevel("module.string.example/inner"))
+	require.Empty(t, logAll())
+}
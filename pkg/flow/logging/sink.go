@@ -3,9 +3,9 @@ package logging
 import (
 	"fmt"
 	"io"
+	"sync"
 
 	"github.com/go-kit/log"
-	"github.com/go-kit/log/level"
 )
 
 // Sink is where a Controller logger will send log lines to.
@@ -18,6 +18,11 @@ type Sink struct {
 	parentComponentID string
 
 	logger *lazyLogger // Constructed logger to use.
+
+	// mut guards opts. opts and filter are only set for updatable sinks.
+	mut    sync.RWMutex
+	opts   SinkOptions
+	filter *levelFilter
 }
 
 // WriterSink forwards logs to the provided [io.Writer]. WriterSinks support
@@ -31,12 +36,16 @@ func WriterSink(w io.Writer, o SinkOptions) (*Sink, error) {
 	if err != nil {
 		return nil, err
 	}
+	filter := newLevelFilter(o.Level)
 
 	return &Sink{
 		w:         w,
 		updatable: true,
 
-		logger: &lazyLogger{inner: l},
+		logger: &lazyLogger{inner: &filteredLogger{filter: filter, next: l}},
+
+		opts:   o,
+		filter: filter,
 	}, nil
 }
 
@@ -65,10 +74,56 @@ func (s *Sink) Update(o SinkOptions) error {
 		return err
 	}
 
-	s.logger.UpdateInner(l)
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.filter.SetLevel(o.Level)
+	s.logger.UpdateInner(&filteredLogger{filter: s.filter, next: l})
+	s.opts = o
+	return nil
+}
+
+// Options returns the options currently used for the Sink. Options returns
+// the zero value if the Sink doesn't support being updated.
+func (s *Sink) Options() SinkOptions {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	return s.opts
+}
+
+// SetComponentLevel overrides the log level for the component with the given
+// ID. The override also applies to components inside of the component if
+// it's a module. SetComponentLevel will return an error if the Sink doesn't
+// support being updated.
+func (s *Sink) SetComponentLevel(id string, l Level) error {
+	if !s.updatable {
+		return fmt.Errorf("logging options cannot be updated in this context")
+	}
+	s.filter.SetComponentLevel(id, l)
+	return nil
+}
+
+// ClearComponentLevel removes the log level override for the component with
+// the given ID.
+func (s *Sink) ClearComponentLevel(id string) error {
+	if !s.updatable {
+		return fmt.Errorf("logging options cannot be updated in this context")
+	}
+	s.filter.ClearComponentLevel(id)
 	return nil
 }
 
+// ComponentLevels returns the log level overrides of components by their ID.
+func (s *Sink) ComponentLevels() map[string]Level {
+	if !s.updatable {
+		return nil
+	}
+	return s.filter.ComponentLevels()
+}
+
+// writerSinkLogger returns a logger which writes to w in the format of o. Log
+// lines are filtered by level separately so that components can use their own
+// level.
 func writerSinkLogger(w io.Writer, o SinkOptions) (log.Logger, error) {
 	var l log.Logger
 
@@ -81,8 +136,6 @@ func writerSinkLogger(w io.Writer, o SinkOptions) (log.Logger, error) {
 		return nil, fmt.Errorf("unrecognized log format %q", o.Format)
 	}
 
-	l = level.NewFilter(l, o.Level.Filter())
-
 	if o.IncludeTimestamps {
 		l = log.With(l, "ts", log.DefaultTimestampUTC)
 	}
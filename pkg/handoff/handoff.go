@@ -0,0 +1,62 @@
+// Package handoff hands the listeners and on-disk state of a running agent
+// over to a new agent process, so that the agent can be upgraded without
+// refusing connections.
+//
+// The running agent serves handoffs on a Unix socket in its data directory.
+// A new agent connects to the socket and receives the listening sockets of
+// the running agent, which keep accepting connections into their backlog from
+// then on. The new agent then asks the running agent to release its state:
+// the running agent stops serving its listeners, stops writing to its data
+// directory, releases the lock on it, and exits. Finally, the new agent takes
+// the lock and starts serving the listeners it received.
+package handoff
+
+import (
+	"errors"
+	"path/filepath"
+)
+
+const (
+	// SocketName is the name of the Unix socket handoffs are served on,
+	// relative to the data directory.
+	SocketName = "handoff.sock"
+
+	// LockName is the name of the file locked by the agent using the data
+	// directory, relative to the data directory.
+	LockName = "agent.lock"
+)
+
+// ErrNoServer is returned by Take when no agent serves handoffs for a data
+// directory.
+var ErrNoServer = errors.New("no running agent serves handoffs")
+
+// SocketPath returns the path of the socket handoffs are served on for the
+// data directory dir.
+func SocketPath(dir string) string {
+	return filepath.Join(dir, SocketName)
+}
+
+// Types of messages exchanged during a handoff.
+const (
+	// msgListeners is sent by the running agent along with the file
+	// descriptors of its listeners.
+	msgListeners = "listeners"
+	// msgRelease is sent by the new agent to ask the running agent to release
+	// its state.
+	msgRelease = "release"
+	// msgReleased is sent by the running agent once it released its state, or
+	// with an error if it failed to.
+	msgReleased = "released"
+)
+
+// message is a message exchanged during a handoff. Messages are encoded as
+// JSON, one per line.
+type message struct {
+	Type string `json:"type"`
+
+	// Listeners are the names of the listeners passed along with a
+	// msgListeners message, in the order of their file descriptors.
+	Listeners []string `json:"listeners,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
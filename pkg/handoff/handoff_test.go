@@ -0,0 +1,201 @@
+//go:build !windows
+// +build !windows
+
+package handoff
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+// shortTempDir returns a temporary directory whose path is short enough for
+// Unix sockets.
+func shortTempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "handoff")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return dir
+}
+
+func TestHandoff(t *testing.T) {
+	dir := shortTempDir(t)
+	path := SocketPath(dir)
+
+	lock, err := AcquireLock(dir)
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv, err := NewServer(path, map[string]net.Listener{"http": lis}, log.NewNopLogger())
+	require.NoError(t, err)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(context.Background(), func() error {
+			// Stop accepting connections in the running agent.
+			if err := lis.Close(); err != nil {
+				return err
+			}
+			return lock.Release()
+		})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	h, err := Take(ctx, path)
+	require.NoError(t, err)
+	defer h.Close()
+	require.Contains(t, h.Listeners, "http")
+	newLis := h.Listeners["http"]
+	defer newLis.Close()
+	require.Equal(t, lis.Addr().String(), newLis.Addr().String())
+
+	// The data directory is locked until the state is released.
+	_, err = AcquireLock(dir)
+	require.Error(t, err)
+
+	require.NoError(t, h.Release(ctx))
+	require.NoError(t, <-serveErr)
+
+	newLock, err := AcquireLock(dir)
+	require.NoError(t, err)
+	defer newLock.Release()
+
+	// Connections made after the running agent stopped accepting are accepted
+	// by the listener which was handed over.
+	go func() {
+		conn, err := net.Dial("tcp", newLis.Addr().String())
+		if err == nil {
+			_, _ = conn.Write([]byte("hello"))
+			_ = conn.Close()
+		}
+	}()
+	conn, err := newLis.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	bb, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(bb))
+
+	// The socket is free for the new agent to serve handoffs on.
+	srv, err = NewServer(path, map[string]net.Listener{"http": newLis}, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, srv.lis.Close())
+}
+
+func TestHandoff_ReleaseFailed(t *testing.T) {
+	dir := shortTempDir(t)
+	path := SocketPath(dir)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+
+	srv, err := NewServer(path, map[string]net.Listener{"http": lis}, log.NewNopLogger())
+	require.NoError(t, err)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(context.Background(), func() error {
+			return errors.New("flushing failed")
+		})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	h, err := Take(ctx, path)
+	require.NoError(t, err)
+	defer h.Close()
+	for _, lis := range h.Listeners {
+		defer lis.Close()
+	}
+
+	require.EqualError(t, h.Release(ctx), "running agent failed to release its state: flushing failed")
+	require.EqualError(t, <-serveErr, "flushing failed")
+}
+
+func TestServer_AbandonedHandoff(t *testing.T) {
+	dir := shortTempDir(t)
+	path := SocketPath(dir)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+
+	srv, err := NewServer(path, map[string]net.Listener{"http": lis}, log.NewNopLogger())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ctx, func() error { return nil })
+	}()
+
+	// A new agent which exits before asking for the state doesn't stop the
+	// running agent from serving handoffs.
+	h, err := Take(ctx, path)
+	require.NoError(t, err)
+	for _, lis := range h.Listeners {
+		require.NoError(t, lis.Close())
+	}
+	require.NoError(t, h.Close())
+
+	h, err = Take(ctx, path)
+	require.NoError(t, err)
+	defer h.Close()
+	for _, lis := range h.Listeners {
+		defer lis.Close()
+	}
+	require.NoError(t, h.Release(ctx))
+	require.NoError(t, <-serveErr)
+}
+
+func TestTake_NoServer(t *testing.T) {
+	dir := shortTempDir(t)
+
+	_, err := Take(context.Background(), SocketPath(dir))
+	require.ErrorIs(t, err, ErrNoServer)
+
+	// A socket left behind by an agent which didn't exit cleanly.
+	lis, err := net.ListenUnix("unix", &net.UnixAddr{Name: SocketPath(dir), Net: "unix"})
+	require.NoError(t, err)
+	lis.SetUnlinkOnClose(false)
+	require.NoError(t, lis.Close())
+
+	_, err = Take(context.Background(), SocketPath(dir))
+	require.ErrorIs(t, err, ErrNoServer)
+}
+
+func TestLock(t *testing.T) {
+	dir := t.TempDir()
+
+	lock, err := AcquireLock(dir)
+	require.NoError(t, err)
+
+	_, err = AcquireLock(dir)
+	require.EqualError(t, err, "data directory "+dir+" is locked by another agent")
+
+	require.NoError(t, lock.Release())
+	require.NoError(t, lock.Release())
+
+	lock, err = AcquireLock(dir)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+	require.FileExists(t, filepath.Join(dir, LockName))
+}
@@ -0,0 +1,330 @@
+//go:build !windows
+// +build !windows
+
+package handoff
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// requestTimeout is how long the running agent waits for a new agent to ask
+// for its state after handing over the listeners.
+const requestTimeout = time.Minute
+
+// maxListeners is the maximum number of listeners which can be handed over.
+const maxListeners = 16
+
+// Server serves handoffs of the listeners and state of the running agent.
+type Server struct {
+	log       log.Logger
+	lis       *net.UnixListener
+	listeners map[string]net.Listener
+}
+
+// NewServer listens for handoffs on the Unix socket at path. Agents
+// connecting to it are given listeners, which must be TCP or Unix listeners.
+//
+// The caller must hold the lock on the data directory of path.
+func NewServer(path string, listeners map[string]net.Listener, l log.Logger) (*Server, error) {
+	if len(listeners) > maxListeners {
+		return nil, fmt.Errorf("at most %d listeners can be handed over", maxListeners)
+	}
+
+	// A socket left behind by an agent which didn't exit cleanly can't belong
+	// to a running agent, since the caller holds the lock.
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("removing stale handoff socket: %w", err)
+	}
+	lis, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, fmt.Errorf("listening for handoffs: %w", err)
+	}
+
+	return &Server{log: l, lis: lis, listeners: listeners}, nil
+}
+
+// Serve hands the listeners over to new agents connecting to the socket.
+// Once a new agent asks for the state to be released, release is called and
+// its result is reported to the new agent. The socket is closed before the
+// new agent is notified, so that the new agent can serve handoffs on the same
+// path.
+//
+// Serve returns the result of release, or ctx.Err() if ctx is canceled
+// before a new agent asked for the state. Failed handoffs, such as when the
+// new agent exits before asking for the state, are logged and Serve waits
+// for the next agent.
+func (s *Server) Serve(ctx context.Context, release func() error) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = s.lis.Close()
+		case <-done:
+		}
+	}()
+	defer s.lis.Close()
+
+	for {
+		conn, err := s.lis.AcceptUnix()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("accepting handoff connection: %w", err)
+		}
+
+		released, err := s.handle(conn, release)
+		if released {
+			return err
+		} else if err != nil {
+			level.Warn(s.log).Log("msg", "handoff to new agent failed", "err", err)
+		}
+	}
+}
+
+// handle hands the listeners over to the agent connected through conn, and
+// releases the state if it asks for it. released reports whether release was
+// called.
+func (s *Server) handle(conn *net.UnixConn, release func() error) (released bool, err error) {
+	defer conn.Close()
+
+	names, files, err := s.listenerFiles()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+
+	fds := make([]int, len(files))
+	for i, f := range files {
+		fds[i] = int(f.Fd())
+	}
+	bb, err := json.Marshal(message{Type: msgListeners, Listeners: names})
+	if err != nil {
+		return false, err
+	}
+	bb = append(bb, '\n')
+	if _, _, err := conn.WriteMsgUnix(bb, syscall.UnixRights(fds...), nil); err != nil {
+		return false, fmt.Errorf("sending listeners: %w", err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(requestTimeout))
+	var req message
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return false, fmt.Errorf("reading request: %w", err)
+	} else if req.Type != msgRelease {
+		return false, fmt.Errorf("unexpected message %q", req.Type)
+	}
+
+	level.Info(s.log).Log("msg", "releasing state to new agent")
+	err = release()
+	_ = s.lis.Close()
+
+	resp := message{Type: msgReleased}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(requestTimeout))
+	if werr := json.NewEncoder(conn).Encode(resp); werr != nil {
+		level.Warn(s.log).Log("msg", "failed to notify new agent of released state", "err", werr)
+	}
+	return true, err
+}
+
+// listenerFiles returns duplicates of the file descriptors of the listeners,
+// along with their names.
+func (s *Server) listenerFiles() ([]string, []*os.File, error) {
+	names := make([]string, 0, len(s.listeners))
+	for name := range s.listeners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make([]*os.File, 0, len(names))
+	for _, name := range names {
+		fl, ok := s.listeners[name].(interface{ File() (*os.File, error) })
+		if !ok {
+			return nil, nil, fmt.Errorf("listener %q can't be handed over", name)
+		}
+		f, err := fl.File()
+		if err != nil {
+			for _, f := range files {
+				_ = f.Close()
+			}
+			return nil, nil, fmt.Errorf("getting file of listener %q: %w", name, err)
+		}
+		files = append(files, f)
+	}
+	return names, files, nil
+}
+
+// Handoff is a handoff in progress, taken from a running agent.
+type Handoff struct {
+	conn *net.UnixConn
+
+	// Listeners handed over by the running agent, by name. They must be
+	// closed by the caller.
+	Listeners map[string]net.Listener
+}
+
+// Take connects to the agent serving handoffs on the Unix socket at path and
+// receives its listeners. ErrNoServer is returned if no agent serves
+// handoffs on path.
+func Take(ctx context.Context, path string) (*Handoff, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", path)
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ECONNREFUSED) {
+		return nil, ErrNoServer
+	} else if err != nil {
+		return nil, fmt.Errorf("connecting to running agent: %w", err)
+	}
+	uc := conn.(*net.UnixConn)
+
+	listeners, err := receiveListeners(ctx, uc)
+	if err != nil {
+		_ = uc.Close()
+		return nil, err
+	}
+	return &Handoff{conn: uc, Listeners: listeners}, nil
+}
+
+func receiveListeners(ctx context.Context, conn *net.UnixConn) (map[string]net.Listener, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetReadDeadline(deadline)
+	}
+
+	buf := make([]byte, 4096)
+	oob := make([]byte, syscall.CmsgSpace(maxListeners*4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		return nil, fmt.Errorf("receiving listeners: %w", err)
+	}
+
+	// The file descriptors are collected first, so that they're closed if
+	// anything else fails.
+	var files []*os.File
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	cmsgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil {
+		return nil, fmt.Errorf("parsing listeners: %w", err)
+	}
+	for i := range cmsgs {
+		fds, err := syscall.ParseUnixRights(&cmsgs[i])
+		if err != nil {
+			return nil, fmt.Errorf("parsing listeners: %w", err)
+		}
+		for _, fd := range fds {
+			files = append(files, os.NewFile(uintptr(fd), "listener"))
+		}
+	}
+
+	if !bytes.HasSuffix(buf[:n], []byte("\n")) {
+		return nil, errors.New("received incomplete listeners message")
+	}
+	var msg message
+	if err := json.Unmarshal(buf[:n], &msg); err != nil {
+		return nil, fmt.Errorf("parsing listeners: %w", err)
+	} else if msg.Type != msgListeners {
+		return nil, fmt.Errorf("unexpected message %q", msg.Type)
+	} else if len(msg.Listeners) != len(files) {
+		return nil, fmt.Errorf("received %d listeners, expected %d", len(files), len(msg.Listeners))
+	}
+
+	listeners := make(map[string]net.Listener, len(files))
+	for i, f := range files {
+		lis, err := net.FileListener(f)
+		if err != nil {
+			for _, lis := range listeners {
+				_ = lis.Close()
+			}
+			return nil, fmt.Errorf("creating listener %q: %w", msg.Listeners[i], err)
+		}
+		listeners[msg.Listeners[i]] = lis
+	}
+	return listeners, nil
+}
+
+// Release asks the running agent to release its state and waits until it
+// did, or until the deadline of ctx.
+func (h *Handoff) Release(ctx context.Context) error {
+	deadline, _ := ctx.Deadline()
+	_ = h.conn.SetDeadline(deadline)
+
+	if err := json.NewEncoder(h.conn).Encode(message{Type: msgRelease}); err != nil {
+		return fmt.Errorf("requesting state: %w", err)
+	}
+	var resp message
+	if err := json.NewDecoder(h.conn).Decode(&resp); err != nil {
+		return fmt.Errorf("waiting for state to be released: %w", err)
+	} else if resp.Type != msgReleased {
+		return fmt.Errorf("unexpected message %q", resp.Type)
+	} else if resp.Error != "" {
+		return fmt.Errorf("running agent failed to release its state: %s", resp.Error)
+	}
+	return nil
+}
+
+// Close closes the connection to the running agent. The listeners aren't
+// closed.
+func (h *Handoff) Close() error {
+	return h.conn.Close()
+}
+
+// Lock is an exclusive lock on a data directory, held by the agent using it.
+type Lock struct {
+	mut sync.Mutex
+	f   *os.File
+}
+
+// AcquireLock locks the data directory dir. It fails if another process
+// holds the lock.
+func AcquireLock(dir string) (*Lock, error) {
+	f, err := os.OpenFile(filepath.Join(dir, LockName), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("opening lock file: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("data directory %s is locked by another agent", dir)
+		}
+		return nil, fmt.Errorf("locking data directory: %w", err)
+	}
+	return &Lock{f: f}, nil
+}
+
+// Release releases the lock. Calling Release more than once has no effect.
+func (l *Lock) Release() error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
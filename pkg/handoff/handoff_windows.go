@@ -0,0 +1,60 @@
+//go:build windows
+// +build windows
+
+package handoff
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/go-kit/log"
+)
+
+var errUnsupported = errors.New("upgrade handoffs are not supported on Windows")
+
+// Server serves handoffs of the listeners and state of the running agent.
+type Server struct{}
+
+// NewServer always fails on Windows.
+func NewServer(path string, listeners map[string]net.Listener, l log.Logger) (*Server, error) {
+	return nil, errUnsupported
+}
+
+// Serve always fails on Windows.
+func (s *Server) Serve(ctx context.Context, release func() error) error {
+	return errUnsupported
+}
+
+// Handoff is a handoff in progress, taken from a running agent.
+type Handoff struct {
+	Listeners map[string]net.Listener
+}
+
+// Take always fails on Windows.
+func Take(ctx context.Context, path string) (*Handoff, error) {
+	return nil, errUnsupported
+}
+
+// Release always fails on Windows.
+func (h *Handoff) Release(ctx context.Context) error {
+	return errUnsupported
+}
+
+// Close always fails on Windows.
+func (h *Handoff) Close() error {
+	return errUnsupported
+}
+
+// Lock is an exclusive lock on a data directory, held by the agent using it.
+type Lock struct{}
+
+// AcquireLock always fails on Windows.
+func AcquireLock(dir string) (*Lock, error) {
+	return nil, errUnsupported
+}
+
+// Release always fails on Windows.
+func (l *Lock) Release() error {
+	return errUnsupported
+}
@@ -121,7 +121,12 @@ func (i *Integration) Run(ctx context.Context) error {
 		return fmt.Errorf("unable to determine included metrics: %w", err)
 	}
 
-	rm, err := manager.New(memoryStorage, sysFs, manager.HousekeepingConfigFlags, includedMetrics, &collectorHTTPClient, i.c.RawCgroupPrefixAllowlist, i.c.EnvMetadataAllowlist, i.c.PerfEventsConfig, time.Duration(i.c.ResctrlInterval))
+	housekeepingConfig := manager.HouskeepingConfig{
+		Interval:     &i.c.MaxHousekeepingInterval,
+		AllowDynamic: &i.c.AllowDynamicHousekeeping,
+	}
+
+	rm, err := manager.New(memoryStorage, sysFs, housekeepingConfig, includedMetrics, &collectorHTTPClient, i.c.RawCgroupPrefixAllowlist, i.c.EnvMetadataAllowlist, i.c.PerfEventsConfig, time.Duration(i.c.ResctrlInterval))
 	if err != nil {
 		return fmt.Errorf("failed to create a manager: %w", err)
 	}
@@ -19,6 +19,10 @@ var DefaultConfig = Config{
 
 	StorageDuration: 2 * time.Minute,
 
+	// Housekeeping config defaults, matching the cadvisor flag defaults
+	MaxHousekeepingInterval:  60 * time.Second,
+	AllowDynamicHousekeeping: true,
+
 	// Containerd config defaults
 	Containerd:          "/run/containerd/containerd.sock",
 	ContainerdNamespace: "k8s.io",
@@ -64,6 +68,12 @@ type Config struct {
 	// StorageDuration length of time to keep data stored in memory (Default: 2m)
 	StorageDuration time.Duration `yaml:"storage_duration,omitempty"`
 
+	// MaxHousekeepingInterval largest interval to allow between container housekeepings (Default: 60s)
+	MaxHousekeepingInterval time.Duration `yaml:"max_housekeeping_interval,omitempty"`
+
+	// AllowDynamicHousekeeping whether to allow the housekeeping interval to be dynamic
+	AllowDynamicHousekeeping bool `yaml:"allow_dynamic_housekeeping,omitempty"`
+
 	// Containerd config options
 	// Containerd containerd endpoint
 	Containerd string `yaml:"containerd,omitempty"`
@@ -3,8 +3,10 @@ package integrations
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"path"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -471,7 +473,7 @@ func (m *Manager) scrapeServiceDiscovery(cfg ManagerConfig) discovery.Configs {
 	if newHost == "" {
 		newHost = "127.0.0.1"
 	}
-	localAddr := fmt.Sprintf("%s:%d", newHost, cfg.ListenPort)
+	localAddr := net.JoinHostPort(newHost, strconv.Itoa(cfg.ListenPort))
 	labels := model.LabelSet{}
 	labels[model.LabelName("agent_hostname")] = model.LabelValue(m.hostname)
 	for k, v := range cfg.Labels {
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"os"
 	"time"
 
 	"github.com/go-kit/log"
@@ -16,6 +17,7 @@ import (
 	integrations_v2 "github.com/grafana/agent/pkg/integrations/v2"
 	"github.com/grafana/agent/pkg/integrations/v2/metricsutils"
 	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v2"
 )
 
 // DefaultConfig is the default config for the mssql integration
@@ -31,6 +33,10 @@ type Config struct {
 	MaxIdleConnections int                `yaml:"max_idle_connections,omitempty"`
 	MaxOpenConnections int                `yaml:"max_open_connections,omitempty"`
 	Timeout            time.Duration      `yaml:"timeout,omitempty"`
+
+	// QueryConfigFile is the path of a sql_exporter collector config which
+	// replaces the default queries of the integration.
+	QueryConfigFile string `yaml:"query_config_file,omitempty"`
 }
 
 func (c Config) validate() error {
@@ -96,12 +102,21 @@ func (c *Config) NewIntegration(l log.Logger) (integrations.Integration, error)
 		return nil, fmt.Errorf("failed to validate config: %w", err)
 	}
 
+	collector := &collectorConfig
+	if c.QueryConfigFile != "" {
+		var err error
+		collector, err = loadCollectorConfig(c.QueryConfigFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	t, err := sql_exporter.NewTarget(
 		"mssqlintegration",
 		"",
 		string(c.ConnectionString),
 		[]*config.CollectorConfig{
-			&collectorConfig,
+			collector,
 		},
 		prometheus.Labels{},
 		&config.GlobalConfig{
@@ -123,3 +138,16 @@ func (c *Config) NewIntegration(l log.Logger) (integrations.Integration, error)
 		integrations.WithCollectors(col),
 	), nil
 }
+
+// loadCollectorConfig reads the sql_exporter collector config at path.
+func loadCollectorConfig(path string) (*config.CollectorConfig, error) {
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query_config_file: %w", err)
+	}
+	var cc config.CollectorConfig
+	if err := yaml.UnmarshalStrict(bb, &cc); err != nil {
+		return nil, fmt.Errorf("failed to parse query_config_file: %w", err)
+	}
+	return &cc, nil
+}
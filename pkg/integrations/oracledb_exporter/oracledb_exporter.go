@@ -3,10 +3,13 @@ package oracledbexporter
 import (
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/go-kit/log"
 	"github.com/grafana/agent/pkg/integrations"
 	oe "github.com/iamseth/oracledb_exporter/collector"
@@ -40,6 +43,10 @@ type Config struct {
 	MaxOpenConns         int                `yaml:"max_open_connections"`
 	MetricScrapeInterval time.Duration      `yaml:"metric_scrape_interval"`
 	QueryTimeout         int                `yaml:"query_timeout"`
+
+	// CustomMetrics are paths of TOML files defining metrics which are
+	// collected in addition to the default metrics.
+	CustomMetrics []string `yaml:"custom_metrics,omitempty"`
 }
 
 // ValidateConnString attempts to ensure the connection string supplied is valid
@@ -83,7 +90,7 @@ func (c *Config) InstanceKey(agentKey string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("%s:%s", u.Hostname(), u.Port()), nil
+	return net.JoinHostPort(u.Hostname(), u.Port()), nil
 }
 
 // NewIntegration returns the OracleDB Exporter Integration
@@ -102,13 +109,20 @@ func New(logger log.Logger, c *Config) (integrations.Integration, error) {
 	if err := validateConnString(string(c.ConnectionString)); err != nil {
 		return nil, fmt.Errorf("invalid connection string: %w", err)
 	}
+	// The exporter panics on custom metrics files it can't load, so they're
+	// checked upfront.
+	for _, path := range c.CustomMetrics {
+		var metrics oe.Metrics
+		if _, err := toml.DecodeFile(path, &metrics); err != nil {
+			return nil, fmt.Errorf("invalid custom metrics file %q: %w", path, err)
+		}
+	}
 
 	oeExporter, err := oe.NewExporter(logger, &oe.Config{
-		DSN:          string(c.ConnectionString),
-		MaxIdleConns: c.MaxIdleConns,
-		MaxOpenConns: c.MaxOpenConns,
-		// no custom metrics file for this integration
-		CustomMetrics:  "",
+		DSN:            string(c.ConnectionString),
+		MaxIdleConns:   c.MaxIdleConns,
+		MaxOpenConns:   c.MaxOpenConns,
+		CustomMetrics:  strings.Join(c.CustomMetrics, ","),
 		QueryTimeout:   c.QueryTimeout,
 		ScrapeInterval: c.MetricScrapeInterval,
 	})
@@ -3,7 +3,9 @@ package app_agent_receiver //nolint:golint
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
+	"strconv"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
@@ -161,7 +163,7 @@ func (i *appAgentReceiverIntegration) RunIntegration(ctx context.Context) error
 	}
 
 	srv := &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", i.conf.Server.Host, i.conf.Server.Port),
+		Addr:    net.JoinHostPort(i.conf.Server.Host, strconv.Itoa(i.conf.Server.Port)),
 		Handler: mw.Wrap(r),
 	}
 	errChan := make(chan error, 1)
@@ -1,7 +1,7 @@
 package vmware_exporter
 
 import (
-	"fmt"
+	"net"
 	"net/url"
 	"time"
 
@@ -68,7 +68,7 @@ func (c *Config) Identifier(g integrations.Globals) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("%s:%s", u.Hostname(), u.Port()), nil
+	return net.JoinHostPort(u.Hostname(), u.Port()), nil
 }
 
 // NewIntegration constructs a new instance of this integration.
@@ -349,6 +349,12 @@ func (a *Agent) Config() Config { return a.cfg }
 // InstanceManager returns the instance manager used by this Agent.
 func (a *Agent) InstanceManager() instance.Manager { return a.mm }
 
+// ClusterShard returns the shard of this Agent in the scraping service
+// cluster. See cluster.Cluster.Shard.
+func (a *Agent) ClusterShard() (shard, peers int, ok bool) {
+	return a.cluster.Shard()
+}
+
 // Stop stops the agent and all its instances.
 func (a *Agent) Stop() {
 	a.mut.Lock()
@@ -157,6 +157,16 @@ func (c *Cluster) WireGRPC(srv *grpc.Server) {
 	agentproto.RegisterScrapingServiceServer(srv, c)
 }
 
+// Shard returns the index of this agent among the healthy agents of the
+// cluster, along with the number of healthy agents. ok is false if the
+// scraping service is disabled or the agent hasn't joined the cluster yet.
+func (c *Cluster) Shard() (shard, peers int, ok bool) {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	return c.node.Shard()
+}
+
 // Stop stops the cluster and all of its dependencies.
 func (c *Cluster) Stop() {
 	c.mut.Lock()
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"hash/fnv"
 	"net/http"
+	"sort"
 	"sync"
 	"time"
 
@@ -272,6 +273,34 @@ func (n *node) WaitJoined(ctx context.Context) error {
 	return waitJoined(ctx, agentKey, n.ring.KVClient, n.lc.ID)
 }
 
+// Shard returns the index of the node among the healthy nodes of the
+// cluster, ordered by address, along with the number of healthy nodes. ok is
+// false if the node hasn't joined the cluster.
+func (n *node) Shard() (shard, peers int, ok bool) {
+	n.mut.RLock()
+	defer n.mut.RUnlock()
+
+	if n.ring == nil || n.lc == nil {
+		return 0, 0, false
+	}
+	rs, err := n.ring.GetAllHealthy(ring.Read)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	addrs := make([]string, 0, len(rs.Instances))
+	for _, inst := range rs.Instances {
+		addrs = append(addrs, inst.Addr)
+	}
+	sort.Strings(addrs)
+	for i, addr := range addrs {
+		if addr == n.lc.Addr {
+			return i, len(addrs), true
+		}
+	}
+	return 0, 0, false
+}
+
 func waitJoined(ctx context.Context, key string, kvClient kv.Client, id string) error {
 	kvClient.WatchKey(ctx, key, func(value interface{}) bool {
 		if value == nil {
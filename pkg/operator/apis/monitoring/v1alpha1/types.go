@@ -184,6 +184,23 @@ type GrafanaAgentSpec struct {
 	DisableSupportBundle bool `json:"disableSupportBundle,omitempty"`
 }
 
+// ObjectReference references a ServiceMonitor, PodMonitor, Probe, or PodLogs
+// resource.
+type ObjectReference struct {
+	// Resource of the referent.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=servicemonitors;podmonitors;probes;podlogs
+	Resource string `json:"resource"`
+	// Namespace of the referent.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// Name of the referent. When not set, all resources in Namespace are
+	// matched.
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
 // +kubebuilder:object:generate=false
 
 // ObjectSelector is a set of selectors to use for finding an object in the
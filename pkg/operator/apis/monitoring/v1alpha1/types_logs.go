@@ -33,6 +33,10 @@ type LogsSubsystemSpec struct {
 	// each metric that is user-created. The label value will always be the
 	// namespace of the object that is being created.
 	EnforcedNamespaceLabel string `json:"enforcedNamespaceLabel,omitempty"`
+	// ExcludedFromEnforcement lists the resources which EnforcedNamespaceLabel
+	// isn't applied to, such as resources owned by cluster administrators
+	// which relabel the namespace themselves.
+	ExcludedFromEnforcement []ObjectReference `json:"excludedFromEnforcement,omitempty"`
 }
 
 // LogsClientSpec defines the client integration for logs, indicating which
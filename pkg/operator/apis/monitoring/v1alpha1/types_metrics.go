@@ -31,10 +31,13 @@ type MetricsSubsystemSpec struct {
 	// denote Grafana Agent cluster. Defaults to "cluster." The external label is
 	// _not_ added when the value is set to the empty string.
 	MetricsExternalLabelName *string `json:"metricsExternalLabelName,omitempty"`
-	// ScrapeInterval is the time between consecutive scrapes.
+	// ScrapeInterval is the time between consecutive scrapes. It is the
+	// default for ServiceMonitor, PodMonitor, and Probe endpoints which don't
+	// set an interval.
 	ScrapeInterval string `json:"scrapeInterval,omitempty"`
 	// ScrapeTimeout is the time to wait for a target to respond before marking a
-	// scrape as failed.
+	// scrape as failed. It is the default for ServiceMonitor, PodMonitor, and
+	// Probe endpoints which don't set a timeout.
 	ScrapeTimeout string `json:"scrapeTimeout,omitempty"`
 	// ExternalLabels are labels to add to any time series when sending data over
 	// remote_write.
@@ -56,6 +59,10 @@ type MetricsSubsystemSpec struct {
 	// each metric that is user-created. The label value is always the
 	// namespace of the object that is being created.
 	EnforcedNamespaceLabel string `json:"enforcedNamespaceLabel,omitempty"`
+	// ExcludedFromEnforcement lists the resources which EnforcedNamespaceLabel
+	// isn't applied to, such as resources owned by cluster administrators
+	// which relabel the namespace themselves.
+	ExcludedFromEnforcement []ObjectReference `json:"excludedFromEnforcement,omitempty"`
 	// EnforcedSampleLimit defines a global limit on the number of scraped samples
 	// that are accepted. This overrides any SampleLimit set per
 	// ServiceMonitor and/or PodMonitor. It is meant to be used by admins to
@@ -697,6 +697,11 @@ func (in *LogsSubsystemSpec) DeepCopyInto(out *LogsSubsystemSpec) {
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ExcludedFromEnforcement != nil {
+		in, out := &in.ExcludedFromEnforcement, &out.ExcludedFromEnforcement
+		*out = make([]ObjectReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogsSubsystemSpec.
@@ -999,6 +1004,11 @@ func (in *MetricsSubsystemSpec) DeepCopyInto(out *MetricsSubsystemSpec) {
 		}
 	}
 	out.ArbitraryFSAccessThroughSMs = in.ArbitraryFSAccessThroughSMs
+	if in.ExcludedFromEnforcement != nil {
+		in, out := &in.ExcludedFromEnforcement, &out.ExcludedFromEnforcement
+		*out = make([]ObjectReference, len(*in))
+		copy(*out, *in)
+	}
 	if in.EnforcedSampleLimit != nil {
 		in, out := &in.EnforcedSampleLimit, &out.EnforcedSampleLimit
 		*out = new(uint64)
@@ -1046,6 +1056,21 @@ func (in *MultilineStageSpec) DeepCopy() *MultilineStageSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectReference) DeepCopyInto(out *ObjectReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectReference.
+func (in *ObjectReference) DeepCopy() *ObjectReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OutputStageSpec) DeepCopyInto(out *OutputStageSpec) {
 	*out = *in
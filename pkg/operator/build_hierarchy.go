@@ -20,7 +20,9 @@ import (
 )
 
 // buildHierarchy constructs a resource hierarchy starting from root.
-func buildHierarchy(ctx context.Context, l log.Logger, cli client.Client, root *gragent.GrafanaAgent) (deployment gragent.Deployment, watchers []hierarchy.Watcher, err error) {
+// ServiceMonitors, PodMonitors, Probes, and PodLogs excluded by filter are left
+// out of the hierarchy.
+func buildHierarchy(ctx context.Context, l log.Logger, cli client.Client, root *gragent.GrafanaAgent, filter resourceFilter) (deployment gragent.Deployment, watchers []hierarchy.Watcher, err error) {
 	deployment.Agent = root
 
 	// search is used throughout BuildHierarchy, where it will perform a list for
@@ -73,6 +75,11 @@ func buildHierarchy(ctx context.Context, l log.Logger, cli client.Client, root *
 		if err := search(children); err != nil {
 			return deployment, nil, err
 		}
+		for _, list := range []client.ObjectList{&serviceMonitors, &podMonitors, &probes} {
+			if err := filter.FilterList(l, list); err != nil {
+				return deployment, nil, err
+			}
+		}
 
 		deployment.Metrics = append(deployment.Metrics, gragent.MetricsDeployment{
 			Instance:        metricsInst,
@@ -93,6 +100,9 @@ func buildHierarchy(ctx context.Context, l log.Logger, cli client.Client, root *
 		if err := search(children); err != nil {
 			return deployment, nil, err
 		}
+		if err := filter.FilterList(l, &podLogs); err != nil {
+			return deployment, nil, err
+		}
 
 		deployment.Logs = append(deployment.Logs, gragent.LogsDeployment{
 			Instance: logsInst,
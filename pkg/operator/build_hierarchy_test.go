@@ -47,7 +47,7 @@ func Test_buildHierarchy(t *testing.T) {
 	err := cli.Get(ctx, client.ObjectKey{Namespace: "default", Name: "grafana-agent-example"}, &root)
 	require.NoError(t, err)
 
-	deployment, watchers, err := buildHierarchy(ctx, l, cli, &root)
+	deployment, watchers, err := buildHierarchy(ctx, l, cli, &root, resourceFilter{})
 	require.NoError(t, err)
 
 	// Check resources in hierarchy
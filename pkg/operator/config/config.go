@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io/fs"
 	"path"
+	"regexp"
 
 	"github.com/fatih/structs"
 	jsonnet "github.com/google/go-jsonnet"
@@ -160,6 +161,22 @@ func createVM(secrets assets.SecretStore) (*jsonnet.VM, error) {
 			return SanitizeLabelName(s), nil
 		},
 	})
+	vm.NativeFunction(&jsonnet.NativeFunction{
+		Name:   "regexQuote",
+		Params: ast.Identifiers{"text"},
+		Func: func(i []interface{}) (interface{}, error) {
+			s, ok := i[0].(string)
+			if !ok {
+				return nil, jsonnet.RuntimeError{Msg: "text must be a string"}
+			}
+			return regexp.QuoteMeta(s), nil
+		},
+	})
+	vm.NativeFunction(&jsonnet.NativeFunction{
+		Name:   "validRelabelConfigs",
+		Params: ast.Identifiers{"configs"},
+		Func:   validRelabelConfigs,
+	})
 
 	return vm, nil
 }
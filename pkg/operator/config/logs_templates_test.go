@@ -678,6 +678,59 @@ func TestLogsConfig(t *testing.T) {
 						replacement: /var/log/pods/*$1/*.log
 			`),
 		},
+		{
+			name: "pod logs excluded from enforcement",
+			input: map[string]interface{}{
+				"agent": agent,
+				"global": &gragent.LogsSubsystemSpec{
+					ExcludedFromEnforcement: []gragent.ObjectReference{{Resource: "podlogs", Namespace: "app"}},
+				},
+				"instance": &gragent.LogsDeployment{
+					Instance: &gragent.LogsInstance{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "inst", Name: "default"},
+					},
+					PodLogs: []*gragent.PodLogs{{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "pod"},
+					}},
+				},
+				"apiServer": &prom.APIServerConfig{},
+
+				"ignoreNamespaceSelectors": false,
+				"enforcedNamespaceLabel":   "namespace",
+			},
+			expect: util.Untab(`
+				name: inst/default
+				scrape_configs:
+				- job_name: podLogs/app/pod
+					kubernetes_sd_configs:
+					- namespaces:
+							names:
+							- app
+						role: pod
+					relabel_configs:
+					- source_labels:
+						- job
+						target_label: __tmp_prometheus_job_name
+					- source_labels:
+						- __meta_kubernetes_namespace
+						target_label: namespace
+					- source_labels:
+						- __meta_kubernetes_service_name
+						target_label: service
+					- source_labels:
+						- __meta_kubernetes_pod_name
+						target_label: pod
+					- source_labels:
+						- __meta_kubernetes_pod_container_name
+						target_label: container
+					- replacement: app/pod
+						target_label: job
+					- source_labels: ['__meta_kubernetes_pod_uid', '__meta_kubernetes_pod_container_name']
+						target_label: __path__
+						separator: /
+						replacement: /var/log/pods/*$1/*.log
+			`),
+		},
 		{
 			name: "additional scrape configs",
 			input: map[string]interface{}{
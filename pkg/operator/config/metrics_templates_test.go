@@ -221,6 +221,30 @@ func TestKubeSDConfig(t *testing.T) {
 					ca_file: ca
 			`),
 		},
+		{
+			name: "attach node metadata",
+			input: map[string]interface{}{
+				"namespace":      "operator",
+				"role":           "pod",
+				"attachMetadata": &prom_v1.AttachMetadata{Node: true},
+			},
+			expect: util.Untab(`
+				role: pod
+				attach_metadata:
+					node: true
+			`),
+		},
+		{
+			name: "attach node metadata ignored for other roles",
+			input: map[string]interface{}{
+				"namespace":      "operator",
+				"role":           "endpoints",
+				"attachMetadata": &prom_v1.AttachMetadata{Node: true},
+			},
+			expect: util.Untab(`
+				role: endpoints
+			`),
+		},
 	}
 
 	for _, tc := range tt {
@@ -228,7 +252,7 @@ func TestKubeSDConfig(t *testing.T) {
 			vm, err := createVM(testStore())
 			require.NoError(t, err)
 
-			args := []string{"namespace", "namespaces", "apiServer", "role"}
+			args := []string{"namespace", "namespaces", "apiServer", "role", "attachMetadata"}
 			for _, arg := range args {
 				bb, err := jsonnetMarshal(tc.input[arg])
 				require.NoError(t, err)
@@ -250,7 +274,358 @@ func TestPodMonitor(t *testing.T) {
 		expect string
 	}{
 		{
-			name: "default",
+			name: "default",
+			input: map[string]interface{}{
+				"agentNamespace": "operator",
+				"monitor": prom_v1.PodMonitor{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Namespace: "operator",
+						Name:      "podmonitor",
+					},
+				},
+				"endpoint": prom_v1.PodMetricsEndpoint{
+					Port:        "metrics",
+					EnableHttp2: &falseVal,
+				},
+				"index":                    0,
+				"apiServer":                prom_v1.APIServerConfig{},
+				"overrideHonorLabels":      false,
+				"overrideHonorTimestamps":  false,
+				"ignoreNamespaceSelectors": false,
+				"enforcedNamespaceLabel":   "",
+				"enforcedSampleLimit":      nil,
+				"enforcedTargetLimit":      nil,
+				"shards":                   1,
+			},
+			expect: util.Untab(`
+				job_name: podMonitor/operator/podmonitor/0
+				enable_http2: false
+				honor_labels: false
+				kubernetes_sd_configs:
+				- role: pod
+				  namespaces:
+						names: [operator]
+				relabel_configs:
+				- source_labels: [job]
+					target_label: __tmp_prometheus_job_name
+				- source_labels: [__meta_kubernetes_pod_phase]
+					regex: (Failed|Succeeded)
+					action: drop
+				- source_labels: [__meta_kubernetes_pod_container_port_name]
+					regex: metrics
+					action: keep
+				- source_labels: [__meta_kubernetes_namespace]
+					target_label: namespace
+				- source_labels: [__meta_kubernetes_service_name]
+					target_label: service
+				- source_labels: [__meta_kubernetes_pod_name]
+					target_label: pod
+				- source_labels: [__meta_kubernetes_pod_container_name]
+					target_label: container
+				- target_label: job
+					replacement: operator/podmonitor
+				- target_label: endpoint
+					replacement: metrics
+				- source_labels: [__address__]
+					target_label: __tmp_hash
+					action: hashmod
+					modulus: 1
+				- source_labels: [__tmp_hash]
+					action: keep
+					regex: $(SHARD)
+			`),
+		},
+		{
+			name: "attach node metadata",
+			input: map[string]interface{}{
+				"agentNamespace": "operator",
+				"monitor": prom_v1.PodMonitor{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Namespace: "operator",
+						Name:      "podmonitor",
+					},
+					Spec: prom_v1.PodMonitorSpec{
+						AttachMetadata: &prom_v1.AttachMetadata{Node: true},
+					},
+				},
+				"endpoint": prom_v1.PodMetricsEndpoint{
+					Port:        "metrics",
+					EnableHttp2: &falseVal,
+				},
+				"index":                    0,
+				"apiServer":                prom_v1.APIServerConfig{},
+				"overrideHonorLabels":      false,
+				"overrideHonorTimestamps":  false,
+				"ignoreNamespaceSelectors": false,
+				"enforcedNamespaceLabel":   "",
+				"enforcedSampleLimit":      nil,
+				"enforcedTargetLimit":      nil,
+				"shards":                   1,
+			},
+			expect: util.Untab(`
+				job_name: podMonitor/operator/podmonitor/0
+				enable_http2: false
+				honor_labels: false
+				kubernetes_sd_configs:
+				- role: pod
+				  namespaces:
+						names: [operator]
+				  attach_metadata:
+						node: true
+				relabel_configs:
+				- source_labels: [job]
+					target_label: __tmp_prometheus_job_name
+				- source_labels: [__meta_kubernetes_pod_phase]
+					regex: (Failed|Succeeded)
+					action: drop
+				- source_labels: [__meta_kubernetes_pod_container_port_name]
+					regex: metrics
+					action: keep
+				- source_labels: [__meta_kubernetes_namespace]
+					target_label: namespace
+				- source_labels: [__meta_kubernetes_service_name]
+					target_label: service
+				- source_labels: [__meta_kubernetes_pod_name]
+					target_label: pod
+				- source_labels: [__meta_kubernetes_pod_container_name]
+					target_label: container
+				- target_label: job
+					replacement: operator/podmonitor
+				- target_label: endpoint
+					replacement: metrics
+				- source_labels: [__address__]
+					target_label: __tmp_hash
+					action: hashmod
+					modulus: 1
+				- source_labels: [__tmp_hash]
+					action: keep
+					regex: $(SHARD)
+			`),
+		},
+		{
+			name: "endpoint options",
+			input: map[string]interface{}{
+				"agentNamespace": "operator",
+				"monitor": prom_v1.PodMonitor{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Namespace: "operator",
+						Name:      "podmonitor",
+					},
+				},
+				"endpoint": prom_v1.PodMetricsEndpoint{
+					Port:            "metrics",
+					EnableHttp2:     &falseVal,
+					ProxyURL:        pointer.String("http://proxy:3128"),
+					Params:          map[string][]string{"module": {"http_2xx"}},
+					FollowRedirects: &falseVal,
+					FilterRunning:   &falseVal,
+				},
+				"index":                    0,
+				"apiServer":                prom_v1.APIServerConfig{},
+				"overrideHonorLabels":      false,
+				"overrideHonorTimestamps":  false,
+				"ignoreNamespaceSelectors": false,
+				"enforcedNamespaceLabel":   "",
+				"enforcedSampleLimit":      nil,
+				"enforcedTargetLimit":      nil,
+				"shards":                   1,
+			},
+			expect: util.Untab(`
+				job_name: podMonitor/operator/podmonitor/0
+				enable_http2: false
+				proxy_url: http://proxy:3128
+				params:
+					module: [http_2xx]
+				follow_redirects: false
+				honor_labels: false
+				kubernetes_sd_configs:
+				- role: pod
+				  namespaces:
+						names: [operator]
+				relabel_configs:
+				- source_labels: [job]
+					target_label: __tmp_prometheus_job_name
+				- source_labels: [__meta_kubernetes_pod_container_port_name]
+					regex: metrics
+					action: keep
+				- source_labels: [__meta_kubernetes_namespace]
+					target_label: namespace
+				- source_labels: [__meta_kubernetes_service_name]
+					target_label: service
+				- source_labels: [__meta_kubernetes_pod_name]
+					target_label: pod
+				- source_labels: [__meta_kubernetes_pod_container_name]
+					target_label: container
+				- target_label: job
+					replacement: operator/podmonitor
+				- target_label: endpoint
+					replacement: metrics
+				- source_labels: [__address__]
+					target_label: __tmp_hash
+					action: hashmod
+					modulus: 1
+				- source_labels: [__tmp_hash]
+					action: keep
+					regex: $(SHARD)
+			`),
+		},
+		{
+			name: "oauth2",
+			input: map[string]interface{}{
+				"agentNamespace": "operator",
+				"monitor": prom_v1.PodMonitor{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Namespace: "operator",
+						Name:      "podmonitor",
+					},
+				},
+				"endpoint": prom_v1.PodMetricsEndpoint{
+					Port:        "metrics",
+					EnableHttp2: &falseVal,
+					OAuth2: &prom_v1.OAuth2{
+						ClientID: prom_v1.SecretOrConfigMap{
+							ConfigMap: &v1.ConfigMapKeySelector{
+								LocalObjectReference: v1.LocalObjectReference{Name: "obj"},
+								Key:                  "key",
+							},
+						},
+						ClientSecret: v1.SecretKeySelector{
+							LocalObjectReference: v1.LocalObjectReference{Name: "obj"},
+							Key:                  "key",
+						},
+						TokenURL:       "https://auth.example.com/token",
+						Scopes:         []string{"metrics"},
+						EndpointParams: map[string]string{"audience": "exporter"},
+					},
+				},
+				"index":                    0,
+				"apiServer":                prom_v1.APIServerConfig{},
+				"overrideHonorLabels":      false,
+				"overrideHonorTimestamps":  false,
+				"ignoreNamespaceSelectors": false,
+				"enforcedNamespaceLabel":   "",
+				"enforcedSampleLimit":      nil,
+				"enforcedTargetLimit":      nil,
+				"shards":                   1,
+			},
+			expect: util.Untab(`
+				job_name: podMonitor/operator/podmonitor/0
+				enable_http2: false
+				honor_labels: false
+				kubernetes_sd_configs:
+				- role: pod
+				  namespaces:
+						names: [operator]
+				oauth2:
+					client_id: secretcm
+					client_secret: secretkey
+					token_url: https://auth.example.com/token
+					scopes: [metrics]
+					endpoint_params:
+						audience: exporter
+				relabel_configs:
+				- source_labels: [job]
+					target_label: __tmp_prometheus_job_name
+				- source_labels: [__meta_kubernetes_pod_phase]
+					regex: (Failed|Succeeded)
+					action: drop
+				- source_labels: [__meta_kubernetes_pod_container_port_name]
+					regex: metrics
+					action: keep
+				- source_labels: [__meta_kubernetes_namespace]
+					target_label: namespace
+				- source_labels: [__meta_kubernetes_service_name]
+					target_label: service
+				- source_labels: [__meta_kubernetes_pod_name]
+					target_label: pod
+				- source_labels: [__meta_kubernetes_pod_container_name]
+					target_label: container
+				- target_label: job
+					replacement: operator/podmonitor
+				- target_label: endpoint
+					replacement: metrics
+				- source_labels: [__address__]
+					target_label: __tmp_hash
+					action: hashmod
+					modulus: 1
+				- source_labels: [__tmp_hash]
+					action: keep
+					regex: $(SHARD)
+			`),
+		},
+		{
+			name: "authorization",
+			input: map[string]interface{}{
+				"agentNamespace": "operator",
+				"monitor": prom_v1.PodMonitor{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Namespace: "operator",
+						Name:      "podmonitor",
+					},
+				},
+				"endpoint": prom_v1.PodMetricsEndpoint{
+					Port:        "metrics",
+					EnableHttp2: &falseVal,
+					Authorization: &prom_v1.SafeAuthorization{
+						Credentials: &v1.SecretKeySelector{
+							LocalObjectReference: v1.LocalObjectReference{Name: "obj"},
+							Key:                  "key",
+						},
+					},
+				},
+				"index":                    0,
+				"apiServer":                prom_v1.APIServerConfig{},
+				"overrideHonorLabels":      false,
+				"overrideHonorTimestamps":  false,
+				"ignoreNamespaceSelectors": false,
+				"enforcedNamespaceLabel":   "",
+				"enforcedSampleLimit":      nil,
+				"enforcedTargetLimit":      nil,
+				"shards":                   1,
+			},
+			expect: util.Untab(`
+				job_name: podMonitor/operator/podmonitor/0
+				enable_http2: false
+				honor_labels: false
+				kubernetes_sd_configs:
+				- role: pod
+				  namespaces:
+						names: [operator]
+				authorization:
+					type: Bearer
+					credentials: secretkey
+				relabel_configs:
+				- source_labels: [job]
+					target_label: __tmp_prometheus_job_name
+				- source_labels: [__meta_kubernetes_pod_phase]
+					regex: (Failed|Succeeded)
+					action: drop
+				- source_labels: [__meta_kubernetes_pod_container_port_name]
+					regex: metrics
+					action: keep
+				- source_labels: [__meta_kubernetes_namespace]
+					target_label: namespace
+				- source_labels: [__meta_kubernetes_service_name]
+					target_label: service
+				- source_labels: [__meta_kubernetes_pod_name]
+					target_label: pod
+				- source_labels: [__meta_kubernetes_pod_container_name]
+					target_label: container
+				- target_label: job
+					replacement: operator/podmonitor
+				- target_label: endpoint
+					replacement: metrics
+				- source_labels: [__address__]
+					target_label: __tmp_hash
+					action: hashmod
+					modulus: 1
+				- source_labels: [__tmp_hash]
+					action: keep
+					regex: $(SHARD)
+			`),
+		},
+		{
+			name: "match expressions",
 			input: map[string]interface{}{
 				"agentNamespace": "operator",
 				"monitor": prom_v1.PodMonitor{
@@ -258,6 +633,15 @@ func TestPodMonitor(t *testing.T) {
 						Namespace: "operator",
 						Name:      "podmonitor",
 					},
+					Spec: prom_v1.PodMonitorSpec{
+						Selector: meta_v1.LabelSelector{
+							MatchLabels: map[string]string{"app": "foo"},
+							MatchExpressions: []meta_v1.LabelSelectorRequirement{
+								{Key: "app.kubernetes.io/tier", Operator: meta_v1.LabelSelectorOpIn, Values: []string{"web", "api"}},
+								{Key: "canary", Operator: meta_v1.LabelSelectorOpDoesNotExist},
+							},
+						},
+					},
 				},
 				"endpoint": prom_v1.PodMetricsEndpoint{
 					Port:        "metrics",
@@ -284,6 +668,87 @@ func TestPodMonitor(t *testing.T) {
 				relabel_configs:
 				- source_labels: [job]
 					target_label: __tmp_prometheus_job_name
+				- source_labels: [__meta_kubernetes_pod_phase]
+					regex: (Failed|Succeeded)
+					action: drop
+				- source_labels: [__meta_kubernetes_pod_label_app]
+					regex: foo
+					action: keep
+				- source_labels: [__meta_kubernetes_pod_label_app_kubernetes_io_tier]
+					regex: web|api
+					action: keep
+				- source_labels: [__meta_kubernetes_pod_labelpresent_canary]
+					regex: "true"
+					action: drop
+				- source_labels: [__meta_kubernetes_pod_container_port_name]
+					regex: metrics
+					action: keep
+				- source_labels: [__meta_kubernetes_namespace]
+					target_label: namespace
+				- source_labels: [__meta_kubernetes_service_name]
+					target_label: service
+				- source_labels: [__meta_kubernetes_pod_name]
+					target_label: pod
+				- source_labels: [__meta_kubernetes_pod_container_name]
+					target_label: container
+				- target_label: job
+					replacement: operator/podmonitor
+				- target_label: endpoint
+					replacement: metrics
+				- source_labels: [__address__]
+					target_label: __tmp_hash
+					action: hashmod
+					modulus: 1
+				- source_labels: [__tmp_hash]
+					action: keep
+					regex: $(SHARD)
+			`),
+		},
+		{
+			name: "limits",
+			input: map[string]interface{}{
+				"agentNamespace": "operator",
+				"monitor": prom_v1.PodMonitor{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Namespace: "operator",
+						Name:      "podmonitor",
+					},
+					Spec: prom_v1.PodMonitorSpec{
+						SampleLimit:           1000,
+						TargetLimit:           10,
+						LabelLimit:            30,
+						LabelNameLengthLimit:  128,
+						LabelValueLengthLimit: 512,
+					},
+				},
+				"endpoint": prom_v1.PodMetricsEndpoint{
+					Port:        "metrics",
+					EnableHttp2: &falseVal,
+				},
+				"index":                    0,
+				"apiServer":                prom_v1.APIServerConfig{},
+				"overrideHonorLabels":      false,
+				"overrideHonorTimestamps":  false,
+				"ignoreNamespaceSelectors": false,
+				"enforcedNamespaceLabel":   "",
+				"enforcedSampleLimit":      pointer.Uint64(500),
+				"enforcedTargetLimit":      nil,
+				"shards":                   1,
+			},
+			expect: util.Untab(`
+				job_name: podMonitor/operator/podmonitor/0
+				enable_http2: false
+				honor_labels: false
+				kubernetes_sd_configs:
+				- role: pod
+				  namespaces:
+						names: [operator]
+				relabel_configs:
+				- source_labels: [job]
+					target_label: __tmp_prometheus_job_name
+				- source_labels: [__meta_kubernetes_pod_phase]
+					regex: (Failed|Succeeded)
+					action: drop
 				- source_labels: [__meta_kubernetes_pod_container_port_name]
 					regex: metrics
 					action: keep
@@ -306,6 +771,11 @@ func TestPodMonitor(t *testing.T) {
 				- source_labels: [__tmp_hash]
 					action: keep
 					regex: $(SHARD)
+				sample_limit: 500
+				target_limit: 10
+				label_limit: 30
+				label_name_length_limit: 128
+				label_value_length_limit: 512
 			`),
 		},
 	}
@@ -407,10 +877,74 @@ func TestProbe(t *testing.T) {
 					target_label: instance
 				- replacement: ""
 					target_label: __address__
+				- source_labels: [__param_target]
+					target_label: __tmp_hash
+					modulus: 1
+					action: hashmod
+				- source_labels: [__tmp_hash]
+					regex: $(SHARD)
+					action: keep
 				tls_config:
 					insecure_skip_verify: true
 			`),
 		},
+		{
+			name: "static targets",
+			input: map[string]interface{}{
+				"agentNamespace": "operator",
+				"probe": prom_v1.Probe{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Namespace: "operator",
+						Name:      "probe",
+					},
+					Spec: prom_v1.ProbeSpec{
+						Module: "http_2xx",
+						ProberSpec: prom_v1.ProberSpec{
+							URL: "blackbox-exporter:9115",
+						},
+						Targets: prom_v1.ProbeTargets{
+							StaticConfig: &prom_v1.ProbeTargetStaticConfig{
+								Targets: []string{"https://example.com"},
+							},
+						},
+					},
+				},
+				"apiServer":                prom_v1.APIServerConfig{},
+				"overrideHonorTimestamps":  false,
+				"ignoreNamespaceSelectors": false,
+				"enforcedNamespaceLabel":   "",
+				"enforcedSampleLimit":      nil,
+				"enforcedTargetLimit":      nil,
+				"shards":                   3,
+			},
+			expect: util.Untab(`
+				job_name: probe/operator/probe
+				honor_timestamps: true
+				metrics_path: /probe
+				params:
+					module: ["http_2xx"]
+				static_configs:
+				- targets: ["https://example.com"]
+					labels:
+						namespace: operator
+				relabel_configs:
+				- source_labels: [job]
+					target_label: __tmp_prometheus_job_name
+				- source_labels: [__address__]
+					target_label: __param_target
+				- source_labels: [__param_target]
+					target_label: instance
+				- replacement: blackbox-exporter:9115
+					target_label: __address__
+				- source_labels: [__param_target]
+					target_label: __tmp_hash
+					modulus: 3
+					action: hashmod
+				- source_labels: [__tmp_hash]
+					regex: $(SHARD)
+					action: keep
+			`),
+		},
 	}
 
 	for _, tc := range tt {
@@ -891,6 +1425,9 @@ func TestServiceMonitor(t *testing.T) {
 				- source_labels:
 					- __meta_kubernetes_pod_container_name
 					target_label: container
+				- source_labels: [__meta_kubernetes_pod_phase]
+					regex: (Failed|Succeeded)
+					action: drop
 				- replacement: $1
 					source_labels:
 					- __meta_kubernetes_service_name
@@ -1035,6 +1572,189 @@ func TestTLSConfig(t *testing.T) {
 	}
 }
 
+func TestMatchExpressionRelabels(t *testing.T) {
+	tt := []struct {
+		name        string
+		role        string
+		expressions []meta_v1.LabelSelectorRequirement
+		expect      string
+		expectErr   string
+	}{
+		{
+			name:   "no expressions",
+			role:   "pod",
+			expect: `[]`,
+		},
+		{
+			name: "in",
+			role: "pod",
+			expressions: []meta_v1.LabelSelectorRequirement{
+				{Key: "app", Operator: meta_v1.LabelSelectorOpIn, Values: []string{"foo", "bar"}},
+			},
+			expect: util.Untab(`
+				- source_labels: [__meta_kubernetes_pod_label_app]
+					regex: foo|bar
+					action: keep
+			`),
+		},
+		{
+			name: "not in",
+			role: "service",
+			expressions: []meta_v1.LabelSelectorRequirement{
+				{Key: "app", Operator: meta_v1.LabelSelectorOpNotIn, Values: []string{"foo"}},
+			},
+			expect: util.Untab(`
+				- source_labels: [__meta_kubernetes_service_label_app]
+					regex: foo
+					action: drop
+			`),
+		},
+		{
+			name: "exists",
+			role: "ingress",
+			expressions: []meta_v1.LabelSelectorRequirement{
+				{Key: "app.kubernetes.io/name", Operator: meta_v1.LabelSelectorOpExists},
+			},
+			expect: util.Untab(`
+				- source_labels: [__meta_kubernetes_ingress_labelpresent_app_kubernetes_io_name]
+					regex: "true"
+					action: keep
+			`),
+		},
+		{
+			name: "does not exist",
+			role: "pod",
+			expressions: []meta_v1.LabelSelectorRequirement{
+				{Key: "canary", Operator: meta_v1.LabelSelectorOpDoesNotExist},
+			},
+			expect: util.Untab(`
+				- source_labels: [__meta_kubernetes_pod_labelpresent_canary]
+					regex: "true"
+					action: drop
+			`),
+		},
+		{
+			name: "values are escaped",
+			role: "pod",
+			expressions: []meta_v1.LabelSelectorRequirement{
+				{Key: "version", Operator: meta_v1.LabelSelectorOpIn, Values: []string{"v1.0", "v1.1"}},
+			},
+			expect: util.Untab(`
+				- source_labels: [__meta_kubernetes_pod_label_version]
+					regex: v1\.0|v1\.1
+					action: keep
+			`),
+		},
+		{
+			name: "multiple expressions",
+			role: "pod",
+			expressions: []meta_v1.LabelSelectorRequirement{
+				{Key: "app", Operator: meta_v1.LabelSelectorOpExists},
+				{Key: "tier", Operator: meta_v1.LabelSelectorOpNotIn, Values: []string{"cache"}},
+			},
+			expect: util.Untab(`
+				- source_labels: [__meta_kubernetes_pod_labelpresent_app]
+					regex: "true"
+					action: keep
+				- source_labels: [__meta_kubernetes_pod_label_tier]
+					regex: cache
+					action: drop
+			`),
+		},
+		{
+			name: "unsupported operator",
+			role: "pod",
+			expressions: []meta_v1.LabelSelectorRequirement{
+				{Key: "app", Operator: "Gt", Values: []string{"1"}},
+			},
+			expectErr: "unsupported label selector operator Gt",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			vm, err := createVM(testStore())
+			require.NoError(t, err)
+
+			bb, err := jsonnetMarshal(meta_v1.LabelSelector{MatchExpressions: tc.expressions})
+			require.NoError(t, err)
+			vm.TLACode("selector", string(bb))
+			vm.TLAVar("role", tc.role)
+
+			actual, err := vm.EvaluateAnonymousSnippet("match_expressions.libsonnet", `
+				local marshal = import './ext/marshal.libsonnet';
+				local k8s = import './utils/k8s.libsonnet';
+				function(role, selector) marshal.YAML(k8s.matchExpressionRelabels(role, selector.MatchExpressions))
+			`)
+			if tc.expectErr != "" {
+				require.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			if !assert.YAMLEq(t, tc.expect, actual) {
+				fmt.Fprintln(os.Stderr, actual)
+			}
+		})
+	}
+}
+
+func TestEnforcedNamespaceLabel(t *testing.T) {
+	monitor := prom_v1.PodMonitor{
+		ObjectMeta: meta_v1.ObjectMeta{Namespace: "app", Name: "podmonitor"},
+	}
+
+	tt := []struct {
+		name     string
+		excluded []gragent.ObjectReference
+		expect   string
+	}{
+		{
+			name:   "no exclusions",
+			expect: "namespace",
+		},
+		{
+			name:     "excluded by name",
+			excluded: []gragent.ObjectReference{{Resource: "podmonitors", Namespace: "app", Name: "podmonitor"}},
+			expect:   "",
+		},
+		{
+			name:     "excluded by namespace",
+			excluded: []gragent.ObjectReference{{Resource: "podmonitors", Namespace: "app"}},
+			expect:   "",
+		},
+		{
+			name: "other resources",
+			excluded: []gragent.ObjectReference{
+				{Resource: "servicemonitors", Namespace: "app"},
+				{Resource: "podmonitors", Namespace: "other"},
+				{Resource: "podmonitors", Namespace: "app", Name: "other"},
+			},
+			expect: "namespace",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			vm, err := createVM(testStore())
+			require.NoError(t, err)
+
+			bb, err := jsonnetMarshal(monitor)
+			require.NoError(t, err)
+			vm.TLACode("monitor", string(bb))
+			bb, err = jsonnetMarshal(gragent.MetricsSubsystemSpec{ExcludedFromEnforcement: tc.excluded})
+			require.NoError(t, err)
+			vm.TLACode("metrics", string(bb))
+
+			actual, err := vm.EvaluateAnonymousSnippet("enforced_namespace_label.libsonnet", `
+				local k8s = import './utils/k8s.libsonnet';
+				function(monitor, metrics) k8s.enforcedNamespaceLabel('namespace', metrics.ExcludedFromEnforcement, 'podmonitors', monitor)
+			`)
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, strings.TrimSpace(actual))
+		})
+	}
+}
+
 func runSnippet(vm *jsonnet.VM, filename string, args ...string) (string, error) {
 	boundArgs := make([]string, len(args))
 	for i := range args {
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redactedSecret replaces secrets in the scrape configs returned by
+// ScrapeConfigs, the same way Prometheus hides secrets when printing its
+// config.
+const redactedSecret = "<secret>"
+
+// secretFields are the fields of a scrape config which may hold secrets
+// inlined by the templates.
+var secretFields = map[string]struct{}{
+	"bearer_token":  {},
+	"client_secret": {},
+	"credentials":   {},
+	"password":      {},
+}
+
+// InstanceScrapeConfigs holds the scrape_configs generated for a
+// MetricsInstance.
+type InstanceScrapeConfigs struct {
+	// Instance is the namespace/name of the MetricsInstance.
+	Instance      string      `yaml:"instance"`
+	ScrapeConfigs []yaml.Node `yaml:"scrape_configs"`
+}
+
+// ScrapeConfigs returns the scrape_configs of every MetricsInstance from a
+// metrics config built by BuildConfig. Secrets inlined into the scrape
+// configs are redacted.
+func ScrapeConfigs(rawConfig string) ([]InstanceScrapeConfigs, error) {
+	var cfg struct {
+		Metrics struct {
+			Configs []struct {
+				Name          string      `yaml:"name"`
+				ScrapeConfigs []yaml.Node `yaml:"scrape_configs"`
+			} `yaml:"configs"`
+		} `yaml:"metrics"`
+	}
+	if err := yaml.Unmarshal([]byte(rawConfig), &cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse metrics config: %w", err)
+	}
+
+	res := make([]InstanceScrapeConfigs, 0, len(cfg.Metrics.Configs))
+	for _, c := range cfg.Metrics.Configs {
+		for i := range c.ScrapeConfigs {
+			redactSecrets(&c.ScrapeConfigs[i])
+		}
+		res = append(res, InstanceScrapeConfigs{
+			Instance:      c.Name,
+			ScrapeConfigs: c.ScrapeConfigs,
+		})
+	}
+	return res, nil
+}
+
+// redactSecrets replaces the values of secretFields found anywhere in n.
+func redactSecrets(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if _, secret := secretFields[key.Value]; secret && value.Kind == yaml.ScalarNode {
+				value.SetString(redactedSecret)
+				continue
+			}
+			redactSecrets(value)
+		}
+		return
+	}
+	for _, c := range n.Content {
+		redactSecrets(c)
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/grafana/agent/pkg/util"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestScrapeConfigs(t *testing.T) {
+	rawConfig := util.Untab(`
+		server: {}
+		metrics:
+			wal_directory: /var/lib/grafana-agent/data
+			configs:
+			- name: operator/primary
+				scrape_configs:
+				- job_name: serviceMonitor/default/sm/0
+					basic_auth:
+						username: user
+						password: hunter2
+					oauth2:
+						client_id: id
+						client_secret: secret
+				- job_name: podMonitor/default/pm/0
+					authorization:
+						type: Bearer
+						credentials: token
+			- name: operator/empty
+	`)
+
+	configs, err := ScrapeConfigs(rawConfig)
+	require.NoError(t, err)
+
+	bb, err := yaml.Marshal(configs)
+	require.NoError(t, err)
+	require.YAMLEq(t, util.Untab(`
+		- instance: operator/primary
+			scrape_configs:
+			- job_name: serviceMonitor/default/sm/0
+				basic_auth:
+					username: user
+					password: <secret>
+				oauth2:
+					client_id: id
+					client_secret: <secret>
+			- job_name: podMonitor/default/pm/0
+				authorization:
+					type: Bearer
+					credentials: <secret>
+		- instance: operator/empty
+			scrape_configs: []
+	`), string(bb))
+}
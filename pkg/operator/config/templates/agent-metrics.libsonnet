@@ -54,6 +54,7 @@ function(ctx) marshal.YAML(optionals.trim({
         overrideHonorTimestamps=metrics.OverrideHonorTimestamps,
         ignoreNamespaceSelectors=metrics.IgnoreNamespaceSelectors,
         enforcedNamespaceLabel=metrics.EnforcedNamespaceLabel,
+        excludedFromEnforcement=metrics.ExcludedFromEnforcement,
         enforcedSampleLimit=metrics.EnforcedSampleLimit,
         enforcedTargetLimit=metrics.EnforcedTargetLimit,
         shards=calculateShards(metrics.Shards),
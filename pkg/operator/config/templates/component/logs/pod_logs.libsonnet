@@ -63,28 +63,7 @@ function(
 
     // Set-based label matching. we have to map the valid relations
     // `In`, `NotIn`, `Exists`, and `DoesNotExist` into relabling rules.
-    std.map(
-      function(exp) (
-        if exp.Operator == 'In' then {
-          source_labels: ['__meta_kubernetes_pod_label_' + k8s.sanitize(exp.Key)],
-          regex: std.join('|', exp.Values),
-          action: 'keep',
-        } else if exp.Operator == 'NotIn' then {
-          source_labels: ['__meta_kubernetes_pod_label_' + k8s.sanitize(exp.Key)],
-          regex: std.join('|', exp.Values),
-          action: 'drop',
-        } else if exp.Operator == 'Exists' then {
-          source_labels: ['__meta_kubernetes_pod_labelpresent_' + k8s.sanitize(exp.Key)],
-          regex: 'true',
-          action: 'keep',
-        } else if exp.Operator == 'DoesNotExist' then {
-          source_labels: ['__meta_kubernetes_pod_labelpresent_' + k8s.sanitize(exp.Key)],
-          regex: 'true',
-          action: 'drop',
-        }
-      ),
-      k8s.array(podLogs.Spec.Selector.MatchExpressions),
-    ) +
+    k8s.matchExpressionRelabels('pod', podLogs.Spec.Selector.MatchExpressions) +
 
     // Relabel namespace, pod, and service metalabels into proper labels.
     [{
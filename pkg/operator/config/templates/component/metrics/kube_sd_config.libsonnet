@@ -10,11 +10,14 @@ local new_tls_config = import './tls_config.libsonnet';
 // @param {string[]} namespaces - Namespaces to discover resources in
 // @param {APIServerConfig} apiServer - config to use for k8s discovery.
 // @param {string} role - role of k8s resources to discover.
+// @param {*AttachMetadata} attachMetadata - metadata to attach to discovered
+//   targets. Only used for the pod role.
 function(
   namespace,
   namespaces,
   apiServer,
   role,
+  attachMetadata=null,
 ) {
   role: role,
   namespaces: if std.length(k8s.array(namespaces)) > 0 then {
@@ -37,6 +40,10 @@ function(
     credentials_file: bearerTokenFile,
   },
 
+  attach_metadata: if role == 'pod' && attachMetadata != null && attachMetadata.Node then {
+    node: true,
+  },
+
   tls_config: if apiServer != null && apiServer.TLSConfig != null then
     new_tls_config(namespace, apiServer.TLSConfig),
 }
@@ -54,6 +54,7 @@ function(
       ),
       apiServer=apiServer,
       role='pod',
+      attachMetadata=monitor.Spec.AttachMetadata,
     ),
   ],
 
@@ -63,6 +64,7 @@ function(
   proxy_url: optionals.string(endpoint.ProxyURL),
   params: optionals.object(endpoint.Params),
   scheme: optionals.string(endpoint.Scheme),
+  follow_redirects: optionals.bool(endpoint.FollowRedirects, true),
   enable_http2: optionals.bool(endpoint.EnableHttp2,true),
 
   // NOTE(rfratto): unlike ServiceMonitor, pod monitors explicitly use
@@ -78,9 +80,25 @@ function(
     password: secrets.valueForSecret(meta.Namespace, endpoint.BasicAuth.Password),
   },
 
+  oauth2: if endpoint.OAuth2 != null then {
+    client_id: secrets.valueForSelector(meta.Namespace, endpoint.OAuth2.ClientID),
+    client_secret: secrets.valueForSecret(meta.Namespace, endpoint.OAuth2.ClientSecret),
+    token_url: endpoint.OAuth2.TokenURL,
+    scopes: optionals.array(endpoint.OAuth2.Scopes),
+    endpoint_params: optionals.object(endpoint.OAuth2.EndpointParams),
+  },
+
+  authorization: if endpoint.Authorization != null then {
+    type: if endpoint.Authorization.Type != '' then endpoint.Authorization.Type else 'Bearer',
+    credentials: secrets.valueForSecret(meta.Namespace, endpoint.Authorization.Credentials),
+  },
+
   relabel_configs: (
     [{ source_labels: ['job'], target_label: '__tmp_prometheus_job_name' }] +
 
+    // Drop pods which aren't running, unless filterRunning is disabled.
+    k8s.runningFilterRelabels(endpoint.FilterRunning) +
+
     // Match on service labels.
     std.map(
       function(k) {
@@ -98,28 +116,7 @@ function(
 
     // Set-based label matching. we have to map the valid relations
     // `In`, `NotIn`, `Exists`, and `DoesNotExist` into relabling rules.
-    std.map(
-      function(exp) (
-        if exp.Operator == 'In' then {
-          source_labels: ['__meta_kubernetes_pod_label_' + k8s.sanitize(exp.Key)],
-          regex: std.join('|', exp.Values),
-          action: 'keep',
-        } else if exp.Operator == 'NotIn' then {
-          source_labels: ['__meta_kubernetes_pod_label_' + k8s.sanitize(exp.Key)],
-          regex: std.join('|', exp.Values),
-          action: 'drop',
-        } else if exp.Operator == 'Exists' then {
-          source_labels: ['__meta_kubernetes_pod_labelpresent_' + k8s.sanitize(exp.Key)],
-          regex: 'true',
-          action: 'keep',
-        } else if exp.Operator == 'DoesNotExist' then {
-          source_labels: ['__meta_kubernetes_pod_labelpresent_' + k8s.sanitize(exp.Key)],
-          regex: 'true',
-          action: 'drop',
-        }
-      ),
-      k8s.array(monitor.Spec.Selector.MatchExpressions),
-    ) +
+    k8s.matchExpressionRelabels('pod', monitor.Spec.Selector.MatchExpressions) +
 
     // First targets based on correct port for the endpoint. If ep.Port,
     // ep.TargetPort.StrVal, or ep.TargetPort.IntVal aren't set, then
@@ -209,20 +206,10 @@ function(
         target_label: enforcedNamespaceLabel,
         replacement: monitor.ObjectMeta.Namespace,
       },
+    ]) +
 
-      // Shard rules
-      {
-        source_labels: ['__address__'],
-        target_label: '__tmp_hash',
-        modulus: shards,
-        action: 'hashmod',
-      },
-      {
-        source_labels: ['__tmp_hash'],
-        regex: '$(SHARD)',
-        action: 'keep',
-      },
-    ])
+    // Shard rules
+    k8s.shardRelabels('__address__', shards)
   ),
 
   metric_relabel_configs: if endpoint.MetricRelabelConfigs != null then optionals.array(
@@ -239,4 +226,7 @@ function(
   target_limit:
     if monitor.Spec.TargetLimit > 0 || enforcedTargetLimit != null
     then k8s.limit(monitor.Spec.TargetLimit, enforcedTargetLimit),
+  label_limit: optionals.number(monitor.Spec.LabelLimit),
+  label_name_length_limit: optionals.number(monitor.Spec.LabelNameLengthLimit),
+  label_value_length_limit: optionals.number(monitor.Spec.LabelValueLengthLimit),
 }
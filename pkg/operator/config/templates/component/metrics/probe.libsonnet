@@ -137,28 +137,7 @@ function(
 
         // Set-based label matching. we have to map the valid relations
         // `In`, `NotIn`, `Exists`, and `DoesNotExist` into relabling rules.
-        std.map(
-          function(exp) (
-            if exp.Operator == 'In' then {
-              source_labels: ['__meta_kubernetes_ingress_label_' + k8s.sanitize(exp.Key)],
-              regex: std.join('|', exp.Values),
-              action: 'keep',
-            } else if exp.Operator == 'NotIn' then {
-              source_labels: ['__meta_kubernetes_ingress_label_' + k8s.sanitize(exp.Key)],
-              regex: std.join('|', exp.Values),
-              action: 'drop',
-            } else if exp.Operator == 'Exists' then {
-              source_labels: ['__meta_kubernetes_ingress_labelpresent_' + k8s.sanitize(exp.Key)],
-              regex: 'true',
-              action: 'keep',
-            } else if exp.Operator == 'DoesNotExist' then {
-              source_labels: ['__meta_kubernetes_ingress_labelpresent_' + k8s.sanitize(exp.Key)],
-              regex: 'true',
-              action: 'drop',
-            }
-          ),
-          k8s.array(probe.Spec.Targets.Ingress.Selector.MatchExpressions),
-        ) +
+        k8s.matchExpressionRelabels('ingress', probe.Spec.Targets.Ingress.Selector.MatchExpressions) +
 
         // Relablings for ingress SD
         [
@@ -211,6 +190,10 @@ function(
         target_label: enforcedNamespaceLabel,
         replacement: probe.ObjectMeta.Namespace,
       },
-    ])
+    ]) +
+
+    // Shard rules. __address__ is the address of the prober for all targets,
+    // so targets are sharded by the probed URL instead.
+    k8s.shardRelabels('__param_target', shards)
   ),
 }
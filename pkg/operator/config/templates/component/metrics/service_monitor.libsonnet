@@ -63,6 +63,7 @@ function(
   proxy_url: optionals.string(endpoint.ProxyURL),
   params: optionals.object(endpoint.Params),
   scheme: optionals.string(endpoint.Scheme),
+  follow_redirects: optionals.bool(endpoint.FollowRedirects, true),
   enable_http2: optionals.bool(endpoint.EnableHttp2,true),
 
   tls_config:
@@ -97,28 +98,7 @@ function(
 
     // Set-based label matching. we have to map the valid relations
     // `In`, `NotIn`, `Exists`, and `DoesNotExist` into relabling rules.
-    std.map(
-      function(exp) (
-        if exp.Operator == 'In' then {
-          source_labels: ['__meta_kubernetes_service_label_' + k8s.sanitize(exp.Key)],
-          regex: std.join('|', exp.Values),
-          action: 'keep',
-        } else if exp.Operator == 'NotIn' then {
-          source_labels: ['__meta_kubernetes_service_label_' + k8s.sanitize(exp.Key)],
-          regex: std.join('|', exp.Values),
-          action: 'drop',
-        } else if exp.Operator == 'Exists' then {
-          source_labels: ['__meta_kubernetes_service_labelpresent_' + k8s.sanitize(exp.Key)],
-          regex: 'true',
-          action: 'keep',
-        } else if exp.Operator == 'DoesNotExist' then {
-          source_labels: ['__meta_kubernetes_service_labelpresent_' + k8s.sanitize(exp.Key)],
-          regex: 'true',
-          action: 'drop',
-        }
-      ),
-      k8s.array(monitor.Spec.Selector.MatchExpressions),
-    ) +
+    k8s.matchExpressionRelabels('service', monitor.Spec.Selector.MatchExpressions) +
 
     // First targets based on correct port for the endpoint. If ep.Port,
     // ep.TargetPort.StrVal, or ep.TargetPort.IntVal aren't set, then
@@ -176,6 +156,9 @@ function(
       target_label: 'container',
     }] +
 
+    // Drop pods which aren't running, unless filterRunning is disabled.
+    k8s.runningFilterRelabels(endpoint.FilterRunning) +
+
     // Relabel targetLabels from the service onto the target.
     std.map(
       function(l) {
@@ -238,20 +221,10 @@ function(
         target_label: enforcedNamespaceLabel,
         replacement: monitor.ObjectMeta.Namespace,
       },
+    ]) +
 
-      // Shard rules
-      {
-        source_labels: ['__address__'],
-        target_label: '__tmp_hash',
-        modulus: shards,
-        action: 'hashmod',
-      },
-      {
-        source_labels: ['__tmp_hash'],
-        regex: '$(SHARD)',
-        action: 'keep',
-      },
-    ])
+    // Shard rules
+    k8s.shardRelabels('__address__', shards)
   ),
 
   metric_relabel_configs: if endpoint.MetricRelabelConfigs != null then optionals.array(
@@ -53,7 +53,7 @@ function(
         podLogs=podLogs,
         apiServer=apiServer,
         ignoreNamespaceSelectors=ignoreNamespaceSelectors,
-        enforcedNamespaceLabel=enforcedNamespaceLabel,
+        enforcedNamespaceLabel=k8s.enforcedNamespaceLabel(enforcedNamespaceLabel, global.ExcludedFromEnforcement, 'podlogs', podLogs),
       ),
       k8s.array(instance.PodLogs)
     ) +
@@ -17,6 +17,7 @@ local new_service_monitor = import 'component/metrics/service_monitor.libsonnet'
 // @param {boolean} overrideHonorTimestamps
 // @param {boolean} ignoreNamespaceSelectors
 // @param {string} enforcedNamespaceLabel
+// @param {ObjectReference[]} excludedFromEnforcement
 // @param {boolean} enforcedSampleLimit
 // @param {boolean} enforcedTargetLimit
 // @param {number} shards
@@ -28,11 +29,13 @@ function(
   overrideHonorTimestamps,
   ignoreNamespaceSelectors,
   enforcedNamespaceLabel,
+  excludedFromEnforcement,
   enforcedSampleLimit,
   enforcedTargetLimit,
   shards,
 ) {
   local namespace = instance.Instance.ObjectMeta.Namespace,
+  local validEndpointsOnly(configs) = std.filter(function(c) c != null, configs),
   local spec = instance.Instance.Spec,
 
   name: '%s/%s' % [namespace, instance.Instance.ObjectMeta.Name],
@@ -58,10 +61,11 @@ function(
   scrape_configs: optionals.array(
     // Iterate over ServiceMonitors. ServiceMonitors have a set of Endpoints,
     // each of which should be its own scrape_configs, so we have to do a nested
-    // iteration here.
+    // iteration here. Invalid endpoints are skipped, keeping the index of the
+    // other endpoints.
     std.flatMap(
-      function(sMon) std.mapWithIndex(
-        function(i, ep) new_service_monitor(
+      function(sMon) validEndpointsOnly(std.mapWithIndex(
+        function(i, ep) if k8s.validEndpoint(ep) then new_service_monitor(
           agentNamespace=agentNamespace,
           monitor=sMon,
           endpoint=ep,
@@ -70,22 +74,23 @@ function(
           overrideHonorLabels=overrideHonorLabels,
           overrideHonorTimestamps=overrideHonorTimestamps,
           ignoreNamespaceSelectors=ignoreNamespaceSelectors,
-          enforcedNamespaceLabel=enforcedNamespaceLabel,
+          enforcedNamespaceLabel=k8s.enforcedNamespaceLabel(enforcedNamespaceLabel, excludedFromEnforcement, 'servicemonitors', sMon),
           enforcedSampleLimit=enforcedSampleLimit,
           enforcedTargetLimit=enforcedTargetLimit,
           shards=shards,
         ),
         k8s.array(sMon.Spec.Endpoints),
-      ),
+      )),
       k8s.array(instance.ServiceMonitors),
     ) +
 
     // Iterate over PodMonitors. PodMonitors have a set of PodMetricsEndpoints,
     // each of which should be its own scrape_configs, so we have to do a
-    // nested iteration here.
+    // nested iteration here. Invalid endpoints are skipped like for
+    // ServiceMonitors.
     std.flatMap(
-      function(pMon) std.mapWithIndex(
-        function(i, ep) new_pod_monitor(
+      function(pMon) validEndpointsOnly(std.mapWithIndex(
+        function(i, ep) if k8s.validEndpoint(ep) then new_pod_monitor(
           agentNamespace=agentNamespace,
           monitor=pMon,
           endpoint=ep,
@@ -94,13 +99,13 @@ function(
           overrideHonorLabels=overrideHonorLabels,
           overrideHonorTimestamps=overrideHonorTimestamps,
           ignoreNamespaceSelectors=ignoreNamespaceSelectors,
-          enforcedNamespaceLabel=enforcedNamespaceLabel,
+          enforcedNamespaceLabel=k8s.enforcedNamespaceLabel(enforcedNamespaceLabel, excludedFromEnforcement, 'podmonitors', pMon),
           enforcedSampleLimit=enforcedSampleLimit,
           enforcedTargetLimit=enforcedTargetLimit,
           shards=shards,
         ),
         k8s.array(pMon.Spec.PodMetricsEndpoints),
-      ),
+      )),
       k8s.array(instance.PodMonitors),
     ) +
 
@@ -112,7 +117,7 @@ function(
         apiServer=apiServer,
         overrideHonorTimestamps=overrideHonorTimestamps,
         ignoreNamespaceSelectors=ignoreNamespaceSelectors,
-        enforcedNamespaceLabel=enforcedNamespaceLabel,
+        enforcedNamespaceLabel=k8s.enforcedNamespaceLabel(enforcedNamespaceLabel, excludedFromEnforcement, 'probes', probe),
         enforcedSampleLimit=enforcedSampleLimit,
         enforcedTargetLimit=enforcedTargetLimit,
         shards=shards,
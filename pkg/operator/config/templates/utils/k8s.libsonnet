@@ -37,6 +37,85 @@
   // sanitize sanitizes text for label safety.
   sanitize(text):: std.native('sanitize')(text),
 
+  // regexQuote escapes all regular expression metacharacters in text.
+  regexQuote(text):: std.native('regexQuote')(text),
+
+  // matchExpressionRelabels converts set-based label selector requirements
+  // into relabel rules for targets of the given kubernetes_sd_config role.
+  // The relations `In`, `NotIn`, `Exists`, and `DoesNotExist` are supported.
+  matchExpressionRelabels(role, expressions):: std.map(
+    function(exp) (
+      local label = '__meta_kubernetes_%s_label_%s' % [role, $.sanitize(exp.Key)];
+      local present = '__meta_kubernetes_%s_labelpresent_%s' % [role, $.sanitize(exp.Key)];
+      local values = std.join('|', std.map($.regexQuote, $.array(exp.Values)));
+
+      if exp.Operator == 'In' then {
+        source_labels: [label],
+        regex: values,
+        action: 'keep',
+      } else if exp.Operator == 'NotIn' then {
+        source_labels: [label],
+        regex: values,
+        action: 'drop',
+      } else if exp.Operator == 'Exists' then {
+        source_labels: [present],
+        regex: 'true',
+        action: 'keep',
+      } else if exp.Operator == 'DoesNotExist' then {
+        source_labels: [present],
+        regex: 'true',
+        action: 'drop',
+      } else error 'unsupported label selector operator %s' % exp.Operator
+    ),
+    $.array(expressions),
+  ),
+
+  // shardRelabels returns relabel rules which only keep the targets assigned
+  // to the shard of the current agent pod. Targets are assigned by hashing
+  // sourceLabel, so every target is scraped by exactly one of the shards.
+  shardRelabels(sourceLabel, shards):: [
+    {
+      source_labels: [sourceLabel],
+      target_label: '__tmp_hash',
+      modulus: shards,
+      action: 'hashmod',
+    },
+    {
+      source_labels: ['__tmp_hash'],
+      regex: '$(SHARD)',
+      action: 'keep',
+    },
+  ],
+
+  // enforcedNamespaceLabel returns the enforced namespace label for obj, a
+  // resource of the given type such as servicemonitors. An empty string is
+  // returned if obj matches one of the ObjectReferences in excluded, so that
+  // the namespace label isn't enforced for it.
+  enforcedNamespaceLabel(label, excluded, resource, obj)::
+    local meta = obj.ObjectMeta;
+    local matches = std.filter(
+      function(ref) ref.Resource == resource && ref.Namespace == meta.Namespace && (ref.Name == '' || ref.Name == meta.Name),
+      $.array(excluded),
+    );
+    if std.length(matches) > 0 then '' else label,
+
+  // validEndpoint returns whether the relabelings and metricRelabelings of a
+  // ServiceMonitor or PodMonitor endpoint are valid. Invalid endpoints are
+  // left out of the config so that they don't break the other scrape
+  // configs.
+  validEndpoint(endpoint)::
+    std.native('validRelabelConfigs')($.array(endpoint.RelabelConfigs)) &&
+    std.native('validRelabelConfigs')($.array(endpoint.MetricRelabelConfigs)),
+
+  // runningFilterRelabels returns relabel rules which drop pods that aren't
+  // running (Failed or Succeeded) unless filterRunning is explicitly false.
+  runningFilterRelabels(filterRunning)::
+    if filterRunning == null || filterRunning then [{
+      source_labels: ['__meta_kubernetes_pod_phase'],
+      regex: '(Failed|Succeeded)',
+      action: 'drop',
+    }] else [],
+
   // intOrString returns the string value of *intstr.IntOrString.
   intOrString(obj)::
     if obj == null then ''
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+
+	jsonnet "github.com/google/go-jsonnet"
+	gragent "github.com/grafana/agent/pkg/operator/apis/monitoring/v1alpha1"
+	prom_v1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/prometheus/prometheus/model/relabel"
+)
+
+// InvalidEndpoint is an endpoint of a ServiceMonitor or PodMonitor which is
+// left out of the generated config because it's invalid. The other endpoints
+// of the monitor are still scraped.
+type InvalidEndpoint struct {
+	// Kind of the monitor: ServiceMonitor or PodMonitor.
+	Kind      string
+	Namespace string
+	Name      string
+	// Index of the endpoint in the monitor.
+	Index int
+	Err   error
+}
+
+// InvalidEndpoints returns the endpoints of the ServiceMonitors and
+// PodMonitors in d which are left out of the generated metrics config.
+func InvalidEndpoints(d *gragent.Deployment) []InvalidEndpoint {
+	var res []InvalidEndpoint
+	for _, m := range d.Metrics {
+		for _, sMon := range m.ServiceMonitors {
+			for i, ep := range sMon.Spec.Endpoints {
+				if err := validateEndpointRelabelings(ep.RelabelConfigs, ep.MetricRelabelConfigs); err != nil {
+					res = append(res, InvalidEndpoint{
+						Kind:      "ServiceMonitor",
+						Namespace: sMon.Namespace,
+						Name:      sMon.Name,
+						Index:     i,
+						Err:       err,
+					})
+				}
+			}
+		}
+		for _, pMon := range m.PodMonitors {
+			for i, ep := range pMon.Spec.PodMetricsEndpoints {
+				if err := validateEndpointRelabelings(ep.RelabelConfigs, ep.MetricRelabelConfigs); err != nil {
+					res = append(res, InvalidEndpoint{
+						Kind:      "PodMonitor",
+						Namespace: pMon.Namespace,
+						Name:      pMon.Name,
+						Index:     i,
+						Err:       err,
+					})
+				}
+			}
+		}
+	}
+	return res
+}
+
+// validateEndpointRelabelings checks the relabelings and metricRelabelings
+// of an endpoint.
+func validateEndpointRelabelings(relabelings, metricRelabelings []*prom_v1.RelabelConfig) error {
+	for i, c := range relabelings {
+		if err := validateRelabelRegex(c.Regex); err != nil {
+			return fmt.Errorf("relabelings[%d]: %w", i, err)
+		}
+	}
+	for i, c := range metricRelabelings {
+		if err := validateRelabelRegex(c.Regex); err != nil {
+			return fmt.Errorf("metricRelabelings[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateRelabelRegex checks that re compiles the way Prometheus compiles
+// relabel regexes. An empty regex uses the default and is always valid.
+func validateRelabelRegex(re string) error {
+	if re == "" {
+		return nil
+	}
+	if _, err := relabel.NewRegexp(re); err != nil {
+		return fmt.Errorf("invalid regex %q: %w", re, err)
+	}
+	return nil
+}
+
+// validRelabelConfigs implements the validRelabelConfigs native function,
+// which returns whether all of the regexes in a list of RelabelConfigs are
+// valid.
+func validRelabelConfigs(i []interface{}) (interface{}, error) {
+	configs, ok := i[0].([]interface{})
+	if !ok && i[0] != nil {
+		return nil, jsonnet.RuntimeError{Msg: "configs must be an array"}
+	}
+	for _, c := range configs {
+		m, ok := c.(map[string]interface{})
+		if !ok {
+			return nil, jsonnet.RuntimeError{Msg: "relabel config must be an object"}
+		}
+		re, _ := m["Regex"].(string)
+		if validateRelabelRegex(re) != nil {
+			return false, nil
+		}
+	}
+	return true, nil
+}
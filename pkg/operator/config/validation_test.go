@@ -0,0 +1,81 @@
+package config
+
+import (
+	"testing"
+
+	gragent "github.com/grafana/agent/pkg/operator/apis/monitoring/v1alpha1"
+	"github.com/grafana/agent/pkg/operator/assets"
+	prom_v1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/stretchr/testify/require"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s_yaml "sigs.k8s.io/yaml"
+)
+
+func TestInvalidEndpoints(t *testing.T) {
+	d := gragent.Deployment{
+		Agent: &gragent.GrafanaAgent{
+			ObjectMeta: meta_v1.ObjectMeta{Namespace: "operator", Name: "agent"},
+		},
+		Metrics: []gragent.MetricsDeployment{{
+			Instance: &gragent.MetricsInstance{
+				ObjectMeta: meta_v1.ObjectMeta{Namespace: "operator", Name: "primary"},
+			},
+			ServiceMonitors: []*prom_v1.ServiceMonitor{{
+				ObjectMeta: meta_v1.ObjectMeta{Namespace: "default", Name: "sm"},
+				Spec: prom_v1.ServiceMonitorSpec{
+					Endpoints: []prom_v1.Endpoint{
+						{Port: "bad", MetricRelabelConfigs: []*prom_v1.RelabelConfig{{Regex: "("}}},
+						{Port: "good", MetricRelabelConfigs: []*prom_v1.RelabelConfig{{Regex: "go_.*"}}},
+					},
+				},
+			}},
+			PodMonitors: []*prom_v1.PodMonitor{{
+				ObjectMeta: meta_v1.ObjectMeta{Namespace: "default", Name: "pm"},
+				Spec: prom_v1.PodMonitorSpec{
+					PodMetricsEndpoints: []prom_v1.PodMetricsEndpoint{
+						{Port: "good"},
+						{Port: "bad", RelabelConfigs: []*prom_v1.RelabelConfig{{}, {Regex: "[a-"}}},
+					},
+				},
+			}},
+		}},
+		Secrets: make(assets.SecretStore),
+	}
+
+	invalid := InvalidEndpoints(&d)
+	require.Len(t, invalid, 2)
+	require.Equal(t, "ServiceMonitor", invalid[0].Kind)
+	require.Equal(t, "sm", invalid[0].Name)
+	require.Equal(t, 0, invalid[0].Index)
+	require.Contains(t, invalid[0].Err.Error(), "metricRelabelings[0]: invalid regex")
+	require.Equal(t, "PodMonitor", invalid[1].Kind)
+	require.Equal(t, "pm", invalid[1].Name)
+	require.Equal(t, 1, invalid[1].Index)
+	require.Contains(t, invalid[1].Err.Error(), "relabelings[1]: invalid regex")
+
+	// Only the invalid endpoints are skipped, and the other endpoints keep
+	// their index in the job name.
+	result, err := BuildConfig(&d, MetricsType)
+	require.NoError(t, err)
+
+	var cfg struct {
+		Metrics struct {
+			Configs []struct {
+				ScrapeConfigs []struct {
+					JobName string `json:"job_name"`
+				} `json:"scrape_configs"`
+			} `json:"configs"`
+		} `json:"metrics"`
+	}
+	require.NoError(t, k8s_yaml.Unmarshal([]byte(result), &cfg))
+	require.Len(t, cfg.Metrics.Configs, 1)
+
+	var jobs []string
+	for _, sc := range cfg.Metrics.Configs[0].ScrapeConfigs {
+		jobs = append(jobs, sc.JobName)
+	}
+	require.Equal(t, []string{
+		"serviceMonitor/default/sm/1",
+		"podMonitor/default/pm/0",
+	}, jobs)
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"path"
 	"strings"
 	"sync"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/flagext"
 	"github.com/weaveworks/common/logging"
 	"k8s.io/apimachinery/pkg/runtime"
 	controller "sigs.k8s.io/controller-runtime"
@@ -41,6 +43,15 @@ type Config struct {
 	AgentSelector       string
 	KubelsetServiceName string
 
+	// ExcludedNamespaces lists namespaces whose ServiceMonitors, PodMonitors,
+	// Probes, and PodLogs are never discovered, for example because they're
+	// managed by another Prometheus.
+	ExcludedNamespaces flagext.StringSliceCSV
+	// ExcludedResourceNames lists glob patterns. ServiceMonitors, PodMonitors,
+	// Probes, and PodLogs with a name matching any of them are never
+	// discovered.
+	ExcludedResourceNames flagext.StringSliceCSV
+
 	// RestConfig used to connect to cluster. One will be generated based on the
 	// environment if not set.
 	RestConfig *rest.Config
@@ -48,7 +59,7 @@ type Config struct {
 	// TODO(rfratto): extra settings from Prometheus Operator:
 	//
 	// 1. Reloader container image/requests/limits
-	// 2. Namespaces allow/denylist.
+	// 2. Namespaces allowlist.
 	// 3. Namespaces for Prometheus resources.
 }
 
@@ -79,6 +90,9 @@ func (c *Config) registerFlags(f *flag.FlagSet) error {
 	f.StringVar(&c.Controller.MetricsBindAddress, "metrics-listen-address", ":8080", "Address to expose Operator metrics on")
 	f.StringVar(&c.Controller.HealthProbeBindAddress, "health-listen-address", "", "Address to expose Operator health probes on")
 
+	f.Var(&c.ExcludedNamespaces, "excluded-namespaces", "Comma-separated list of namespaces to ignore ServiceMonitors, PodMonitors, Probes, and PodLogs in.")
+	f.Var(&c.ExcludedResourceNames, "excluded-resource-names", "Comma-separated list of glob patterns. ServiceMonitors, PodMonitors, Probes, and PodLogs with a matching name are ignored.")
+
 	f.StringVar(&c.KubelsetServiceName, "kubelet-service", "", "Service and Endpoints objects to write kubelets into. Allows for monitoring Kubelet and cAdvisor metrics using a ServiceMonitor. Must be in format \"namespace/name\". If empty, nothing will be created.")
 
 	// Custom initial values for the endpoint names.
@@ -120,6 +134,12 @@ func New(l log.Logger, c *Config) (*Operator, error) {
 		lazyKubeletReconciler, lazyAgentReconciler lazyReconciler
 	)
 
+	for _, pattern := range c.ExcludedResourceNames {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid excluded-resource-names pattern %q: %w", pattern, err)
+		}
+	}
+
 	restConfig := c.RestConfig
 	if restConfig == nil {
 		restConfig = controller.GetConfigOrDie()
@@ -136,6 +156,11 @@ func New(l log.Logger, c *Config) (*Operator, error) {
 		level.Warn(l).Log("msg", "failed to set up 'running' healthz check", "err", err)
 	}
 
+	scrapeConfigs := newGeneratedScrapeConfigs()
+	if err := manager.AddMetricsExtraHandler(scrapeConfigsPath, scrapeConfigs); err != nil {
+		level.Warn(l).Log("msg", "failed to set up scrape configs debug handler", "err", err)
+	}
+
 	var (
 		agentPredicates []predicate.Predicate
 
@@ -208,6 +233,8 @@ func New(l log.Logger, c *Config) (*Operator, error) {
 		scheme:   manager.GetScheme(),
 		notifier: notifier,
 		config:   c,
+
+		scrapeConfigs: scrapeConfigs,
 	})
 
 	return &Operator{
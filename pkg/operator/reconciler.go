@@ -25,6 +25,9 @@ type reconciler struct {
 	config *Config
 
 	notifier *hierarchy.Notifier
+
+	// scrapeConfigs holds the scrape configs generated for every GrafanaAgent.
+	scrapeConfigs *generatedScrapeConfigs
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, req controller.Request) (controller.Result, error) {
@@ -38,6 +41,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req controller.Request) (con
 	var agent gragent.GrafanaAgent
 	if err := r.Get(ctx, req.NamespacedName, &agent); k8s_errors.IsNotFound(err) {
 		level.Debug(l).Log("msg", "detected deleted agent")
+		r.scrapeConfigs.Delete(req.NamespacedName)
 		return controller.Result{}, nil
 	} else if err != nil {
 		level.Error(l).Log("msg", "unable to get grafana-agent", "err", err)
@@ -48,7 +52,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req controller.Request) (con
 		return controller.Result{}, nil
 	}
 
-	deployment, watchers, err := buildHierarchy(ctx, l, r.Client, &agent)
+	deployment, watchers, err := buildHierarchy(ctx, l, r.Client, &agent, newResourceFilter(r.config))
 	if err != nil {
 		level.Error(l).Log("msg", "unable to build hierarchy", "err", err)
 		return controller.Result{}, nil
@@ -31,6 +31,17 @@ func (r *reconciler) createMetricsConfigurationSecret(
 	d gragent.Deployment,
 ) error {
 
+	for _, ep := range config.InvalidEndpoints(&d) {
+		level.Warn(l).Log(
+			"msg", "skipping invalid endpoint",
+			"agent", client.ObjectKeyFromObject(d.Agent),
+			"kind", ep.Kind,
+			"monitor", types.NamespacedName{Namespace: ep.Namespace, Name: ep.Name},
+			"endpoint", ep.Index,
+			"err", ep.Err,
+		)
+	}
+
 	name := fmt.Sprintf("%s-config", d.Agent.Name)
 	return r.createTelemetryConfigurationSecret(ctx, l, name, d, config.MetricsType)
 }
@@ -62,6 +73,9 @@ func (r *reconciler) createTelemetryConfigurationSecret(
 
 	// Delete the old Secret if one exists and we have nothing to create.
 	if !shouldCreate {
+		if ty == config.MetricsType {
+			r.scrapeConfigs.Delete(client.ObjectKeyFromObject(d.Agent))
+		}
 		var secret core_v1.Secret
 		return deleteManagedResource(ctx, r.Client, key, &secret)
 	}
@@ -78,6 +92,10 @@ func (r *reconciler) createTelemetryConfigurationSecret(
 		return fmt.Errorf("unable to build config: %w", err)
 	}
 
+	if ty == config.MetricsType {
+		r.recordScrapeConfigs(l, d, rawConfig)
+	}
+
 	const maxUncompressed = 100 * 1024 // only compress secrets over 100kB
 	rawBytes := []byte(rawConfig)
 	if len(rawBytes) > maxUncompressed {
@@ -116,6 +134,18 @@ func (r *reconciler) createTelemetryConfigurationSecret(
 	return nil
 }
 
+// recordScrapeConfigs stores the scrape configs of the metrics config
+// generated for d, so they can be inspected through the debug handler.
+// Failures are logged, but don't fail the reconcile.
+func (r *reconciler) recordScrapeConfigs(l log.Logger, d gragent.Deployment, rawConfig string) {
+	configs, err := config.ScrapeConfigs(rawConfig)
+	if err != nil {
+		level.Warn(l).Log("msg", "unable to record generated scrape configs", "err", err)
+		return
+	}
+	r.scrapeConfigs.Set(client.ObjectKeyFromObject(d.Agent), configs)
+}
+
 // createMetricsGoverningService creates the service that governs the (eventual)
 // StatefulSet. It must be created before the StatefulSet.
 func (r *reconciler) createMetricsGoverningService(
@@ -0,0 +1,75 @@
+package operator
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// resourceFilter excludes ServiceMonitors, PodMonitors, Probes, and PodLogs
+// from the hierarchy by namespace or name.
+type resourceFilter struct {
+	ExcludedNamespaces []string
+	// ExcludedNames are glob patterns matched against the names of
+	// resources, using the syntax of path.Match.
+	ExcludedNames []string
+}
+
+// newResourceFilter returns the resourceFilter configured by c.
+func newResourceFilter(c *Config) resourceFilter {
+	return resourceFilter{
+		ExcludedNamespaces: c.ExcludedNamespaces,
+		ExcludedNames:      c.ExcludedResourceNames,
+	}
+}
+
+// Excluded returns whether obj is excluded by f.
+func (f resourceFilter) Excluded(obj metav1.Object) bool {
+	for _, ns := range f.ExcludedNamespaces {
+		if obj.GetNamespace() == ns {
+			return true
+		}
+	}
+	for _, pattern := range f.ExcludedNames {
+		// Patterns are validated when the Operator is created.
+		if matched, _ := path.Match(pattern, obj.GetName()); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterList removes the items excluded by f from list.
+func (f resourceFilter) FilterList(l log.Logger, list client.ObjectList) error {
+	if len(f.ExcludedNamespaces) == 0 && len(f.ExcludedNames) == 0 {
+		return nil
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return fmt.Errorf("failed to filter resources: %w", err)
+	}
+	kept := make([]runtime.Object, 0, len(items))
+	for _, item := range items {
+		obj, err := meta.Accessor(item)
+		if err != nil {
+			return fmt.Errorf("failed to filter resources: %w", err)
+		}
+		if f.Excluded(obj) {
+			level.Debug(l).Log(
+				"msg", "skipping excluded resource",
+				"kind", fmt.Sprintf("%T", item),
+				"resource", fmt.Sprintf("%s/%s", obj.GetNamespace(), obj.GetName()),
+			)
+			continue
+		}
+		kept = append(kept, item)
+	}
+	return meta.SetList(list, kept)
+}
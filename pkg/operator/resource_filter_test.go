@@ -0,0 +1,29 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/grafana/agent/pkg/util"
+	prom "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_resourceFilter(t *testing.T) {
+	list := prom.ServiceMonitorList{
+		Items: []*prom.ServiceMonitor{
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"}},
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kube-state-metrics"}},
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "prometheus", Name: "app"}},
+		},
+	}
+
+	filter := resourceFilter{
+		ExcludedNamespaces: []string{"prometheus"},
+		ExcludedNames:      []string{"kube-*"},
+	}
+	require.NoError(t, filter.FilterList(util.TestLogger(t), &list))
+	require.Len(t, list.Items, 1)
+	require.Equal(t, "default", list.Items[0].Namespace)
+	require.Equal(t, "app", list.Items[0].Name)
+}
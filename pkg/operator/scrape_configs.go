@@ -0,0 +1,75 @@
+package operator
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/grafana/agent/pkg/operator/config"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// scrapeConfigsPath is the path on the metrics server of the Operator which
+// serves the generated scrape configs.
+const scrapeConfigsPath = "/debug/scrape-configs"
+
+// generatedScrapeConfigs holds the scrape configs generated for every
+// reconciled GrafanaAgent. They're served as YAML, so that they can be diffed
+// against the scrape configs the Prometheus Operator generates for the same
+// monitors.
+type generatedScrapeConfigs struct {
+	mut    sync.RWMutex
+	agents map[types.NamespacedName][]config.InstanceScrapeConfigs
+}
+
+func newGeneratedScrapeConfigs() *generatedScrapeConfigs {
+	return &generatedScrapeConfigs{
+		agents: make(map[types.NamespacedName][]config.InstanceScrapeConfigs),
+	}
+}
+
+// Set stores the scrape configs generated for agent.
+func (g *generatedScrapeConfigs) Set(agent types.NamespacedName, configs []config.InstanceScrapeConfigs) {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+	g.agents[agent] = configs
+}
+
+// Delete removes the scrape configs of agent.
+func (g *generatedScrapeConfigs) Delete(agent types.NamespacedName) {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+	delete(g.agents, agent)
+}
+
+// ServeHTTP writes the scrape configs of every GrafanaAgent as YAML. The
+// agent query parameter, formatted as namespace/name, limits the response to
+// a single GrafanaAgent.
+func (g *generatedScrapeConfigs) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	type agentScrapeConfigs struct {
+		Agent     string                         `yaml:"agent"`
+		Instances []config.InstanceScrapeConfigs `yaml:"instances"`
+	}
+
+	filter := r.URL.Query().Get("agent")
+
+	g.mut.RLock()
+	res := make([]agentScrapeConfigs, 0, len(g.agents))
+	for agent, configs := range g.agents {
+		if filter != "" && filter != agent.String() {
+			continue
+		}
+		res = append(res, agentScrapeConfigs{Agent: agent.String(), Instances: configs})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Agent < res[j].Agent })
+	bb, err := yaml.Marshal(res)
+	g.mut.RUnlock()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(bb)
+}
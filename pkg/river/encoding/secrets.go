@@ -0,0 +1,81 @@
+package encoding
+
+import (
+	"fmt"
+)
+
+// secretLiteral is how secret values are tokenized.
+const secretLiteral = "(secret)"
+
+// SecretPaths returns the paths of the secret values in the River body value
+// input, such as "endpoint[0].basic_auth.password". Secrets are found whether
+// or not they're set, but not inside of unset blocks.
+func SecretPaths(input interface{}) ([]string, error) {
+	if input == nil {
+		return nil, nil
+	}
+	fields, err := getFieldsForBlock(nil, input)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	findSecretsInBody("", fields, &paths)
+	return paths, nil
+}
+
+// findSecretsInBody appends the paths of the secrets in the fields of a block
+// body to paths. Blocks which are specified multiple times are indexed by the
+// order they appear in.
+func findSecretsInBody(prefix string, fields []interface{}, paths *[]string) {
+	counts := make(map[string]int)
+	for _, f := range fields {
+		if bf, ok := f.(*blockField); ok {
+			counts[bf.Name]++
+		}
+	}
+
+	indexes := make(map[string]int)
+	for _, f := range fields {
+		switch f := f.(type) {
+		case *attributeField:
+			findSecretsInValue(joinSecretPath(prefix, f.Name), f.valField, paths)
+		case *blockField:
+			name := f.Name
+			if f.Label != "" {
+				name = fmt.Sprintf("%s[%q]", name, f.Label)
+			} else if counts[f.Name] > 1 {
+				name = fmt.Sprintf("%s[%d]", name, indexes[f.Name])
+				indexes[f.Name]++
+			}
+			findSecretsInBody(joinSecretPath(prefix, name), f.Body, paths)
+		}
+	}
+}
+
+// findSecretsInValue appends the paths of the secrets in the value at path to
+// paths.
+func findSecretsInValue(path string, v riverField, paths *[]string) {
+	switch v := v.(type) {
+	case *valueField:
+		if v.Type == "capsule" && v.Value == secretLiteral {
+			*paths = append(*paths, path)
+		}
+	case *arrayField:
+		for i, elem := range v.Value {
+			findSecretsInValue(fmt.Sprintf("%s[%d]", path, i), elem, paths)
+		}
+	case *mapField:
+		for _, kf := range v.Value {
+			if elem, ok := kf.Value.(riverField); ok {
+				findSecretsInValue(fmt.Sprintf("%s[%q]", path, kf.Key), elem, paths)
+			}
+		}
+	}
+}
+
+func joinSecretPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,45 @@
+package encoding_test
+
+import (
+	"testing"
+
+	"github.com/grafana/agent/pkg/flow/rivertypes"
+	"github.com/grafana/agent/pkg/river/encoding"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretPaths(t *testing.T) {
+	type BasicAuth struct {
+		Username string            `river:"username,attr"`
+		Password rivertypes.Secret `river:"password,attr,optional"`
+	}
+	type Endpoint struct {
+		URL       string                       `river:"url,attr"`
+		BasicAuth *BasicAuth                   `river:"basic_auth,block,optional"`
+		Headers   map[string]rivertypes.Secret `river:"headers,attr,optional"`
+	}
+	type Args struct {
+		Token     rivertypes.OptionalSecret `river:"token,attr,optional"`
+		TenantID  rivertypes.OptionalSecret `river:"tenant_id,attr,optional"`
+		Endpoints []Endpoint                `river:"endpoint,block"`
+	}
+
+	paths, err := encoding.SecretPaths(Args{
+		Token:    rivertypes.OptionalSecret{IsSecret: true, Value: "token"},
+		TenantID: rivertypes.OptionalSecret{Value: "tenant"},
+		Endpoints: []Endpoint{
+			{URL: "http://localhost:1"},
+			{
+				URL:       "http://localhost:2",
+				BasicAuth: &BasicAuth{Username: "user", Password: "password"},
+				Headers:   map[string]rivertypes.Secret{"X-Scope-OrgID": "tenant"},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"token",
+		"endpoint[1].basic_auth.password",
+		`endpoint[1].headers["X-Scope-OrgID"]`,
+	}, paths)
+}
@@ -0,0 +1,170 @@
+package runtimelimits
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// cgroupLimits holds resource limits discovered from the cgroup hierarchy of
+// the current process. A zero value for a field means that no limit was
+// found.
+type cgroupLimits struct {
+	// CPU is the number of CPUs the process is allowed to use. It may be
+	// fractional (i.e., a quota of 1.5 CPUs).
+	CPU float64
+
+	// Memory is the maximum number of bytes the process may use.
+	Memory int64
+}
+
+// readCgroupLimits reads the cgroup limits for the current process. root is
+// the root of the filesystem to read from, and is used for testing.
+//
+// Both cgroup v1 and cgroup v2 hierarchies are supported. Errors are only
+// returned if a limit file exists but could not be parsed; a missing cgroup
+// hierarchy is not an error.
+func readCgroupLimits(root string) (cgroupLimits, error) {
+	paths, err := readProcCgroups(filepath.Join(root, "proc/self/cgroup"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return cgroupLimits{}, err
+	}
+
+	mountRoot := filepath.Join(root, "sys/fs/cgroup")
+
+	// cgroup v2 exposes a single unified hierarchy with a cgroup.controllers
+	// file at its root.
+	if _, err := os.Stat(filepath.Join(mountRoot, "cgroup.controllers")); err == nil {
+		return readCgroupV2Limits(cgroupDirs(mountRoot, paths[""]))
+	}
+	return readCgroupV1Limits(
+		cgroupDirs(filepath.Join(mountRoot, "cpu"), paths["cpu"]),
+		cgroupDirs(filepath.Join(mountRoot, "memory"), paths["memory"]),
+	)
+}
+
+// cgroupDirs returns the set of directories to search for limit files, in
+// order of preference. Inside of containers, the cgroup path reported by
+// /proc/self/cgroup often doesn't exist because the cgroup namespace is
+// rooted at the container's cgroup, so the mount root is always searched
+// last.
+func cgroupDirs(mountRoot, path string) []string {
+	if path == "" || path == "/" {
+		return []string{mountRoot}
+	}
+	return []string{filepath.Join(mountRoot, path), mountRoot}
+}
+
+// readProcCgroups parses a /proc/<pid>/cgroup file into a map of controller
+// to cgroup path. The cgroup v2 unified hierarchy is stored with an empty
+// controller name.
+func readProcCgroups(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return map[string]string{}, err
+	}
+	defer f.Close()
+
+	res := make(map[string]string)
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		// Each line is in the form hierarchy-ID:controller-list:cgroup-path.
+		parts := strings.SplitN(s.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			res[controller] = parts[2]
+		}
+	}
+	return res, s.Err()
+}
+
+func readCgroupV2Limits(dirs []string) (cgroupLimits, error) {
+	var res cgroupLimits
+
+	if bb, ok := readFirst(dirs, "cpu.max"); ok {
+		fields := strings.Fields(bb)
+		if len(fields) != 2 {
+			return res, fmt.Errorf("unexpected cpu.max format %q", bb)
+		}
+		if fields[0] != "max" {
+			quota, err := strconv.ParseFloat(fields[0], 64)
+			if err != nil {
+				return res, fmt.Errorf("parsing cpu.max quota: %w", err)
+			}
+			period, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return res, fmt.Errorf("parsing cpu.max period: %w", err)
+			}
+			if period > 0 {
+				res.CPU = quota / period
+			}
+		}
+	}
+
+	if bb, ok := readFirst(dirs, "memory.max"); ok && bb != "max" {
+		limit, err := strconv.ParseInt(bb, 10, 64)
+		if err != nil {
+			return res, fmt.Errorf("parsing memory.max: %w", err)
+		}
+		res.Memory = limit
+	}
+
+	return res, nil
+}
+
+// cgroupV1Unlimited is the threshold above which a cgroup v1 memory limit is
+// considered to be unset. The kernel reports "unlimited" as the largest
+// page-aligned int64.
+const cgroupV1Unlimited = math.MaxInt64 / 2
+
+func readCgroupV1Limits(cpuDirs, memoryDirs []string) (cgroupLimits, error) {
+	var res cgroupLimits
+
+	quotaText, quotaOK := readFirst(cpuDirs, "cpu.cfs_quota_us")
+	periodText, periodOK := readFirst(cpuDirs, "cpu.cfs_period_us")
+	if quotaOK && periodOK && quotaText != "-1" {
+		quota, err := strconv.ParseFloat(quotaText, 64)
+		if err != nil {
+			return res, fmt.Errorf("parsing cpu.cfs_quota_us: %w", err)
+		}
+		period, err := strconv.ParseFloat(periodText, 64)
+		if err != nil {
+			return res, fmt.Errorf("parsing cpu.cfs_period_us: %w", err)
+		}
+		if quota > 0 && period > 0 {
+			res.CPU = quota / period
+		}
+	}
+
+	if bb, ok := readFirst(memoryDirs, "memory.limit_in_bytes"); ok {
+		limit, err := strconv.ParseInt(bb, 10, 64)
+		if err != nil {
+			return res, fmt.Errorf("parsing memory.limit_in_bytes: %w", err)
+		}
+		if limit < cgroupV1Unlimited {
+			res.Memory = limit
+		}
+	}
+
+	return res, nil
+}
+
+// readFirst returns the trimmed contents of the first file named name found
+// in dirs.
+func readFirst(dirs []string, name string) (string, bool) {
+	for _, dir := range dirs {
+		bb, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			return strings.TrimSpace(string(bb)), true
+		}
+	}
+	return "", false
+}
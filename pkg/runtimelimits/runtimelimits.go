@@ -0,0 +1,186 @@
+// Package runtimelimits configures the Go runtime to respect the CPU and
+// memory limits of the container the agent is running in.
+//
+// By default, the Go runtime sets GOMAXPROCS to the number of CPUs on the
+// host and doesn't set a soft memory limit. Inside of a container with a CPU
+// quota, this leads to the process being throttled, and inside of a container
+// with a memory limit, the garbage collector isn't aware that it should
+// collect more aggressively before the process gets OOM killed.
+package runtimelimits
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/alecthomas/units"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultOptions holds the default settings for Options.
+var DefaultOptions = Options{
+	MaxProcs:         0,
+	MemoryLimit:      0,
+	MemoryLimitRatio: 0.9,
+}
+
+// Options controls how runtime limits are applied.
+type Options struct {
+	// MaxProcs overrides the GOMAXPROCS value derived from the cgroup CPU
+	// quota. 0 means to derive the value.
+	MaxProcs int
+
+	// MemoryLimit overrides the GOMEMLIMIT value derived from the cgroup
+	// memory limit. 0 means to derive the value.
+	MemoryLimit units.Base2Bytes
+
+	// MemoryLimitRatio is the fraction of the cgroup memory limit to use as
+	// the derived GOMEMLIMIT. 0 disables deriving GOMEMLIMIT.
+	MemoryLimitRatio float64
+}
+
+// RegisterFlags registers flags for o to f.
+func (o *Options) RegisterFlags(f *flag.FlagSet) {
+	*o = DefaultOptions
+
+	f.IntVar(&o.MaxProcs, "runtime.max-procs", o.MaxProcs, "Overrides GOMAXPROCS. When 0, GOMAXPROCS is derived from the cgroup CPU quota.")
+	f.Var((*bytesValue)(&o.MemoryLimit), "runtime.memory-limit", "Overrides the Go soft memory limit (GOMEMLIMIT). When 0, the limit is derived from the cgroup memory limit.")
+	f.Float64Var(&o.MemoryLimitRatio, "runtime.memory-limit-ratio", o.MemoryLimitRatio, "Fraction of the cgroup memory limit to use as the Go soft memory limit. 0 disables deriving the limit.")
+}
+
+// Validate returns an error if o is invalid.
+func (o *Options) Validate() error {
+	if o.MaxProcs < 0 {
+		return fmt.Errorf("runtime.max-procs must not be negative")
+	}
+	if o.MemoryLimit < 0 {
+		return fmt.Errorf("runtime.memory-limit must not be negative")
+	}
+	if o.MemoryLimitRatio < 0 || o.MemoryLimitRatio > 1 {
+		return fmt.Errorf("runtime.memory-limit-ratio must be between 0 and 1")
+	}
+	return nil
+}
+
+// Limits are the runtime limits which were applied.
+type Limits struct {
+	CgroupCPU    float64 // CPU quota from the cgroup, 0 if unlimited.
+	CgroupMemory int64   // Memory limit from the cgroup, 0 if unlimited.
+
+	MaxProcs    int   // Applied GOMAXPROCS.
+	MemoryLimit int64 // Applied GOMEMLIMIT, math.MaxInt64 if unlimited.
+}
+
+// Apply configures the Go runtime based on the cgroup limits of the current
+// process and o. Explicit GOMAXPROCS or GOMEMLIMIT environment variables
+// always take precedence over derived values.
+//
+// If reg is non-nil, metrics exposing the detected limits are registered
+// against it.
+func Apply(l log.Logger, reg prometheus.Registerer, o Options) (Limits, error) {
+	if err := o.Validate(); err != nil {
+		return Limits{}, err
+	}
+
+	cg, err := readCgroupLimits("/")
+	if err != nil {
+		// Failing to read limits shouldn't prevent the process from starting.
+		level.Warn(l).Log("msg", "failed to read cgroup limits", "err", err)
+	}
+
+	res := computeLimits(cg, o, os.Getenv("GOMAXPROCS") != "", os.Getenv("GOMEMLIMIT") != "")
+
+	if res.MaxProcs > 0 {
+		runtime.GOMAXPROCS(res.MaxProcs)
+		level.Debug(l).Log("msg", "set GOMAXPROCS", "value", res.MaxProcs)
+	}
+	res.MaxProcs = runtime.GOMAXPROCS(0)
+
+	if res.MemoryLimit > 0 {
+		debug.SetMemoryLimit(res.MemoryLimit)
+		level.Debug(l).Log("msg", "set GOMEMLIMIT", "value", res.MemoryLimit)
+	}
+	res.MemoryLimit = debug.SetMemoryLimit(-1)
+
+	if reg != nil {
+		if err := registerMetrics(reg, res); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// computeLimits determines the limits to apply. A zero value for MaxProcs or
+// MemoryLimit in the result means that the runtime's current value should be
+// kept.
+func computeLimits(cg cgroupLimits, o Options, envProcs, envMemory bool) Limits {
+	res := Limits{
+		CgroupCPU:    cg.CPU,
+		CgroupMemory: cg.Memory,
+	}
+
+	switch {
+	case o.MaxProcs > 0:
+		res.MaxProcs = o.MaxProcs
+	case envProcs:
+		// Keep the value from the environment.
+	case cg.CPU > 0:
+		// Round up so that fractional quotas can still make use of the
+		// partial CPU.
+		res.MaxProcs = int(math.Ceil(cg.CPU))
+	}
+
+	switch {
+	case o.MemoryLimit > 0:
+		res.MemoryLimit = int64(o.MemoryLimit)
+	case envMemory:
+		// Keep the value from the environment.
+	case cg.Memory > 0 && o.MemoryLimitRatio > 0:
+		res.MemoryLimit = int64(float64(cg.Memory) * o.MemoryLimitRatio)
+	}
+
+	return res
+}
+
+func registerMetrics(reg prometheus.Registerer, l Limits) error {
+	gauges := []struct {
+		name, help string
+		value      float64
+	}{
+		{"agent_runtime_cgroup_cpu_limit", "CPU quota detected from the cgroup of the process. 0 if unlimited.", l.CgroupCPU},
+		{"agent_runtime_cgroup_memory_limit_bytes", "Memory limit detected from the cgroup of the process. 0 if unlimited.", float64(l.CgroupMemory)},
+		{"agent_runtime_gomaxprocs", "Value of GOMAXPROCS used by the Go runtime.", float64(l.MaxProcs)},
+		{"agent_runtime_gomemlimit_bytes", "Soft memory limit used by the Go runtime.", float64(l.MemoryLimit)},
+	}
+
+	for _, g := range gauges {
+		value := g.value
+		err := reg.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: g.name,
+			Help: g.help,
+		}, func() float64 { return value }))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bytesValue implements flag.Value for units.Base2Bytes.
+type bytesValue units.Base2Bytes
+
+func (v *bytesValue) String() string {
+	return units.Base2Bytes(*v).String()
+}
+
+func (v *bytesValue) Set(s string) error {
+	return (*units.Base2Bytes)(v).UnmarshalText([]byte(s))
+}
+
+// Type implements pflag.Value.
+func (v *bytesValue) Type() string { return "bytes" }
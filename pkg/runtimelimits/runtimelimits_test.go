@@ -0,0 +1,116 @@
+package runtimelimits
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCgroupLimits_V2(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"proc/self/cgroup":                       "0::/kubepods/pod1\n",
+		"sys/fs/cgroup/cgroup.controllers":       "cpu memory\n",
+		"sys/fs/cgroup/kubepods/pod1/cpu.max":    "150000 100000\n",
+		"sys/fs/cgroup/kubepods/pod1/memory.max": "1073741824\n",
+	})
+
+	limits, err := readCgroupLimits(root)
+	require.NoError(t, err)
+	require.Equal(t, cgroupLimits{CPU: 1.5, Memory: 1073741824}, limits)
+}
+
+func TestReadCgroupLimits_V2Namespaced(t *testing.T) {
+	// Inside of a cgroup namespace, the path from /proc/self/cgroup doesn't
+	// exist under the mount and the root of the mount should be used instead.
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"proc/self/cgroup":                 "0::/kubepods/pod1\n",
+		"sys/fs/cgroup/cgroup.controllers": "cpu memory\n",
+		"sys/fs/cgroup/cpu.max":            "max 100000\n",
+		"sys/fs/cgroup/memory.max":         "max\n",
+	})
+
+	limits, err := readCgroupLimits(root)
+	require.NoError(t, err)
+	require.Equal(t, cgroupLimits{}, limits)
+}
+
+func TestReadCgroupLimits_V1(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"proc/self/cgroup":                           "4:memory:/docker/abc\n3:cpu,cpuacct:/docker/abc\n",
+		"sys/fs/cgroup/cpu/cpu.cfs_quota_us":         "200000\n",
+		"sys/fs/cgroup/cpu/cpu.cfs_period_us":        "100000\n",
+		"sys/fs/cgroup/memory/memory.limit_in_bytes": "9223372036854771712\n",
+	})
+
+	limits, err := readCgroupLimits(root)
+	require.NoError(t, err)
+	require.Equal(t, cgroupLimits{CPU: 2}, limits)
+}
+
+func TestReadCgroupLimits_Missing(t *testing.T) {
+	limits, err := readCgroupLimits(t.TempDir())
+	require.NoError(t, err)
+	require.Equal(t, cgroupLimits{}, limits)
+}
+
+func TestComputeLimits(t *testing.T) {
+	cg := cgroupLimits{CPU: 1.5, Memory: 1000}
+
+	tt := []struct {
+		name       string
+		opts       Options
+		envProcs   bool
+		envMemory  bool
+		expectProc int
+		expectMem  int64
+	}{
+		{
+			name:       "derived",
+			opts:       DefaultOptions,
+			expectProc: 2,
+			expectMem:  900,
+		},
+		{
+			name:       "overrides",
+			opts:       Options{MaxProcs: 4, MemoryLimit: 500, MemoryLimitRatio: 0.9},
+			envProcs:   true,
+			envMemory:  true,
+			expectProc: 4,
+			expectMem:  500,
+		},
+		{
+			name:      "environment wins over derived",
+			opts:      DefaultOptions,
+			envProcs:  true,
+			envMemory: true,
+		},
+		{
+			name:       "ratio disabled",
+			opts:       Options{MemoryLimitRatio: 0},
+			expectProc: 2,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			res := computeLimits(cg, tc.opts, tc.envProcs, tc.envMemory)
+			require.Equal(t, tc.expectProc, res.MaxProcs)
+			require.Equal(t, tc.expectMem, res.MemoryLimit)
+		})
+	}
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+
+	for name, contents := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+	}
+}
@@ -0,0 +1,13 @@
+package util
+
+import "strings"
+
+// BracketIPv6 wraps host in square brackets if it is an IPv6 literal. It is
+// used for libraries which build listen addresses by formatting "host:port"
+// themselves instead of using net.JoinHostPort, such as weaveworks' server.
+func BracketIPv6(host string) string {
+	if strings.Contains(host, ":") && !strings.HasPrefix(host, "[") {
+		return "[" + host + "]"
+	}
+	return host
+}
@@ -0,0 +1,23 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBracketIPv6(t *testing.T) {
+	tt := map[string]string{
+		"":                "",
+		"0.0.0.0":         "0.0.0.0",
+		"localhost":       "localhost",
+		"::":              "[::]",
+		"::1":             "[::1]",
+		"[::1]":           "[::1]",
+		"fe80::1%eth0":    "[fe80::1%eth0]",
+		"2001:db8::a:b:c": "[2001:db8::a:b:c]",
+	}
+	for in, expect := range tt {
+		require.Equal(t, expect, BracketIPv6(in), "input %q", in)
+	}
+}
@@ -6,30 +6,162 @@ package api
 
 import (
 	"bytes"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"path"
+	"strings"
 
 	"github.com/prometheus/prometheus/util/httputil"
 
 	"github.com/gorilla/mux"
 	"github.com/grafana/agent/pkg/flow"
+	"github.com/grafana/agent/pkg/flow/logging"
 )
 
 // FlowAPI is a wrapper around the component API.
 type FlowAPI struct {
-	flow *flow.Flow
+	flow       *flow.Flow
+	logSink    *logging.Sink
+	adminToken string
+
+	secretFiles []SecretFile
+}
+
+// SecretFile is a location outside of the data directories of components
+// where the agent writes secrets to disk.
+type SecretFile struct {
+	Path        string `json:"path"`
+	Description string `json:"description"`
+}
+
+// NewFlowAPI instantiates a new Flow API. Administrative endpoints, which
+// change the state of components, require adminToken to be sent as a bearer
+// token. They're disabled if adminToken is empty.
+//
+// Endpoints to change logging at runtime are only registered if logSink is
+// non-nil.
+func NewFlowAPI(flow *flow.Flow, logSink *logging.Sink, r *mux.Router, adminToken string) *FlowAPI {
+	return &FlowAPI{flow: flow, logSink: logSink, adminToken: adminToken}
 }
 
-// NewFlowAPI instantiates a new Flow API.
-func NewFlowAPI(flow *flow.Flow, r *mux.Router) *FlowAPI {
-	return &FlowAPI{flow: flow}
+// AddSecretFile adds a location where secrets are written to disk to the
+// secrets endpoint. It must be called before RegisterRoutes.
+func (f *FlowAPI) AddSecretFile(path, description string) {
+	f.secretFiles = append(f.secretFiles, SecretFile{Path: path, Description: description})
 }
 
 // RegisterRoutes registers all the API's routes.
 func (f *FlowAPI) RegisterRoutes(urlPrefix string, r *mux.Router) {
 	r.Handle(path.Join(urlPrefix, "/components"), httputil.CompressionHandler{Handler: f.listComponentsHandler()})
 	r.Handle(path.Join(urlPrefix, "/components/{id}"), httputil.CompressionHandler{Handler: f.listComponentHandler()})
+	r.Handle(path.Join(urlPrefix, "/targets"), httputil.CompressionHandler{Handler: f.listTargetsHandler()})
+	r.Handle(path.Join(urlPrefix, "/secrets"), f.listSecretsHandler()).Methods(http.MethodGet)
+	r.Handle(path.Join(urlPrefix, "/components/{id}/restart"), f.requireAdminToken(f.componentActionHandler(f.flow.RestartComponent))).Methods(http.MethodPost)
+	r.Handle(path.Join(urlPrefix, "/components/{id}/reevaluate"), f.requireAdminToken(f.componentActionHandler(f.flow.ReevaluateComponent))).Methods(http.MethodPost)
+	r.Handle(path.Join(urlPrefix, "/snapshot"), f.requireAdminToken(f.snapshotHandler())).Methods(http.MethodGet)
+	r.Handle(path.Join(urlPrefix, "/snapshot"), f.requireAdminToken(f.restoreHandler())).Methods(http.MethodPost)
+
+	if f.logSink != nil {
+		r.Handle(path.Join(urlPrefix, "/logging"), f.getLoggingHandler()).Methods(http.MethodGet)
+		r.Handle(path.Join(urlPrefix, "/logging"), f.requireAdminToken(f.updateLoggingHandler())).Methods(http.MethodPost)
+		r.Handle(path.Join(urlPrefix, "/components/{id}/log_level"), f.requireAdminToken(f.setComponentLevelHandler())).Methods(http.MethodPut)
+		r.Handle(path.Join(urlPrefix, "/components/{id}/log_level"), f.requireAdminToken(f.clearComponentLevelHandler())).Methods(http.MethodDelete)
+	}
+}
+
+// requireAdminToken only calls next for requests which carry the admin token
+// as a bearer token.
+func (f *FlowAPI) requireAdminToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if f.adminToken == "" {
+			http.Error(w, "administrative endpoints are disabled", http.StatusForbidden)
+			return
+		}
+
+		auth := r.Header.Get("Authorization")
+		token := strings.TrimPrefix(auth, "Bearer ")
+		if token == auth || subtle.ConstantTimeCompare([]byte(token), []byte(f.adminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "invalid or missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// componentActionHandler returns a handler which calls action with the ID of
+// the requested component.
+func (f *FlowAPI) componentActionHandler(action func(id string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := action(mux.Vars(r)["id"])
+		if errors.Is(err, flow.ErrComponentNotFound) {
+			http.NotFound(w, r)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// maxSnapshotSize is the largest snapshot accepted by restoreHandler.
+const maxSnapshotSize = 64 << 20 // 64MiB
+
+// snapshotHandler returns an archive of the data directories of all
+// components. The archive is streamed as it's created. If creating it fails
+// after the response was started, the connection is aborted so that clients
+// don't mistake the partial archive for a complete one.
+func (f *FlowAPI) snapshotHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", `attachment; filename="snapshot.tar.gz"`)
+
+		cw := &countingWriter{w: w}
+		if err := f.flow.Snapshot(cw); err != nil {
+			if cw.n > 0 {
+				panic(http.ErrAbortHandler)
+			}
+			w.Header().Del("Content-Disposition")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// restoreHandler restores the data directories of components from a snapshot
+// sent as the request body.
+func (f *FlowAPI) restoreHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := f.flow.Restore(http.MaxBytesReader(w, r.Body, maxSnapshotSize))
+		if res == nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		bb, err := json.Marshal(res)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(bb)
+	}
 }
 
 func (f *FlowAPI) listComponentsHandler() http.HandlerFunc {
@@ -66,6 +198,49 @@ func (f *FlowAPI) listComponentHandler() http.HandlerFunc {
 	}
 }
 
+// listTargetsHandler lists the targets scraped by all components which
+// report their scrape targets.
+func (f *FlowAPI) listTargetsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		targets := f.flow.ScrapeTargets()
+		if targets == nil {
+			targets = []*flow.ScrapeTargetInfo{}
+		}
+		bb, err := json.Marshal(targets)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(bb)
+	}
+}
+
+// listSecretsHandler lists the secret arguments of all components and the
+// places where secrets are written to disk, without the secrets themselves.
+func (f *FlowAPI) listSecretsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		resp := struct {
+			Components []*flow.SecretInfo `json:"components"`
+			Files      []SecretFile       `json:"files"`
+		}{
+			Components: f.flow.SecretInfos(),
+			Files:      f.secretFiles,
+		}
+		if resp.Components == nil {
+			resp.Components = []*flow.SecretInfo{}
+		}
+		if resp.Files == nil {
+			resp.Files = []SecretFile{}
+		}
+		bb, err := json.Marshal(resp)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(bb)
+	}
+}
+
 // json returns the JSON representation of c.
 func (f *FlowAPI) json(c *flow.ComponentInfo) ([]byte, error) {
 	var buf bytes.Buffer
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/grafana/agent/pkg/flow"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentActions_AdminToken(t *testing.T) {
+	tt := []struct {
+		name       string
+		adminToken string
+		header     string
+		expect     int
+	}{
+		{name: "disabled", adminToken: "", header: "Bearer ", expect: http.StatusForbidden},
+		{name: "missing token", adminToken: "secret", header: "", expect: http.StatusUnauthorized},
+		{name: "wrong token", adminToken: "secret", header: "Bearer wrong", expect: http.StatusUnauthorized},
+		{name: "wrong scheme", adminToken: "secret", header: "Basic secret", expect: http.StatusUnauthorized},
+		// The token is accepted, but the component doesn't exist.
+		{name: "valid token", adminToken: "secret", header: "Bearer secret", expect: http.StatusNotFound},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			fa := NewFlowAPI(flow.New(flow.Options{}), nil, r, tc.adminToken)
+			fa.RegisterRoutes("/api/v0/web", r)
+
+			for _, action := range []string{"restart", "reevaluate"} {
+				req := httptest.NewRequest(http.MethodPost, "/api/v0/web/components/local.file.missing/"+action, nil)
+				if tc.header != "" {
+					req.Header.Set("Authorization", tc.header)
+				}
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, req)
+				require.Equal(t, tc.expect, rec.Code, action)
+			}
+		})
+	}
+}
+
+func TestListTargets_Empty(t *testing.T) {
+	r := mux.NewRouter()
+	fa := NewFlowAPI(flow.New(flow.Options{}), nil, r, "")
+	fa.RegisterRoutes("/api/v0/web", r)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v0/web/targets", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, "[]", rec.Body.String())
+}
+
+func TestListSecrets(t *testing.T) {
+	r := mux.NewRouter()
+	fa := NewFlowAPI(flow.New(flow.Options{}), nil, r, "")
+	fa.AddSecretFile("/var/lib/agent/cache", "cached remote configs")
+	fa.RegisterRoutes("/api/v0/web", r)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v0/web/secrets", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{
+		"components": [],
+		"files": [{"path": "/var/lib/agent/cache", "description": "cached remote configs"}]
+	}`, rec.Body.String())
+}
+
+func TestSnapshot(t *testing.T) {
+	r := mux.NewRouter()
+	fa := NewFlowAPI(flow.New(flow.Options{}), nil, r, "secret")
+	fa.RegisterRoutes("/api/v0/web", r)
+
+	// Taking a snapshot requires the admin token.
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v0/web/snapshot", nil))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v0/web/snapshot", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/gzip", rec.Header().Get("Content-Type"))
+
+	// Restoring the snapshot of an empty controller doesn't change anything.
+	req = httptest.NewRequest(http.MethodPost, "/api/v0/web/snapshot", bytes.NewReader(rec.Body.Bytes()))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"restored": [], "skipped": []}`, rec.Body.String())
+}
+
+func TestRestore_Invalid(t *testing.T) {
+	r := mux.NewRouter()
+	fa := NewFlowAPI(flow.New(flow.Options{}), nil, r, "secret")
+	fa.RegisterRoutes("/api/v0/web", r)
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "local.file.a/../../etc/passwd", Mode: 0600}))
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	for _, body := range [][]byte{[]byte("not a snapshot"), buf.Bytes()} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v0/web/snapshot", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/grafana/agent/pkg/flow/logging"
+)
+
+// loggingState is the JSON representation of the current logging options.
+type loggingState struct {
+	Level           logging.Level            `json:"level"`
+	Format          logging.Format           `json:"format"`
+	ComponentLevels map[string]logging.Level `json:"component_levels"`
+}
+
+// loggingUpdate is the body of requests to change the logging options.
+// Options which aren't set are kept as-is.
+type loggingUpdate struct {
+	Level  *logging.Level  `json:"level,omitempty"`
+	Format *logging.Format `json:"format,omitempty"`
+}
+
+// componentLevelUpdate is the body of requests to override the log level of
+// a component.
+type componentLevelUpdate struct {
+	Level logging.Level `json:"level"`
+}
+
+func (f *FlowAPI) loggingState() loggingState {
+	opts := f.logSink.Options()
+	return loggingState{
+		Level:           opts.Level,
+		Format:          opts.Format,
+		ComponentLevels: f.logSink.ComponentLevels(),
+	}
+}
+
+func (f *FlowAPI) getLoggingHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		f.writeLoggingState(w)
+	}
+}
+
+// updateLoggingHandler changes the log level and format. Changes only last
+// until the next time the logging block of the config file is evaluated.
+func (f *FlowAPI) updateLoggingHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var update loggingUpdate
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		opts := f.logSink.Options()
+		if update.Level != nil {
+			opts.Level = *update.Level
+		}
+		if update.Format != nil {
+			opts.Format = *update.Format
+		}
+		if err := f.logSink.Update(opts); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		f.writeLoggingState(w)
+	}
+}
+
+func (f *FlowAPI) setComponentLevelHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var update componentLevelUpdate
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %s", err), http.StatusBadRequest)
+			return
+		}
+		if update.Level == "" {
+			http.Error(w, "level must be set", http.StatusBadRequest)
+			return
+		}
+
+		if err := f.logSink.SetComponentLevel(mux.Vars(r)["id"], update.Level); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (f *FlowAPI) clearComponentLevelHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := f.logSink.ClearComponentLevel(mux.Vars(r)["id"]); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (f *FlowAPI) writeLoggingState(w http.ResponseWriter) {
+	bb, err := json.Marshal(f.loggingState())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = w.Write(bb)
+}
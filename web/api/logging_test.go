@@ -0,0 +1,53 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/grafana/agent/pkg/flow"
+	"github.com/grafana/agent/pkg/flow/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogging(t *testing.T) {
+	sink, err := logging.WriterSink(io.Discard, logging.DefaultSinkOptions)
+	require.NoError(t, err)
+
+	r := mux.NewRouter()
+	fa := NewFlowAPI(flow.New(flow.Options{LogSink: sink}), sink, r, "secret")
+	fa.RegisterRoutes("/api/v0/web", r)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v0/web"+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/logging", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"level": "info", "format": "logfmt", "component_levels": {}}`, rec.Body.String())
+
+	rec = do(http.MethodPost, "/logging", `{"level": "debug"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"level": "debug", "format": "logfmt", "component_levels": {}}`, rec.Body.String())
+
+	rec = do(http.MethodPost, "/logging", `{"format": "yaml"}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = do(http.MethodPut, "/components/prometheus.scrape.default/log_level", `{"level": "warn"}`)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Equal(t, map[string]logging.Level{"prometheus.scrape.default": logging.LevelWarn}, sink.ComponentLevels())
+
+	rec = do(http.MethodPut, "/components/prometheus.scrape.default/log_level", `{"level": "verbose"}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = do(http.MethodDelete, "/components/prometheus.scrape.default/log_level", "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Empty(t, sink.ComponentLevels())
+}
@@ -5,6 +5,7 @@ import Navbar from './features/layout/Navbar';
 import ComponentDetailPage from './pages/ComponentDetailPage';
 import Graph from './pages/Graph';
 import PageComponentList from './pages/PageComponentList';
+import PageTargets from './pages/PageTargets';
 
 interface Props {
   basePath: string;
@@ -19,6 +20,7 @@ const Router = ({ basePath }: Props) => {
           <Route path="/" element={<PageComponentList />} />
           <Route path="/component/*" element={<ComponentDetailPage />} />
           <Route path="/graph" element={<Graph />} />
+          <Route path="/targets" element={<PageTargets />} />
         </Routes>
       </main>
     </BrowserRouter>
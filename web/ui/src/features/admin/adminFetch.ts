@@ -0,0 +1,39 @@
+/** adminTokenKey is the session storage key holding the admin token. */
+const adminTokenKey = 'grafana-agent-admin-token';
+
+/**
+ * adminFetch sends a request to an administrative API endpoint, which
+ * requires a bearer token.
+ *
+ * The user is prompted for the token the first time it's needed, and it's
+ * kept for the rest of the browser session. undefined is returned if the user
+ * cancels the prompt.
+ *
+ * @param path The path of the endpoint, relative to the <base> tag inside of
+ * <head>.
+ * @param init Options of the request.
+ */
+export async function adminFetch(path: string, init: RequestInit): Promise<Response | undefined> {
+  const send = (token: string | null) =>
+    fetch(path, {
+      ...init,
+      cache: 'no-cache',
+      credentials: 'same-origin',
+      headers: {
+        ...init.headers,
+        ...(token ? { Authorization: `Bearer ${token}` } : {}),
+      },
+    });
+
+  const resp = await send(sessionStorage.getItem(adminTokenKey));
+  if (resp.status !== 401) {
+    return resp;
+  }
+
+  const token = window.prompt('Enter the admin token of this agent:');
+  if (token === null) {
+    return undefined;
+  }
+  sessionStorage.setItem(adminTokenKey, token);
+  return send(token);
+}
@@ -0,0 +1,58 @@
+import { FC, useState } from 'react';
+import { faArrowsRotate, faPowerOff } from '@fortawesome/free-solid-svg-icons';
+import { FontAwesomeIcon } from '@fortawesome/react-fontawesome';
+
+import { adminFetch } from '../admin/adminFetch';
+
+import styles from './ComponentActions.module.css';
+
+type Action = 'restart' | 'reevaluate';
+
+export interface ComponentActionsProps {
+  /** ID of the component to act on. */
+  id: string;
+}
+
+/**
+ * ComponentActions renders buttons to restart or re-evaluate a component.
+ *
+ * The actions are administrative endpoints which require the admin token.
+ */
+export const ComponentActions: FC<ComponentActionsProps> = ({ id }) => {
+  const [pending, setPending] = useState(false);
+  const [result, setResult] = useState<string | undefined>(undefined);
+
+  async function run(action: Action) {
+    setPending(true);
+    setResult(undefined);
+
+    try {
+      const resp = await adminFetch(`./api/v0/web/components/${id}/${action}`, { method: 'POST' });
+      if (resp === undefined) {
+        return;
+      }
+
+      if (resp.ok) {
+        setResult(action === 'restart' ? 'Component restarted.' : 'Component re-evaluated.');
+      } else {
+        setResult(`Failed: ${(await resp.text()).trim()}`);
+      }
+    } catch (err) {
+      setResult(`Failed: ${err}`);
+    } finally {
+      setPending(false);
+    }
+  }
+
+  return (
+    <div className={styles.actions}>
+      <button disabled={pending} onClick={() => run('restart')}>
+        Restart <FontAwesomeIcon icon={faPowerOff} />
+      </button>
+      <button disabled={pending} onClick={() => run('reevaluate')}>
+        Re-evaluate <FontAwesomeIcon icon={faArrowsRotate} />
+      </button>
+      {result && <span className={styles.result}>{result}</span>}
+    </div>
+  );
+};
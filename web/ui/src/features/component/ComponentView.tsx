@@ -5,7 +5,9 @@ import { FontAwesomeIcon } from '@fortawesome/react-fontawesome';
 
 import { RiverValue } from '../../features/river-js/RiverValue';
 import { AttrStmt, Body, StmtType } from '../../features/river-js/types';
+import { ComponentLogLevel } from '../logging/ComponentLogLevel';
 
+import { ComponentActions } from './ComponentActions';
 import ComponentList from './ComponentList';
 import { HealthLabel } from './HealthLabel';
 import { ComponentDetail, ComponentInfo } from './types';
@@ -101,6 +103,14 @@ export const ComponentView: FC<ComponentViewProps> = (props) => {
           </a>
         </div>
 
+        {/* Administrative actions are only available for components outside of modules. */}
+        {!props.component.parent && (
+          <>
+            <ComponentActions id={props.component.id} />
+            <ComponentLogLevel id={props.component.id} />
+          </>
+        )}
+
         {props.component.health.message && (
           <blockquote>
             <h1>
@@ -18,6 +18,11 @@ function Navbar() {
             Graph
           </NavLink>
         </li>
+        <li>
+          <NavLink to="/targets" className="nav-link">
+            Targets
+          </NavLink>
+        </li>
         <li>
           <a href="https://grafana.com/docs/agent/latest">Help</a>
         </li>
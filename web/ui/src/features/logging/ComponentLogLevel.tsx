@@ -0,0 +1,75 @@
+import { FC, useState } from 'react';
+
+import { useLogging } from '../../hooks/logging';
+import { adminFetch } from '../admin/adminFetch';
+
+import { logLevels } from './types';
+
+import styles from './LoggingControls.module.css';
+
+export interface ComponentLogLevelProps {
+  /** ID of the component to override the log level of. */
+  id: string;
+}
+
+/**
+ * ComponentLogLevel renders a control to override the log level of a single
+ * component. Selecting the default removes the override, so the component
+ * uses the log level of the agent again.
+ */
+export const ComponentLogLevel: FC<ComponentLogLevelProps> = ({ id }) => {
+  const [state, reload] = useLogging();
+  const [pending, setPending] = useState(false);
+  const [result, setResult] = useState<string | undefined>(undefined);
+
+  if (!state) {
+    return null;
+  }
+
+  async function update(level: string) {
+    setPending(true);
+    setResult(undefined);
+
+    try {
+      const resp = await adminFetch(
+        `./api/v0/web/components/${id}/log_level`,
+        level === ''
+          ? { method: 'DELETE' }
+          : {
+              method: 'PUT',
+              headers: { 'Content-Type': 'application/json' },
+              body: JSON.stringify({ level }),
+            }
+      );
+      if (resp === undefined) {
+        return;
+      }
+
+      if (!resp.ok) {
+        setResult(`Failed: ${(await resp.text()).trim()}`);
+      }
+      reload();
+    } catch (err) {
+      setResult(`Failed: ${err}`);
+    } finally {
+      setPending(false);
+    }
+  }
+
+  return (
+    <div className={styles.controls}>
+      <label>
+        Log level
+        <select disabled={pending} value={state.component_levels[id] ?? ''} onChange={(e) => update(e.target.value)}>
+          <option value="">default ({state.level})</option>
+          {logLevels.map((l) => (
+            <option key={l} value={l}>
+              {l}
+            </option>
+          ))}
+        </select>
+      </label>
+      {result && <span className={styles.result}>{result}</span>}
+    </div>
+  );
+};
@@ -0,0 +1,89 @@
+import { FC, useState } from 'react';
+
+import { adminFetch } from '../admin/adminFetch';
+
+import { LoggingState, logFormats, logLevels } from './types';
+
+import styles from './LoggingControls.module.css';
+
+export interface LoggingControlsProps {
+  /** Current logging options; controls are hidden until they're loaded. */
+  state?: LoggingState;
+
+  /** Called after the logging options have been changed. */
+  onChange: () => void;
+}
+
+/**
+ * LoggingControls renders controls to change the log level and format of the
+ * agent at runtime.
+ *
+ * Changes last until the logging block of the config file is next evaluated.
+ */
+export const LoggingControls: FC<LoggingControlsProps> = ({ state, onChange }) => {
+  const [pending, setPending] = useState(false);
+  const [result, setResult] = useState<string | undefined>(undefined);
+
+  if (!state) {
+    return null;
+  }
+
+  async function update(change: Partial<Pick<LoggingState, 'level' | 'format'>>) {
+    setPending(true);
+    setResult(undefined);
+
+    try {
+      const resp = await adminFetch('./api/v0/web/logging', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify(change),
+      });
+      if (resp === undefined) {
+        return;
+      }
+
+      if (!resp.ok) {
+        setResult(`Failed: ${(await resp.text()).trim()}`);
+      }
+      onChange();
+    } catch (err) {
+      setResult(`Failed: ${err}`);
+    } finally {
+      setPending(false);
+    }
+  }
+
+  return (
+    <div className={styles.controls}>
+      <label>
+        Log level
+        <select
+          disabled={pending}
+          value={state.level}
+          onChange={(e) => update({ level: e.target.value as LoggingState['level'] })}
+        >
+          {logLevels.map((l) => (
+            <option key={l} value={l}>
+              {l}
+            </option>
+          ))}
+        </select>
+      </label>
+      <label>
+        Log format
+        <select
+          disabled={pending}
+          value={state.format}
+          onChange={(e) => update({ format: e.target.value as LoggingState['format'] })}
+        >
+          {logFormats.map((f) => (
+            <option key={f} value={f}>
+              {f}
+            </option>
+          ))}
+        </select>
+      </label>
+      {result && <span className={styles.result}>{result}</span>}
+    </div>
+  );
+};
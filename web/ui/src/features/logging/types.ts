@@ -0,0 +1,18 @@
+/** Levels the agent can log at, from the most to the least verbose. */
+export const logLevels = ['debug', 'info', 'warn', 'error'] as const;
+
+/** Formats the agent can write log lines in. */
+export const logFormats = ['logfmt', 'json'] as const;
+
+export type LogLevel = typeof logLevels[number];
+
+export type LogFormat = typeof logFormats[number];
+
+/** LoggingState describes the current logging options of the agent. */
+export interface LoggingState {
+  level: LogLevel;
+  format: LogFormat;
+
+  /** Log level overrides of components, keyed by component ID. */
+  component_levels: Record<string, LogLevel>;
+}
@@ -0,0 +1,87 @@
+import { NavLink } from 'react-router-dom';
+
+import { ScrapeTarget } from './types';
+
+import listStyles from '../component/ComponentList.module.css';
+import healthStyles from '../component/HealthLabel.module.css';
+import styles from './TargetList.module.css';
+
+interface TargetListProps {
+  targets: ScrapeTarget[];
+}
+
+/** healthClass returns the class to style the health of a target with. */
+function healthClass(health: string): string {
+  switch (health) {
+    case 'up':
+      return healthStyles['state-ok'];
+    case 'down':
+      return healthStyles['state-error'];
+    default:
+      return healthStyles['state-warn'];
+  }
+}
+
+/**
+ * TargetList renders a table of scrape targets along with the state of their
+ * latest scrape.
+ */
+const TargetList = ({ targets }: TargetListProps) => {
+  if (targets.length === 0) {
+    return <div className={`${listStyles.list} ${styles.empty}`}>No components are scraping targets.</div>;
+  }
+
+  return (
+    <div className={listStyles.list}>
+      <table className={listStyles.table}>
+        <tr>
+          <th>Health</th>
+          <th>Component</th>
+          <th>Endpoint</th>
+          <th>Labels</th>
+          <th>Last scrape</th>
+          <th>Duration</th>
+        </tr>
+        {targets.map((target) => {
+          return (
+            <tr key={`${target.componentId}/${target.job}/${target.url}`}>
+              <td>
+                <span className={`${healthStyles.health} ${healthClass(target.health)}`}>{target.health}</span>
+              </td>
+              <td>
+                <NavLink to={'/component/' + target.componentId}>{target.componentId}</NavLink>
+              </td>
+              <td>
+                {target.url}
+                {target.lastError && <div className={styles.error}>{target.lastError}</div>}
+              </td>
+              <td>
+                <div className={styles.labels}>
+                  {Object.entries(target.labels).map(([name, value]) => (
+                    <span key={name} className={styles.label}>
+                      {name}="{value}"
+                    </span>
+                  ))}
+                </div>
+              </td>
+              <td>{formatLastScrape(target.lastScrape)}</td>
+              <td>{`${(target.lastScrapeDurationSeconds * 1000).toFixed(1)}ms`}</td>
+            </tr>
+          );
+        })}
+      </table>
+    </div>
+  );
+};
+
+/** formatLastScrape formats the time of the latest scrape relative to now. */
+function formatLastScrape(lastScrape: string): string {
+  const time = new Date(lastScrape);
+  if (time.getFullYear() <= 1) {
+    return 'never';
+  }
+  const seconds = Math.max(0, (Date.now() - time.getTime()) / 1000);
+  return `${seconds.toFixed(1)}s ago`;
+}
+
+export default TargetList;
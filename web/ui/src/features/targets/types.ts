@@ -0,0 +1,23 @@
+/** ScrapeTarget describes a target scraped by a component. */
+export interface ScrapeTarget {
+  /** ID of the component scraping the target. */
+  componentId: string;
+
+  job: string;
+  url: string;
+
+  /** Health of the latest scrape: up, down, or unknown. */
+  health: string;
+
+  /** Labels of the target. */
+  labels: Record<string, string>;
+
+  /** Error of the latest scrape, if it failed. */
+  lastError?: string;
+
+  /** Time of the latest scrape. */
+  lastScrape: string;
+
+  /** Duration of the latest scrape in seconds. */
+  lastScrapeDurationSeconds: number;
+}
@@ -0,0 +1,31 @@
+import { useCallback, useEffect, useState } from 'react';
+
+import { LoggingState } from '../features/logging/types';
+
+/**
+ * useLogging retrieves the current logging options from the API.
+ *
+ * The returned function reloads the options, such as after changing them.
+ */
+export const useLogging = (): [LoggingState | undefined, () => void] => {
+  const [state, setState] = useState<LoggingState | undefined>(undefined);
+
+  const reload = useCallback(() => {
+    const worker = async () => {
+      // Request is relative to the <base> tag inside of <head>.
+      const resp = await fetch('./api/v0/web/logging', {
+        cache: 'no-cache',
+        credentials: 'same-origin',
+      });
+      if (resp.ok) {
+        setState(await resp.json());
+      }
+    };
+
+    worker().catch(console.error);
+  }, []);
+
+  useEffect(reload, [reload]);
+
+  return [state, reload];
+};
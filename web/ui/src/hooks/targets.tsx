@@ -0,0 +1,26 @@
+import { useEffect, useState } from 'react';
+
+import { ScrapeTarget } from '../features/targets/types';
+
+/**
+ * useScrapeTargets retrieves the list of targets scraped by all components
+ * from the API.
+ */
+export const useScrapeTargets = (): ScrapeTarget[] => {
+  const [targets, setTargets] = useState<ScrapeTarget[]>([]);
+
+  useEffect(function () {
+    const worker = async () => {
+      // Request is relative to the <base> tag inside of <head>.
+      const resp = await fetch('./api/v0/web/targets', {
+        cache: 'no-cache',
+        credentials: 'same-origin',
+      });
+      setTargets(await resp.json());
+    };
+
+    worker().catch(console.error);
+  }, []);
+
+  return targets;
+};
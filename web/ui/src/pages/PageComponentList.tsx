@@ -2,13 +2,17 @@ import { faCubes } from '@fortawesome/free-solid-svg-icons';
 
 import ComponentList from '../features/component/ComponentList';
 import Page from '../features/layout/Page';
+import { LoggingControls } from '../features/logging/LoggingControls';
 import { useComponentInfo } from '../hooks/componentInfo';
+import { useLogging } from '../hooks/logging';
 
 function PageComponentList() {
   const components = useComponentInfo();
+  const [logging, reloadLogging] = useLogging();
 
   return (
     <Page name="Components" desc="List of defined components" icon={faCubes}>
+      <LoggingControls state={logging} onChange={reloadLogging} />
       <ComponentList components={components} />
     </Page>
   );
@@ -0,0 +1,17 @@
+import { faCrosshairs } from '@fortawesome/free-solid-svg-icons';
+
+import Page from '../features/layout/Page';
+import TargetList from '../features/targets/TargetList';
+import { useScrapeTargets } from '../hooks/targets';
+
+function PageTargets() {
+  const targets = useScrapeTargets();
+
+  return (
+    <Page name="Targets" desc="Targets scraped by all components" icon={faCrosshairs}>
+      <TargetList targets={targets} />
+    </Page>
+  );
+}
+
+export default PageTargets;